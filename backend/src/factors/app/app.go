@@ -9,7 +9,6 @@ import (
 	session "factors/session/store"
 	U "factors/util"
 	"flag"
-	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -33,6 +32,8 @@ func main() {
 	env := flag.String("env", "development", "")
 	port := flag.Int("api_http_port", 8080, "")
 	etcd := flag.String("etcd", "localhost:2379", "Comma separated list of etcd endpoints localhost:2379,localhost:2378")
+	patternServerMembershipFile := flag.String("pattern_server_membership_file", "",
+		"Json file with pattern_servers, to discover pattern servers without etcd.")
 
 	dbHost := flag.String("db_host", C.PostgresDefaultDBParams.Host, "")
 	dbPort := flag.Int("db_port", C.PostgresDefaultDBParams.Port, "")
@@ -136,6 +137,7 @@ func main() {
 	attributionCommonFlow := flag.String("attribution_common_flow", "", "For given projects, run attribution queries with common flow for "+
 		"dashboard and normal query. Both flow will check DB, cache based on week, months and so on..")
 	attributionDebugKPI := flag.String("attribution_debug_kpi", "ignore", "Attribution Debug KPI ID.")
+	attributionDayPartials := flag.String("attribution_day_partials", "", "For given projects, compute attribution queries by merging cached per day partials.")
 	enableMQLAPI := flag.Bool("enable_mql_api", false, "Enable MQL API routes.")
 	overrideAppName := flag.String("app_name", "", "Override default app_name.")
 
@@ -143,6 +145,9 @@ func main() {
 		"Disable direct execution of query from dashboard, if not available on cache.")
 
 	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRoot := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
+	trustedProxies := flag.String("trusted_proxies", "",
+		"Comma separated list of ips or cidrs of the proxies in front of the app server, to take the client ip from X-Forwarded-For.")
 
 	enableFilterOptimisation := flag.Bool("enable_filter_optimisation", false,
 		"Enables filter optimisation changes for memsql implementation.")
@@ -233,6 +238,9 @@ func main() {
 	enableCacheDBWriteProjects := flag.String("cache_db_write_projects", "", "")
 	enableCacheDBReadProjects := flag.String("cache_db_read_projects", "", "")
 	chatDebug := flag.Int("chat_debug", 0, "")
+	enableSelfHostedChartRendering := flag.Bool("enable_self_hosted_chart_rendering", true,
+		"Renders the images of the shared reports in-process, instead of using quickchart.")
+	chartImageURLSigningKey := flag.String("chart_image_url_signing_key", "", "Key to sign the urls of the chart images.")
 	flag.Parse()
 
 	defaultAppName := "app_server"
@@ -296,6 +304,7 @@ func main() {
 		RedisPortPersistent:                            *redisPortPersistent,
 		GeolocationFile:                                *geoLocFilePath,
 		DeviceDetectorPath:                             *deviceDetectorPath,
+		PatternServerMembershipFile:                    *patternServerMembershipFile,
 		APIDomain:                                      *apiDomain,
 		APPDomain:                                      *appDomain,
 		APPOldDomain:                                   *appOldDomain,
@@ -330,6 +339,7 @@ func main() {
 		AttributionCommonFlow:                          *attributionCommonFlow,
 		AttributionDBCacheLookup:                       *attributionDBCacheLookup,
 		AttributionDebugKPI:                            *attributionDebugKPI,
+		AttributionDayPartials:                         *attributionDayPartials,
 		DisableDashboardQueryDBExecution:               *disableDashboardQueryDBExecution,
 		EnableFilterOptimisation:                       *enableFilterOptimisation,
 		FilterPropertiesStartTimestamp:                 *filterPropertiesStartTimestamp,
@@ -411,6 +421,8 @@ func main() {
 		EnableCacheDBReadProjects:                      *enableCacheDBReadProjects,
 		WebsiteAggregationTestEnabledProjects:          *websiteAggregationTestEnabledProjects,
 		ChatDebug:                                      *chatDebug,
+		EnableSelfHostedChartRendering:                 *enableSelfHostedChartRendering,
+		ChartImageURLSigningKey:                        *chartImageURLSigningKey,
 	}
 	C.InitConf(config)
 
@@ -424,15 +436,33 @@ func main() {
 	CheckIfDefaultDatasAreCorrect()
 	C.InitMonitoringAPIServices(config)
 	C.InitRedisPersistent(config.RedisHostPersistent, config.RedisPortPersistent)
-	C.InitFilemanager(*bucketName, *env, config)
+	if *localFSRoot != "" {
+		C.InitLocalFSFilemanager(*localFSRoot, *bucketName, config)
+	} else {
+		C.InitFilemanager(*bucketName, *env, config)
+	}
 
 	if !C.IsDevelopment() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	defer C.SafeFlushAllCollectors()
 	defer U.NotifyOnPanicWithError(*env, appName)
+	// Queries still running are killed only after the server is shutdown and the usages are written.
+	defer C.CancelDBContexts()
+	// Usages of the api keys counted in memory are written before exit.
+	defer mid.FlushAPIKeyUsages()
 
 	r := gin.New()
+	if *trustedProxies != "" {
+		err = r.SetTrustedProxies(C.GetTokensFromStringListAsString(*trustedProxies))
+		if err != nil {
+			log.WithError(err).Fatal("Invalid trusted proxies.")
+		}
+	} else {
+		// X-Forwarded-For can be set by any client, without a trusted proxy.
+		_ = r.SetTrustedProxies(nil)
+		log.Warn("Trusted proxies not provided. Client ip is taken from the remote address of the request.")
+	}
 	// Group based middlewares should be registered on corresponding init methods.
 	r.Use(mid.AddSecurityHeadersForAppRoutes())
 	// Root middleware for cors.
@@ -454,6 +484,7 @@ func main() {
 	}
 	H.InitAppRoutes(r)
 	H.InitIntRoutes(r)
+	H.InitPrivateQueryRoutes(r)
 
 	if *auth0ClientID != "" && *auth0ClientSecret != "" && *auth0Domain != "" && *auth0CallbackURL != "" {
 		authenticator, err := H.NewAuth()
@@ -466,8 +497,7 @@ func main() {
 
 	model.SetSmartPropertiesReservedNames()
 
-	C.KillDBQueriesOnExit()
-	r.Run(":" + strconv.Itoa(C.GetConfig().Port))
+	C.RunServerWithGracefulShutdown(r, C.GetConfig().Port)
 }
 
 func CheckIfDefaultDatasAreCorrect() {
@@ -241,16 +241,27 @@ func DelQueueRedis(keys ...*cache.Key) error {
 	return del(false, true, keys...)
 }
 
+// DelWithCountPersistent deletes the keys and returns the no.of keys deleted,
+// i.e 0 when the keys were already deleted by a concurrent caller.
+func DelWithCountPersistent(keys ...*cache.Key) (int64, error) {
+	return delWithCount(true, false, keys...)
+}
+
 func del(persistent bool, queue bool, keys ...*cache.Key) error {
+	_, err := delWithCount(persistent, queue, keys...)
+	return err
+}
+
+func delWithCount(persistent bool, queue bool, keys ...*cache.Key) (int64, error) {
 	var cKeys []interface{}
 
 	for _, key := range keys {
 		if key == nil {
-			return cache.ErrorInvalidKey
+			return 0, cache.ErrorInvalidKey
 		}
 		cKey, err := key.Key()
 		if err != nil {
-			return err
+			return 0, err
 		}
 		cKeys = append(cKeys, cKey)
 	}
@@ -265,11 +276,7 @@ func del(persistent bool, queue bool, keys ...*cache.Key) error {
 	}
 	defer redisConn.Close()
 
-	_, err := redisConn.Do("DEL", cKeys...)
-	if err != nil {
-		return err
-	}
-	return err
+	return redis.Int64(redisConn.Do("DEL", cKeys...))
 }
 
 func ExistsPersistent(key *cache.Key) (bool, error) {
@@ -1003,3 +1010,120 @@ func setExpiry(key *cache.Key, persistent bool, queue bool, expiryInSeconds int)
 
 	return redis.Int64(redisConn.Do("EXPIRE", cKey, expiryInSeconds))
 }
+
+// RPushPersistent appends the value to the list and returns the length of the list after the push.
+func RPushPersistent(key *cache.Key, value string, expiryInSecs float64) (int64, error) {
+	return rPush(key, value, expiryInSecs, true, false)
+}
+func rPush(key *cache.Key, value string, expiryInSecs float64, persistent bool, queue bool) (int64, error) {
+	if key == nil {
+		return 0, cache.ErrorInvalidKey
+	}
+
+	cKey, err := key.Key()
+	if err != nil {
+		return 0, err
+	}
+
+	var redisConn redis.Conn
+	if queue {
+		redisConn = C.GetCacheQueueRedisConnection()
+	} else if persistent {
+		redisConn = C.GetCacheRedisPersistentConnection()
+	} else {
+		redisConn = C.GetCacheRedisConnection()
+	}
+	defer redisConn.Close()
+
+	length, err := redis.Int64(redisConn.Do("RPUSH", cKey, value))
+	if err != nil {
+		return 0, err
+	}
+	if expiryInSecs > 0 {
+		if _, err := redisConn.Do("EXPIRE", cKey, int64(expiryInSecs)); err != nil {
+			return length, err
+		}
+	}
+	return length, nil
+}
+
+// LRangePersistent returns the elements of the list between start and stop, both inclusive.
+func LRangePersistent(key *cache.Key, start, stop int64) ([]string, error) {
+	return lRange(key, start, stop, true, false)
+}
+func lRange(key *cache.Key, start, stop int64, persistent bool, queue bool) ([]string, error) {
+	if key == nil {
+		return nil, cache.ErrorInvalidKey
+	}
+
+	cKey, err := key.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	var redisConn redis.Conn
+	if queue {
+		redisConn = C.GetCacheQueueRedisConnection()
+	} else if persistent {
+		redisConn = C.GetCacheRedisPersistentConnection()
+	} else {
+		redisConn = C.GetCacheRedisConnection()
+	}
+	defer redisConn.Close()
+
+	return redis.Strings(redisConn.Do("LRANGE", cKey, start, stop))
+}
+
+func LLenPersistent(key *cache.Key) (int64, error) {
+	return lLen(key, true, false)
+}
+func lLen(key *cache.Key, persistent bool, queue bool) (int64, error) {
+	if key == nil {
+		return 0, cache.ErrorInvalidKey
+	}
+
+	cKey, err := key.Key()
+	if err != nil {
+		return 0, err
+	}
+
+	var redisConn redis.Conn
+	if queue {
+		redisConn = C.GetCacheQueueRedisConnection()
+	} else if persistent {
+		redisConn = C.GetCacheRedisPersistentConnection()
+	} else {
+		redisConn = C.GetCacheRedisConnection()
+	}
+	defer redisConn.Close()
+
+	return redis.Int64(redisConn.Do("LLEN", cKey))
+}
+
+// LTrimPersistent keeps only the elements of the list between start and stop, both inclusive.
+func LTrimPersistent(key *cache.Key, start, stop int64) error {
+	return lTrim(key, start, stop, true, false)
+}
+func lTrim(key *cache.Key, start, stop int64, persistent bool, queue bool) error {
+	if key == nil {
+		return cache.ErrorInvalidKey
+	}
+
+	cKey, err := key.Key()
+	if err != nil {
+		return err
+	}
+
+	var redisConn redis.Conn
+	if queue {
+		redisConn = C.GetCacheQueueRedisConnection()
+	} else if persistent {
+		redisConn = C.GetCacheRedisPersistentConnection()
+	} else {
+		redisConn = C.GetCacheRedisConnection()
+	}
+	defer redisConn.Close()
+
+	_, err = redisConn.Do("LTRIM", cKey, start, stop)
+	return err
+}
@@ -0,0 +1,188 @@
+package company_enrichment
+
+import (
+	"factors/company_enrichment/clearbit"
+	"factors/company_enrichment/demandbase"
+	"factors/company_enrichment/factors_deanon"
+	"factors/company_enrichment/sixsignal"
+	"factors/model/model"
+	U "factors/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EnrichmentRequest is the context of the event, on which the company is identified.
+type EnrichmentRequest struct {
+	ProjectSettings *model.ProjectSetting
+	UserProperties  *U.PropertiesMap
+	EventProperties *U.PropertiesMap
+	UserId          string
+	ClientIP        string
+	IsoCode         string
+	PageURL         string
+	// Providers tried by the waterfall, including the one served from the shared cache.
+	AttemptedProviders []string
+}
+
+// IsAttemptedBy checks if the provider was tried on the request.
+func (request *EnrichmentRequest) IsAttemptedBy(providerName string) bool {
+	for _, name := range request.AttemptedProviders {
+		if name == providerName {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Provider is a company enrichment vendor. A new vendor is added by implementing the
+provider and registering it on providers, the waterfall picks it up by the name.
+ 1. IsQuotaAvailable checks the usage of the provider against the limit.
+ 2. IsEligible checks the integration and the rules of the provider.
+ 3. Enrich fills the company identification props and returns the domain and status as 1 on success.
+ 4. Meter meters the successful enrichment, including the ones served from the shared cache.
+*/
+type Provider interface {
+	Name() string
+	// Feature of the provider, for the integration status.
+	Feature() string
+	IsQuotaAvailable(projectId int64) (bool, error)
+	IsEligible(request *EnrichmentRequest, logCtx *log.Entry) (bool, error)
+	Enrich(request *EnrichmentRequest, logCtx *log.Entry) (string, int)
+	Meter(projectId int64, domain string, logCtx *log.Entry)
+}
+
+var providers = map[string]Provider{
+	model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE:     &demandbaseProvider{},
+	model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT:       &clearbitProvider{},
+	model.COMPANY_ENRICHMENT_PROVIDER_SIX_SIGNAL:     &sixSignalProvider{},
+	model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON: &factorsDeanonProvider{},
+}
+
+// RegisterProvider registers the provider by its name, replacing the provider registered with the
+// name, if any. It returns a function to restore the replaced provider, for the tests to mock providers.
+// Providers are not safe to be registered while enriching.
+func RegisterProvider(provider Provider) func() {
+	previousProvider, exists := providers[provider.Name()]
+	providers[provider.Name()] = provider
+	return func() {
+		if exists {
+			providers[provider.Name()] = previousProvider
+		} else {
+			delete(providers, provider.Name())
+		}
+	}
+}
+
+// GetProvider returns the registered provider by the name.
+func GetProvider(name string) (Provider, bool) {
+	provider, exists := providers[name]
+	return provider, exists
+}
+
+type demandbaseProvider struct {
+	demandbase.CustomerDemandbase
+}
+
+func (p *demandbaseProvider) Name() string {
+	return model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE
+}
+
+func (p *demandbaseProvider) Feature() string {
+	return model.FEATURE_DEMANDBASE
+}
+
+func (p *demandbaseProvider) IsQuotaAvailable(projectId int64) (bool, error) {
+	return true, nil
+}
+
+func (p *demandbaseProvider) IsEligible(request *EnrichmentRequest, logCtx *log.Entry) (bool, error) {
+	return p.CustomerDemandbase.IsEligible(request.ProjectSettings, logCtx)
+}
+
+func (p *demandbaseProvider) Enrich(request *EnrichmentRequest, logCtx *log.Entry) (string, int) {
+	return p.CustomerDemandbase.Enrich(request.ProjectSettings, request.UserProperties, request.UserId, request.ClientIP, logCtx)
+}
+
+func (p *demandbaseProvider) Meter(projectId int64, domain string, logCtx *log.Entry) {}
+
+type clearbitProvider struct {
+	clearbit.CustomerClearbit
+}
+
+func (p *clearbitProvider) Name() string {
+	return model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT
+}
+
+func (p *clearbitProvider) Feature() string {
+	return model.FEATURE_CLEARBIT
+}
+
+func (p *clearbitProvider) IsQuotaAvailable(projectId int64) (bool, error) {
+	return true, nil
+}
+
+func (p *clearbitProvider) IsEligible(request *EnrichmentRequest, logCtx *log.Entry) (bool, error) {
+	return p.CustomerClearbit.IsEligible(request.ProjectSettings, logCtx)
+}
+
+func (p *clearbitProvider) Enrich(request *EnrichmentRequest, logCtx *log.Entry) (string, int) {
+	return p.CustomerClearbit.Enrich(request.ProjectSettings, request.UserProperties, request.UserId, request.ClientIP, logCtx)
+}
+
+func (p *clearbitProvider) Meter(projectId int64, domain string, logCtx *log.Entry) {}
+
+type sixSignalProvider struct {
+	sixsignal.CustomerSixSignal
+}
+
+func (p *sixSignalProvider) Name() string {
+	return model.COMPANY_ENRICHMENT_PROVIDER_SIX_SIGNAL
+}
+
+func (p *sixSignalProvider) Feature() string {
+	return model.FEATURE_SIX_SIGNAL
+}
+
+func (p *sixSignalProvider) IsQuotaAvailable(projectId int64) (bool, error) {
+	return true, nil
+}
+
+func (p *sixSignalProvider) IsEligible(request *EnrichmentRequest, logCtx *log.Entry) (bool, error) {
+	return p.CustomerSixSignal.IsEligible(request.ProjectSettings, logCtx)
+}
+
+func (p *sixSignalProvider) Enrich(request *EnrichmentRequest, logCtx *log.Entry) (string, int) {
+	return p.CustomerSixSignal.Enrich(request.ProjectSettings, request.UserProperties, request.UserId, request.ClientIP, logCtx)
+}
+
+func (p *sixSignalProvider) Meter(projectId int64, domain string, logCtx *log.Entry) {}
+
+type factorsDeanonProvider struct {
+	factors_deanon.FactorsDeanon
+}
+
+func (p *factorsDeanonProvider) Name() string {
+	return model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON
+}
+
+func (p *factorsDeanonProvider) Feature() string {
+	return model.FEATURE_FACTORS_DEANONYMISATION
+}
+
+func (p *factorsDeanonProvider) IsQuotaAvailable(projectId int64) (bool, error) {
+	return factors_deanon.CheckingFactorsDeanonQuotaLimit(projectId)
+}
+
+func (p *factorsDeanonProvider) IsEligible(request *EnrichmentRequest, logCtx *log.Entry) (bool, error) {
+	return p.FactorsDeanon.IsEligible(request.ProjectSettings, request.IsoCode, request.PageURL, logCtx)
+}
+
+func (p *factorsDeanonProvider) Enrich(request *EnrichmentRequest, logCtx *log.Entry) (string, int) {
+	return p.FactorsDeanon.Enrich(request.ProjectSettings, request.UserProperties, request.EventProperties,
+		request.UserId, request.ClientIP, logCtx)
+}
+
+func (p *factorsDeanonProvider) Meter(projectId int64, domain string, logCtx *log.Entry) {
+	p.FactorsDeanon.Meter(projectId, domain, logCtx)
+}
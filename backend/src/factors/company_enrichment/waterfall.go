@@ -0,0 +1,267 @@
+package company_enrichment
+
+import (
+	"encoding/json"
+	"factors/cache"
+	cacheRedis "factors/cache/redis"
+	"factors/company_enrichment/factors_deanon"
+	"factors/config"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gomodule/redigo/redis"
+	log "github.com/sirupsen/logrus"
+)
+
+// Company of the IP is cached for a week, as it doesn't change often.
+const companyEnrichmentCacheInvalidationDuration float64 = 7 * 24 * 60 * 60
+
+// CompanyEnrichmentCacheResult is the company props filled by a provider for an IP.
+type CompanyEnrichmentCacheResult struct {
+	Provider        string          `json:"provider"`
+	Domain          string          `json:"domain"`
+	Properties      U.PropertiesMap `json:"properties"`
+	EventProperties U.PropertiesMap `json:"event_properties"`
+}
+
+/*
+EnrichByWaterfall fills the company identification props using the providers on the
+waterfall of the project.
+ 1. Company props of the IP enriched earlier by any of the providers are used from the cache,
+    if the provider is still available to the project. Enrichment is metered as by the provider.
+ 2. Providers are tried in the order, skipping the ones without quota, failing the rules or not eligible.
+ 3. Enrichment stops on the first eligible provider, or on the first success with fallback enabled.
+*/
+func EnrichByWaterfall(request *EnrichmentRequest, logCtx *log.Entry) (string, int) {
+	projectId := request.ProjectSettings.ProjectId
+
+	waterfall, err := model.GetCompanyEnrichmentWaterfall(request.ProjectSettings)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to decode company enrichment waterfall. Using default.")
+	}
+
+	if domain, status := enrichFromCache(request, waterfall, logCtx); status == 1 {
+		return domain, status
+	}
+
+	for _, providerConfig := range waterfall.Providers {
+		provider, available := getAvailableProvider(providerConfig, request, logCtx)
+		if !available {
+			continue
+		}
+
+		propertiesBeforeEnrich := copyProperties(request.UserProperties)
+		eventPropertiesBeforeEnrich := copyProperties(request.EventProperties)
+		request.AttemptedProviders = append(request.AttemptedProviders, provider.Name())
+		domain, status := provider.Enrich(request, logCtx)
+		if status == 1 {
+			onEnrichmentSuccess(projectId, provider, domain, logCtx)
+			SetCompanyEnrichmentCacheResult(projectId, request.ClientIP, CompanyEnrichmentCacheResult{
+				Provider:        provider.Name(),
+				Domain:          domain,
+				Properties:      getUpdatedProperties(propertiesBeforeEnrich, request.UserProperties),
+				EventProperties: getUpdatedProperties(eventPropertiesBeforeEnrich, request.EventProperties),
+			})
+			return domain, status
+		}
+
+		if !waterfall.FallbackOnFailure {
+			return domain, status
+		}
+		if config.IsEnrichmentDebugLogsEnabled(projectId) {
+			logCtx.WithField("provider", provider.Name()).Info("Company enrichment failed. Falling back to the next provider.")
+		}
+	}
+
+	return "", 0
+}
+
+// enrichFromCache fills the company props of the IP from the shared cache, when the provider which
+// enriched it is on the waterfall and available to the project. Returns status as 1 on success.
+func enrichFromCache(request *EnrichmentRequest, waterfall model.CompanyEnrichmentWaterfall, logCtx *log.Entry) (string, int) {
+	projectId := request.ProjectSettings.ProjectId
+
+	cacheResult, status := GetCompanyEnrichmentCacheResult(projectId, request.ClientIP)
+	if status != http.StatusFound {
+		return "", 0
+	}
+
+	for _, providerConfig := range waterfall.Providers {
+		if providerConfig.Name != cacheResult.Provider {
+			continue
+		}
+
+		provider, available := getAvailableProvider(providerConfig, request, logCtx)
+		if !available {
+			break
+		}
+
+		for key, value := range cacheResult.Properties {
+			(*request.UserProperties)[key] = value
+		}
+		if request.EventProperties != nil {
+			for key, value := range cacheResult.EventProperties {
+				(*request.EventProperties)[key] = value
+			}
+		}
+		request.AttemptedProviders = append(request.AttemptedProviders, provider.Name())
+		onEnrichmentSuccess(projectId, provider, cacheResult.Domain, logCtx)
+
+		if config.IsEnrichmentDebugLogsEnabled(projectId) {
+			logCtx.WithField("provider", cacheResult.Provider).Info("Company enrichment from the shared cache.")
+		}
+		return cacheResult.Domain, 1
+	}
+
+	if config.IsEnrichmentDebugLogsEnabled(projectId) {
+		logCtx.WithField("provider", cacheResult.Provider).Info("Provider of the shared cache is not available. Enriching.")
+	}
+	return "", 0
+}
+
+// getAvailableProvider returns the provider if it has quota, passes the rules and is eligible for the request.
+func getAvailableProvider(providerConfig model.CompanyEnrichmentProviderConfig, request *EnrichmentRequest,
+	logCtx *log.Entry) (Provider, bool) {
+
+	projectId := request.ProjectSettings.ProjectId
+	provider, exists := GetProvider(providerConfig.Name)
+	if !exists {
+		logCtx.WithField("provider", providerConfig.Name).Error("Invalid provider on company enrichment waterfall.")
+		return nil, false
+	}
+
+	isQuotaAvailable, err := provider.IsQuotaAvailable(projectId)
+	if err != nil {
+		logCtx.WithField("provider", provider.Name()).WithError(err).Error("Failed to check quota of provider.")
+		return nil, false
+	}
+	if !isQuotaAvailable {
+		updateIntegrationStatus(projectId, provider.Feature(), model.LIMIT_EXCEED)
+		return nil, false
+	}
+
+	if !IsEnrichmentRulesPassed(providerConfig.Rules, request.IsoCode, request.PageURL) {
+		return nil, false
+	}
+	if eligible, _ := provider.IsEligible(request, logCtx); !eligible {
+		return nil, false
+	}
+	return provider, true
+}
+
+func onEnrichmentSuccess(projectId int64, provider Provider, domain string, logCtx *log.Entry) {
+	provider.Meter(projectId, domain, logCtx)
+	updateIntegrationStatus(projectId, provider.Feature(), model.SUCCESS)
+}
+
+// IsEnrichmentRulesPassed checks the country and page rules of the provider on the waterfall.
+func IsEnrichmentRulesPassed(rules *model.SixSignalConfig, isoCode, pageURL string) bool {
+	if rules == nil {
+		return true
+	}
+	if !factors_deanon.IsCountryRulesPassed(*rules, isoCode) {
+		return false
+	}
+	pageRulesPassed, _ := factors_deanon.IsPageUrlRulesPassed(*rules, pageURL)
+	return pageRulesPassed
+}
+
+func updateIntegrationStatus(projectId int64, feature, integrationStatus string) {
+	status := store.GetStore().UpdateProjectSettingsIntegrationStatus(projectId, feature, integrationStatus)
+	if status != http.StatusAccepted {
+		log.WithFields(log.Fields{"project_id": projectId}).Warn("Failed to update integration status")
+	}
+}
+
+func copyProperties(properties *U.PropertiesMap) U.PropertiesMap {
+	if properties == nil {
+		return U.PropertiesMap{}
+	}
+	propertiesCopy := make(U.PropertiesMap, len(*properties))
+	for key, value := range *properties {
+		propertiesCopy[key] = value
+	}
+	return propertiesCopy
+}
+
+// getUpdatedProperties returns the properties added or updated from the previous properties.
+func getUpdatedProperties(previousProperties U.PropertiesMap, properties *U.PropertiesMap) U.PropertiesMap {
+	updatedProperties := make(U.PropertiesMap)
+	if properties == nil {
+		return updatedProperties
+	}
+	for key, value := range *properties {
+		if previousValue, exists := previousProperties[key]; !exists || !reflect.DeepEqual(previousValue, value) {
+			updatedProperties[key] = value
+		}
+	}
+	return updatedProperties
+}
+
+// GetCompanyEnrichmentCacheKey returns the key by the IP, shared across the providers.
+func GetCompanyEnrichmentCacheKey(projectId int64, clientIP string) (*cache.Key, error) {
+	prefix := "ip:enrichment:company"
+	suffix := fmt.Sprintf("userIP:%v", clientIP)
+	return cache.NewKey(projectId, prefix, suffix)
+}
+
+func SetCompanyEnrichmentCacheResult(projectId int64, clientIP string, result CompanyEnrichmentCacheResult) {
+	logCtx := log.WithFields(log.Fields{
+		"project_id": projectId,
+		"user_ip":    clientIP,
+	})
+	if clientIP == "" || len(result.Properties) == 0 {
+		return
+	}
+
+	cacheKey, err := GetCompanyEnrichmentCacheKey(projectId, clientIP)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get cache key")
+		return
+	}
+
+	resultString, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	err = cacheRedis.SetPersistent(cacheKey, string(resultString), companyEnrichmentCacheInvalidationDuration)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to set cache for company enrichment")
+	}
+}
+
+func GetCompanyEnrichmentCacheResult(projectId int64, clientIP string) (CompanyEnrichmentCacheResult, int) {
+	var cacheResult CompanyEnrichmentCacheResult
+	logCtx := log.WithFields(log.Fields{
+		"project_id": projectId,
+		"user_ip":    clientIP,
+	})
+	if clientIP == "" {
+		return cacheResult, http.StatusNotFound
+	}
+
+	cacheKey, err := GetCompanyEnrichmentCacheKey(projectId, clientIP)
+	if err != nil {
+		logCtx.WithError(err).Error("Error getting cache key")
+		return cacheResult, http.StatusInternalServerError
+	}
+
+	result, err := cacheRedis.GetPersistent(cacheKey)
+	if err == redis.ErrNil {
+		return cacheResult, http.StatusNotFound
+	} else if err != nil {
+		logCtx.WithError(err).Error("Error getting key from redis")
+		return cacheResult, http.StatusInternalServerError
+	}
+	err = json.Unmarshal([]byte(result), &cacheResult)
+	if err != nil {
+		logCtx.WithError(err).Errorf("Error decoding redis result %v", result)
+		return cacheResult, http.StatusInternalServerError
+	}
+	return cacheResult, http.StatusFound
+}
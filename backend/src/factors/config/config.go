@@ -42,6 +42,7 @@ import (
 	"factors/metrics"
 	U "factors/util"
 
+	"factors/pattern_server/membership"
 	"factors/services/error_collector"
 	serviceEtcd "factors/services/etcd"
 	"factors/services/mailer"
@@ -49,6 +50,7 @@ import (
 
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 
 	cache "github.com/hashicorp/golang-lru"
 )
@@ -140,6 +142,7 @@ type Configuration struct {
 	DuplicateQueueRedisPort                        int
 	EnableSDKAndIntegrationRequestQueueDuplication bool
 	EtcdEndpoints                                  []string
+	PatternServerMembershipFile                    string
 	GeolocationFile                                string
 	DeviceDetectorPath                             string
 	FactorsSixSignalAPIKey                         string
@@ -218,6 +221,7 @@ type Configuration struct {
 	AttributionCommonFlow                                string
 	AttributionDBCacheLookup                             string
 	AttributionDebugKPI                                  string
+	AttributionDayPartials                               string
 	DisableDashboardQueryDBExecution                     bool
 	AllowedHubspotGroupsByProjectIDs                     string
 	EnableFilterOptimisation                             bool
@@ -376,6 +380,8 @@ type Configuration struct {
 	EnableSalesforceRelationshipPullByProjectID          string
 	SixSignalV3ProjectIds                                string
 	ChatDebug                                            int
+	EnableSelfHostedChartRendering                       bool
+	ChartImageURLSigningKey                              string
 }
 
 type Services struct {
@@ -516,6 +522,17 @@ func (service *Services) addPatternServer(key, addr string) {
 	service.patternServers[key] = addr
 }
 
+func (service *Services) setPatternServers(patternServers []serviceEtcd.KV) {
+	log.WithField("pattern_servers", patternServers).Info("Set Pattern Servers")
+	service.patternServersLock.Lock()
+	defer service.patternServersLock.Unlock()
+
+	service.patternServers = make(map[string]string, len(patternServers))
+	for _, ps := range patternServers {
+		service.patternServers[ps.Key] = ps.Value
+	}
+}
+
 func (service *Services) removePatternServer(key string) {
 	log.Infof("Remove Pattern Server Key: %s", key)
 	service.patternServersLock.Lock()
@@ -833,6 +850,16 @@ func initAppServerServices(config *Configuration) error {
 
 	InitChargebeeObject(config.ChargebeeApiKey, config.ChargebeeSiteName)
 
+	// Pattern servers are discovered from the membership file, when given, instead of etcd.
+	if config.PatternServerMembershipFile != "" {
+		err = initPatternServersFromMembershipFile(config.PatternServerMembershipFile)
+		if err != nil {
+			log.WithError(err).Error("Failed to initialize pattern servers from membership file.")
+			return err
+		}
+		return nil
+	}
+
 	// Etcd is now an optional service.
 	// Any failure should still continue instead of crashing.
 	err = InitEtcd(config.EtcdEndpoints)
@@ -1178,19 +1205,54 @@ func KillDBQueriesOnExit() {
 	go func() {
 		select {
 		case <-c:
-			if GetServices().DBContext != nil && GetServices().DBContextCancel != nil {
-				(*GetServices().DBContextCancel)()
-			}
-
-			if GetServices().DBContext2 != nil && GetServices().DBContextCancel2 != nil {
-				(*GetServices().DBContextCancel2)()
-			}
-
+			CancelDBContexts()
 			signal.Stop(c)
 		}
 	}()
 }
 
+// CancelDBContexts cancels the db contexts, which kills the queries in progress.
+func CancelDBContexts() {
+	if GetServices().DBContext != nil && GetServices().DBContextCancel != nil {
+		(*GetServices().DBContextCancel)()
+	}
+
+	if GetServices().DBContext2 != nil && GetServices().DBContextCancel2 != nil {
+		(*GetServices().DBContextCancel2)()
+	}
+}
+
+// serverShutdownTimeout is the max wait for the requests in progress on shutdown.
+const serverShutdownTimeout = 30 * time.Second
+
+// RunServerWithGracefulShutdown serves the handler on the port till SIGTERM or SIGINT and
+// completes the requests in progress before returning, for the deferred calls of the caller to run.
+func RunServerWithGracefulShutdown(handler http.Handler, port int) {
+	server := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: handler}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-serverErr:
+		log.WithError(err).Error("Server stopped.")
+		return
+	case <-quit:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithError(err).Error("Failed to shutdown server gracefully.")
+	}
+}
+
 func InitDB(config Configuration) error {
 	if !IsConfigInitialized() {
 		log.Fatal("Config not initialised on InitDB.")
@@ -1220,6 +1282,14 @@ func InitFilemanager(bucketName string, env string, config *Configuration) {
 	}
 }
 
+// InitLocalFSFilemanager keeps the bucket under rootDir on the local filesystem,
+// with the same layout as cloud storage.
+func InitLocalFSFilemanager(rootDir, bucketName string, config *Configuration) {
+	config.CloudManager = serviceLocalFS.New(rootDir, bucketName)
+	log.WithField("root_dir", rootDir).WithField("bucket_name", bucketName).
+		Info("Initialised local filesystem file manager.")
+}
+
 func InitRedis(host string, port int) {
 	initRedisConnection(host, port, false, false, 300, 100)
 }
@@ -1673,6 +1743,27 @@ func watchPatternServers(psUpdateChannel clientv3.WatchChan) {
 	}
 }
 
+// initPatternServersFromMembershipFile uses the pattern servers on the static membership
+// file, which is watched for changes, for running pattern servers without etcd.
+func initPatternServersFromMembershipFile(membershipFile string) error {
+	psMembership, err := membership.NewStaticMembership(membershipFile, membership.DefaultStaticPollInterval)
+	if err != nil {
+		return err
+	}
+
+	patternServers, _ := psMembership.DiscoverPatternServers()
+	services.setPatternServers(patternServers)
+	configuration.PatternServerMembershipFile = membershipFile
+
+	go func() {
+		for range psMembership.WatchPatternServers() {
+			patternServers, _ := psMembership.DiscoverPatternServers()
+			services.setPatternServers(patternServers)
+		}
+	}()
+	return nil
+}
+
 func InitAppServer(config *Configuration) error {
 	if !IsConfigInitialized() {
 		log.Fatal("Config not initialised on Init.")
@@ -2212,6 +2303,27 @@ func IsAllowedAttributionDBCacheLookup(projectID int64) bool {
 	return false
 }
 
+// IsAllowedAttributionDayPartials - Checks if attribution queries are computed by merging per day partials.
+func IsAllowedAttributionDayPartials(projectID int64) bool {
+	if configuration.AttributionDayPartials == "" {
+		return false
+	}
+
+	if configuration.AttributionDayPartials == "*" {
+		return true
+	}
+
+	projectIDStr := fmt.Sprintf("%d", projectID)
+	projectIDs := strings.Split(configuration.AttributionDayPartials, ",")
+	for i := range projectIDs {
+		if projectIDs[i] == projectIDStr {
+			return true
+		}
+	}
+
+	return false
+}
+
 func GetOtpKeyWithQueryCheckEnabled() bool {
 	return configuration.OtpKeyWithQueryCheckEnabled
 }
@@ -3025,6 +3137,17 @@ func SkipKpiResultValidation() bool {
 	return configuration.SkipKpiResultValidation
 }
 
+// IsSelfHostedChartRenderingEnabled renders the images of the shared reports in-process,
+// instead of sending the data to quickchart. Falls back to quickchart, when the key to
+// sign the urls of the images is not configured.
+func IsSelfHostedChartRenderingEnabled() bool {
+	return configuration.EnableSelfHostedChartRendering && configuration.ChartImageURLSigningKey != ""
+}
+
+func GetChartImageURLSigningKey() string {
+	return configuration.ChartImageURLSigningKey
+}
+
 func EnableSixSignalGroupByProjectID(projectID int64) bool {
 	allProjects, allowedProjectIDs, _ := GetProjectsFromListWithAllProjectSupport(GetConfig().EnableSixSignalGroupByProjectID, "")
 	if allProjects {
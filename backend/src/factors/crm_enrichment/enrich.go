@@ -15,13 +15,20 @@ import (
 )
 
 type CRMSourceConfig struct {
-	projectID          int64
-	sourceAlias        string
+	projectID   int64
+	source      U.CRMSource
+	sourceAlias string
+	// source and request source used on the sdk calls.
+	trackSource        string
+	requestSource      int
 	objectTypeAlias    map[int]string
 	userTypes          map[int]bool
 	groupTypes         map[int]bool
 	activityTypes      map[int]bool
 	recordProcessLimit int
+	// smart event type of the source, smart events are created only if set.
+	smartEventType  string
+	smartEventNames map[string][]CRMSmartEventName
 }
 
 const (
@@ -87,7 +94,7 @@ func NewCRMEnrichmentConfig(sourceAlias string, sourceObjectTypeAndAlias map[int
 		return nil, errors.New("invalid source on request source mapping")
 	}
 
-	_, err := model.GetCRMSourceByAliasName(sourceAlias)
+	crmSource, err := model.GetCRMSourceByAliasName(sourceAlias)
 	if err != nil {
 		return nil, errors.New("invalid source")
 	}
@@ -127,7 +134,10 @@ func NewCRMEnrichmentConfig(sourceAlias string, sourceObjectTypeAndAlias map[int
 	}
 
 	sourceConfig := &CRMSourceConfig{
+		source:             crmSource,
 		sourceAlias:        sourceAlias,
+		trackSource:        sourceAlias,
+		requestSource:      source,
 		objectTypeAlias:    sourceObjectTypeAndAlias,
 		userTypes:          userTypes,
 		groupTypes:         groupTypes,
@@ -138,6 +148,31 @@ func NewCRMEnrichmentConfig(sourceAlias string, sourceObjectTypeAndAlias map[int
 	return sourceConfig, nil
 }
 
+// NewCustomCRMEnrichmentConfig returns the config for the custom source registered through the CRM API.
+// Contacts and leads are enriched as users and activities as events of the contact or lead.
+func NewCustomCRMEnrichmentConfig(customSource *model.CRMCustomSource, recordProcessLimit int) (*CRMSourceConfig, error) {
+	if customSource == nil || customSource.ProjectID == 0 || !model.IsCRMCustomSource(customSource.Source) {
+		return nil, errors.New("invalid custom source")
+	}
+
+	if err := model.ValidateCRMCustomSourceName(customSource.Name); err != nil {
+		return nil, err
+	}
+
+	return &CRMSourceConfig{
+		projectID:          customSource.ProjectID,
+		source:             customSource.Source,
+		sourceAlias:        customSource.GetSourceAlias(),
+		trackSource:        model.UserSourceCustomCRMString,
+		requestSource:      model.UserSourceCustomCRM,
+		objectTypeAlias:    model.CRMCustomSourceObjectTypeAlias,
+		userTypes:          model.CRMCustomSourceUserTypes,
+		activityTypes:      model.CRMCustomSourceActivityTypes,
+		recordProcessLimit: recordProcessLimit,
+		smartEventType:     model.TYPE_CRM_CUSTOM,
+	}, nil
+}
+
 func getMinimumTimestampForSync(projectID int64, source U.CRMSource, minTimestampForSync int64) (int64, int) {
 	if minTimestampForSync > 0 {
 		return minTimestampForSync, http.StatusOK
@@ -208,11 +243,7 @@ func enrichAllWorker(project *model.Project, wg *sync.WaitGroup, enrichStatus *e
 }
 
 func getAllCRMEventNames(projectID int64, config *CRMSourceConfig) ([]string, error) {
-	source, err := model.GetCRMSourceByAliasName(config.sourceAlias)
-	if err != nil {
-		return nil, err
-	}
-
+	source := config.source
 	crmUsersTypeAndAction, errCode := store.GetStore().GetCRMUsersTypeAndAction(projectID, source)
 	if errCode != http.StatusFound && errCode != http.StatusNotFound {
 		return nil, errors.New("Failed to get crm users type and action.")
@@ -365,14 +396,9 @@ func Enrich(projectID int64, sourceConfig *CRMSourceConfig, batchSize int, minTi
 	}
 
 	sourceConfig.projectID = projectID
-	source, err := model.GetCRMSourceByAliasName(sourceConfig.sourceAlias)
-	if err != nil {
-		logCtx.WithError(err).Error("Failed to get source alias.")
-		return []EnrichStatus{{Status: U.CRM_SYNC_STATUS_FAILURES}}
-	}
-
+	source := sourceConfig.source
 	if !model.AllowedCRMBySource(source) {
-		logCtx.WithError(err).Error("Invalid source.")
+		logCtx.Error("Invalid source.")
 		return []EnrichStatus{{Status: U.CRM_SYNC_STATUS_FAILURES}}
 	}
 
@@ -381,14 +407,18 @@ func Enrich(projectID int64, sourceConfig *CRMSourceConfig, batchSize int, minTi
 	project, status := store.GetStore().GetProject(sourceConfig.projectID)
 	if status != http.StatusFound {
 		if status == http.StatusNotFound {
-			logCtx.WithError(err).Error("Invalid project_id.")
+			logCtx.Error("Invalid project_id.")
 		} else {
-			logCtx.WithError(err).Error("Failed to get project.")
+			logCtx.Error("Failed to get project.")
 		}
 
 		return []EnrichStatus{{Status: U.CRM_SYNC_STATUS_FAILURES}}
 	}
 
+	if sourceConfig.smartEventType != "" {
+		sourceConfig.smartEventNames = getCRMSmartEventNames(projectID, sourceConfig)
+	}
+
 	projectStatus := make([]EnrichStatus, 0)
 	minTimestamp, status := getMinimumTimestampForSync(projectID, source, minTimestampForSync)
 	if status != http.StatusOK {
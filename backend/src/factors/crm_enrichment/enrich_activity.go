@@ -85,13 +85,11 @@ func enrichCRMActivity(project *model.Project, config *CRMSourceConfig, crmActiv
 		Name:            getActivityEventName(config.sourceAlias, crmActivity.Name),
 		ProjectId:       project.ID,
 		EventProperties: *enProperties,
-		RequestSource:   model.UserSourceMap[config.sourceAlias],
+		RequestSource:   config.requestSource,
 		Timestamp:       crmActivity.Timestamp,
 	}
 
-	source, _ := model.GetCRMSourceByAliasName(config.sourceAlias)
-
-	userID, err := getActivityAssociatedUserID(project.ID, source, config, crmActivity)
+	userID, err := getActivityAssociatedUserID(project.ID, config.source, config, crmActivity)
 	if err != nil {
 		logCtx.WithError(err).Error("Failed to get user for associating activity.")
 		return http.StatusInternalServerError
@@ -99,7 +97,7 @@ func enrichCRMActivity(project *model.Project, config *CRMSourceConfig, crmActiv
 
 	trackPayload.UserId = userID
 
-	status, trackResponse := sdk.Track(project.ID, trackPayload, true, config.sourceAlias, typeAlias)
+	status, trackResponse := sdk.Track(project.ID, trackPayload, true, config.trackSource, typeAlias)
 	if status != http.StatusOK && status != http.StatusFound && status != http.StatusNotModified {
 		logCtx.WithFields(log.Fields{"error": trackResponse.Error, "message": trackResponse.Message}).
 			Error("Failed to create activity event.")
@@ -111,7 +109,7 @@ func enrichCRMActivity(project *model.Project, config *CRMSourceConfig, crmActiv
 		userID = trackResponse.UserId
 	}
 
-	_, status = store.GetStore().UpdateCRMActivityAsSynced(project.ID, source, crmActivity, syncID, userID)
+	_, status = store.GetStore().UpdateCRMActivityAsSynced(project.ID, config.source, crmActivity, syncID, userID)
 	if status != http.StatusAccepted {
 		logCtx.Error("Failed to mark crm activity as synced.")
 		return http.StatusInternalServerError
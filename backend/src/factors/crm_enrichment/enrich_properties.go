@@ -27,7 +27,7 @@ func getBatchedPropertiesByTable(projectID int64, properties []model.CRMProperty
 
 func SyncProperties(projectID int64, sourceConfig *CRMSourceConfig) []EnrichStatus {
 
-	properties, status := store.GetStore().GetCRMPropertiesForSync(projectID)
+	properties, status := store.GetStore().GetCRMPropertiesForSync(projectID, sourceConfig.source)
 	if status != http.StatusFound {
 		if status == http.StatusNotFound {
 			return nil
@@ -151,9 +151,7 @@ func syncUserProperty(projectID int64, property *model.CRMProperty, sourceConfig
 		}
 	}
 
-	source, _ := model.GetCRMSourceByAliasName(sourceConfig.sourceAlias)
-
-	_, status := store.GetStore().UpdateCRMProperyAsSynced(projectID, source, property)
+	_, status := store.GetStore().UpdateCRMProperyAsSynced(projectID, sourceConfig.source, property)
 
 	if status != http.StatusAccepted {
 		logCtx.Error("Failed to mark crm properties as synced.")
@@ -178,7 +176,7 @@ func getAllPropertiesObjectType(properties []*model.CRMProperty) []int {
 
 func syncAllActivityProperties(projectID int64, properties []*model.CRMProperty, sourceConfig *CRMSourceConfig) bool {
 	types := getAllPropertiesObjectType(properties)
-	typeNames, status := store.GetStore().GetActivitiesDistinctEventNamesByType(projectID, types)
+	typeNames, status := store.GetStore().GetActivitiesDistinctEventNamesByType(projectID, sourceConfig.source, types)
 	if status != http.StatusFound {
 		log.WithFields(log.Fields{"project_id": projectID, "properties": properties, "err_code": status}).
 			Error("Failed to get name for activites properties.")
@@ -257,8 +255,7 @@ func syncActivityProperty(projectID int64, property *model.CRMProperty, activity
 		}
 	}
 
-	source, _ := model.GetCRMSourceByAliasName(sourceConfig.sourceAlias)
-	_, status := store.GetStore().UpdateCRMProperyAsSynced(projectID, source, property)
+	_, status := store.GetStore().UpdateCRMProperyAsSynced(projectID, sourceConfig.source, property)
 
 	if status != http.StatusAccepted {
 		logCtx.Error("Failed to mark crm properties as synced.")
@@ -0,0 +1,177 @@
+package crm_enrichment
+
+import (
+	"errors"
+	C "factors/config"
+	"factors/model/model"
+	"factors/model/store"
+	"factors/sdk"
+	U "factors/util"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type CRMSmartEventName struct {
+	EventName   string
+	EventNameID string
+	Filter      *model.SmartCRMEventFilter
+	Type        string
+}
+
+// getCRMSmartEventNames returns the smart event names of the source by object type.
+func getCRMSmartEventNames(projectID int64, config *CRMSourceConfig) map[string][]CRMSmartEventName {
+	logCtx := log.WithFields(log.Fields{"project_id": projectID, "source": config.sourceAlias})
+
+	smartEventNames := make(map[string][]CRMSmartEventName)
+
+	eventNames, status := store.GetStore().GetSmartEventFilterEventNames(projectID, false)
+	if status != http.StatusFound {
+		if status != http.StatusNotFound {
+			logCtx.Error("Failed to get smart event filter event names.")
+		}
+		return smartEventNames
+	}
+
+	for i := range eventNames {
+		if eventNames[i].Type != config.smartEventType {
+			continue
+		}
+
+		filterExp, err := model.GetDecodedSmartEventFilterExp(eventNames[i].FilterExpr)
+		if err != nil {
+			if err == model.ErrorSmartEventFiterEmptyString {
+				logCtx.WithError(err).Warn("Empty string on smart event filter.")
+			} else {
+				logCtx.WithError(err).Error("Failed to decode smart event filter expression.")
+			}
+			continue
+		}
+
+		if filterExp.Source != config.sourceAlias {
+			continue
+		}
+
+		smartEventNames[filterExp.ObjectType] = append(smartEventNames[filterExp.ObjectType], CRMSmartEventName{
+			EventName:   eventNames[i].Name,
+			EventNameID: eventNames[i].ID,
+			Filter:      filterExp,
+			Type:        eventNames[i].Type,
+		})
+	}
+
+	return smartEventNames
+}
+
+func getCRMSmartEventTimestampFromField(propertyName string, properties *map[string]interface{}) (int64, error) {
+	value, exists := (*properties)[propertyName]
+	if !exists || value == nil || value == "" {
+		return 0, errors.New("field missing")
+	}
+
+	timestamp, err := U.GetPropertyValueAsFloat64(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if timestamp <= 0 {
+		return 0, errors.New("invalid timestamp")
+	}
+
+	return model.GetCRMCustomTimestampInSecs(int64(timestamp)), nil
+}
+
+// trackCRMUserSmartEvents creates the smart events of the source for the crm user,
+// using the last synced state of the crm user as previous properties.
+func trackCRMUserSmartEvents(projectID int64, config *CRMSourceConfig, crmUser *model.CRMUser, userTypeAlias string,
+	currentProperties *map[string]interface{}, userID, eventID string) {
+	logCtx := log.WithFields(log.Fields{"project_id": projectID, "source": config.sourceAlias,
+		"crm_user_id": crmUser.ID, "type": userTypeAlias, "user_id": userID})
+
+	smartEventNames := config.smartEventNames[userTypeAlias]
+	if len(smartEventNames) == 0 {
+		return
+	}
+
+	var prevProperties *map[string]interface{}
+	for i := range smartEventNames {
+		if smartEventNames[i].Filter == nil {
+			continue
+		}
+
+		if !model.CRMFilterEvaluator(projectID, currentProperties, nil, smartEventNames[i].Filter, model.CompareStateCurr) {
+			continue
+		}
+
+		// previous properties are fetched once, only if any of the filter passes on current properties.
+		if prevProperties == nil {
+			prevCRMUser, status := store.GetStore().GetLastSyncedCRMUserBeforeTimestamp(projectID, config.source, crmUser.ID,
+				crmUser.Type, crmUser.Timestamp)
+			if status != http.StatusFound && status != http.StatusNotFound {
+				logCtx.Error("Failed to get last synced crm user for smart event.")
+				return
+			}
+
+			prevProperties = &map[string]interface{}{}
+			if status == http.StatusFound {
+				if err := U.DecodePostgresJsonbToStructType(prevCRMUser.Properties, prevProperties); err != nil {
+					logCtx.WithError(err).Error("Failed to decode previous crm user properties for smart event.")
+					return
+				}
+			}
+		}
+
+		if !model.CRMFilterEvaluator(projectID, currentProperties, prevProperties, smartEventNames[i].Filter, model.CompareStateBoth) {
+			continue
+		}
+
+		var properties map[string]interface{}
+		model.FillSmartEventCRMProperties(&properties, currentProperties, prevProperties, smartEventNames[i].Filter)
+		model.AddSmartEventReferenceMeta(&properties, eventID)
+
+		trackPayload := &sdk.TrackPayload{
+			ProjectId:       projectID,
+			Name:            smartEventNames[i].EventName,
+			EventProperties: properties,
+			SmartEventType:  smartEventNames[i].Type,
+			UserId:          userID,
+			RequestSource:   config.requestSource,
+			Timestamp:       crmUser.Timestamp + 1,
+		}
+
+		timestampReferenceField := smartEventNames[i].Filter.TimestampReferenceField
+		if timestampReferenceField != model.TimestampReferenceTypeDocument {
+			fieldTimestamp, err := getCRMSmartEventTimestampFromField(timestampReferenceField, currentProperties)
+			if err != nil {
+				logCtx.WithField("timestamp_reference_field", timestampReferenceField).
+					WithError(err).Error("Failed to get timestamp from reference field.")
+			} else {
+				trackPayload.Timestamp = fieldTimestamp + 1
+			}
+		}
+
+		if C.IsDryRunCRMSmartEvent() {
+			logCtx.WithFields(log.Fields{"properties": properties, "event_name": trackPayload.Name,
+				"filter_exp": *smartEventNames[i].Filter, "smart_event_timestamp": trackPayload.Timestamp}).
+				Info("Dry run smart event creation.")
+			continue
+		}
+
+		// smart event of the same reference event is not created again on re-enrichment.
+		exist, err := store.GetStore().IsSmartEventAlreadyExist(projectID, userID, smartEventNames[i].EventNameID,
+			eventID, trackPayload.Timestamp)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to validate existing crm smart event.")
+			continue
+		}
+		if exist {
+			continue
+		}
+
+		status, response := sdk.Track(projectID, trackPayload, true, config.trackSource, "")
+		if status != http.StatusOK && status != http.StatusFound && status != http.StatusNotModified {
+			logCtx.WithFields(log.Fields{"event_name": trackPayload.Name, "message": response.Error}).
+				Error("Failed to create crm smart event.")
+		}
+	}
+}
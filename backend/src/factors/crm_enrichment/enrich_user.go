@@ -75,14 +75,14 @@ func GetCRMEventNameByAction(source, objectType string, action model.CRMAction)
 	return ""
 }
 
-func createOrGetUserByAction(projectID int64, sourceAlias string, id string, userType int, action model.CRMAction, timestamp int64,
+func createOrGetUserByAction(projectID int64, config *CRMSourceConfig, id string, userType int, action model.CRMAction, timestamp int64,
 	customerUserID string) (string, error) {
 	if action == model.CRMActionCreated {
 		createUserID, status := store.GetStore().CreateUser(&model.User{
 			ProjectId:      projectID,
 			CustomerUserId: customerUserID,
 			JoinTimestamp:  timestamp,
-			Source:         model.GetRequestSourcePointer(model.UserSourceMap[sourceAlias]),
+			Source:         model.GetRequestSourcePointer(config.requestSource),
 		})
 		if status != http.StatusCreated {
 			return "", errors.New("failed to create user for crm user")
@@ -93,8 +93,7 @@ func createOrGetUserByAction(projectID int64, sourceAlias string, id string, use
 
 	userID := ""
 	if action == model.CRMActionUpdated {
-		source, _ := model.GetCRMSourceByAliasName(sourceAlias)
-		createdUser, status := store.GetStore().GetCRMUserByTypeAndAction(projectID, source, id, userType, model.CRMActionCreated)
+		createdUser, status := store.GetStore().GetCRMUserByTypeAndAction(projectID, config.source, id, userType, model.CRMActionCreated)
 		if status != http.StatusFound {
 			return "", errors.New("failed to get user from crm user record")
 		}
@@ -112,7 +111,7 @@ func createOrGetUserByAction(projectID int64, sourceAlias string, id string, use
 			UserId:         userID,
 			CustomerUserId: customerUserID,
 			Timestamp:      timestamp,
-			RequestSource:  model.UserSourceMap[sourceAlias],
+			RequestSource:  config.requestSource,
 		}, false)
 
 		if status != http.StatusOK {
@@ -153,13 +152,13 @@ func enrichUser(project *model.Project, config *CRMSourceConfig, crmUser *model.
 		Name:            eventName,
 		EventProperties: *enProperties,
 		UserProperties:  *enProperties,
-		RequestSource:   model.UserSourceMap[config.sourceAlias],
+		RequestSource:   config.requestSource,
 		Timestamp:       crmUser.Timestamp,
 	}
 
 	customerUserID := getUserCustomerUserID(project.ID, crmUser)
 
-	userID, err := createOrGetUserByAction(project.ID, config.sourceAlias, crmUser.ID, crmUser.Type, crmUser.Action, crmUser.Timestamp, customerUserID)
+	userID, err := createOrGetUserByAction(project.ID, config, crmUser.ID, crmUser.Type, crmUser.Action, crmUser.Timestamp, customerUserID)
 
 	if err != nil {
 		logCtx.WithError(err).Error("Failed to get user id from crm user")
@@ -168,7 +167,7 @@ func enrichUser(project *model.Project, config *CRMSourceConfig, crmUser *model.
 
 	trackPayload.UserId = userID
 
-	status, trackResponse := sdk.Track(project.ID, trackPayload, true, config.sourceAlias, userTypeAlias)
+	status, trackResponse := sdk.Track(project.ID, trackPayload, true, config.trackSource, userTypeAlias)
 	if status != http.StatusOK && status != http.StatusFound && status != http.StatusNotModified {
 		logCtx.WithFields(log.Fields{"message": trackResponse.Error, "event_name": eventName}).Error("Failed to create crm user event")
 		return http.StatusInternalServerError
@@ -179,8 +178,11 @@ func enrichUser(project *model.Project, config *CRMSourceConfig, crmUser *model.
 		userID = trackResponse.UserId
 	}
 
-	source, _ := model.GetCRMSourceByAliasName(config.sourceAlias)
-	_, status = store.GetStore().UpdateCRMUserAsSynced(project.ID, source, crmUser, userID, syncID)
+	if config.smartEventType != "" {
+		trackCRMUserSmartEvents(project.ID, config, crmUser, userTypeAlias, &properties, userID, syncID)
+	}
+
+	_, status = store.GetStore().UpdateCRMUserAsSynced(project.ID, config.source, crmUser, userID, syncID)
 	if status != http.StatusAccepted {
 		logCtx.Error("Failed to mark crm user as synced.")
 		return http.StatusInternalServerError
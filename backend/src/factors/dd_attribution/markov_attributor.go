@@ -0,0 +1,125 @@
+package dd_attribution
+
+import (
+	"math"
+)
+
+const (
+	StateStart      = "$dd_start"
+	StateConversion = "$dd_conversion"
+	StateNull       = "$dd_null"
+
+	maxIterations        = 500
+	convergenceTolerance = 1e-9
+)
+
+// MarkovChain is a first order markov chain built over touch point paths.
+// Every path starts from StateStart and ends in StateConversion or StateNull.
+type MarkovChain struct {
+	states      []string
+	transitions map[string]map[string]float64
+}
+
+// NewMarkovChain builds the transition probabilities from the given converted and
+// non-converted paths. Consecutive repetitions of the same touch point are collapsed.
+func NewMarkovChain(convertedPaths, nonConvertedPaths [][]string) *MarkovChain {
+	counts := make(map[string]map[string]float64)
+	addTransition := func(from, to string) {
+		if _, exists := counts[from]; !exists {
+			counts[from] = make(map[string]float64)
+		}
+		counts[from][to]++
+	}
+
+	addPath := func(path []string, endState string) {
+		prev := StateStart
+		for _, touchPoint := range path {
+			if touchPoint == prev {
+				continue
+			}
+			addTransition(prev, touchPoint)
+			prev = touchPoint
+		}
+		addTransition(prev, endState)
+	}
+
+	for _, path := range convertedPaths {
+		addPath(path, StateConversion)
+	}
+	for _, path := range nonConvertedPaths {
+		addPath(path, StateNull)
+	}
+
+	chain := MarkovChain{transitions: make(map[string]map[string]float64)}
+	for from, toCounts := range counts {
+		total := 0.0
+		for _, count := range toCounts {
+			total += count
+		}
+		chain.transitions[from] = make(map[string]float64)
+		for to, count := range toCounts {
+			chain.transitions[from][to] = count / total
+		}
+		chain.states = append(chain.states, from)
+	}
+	return &chain
+}
+
+// ConversionProbability returns the probability of reaching StateConversion from StateStart.
+// Any touch point in removedStates is treated as StateNull, i.e. the journey drops there.
+func (m *MarkovChain) ConversionProbability(removedStates map[string]bool) float64 {
+	probability := make(map[string]float64)
+	probability[StateConversion] = 1
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		maxDelta := 0.0
+		for _, state := range m.states {
+			if removedStates[state] {
+				continue
+			}
+
+			newProbability := 0.0
+			for to, transitionProbability := range m.transitions[state] {
+				if removedStates[to] {
+					continue
+				}
+				newProbability += transitionProbability * probability[to]
+			}
+			maxDelta = math.Max(maxDelta, math.Abs(newProbability-probability[state]))
+			probability[state] = newProbability
+		}
+		if maxDelta < convergenceTolerance {
+			break
+		}
+	}
+	return probability[StateStart]
+}
+
+// TouchPoints returns all the touch points seen in the chain.
+func (m *MarkovChain) TouchPoints() []string {
+	touchPoints := make([]string, 0)
+	for _, state := range m.states {
+		if state != StateStart {
+			touchPoints = append(touchPoints, state)
+		}
+	}
+	return touchPoints
+}
+
+// ComputeRemovalEffects returns the removal effect of each touch point, which is the relative
+// drop in conversion probability when the touch point is removed from the chain.
+func ComputeRemovalEffects(convertedPaths, nonConvertedPaths [][]string) map[string]float64 {
+	removalEffects := make(map[string]float64)
+	chain := NewMarkovChain(convertedPaths, nonConvertedPaths)
+
+	baseProbability := chain.ConversionProbability(nil)
+	if baseProbability == 0 {
+		return removalEffects
+	}
+
+	for _, touchPoint := range chain.TouchPoints() {
+		probability := chain.ConversionProbability(map[string]bool{touchPoint: true})
+		removalEffects[touchPoint] = 1 - probability/baseProbability
+	}
+	return removalEffects
+}
@@ -514,6 +514,8 @@ func getPropertiesToEvaluateAndInfo(projectID int64, displayCategory string, fil
 			kpiProperties = store.GetStore().GetPropertiesForMarketo(projectID, "")
 		case M.LeadSquaredLeadsDisplayCategory:
 			kpiProperties = store.GetStore().GetPropertiesForLeadSquared(projectID, "")
+		case M.CustomCRMUsersDisplayCategory:
+			kpiProperties = store.GetStore().GetPropertiesForCustomCRM(projectID, "")
 		default:
 			err := fmt.Errorf("no properties to evaluate for category: %s", displayCategory)
 			log.WithError(err).Error("unknown category")
@@ -542,4 +544,5 @@ var displayCategoryToFileTypeMap = map[string]string{
 	M.SalesforceOpportunitiesDisplayCategory: "users",
 	M.MarketoLeadsDisplayCategory:            "users",
 	M.LeadSquaredLeadsDisplayCategory:        "users",
+	M.CustomCRMUsersDisplayCategory:          "users",
 }
@@ -9,6 +9,7 @@ type FileManager interface {
 	GetWriter(dir, fileName string) (io.WriteCloser, error)
 	Get(path, fileName string) (io.ReadCloser, error)
 	GetObjectSize(dir, fileName string) (int64, error)
+	Delete(dir, fileName string) error
 	GetBucketName() string
 	ListFiles(path string) []string
 
@@ -85,6 +86,9 @@ type FileManager interface {
 	GetSixSignalAnalysisTempFileDir(id string, projectId int64) string
 	GetSixSignalAnalysisTempFilePathAndName(id string, projectId int64) (string, string)
 
+	//chart images
+	GetChartImageFilePathAndName(projectId int64, imageName string) (string, string)
+
 	//acc scoring
 	GetAccScoreDir(project_id int64) string
 	GetAccScoreUsers(project_id int64) string
@@ -60,7 +60,10 @@ require (
 	gopkg.in/yaml.v2 v2.4.0
 )
 
-require github.com/chargebee/chargebee-go/v3 v3.12.0
+require (
+	github.com/chargebee/chargebee-go/v3 v3.12.0
+	golang.org/x/image v0.14.0
+)
 
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
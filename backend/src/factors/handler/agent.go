@@ -204,6 +204,21 @@ func AgentInvite(c *gin.Context) {
 
 	invitedByAgentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
 
+	inviterPermissions, errCode := getLoggedInAgentPermissions(projectId, invitedByAgentUUID)
+	if errCode != http.StatusFound {
+		c.AbortWithStatus(errCode)
+		return
+	}
+
+	newProjectAgentRole := uint64(model.AGENT)
+	if model.IsValidRole(roleOfAgent) {
+		newProjectAgentRole = uint64(roleOfAgent)
+	}
+	if !model.IsRoleWithinPermissions(newProjectAgentRole, inviterPermissions) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invite is not allowed for a role above your role"})
+		return
+	}
+
 	createDefaultDashBoard := c.Query("create_dashboard")
 	var createDashboard bool = true // by default
 	if createDefaultDashBoard == "false" {
@@ -239,10 +254,6 @@ func AgentInvite(c *gin.Context) {
 		invitedAgent = resp.Agent
 	}
 
-	newProjectAgentRole := uint64(model.AGENT)
-	if roleOfAgent == model.ADMIN {
-		newProjectAgentRole = uint64(model.ADMIN)
-	}
 	var pam *model.ProjectAgentMapping
 	if createDashboard {
 		pam, errCode = store.GetStore().CreateProjectAgentMappingWithDependencies(
@@ -330,6 +341,11 @@ func AgentInviteBatch(c *gin.Context) {
 	}
 	projectId := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
 	invitedByAgentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	inviterPermissions, errCode := getLoggedInAgentPermissions(projectId, invitedByAgentUUID)
+	if errCode != http.StatusFound {
+		c.AbortWithStatus(errCode)
+		return
+	}
 	createDefaultDashBoard := c.Query("create_dashboard")
 	var createDashboard bool = true // by default
 	if createDefaultDashBoard == "false" {
@@ -347,6 +363,15 @@ func AgentInviteBatch(c *gin.Context) {
 		emailOfAgentToInvite := agentDetail.Email
 		roleOfAgent := agentDetail.Role
 
+		newProjectAgentRole := uint64(model.AGENT)
+		if model.IsValidRole(roleOfAgent) {
+			newProjectAgentRole = uint64(roleOfAgent)
+		}
+		if !model.IsRoleWithinPermissions(newProjectAgentRole, inviterPermissions) {
+			failedToInviteAgentIndexes[idx] = "Invite is not allowed for a role above your role"
+			continue
+		}
+
 		createProjectAgentMapping, errCode := store.GetStore().IsNewProjectAgentMappingCreationAllowed(projectId, emailOfAgentToInvite)
 		if errCode != http.StatusOK {
 			failedToInviteAgentIndexes[idx] = ""
@@ -380,11 +405,6 @@ func AgentInviteBatch(c *gin.Context) {
 			}
 			invitedAgent = resp.Agent
 		}
-		newProjectAgentRole := uint64(model.AGENT)
-
-		if roleOfAgent == model.ADMIN {
-			newProjectAgentRole = uint64(model.ADMIN)
-		}
 		var projectAgentMapping *model.ProjectAgentMapping
 		if createDashboard {
 			projectAgentMapping, errCode = store.GetStore().CreateProjectAgentMappingWithDependencies(
@@ -502,7 +522,7 @@ func AgentUpdate(c *gin.Context) {
 		return
 	}
 
-	if !(roleIDToUpdate == 1 || roleIDToUpdate == 2) {
+	if !model.IsValidRole(roleIDToUpdate) {
 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid RoleID"})
 		return
 	}
@@ -1159,6 +1179,23 @@ func UpdateAgentPassword(c *gin.Context) {
 	c.Status(errCode)
 }
 
+// getLoggedInAgentPermissions returns the permissions of the logged in agent on the project.
+func getLoggedInAgentPermissions(projectId int64, agentUUID string) ([]string, int) {
+	loggedInAgentPAM, errCode := store.GetStore().GetProjectAgentMapping(projectId, agentUUID)
+	if errCode != http.StatusFound {
+		if errCode == http.StatusNotFound {
+			return nil, http.StatusForbidden
+		}
+		return nil, http.StatusInternalServerError
+	}
+
+	permissions, errCode := store.GetStore().GetProjectAgentMappingPermissions(loggedInAgentPAM)
+	if errCode != http.StatusFound {
+		return nil, http.StatusInternalServerError
+	}
+	return permissions, http.StatusFound
+}
+
 func isAdmin(role uint64) bool {
 	if role == model.ADMIN {
 		return true
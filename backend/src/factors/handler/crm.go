@@ -48,6 +48,8 @@ func GetCRMObjectPropertiesHandler(c *gin.Context) {
 		crmResponsePayload.Categorical, crmResponsePayload.DateTime = store.GetStore().GetSalesforceObjectPropertiesName(projectID, objectType)
 	} else if source == model.SmartCRMEventSourceHubspot {
 		crmResponsePayload.Categorical, crmResponsePayload.DateTime = store.GetStore().GetHubspotObjectPropertiesName(projectID, objectType)
+	} else if model.IsCRMCustomSourceAlias(source) {
+		crmResponsePayload.Categorical, crmResponsePayload.DateTime = store.GetStore().GetCRMCustomSourceObjectPropertiesName(projectID, source, objectType)
 	}
 
 	c.JSON(http.StatusOK, crmResponsePayload)
@@ -85,6 +87,8 @@ func GetCRMObjectValuesByPropertyNameHandler(c *gin.Context) {
 		properties = store.GetStore().GetSalesforceObjectValuesByPropertyName(projectID, objectType, propertyName)
 	} else if source == model.SmartCRMEventSourceHubspot {
 		properties = store.GetStore().GetAllHubspotObjectValuesByPropertyName(projectID, objectType, propertyName)
+	} else if model.IsCRMCustomSourceAlias(source) {
+		properties = store.GetStore().GetCRMCustomSourceObjectValuesByPropertyName(projectID, source, objectType, propertyName)
 	}
 
 	for i, value := range properties {
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	mid "factors/middleware"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+)
+
+type CRMCustomUsersPayload struct {
+	Records []model.CRMCustomUserPayload `json:"records"`
+}
+
+type CRMCustomActivitiesPayload struct {
+	Records []model.CRMCustomActivityPayload `json:"records"`
+}
+
+type CRMCustomPropertiesPayload struct {
+	Records []model.CRMCustomPropertyPayload `json:"records"`
+}
+
+type CRMCustomResponse struct {
+	Source     string `json:"source"`
+	Created    int    `json:"created"`
+	Duplicates int    `json:"duplicates"`
+}
+
+// decodeCRMCustomPayload decodes the payload of the CRM API and returns the project and source
+// of the request. Aborts the request and returns false on failure.
+func decodeCRMCustomPayload(c *gin.Context, payload interface{}) (int64, string, bool) {
+	r := c.Request
+
+	logCtx := log.WithFields(log.Fields{
+		"reqId": U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID),
+	})
+
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"Error": "Invalid token on crm payload.",
+		})
+		return 0, "", false
+	}
+
+	source := c.Params.ByName("source")
+	if err := model.ValidateCRMCustomSourceName(source); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"Error": "Invalid source. " + err.Error(),
+		})
+		return 0, "", false
+	}
+
+	if r.Body == nil {
+		logCtx.Error("Invalid request. Request body unavailable.")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"Error": "Invalid request. Request body unavailable.",
+		})
+		return 0, "", false
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(payload); U.IsJsonError(err) {
+		logCtx.WithError(err).Error("CRM payload json decoding failed.")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"Error": "Invalid request. Json decoding failed.",
+		})
+		return 0, "", false
+	}
+
+	return projectID, source, true
+}
+
+func isValidCRMCustomRecordsCount(c *gin.Context, count int) bool {
+	if count == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"Error": "Invalid request. No records.",
+		})
+		return false
+	}
+
+	if count > model.MaxCRMCustomRecordsPerRequest {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"Error": fmt.Sprintf("Invalid request. Maximum %d records allowed per request.", model.MaxCRMCustomRecordsPerRequest),
+		})
+		return false
+	}
+
+	return true
+}
+
+// getRegisteredCRMCustomSource returns the custom source registered by the project admin.
+// Sources are not registered on push, as any crm:write key could use up the sources
+// allowed on the project.
+func getRegisteredCRMCustomSource(c *gin.Context, projectID int64, name string) (*model.CRMCustomSource, bool) {
+	customSource, status := store.GetStore().GetCRMCustomSourceByName(projectID, name)
+	if status == http.StatusNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"Error": "Source not registered. Register the source on the project settings.",
+		})
+		return nil, false
+	}
+
+	if status != http.StatusFound {
+		c.AbortWithStatusJSON(status, gin.H{
+			"Error": "Failed to get the source.",
+		})
+		return nil, false
+	}
+
+	return customSource, true
+}
+
+func abortWithInvalidCRMCustomRecord(c *gin.Context, index int, err error) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+		"Error": fmt.Sprintf("Invalid record at index %d. %s.", index, err.Error()),
+	})
+}
+
+// CreateCRMCustomUsersHandler godoc
+// @Summary Push contacts or leads of the custom crm source registered on the project.
+// @Tags SDK
+// @Accept  json
+// @Produce json
+// @Param source path string true "Source"
+// @Param request body handler.CRMCustomUsersPayload true "Records"
+// @Success 200 {object} handler.CRMCustomResponse
+// @Router /sdk/crm/{source}/users [post]
+// @Security ApiKeyAuth
+func CreateCRMCustomUsersHandler(c *gin.Context) {
+	var payload CRMCustomUsersPayload
+	projectID, source, ok := decodeCRMCustomPayload(c, &payload)
+	if !ok || !isValidCRMCustomRecordsCount(c, len(payload.Records)) {
+		return
+	}
+
+	// validate all the records before creating any record,
+	// for the request to be retried as a whole.
+	crmUsers := make([]*model.CRMUser, len(payload.Records))
+	for i := range payload.Records {
+		crmUser, err := payload.Records[i].GetCRMUser(projectID, 0)
+		if err != nil {
+			abortWithInvalidCRMCustomRecord(c, i, err)
+			return
+		}
+		crmUsers[i] = crmUser
+	}
+
+	customSource, ok := getRegisteredCRMCustomSource(c, projectID, source)
+	if !ok {
+		return
+	}
+
+	for i := range crmUsers {
+		crmUsers[i].Source = customSource.Source
+	}
+
+	response := CRMCustomResponse{Source: source}
+	for i := range crmUsers {
+		status, err := store.GetStore().CreateCRMUser(crmUsers[i])
+		if status == http.StatusConflict {
+			response.Duplicates++
+			continue
+		}
+
+		if status != http.StatusCreated {
+			log.WithFields(log.Fields{"project_id": projectID, "source": source, "id": crmUsers[i].ID}).
+				WithError(err).Error("Failed to create crm user of custom source.")
+			c.AbortWithStatusJSON(status, gin.H{
+				"Error": fmt.Sprintf("Failed to create record at index %d.", i),
+			})
+			return
+		}
+		response.Created++
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCRMCustomActivitiesHandler godoc
+// @Summary Push activities of the contacts or leads of the custom crm source registered on the project.
+// @Tags SDK
+// @Accept  json
+// @Produce json
+// @Param source path string true "Source"
+// @Param request body handler.CRMCustomActivitiesPayload true "Records"
+// @Success 200 {object} handler.CRMCustomResponse
+// @Router /sdk/crm/{source}/activities [post]
+// @Security ApiKeyAuth
+func CreateCRMCustomActivitiesHandler(c *gin.Context) {
+	var payload CRMCustomActivitiesPayload
+	projectID, source, ok := decodeCRMCustomPayload(c, &payload)
+	if !ok || !isValidCRMCustomRecordsCount(c, len(payload.Records)) {
+		return
+	}
+
+	crmActivities := make([]*model.CRMActivity, len(payload.Records))
+	for i := range payload.Records {
+		crmActivity, err := payload.Records[i].GetCRMActivity(projectID, 0)
+		if err != nil {
+			abortWithInvalidCRMCustomRecord(c, i, err)
+			return
+		}
+		crmActivities[i] = crmActivity
+	}
+
+	customSource, ok := getRegisteredCRMCustomSource(c, projectID, source)
+	if !ok {
+		return
+	}
+
+	for i := range crmActivities {
+		crmActivities[i].Source = customSource.Source
+	}
+
+	response := CRMCustomResponse{Source: source}
+	for i := range crmActivities {
+		status, err := store.GetStore().CreateCRMActivity(crmActivities[i])
+		if status == http.StatusConflict {
+			response.Duplicates++
+			continue
+		}
+
+		if status != http.StatusCreated {
+			log.WithFields(log.Fields{"project_id": projectID, "source": source, "id": crmActivities[i].ExternalActivityID}).
+				WithError(err).Error("Failed to create crm activity of custom source.")
+			c.AbortWithStatusJSON(status, gin.H{
+				"Error": fmt.Sprintf("Failed to create record at index %d.", i),
+			})
+			return
+		}
+		response.Created++
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCRMCustomPropertiesHandler godoc
+// @Summary Push labels and data types of the properties of the custom crm source registered on the project.
+// @Tags SDK
+// @Accept  json
+// @Produce json
+// @Param source path string true "Source"
+// @Param request body handler.CRMCustomPropertiesPayload true "Records"
+// @Success 200 {object} handler.CRMCustomResponse
+// @Router /sdk/crm/{source}/properties [post]
+// @Security ApiKeyAuth
+func CreateCRMCustomPropertiesHandler(c *gin.Context) {
+	var payload CRMCustomPropertiesPayload
+	projectID, source, ok := decodeCRMCustomPayload(c, &payload)
+	if !ok || !isValidCRMCustomRecordsCount(c, len(payload.Records)) {
+		return
+	}
+
+	crmProperties := make([]*model.CRMProperty, len(payload.Records))
+	for i := range payload.Records {
+		crmProperty, err := payload.Records[i].GetCRMProperty(projectID, 0)
+		if err != nil {
+			abortWithInvalidCRMCustomRecord(c, i, err)
+			return
+		}
+		crmProperties[i] = crmProperty
+	}
+
+	customSource, ok := getRegisteredCRMCustomSource(c, projectID, source)
+	if !ok {
+		return
+	}
+
+	for i := range crmProperties {
+		crmProperties[i].Source = customSource.Source
+	}
+
+	response := CRMCustomResponse{Source: source}
+	for i := range crmProperties {
+		status, err := store.GetStore().CreateCRMProperties(crmProperties[i])
+		if status == http.StatusConflict {
+			response.Duplicates++
+			continue
+		}
+
+		if status != http.StatusCreated {
+			log.WithFields(log.Fields{"project_id": projectID, "source": source, "name": crmProperties[i].Name}).
+				WithError(err).Error("Failed to create crm property of custom source.")
+			c.AbortWithStatusJSON(status, gin.H{
+				"Error": fmt.Sprintf("Failed to create record at index %d.", i),
+			})
+			return
+		}
+		response.Created++
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type CRMCustomSourcePayload struct {
+	Name string `json:"name"`
+}
+
+// RegisterCRMCustomSourceHandler godoc
+// @Summary Register the custom crm source on the project, allowed only for the project admins.
+// @Tags CRM
+// @Accept  json
+// @Produce json
+// @Param project_id path integer true "Project ID"
+// @Param request body handler.CRMCustomSourcePayload true "Source"
+// @Success 201 {object} model.CRMCustomSource
+// @Router /{project_id}/v1/crm_custom_sources [post]
+func RegisterCRMCustomSourceHandler(c *gin.Context) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project."})
+		return
+	}
+
+	loggedInAgentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	loggedInAgentPAM, errCode := store.GetStore().GetProjectAgentMapping(projectID, loggedInAgentUUID)
+	if errCode != http.StatusFound {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch loggedInAgentPAM"})
+		return
+	}
+
+	if loggedInAgentPAM.Role != model.ADMIN {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "operation denied for non-admins"})
+		return
+	}
+
+	var payload CRMCustomSourcePayload
+	decoder := json.NewDecoder(c.Request.Body)
+	if err := decoder.Decode(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request. Json decoding failed."})
+		return
+	}
+
+	customSource, status, errMsg := store.GetStore().CreateOrGetCRMCustomSource(projectID, payload.Name)
+	if status != http.StatusCreated && status != http.StatusFound {
+		c.AbortWithStatusJSON(status, gin.H{"error": errMsg})
+		return
+	}
+
+	c.JSON(status, customSource)
+}
+
+// GetCRMCustomSourcesHandler godoc
+// @Summary Get the custom crm sources registered on the project.
+// @Tags CRM
+// @Produce json
+// @Param project_id path integer true "Project ID"
+// @Success 200 {array} model.CRMCustomSource
+// @Router /{project_id}/v1/crm_custom_sources [get]
+func GetCRMCustomSourcesHandler(c *gin.Context) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project."})
+		return
+	}
+
+	customSources, status := store.GetStore().GetCRMCustomSources(projectID)
+	if status != http.StatusFound && status != http.StatusNotFound {
+		c.AbortWithStatusJSON(status, gin.H{"error": "Failed to get the custom crm sources."})
+		return
+	}
+
+	if customSources == nil {
+		customSources = []model.CRMCustomSource{}
+	}
+	c.JSON(http.StatusOK, customSources)
+}
@@ -839,7 +839,8 @@ func IntFacebookAddAccessTokenHandler(c *gin.Context) {
 }
 
 type LinkedinOauthCode struct {
-	Code string `json:"code"`
+	ProjectID string `json:"project_id"`
+	Code      string `json:"code"`
 }
 type LinkedinOauthToken struct {
 	AccessToken           string `json:"access_token"`
@@ -894,6 +895,7 @@ func IntLinkedinAuthHandler(c *gin.Context) {
 }
 
 type LinkedinAccountPayload struct {
+	ProjectId   string `json:"project_id"`
 	AccessToken string `json:"access_token"`
 }
 
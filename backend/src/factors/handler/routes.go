@@ -6,6 +6,7 @@ import (
 	V1 "factors/handler/v1"
 	mid "factors/middleware"
 	M "factors/model/model"
+	qc "factors/quickchart"
 	U "factors/util"
 	"fmt"
 	"net/http"
@@ -27,6 +28,8 @@ const ROUTE_VERSION_V1 = "/v1"
 const ROUTE_VERSION_V1_WITHOUT_SLASH = "v1"
 const ROUTE_COMMON_ROOT = "/common"
 const ROUTE_ACCOUNT_ROOT = "/sdk/account"
+const ROUTE_CRM_ROOT = "/sdk/crm"
+const ROUTE_PRIVATE_ROOT = "/private"
 
 func InitExternalAuth(r *gin.Engine, auth *Authenticator) {
 	routePrefix := C.GetRoutesURLPrefix() + "/oauth"
@@ -92,6 +95,8 @@ func InitAppRoutes(r *gin.Engine) {
 	r.POST(routePrefix+"/InsertTaskBeginRecord", mid.SetLoggedInAgentInternalOnly(), responseWrapper(V1.InsertTaskBeginRecordHandler))
 	r.POST(routePrefix+"/InsertTaskEndRecord", mid.SetLoggedInAgentInternalOnly(), responseWrapper(V1.InsertTaskEndRecordHandler))
 	r.GET("/hubspot/getcontact", V1.GetHubspotContactByEmail)
+	// images of the shared reports, accessed by the signed url.
+	r.GET(qc.ROUTE_CHART_IMAGES+"/:project_id/:image_name", V1.GetChartImageHandler)
 	r.GET(routePrefix+"/"+ROUTE_PROJECTS_ROOT_V1,
 		mid.SetLoggedInAgent(),
 		mid.SetAuthorizedProjectsByLoggedInAgent(),
@@ -122,6 +127,7 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.Use(mid.SetLoggedInAgent())
 	authRouteGroup.Use(mid.SetAuthorizedProjectsByLoggedInAgent())
 	authRouteGroup.Use(mid.ValidateLoggedInAgentHasAccessToRequestProject())
+	authRouteGroup.Use(mid.ValidateLoggedInAgentHasPermissionForRequest())
 
 	// Shareable link routes
 	shareRouteGroup := r.Group(routePrefix + ROUTE_PROJECTS_ROOT)
@@ -135,6 +141,14 @@ func InitAppRoutes(r *gin.Engine) {
 	shareRouteGroup.POST("/:project_id/profiles/query", mid.RequestRateLimiterMiddleware("PROFILES_QUERY", 200, 60), responseWrapper(ProfilesQueryHandler))
 	shareRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/kpi/query", mid.RequestRateLimiterMiddleware("KPI_QUERY", 200, 60), responseWrapper(V1.ExecuteKPIQueryHandler))
 
+	// Login of the viewers, not part of the project, to the restricted shares.
+	r.POST(routePrefix+ROUTE_PROJECTS_ROOT+"/:project_id/shareable_url/:query_id/login_link", mid.SetScopeProjectId(),
+		mid.ShareLoginRateLimiterMiddleware("SHARE_LOGIN_LINK", 10, 100, 3600), SendSharedEntityLoginLinkHandler)
+	r.POST(routePrefix+ROUTE_PROJECTS_ROOT+"/:project_id/shareable_url/:query_id/verify", mid.SetScopeProjectId(),
+		mid.ShareLoginRateLimiterMiddleware("SHARE_LOGIN_VERIFY", 10, 0, 3600), VerifySharedEntityLoginLinkHandler)
+	r.POST(routePrefix+ROUTE_PROJECTS_ROOT+"/:project_id/shareable_url/:query_id/password", mid.SetScopeProjectId(),
+		mid.ShareLoginRateLimiterMiddleware("SHARE_LOGIN_PASSWORD", 10, 100, 3600), LoginSharedEntityWithPasswordHandler)
+
 	// Predefined dashboards and queries
 	// shareRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/predefined_dashboards", )
 	shareRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/predefined_dashboards/:internal_id/config", responseWrapper(V1.GetPredefinedDashboardConfigsHandler))
@@ -197,6 +211,7 @@ func InitAppRoutes(r *gin.Engine) {
 	// shareable url endpoints
 	authRouteGroup.GET("/:project_id/shareable_url", mid.FeatureMiddleware([]string{M.FEATURE_SHAREABLE_URL}), GetShareableURLsHandler)
 	authRouteGroup.POST("/:project_id/shareable_url", mid.FeatureMiddleware([]string{M.FEATURE_SHAREABLE_URL}), CreateShareableURLHandler)
+	authRouteGroup.PUT("/:project_id/shareable_url/:share_id", mid.FeatureMiddleware([]string{M.FEATURE_SHAREABLE_URL}), UpdateShareableURLHandler)
 	authRouteGroup.DELETE("/:project_id/shareable_url/:share_id", mid.FeatureMiddleware([]string{M.FEATURE_SHAREABLE_URL}), DeleteShareableURLHandler)
 	authRouteGroup.DELETE("/:project_id/shareable_url/revoke/:query_id", mid.FeatureMiddleware([]string{M.FEATURE_SHAREABLE_URL}), RevokeShareableURLHandler)
 
@@ -325,6 +340,7 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.POST("/:project_id/segments", mid.FeatureMiddleware([]string{M.FEATURE_SEGMENT}), CreateSegmentHandler)
 	authRouteGroup.GET("/:project_id/segments", mid.FeatureMiddleware([]string{M.FEATURE_SEGMENT}), responseWrapper(GetSegmentsHandler))
 	authRouteGroup.GET("/:project_id/segments/:id", mid.FeatureMiddleware([]string{M.FEATURE_SEGMENT}), responseWrapper(GetSegmentByIdHandler))
+	authRouteGroup.GET("/:project_id/segments/:id/history", mid.FeatureMiddleware([]string{M.FEATURE_SEGMENT}), responseWrapper(GetSegmentMembershipHistoryHandler))
 	authRouteGroup.PUT("/:project_id/segments/:id", mid.FeatureMiddleware([]string{M.FEATURE_SEGMENT}), UpdateSegmentHandler)
 	authRouteGroup.DELETE("/:project_id/segments/:id", mid.FeatureMiddleware([]string{M.FEATURE_SEGMENT}), DeleteSegmentByIdHandler)
 
@@ -367,6 +383,7 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.GET("/:project_id/v1/accscore/score/user", mid.FeatureMiddleware([]string{M.FEATURE_ACCOUNT_SCORING}), responseWrapper(V1.GetUserScore))
 	authRouteGroup.GET("/:project_id/v1/accscore/score/account", mid.FeatureMiddleware([]string{M.FEATURE_ACCOUNT_SCORING}), responseWrapper(V1.GetAccountScores))
 	authRouteGroup.GET("/:project_id/v1/accscore/score/paccount/", mid.FeatureMiddleware([]string{M.FEATURE_ACCOUNT_SCORING}), responseWrapper(V1.GetPerAccountScore))
+	authRouteGroup.GET("/:project_id/v1/accscore/score/account/explain", mid.FeatureMiddleware([]string{M.FEATURE_ACCOUNT_SCORING}), responseWrapper(V1.GetAccountScoreExplanation))
 
 	// event trigger alert
 	authRouteGroup.GET("/:project_id/v1/eventtriggeralert", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.GetEventTriggerAlertsByProjectHandler))
@@ -376,6 +393,8 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.PUT("/:project_id/v1/eventtriggeralert/test_wh", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.TestWebhookforEventTriggerAlerts))
 	authRouteGroup.GET("/:project_id/v1/eventtriggeralert/:id", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.GetInternalStatusForEventTriggerAlertHandler))
 	authRouteGroup.PUT("/:project_id/v1/eventtriggeralert/:id/status", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.UpdateEventTriggerAlertInternalStatusHandler))
+	authRouteGroup.GET("/:project_id/v1/eventtriggeralert/:id/deliveries", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.GetEventTriggerAlertDeliveriesHandler))
+	authRouteGroup.POST("/:project_id/v1/eventtriggeralert/:id/deliveries/:delivery_id/replay", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.ReplayEventTriggerAlertDeliveryHandler))
 	authRouteGroup.POST("/:project_id/v1/eventtriggeralert/test_slack", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.SlackTestforEventTriggerAlerts))
 	authRouteGroup.POST("/:project_id/v1/eventtriggeralert/test_teams", mid.FeatureMiddleware([]string{M.FEATURE_EVENT_BASED_ALERTS}), responseWrapper(V1.TeamsTestforEventTriggerAlerts))
 
@@ -399,6 +418,23 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.POST("/:project_id/agents/batchinvite", AgentInviteBatch)
 	authRouteGroup.PUT("/:project_id/agents/remove", RemoveProjectAgent)
 	authRouteGroup.PUT("/:project_id/agents/update", AgentUpdate)
+
+	// Custom roles
+	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/custom_roles/permissions", responseWrapper(V1.GetPermissionsHandler))
+	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/custom_roles", responseWrapper(V1.GetCustomRolesHandler))
+	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/custom_roles", responseWrapper(V1.CreateCustomRoleHandler))
+	authRouteGroup.PUT("/:project_id"+ROUTE_VERSION_V1+"/custom_roles/assign", responseWrapper(V1.AssignCustomRoleHandler))
+	authRouteGroup.PUT("/:project_id"+ROUTE_VERSION_V1+"/custom_roles/:id", responseWrapper(V1.UpdateCustomRoleHandler))
+	authRouteGroup.DELETE("/:project_id"+ROUTE_VERSION_V1+"/custom_roles/:id", responseWrapper(V1.DeleteCustomRoleHandler))
+
+	// API keys
+	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/api_keys", responseWrapper(V1.GetAPIKeysHandler))
+	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/api_keys", responseWrapper(V1.CreateAPIKeyHandler))
+	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/api_keys/:id/rotate", responseWrapper(V1.RotateAPIKeyHandler))
+	authRouteGroup.DELETE("/:project_id"+ROUTE_VERSION_V1+"/api_keys/:id", responseWrapper(V1.RevokeAPIKeyHandler))
+	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/api_keys/:id/audits", responseWrapper(V1.GetAPIKeyAuditsHandler))
+	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/api_keys/:id/usages", responseWrapper(V1.GetAPIKeyUsagesHandler))
+
 	authRouteGroup.PUT("/:project_id/checklist/update", UpdateCheckListStatus)
 	authRouteGroup.GET("/:project_id/settings", GetProjectSettingHandler)
 	authRouteGroup.GET("/:project_id/v1/settings", V1.GetProjectSettingHandler)
@@ -423,9 +459,12 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.GET("/:project_id/channel_grouping_properties", GetChannelGroupingPropertiesHandler)
 	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/crm/:crm_source/:object_type/properties", GetCRMObjectPropertiesHandler)
 	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/crm/:crm_source/:object_type/properties/:property_name/values", GetCRMObjectValuesByPropertyNameHandler)
+	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/crm_custom_sources", GetCRMCustomSourcesHandler)
+	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/crm_custom_sources", RegisterCRMCustomSourceHandler)
 	// v1 KPI endpoints
 	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/kpi/config", responseWrapper(V1.GetKPIConfigHandler))
 	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/kpi/filter_values", responseWrapper(V1.GetKPIFilterValuesHandler))
+	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/kpi/holdout_lift", responseWrapper(V1.ExecuteHoldoutLiftQueryHandler))
 	authRouteGroup.GET("/:project_id/v1/kpi/:custom_event_kpi/properties", V1.GetPropertiesForCustomKPIEventBased)
 	// V1 Routes
 	authRouteGroup.GET("/:project_id/v1/event_names", V1.GetEventNamesHandler)
@@ -448,11 +487,13 @@ func InitAppRoutes(r *gin.Engine) {
 	//The dashboard endpoints doesn't have project_id params, hence feature middleware is not added here.
 	authCommonRouteGroup.GET("/dashboard_templates/:id/search", SearchTemplateHandler)
 	authCommonRouteGroup.GET("/dashboard_templates", GetDashboardTemplatesHandler)
-	authCommonRouteGroup.POST("/dashboard_template/create", CreateTemplateHandler)
+	authCommonRouteGroup.POST("/dashboard_template/create", mid.SetLoggedInAgent(), mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasPermissionForRequest(), CreateTemplateHandler)
 
 	// alert templates
 	authCommonRouteGroup.GET("/alert_templates", GetAlertTemplateHandler)
-	authCommonRouteGroup.DELETE("/alert_templates/:id", DeleteAlertTemplateHandler)
+	authCommonRouteGroup.DELETE("/alert_templates/:id", mid.SetLoggedInAgent(), mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasPermissionForRequest(), DeleteAlertTemplateHandler)
 	// feature gate v2
 	authRouteGroup.GET("/:project_id/v1/features", responseWrapper(V1.GetPlanDetailsForProjectHandler))
 
@@ -463,7 +504,7 @@ func InitAppRoutes(r *gin.Engine) {
 	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/kpi/property_mappings", responseWrapper(V1.CreatePropertyMapping))
 	authRouteGroup.GET("/:project_id"+ROUTE_VERSION_V1+"/kpi/property_mappings", responseWrapper(V1.GetPropertyMappings))
 	authRouteGroup.DELETE("/:project_id"+ROUTE_VERSION_V1+"/kpi/property_mappings/:id", responseWrapper(V1.DeletePropertyMapping))
-	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/kpi/property_mappings/commom_properties", responseWrapper(V1.GetCommonPropertyMappings))
+	authRouteGroup.POST("/:project_id"+ROUTE_VERSION_V1+"/kpi/property_mappings/common_properties", responseWrapper(V1.GetCommonPropertyMappings))
 
 	//six signal
 	authRouteGroup.POST("/:project_id/sixsignal/email", responseWrapper(SendSixSignalReportViaEmailHandler))
@@ -510,13 +551,39 @@ func InitAccountRoutes(r *gin.Engine) {
 
 	// account event tracking
 	accountRouteGroup := r.Group(ROUTE_ACCOUNT_ROOT)
-	accountRouteGroup.Use(mid.SetScopeProjectIdByPrivateToken())
+	accountRouteGroup.Use(mid.SetScopeProjectIdByPrivateToken(M.APIKeyScopeAccountsWrite))
 	accountRouteGroup.POST("/create", CreateAccountHandler)
 	accountRouteGroup.POST("/update", UpdateAccountHandler)
 	accountRouteGroup.POST("/event/track", TrackAccountEventHandler)
 
 }
 
+func InitPrivateQueryRoutes(r *gin.Engine) {
+	routePrefix := C.GetRoutesURLPrefix()
+
+	// queries of the project, only with an api key with the read scope.
+	queryRouteGroup := r.Group(routePrefix + ROUTE_PRIVATE_ROOT + ROUTE_VERSION_V1)
+	queryRouteGroup.Use(mid.SetScopeProjectIdByAPIKey(M.APIKeyScopeQueryRead))
+	queryRouteGroup.POST("/query", mid.RequestRateLimiterMiddleware("PRIVATE_QUERY_V1", 200, 60), responseWrapper(EventsQueryHandler))
+	queryRouteGroup.POST("/kpi/query", mid.RequestRateLimiterMiddleware("PRIVATE_KPI_QUERY", 200, 60), responseWrapper(V1.ExecuteKPIQueryHandler))
+
+	profilesRouteGroup := r.Group(routePrefix + ROUTE_PRIVATE_ROOT + ROUTE_VERSION_V1)
+	profilesRouteGroup.Use(mid.SetScopeProjectIdByAPIKey(M.APIKeyScopeProfilesRead))
+	profilesRouteGroup.POST("/profiles/query", mid.RequestRateLimiterMiddleware("PRIVATE_PROFILES_QUERY", 200, 60), responseWrapper(ProfilesQueryHandler))
+
+}
+
+func InitCRMRoutes(r *gin.Engine) {
+
+	// objects of the custom crm sources
+	crmRouteGroup := r.Group(ROUTE_CRM_ROOT)
+	crmRouteGroup.Use(mid.SetScopeProjectIdByPrivateToken(M.APIKeyScopeCRMWrite))
+	crmRouteGroup.POST("/:source/users", CreateCRMCustomUsersHandler)
+	crmRouteGroup.POST("/:source/activities", CreateCRMCustomActivitiesHandler)
+	crmRouteGroup.POST("/:source/properties", CreateCRMCustomPropertiesHandler)
+
+}
+
 func InitSDKServiceRoutes(r *gin.Engine) {
 	// Initialize swagger api docs only for development / staging.
 	if C.GetConfig().Env != C.PRODUCTION {
@@ -555,6 +622,12 @@ func InitSDKServiceRoutes(r *gin.Engine) {
 	sdkRouteGroup.POST("/capture_click", SDKCaptureClickHandler)
 	sdkRouteGroup.POST("/form_fill", SDKFormFillHandler)
 
+	// Bulk requests are read as stream and can be gzipped, so the body is not decoded upfront.
+	sdkBulkRouteGroup := r.Group(ROUTE_SDK_ROOT)
+	sdkBulkRouteGroup.Use(mid.SetScopeProjectToken())
+	sdkBulkRouteGroup.Use(mid.IsBlockedIPByProject())
+	sdkBulkRouteGroup.POST("/bulk", SDKBulkHandler)
+
 	ampSdkRouteGroup := r.Group(ROUTE_SDK_AMP_ROOT)
 	ampSdkRouteGroup.POST("/event/track", SDKAMPTrackHandler)
 	ampSdkRouteGroup.POST("/event/update_properties", SDKAMPUpdateEventPropertiesHandler)
@@ -587,35 +660,55 @@ func InitIntRoutes(r *gin.Engine) {
 
 	intRouteGroup.POST("/adwords/enable",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_GOOGLE_ADS}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_GOOGLE_ADS}),
 		IntEnableAdwordsHandler)
 
 	intRouteGroup.POST("/google_organic/enable",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_GOOGLE_ORGANIC}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_GOOGLE_ORGANIC}),
 		IntEnableGoogleOrganicHandler)
 
 	intRouteGroup.POST("/facebook/add_access_token",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_FACEBOOK}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_FACEBOOK}),
 		IntFacebookAddAccessTokenHandler)
 
-	intRouteGroup.POST("/linkedin/auth", mid.FeatureMiddleware([]string{M.FEATURE_LINKEDIN}), IntLinkedinAuthHandler)
-	intRouteGroup.POST("/linkedin/ad_accounts", mid.FeatureMiddleware([]string{M.FEATURE_LINKEDIN}), IntLinkedinAccountHandler)
+	intRouteGroup.POST("/linkedin/auth",
+		mid.SetLoggedInAgent(),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_LINKEDIN}), IntLinkedinAuthHandler)
+	intRouteGroup.POST("/linkedin/ad_accounts",
+		mid.SetLoggedInAgent(),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_LINKEDIN}), IntLinkedinAccountHandler)
 
 	intRouteGroup.POST("/linkedin/add_access_token",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_LINKEDIN}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_LINKEDIN}),
 		IntLinkedinAddAccessTokenHandler)
 
 	intRouteGroup.POST("/salesforce/enable",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_SALESFORCE}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_SALESFORCE}),
 		IntEnableSalesforceHandler)
 
 	intRouteGroup.POST("/salesforce/auth",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_SALESFORCE}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_SALESFORCE}),
 		SalesforceAuthRedirectHandler)
 	// salesforce integration.
 	intRouteGroup.GET(SalesforceCallbackRoute, mid.FeatureMiddleware([]string{M.FEATURE_SALESFORCE}),
@@ -623,7 +716,9 @@ func InitIntRoutes(r *gin.Engine) {
 
 	intRouteGroup.POST("/hubspot/auth",
 		mid.SetLoggedInAgent(),
-		mid.SetAuthorizedProjectsByLoggedInAgent(), mid.FeatureMiddleware([]string{M.FEATURE_HUBSPOT}),
+		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToPayloadProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
+		mid.FeatureMiddleware([]string{M.FEATURE_HUBSPOT}),
 		HubspotAuthRedirectHandler)
 
 	// hubspot integration.
@@ -633,6 +728,7 @@ func InitIntRoutes(r *gin.Engine) {
 	intRouteGroup.DELETE("/:project_id/:channel_name",
 		mid.SetLoggedInAgent(),
 		mid.SetAuthorizedProjectsByLoggedInAgent(),
+		mid.ValidateLoggedInAgentHasAccessToRequestProject(), mid.ValidateLoggedInAgentHasPermissionForRequest(),
 		mid.FeatureMiddleware([]string{M.FEATURE_GOOGLE_ADS, M.FEATURE_FACEBOOK, M.FEATURE_LINKEDIN, M.FEATURE_GOOGLE_ORGANIC}),
 		IntDeleteHandler)
 
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -122,9 +123,9 @@ func SDKBulkEventHandler(c *gin.Context) {
 		return
 	}
 
-	if len(sdkTrackPayloads) > 50000 {
+	if len(sdkTrackPayloads) > SDK.BulkMaxItems {
 		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge,
-			&SDK.TrackResponse{Error: "Tracking failed. Invalid payload. Request Exceeds more than 1000 events."})
+			&SDK.TrackResponse{Error: fmt.Sprintf("Tracking failed. Invalid payload. Request exceeds more than %d events.", SDK.BulkMaxItems)})
 		return
 	}
 
@@ -156,6 +157,75 @@ func SDKBulkEventHandler(c *gin.Context) {
 	c.JSON(respCode, response)
 }
 
+// Test command.
+// curl -i -H "Content-UnitType: application/x-ndjson" -H "Authorization: PROJECT_TOKEN" -X POST http://localhost:8080/sdk/bulk --data-binary $'{"type": "track", "payload": {"event_name": "login", "c_event_id": "1"}}\n{"type": "identify", "payload": {"user_id": "YOUR_USER_ID", "c_uid": "CUSTOMER_USER_ID"}}'
+// SDKBulkHandler godoc
+// @Summary Bulk request of track, identify and add_user_properties items, as json array or ndjson, optionally gzipped.
+// @Tags SDK
+// @Accept  json
+// @Produce json
+// @Param request body []sdk.BulkRequestItem true "Array of bulk request items"
+// @Success 200 {object} sdk.BulkResponse
+// @Success 207 {object} sdk.BulkResponse
+// @Router /sdk/bulk [post]
+// @Security ApiKeyAuth
+func SDKBulkHandler(c *gin.Context) {
+	r := c.Request
+
+	logCtx := log.WithFields(log.Fields{
+		"reqId": U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID),
+	})
+
+	if r.Body == nil {
+		logCtx.Error("Invalid request. Request body unavailable.")
+		c.AbortWithStatusJSON(http.StatusBadRequest,
+			&SDK.BulkResponse{Error: "Bulk request failed. Missing request body."})
+		return
+	}
+
+	projectToken := U.GetScopeByKeyAsString(c, mid.SCOPE_PROJECT_TOKEN)
+	requestContext := SDK.NewBulkRequestContext(projectToken, c.ClientIP(), r.UserAgent(),
+		C.GetSDKRequestQueueAllowedTokens())
+
+	response := &SDK.BulkResponse{Items: make([]*SDK.BulkItemResponse, 0)}
+	isGzip := strings.Contains(r.Header.Get("Content-Encoding"), "gzip")
+	err := SDK.ReadBulkRequestItems(r.Body, isGzip, func(index int, item *SDK.BulkRequestItem, err error) {
+		var itemResponse *SDK.BulkItemResponse
+		if err != nil {
+			itemResponse = &SDK.BulkItemResponse{Index: index, Status: http.StatusBadRequest, Error: "Invalid item."}
+		} else {
+			itemResponse = SDK.ProcessBulkRequestItem(requestContext, index, item)
+		}
+
+		if SDK.IsBulkItemSuccess(itemResponse.Status) {
+			response.Succeeded++
+		} else if itemResponse.Duplicate {
+			response.Duplicates++
+		} else {
+			response.Failed++
+		}
+		response.Items = append(response.Items, itemResponse)
+	})
+	if err != nil {
+		logCtx.WithError(err).WithField("items", len(response.Items)).Error("Failed reading bulk request.")
+		if len(response.Items) == 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest,
+				&SDK.BulkResponse{Error: "Bulk request failed. Invalid payload."})
+			return
+		}
+		// items read before the failure are processed already.
+		response.Error = fmt.Sprintf("Items after %d were not processed. %s.", len(response.Items)-1, err.Error())
+	}
+
+	metrics.CountInt(metrics.IncrSDKRequestOverallCount, int64(response.Succeeded))
+
+	status := http.StatusOK
+	if response.Failed > 0 || response.Duplicates > 0 || response.Error != "" {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
+
 // Test command.
 // curl -i -H "Content-UnitType: application/json" -H "Authorization: YOUR_TOKEN" -X POST http://localhost:8080/sdk/user/identify -d '{"user_id":"USER_ID", "c_uid": "CUSTOMER_USER_ID"}'
 // SDKIdentifyHandler godoc
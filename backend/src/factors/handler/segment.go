@@ -7,6 +7,7 @@ import (
 	"factors/model/store"
 	U "factors/util"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -101,6 +102,51 @@ func GetSegmentByIdHandler(c *gin.Context) (interface{}, int, string, string, bo
 	return segment, http.StatusFound, "", "", false
 }
 
+// GetSegmentMembershipHistoryHandler returns the entries to and exits from the segment.
+// Optional query params: user_id, from, to (unix timestamps) and limit.
+func GetSegmentMembershipHistoryHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	id := c.Params.ByName("id")
+	logCtx := log.WithFields(log.Fields{
+		"projectId": projectID,
+		"segmentId": id,
+	})
+	if projectID == 0 || id == "" {
+		logCtx.Error("Invalid project_id or segment Id.")
+		return nil, http.StatusBadRequest, "Input Params are incorrect", "invalid project_id or segment Id", true
+	}
+
+	var from, to int64
+	var limit int
+	var err error
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, err = strconv.ParseInt(fromStr, 10, 64); err != nil {
+			return nil, http.StatusBadRequest, "Input Params are incorrect", "invalid from", true
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = strconv.ParseInt(toStr, 10, 64); err != nil {
+			return nil, http.StatusBadRequest, "Input Params are incorrect", "invalid to", true
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			return nil, http.StatusBadRequest, "Input Params are incorrect", "invalid limit", true
+		}
+	}
+
+	if _, errCode := store.GetStore().GetSegmentById(projectID, id); errCode != http.StatusFound {
+		logCtx.Error("Segment not found.")
+		return nil, errCode, "Processing Failed", "Failed to get segment", true
+	}
+
+	history, errCode := store.GetStore().GetSegmentMembershipHistory(projectID, id, c.Query("user_id"), from, to, limit)
+	if errCode != http.StatusFound {
+		return nil, errCode, "Processing Failed", "Failed to get segment membership history", true
+	}
+	return history, http.StatusOK, "", "", false
+}
+
 func UpdateSegmentHandler(c *gin.Context) {
 	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
 	if projectID == 0 {
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	C "factors/config"
 	mid "factors/middleware"
 	"factors/model/model"
 	"factors/model/store"
 	U "factors/util"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,17 +16,62 @@ import (
 )
 
 type ShareableURLParams struct {
-	EntityID   int64 `json:"entity_id"`
-	EntityType int   `json:"entity_type"`
-	ShareType  int   `json:"share_type"`
-	// AllowedUsers    string `json:"allowed_users"`
-	IsExpirationSet bool  `json:"is_expiration_set"`
-	ExpirationTime  int64 `json:"expiration_time"`
+	EntityID        int64    `json:"entity_id"`
+	EntityType      int      `json:"entity_type"`
+	ShareType       int      `json:"share_type"`
+	AllowedUsers    []string `json:"allowed_users"`
+	Password        string   `json:"password"`
+	IsExpirationSet bool     `json:"is_expiration_set"`
+	ExpirationTime  int64    `json:"expiration_time"`
 }
 
 type UpdateShareableURLParams struct {
-	ShareType int `json:"share_type"`
-	// AllowedUsers string `json:"allowed_users"`
+	ShareType    int      `json:"share_type"`
+	AllowedUsers []string `json:"allowed_users"`
+	Password     string   `json:"password"`
+}
+
+type SharedEntityLoginLinkParams struct {
+	EntityType int    `json:"entity_type"`
+	Email      string `json:"email" binding:"required"`
+}
+
+type SharedEntityVerifyParams struct {
+	EntityType int    `json:"entity_type"`
+	Token      string `json:"token" binding:"required"`
+}
+
+type SharedEntityPasswordParams struct {
+	EntityType int    `json:"entity_type"`
+	Password   string `json:"password" binding:"required"`
+}
+
+// Path of the app, which verifies the one-time login link for the shared entity.
+const sharedEntityLoginAppPath = "/shared/login"
+
+// getAllowedUsersAndPasswordHash validates and returns the allowed users and the
+// hash of the password, required by the restricted share types.
+func getAllowedUsersAndPasswordHash(shareType int, emails []string, password string) (string, string, string) {
+	var allowedUsers, passwordHash, errMsg string
+	if shareType == model.ShareableURLShareTypeAllowedUsers {
+		allowedUsers, errMsg = model.GetAllowedUsersAsString(emails)
+		if errMsg != "" {
+			return "", "", errMsg
+		}
+	}
+
+	if shareType == model.ShareableURLShareTypePassword {
+		if len(password) < model.ShareMinPasswordLength {
+			return "", "", fmt.Sprintf("Password should have at least %d characters.", model.ShareMinPasswordLength)
+		}
+
+		var err error
+		passwordHash, err = model.HashPassword(password)
+		if err != nil {
+			return "", "", "Failed to hash the password."
+		}
+	}
+	return allowedUsers, passwordHash, ""
 }
 
 func GetShareableURLsHandler(c *gin.Context) {
@@ -75,13 +123,21 @@ func CreateShareableURLHandler(c *gin.Context) {
 		return
 	}
 
+	allowedUsers, passwordHash, errMsg := getAllowedUsersAndPasswordHash(params.ShareType, params.AllowedUsers, params.Password)
+	if errMsg != "" {
+		logCtx.Error(errMsg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
 	shareableUrlRequest := &model.ShareableURL{
-		EntityType: params.EntityType,
-		ShareType:  params.ShareType,
-		// AllowedUsers: params.AllowedUsers,
-		EntityID:  params.EntityID,
-		ProjectID: projectID,
-		CreatedBy: agentUUID,
+		EntityType:   params.EntityType,
+		ShareType:    params.ShareType,
+		AllowedUsers: allowedUsers,
+		PasswordHash: passwordHash,
+		EntityID:     params.EntityID,
+		ProjectID:    projectID,
+		CreatedBy:    agentUUID,
 	}
 
 	if params.IsExpirationSet && params.ExpirationTime > time.Now().Unix() {
@@ -107,52 +163,64 @@ func CreateShareableURLHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, share)
 }
 
-// func UpdateShareableURLHandler(c *gin.Context) {
-
-// 	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
-// 	if projectID == 0 {
-// 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid project."})
-// 		return
-// 	}
-
-// 	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
-
-// 	logCtx := log.WithFields(log.Fields{
-// 		"reqId":         U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID),
-// 		"loggedInAgent": agentUUID,
-// 		"projectId":     projectID,
-// 	})
-
-// 	shareId := c.Param("share_id")
-// 	if shareId == "" {
-// 		logCtx.Error("Invalid share id")
-// 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid share id."})
-// 		return
-// 	}
-
-// 	params := UpdateShareableURLParams{}
-// 	err := c.BindJSON(&params)
-// 	if err != nil {
-// 		logCtx.WithError(err).Error("Failed to parse request body")
-// 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid params."})
-// 		return
-// 	}
-
-// 	if !model.ValidShareTypes[params.shareType] {
-// 		logCtx.Error("Invalid share type")
-// 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid share type."})
-// 		return
-// 	}
-
-// 	errCode := store.GetStore().UpdateShareableURLShareTypeWithShareIDandCreatedBy(shareId, agentUUID, params.ShareType)
-// 	if errCode != http.StatusAccepted {
-// 		logCtx.Error("Failed to update shareable query")
-// 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Successfully updated."})
-// 		return
-// 	}
-
-// 	c.JSON(http.StatusOK, gin.H{"message": "Shareable query updated successfully."})
-// }
+func UpdateShareableURLHandler(c *gin.Context) {
+
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid project."})
+		return
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+
+	logCtx := log.WithFields(log.Fields{
+		"reqId":         U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID),
+		"loggedInAgent": agentUUID,
+		"projectId":     projectID,
+	})
+
+	shareId := c.Param("share_id")
+	if shareId == "" {
+		logCtx.Error("Invalid share id")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid share id."})
+		return
+	}
+
+	params := UpdateShareableURLParams{}
+	err := c.BindJSON(&params)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to parse request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid params."})
+		return
+	}
+
+	if !model.ValidShareTypes[params.ShareType] {
+		logCtx.Error("Invalid share type")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid share type."})
+		return
+	}
+
+	allowedUsers, passwordHash, errMsg := getAllowedUsersAndPasswordHash(params.ShareType, params.AllowedUsers, params.Password)
+	if errMsg != "" {
+		logCtx.Error(errMsg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	errCode := store.GetStore().UpdateShareableURLShareTypeWithShareIDandCreatedBy(projectID, shareId, agentUUID,
+		params.ShareType, allowedUsers, passwordHash)
+	if errCode == http.StatusNotFound {
+		logCtx.Error("Shareable query not found")
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Shareable query not found."})
+		return
+	} else if errCode != http.StatusAccepted {
+		logCtx.Error("Failed to update shareable query")
+		c.AbortWithStatusJSON(errCode, gin.H{"error": "Shareable query update failed."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shareable query updated successfully."})
+}
 
 func DeleteShareableURLHandler(c *gin.Context) {
 
@@ -240,3 +308,161 @@ func RevokeShareableURLHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully revoked"})
 }
+
+// SendSharedEntityLoginLinkHandler sends the one-time login link to the email, if it is on the
+// allowed users of the share. Responds the same when it is not, to not reveal the allowed users.
+func SendSharedEntityLoginLinkHandler(c *gin.Context) {
+	logCtx := log.WithField("reqId", U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID))
+
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	shareString := c.Param("query_id")
+	if projectID == 0 || shareString == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project or share."})
+		return
+	}
+
+	params := SharedEntityLoginLinkParams{}
+	if err := c.BindJSON(&params); err != nil || !U.IsEmail(strings.TrimSpace(params.Email)) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid email."})
+		return
+	}
+	if params.EntityType == 0 {
+		params.EntityType = model.ShareableURLEntityTypeQuery
+	}
+	logCtx = logCtx.WithFields(log.Fields{"project_id": projectID, "share_query_id": shareString})
+
+	shares, errCode := store.GetStore().GetActiveShareableURLsWithShareString(projectID, shareString, params.EntityType)
+	if errCode == http.StatusInternalServerError {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to send login link."})
+		return
+	}
+
+	for _, share := range shares {
+		if share.ShareType != model.ShareableURLShareTypeAllowedUsers || !share.IsAllowedUser(params.Email) {
+			continue
+		}
+
+		if err := sendSharedEntityLoginLinkEmail(share, params.Email); err != nil {
+			logCtx.WithError(err).Error("Failed to send login link for shared entity.")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to send login link."})
+			return
+		}
+		break
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login link is sent, if the email is allowed to view."})
+}
+
+func sendSharedEntityLoginLinkEmail(share *model.ShareableURL, email string) error {
+	token, err := model.CreateShareLoginToken(share, email)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s%s%s?project_id=%d&query_id=%s&entity_type=%d&token=%s", C.GetProtocol(), C.GetAPPDomain(),
+		sharedEntityLoginAppPath, share.ProjectID, share.QueryID, share.EntityType, token)
+	sub, text, html := U.CreateSharedReportLoginLinkTemplate(email, link, model.ShareLoginLinkExpiryInSecs/60)
+	return C.GetServices().Mailer.SendMail(email, C.GetFactorsSenderEmail(), sub, html, text)
+}
+
+// VerifySharedEntityLoginLinkHandler consumes the token of the one-time login link
+// and responds with the access token for the share.
+func VerifySharedEntityLoginLinkHandler(c *gin.Context) {
+	logCtx := log.WithField("reqId", U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID))
+
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	shareString := c.Param("query_id")
+	if projectID == 0 || shareString == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project or share."})
+		return
+	}
+
+	params := SharedEntityVerifyParams{}
+	if err := c.BindJSON(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid token."})
+		return
+	}
+	if params.EntityType == 0 {
+		params.EntityType = model.ShareableURLEntityTypeQuery
+	}
+	logCtx = logCtx.WithFields(log.Fields{"project_id": projectID, "share_query_id": shareString})
+
+	access, err := model.ConsumeShareLoginToken(projectID, params.Token)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get the login token of shared entity.")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify login link."})
+		return
+	}
+	if access == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login link is invalid or expired."})
+		return
+	}
+
+	var share *model.ShareableURL
+	shares, _ := store.GetStore().GetActiveShareableURLsWithShareString(projectID, shareString, params.EntityType)
+	for i := range shares {
+		if shares[i].ID == access.ShareID {
+			share = shares[i]
+		}
+	}
+	if share == nil || !share.IsAllowedUser(access.Email) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Login link is invalid or expired."})
+		return
+	}
+
+	respondWithShareAccessToken(c, logCtx, share, access.Email)
+}
+
+// LoginSharedEntityWithPasswordHandler responds with the access token for the share, protected by the password.
+func LoginSharedEntityWithPasswordHandler(c *gin.Context) {
+	logCtx := log.WithField("reqId", U.GetScopeByKeyAsString(c, mid.SCOPE_REQ_ID))
+
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	shareString := c.Param("query_id")
+	if projectID == 0 || shareString == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project or share."})
+		return
+	}
+
+	params := SharedEntityPasswordParams{}
+	if err := c.BindJSON(&params); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid password."})
+		return
+	}
+	if params.EntityType == 0 {
+		params.EntityType = model.ShareableURLEntityTypeQuery
+	}
+	logCtx = logCtx.WithFields(log.Fields{"project_id": projectID, "share_query_id": shareString})
+
+	shares, errCode := store.GetStore().GetActiveShareableURLsWithShareString(projectID, shareString, params.EntityType)
+	if errCode == http.StatusInternalServerError {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to login."})
+		return
+	}
+
+	for _, share := range shares {
+		if share.ShareType == model.ShareableURLShareTypePassword &&
+			model.IsPasswordAndHashEqual(params.Password, share.PasswordHash) {
+			respondWithShareAccessToken(c, logCtx, share, "")
+			return
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid password."})
+}
+
+func respondWithShareAccessToken(c *gin.Context, logCtx *log.Entry, share *model.ShareableURL, email string) {
+	token, expiresAt, err := model.CreateShareAccessToken(share, email)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to create access token for shared entity.")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access token."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": token,
+		"header":       model.ShareAccessTokenHeader,
+		"expires_at":   expiresAt,
+		"share_type":   share.ShareType,
+	})
+}
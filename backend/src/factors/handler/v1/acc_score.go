@@ -37,6 +37,17 @@ func UpdateAccScoreWeights(c *gin.Context) (interface{}, int, string, string, bo
 		return nil, http.StatusBadRequest, errMsg, "", true
 	}
 	weights.SaleWindow = weightsRequest.SaleWindow
+	if err := M.ValidateScoringMode(&weightsRequest); err != nil {
+		logCtx.WithError(err).Error("Invalid scoring mode.")
+		return nil, http.StatusBadRequest, err.Error(), "", true
+	}
+	M.CopyScoringMode(&weightsRequest, &weights)
+	// fitted weights are updated only by the scoring job.
+	weights.FittedWeights, weights.FittedAt, weights.FittedVersion = nil, 0, ""
+	if existingWeights, errCode := store.GetStore().GetWeightsByProject(projectId); errCode == http.StatusFound {
+		weights.FittedWeights, weights.FittedAt = existingWeights.FittedWeights, existingWeights.FittedAt
+		weights.FittedVersion = existingWeights.FittedVersion
+	}
 
 	// convert incoming request to AccWeights.
 	for _, wtVal := range weightsRequest.WeightConfig {
@@ -276,3 +287,49 @@ func GetUserScore(c *gin.Context) (interface{}, int, string, string, bool) {
 	return UsersScores, http.StatusOK, "", "", false
 
 }
+
+// GetAccountScoreExplanation returns the breakdown of the account score for a given date and id
+func GetAccountScoreExplanation(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectId := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	reqID, _ := getReqIDAndProjectID(c)
+
+	userId := c.Query("id")
+	dateString := c.Query("date")
+	numDaysTrend := c.Query("trend")
+
+	logCtx := log.WithFields(log.Fields{
+		"projectId": projectId,
+		"RequestId": reqID,
+		"userId":    userId,
+		"date":      dateString,
+	})
+
+	if userId == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+	if dateString == "" {
+		dateString = U.GetDateOnlyFromTimestamp(time.Now().Unix())
+	}
+
+	numDaysToTrend := M.NUM_TREND_DAYS
+	if numDaysTrend != "" {
+		var err error
+		numDaysToTrend, err = strconv.Atoi(numDaysTrend)
+		if err != nil || numDaysToTrend < 0 {
+			return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid number of days to trend.", true
+		}
+	}
+	if numDaysToTrend == 0 {
+		numDaysToTrend = M.NUM_TREND_DAYS
+	}
+	if numDaysToTrend > M.MAX_NUM_TREND_DAYS {
+		numDaysToTrend = M.MAX_NUM_TREND_DAYS
+	}
+
+	explanation, errCode := store.GetStore().GetAccountScoreExplanation(projectId, dateString, userId, numDaysToTrend)
+	if errCode != http.StatusFound {
+		logCtx.WithField("err_code", errCode).Error("Unable to get account score explanation.")
+		return nil, errCode, PROCESSING_FAILED, "Unable to get account score explanation.", true
+	}
+	return explanation, http.StatusOK, "", "", false
+}
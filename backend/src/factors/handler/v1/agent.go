@@ -70,6 +70,7 @@ func mapAgentInfoWithProjectMapping(pam model.ProjectAgentMapping, agent *model.
 	agentWithProject.ProjectID = fmt.Sprintf("%v", pam.ProjectID)
 	agentWithProject.ChecklistDismissed = pam.ChecklistDismissed
 	agentWithProject.Role = pam.Role
+	agentWithProject.CustomRoleID = pam.CustomRoleID
 	agentWithProject.InvitedBy = pam.InvitedBy
 	agentWithProject.CreatedAt = pam.CreatedAt
 	agentWithProject.UpdatedAt = pam.UpdatedAt
@@ -87,6 +88,7 @@ type AgentInfoWithProjectMapping struct {
 	ProjectID          string     `json:"project_id"`
 	ChecklistDismissed bool       `json:"checklist_dismissed"`
 	Role               uint64     `json:"role"`
+	CustomRoleID       *string    `json:"custom_role_id"`
 	InvitedBy          *string    `json:"invited_by"`
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
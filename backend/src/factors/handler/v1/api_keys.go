@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"encoding/json"
+	H "factors/handler/helpers"
+	mid "factors/middleware"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type createAPIKeyPayload struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// unix timestamp. Key doesn't expire, if not given.
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+type rotateAPIKeyPayload struct {
+	// old key stays valid for the grace period. Defaults to 24 hours.
+	GracePeriodInHours *int64 `json:"grace_period_in_hours"`
+}
+
+// APIKeyWithKey is the api key with the key, returned only on create and rotate.
+type APIKeyWithKey struct {
+	*model.APIKey
+	Key string `json:"key"`
+}
+
+func getAPIKeyAdminScope(c *gin.Context) (int64, string, int, string, string) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return 0, "", http.StatusForbidden, INVALID_PROJECT, ErrorMessages[INVALID_PROJECT]
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	if !H.IsAdmin(projectID, agentUUID) {
+		return 0, "", http.StatusForbidden, "", "Only admins can manage api keys."
+	}
+	return projectID, agentUUID, http.StatusOK, "", ""
+}
+
+func GetAPIKeysHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID, _, errCode, errCodeStr, errMsg := getAPIKeyAdminScope(c)
+	if errCode != http.StatusOK {
+		return nil, errCode, errCodeStr, errMsg, true
+	}
+
+	apiKeys, errCode := store.GetStore().GetAPIKeys(projectID)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Failed to get api keys.", true
+	}
+	return apiKeys, http.StatusOK, "", "", false
+}
+
+func CreateAPIKeyHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID, agentUUID, errCode, errCodeStr, errMsg := getAPIKeyAdminScope(c)
+	if errCode != http.StatusOK {
+		return nil, errCode, errCodeStr, errMsg, true
+	}
+
+	var payload createAPIKeyPayload
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Failed to decode Json request.", true
+	}
+
+	var expiresAt *time.Time
+	if payload.ExpiresAt > 0 {
+		expiryTime := time.Unix(payload.ExpiresAt, 0).UTC()
+		expiresAt = &expiryTime
+	}
+
+	apiKey, key, errCode, errMsg := store.GetStore().CreateAPIKey(projectID, agentUUID,
+		payload.Name, payload.Scopes, expiresAt)
+	if errCode != http.StatusCreated {
+		log.WithFields(log.Fields{"project_id": projectID, "err-message": errMsg}).Error("Failed to create api key.")
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+	return APIKeyWithKey{APIKey: apiKey, Key: key}, http.StatusCreated, "", "", false
+}
+
+func RotateAPIKeyHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID, agentUUID, errCode, errCodeStr, errMsg := getAPIKeyAdminScope(c)
+	if errCode != http.StatusOK {
+		return nil, errCode, errCodeStr, errMsg, true
+	}
+
+	id := c.Params.ByName("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+
+	var payload rotateAPIKeyPayload
+	if c.Request.ContentLength > 0 {
+		if err := json.NewDecoder(c.Request.Body).Decode(&payload); err != nil {
+			return nil, http.StatusBadRequest, INVALID_INPUT, "Failed to decode Json request.", true
+		}
+	}
+	gracePeriod := model.DefaultAPIKeyRotationGracePeriod
+	if payload.GracePeriodInHours != nil {
+		gracePeriod = time.Duration(*payload.GracePeriodInHours) * time.Hour
+	}
+
+	apiKey, key, errCode, errMsg := store.GetStore().RotateAPIKey(projectID, id, agentUUID, gracePeriod)
+	if errCode != http.StatusCreated {
+		log.WithFields(log.Fields{"project_id": projectID, "id": id, "err-message": errMsg}).Error("Failed to rotate api key.")
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+	return APIKeyWithKey{APIKey: apiKey, Key: key}, http.StatusCreated, "", "", false
+}
+
+func RevokeAPIKeyHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID, agentUUID, errCode, errCodeStr, errMsg := getAPIKeyAdminScope(c)
+	if errCode != http.StatusOK {
+		return nil, errCode, errCodeStr, errMsg, true
+	}
+
+	id := c.Params.ByName("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+
+	errCode, errMsg = store.GetStore().RevokeAPIKey(projectID, id, agentUUID)
+	if errCode != http.StatusAccepted && errCode != http.StatusNotModified {
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+	return nil, http.StatusOK, "", "", false
+}
+
+func GetAPIKeyAuditsHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID, _, errCode, errCodeStr, errMsg := getAPIKeyAdminScope(c)
+	if errCode != http.StatusOK {
+		return nil, errCode, errCodeStr, errMsg, true
+	}
+
+	id := c.Params.ByName("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+
+	audits, errCode := store.GetStore().GetAPIKeyAudits(projectID, id)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Failed to get api key audits.", true
+	}
+	return audits, http.StatusOK, "", "", false
+}
+
+func GetAPIKeyUsagesHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID, _, errCode, errCodeStr, errMsg := getAPIKeyAdminScope(c)
+	if errCode != http.StatusOK {
+		return nil, errCode, errCodeStr, errMsg, true
+	}
+
+	id := c.Params.ByName("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+
+	var limit int
+	if limitParam := c.Query("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid limit provided.", true
+		}
+	}
+
+	usages, errCode := store.GetStore().GetAPIKeyUsages(projectID, id, limit)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Failed to get api key usages.", true
+	}
+	return usages, http.StatusOK, "", "", false
+}
@@ -67,31 +67,32 @@ func RunMultipleRangeAttributionQueries(projectId, dashboardId, unitId int64, re
 		} else {
 
 			// Not allowing query: This is not a failure but due to some reason if the result is not cached in the DB
-			// we want to avoid computing and instead throw "No Data Found" error
-
-			logCtx.Info("Failing the query as the all parts of the query was not found in DB - attribution v1")
-			return true, mergedResult, computedMeta, errors.New("no Data found")
-
-			// compute if not found in cache
-			/*
-				requestPayload.Query.From = qRange.Start
-				requestPayload.Query.To = qRange.End
-				attributionQueryUnitPayload := model.AttributionQueryUnitV1{
-					Class: model.QueryClassAttribution,
-					Query: requestPayload.Query,
-				}
-				QueryKey, _ := attributionQueryUnitPayload.GetQueryCacheRedisKey(projectId)
-				debugQueryKey := model.GetStringKeyFromCacheRedisKey(QueryKey)
-				resultForRange, err = store.GetStore().ExecuteAttributionQueryV1(projectId, requestPayload.Query, debugQueryKey,
-					enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, unitId)
-				logCtx.WithError(err).WithFields(log.Fields{"RIndex": idx, "RStart": qRange.Start, "REnd": qRange.End}).Info("Found there ExecuteAttributionQueryV1")
-				if err != nil {
-					logCtx.Info("Failed to process query when not found in  DB - attribution v1")
-					return true, mergedResult, computedMeta
-				}
-				computedM := H.ComputedRangeInfo{From: qRange.Start, To: qRange.End, TimeZone: string(timezoneString), FromCache: false}
-				computedMeta = append(computedMeta, computedM)
-			*/
+			// we want to avoid computing and instead throw "No Data Found" error. With day partials, computing
+			// the range only computes the days not cached.
+			if !C.IsAllowedAttributionDayPartials(projectId) {
+				logCtx.Info("Failing the query as the all parts of the query was not found in DB - attribution v1")
+				return true, mergedResult, computedMeta, errors.New("no Data found")
+			}
+
+			var rangeQuery *model.AttributionQueryV1
+			U.DeepCopy(requestPayload.Query, &rangeQuery)
+			rangeQuery.From = qRange.Start
+			rangeQuery.To = qRange.End
+			attributionQueryUnitPayload := model.AttributionQueryUnitV1{
+				Class: model.QueryClassAttribution,
+				Query: rangeQuery,
+			}
+			QueryKey, _ := attributionQueryUnitPayload.GetQueryCacheRedisKey(projectId)
+			debugQueryKey := model.GetStringKeyFromCacheRedisKey(QueryKey)
+			resultForRange, err = store.GetStore().ExecuteAttributionQueryV1(projectId, rangeQuery, debugQueryKey,
+				enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, unitId)
+			if err != nil {
+				logCtx.WithError(err).WithFields(log.Fields{"RIndex": idx, "RStart": qRange.Start, "REnd": qRange.End}).
+					Error("Failed to process query when not found in DB - attribution v1")
+				return true, mergedResult, computedMeta, err
+			}
+			computedM := H.ComputedRangeInfo{From: qRange.Start, To: qRange.End, TimeZone: string(timezoneString), FromCache: false}
+			computedMeta = append(computedMeta, computedM)
 		}
 		keyIndex := model.GetLastKeyValueIndex(resultForRange.Headers)
 		if requestPayload.Query.AttributionKey == model.AttributionKeyLandingPage ||
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	C "factors/config"
+	qc "factors/quickchart"
+	U "factors/util"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetChartImageHandler serves the chart and table images of the shared reports.
+// It is not authenticated, access is by the signature of the url, which expires.
+func GetChartImageHandler(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("project_id"), 10, 64)
+	if err != nil || projectID == 0 {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	imageName := c.Param("image_name")
+	format, isValidFormat := qc.GetImageFormat(imageName)
+	expiresAt, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if !isValidFormat || err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	logCtx := log.WithFields(log.Fields{"project_id": projectID, "image_name": imageName})
+	if !qc.IsValidImageURLSignature(projectID, imageName, expiresAt, c.Query("signature")) {
+		logCtx.Warn("Invalid or expired signature on chart image url.")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	path, fileName := C.GetCloudManager().GetChartImageFilePathAndName(projectID, imageName)
+	reader, err := C.GetCloudManager().Get(path, fileName)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to read chart image.")
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	defer reader.Close()
+
+	size, err := C.GetCloudManager().GetObjectSize(path, fileName)
+	if err != nil {
+		size = -1
+	}
+	c.DataFromReader(http.StatusOK, size, qc.GetImageContentType(format), reader, map[string]string{
+		"Cache-Control": fmt.Sprintf("private, max-age=%d", expiresAt-U.TimeNowUnix()),
+	})
+}
@@ -318,6 +318,8 @@ func getPropertiesFunctionBasedOnSectionDisplayCategory(sectionDisplayCategory s
 		return store.GetStore().GetPropertiesForMarketo
 	} else if strings.Contains(sectionDisplayCategory, U.CRM_SOURCE_NAME_LEADSQUARED) {
 		return store.GetStore().GetPropertiesForLeadSquared
+	} else if strings.Contains(sectionDisplayCategory, U.CRM_SOURCE_NAME_CUSTOM) {
+		return store.GetStore().GetPropertiesForCustomCRM
 	}
 	return nil
 }
@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"encoding/json"
+	H "factors/handler/helpers"
+	mid "factors/middleware"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type customRolePayload struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type assignCustomRolePayload struct {
+	AgentUUID string `json:"agent_uuid"`
+	// empty to remove the custom role of the agent.
+	CustomRoleID string `json:"custom_role_id"`
+}
+
+type permissionsResponse struct {
+	Resources       []string            `json:"resources"`
+	Actions         []string            `json:"actions"`
+	PredefinedRoles map[uint64][]string `json:"predefined_roles"`
+}
+
+func getCustomRoleFromPayload(c *gin.Context, isUpdate bool) (*model.CustomRole, string) {
+	var payload customRolePayload
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, "Failed to decode Json request."
+	}
+
+	customRole := &model.CustomRole{Name: payload.Name, Description: payload.Description}
+	if isUpdate && payload.Permissions == nil {
+		return customRole, ""
+	}
+
+	permissions, err := model.ValidateCustomRolePermissions(payload.Permissions)
+	if err != nil {
+		return nil, err.Error()
+	}
+	customRole.Permissions = permissions
+	return customRole, ""
+}
+
+// GetPermissionsHandler returns the resources and actions for the custom roles,
+// with the permissions of the predefined roles.
+func GetPermissionsHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	predefinedRoles := make(map[uint64][]string)
+	for _, role := range []uint64{model.AGENT, model.ADMIN, model.VIEWER, model.ANALYST,
+		model.MARKETER, model.INTEGRATION_ADMIN} {
+		predefinedRoles[role] = model.GetPredefinedRolePermissions(role)
+	}
+
+	return permissionsResponse{
+		Resources:       model.PermissionResources,
+		Actions:         []string{model.PermissionActionRead, model.PermissionActionWrite},
+		PredefinedRoles: predefinedRoles,
+	}, http.StatusOK, "", "", false
+}
+
+func GetCustomRolesHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, INVALID_PROJECT, ErrorMessages[INVALID_PROJECT], true
+	}
+
+	customRoles, errCode := store.GetStore().GetCustomRoles(projectID)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Failed to get custom roles.", true
+	}
+	return customRoles, http.StatusOK, "", "", false
+}
+
+func CreateCustomRoleHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, INVALID_PROJECT, ErrorMessages[INVALID_PROJECT], true
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	if !H.IsAdmin(projectID, agentUUID) {
+		return nil, http.StatusForbidden, "", "Only admins can create custom roles.", true
+	}
+
+	customRole, errMsg := getCustomRoleFromPayload(c, false)
+	if errMsg != "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, errMsg, true
+	}
+	customRole.CreatedBy = agentUUID
+
+	customRole, errCode, errMsg := store.GetStore().CreateCustomRole(projectID, customRole)
+	if errCode != http.StatusCreated {
+		log.WithFields(log.Fields{"project_id": projectID, "err-message": errMsg}).Error("Failed to create custom role.")
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+	return customRole, http.StatusCreated, "", "", false
+}
+
+func UpdateCustomRoleHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, INVALID_PROJECT, ErrorMessages[INVALID_PROJECT], true
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	if !H.IsAdmin(projectID, agentUUID) {
+		return nil, http.StatusForbidden, "", "Only admins can update custom roles.", true
+	}
+
+	id := c.Params.ByName("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+
+	customRole, errMsg := getCustomRoleFromPayload(c, true)
+	if errMsg != "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, errMsg, true
+	}
+
+	errCode, errMsg := store.GetStore().UpdateCustomRole(projectID, id, customRole)
+	if errCode != http.StatusAccepted {
+		log.WithFields(log.Fields{"project_id": projectID, "id": id, "err-message": errMsg}).Error("Failed to update custom role.")
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+
+	customRole, errCode = store.GetStore().GetCustomRole(projectID, id)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Failed to get updated custom role.", true
+	}
+	return customRole, http.StatusOK, "", "", false
+}
+
+func DeleteCustomRoleHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, INVALID_PROJECT, ErrorMessages[INVALID_PROJECT], true
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	if !H.IsAdmin(projectID, agentUUID) {
+		return nil, http.StatusForbidden, "", "Only admins can delete custom roles.", true
+	}
+
+	id := c.Params.ByName("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid id provided.", true
+	}
+
+	errCode, errMsg := store.GetStore().DeleteCustomRole(projectID, id)
+	if errCode != http.StatusAccepted {
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+	return nil, http.StatusOK, "", "", false
+}
+
+// AssignCustomRoleHandler assigns the custom role to the agent of the project.
+func AssignCustomRoleHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, INVALID_PROJECT, ErrorMessages[INVALID_PROJECT], true
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	if !H.IsAdmin(projectID, agentUUID) {
+		return nil, http.StatusForbidden, "", "Only admins can assign custom roles.", true
+	}
+
+	var payload assignCustomRolePayload
+	if err := c.BindJSON(&payload); err != nil || payload.AgentUUID == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid agent provided.", true
+	}
+	if payload.AgentUUID == agentUUID {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Custom role can't be assigned to self.", true
+	}
+
+	errCode := store.GetStore().SetCustomRoleForProjectAgentMapping(projectID, payload.AgentUUID, payload.CustomRoleID)
+	if errCode != http.StatusAccepted {
+		return nil, errCode, PROCESSING_FAILED, "Failed to assign custom role.", true
+	}
+
+	pam, errCode := store.GetStore().GetProjectAgentMapping(projectID, payload.AgentUUID)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Failed to get agent of the project.", true
+	}
+	return pam, http.StatusOK, "", "", false
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"encoding/json"
+	H "factors/handler/helpers"
 	teams "factors/integration/ms_teams"
 	slack "factors/integration/slack"
 	mid "factors/middleware"
@@ -12,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -413,7 +415,7 @@ func TestWebhookforEventTriggerAlerts(c *gin.Context) (interface{}, int, string,
 		MessageProperty: msgPropMap,
 	}
 
-	response, err := webhooks.DropWebhook(webhook.Url, webhook.Secret, payload)
+	response, err := webhooks.DropWebhook(webhook.Url, webhook.Secret, !webhook.IsLegacySecretHeaderDisabled, payload)
 	if err != nil {
 		errMsg := "failed to send test_webhook"
 		log.WithFields(log.Fields{"project_id": projectID, "response": response, "url": webhook.Url}).WithError(err).Error(errMsg)
@@ -695,3 +697,67 @@ func TeamsTestforEventTriggerAlerts(c *gin.Context) (interface{}, int, string, s
 	}
 	return nil, http.StatusAccepted, "", "", false
 }
+
+func GetEventTriggerAlertDeliveriesHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, ErrorMessages[INVALID_PROJECT], "Get deliveries failed. Invalid project ID.", true
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Get deliveries failed. Invalid id provided.", true
+	}
+
+	status := c.Query("status")
+	if status != "" && status != model.DeliveryStatusSuccess && status != model.DeliveryStatusFailure {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Get deliveries failed. Invalid status provided.", true
+	}
+
+	limit := 0
+	if limitParam := c.Query("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return nil, http.StatusBadRequest, INVALID_INPUT, "Get deliveries failed. Invalid limit provided.", true
+		}
+	}
+
+	deliveries, errCode := store.GetStore().GetEventTriggerAlertDeliveries(projectID, id, status, limit)
+	if errCode != http.StatusFound {
+		return nil, errCode, PROCESSING_FAILED, "Get deliveries failed.", true
+	}
+
+	return deliveries, http.StatusOK, "", "", false
+}
+
+func ReplayEventTriggerAlertDeliveryHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	projectID := U.GetScopeByKeyAsInt64(c, mid.SCOPE_PROJECT_ID)
+	if projectID == 0 {
+		return nil, http.StatusForbidden, ErrorMessages[INVALID_PROJECT], "Replay delivery failed. Invalid project ID.", true
+	}
+
+	agentUUID := U.GetScopeByKeyAsString(c, mid.SCOPE_LOGGEDIN_AGENT_UUID)
+	if !H.IsAdmin(projectID, agentUUID) {
+		return nil, http.StatusForbidden, "", "Replay delivery failed. Only admins can replay deliveries.", true
+	}
+
+	id := c.Param("id")
+	deliveryID := c.Param("delivery_id")
+	if id == "" || deliveryID == "" {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Replay delivery failed. Invalid id provided.", true
+	}
+
+	errCode, err := store.GetStore().ReplayEventTriggerAlertDelivery(projectID, id, deliveryID)
+	if errCode != http.StatusAccepted {
+		log.WithFields(log.Fields{"project_id": projectID, "alert_id": id, "delivery_id": deliveryID}).
+			WithError(err).Error("Failed to replay event trigger alert delivery.")
+		errMsg := "Replay delivery failed."
+		if err != nil {
+			errMsg = err.Error()
+		}
+		return nil, errCode, PROCESSING_FAILED, errMsg, true
+	}
+
+	return nil, http.StatusAccepted, "", "", false
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"encoding/json"
+	C "factors/config"
+	H "factors/handler/helpers"
+	"factors/model/model"
+	"factors/model/store"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ExecuteHoldoutLiftQueryHandler godoc
+// @Summary To get the lift of kpis on the exposed population over the held-out population.
+// @Tags V1Api
+// @Accept  json
+// @Produce json
+// @Param project_id path integer true "Project ID"
+// @Param query body model.HoldoutLiftQuery true "Query payload"
+// @Success 200 {object} model.QueryResult
+// @Router /{project_id}/v1/kpi/holdout_lift [post]
+func ExecuteHoldoutLiftQueryHandler(c *gin.Context) (interface{}, int, string, string, bool) {
+	reqID, projectID := getReqIDAndProjectID(c)
+	if projectID == 0 {
+		return nil, http.StatusBadRequest, INVALID_INPUT, "", true
+	}
+	logCtx := log.WithField("project_id", projectID).WithField("reqId", reqID)
+
+	var query model.HoldoutLiftQuery
+	decoder := json.NewDecoder(c.Request.Body)
+	if err := decoder.Decode(&query); err != nil {
+		logCtx.WithError(err).Error("Failed to decode Json request on holdout lift handler.")
+		return nil, http.StatusBadRequest, INVALID_INPUT, "Invalid query payload.", true
+	}
+
+	if valid, errMsg := query.IsValid(); !valid {
+		return nil, http.StatusBadRequest, INVALID_INPUT, errMsg, true
+	}
+
+	var statusCode int
+	var errorCode, errMsg string
+	var isErr bool
+	query.KPI, statusCode, errorCode, errMsg, isErr = setTimezoneForKPIRequest(logCtx, query.KPI, projectID)
+	if statusCode != http.StatusOK {
+		return nil, statusCode, errorCode, errMsg, isErr
+	}
+
+	if err := query.KPI.TransformDateTypeFilters(); err != nil {
+		return nil, http.StatusBadRequest, INVALID_INPUT, err.Error(), true
+	}
+
+	enableOptimisedFilterOnProfileQuery := c.Request.Header.Get(H.HeaderUserFilterOptForProfiles) == "true" ||
+		C.EnableOptimisedFilterOnProfileQuery()
+	enableOptimisedFilterOnEventUserQuery := c.Request.Header.Get(H.HeaderUserFilterOptForEventsAndUsers) == "true" ||
+		C.EnableOptimisedFilterOnEventUserQuery()
+
+	result, statusCode := store.GetStore().ExecuteHoldoutLiftQuery(projectID, reqID, query,
+		enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery)
+	if statusCode != http.StatusOK {
+		return nil, statusCode, PROCESSING_FAILED, "Failed to compute holdout lift.", true
+	}
+	return result, http.StatusOK, "", "", false
+}
@@ -84,6 +84,7 @@ func GetKPIConfigHandler(c *gin.Context) (interface{}, int, string, string, bool
 		storeSelected.GetKPIConfigsForLinkedinCompanyEngagements,
 		storeSelected.GetKPIConfigsForAllChannels, storeSelected.GetKPIConfigsForBingAds, storeSelected.GetKPIConfigsForMarketoLeads,
 		storeSelected.GetKPIConfigsForLeadSquaredLeads,
+		storeSelected.GetKPIConfigsForCustomCRMUsers,
 	}
 	configFunctionsForCustomAds := []func(int64, string, bool) ([]map[string]interface{}, int){
 		storeSelected.GetKPIConfigsForCustomAds,
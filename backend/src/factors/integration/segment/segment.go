@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/gorm/dialects/postgres"
@@ -113,6 +114,7 @@ type Event struct {
 	ScreenName  string          `json:"name"`
 	UserId      string          `json:"userId"`
 	AnonymousID string          `json:"anonymousId"`
+	PreviousID  string          `json:"previousId"`
 	MessageID   *string         `json:"messageId"`
 	GroupID     *string         `json:"groupId"`
 	Channel     string          `json:"channel"`
@@ -619,12 +621,42 @@ func ReceiveEvent(token string, event *Event) (int, *EventResponse) {
 		}
 
 	case "group":
-		logCtx.WithField("tag", "unsupported").Info("Segment group event.")
-		return http.StatusBadRequest, nil
+		groupProperties, err := getSegmentAccountGroupProperties(event)
+		if err != nil {
+			logCtx.WithError(err).Error("Segment event failure. Invalid group traits.")
+			response.Error = "Invalid group traits."
+			return http.StatusBadRequest, response
+		}
+
+		status := SDK.TrackUserAccountGroup(project.ID, userID, model.GROUP_NAME_SEGMENT_ACCOUNT,
+			groupProperties, requestTimestamp)
+		if status == http.StatusNotImplemented {
+			response.Error = "Invalid group event. Requires website or domain of the account."
+			return http.StatusBadRequest, response
+		}
+		if status != http.StatusOK {
+			logCtx.WithFields(log.Fields{"group_properties": groupProperties,
+				"error_code": status}).Error("Segment event failure. Tracking user account group failed.")
+
+			response.Error = "Reception of group event failed."
+			return status, response
+		}
 
 	case "alias":
-		logCtx.WithField("tag", "unsupported").Info("Segment alias event.")
-		return http.StatusBadRequest, nil
+		if event.PreviousID == "" || event.UserId == "" {
+			response.Error = "Invalid alias event. Requires previousId and userId."
+			return http.StatusBadRequest, response
+		}
+
+		status := aliasSegmentUser(project.ID, event.PreviousID, event.UserId, requestTimestamp)
+		if status != http.StatusOK {
+			logCtx.WithFields(log.Fields{"previous_id": event.PreviousID,
+				"error_code": status}).Error("Segment event failure. Alias failed.")
+
+			response.Error = "Reception of alias event failed."
+			return status, response
+		}
+
 	default:
 		response.Error = fmt.Sprintf("Unknown event type %s", event.Type)
 		logCtx.Error("Unknown segment event type.")
@@ -635,6 +667,105 @@ func ReceiveEvent(token string, event *Event) (int, *EventResponse) {
 	return http.StatusOK, response
 }
 
+// getSegmentAccountGroupProperties returns the traits of the group call as account group properties.
+// Domain of the account is taken from the website or the domain trait, falls back to the group id.
+func getSegmentAccountGroupProperties(event *Event) (*U.PropertiesMap, error) {
+	traits := make(map[string]interface{})
+	if event.Traits.RawMessage != nil {
+		decodedTraits, err := U.DecodePostgresJsonb(&event.Traits)
+		if err != nil {
+			return nil, err
+		}
+		traits = *decodedTraits
+	}
+
+	groupProperties := U.PropertiesMap{}
+	for key, value := range traits {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			// nested traits like address are not supported as properties.
+			continue
+		}
+
+		// trait in lower case is kept, when the traits differ only by the case.
+		propertyKey := U.SEGMENT_ACCOUNT_PROPERTIES_PREFIX + strings.ToLower(key)
+		if _, exists := groupProperties[propertyKey]; exists && key != strings.ToLower(key) {
+			continue
+		}
+		groupProperties[propertyKey] = value
+	}
+
+	groupID := ""
+	if event.GroupID != nil {
+		groupID = strings.TrimSpace(*event.GroupID)
+	}
+	if groupID != "" {
+		groupProperties[U.SEGMENT_ACCOUNT_ID] = groupID
+	}
+
+	domain := U.GetPropertyValueAsString(traits["website"])
+	if domain == "" {
+		domain = U.GetPropertyValueAsString(traits["domain"])
+	}
+	if domain == "" && strings.Contains(groupID, ".") {
+		domain = groupID
+	}
+	if domain != "" {
+		groupProperties[U.SEGMENT_ACCOUNT_DOMAIN] = domain
+	}
+
+	return &groupProperties, nil
+}
+
+// aliasSegmentUser merges the previous id into the user identified by the customer user id.
+// Previous id could be the anonymous id of the user or the user id used before.
+func aliasSegmentUser(projectID int64, previousID, customerUserID string, timestamp int64) int {
+	logCtx := log.WithFields(log.Fields{"project_id": projectID,
+		"previous_id": previousID, "customer_user_id": customerUserID})
+
+	userIDs := make([]string, 0)
+	user, status := store.GetStore().GetUserBySegmentAnonymousId(projectID, previousID)
+	if status == http.StatusFound {
+		userIDs = append(userIDs, user.ID)
+	} else if status != http.StatusNotFound {
+		logCtx.WithField("err_code", status).Error("Failed to get user by segment anonymous id on alias.")
+		return http.StatusInternalServerError
+	}
+
+	if previousID != customerUserID {
+		users, status := store.GetStore().GetUsersByCustomerUserID(projectID, previousID)
+		if status != http.StatusFound && status != http.StatusNotFound {
+			logCtx.WithField("err_code", status).Error("Failed to get users by customer user id on alias.")
+			return http.StatusInternalServerError
+		}
+		for i := range users {
+			userIDs = append(userIDs, users[i].ID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		logCtx.Info("No user with the previous id on segment alias.")
+		return http.StatusOK
+	}
+
+	for _, userID := range userIDs {
+		status, _ := SDK.Identify(projectID, &SDK.IdentifyPayload{
+			UserId:         userID,
+			CustomerUserId: customerUserID,
+			Timestamp:      timestamp,
+			RequestSource:  model.UserSourceWeb,
+			Source:         SDK.SourceSegment,
+		}, true)
+		if status != http.StatusOK {
+			logCtx.WithFields(log.Fields{"user_id": userID, "err_code": status}).
+				Error("Failed to identify user with the alias.")
+			return http.StatusInternalServerError
+		}
+	}
+
+	return http.StatusOK
+}
+
 func enrichEventPropertyUsingURL(parsedPageURL *url.URL, eventProperties *U.PropertiesMap) {
 
 	var eventPropertiesURL U.PropertiesMap
@@ -22,6 +22,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	cacheRedis "factors/cache/redis"
@@ -37,7 +38,9 @@ import (
 const SCOPE_PROJECT_ID = "projectId"
 const SCOPE_PROJECT_TOKEN = "projectToken"
 const SCOPE_PROJECT_PRIVATE_TOKEN = "projectPrivateToken"
+const SCOPE_API_KEY_ID = "apiKeyId"
 const SCOPE_AUTHORIZED_PROJECTS = "authorizedProjects"
+const SCOPE_AUTHORIZED_PROJECT_AGENT_MAPPINGS = "authorizedProjectAgentMappings"
 const SCOPE_LOGGEDIN_AGENT_UUID = "loggedInAgentUUID"
 const SCOPE_LOGGEDIN_AGENT_EMAIL = "loggedInAgentEmail"
 const SCOPE_REQ_ID = "requestId"
@@ -263,14 +266,178 @@ func SetScopeProjectPrivateToken() gin.HandlerFunc {
 			return
 		}
 
+		if model.IsAPIKeyToken(token) {
+			apiKey, project, errMsg := getProjectByAPIKey(token, model.APIKeyScopeSDKWrite)
+			if apiKey == nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
+				return
+			}
+			setScopeAPIKey(c, apiKey)
+			token = project.PrivateToken
+		}
+
 		U.SetScope(c, SCOPE_PROJECT_PRIVATE_TOKEN, token)
 		c.Next()
+		recordAPIKeyUsage(c)
 	}
 }
 
+// apiKeyLastUsedAtUpdateInterval throttles the updates of last_used_at of the api
+// key, as the keys are used on every request.
+const apiKeyLastUsedAtUpdateInterval = time.Minute
+
+var apiKeyLastUsedAtUpdatedAt sync.Map
+
+// getProjectByAPIKey returns the api key and its project, if the key is active
+// and carries the scope. Returns the error message otherwise.
+func getProjectByAPIKey(token, scope string) (*model.APIKey, *model.Project, string) {
+	apiKey, errCode := store.GetStore().GetAPIKeyByKey(token)
+	if errCode != http.StatusFound {
+		return nil, nil, "Invalid token"
+	}
+
+	now := U.TimeNowZ()
+	if err := model.ValidateAPIKeyForScope(apiKey, scope, now); err != nil {
+		log.WithFields(log.Fields{"project_id": apiKey.ProjectID, "api_key_id": apiKey.ID}).
+			WithError(err).Warn("Request failed because of invalid api key.")
+		return nil, nil, err.Error()
+	}
+
+	project, errCode := store.GetStore().GetProject(apiKey.ProjectID)
+	if errCode != http.StatusFound {
+		return nil, nil, "Invalid token"
+	}
+
+	lastUpdatedAt, exists := apiKeyLastUsedAtUpdatedAt.Load(apiKey.ID)
+	if !exists || now.Sub(lastUpdatedAt.(time.Time)) >= apiKeyLastUsedAtUpdateInterval {
+		apiKeyLastUsedAtUpdatedAt.Store(apiKey.ID, now)
+		store.GetStore().UpdateAPIKeyLastUsedAt(apiKey.ProjectID, apiKey.ID, now)
+	}
+
+	return apiKey, project, ""
+}
+
+func setScopeAPIKey(c *gin.Context, apiKey *model.APIKey) {
+	U.SetScope(c, SCOPE_API_KEY_ID, apiKey.ID)
+	U.SetScope(c, SCOPE_PROJECT_ID, apiKey.ProjectID)
+}
+
+// apiKeyUsagesFlushInterval is the interval of writing the usages of the api keys,
+// counted in memory by the minute, to keep the writes off the request path.
+const apiKeyUsagesFlushInterval = 30 * time.Second
+
+type apiKeyUsageKey struct {
+	projectID int64
+	apiKeyID  string
+	method    string
+	route     string
+	status    int
+	timestamp int64
+}
+
+var apiKeyUsagesMutex sync.Mutex
+var apiKeyUsages = make(map[apiKeyUsageKey]*model.APIKeyUsage)
+var apiKeyUsagesFlushOnce sync.Once
+
+// recordAPIKeyUsage counts the request made with the api key on the usage of the
+// minute. Has to be called after the request is processed, for the status.
+func recordAPIKeyUsage(c *gin.Context) {
+	apiKeyID := U.GetScopeByKeyAsString(c, SCOPE_API_KEY_ID)
+	if apiKeyID == "" {
+		return
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	key := apiKeyUsageKey{
+		projectID: U.GetScopeByKeyAsInt64(c, SCOPE_PROJECT_ID),
+		apiKeyID:  apiKeyID,
+		method:    c.Request.Method,
+		route:     route,
+		status:    c.Writer.Status(),
+		timestamp: U.TimeNowZ().Truncate(time.Minute).Unix(),
+	}
+
+	apiKeyUsagesMutex.Lock()
+	usage, exists := apiKeyUsages[key]
+	if !exists {
+		usage = &model.APIKeyUsage{ProjectID: key.projectID, APIKeyID: key.apiKeyID, Method: key.method,
+			Route: key.route, Status: key.status, Timestamp: key.timestamp}
+		apiKeyUsages[key] = usage
+	}
+	usage.Count++
+	usage.ClientIP = c.ClientIP()
+	apiKeyUsagesMutex.Unlock()
+
+	apiKeyUsagesFlushOnce.Do(func() {
+		go func() {
+			for range time.Tick(apiKeyUsagesFlushInterval) {
+				FlushAPIKeyUsages()
+			}
+		}()
+	})
+}
+
+// FlushAPIKeyUsages writes the usages of the api keys counted in memory.
+func FlushAPIKeyUsages() {
+	apiKeyUsagesMutex.Lock()
+	usagesToFlush := apiKeyUsages
+	apiKeyUsages = make(map[apiKeyUsageKey]*model.APIKeyUsage)
+	apiKeyUsagesMutex.Unlock()
+
+	if len(usagesToFlush) == 0 {
+		return
+	}
+
+	usages := make([]model.APIKeyUsage, 0, len(usagesToFlush))
+	for _, usage := range usagesToFlush {
+		usages = append(usages, *usage)
+	}
+	if errCode := store.GetStore().CreateOrIncrementAPIKeyUsages(usages); errCode != http.StatusCreated {
+		log.WithFields(log.Fields{"usages": len(usages), "err_code": errCode}).
+			Error("Failed to record api key usages.")
+	}
+}
+
+// setScopeProjectIdByPrivateTokenOrAPIKey sets the project id scope by the api key with
+// the scope or, if allowed, by the private token of the project.
+func setScopeProjectIdByPrivateTokenOrAPIKey(c *gin.Context, token, scope string, allowPrivateToken bool) (int, string) {
+	if model.IsAPIKeyToken(token) {
+		apiKey, _, errMsg := getProjectByAPIKey(token, scope)
+		if apiKey == nil {
+			return http.StatusUnauthorized, errMsg
+		}
+		setScopeAPIKey(c, apiKey)
+		return http.StatusOK, ""
+	}
+
+	if !allowPrivateToken {
+		return http.StatusUnauthorized, "Invalid token. Use an api key with the " + scope + " scope"
+	}
+
+	project, errCode := store.GetStore().GetProjectByPrivateToken(token)
+	if errCode != http.StatusFound {
+		return http.StatusUnauthorized, "Invalid token"
+	}
+	U.SetScope(c, SCOPE_PROJECT_ID, project.ID)
+	return http.StatusOK, ""
+}
+
 // SetScopeProjectIdByPrivateToken - Set project id scope by private
-// token on 'Authorization' header.
-func SetScopeProjectIdByPrivateToken() gin.HandlerFunc {
+// token or api key with the scope on 'Authorization' header.
+func SetScopeProjectIdByPrivateToken(scope string) gin.HandlerFunc {
+	return setScopeProjectIdByAuthorizationHeader(scope, true)
+}
+
+// SetScopeProjectIdByAPIKey - Set project id scope by api key with
+// the scope on 'Authorization' header. The private token is not accepted.
+func SetScopeProjectIdByAPIKey(scope string) gin.HandlerFunc {
+	return setScopeProjectIdByAuthorizationHeader(scope, false)
+}
+
+func setScopeProjectIdByAuthorizationHeader(scope string, allowPrivateToken bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.Request.Header.Get("Authorization")
 		token = strings.TrimSpace(token)
@@ -287,16 +454,15 @@ func SetScopeProjectIdByPrivateToken() gin.HandlerFunc {
 			return
 		}
 
-		project, errCode := store.GetStore().GetProjectByPrivateToken(token)
-		if errCode != http.StatusFound {
-			errorMessage := "Invalid token"
+		errCode, errorMessage := setScopeProjectIdByPrivateTokenOrAPIKey(c, token, scope, allowPrivateToken)
+		if errCode != http.StatusOK {
 			log.WithFields(log.Fields{"error": errorMessage}).Error("Request failed because of invalid private token.")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errorMessage})
+			c.AbortWithStatusJSON(errCode, gin.H{"error": errorMessage})
 			return
 		}
-		U.SetScope(c, SCOPE_PROJECT_ID, project.ID)
 
 		c.Next()
+		recordAPIKeyUsage(c)
 	}
 }
 
@@ -316,6 +482,8 @@ func decodeBasicAuthToken(basicAuthToken string) (string, error) {
 	return token, nil
 }
 
+// SetScopeProjectPrivateTokenUsingBasicAuth - Set private token scope by private
+// token or api key with sdk:write scope on header 'Authorization': 'Basic <TOKEN>:'
 func SetScopeProjectPrivateTokenUsingBasicAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := decodeBasicAuthToken(c.Request.Header.Get("Authorization"))
@@ -325,14 +493,25 @@ func SetScopeProjectPrivateTokenUsingBasicAuth() gin.HandlerFunc {
 			return
 		}
 
+		if model.IsAPIKeyToken(token) {
+			apiKey, project, errMsg := getProjectByAPIKey(token, model.APIKeyScopeSDKWrite)
+			if apiKey == nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errMsg})
+				return
+			}
+			setScopeAPIKey(c, apiKey)
+			token = project.PrivateToken
+		}
+
 		U.SetScope(c, SCOPE_PROJECT_PRIVATE_TOKEN, token)
 		c.Next()
+		recordAPIKeyUsage(c)
 	}
 }
 
 // SetScopeProjectIdByPrivateTokenUsingBasicAuth - Set project id scope by private
-// token on header 'Authorization': 'Basic <TOKEN>:'
-func SetScopeProjectIdByPrivateTokenUsingBasicAuth() gin.HandlerFunc {
+// token or api key with the scope on header 'Authorization': 'Basic <TOKEN>:'
+func SetScopeProjectIdByPrivateTokenUsingBasicAuth(scope string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := decodeBasicAuthToken(c.Request.Header.Get("Authorization"))
 		if err != nil {
@@ -341,15 +520,14 @@ func SetScopeProjectIdByPrivateTokenUsingBasicAuth() gin.HandlerFunc {
 			return
 		}
 
-		project, errCode := store.GetStore().GetProjectByPrivateToken(token)
-		if errCode != http.StatusFound {
-			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				gin.H{"error": "Invalid authorization token"})
+		errCode, errorMessage := setScopeProjectIdByPrivateTokenOrAPIKey(c, token, scope, true)
+		if errCode != http.StatusOK {
+			c.AbortWithStatusJSON(errCode, gin.H{"error": errorMessage})
 			return
 		}
 
-		U.SetScope(c, SCOPE_PROJECT_ID, project.ID)
 		c.Next()
+		recordAPIKeyUsage(c)
 	}
 }
 
@@ -594,6 +772,110 @@ func ValidateLoggedInAgentHasAccessToRequestProject() gin.HandlerFunc {
 	}
 }
 
+// ValidateLoggedInAgentHasAccessToPayloadProject is ValidateLoggedInAgentHasAccessToRequestProject
+// for the routes which take the project_id on the json payload, as string or number.
+// The body is kept as is for the handler.
+func ValidateLoggedInAgentHasAccessToPayloadProject() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bodyBytes, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body."})
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+
+		var payload struct {
+			ProjectID json.Number `json:"project_id"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(bodyBytes))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project id on payload."})
+			return
+		}
+		payloadProjectId, err := payload.ProjectID.Int64()
+		if err != nil || payloadProjectId == 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid project id on payload."})
+			return
+		}
+
+		authorizedProjects, _ := U.GetScopeByKey(c, SCOPE_AUTHORIZED_PROJECTS).([]int64)
+		for _, pid := range authorizedProjects {
+			if payloadProjectId == pid {
+				U.SetScope(c, SCOPE_PROJECT_ID, pid)
+
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden,
+			gin.H{"error": "Unauthorized access. No projects found."})
+	}
+}
+
+// ValidateLoggedInAgentHasPermissionForRequest checks the permission required by the
+// route against the role of the agent on the project. Has to be used after
+// ValidateLoggedInAgentHasAccessToRequestProject or ValidateLoggedInAgentHasAccessToPayloadProject.
+// On the routes without a project, like the common templates, the permission is required
+// on all the projects of the agent. Denies the non GET routes without a permission mapped.
+func ValidateLoggedInAgentHasPermissionForRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permission, exists := model.GetPermissionForRequest(c.Request.Method, c.FullPath())
+		if !exists {
+			if c.Request.Method == http.MethodGet {
+				c.Next()
+				return
+			}
+
+			log.WithFields(log.Fields{"method": c.Request.Method, "route": c.FullPath()}).
+				Error("No permission mapped for the route.")
+			c.AbortWithStatusJSON(http.StatusForbidden,
+				gin.H{"error": "Access Forbidden. No permission for the route."})
+			return
+		}
+		if permission == "" {
+			c.Next()
+			return
+		}
+
+		projectId := U.GetScopeByKeyAsInt64(c, SCOPE_PROJECT_ID)
+		projectAgentMappings, _ := U.GetScopeByKey(c, SCOPE_AUTHORIZED_PROJECT_AGENT_MAPPINGS).(map[int64]model.ProjectAgentMapping)
+
+		pamsToCheck := make([]model.ProjectAgentMapping, 0)
+		if projectId == 0 {
+			for _, pam := range projectAgentMappings {
+				pamsToCheck = append(pamsToCheck, pam)
+			}
+		} else if pam, exists := projectAgentMappings[projectId]; exists {
+			pamsToCheck = append(pamsToCheck, pam)
+		}
+		if len(pamsToCheck) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden,
+				gin.H{"error": "Unauthorized access. No projects found."})
+			return
+		}
+
+		for i := range pamsToCheck {
+			permissions, errCode := store.GetStore().GetProjectAgentMappingPermissions(&pamsToCheck[i])
+			if errCode != http.StatusFound {
+				log.WithFields(log.Fields{"project_id": pamsToCheck[i].ProjectID, "agent_uuid": pamsToCheck[i].AgentUUID}).
+					Error("Failed to get permissions of the agent.")
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permissions."})
+				return
+			}
+
+			if !model.HasPermission(permissions, permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden,
+					gin.H{"error": "Access Forbidden. Missing permission " + permission + "."})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
 func validateAuthData(authDataStr string, cookieExpiry int64) (*model.Agent, string, int) {
 	if authDataStr == "" {
 		return nil, "error parsing auth data empty", http.StatusBadRequest
@@ -821,6 +1103,7 @@ func SetAuthorizedProjectsByLoggedInAgent() gin.HandlerFunc {
 		loggedInAgentUUID := U.GetScopeByKeyAsString(c, SCOPE_LOGGEDIN_AGENT_UUID)
 
 		var projectIds []int64
+		projectAgentMappingByProjectID := make(map[int64]model.ProjectAgentMapping)
 
 		projectAgentMappings, errCode := store.GetStore().GetProjectAgentMappingsByAgentUUID(loggedInAgentUUID)
 		if errCode == http.StatusInternalServerError {
@@ -830,9 +1113,11 @@ func SetAuthorizedProjectsByLoggedInAgent() gin.HandlerFunc {
 
 		for _, pam := range projectAgentMappings {
 			projectIds = append(projectIds, pam.ProjectID)
+			projectAgentMappingByProjectID[pam.ProjectID] = pam
 		}
 
 		U.SetScope(c, SCOPE_AUTHORIZED_PROJECTS, projectIds)
+		U.SetScope(c, SCOPE_AUTHORIZED_PROJECT_AGENT_MAPPINGS, projectAgentMappingByProjectID)
 		c.Next()
 	}
 }
@@ -919,8 +1204,8 @@ func ValidateAccessToSharedEntity(entityType int) gin.HandlerFunc {
 			})
 			return
 		} else if agentErrCode != http.StatusFound { // Not part of the project, check whether it is shared
-			sharedEntity, errCode := store.GetStore().GetShareableURLWithShareStringWithLargestScope(urlParamProjectId, shareString, entityType)
-			if errCode == http.StatusNotFound {
+			sharedEntities, errCode := store.GetStore().GetActiveShareableURLsWithShareString(urlParamProjectId, shareString, entityType)
+			if errCode == http.StatusNotFound || errCode == http.StatusBadRequest {
 				log.Error("No access to entity.")
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 					"error": "cannot access entity",
@@ -945,21 +1230,36 @@ func ValidateAccessToSharedEntity(entityType int) gin.HandlerFunc {
 				}
 			}
 
-			if sharedEntity.ShareType == model.ShareableURLShareTypePublic {
-				// Public share, access allowed but agent is not part of the project, so add an audit log
-				errCode = store.GetStore().CreateSharableURLAudit(sharedEntity, agentId)
-				if errCode != http.StatusOK {
-					log.Error("Failed to create audit for shared entity.")
-					c.AbortWithStatusJSON(errCode, gin.H{
-						"error": "failed to create audit",
-					})
-					return
+			var shareAccess *model.ShareAccess
+			if accessToken := c.GetHeader(model.ShareAccessTokenHeader); accessToken != "" {
+				shareAccess, err = model.GetShareAccessWithAccessToken(urlParamProjectId, accessToken)
+				if err != nil {
+					log.WithError(err).Error("Failed to get access of the share access token.")
+				}
+			}
+
+			sharedEntity, viewerEmail := getSharedEntityAccessibleToViewer(sharedEntities,
+				U.GetScopeByKeyAsString(c, SCOPE_LOGGEDIN_AGENT_EMAIL), shareAccess)
+			if sharedEntity == nil {
+				// Share types are sent for the viewer to be asked for the password or email.
+				shareTypes := make([]int, 0, len(sharedEntities))
+				for _, share := range sharedEntities {
+					shareTypes = append(shareTypes, share.ShareType)
 				}
-				// Add allowed users case
-			} else {
 				log.Error("Forbidden access to entity.")
 				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-					"error": "cannot access entity",
+					"error":       "cannot access entity",
+					"share_types": shareTypes,
+				})
+				return
+			}
+
+			// Access allowed but agent is not part of the project, so add an audit log
+			errCode = store.GetStore().CreateSharableURLAudit(sharedEntity, agentId, viewerEmail, c.ClientIP())
+			if errCode != http.StatusOK {
+				log.Error("Failed to create audit for shared entity.")
+				c.AbortWithStatusJSON(errCode, gin.H{
+					"error": "failed to create audit",
 				})
 				return
 			}
@@ -969,6 +1269,28 @@ func ValidateAccessToSharedEntity(entityType int) gin.HandlerFunc {
 	}
 }
 
+// getSharedEntityAccessibleToViewer returns the share, with the largest scope, which allows the
+// viewer who is not a member of the project and the email of the viewer verified for the share.
+func getSharedEntityAccessibleToViewer(sharedEntities []*model.ShareableURL, loggedInAgentEmail string,
+	shareAccess *model.ShareAccess) (*model.ShareableURL, string) {
+
+	for _, share := range sharedEntities {
+		switch share.ShareType {
+		case model.ShareableURLShareTypePublic:
+			return share, loggedInAgentEmail
+		case model.ShareableURLShareTypePassword, model.ShareableURLShareTypeAllowedUsers:
+			if share.IsAccessibleWithShareAccess(shareAccess) {
+				return share, shareAccess.Email
+			}
+			// Logged in agents, on the allowed users, do not need the login link.
+			if share.ShareType == model.ShareableURLShareTypeAllowedUsers && share.IsAllowedUser(loggedInAgentEmail) {
+				return share, loggedInAgentEmail
+			}
+		}
+	}
+	return nil, ""
+}
+
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -1145,44 +1467,73 @@ func FeatureMiddleware(features []string) gin.HandlerFunc {
 func RequestRateLimiterMiddleware(key string, numberOfRequestsALlowed int64, timeWindowInSeconds int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		projectId := U.GetScopeByKeyAsInt64(c, SCOPE_PROJECT_ID)
+		if isRequestRateLimited(c, projectId, key, numberOfRequestsALlowed, timeWindowInSeconds) {
+			return
+		}
+		c.Next()
+	}
+}
 
-		logCtx := log.WithFields(log.Fields{
-			"project_id": projectId,
-		})
-
-		key, err := cache.NewKey(projectId, "RL", key)
-		if err != nil {
-			logCtx.WithError(err).Error("Failed to get cache key for rate limiter middleware")
-			c.AbortWithStatus(http.StatusInternalServerError)
+// ShareLoginRateLimiterMiddleware limits the requests by project, share and client ip,
+// for the login attempts of a viewer not to block the other viewers of the share.
+// Client ip is resolved through the trusted proxies of the engine. The requests are
+// also capped by the share alone, with a higher limit, for the attempts spread over
+// many client ips. Cap by the share is skipped when numberOfRequestsALlowedPerShare is 0.
+func ShareLoginRateLimiterMiddleware(key string, numberOfRequestsALlowedPerClient int64,
+	numberOfRequestsALlowedPerShare int64, timeWindowInSeconds int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		projectId := U.GetScopeByKeyAsInt64(c, SCOPE_PROJECT_ID)
+		shareKey := fmt.Sprintf("%s:%s", key, c.Params.ByName("query_id"))
+		clientKey := fmt.Sprintf("%s:%s", shareKey, c.ClientIP())
+		if isRequestRateLimited(c, projectId, clientKey, numberOfRequestsALlowedPerClient, timeWindowInSeconds) {
 			return
 		}
-		countString, err := cacheRedis.GetPersistent(key)
-		if err != nil && err != redis.ErrNil {
-			log.WithError(err).Error("Failed to fetch rate limiter count from key")
-			c.AbortWithStatus(http.StatusInternalServerError)
+		if numberOfRequestsALlowedPerShare > 0 &&
+			isRequestRateLimited(c, projectId, shareKey, numberOfRequestsALlowedPerShare, timeWindowInSeconds) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		var count int64
-		if err != redis.ErrNil {
-			count, err = strconv.ParseInt(countString, 10, 64)
-			if err != nil {
-				logCtx.WithError(err).Error("Failed to parse rate limiter count from count string")
-				c.AbortWithStatus(http.StatusInternalServerError)
-				return
-			}
-		}
+// isRequestRateLimited counts the request on the key and aborts the request, if the
+// limit on the key is exceeded or the count is not available.
+func isRequestRateLimited(c *gin.Context, projectId int64, key string, numberOfRequestsALlowed int64, timeWindowInSeconds int) bool {
+	logCtx := log.WithFields(log.Fields{
+		"project_id": projectId,
+	})
 
-		if count > numberOfRequestsALlowed {
-			logCtx.Warn("Rate limit exceeded")
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, "Rate limit exceeded. Please try again later.")
-		}
+	cacheKey, err := cache.NewKey(projectId, "RL", key)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get cache key for rate limiter middleware")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return true
+	}
+	countString, err := cacheRedis.GetPersistent(cacheKey)
+	if err != nil && err != redis.ErrNil {
+		log.WithError(err).Error("Failed to fetch rate limiter count from key")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return true
+	}
 
-		_ = createOrIncreamentRequestCount(projectId, key, timeWindowInSeconds)
+	var count int64
+	if err != redis.ErrNil {
+		count, err = strconv.ParseInt(countString, 10, 64)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to parse rate limiter count from count string")
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return true
+		}
+	}
 
-		c.Next()
+	_ = createOrIncreamentRequestCount(projectId, cacheKey, timeWindowInSeconds)
 
+	if count > numberOfRequestsALlowed {
+		logCtx.Warn("Rate limit exceeded")
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, "Rate limit exceeded. Please try again later.")
+		return true
 	}
+	return false
 }
 
 func createOrIncreamentRequestCount(ProjectID int64, key *cache.Key, expiryInSeconds int) error {
@@ -398,6 +398,7 @@ CREATE ROWSTORE TABLE IF NOT EXISTS project_agent_mappings (
     role bigint,
     checklist_dismissed bool,
     invited_by text,
+    custom_role_id text,
     created_at timestamp(6) NOT NULL,
     updated_at timestamp(6) NOT NULL,
     KEY (updated_at),
@@ -408,6 +409,68 @@ CREATE ROWSTORE TABLE IF NOT EXISTS project_agent_mappings (
     -- Ref (project_id) -> projects(id)
     -- Ref (agent_uuid) -> agents(uuid)
     -- Ref (invited_by) -> agents(uuid)
+    -- Ref (project_id, custom_role_id) -> custom_roles(project_id, id)
+);
+
+CREATE ROWSTORE TABLE IF NOT EXISTS custom_roles (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    name text NOT NULL,
+    description text,
+    permissions json,
+    created_by text,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, id)
+);
+
+CREATE ROWSTORE TABLE IF NOT EXISTS api_keys (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    name text NOT NULL,
+    key_prefix text NOT NULL,
+    key_hash text NOT NULL,
+    scopes json,
+    expires_at timestamp(6),
+    last_used_at timestamp(6),
+    revoked_at timestamp(6),
+    rotated_from text,
+    created_by text,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    KEY (key_hash),
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, id)
+);
+
+CREATE ROWSTORE TABLE IF NOT EXISTS api_key_audits (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    api_key_id text NOT NULL,
+    action text NOT NULL,
+    agent_uuid text,
+    created_at timestamp(6) NOT NULL,
+    KEY (project_id, api_key_id),
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, id)
+);
+
+CREATE ROWSTORE TABLE IF NOT EXISTS api_key_usages (
+    project_id bigint NOT NULL,
+    api_key_id text NOT NULL,
+    method text NOT NULL,
+    route text NOT NULL,
+    status integer NOT NULL,
+    timestamp bigint NOT NULL,
+    count bigint NOT NULL,
+    client_ip text,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    KEY (project_id, api_key_id, timestamp),
+    KEY (timestamp),
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, api_key_id, method, route, status, timestamp)
 );
 
 
@@ -490,6 +553,8 @@ CREATE ROWSTORE TABLE IF NOT EXISTS project_settings (
     saml_enabled boolean,
     int_client_demandbase boolean NOT NULL DEFAULT FALSE,
     client_demandbase_key text,
+    company_enrichment_waterfall JSON,
+    session_definition JSON,
     KEY (updated_at),
     SHARD KEY (project_id),
     PRIMARY KEY (project_id)
@@ -950,6 +1015,8 @@ CREATE ROWSTORE TABLE IF NOT EXISTS shareable_urls (
     query_id text NOT NULL,
     entity_type integer NOT NULL,
     share_type integer NOT NULL,
+    allowed_users text,
+    password_hash text,
     entity_id bigint NOT NULL,
     created_at timestamp(6),
     updated_at timestamp(6),
@@ -968,11 +1035,14 @@ CREATE ROWSTORE TABLE IF NOT EXISTS shareable_url_audits (
     entity_type integer NOT NULL,
     share_type integer NOT NULL,
     entity_id bigint NOT NULL,
+    allowed_users text,
     created_at timestamp(6),
     updated_at timestamp(6),
     is_deleted boolean NOT NULL DEFAULT false,
     expires_at bigint,
     accessed_by text NOT NULL,
+    viewer_email text,
+    client_ip text,
     PRIMARY KEY (id)
 );
 
@@ -1126,6 +1196,19 @@ CREATE ROWSTORE TABLE IF NOT EXISTS crm_properties (
     -- Ref (project_id) -> projects(id)
 );
 
+CREATE ROWSTORE TABLE IF NOT EXISTS crm_custom_sources (
+    project_id bigint NOT NULL,
+    source integer NOT NULL,
+    name text NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, source),
+    UNIQUE KEY crm_custom_sources_project_id_name_unique_idx(project_id, name)
+    -- Required constraints.
+    -- Ref (project_id) -> projects(id)
+);
+
 CREATE ROWSTORE TABLE IF NOT EXISTS crm_settings (
     project_id bigint NOT NULL,
     hubspot_enrich_heavy boolean NOT NULL DEFAULT FALSE,
@@ -1402,6 +1485,18 @@ CREATE TABLE IF NOT EXISTS dash_query_results (
     KEY (project_id, id) USING CLUSTERED COLUMNSTORE,
     PRIMARY KEY (project_id, query_id, id)
 );
+
+CREATE TABLE IF NOT EXISTS attribution_day_partials (
+    project_id bigint NOT NULL,
+    query_hash text NOT NULL,
+    day_from bigint NOT NULL,
+    partial LONGBLOB NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, query_hash, day_from) USING CLUSTERED COLUMNSTORE,
+    PRIMARY KEY (project_id, query_hash, day_from)
+);
     
 CREATE TABLE IF NOT EXISTS plan_details (
     id bigint auto_increment,
@@ -1457,6 +1552,19 @@ CREATE TABLE IF NOT EXISTS account_scoring_ranges(
     PRIMARY KEY (project_id, date)
 );
 
+CREATE TABLE IF NOT EXISTS account_deal_snapshots (
+    project_id bigint NOT NULL,
+    account_id text NOT NULL,
+    closed_at bigint NOT NULL,
+    won boolean NOT NULL DEFAULT FALSE,
+    counts json,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, account_id, closed_at) USING CLUSTERED COLUMNSTORE,
+    PRIMARY KEY (project_id, account_id, closed_at)
+);
+
 CREATE TABLE IF NOT EXISTS slack_users_list(
     project_id BIGINT NOT NULL, 
     agent_id TEXT NOT NULL,
@@ -1637,3 +1745,48 @@ CREATE TABLE IF NOT EXISTS segment_folders (
     updated_at timestamp not null,
         KEY (project_id) USING CLUSTERED COLUMNSTORE
 );
+
+CREATE TABLE IF NOT EXISTS event_trigger_alert_deliveries (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    alert_id text NOT NULL,
+    delivery_option text NOT NULL,
+    destination text,
+    payload json,
+    status text NOT NULL,
+    error text,
+    latency_in_ms bigint,
+    is_retry boolean DEFAULT FALSE,
+    replay_of text,
+    replayed_at timestamp(6) NULL,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, alert_id, created_at) USING CLUSTERED COLUMNSTORE
+);
+
+CREATE ROWSTORE TABLE IF NOT EXISTS webhook_dead_letters (
+    project_id bigint NOT NULL,
+    delivery_id text NOT NULL,
+    url text,
+    payload json,
+    attempts integer NOT NULL,
+    last_status_code integer,
+    last_error text,
+    failed_at timestamp(6) NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, delivery_id)
+);
+
+CREATE TABLE IF NOT EXISTS segment_membership_changes (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    segment_id text NOT NULL,
+    user_id text NOT NULL,
+    action text NOT NULL,
+    timestamp bigint NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, segment_id, timestamp) USING CLUSTERED COLUMNSTORE
+);
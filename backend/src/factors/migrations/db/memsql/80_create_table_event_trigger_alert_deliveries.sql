@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS event_trigger_alert_deliveries (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    alert_id text NOT NULL,
+    delivery_option text NOT NULL,
+    destination text,
+    payload json,
+    status text NOT NULL,
+    error text,
+    latency_in_ms bigint,
+    is_retry boolean DEFAULT FALSE,
+    replay_of text,
+    replayed_at timestamp(6) NULL,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, alert_id, created_at) USING CLUSTERED COLUMNSTORE
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS segment_membership_changes (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    segment_id text NOT NULL,
+    user_id text NOT NULL,
+    action text NOT NULL,
+    timestamp bigint NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, segment_id, timestamp) USING CLUSTERED COLUMNSTORE
+);
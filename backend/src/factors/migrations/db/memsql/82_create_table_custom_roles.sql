@@ -0,0 +1,14 @@
+CREATE ROWSTORE TABLE IF NOT EXISTS custom_roles (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    name text NOT NULL,
+    description text,
+    permissions json,
+    created_by text,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, id)
+);
+
+ALTER TABLE project_agent_mappings ADD COLUMN custom_role_id text;
@@ -0,0 +1,30 @@
+CREATE ROWSTORE TABLE IF NOT EXISTS api_keys (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    name text NOT NULL,
+    key_prefix text NOT NULL,
+    key_hash text NOT NULL,
+    scopes json,
+    expires_at timestamp(6),
+    last_used_at timestamp(6),
+    revoked_at timestamp(6),
+    rotated_from text,
+    created_by text,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    KEY (key_hash),
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, id)
+);
+
+CREATE ROWSTORE TABLE IF NOT EXISTS api_key_audits (
+    id text NOT NULL,
+    project_id bigint NOT NULL,
+    api_key_id text NOT NULL,
+    action text NOT NULL,
+    agent_uuid text,
+    created_at timestamp(6) NOT NULL,
+    KEY (project_id, api_key_id),
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, id)
+);
@@ -0,0 +1 @@
+ALTER TABLE project_settings ADD COLUMN company_enrichment_waterfall JSON;
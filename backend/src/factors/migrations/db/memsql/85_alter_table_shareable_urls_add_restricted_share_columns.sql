@@ -0,0 +1,5 @@
+ALTER TABLE shareable_urls ADD COLUMN allowed_users text;
+ALTER TABLE shareable_urls ADD COLUMN password_hash text;
+ALTER TABLE shareable_url_audits ADD COLUMN allowed_users text;
+ALTER TABLE shareable_url_audits ADD COLUMN viewer_email text;
+ALTER TABLE shareable_url_audits ADD COLUMN client_ip text;
@@ -0,0 +1 @@
+ALTER TABLE project_settings ADD COLUMN session_definition JSON;
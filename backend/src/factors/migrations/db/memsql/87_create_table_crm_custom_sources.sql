@@ -0,0 +1,12 @@
+CREATE ROWSTORE TABLE IF NOT EXISTS crm_custom_sources (
+    project_id bigint NOT NULL,
+    source integer NOT NULL,
+    name text NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, source),
+    UNIQUE KEY crm_custom_sources_project_id_name_unique_idx(project_id, name)
+    -- Required constraints.
+    -- Ref (project_id) -> projects(id)
+);
@@ -0,0 +1,18 @@
+CREATE ROWSTORE TABLE IF NOT EXISTS api_key_usages (
+    project_id bigint NOT NULL,
+    api_key_id text NOT NULL,
+    method text NOT NULL,
+    route text NOT NULL,
+    status integer NOT NULL,
+    timestamp bigint NOT NULL,
+    count bigint NOT NULL,
+    client_ip text,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    KEY (project_id, api_key_id, timestamp),
+    KEY (timestamp),
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, api_key_id, method, route, status, timestamp)
+    -- Required constraints.
+    -- Ref (project_id, api_key_id) -> api_keys(project_id, id)
+);
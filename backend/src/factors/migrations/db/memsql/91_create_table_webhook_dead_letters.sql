@@ -0,0 +1,13 @@
+CREATE ROWSTORE TABLE IF NOT EXISTS webhook_dead_letters (
+    project_id bigint NOT NULL,
+    delivery_id text NOT NULL,
+    url text,
+    payload json,
+    attempts integer NOT NULL,
+    last_status_code integer,
+    last_error text,
+    failed_at timestamp(6) NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    PRIMARY KEY (project_id, delivery_id)
+);
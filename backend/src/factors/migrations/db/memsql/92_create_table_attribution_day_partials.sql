@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS attribution_day_partials (
+    project_id bigint NOT NULL,
+    query_hash text NOT NULL,
+    day_from bigint NOT NULL,
+    partial LONGBLOB NOT NULL,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, query_hash, day_from) USING CLUSTERED COLUMNSTORE,
+    PRIMARY KEY (project_id, query_hash, day_from)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS account_deal_snapshots (
+    project_id bigint NOT NULL,
+    account_id text NOT NULL,
+    closed_at bigint NOT NULL,
+    won boolean NOT NULL DEFAULT FALSE,
+    counts json,
+    created_at timestamp(6) NOT NULL,
+    updated_at timestamp(6) NOT NULL,
+    SHARD KEY (project_id),
+    KEY (project_id, account_id, closed_at) USING CLUSTERED COLUMNSTORE,
+    PRIMARY KEY (project_id, account_id, closed_at)
+);
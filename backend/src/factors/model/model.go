@@ -68,6 +68,7 @@ type Model interface {
 	FetchCachedResultFromDataBase(reqId string, projectID, dashboardID, unitID int64, from, to int64) (int, model.DashQueryResult)
 	FetchCachedResultFromDataBaseByQueryID(reqId string, projectID, queryID, from, to int64) (int, model.DashQueryResult)
 	GetCoalesceIDFromUserIDs(userIDs []string, projectID int64, logCtx log.Entry) (map[string]model.UserInfo, []string, error)
+	GetAccountUsersForAttribution(projectID int64, groupName string, roleProperty string, userIDs []string, logCtx log.Entry) (map[string]model.AttributionAccountUser, error)
 	PullAllUsersByCustomerUserID(projectID int64, kpiData *map[string]model.KPIInfo, logCtx log.Entry) error
 	FetchAllUsersAndCustomerUserData(projectID int64, customerUserIdList []string, logCtx log.Entry) (map[string]string, map[string][]string, error)
 	FetchAllUsersAndCustomerUserDataInBatches(projectID int64, customerUserIdList []string, logCtx log.Entry) (map[string]string, map[string][]string, error)
@@ -138,6 +139,8 @@ type Model interface {
 	GetPropertiesForLeadSquared(projectID int64, reqID string) []map[string]string
 	GetKPIConfigsForLeadSquaredLeads(projectID int64, reqID string, includeDerivedKPIs bool) (map[string]interface{}, int)
 	GetKPIConfigsForLeadSquared(projectID int64, reqID string, displayCategory string, includeDerivedKPIs bool) (map[string]interface{}, int)
+	GetPropertiesForCustomCRM(projectID int64, reqID string) []map[string]string
+	GetKPIConfigsForCustomCRMUsers(projectID int64, reqID string, includeDerivedKPIs bool) (map[string]interface{}, int)
 	GetKPIConfigsForOthers(projectID int64, reqID string, includeDerivedKPIs bool) (map[string]interface{}, int)
 	GetKPIConfigsForCustomEvents(projectID int64, reqID string, includeDerivedKPIs bool) (map[string]interface{}, int)
 
@@ -145,6 +148,8 @@ type Model interface {
 		enableOptimisedFilterOnProfileQuery bool, enableOptimisedFilterOnEventUserQuery bool) ([]model.QueryResult, int)
 	ExecuteKPIQueryForEvents(projectID int64, reqID string, kpiQuery model.KPIQuery, enableFilterOpt bool) ([]model.QueryResult, int)
 	ExecuteKPIQueryForChannels(projectID int64, reqID string, kpiQuery model.KPIQuery) ([]model.QueryResult, int)
+	ExecuteHoldoutLiftQuery(projectID int64, reqID string, query model.HoldoutLiftQuery,
+		enableOptimisedFilterOnProfileQuery bool, enableOptimisedFilterOnEventUserQuery bool) (model.QueryResult, int)
 
 	// Custom Metrics
 	CreateCustomMetric(customMetric model.CustomMetric) (*model.CustomMetric, string, int)
@@ -324,6 +329,7 @@ type Model interface {
 	CreateEvent(event *model.Event) (*model.Event, int)
 	GetEvent(projectID int64, userId string, id string) (*model.Event, int)
 	GetEventById(projectID int64, id, userID string) (*model.Event, int)
+	GetEventIDByCustomerEventID(projectID int64, customerEventID string) (string, int)
 	GetLatestEventTimeStampByEventNameId(projectId int64, eventNameId string, startTimestamp int64, endTimestamp int64) (int64, int)
 	GetLatestEventOfUserByEventNameId(projectID int64, userId string, eventNameId string, startTimestamp int64, endTimestamp int64) (*model.Event, int)
 	GetEventsByEventNameId(projectID int64, eventNameId string, startTimestamp int64, endTimestamp int64) ([]model.Event, int)
@@ -333,7 +339,7 @@ type Model interface {
 	GetUserEventsByEventNameId(projectID int64, userId string, eventNameId string) ([]model.Event, int)
 	OverwriteEventProperties(projectID int64, userId string, eventId string, newEventProperties *postgres.Jsonb) int
 	OverwriteEventPropertiesByID(projectID int64, id string, newEventProperties *postgres.Jsonb) int
-	AddSessionForUser(projectID int64, userId string, userEvents []model.Event, bufferTimeBeforeSessionCreateInSecs int64, sessionEventNameId string) (int, int, bool, int, int)
+	AddSessionForUser(projectID int64, userId string, userEvents []model.Event, bufferTimeBeforeSessionCreateInSecs int64, sessionEventNameId string, sessionDefinition model.SessionDefinition) (int, int, bool, int, int)
 	GetDatesForNextEventsArchivalBatch(projectID int64, startTime int64) (map[string]int64, int)
 	GetAllEventsForSessionCreationAsUserEventsMap(projectID int64, sessionEventNameId string, startTimestamp, endTimestamp int64) (*map[string][]model.Event, int, int)
 	GetEventsWithoutPropertiesAndWithPropertiesByNameForYourStory(projectID int64, from, to int64, mandatoryProperties []string) ([]model.EventWithProperties, *map[string]U.PropertiesMap, int)
@@ -352,7 +358,7 @@ type Model interface {
 	AssociateSessionByEventIds(projectID int64, userID string, events []*model.Event, sessionId string, sessionEventNameId string) int
 	GetHubspotFormEvents(projectID int64, userId string, timestamps []interface{}) ([]model.Event, int)
 	IsSmartEventAlreadyExist(projectID int64, userID, eventNameID, referenceEventID string, eventTimestamp int64) (bool, error)
-	GetLastEventWithSessionByUser(projectId int64, userId string, firstEventTimestamp int64) (*model.Event, int)
+	GetLastEventWithSessionByUser(projectId int64, userId string, firstEventTimestamp int64, inactivityTimeoutInSecs int64) (*model.Event, int)
 	GetAllEventsForSessionCreationAsUserEventsMapV2(projectID int64, sessionEventNameId string, startTimestamp int64, endTimestamp int64) (*map[string][]model.Event, int, int)
 	GetUserIdFromEventId(projectID int64, id string, userID string) (string, string, int)
 	GetEventsBySessionEvent(projectID int64, sessionEventID, userID string) ([]model.Event, int)
@@ -483,6 +489,28 @@ type Model interface {
 	SetSlackTeamIdForProjectAgentMappings(projectId int64, agentUUIDToUpdate string, teamId string) int
 	GetProjectAgentMappingFromSlackTeamId(teamId string) ([]model.ProjectAgentMapping, int)
 
+	// custom_role
+	CreateCustomRole(projectID int64, customRole *model.CustomRole) (*model.CustomRole, int, string)
+	GetCustomRoles(projectID int64) ([]model.CustomRole, int)
+	GetCustomRole(projectID int64, id string) (*model.CustomRole, int)
+	UpdateCustomRole(projectID int64, id string, customRole *model.CustomRole) (int, string)
+	DeleteCustomRole(projectID int64, id string) (int, string)
+	SetCustomRoleForProjectAgentMapping(projectID int64, agentUUID string, customRoleID string) int
+	GetProjectAgentMappingPermissions(pam *model.ProjectAgentMapping) ([]string, int)
+
+	// api_key
+	CreateAPIKey(projectID int64, agentUUID, name string, scopes []string, expiresAt *time.Time) (*model.APIKey, string, int, string)
+	GetAPIKeys(projectID int64) ([]model.APIKey, int)
+	GetAPIKey(projectID int64, id string) (*model.APIKey, int)
+	GetAPIKeyByKey(key string) (*model.APIKey, int)
+	RotateAPIKey(projectID int64, id, agentUUID string, gracePeriod time.Duration) (*model.APIKey, string, int, string)
+	RevokeAPIKey(projectID int64, id, agentUUID string) (int, string)
+	UpdateAPIKeyLastUsedAt(projectID int64, id string, lastUsedAt time.Time) int
+	GetAPIKeyAudits(projectID int64, id string) ([]model.APIKeyAudit, int)
+	CreateOrIncrementAPIKeyUsages(usages []model.APIKeyUsage) int
+	DeleteAPIKeyUsagesOlderThanGivenDays(expiry int) (int, error)
+	GetAPIKeyUsages(projectID int64, id string, limit int) ([]model.APIKeyUsage, int)
+
 	// project_setting
 	GetProjectSetting(projectID int64) (*model.ProjectSetting, int)
 	IsClearbitIntegratedByProjectID(projectID int64) (bool, int)
@@ -683,6 +711,8 @@ type Model interface {
 	GetUserPropertiesByUserID(projectID int64, id string) (*postgres.Jsonb, int)
 	GetUser(projectID int64, id string) (*model.User, int)
 	GetUserIDByAMPUserID(projectId int64, ampUserId string) (string, int)
+	CreateOrGetImportUser(projectID int64, anonymousID string, timestamp int64) (string, int)
+	GetUserIDByImportAnonymousID(projectID int64, anonymousID string) (string, int)
 	IsUserExistByID(projectID int64, id string) int
 	GetUsers(projectID int64, offset uint64, limit uint64) ([]model.User, int)
 	GetUsersByCustomerUserID(projectID int64, customerUserID string) ([]model.User, int)
@@ -925,14 +955,15 @@ type Model interface {
 	GetAllShareableURLsWithProjectIDAndAgentID(projectID int64, agentUUID string) ([]*model.ShareableURL, int)
 	GetShareableURLWithShareStringAndAgentID(projectID int64, shareId, agentUUID string) (*model.ShareableURL, int)
 	GetShareableURLWithShareStringWithLargestScope(projectID int64, shareId string, entityType int) (*model.ShareableURL, int)
+	GetActiveShareableURLsWithShareString(projectID int64, shareString string, entityType int) ([]*model.ShareableURL, int)
 	// GetShareableURLWithID(projectID int64, shareId string) (*model.ShareableURL, int)
-	// UpdateShareableURLShareTypeWithShareIDandCreatedBy(projectID int64, shareId, createdBy string, shareType int, allowedUsers string) int
+	UpdateShareableURLShareTypeWithShareIDandCreatedBy(projectID int64, shareId, createdBy string, shareType int, allowedUsers, passwordHash string) int
 	DeleteShareableURLWithShareIDandAgentID(projectID int64, shareId, createdBy string) int
 	DeleteShareableURLWithEntityIDandType(projectID int64, entityID int64, entityType int) int
 	RevokeShareableURLsWithShareString(projectId int64, shareString string) (int, string)
 	RevokeShareableURLsWithProjectID(projectId int64) (int, string)
 
-	CreateSharableURLAudit(sharableURL *model.ShareableURL, agentId string) int
+	CreateSharableURLAudit(sharableURL *model.ShareableURL, agentId, viewerEmail, clientIP string) int
 	ValidateCreateShareableURLRequest(params *model.ShareableURL, projectID int64, agentUUID string) (bool, string)
 
 	//crm
@@ -947,13 +978,22 @@ type Model interface {
 	GetCRMActivityInOrderForSync(projectID int64, source U.CRMSource, startTimestamp, endTimestamp int64, recordProcessLimit int) ([]model.CRMActivity, int)
 	GetCRMActivityMinimumTimestampForSync(projectID int64, source U.CRMSource) (int64, int)
 	GetCRMUsersMinimumTimestampForSync(projectID int64, source U.CRMSource) (int64, int)
-	GetCRMPropertiesForSync(projectID int64) ([]model.CRMProperty, int)
-	GetActivitiesDistinctEventNamesByType(projectID int64, objectTypes []int) (map[int][]string, int)
+	GetCRMPropertiesForSync(projectID int64, source U.CRMSource) ([]model.CRMProperty, int)
+	GetActivitiesDistinctEventNamesByType(projectID int64, source U.CRMSource, objectTypes []int) (map[int][]string, int)
 	UpdateCRMProperyAsSynced(projectID int64, source U.CRMSource, crmProperty *model.CRMProperty) (*model.CRMProperty, int)
 	UpdateCRMActivityAsSynced(projectID int64, source U.CRMSource, crmActivity *model.CRMActivity, syncID, userID string) (*model.CRMActivity, int)
 	GetCRMUsersTypeAndAction(projectID int64, source U.CRMSource) ([]model.CRMUser, int)
 	GetCRMActivityNames(projectID int64, source U.CRMSource) ([]string, int)
 	IncrementSyncTriesForCrmEnrichment(crmSource, docId string, projectId, timestamp int64, action, doctype int) int
+	GetLastSyncedCRMUserBeforeTimestamp(projectID int64, source U.CRMSource, id string, userType int, timestamp int64) (*model.CRMUser, int)
+
+	// crm custom sources
+	CreateOrGetCRMCustomSource(projectID int64, name string) (*model.CRMCustomSource, int, string)
+	GetCRMCustomSourceByName(projectID int64, name string) (*model.CRMCustomSource, int)
+	GetCRMCustomSources(projectID int64) ([]model.CRMCustomSource, int)
+	GetAllCRMCustomSources() (map[int64][]model.CRMCustomSource, int)
+	GetCRMCustomSourceObjectPropertiesName(projectID int64, sourceAlias, objectType string) ([]string, []string)
+	GetCRMCustomSourceObjectValuesByPropertyName(projectID int64, sourceAlias, objectType string, propertyName string) []interface{}
 
 	GetCRMSetting(projectID int64) (*model.CRMSetting, int)
 	GetAllCRMSetting() ([]model.CRMSetting, int)
@@ -1028,6 +1068,8 @@ type Model interface {
 	GetSegmentByGivenIds(projectId int64, segmentIds []string) (map[string][]model.Segment, int)
 	UpdateMarkerRunSegment(projectID int64, ids []string, updateTime time.Time) int
 	ModifySegment(projectID int64, segment model.Segment) (int, error)
+	CreateSegmentMembershipChanges(projectID int64, changes []model.SegmentMembershipChange) int
+	GetSegmentMembershipHistory(projectID int64, segmentID, userID string, from, to int64, limit int) ([]model.SegmentMembershipChange, int)
 
 	// Segment Folder Item ( Segment Itself )
 	MoveSegmentFolderItem(projectID int64, segmentID string, folderID string, folder_type string) int
@@ -1115,6 +1157,13 @@ type Model interface {
 	GetParagonMetadataForEventTriggerAlert(projectID int64, alertID string) (map[string]interface{}, int, error)
 	FindAndCacheAlertForCurrentSegment(projectID int64, segmentID, domainID, actionPerformed string, timeOfActionPerformed time.Time) (int, error)
 	GetAllEventTriggerAlertsBySlackTeamID(slackTeamID string) ([]model.EventTriggerAlert, error)
+	CreateEventTriggerAlertDelivery(delivery *model.EventTriggerAlertDelivery) int
+	GetEventTriggerAlertDeliveries(projectID int64, alertID, status string, limit int) ([]model.EventTriggerAlertDelivery, int)
+	GetEventTriggerAlertDeliveryByID(projectID int64, alertID, id string) (*model.EventTriggerAlertDelivery, int)
+	ReplayEventTriggerAlertDelivery(projectID int64, alertID, id string) (int, error)
+	DeleteEventTriggerAlertDeliveriesOlderThanGivenDays(expiry int) (int, error)
+	CreateWebhookDeadLetter(deadLetter *model.WebhookDeadLetter) int
+	GetWebhookDeadLetter(projectID int64, deliveryID string) (*model.WebhookDeadLetter, int)
 
 	//ExplainV2
 	GetAllExplainV2EntityByProject(projectID int64) ([]model.ExplainV2EntityInfo, int)
@@ -1160,7 +1209,11 @@ type Model interface {
 	GetUserScoreOnIds(projectId int64, usersAnonymous, usersNonAnonymous []string, debug bool) (map[string]model.PerUserScoreOnDay, error)
 	GetAccountScoreOnIds(projectId int64, accountIds []string, debug bool) (map[string]model.PerUserScoreOnDay, error)
 	GetPerAccountScore(projectId int64, timestamp string, userId string, num_days int, debug bool) (model.PerAccountScore, *model.AccWeights, string, error)
+	GetAccountScoreExplanation(projectId int64, timestamp string, userId string, numDaysToTrend int) (*model.AccScoreExplanation, int)
 	GetAllUserEvents(projectId int64, debug bool) (map[string]map[string]model.LatestScore, map[string]int, error, int64)
+	GetAccountDealOutcomes(projectId int64) ([]model.AccDealOutcome, int)
+	CreateAccountDealSnapshots(projectId int64, snapshots []model.AccDealSnapshot) int
+	GetAccountDealSnapshots(projectId int64) ([]model.AccDealSnapshot, int)
 	WriteScoreRanges(projectId int64, buckets []model.BucketRanges) error
 	GetEngagementBucketsOnProject(projectId int64, timestamp string) (model.BucketRanges, error)
 
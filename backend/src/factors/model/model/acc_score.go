@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +18,7 @@ import (
 const DEFAULT_EVENT string = "all_events"
 const LAST_EVENT string = "LAST_EVENT"
 const NUM_TREND_DAYS int = 30
+const MAX_NUM_TREND_DAYS int = 90
 
 const (
 	ENGAGEMENT_LEVEL_HOT  = "Hot"
@@ -55,9 +57,47 @@ type AccAggScore struct {
 	TimeStamp    time.Time `json:"time"`
 }
 
+const (
+	// decay of the event counts with the days since the event.
+	ACC_SCORE_DECAY_LINEAR    = "linear"
+	ACC_SCORE_DECAY_HALF_LIFE = "half_life"
+
+	// weights entered on the weight config or fitted from the won and lost deals.
+	ACC_SCORE_WEIGHT_MODE_MANUAL = "manual"
+	ACC_SCORE_WEIGHT_MODE_FITTED = "fitted"
+
+	DEFAULT_HALF_LIFE_DAYS int64 = 14
+)
+
 type AccWeights struct {
 	WeightConfig []AccEventWeight `json:"WeightConfig"`
 	SaleWindow   int64            `json:"salewindow"`
+	Decay        string           `json:"decay"`
+	// days after which an event counts for half, used with half life decay.
+	HalfLife   int64  `json:"half_life"`
+	WeightMode string `json:"weight_mode"`
+	// weight values by weight id, fitted by the scoring job.
+	FittedWeights map[string]float32 `json:"fitted_weights"`
+	FittedAt      int64              `json:"fitted_at"`
+	// version of the config, set on update, and the version the weights were fitted on. Fitted
+	// weights are used only on the version they were fitted on.
+	Version       string `json:"version"`
+	FittedVersion string `json:"fitted_version"`
+}
+
+type AccDealOutcome struct {
+	AccountID string
+	Won       bool
+	ClosedAt  int64
+}
+
+// AccDealSnapshot holds the counts of the account on the sale window before the close of the deal,
+// by day. Counts are kept on the close of the deal, as the older days are not kept on the account.
+type AccDealSnapshot struct {
+	AccountID string                 `json:"account_id"`
+	Won       bool                   `json:"won"`
+	ClosedAt  int64                  `json:"closed_at"`
+	Counts    map[string]LatestScore `json:"counts"`
 }
 
 type AccEventWeight struct {
@@ -267,6 +307,35 @@ func ComputeDecayValue(ts string, SaleWindow int64) float64 {
 	return decay
 }
 
+// ComputeDecayValueForWeights returns the decay of the day as configured on the weights.
+func ComputeDecayValueForWeights(ts string, weights *AccWeights) float64 {
+	currentTS := time.Now().Unix()
+	EventTs := U.GetDateFromString(ts)
+	return ComputeDecayValueGivenStartEndTSForWeights(currentTS, EventTs, weights)
+}
+
+func ComputeDecayValueGivenStartEndTSForWeights(start int64, end int64, weights *AccWeights) float64 {
+	if weights.Decay != ACC_SCORE_DECAY_HALF_LIFE {
+		return ComputeDecayValueGivenStartEndTS(start, end, weights.SaleWindow)
+	}
+
+	halfLife := weights.HalfLife
+	if halfLife <= 0 {
+		halfLife = DEFAULT_HALF_LIFE_DAYS
+	}
+	return ComputeHalfLifeDecayValueGivenStartEndTS(start, end, weights.SaleWindow, halfLife)
+}
+
+// ComputeHalfLifeDecayValueGivenStartEndTS halves the value for every halfLife days,
+// events beyond the sale window are not counted.
+func ComputeHalfLifeDecayValueGivenStartEndTS(start int64, end int64, SaleWindow int64, halfLife int64) float64 {
+	dayDiff := U.ComputeDayDifference(start, end)
+	if int64(dayDiff) > SaleWindow {
+		return 0
+	}
+	return math.Pow(0.5, float64(dayDiff)/float64(halfLife))
+}
+
 func ComputeDecayValueGivenStartEndTS(start int64, end int64, SaleWindow int64) float64 {
 	var decay float64
 	// get difference in weeks
@@ -280,6 +349,67 @@ func ComputeDecayValueGivenStartEndTS(start int64, end int64, SaleWindow int64)
 	return decay
 }
 
+// GetAccWeightsVersion returns the version of the config the weights are fitted on, which
+// changes with the weights, the sale window or the decay.
+func GetAccWeightsVersion(weights *AccWeights) string {
+	weightIds := make([]string, 0)
+	for _, weight := range weights.WeightConfig {
+		if !weight.Is_deleted {
+			weightIds = append(weightIds, weight.WeightId)
+		}
+	}
+	sort.Strings(weightIds)
+
+	version, err := U.GenerateHashStringForStruct(map[string]interface{}{
+		"weight_ids": weightIds,
+		"salewindow": weights.SaleWindow,
+		"decay":      weights.Decay,
+		"half_life":  weights.HalfLife,
+	})
+	if err != nil {
+		return ""
+	}
+	return version
+}
+
+// GetScoringWeightValue returns the fitted value of the weight on fitted mode,
+// if fitted on the current version of the config, else the value on the weight config.
+func GetScoringWeightValue(weights *AccWeights, weight AccEventWeight) float32 {
+	if weights.WeightMode == ACC_SCORE_WEIGHT_MODE_FITTED && weights.FittedVersion != "" &&
+		weights.FittedVersion == weights.Version {
+		if value, exists := weights.FittedWeights[weight.WeightId]; exists {
+			return value
+		}
+	}
+	return weight.Weight_value
+}
+
+// ValidateScoringMode checks the decay and weight mode on the weights.
+func ValidateScoringMode(weights *AccWeights) error {
+	if weights.Decay != "" && weights.Decay != ACC_SCORE_DECAY_LINEAR && weights.Decay != ACC_SCORE_DECAY_HALF_LIFE {
+		return fmt.Errorf("invalid decay %s", weights.Decay)
+	}
+	if weights.HalfLife < 0 || (weights.SaleWindow > 0 && weights.HalfLife > weights.SaleWindow) {
+		return fmt.Errorf("half life should be within the sale window")
+	}
+	if weights.WeightMode != "" && weights.WeightMode != ACC_SCORE_WEIGHT_MODE_MANUAL &&
+		weights.WeightMode != ACC_SCORE_WEIGHT_MODE_FITTED {
+		return fmt.Errorf("invalid weight mode %s", weights.WeightMode)
+	}
+	return nil
+}
+
+// CopyScoringMode copies the decay, weight mode and the fitted weights.
+func CopyScoringMode(from *AccWeights, to *AccWeights) {
+	to.Decay = from.Decay
+	to.HalfLife = from.HalfLife
+	to.WeightMode = from.WeightMode
+	to.FittedWeights = from.FittedWeights
+	to.FittedAt = from.FittedAt
+	to.Version = from.Version
+	to.FittedVersion = from.FittedVersion
+}
+
 func removeZeros(input []float64) []float64 {
 	var result []float64
 
@@ -395,6 +525,7 @@ func DeduplicateWeights(weights AccWeights) (AccWeights, error) {
 
 	updatedWeights.WeightConfig = weightrulelist
 	updatedWeights.SaleWindow = weights.SaleWindow
+	CopyScoringMode(&weights, &updatedWeights)
 	return updatedWeights, nil
 }
 
@@ -429,6 +560,7 @@ func UpdateWeights(projectId int64, weightsRequest AccWeights, requestID string)
 	weights.WeightConfig = make([]AccEventWeight, 0)
 	filterNamesMap := make(map[string]int)
 	weights.SaleWindow = weightsRequest.SaleWindow
+	CopyScoringMode(&weightsRequest, &weights)
 
 	// check for duplicate rule names first , in case of empty rule name
 	// fill it with the event names
@@ -487,3 +619,124 @@ func DefaultEngagementBuckets() BucketRanges {
 	return defaultBucket
 
 }
+
+// AccScoreExplanation is the breakdown of the score of an account on a day.
+type AccScoreExplanation struct {
+	Id                 string                 `json:"id"`
+	Timestamp          string                 `json:"timestamp"`
+	Score              float32                `json:"score"`
+	Engagement         string                 `json:"engagement"`
+	PreviousScore      float32                `json:"previous_score"`
+	PreviousEngagement string                 `json:"previous_engagement"`
+	RuleContributions  []AccScoreContribution `json:"rule_contributions"`
+	EventContributions []AccScoreContribution `json:"event_contributions"`
+	// contributions changed since the previous day, by the largest change.
+	Changes []AccScoreContribution `json:"changes"`
+	Trend   map[string]float32     `json:"trend"`
+}
+
+// AccScoreContribution is the part of the score from a weight rule or an event.
+type AccScoreContribution struct {
+	WeightId  string `json:"wid,omitempty"`
+	Name      string `json:"name"`
+	EventName string `json:"event_name"`
+	// count of events after decay.
+	Count      float64 `json:"count"`
+	Score      float64 `json:"score"`
+	Percentage float64 `json:"percentage"`
+	Change     float64 `json:"change"`
+}
+
+// ComputeAccScoreContributions returns the contribution of each weight rule to the score
+// from the aggregated counts of the account, as the decayed count of the rule times the
+// weight value. Contributions add up to the score, by the largest contribution.
+func ComputeAccScoreContributions(weights *AccWeights, eventsCount map[string]float64,
+	decay float64) ([]AccScoreContribution, float64) {
+
+	contributions := make([]AccScoreContribution, 0)
+	totalScore := float64(0)
+	for _, weight := range weights.WeightConfig {
+		count, exists := eventsCount[weight.WeightId]
+		if weight.Is_deleted || !exists {
+			continue
+		}
+
+		name := weight.FilterName
+		if name == "" {
+			name = weight.EventName
+		}
+		score := decay * count * float64(GetScoringWeightValue(weights, weight))
+		contributions = append(contributions, AccScoreContribution{WeightId: weight.WeightId, Name: name,
+			EventName: weight.EventName, Count: decay * count, Score: score})
+		totalScore += score
+	}
+	setAccScoreContributionPercentages(contributions, totalScore)
+	return contributions, totalScore
+}
+
+// GetAccScoreEventContributions groups the contributions of the rules by the event.
+func GetAccScoreEventContributions(ruleContributions []AccScoreContribution, totalScore float64) []AccScoreContribution {
+	contributionsByEvent := make(map[string]*AccScoreContribution)
+	for _, ruleContribution := range ruleContributions {
+		if _, exists := contributionsByEvent[ruleContribution.EventName]; !exists {
+			contributionsByEvent[ruleContribution.EventName] = &AccScoreContribution{
+				Name: ruleContribution.EventName, EventName: ruleContribution.EventName}
+		}
+		contributionsByEvent[ruleContribution.EventName].Count += ruleContribution.Count
+		contributionsByEvent[ruleContribution.EventName].Score += ruleContribution.Score
+	}
+
+	contributions := make([]AccScoreContribution, 0, len(contributionsByEvent))
+	for _, contribution := range contributionsByEvent {
+		contributions = append(contributions, *contribution)
+	}
+	setAccScoreContributionPercentages(contributions, totalScore)
+	return contributions
+}
+
+// GetAccScoreContributionChanges returns the rules with a change in the contribution
+// from the previous contributions.
+func GetAccScoreContributionChanges(contributions, previousContributions []AccScoreContribution) []AccScoreContribution {
+	previousByWeightId := make(map[string]AccScoreContribution)
+	for _, previous := range previousContributions {
+		previousByWeightId[previous.WeightId] = previous
+	}
+
+	changes := make([]AccScoreContribution, 0)
+	for _, contribution := range contributions {
+		contribution.Change = contribution.Score - previousByWeightId[contribution.WeightId].Score
+		delete(previousByWeightId, contribution.WeightId)
+		if contribution.Change != 0 {
+			changes = append(changes, contribution)
+		}
+	}
+	// rules which stopped contributing.
+	for _, previous := range previousByWeightId {
+		if previous.Score != 0 {
+			changes = append(changes, AccScoreContribution{WeightId: previous.WeightId, Name: previous.Name,
+				EventName: previous.EventName, Change: -1 * previous.Score})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if math.Abs(changes[i].Change) == math.Abs(changes[j].Change) {
+			return changes[i].Name < changes[j].Name
+		}
+		return math.Abs(changes[i].Change) > math.Abs(changes[j].Change)
+	})
+	return changes
+}
+
+func setAccScoreContributionPercentages(contributions []AccScoreContribution, totalScore float64) {
+	for idx := range contributions {
+		if totalScore > 0 {
+			contributions[idx].Percentage = math.Round(contributions[idx].Score/totalScore*10000) / 100
+		}
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].Score == contributions[j].Score {
+			return contributions[i].Name < contributions[j].Name
+		}
+		return contributions[i].Score > contributions[j].Score
+	})
+}
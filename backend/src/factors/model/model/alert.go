@@ -2,6 +2,9 @@ package model
 
 import (
 	U "factors/util"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/jinzhu/gorm/dialects/postgres"
@@ -25,6 +28,20 @@ const (
 	PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN = "%_has_increased_or_decreased_by_more_than"
 	PREVIOUS_PERIOD                                    = "previous_period"
 	SAME_PERIOD_LAST_YEAR                              = "same_period_last_year"
+	// anomaly operators flag the kpi outside the range expected from the history,
+	// value of the alert is the number of deviations allowed from the expected value.
+	IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV = "is_outside_expected_range_by_std_dev"
+	IS_OUTSIDE_EXPECTED_RANGE_BY_MAD     = "is_outside_expected_range_by_mad"
+)
+
+const (
+	ANOMALY_DEFAULT_DEVIATIONS   = 3
+	ANOMALY_BASELINE_PERIODS     = 8
+	ANOMALY_MIN_BASELINE_PERIODS = 4
+	// scales MAD to be comparable with the standard deviation for normal data.
+	MAD_SCALE_FACTOR = 1.4826
+	// minimum spread as a ratio of the expected value, for the history without variation.
+	ANOMALY_MIN_SPREAD_RATIO = 0.01
 )
 
 // last quarter, last month to be added
@@ -34,7 +51,8 @@ var ValidDateRangeComparisions = []string{
 }
 
 var ValidValues = []string{}
-var ValidOperators = []string{IS_LESS_THAN, IS_GREATER_THAN, DECREASED_BY_MORE_THAN, INCREASED_BY_MORE_THAN, INCREASED_OR_DECREASED_BY_MORE_THAN, PERCENTAGE_HAS_DECREASED_BY_MORE_THAN, PERCENTAGE_HAS_INCREASED_BY_MORE_THAN, PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN}
+var ValidOperators = []string{IS_LESS_THAN, IS_GREATER_THAN, DECREASED_BY_MORE_THAN, INCREASED_BY_MORE_THAN, INCREASED_OR_DECREASED_BY_MORE_THAN, PERCENTAGE_HAS_DECREASED_BY_MORE_THAN, PERCENTAGE_HAS_INCREASED_BY_MORE_THAN, PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN,
+	IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV, IS_OUTSIDE_EXPECTED_RANGE_BY_MAD}
 
 type Alert struct {
 	ID                 string          `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
@@ -111,3 +129,101 @@ func DecodeAndFetchAlertRelatedStructs(projectID int64, alert Alert) (AlertDescr
 
 	return alertDescription, alertConfiguration, kpiQuery, err
 }
+
+// AlertBaseline is the range of the kpi expected from the history.
+type AlertBaseline struct {
+	Expected   float64 `json:"expected"`
+	Spread     float64 `json:"spread"`
+	Deviations float64 `json:"deviations"`
+	LowerBound float64 `json:"lower_bound"`
+	UpperBound float64 `json:"upper_bound"`
+}
+
+func IsAnomalyAlertOperator(operator string) bool {
+	return operator == IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV || operator == IS_OUTSIDE_EXPECTED_RANGE_BY_MAD
+}
+
+/*
+ComputeAlertBaseline computes the expected range from the values of the history periods.
+ 1. IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV uses mean ± deviations·σ.
+ 2. IS_OUTSIDE_EXPECTED_RANGE_BY_MAD uses median ± deviations·MAD, which is not skewed by the past outliers.
+*/
+func ComputeAlertBaseline(operator string, history []float64, deviations float64) (AlertBaseline, error) {
+	var baseline AlertBaseline
+	if len(history) < ANOMALY_MIN_BASELINE_PERIODS {
+		return baseline, fmt.Errorf("not enough history to compute baseline, required %d periods, found %d",
+			ANOMALY_MIN_BASELINE_PERIODS, len(history))
+	}
+	if deviations <= 0 {
+		deviations = ANOMALY_DEFAULT_DEVIATIONS
+	}
+
+	switch operator {
+	case IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV:
+		baseline.Expected = getMean(history)
+		baseline.Spread = getStdDev(history, baseline.Expected)
+	case IS_OUTSIDE_EXPECTED_RANGE_BY_MAD:
+		baseline.Expected = getMedian(history)
+		absoluteDeviations := make([]float64, 0, len(history))
+		for _, value := range history {
+			absoluteDeviations = append(absoluteDeviations, math.Abs(value-baseline.Expected))
+		}
+		baseline.Spread = MAD_SCALE_FACTOR * getMedian(absoluteDeviations)
+	default:
+		return baseline, fmt.Errorf("invalid anomaly operator %s", operator)
+	}
+
+	minSpread := ANOMALY_MIN_SPREAD_RATIO * math.Abs(baseline.Expected)
+	if minSpread == 0 {
+		minSpread = ANOMALY_MIN_SPREAD_RATIO
+	}
+	if baseline.Spread < minSpread {
+		baseline.Spread = minSpread
+	}
+	baseline.Deviations = deviations
+	baseline.LowerBound = baseline.Expected - deviations*baseline.Spread
+	baseline.UpperBound = baseline.Expected + deviations*baseline.Spread
+	return baseline, nil
+}
+
+// GetDeviationScore returns the number of spreads the value is away from the expected value.
+func (baseline AlertBaseline) GetDeviationScore(value float64) float64 {
+	if baseline.Spread == 0 {
+		return 0
+	}
+	return (value - baseline.Expected) / baseline.Spread
+}
+
+func (baseline AlertBaseline) IsOutsideExpectedRange(value float64) bool {
+	return value < baseline.LowerBound || value > baseline.UpperBound
+}
+
+func getMean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func getStdDev(values []float64, mean float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sumOfSquares float64
+	for _, value := range values {
+		sumOfSquares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sumOfSquares / float64(len(values)-1))
+}
+
+func getMedian(values []float64) float64 {
+	sortedValues := make([]float64, len(values))
+	copy(sortedValues, values)
+	sort.Float64s(sortedValues)
+	mid := len(sortedValues) / 2
+	if len(sortedValues)%2 == 0 {
+		return (sortedValues[mid-1] + sortedValues[mid]) / 2
+	}
+	return sortedValues[mid]
+}
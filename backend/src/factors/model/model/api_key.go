@@ -0,0 +1,174 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	U "factors/util"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
+)
+
+// APIKey is a named key of the project for the private API, in addition to the
+// private token of the project. Only the hash of the key is stored, the key is
+// returned once on create and rotate.
+type APIKey struct {
+	ID        string `gorm:"primary_key:true;type:varchar(255)" json:"id"`
+	ProjectID int64  `gorm:"primary_key:true" json:"project_id"`
+	Name      string `json:"name"`
+	// First characters of the key, to identify the key on the list.
+	KeyPrefix  string          `json:"key_prefix"`
+	KeyHash    string          `json:"-"`
+	Scopes     *postgres.Jsonb `json:"scopes"`
+	ExpiresAt  *time.Time      `json:"expires_at"`
+	LastUsedAt *time.Time      `json:"last_used_at"`
+	RevokedAt  *time.Time      `json:"revoked_at"`
+	// Key which was rotated to this key.
+	RotatedFrom *string   `gorm:"type:varchar(255)" json:"rotated_from"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// APIKeyAudit is an action taken on the API key by an agent.
+type APIKeyAudit struct {
+	ID        string    `gorm:"primary_key:true;type:varchar(255)" json:"id"`
+	ProjectID int64     `gorm:"primary_key:true" json:"project_id"`
+	APIKeyID  string    `json:"api_key_id"`
+	Action    string    `json:"action"`
+	AgentUUID string    `json:"agent_uuid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKeyUsage is the count of the requests made with the API key on a minute,
+// by the route and the status.
+type APIKeyUsage struct {
+	ProjectID int64  `gorm:"primary_key:true" json:"project_id"`
+	APIKeyID  string `gorm:"primary_key:true" json:"api_key_id"`
+	Method    string `gorm:"primary_key:true" json:"method"`
+	Route     string `gorm:"primary_key:true" json:"route"`
+	Status    int    `gorm:"primary_key:true" json:"status"`
+	// Start of the minute, in unix seconds.
+	Timestamp int64 `gorm:"primary_key:true" json:"timestamp"`
+	Count     int64 `json:"count"`
+	// Client ip of the latest request on the minute.
+	ClientIP  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const (
+	APIKeyScopeSDKWrite      = "sdk:write"
+	APIKeyScopeQueryRead     = "query:read"
+	APIKeyScopeProfilesRead  = "profiles:read"
+	APIKeyScopeAccountsWrite = "accounts:write"
+	APIKeyScopeCRMWrite      = "crm:write"
+
+	APIKeyAuditActionCreated = "created"
+	APIKeyAuditActionRotated = "rotated"
+	APIKeyAuditActionRevoked = "revoked"
+
+	// Prefix to differentiate the API keys from the private token of the project.
+	APIKeyTokenPrefix    = "fak_"
+	apiKeyRandomBytes    = 24
+	apiKeyDisplayLength  = len(APIKeyTokenPrefix) + 6
+	MaxAPIKeysPerProject = 50
+	// Number of latest usages returned on the usage of the key.
+	MaxAPIKeyUsagesLimit = 1000
+	// Usages older than the retention are deleted by the cleanup job.
+	APIKeyUsageRetentionDays = 90
+
+	// Old key stays valid for the grace period after rotation, for the callers to move to the new key.
+	DefaultAPIKeyRotationGracePeriod = 24 * time.Hour
+	MaxAPIKeyRotationGracePeriod     = 30 * 24 * time.Hour
+)
+
+var APIKeyScopes = []string{
+	APIKeyScopeSDKWrite,
+	APIKeyScopeQueryRead,
+	APIKeyScopeProfilesRead,
+	APIKeyScopeAccountsWrite,
+	APIKeyScopeCRMWrite,
+}
+
+func IsValidAPIKeyScope(scope string) bool {
+	for _, validScope := range APIKeyScopes {
+		if scope == validScope {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAPIKeyScopes checks the scopes and returns them as json.
+func ValidateAPIKeyScopes(scopes []string) (*postgres.Jsonb, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("scopes are required")
+	}
+	for _, scope := range scopes {
+		if !IsValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("invalid scope %s", scope)
+		}
+	}
+	return U.EncodeStructTypeToPostgresJsonb(scopes)
+}
+
+func GetAPIKeyScopes(apiKey *APIKey) ([]string, error) {
+	scopes := make([]string, 0)
+	if apiKey == nil || apiKey.Scopes == nil {
+		return scopes, nil
+	}
+	if err := U.DecodePostgresJsonbToStructType(apiKey.Scopes, &scopes); err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
+
+func IsAPIKeyToken(token string) bool {
+	return strings.HasPrefix(token, APIKeyTokenPrefix)
+}
+
+// GenerateAPIKey returns a new key with its prefix and hash.
+func GenerateAPIKey() (key, keyPrefix, keyHash string, err error) {
+	randomBytes := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyTokenPrefix + hex.EncodeToString(randomBytes)
+	return key, key[:apiKeyDisplayLength], GetAPIKeyHash(key), nil
+}
+
+func GetAPIKeyHash(key string) string {
+	return U.HashKeyUsingSha256Checksum(key)
+}
+
+// ValidateAPIKeyForScope checks the key is active at the time and carries the scope.
+func ValidateAPIKeyForScope(apiKey *APIKey, scope string, now time.Time) error {
+	if apiKey == nil {
+		return errors.New("invalid api key")
+	}
+	if apiKey.RevokedAt != nil && !apiKey.RevokedAt.After(now) {
+		return errors.New("api key is revoked")
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return errors.New("api key is expired")
+	}
+	if scope == "" {
+		return nil
+	}
+
+	scopes, err := GetAPIKeyScopes(apiKey)
+	if err != nil {
+		return errors.New("invalid scopes on api key")
+	}
+	for i := range scopes {
+		if scopes[i] == scope {
+			return nil
+		}
+	}
+	return fmt.Errorf("api key does not have the scope %s", scope)
+}
@@ -41,6 +41,21 @@ type AttributionQuery struct {
 	// Tactic or Offer or TacticOffer
 	TacticOfferType string `json:"tactic_offer_type"`
 	Timezone        string `json:"time_zone"`
+	// user (default) or account, account rolls up touch points of all the users of a group
+	AttributionLevel string `json:"attribution_level,omitempty"`
+	// group used as account on account level, defaults to $domains
+	AccountGroupName string `json:"account_group_name,omitempty"`
+	// user property holding the buying committee role and the weight per role, roles not listed weigh 1
+	RoleProperty string             `json:"role_property,omitempty"`
+	RoleWeights  map[string]float64 `json:"role_weights,omitempty"`
+	// adds credit per key under both the methodologies and the top paths explaining the delta to the result meta
+	MethodologyComparisonDetails bool `json:"methodology_comparison_details,omitempty"`
+	// computed while running account level attribution, account -> key -> role weight
+	AccountKeyRoleWeights map[string]map[string]float64 `json:"-"`
+	// attribution under both the methodologies, kept by the run for the methodology comparison details
+	MethodologyComparisonHits *MethodologyComparisonHits `json:"-"`
+	// touch point paths of a sample of the non converted users, pulled for the data driven methodology
+	NonConvertedPaths [][]string `json:"-"`
 }
 
 type KPIInfo struct {
@@ -77,6 +92,7 @@ const (
 	AttributionMethodTimeDecay           = "Time_Decay"
 	AttributionMethodInfluence           = "Influence"
 	AttributionMethodWShaped             = "W_Shaped"
+	AttributionMethodDataDriven          = "Data_Driven"
 
 	AttributionKeyCampaign    = "Campaign"
 	AttributionKeySource      = "Source"
@@ -172,6 +188,10 @@ const (
 	AnalyzeTypeHSDeals         = "hubspot_deals"            // Supports RunTypeHSDeals, RunTypeHSCompanies
 	AnalyzeTypeUserKPI         = "user_kpi"                 // Supports
 
+	// query.AttributionLevel
+	AttributionLevelUser    = "user"
+	AttributionLevelAccount = "account"
+
 	// query.RunType
 	RunTypeSFOpportunities = "salesforce_opportunities"
 	RunTypeSFAccounts      = "salesforce_accounts"
@@ -529,6 +549,8 @@ const (
 	UserBatchSize          = 2000
 	QueryRangeLimit        = 93
 	LookBackWindowLimit    = 370
+	// non converted users sampled for the data driven methodology
+	DataDrivenNonConvertedUsersLimit = 20000
 )
 
 // LookbackAdjustedFrom Returns the effective From timestamp considering lookback days
@@ -0,0 +1,261 @@
+package model
+
+import (
+	U "factors/util"
+	"fmt"
+	"strings"
+)
+
+const DefaultBuyingCommitteeRoleWeight = float64(1)
+
+// AttributionAccountUser is a user of an account considered on account level attribution.
+type AttributionAccountUser struct {
+	CoalUserID string
+	AccountID  string
+	Role       string
+}
+
+func IsAccountLevelAttribution(query *AttributionQuery) bool {
+	return query != nil && query.AttributionLevel == AttributionLevelAccount
+}
+
+func GetAttributionAccountGroupName(query *AttributionQuery) string {
+	if query.AccountGroupName == "" {
+		return GROUP_NAME_DOMAINS
+	}
+	return query.AccountGroupName
+}
+
+func IsAccountLevelAttributionV1(query *AttributionQueryV1) bool {
+	return query != nil && query.AttributionLevel == AttributionLevelAccount
+}
+
+func GetAttributionAccountGroupNameV1(query *AttributionQueryV1) string {
+	if query.AccountGroupName == "" {
+		return GROUP_NAME_DOMAINS
+	}
+	return query.AccountGroupName
+}
+
+// GetBuyingCommitteeRoleWeight returns the weight configured for the role, roles are matched ignoring case.
+func GetBuyingCommitteeRoleWeight(roleWeights map[string]float64, role string) float64 {
+	role = strings.TrimSpace(role)
+	if role == "" {
+		return DefaultBuyingCommitteeRoleWeight
+	}
+	if weight, exists := roleWeights[role]; exists {
+		return weight
+	}
+	for configuredRole, weight := range roleWeights {
+		if strings.EqualFold(configuredRole, role) {
+			return weight
+		}
+	}
+	return DefaultBuyingCommitteeRoleWeight
+}
+
+// GetCoalUserIDToAccountMaps returns coal_user_id -> account_id and coal_user_id -> role
+// for the users of the accounts.
+func GetCoalUserIDToAccountMaps(accountUsers map[string]AttributionAccountUser) (map[string]string, map[string]string) {
+	coalUserIDToAccount := make(map[string]string)
+	coalUserIDToRole := make(map[string]string)
+	for _, accountUser := range accountUsers {
+		if _, exists := coalUserIDToAccount[accountUser.CoalUserID]; !exists {
+			coalUserIDToAccount[accountUser.CoalUserID] = accountUser.AccountID
+		}
+		if coalUserIDToRole[accountUser.CoalUserID] == "" {
+			coalUserIDToRole[accountUser.CoalUserID] = accountUser.Role
+		}
+	}
+	return coalUserIDToAccount, coalUserIDToRole
+}
+
+func getAccountForCoalUserID(coalUserIDToAccount map[string]string, coalUserID string) string {
+	if accountID, exists := coalUserIDToAccount[coalUserID]; exists && accountID != "" {
+		return accountID
+	}
+	// users not part of any account are considered as an account by themselves.
+	return coalUserID
+}
+
+// RollUpSessionsToAccounts merges the sessions of all the users of an account. It also returns
+// the role weight of each key per account, which is the highest weight among the roles which touched the key.
+func RollUpSessionsToAccounts(sessions map[string]map[string]UserSessionData, coalUserIDToAccount map[string]string,
+	coalUserIDToRole map[string]string, roleWeights map[string]float64) (map[string]map[string]UserSessionData,
+	map[string]map[string]float64) {
+
+	accountSessions := make(map[string]map[string]UserSessionData)
+	accountKeyRoleWeights := make(map[string]map[string]float64)
+	for coalUserID, userSessions := range sessions {
+		accountID := getAccountForCoalUserID(coalUserIDToAccount, coalUserID)
+		if _, exists := accountSessions[accountID]; !exists {
+			accountSessions[accountID] = make(map[string]UserSessionData)
+			accountKeyRoleWeights[accountID] = make(map[string]float64)
+		}
+		roleWeight := GetBuyingCommitteeRoleWeight(roleWeights, coalUserIDToRole[coalUserID])
+
+		for attributionKey, newUserSession := range userSessions {
+			if existingUserSession, exists := accountSessions[accountID][attributionKey]; exists {
+				existingUserSession.MinTimestamp = U.Min(existingUserSession.MinTimestamp, newUserSession.MinTimestamp)
+				existingUserSession.MaxTimestamp = U.Max(existingUserSession.MaxTimestamp, newUserSession.MaxTimestamp)
+				existingUserSession.TimeStamps = append(existingUserSession.TimeStamps, newUserSession.TimeStamps...)
+				existingUserSession.WithinQueryPeriod = existingUserSession.WithinQueryPeriod || newUserSession.WithinQueryPeriod
+				accountSessions[accountID][attributionKey] = existingUserSession
+			} else {
+				newUserSession.TimeStamps = append([]int64{}, newUserSession.TimeStamps...)
+				accountSessions[accountID][attributionKey] = newUserSession
+			}
+
+			if existingWeight, exists := accountKeyRoleWeights[accountID][attributionKey]; !exists || roleWeight > existingWeight {
+				accountKeyRoleWeights[accountID][attributionKey] = roleWeight
+			}
+		}
+	}
+	return accountSessions, accountKeyRoleWeights
+}
+
+// RollUpConversionsToAccounts keeps the earliest conversion of each event per account.
+func RollUpConversionsToAccounts(usersToBeAttributed []UserEventInfo, coalUserIdConversionTimestamp map[string]int64,
+	coalUserIDToAccount map[string]string) ([]UserEventInfo, map[string]int64) {
+
+	accountConversionTimestamp := make(map[string]int64)
+	for coalUserID, timestamp := range coalUserIdConversionTimestamp {
+		accountID := getAccountForCoalUserID(coalUserIDToAccount, coalUserID)
+		if existing, exists := accountConversionTimestamp[accountID]; !exists || timestamp < existing {
+			accountConversionTimestamp[accountID] = timestamp
+		}
+	}
+
+	var accountsToBeAttributed []UserEventInfo
+	indexOfAccountEvent := make(map[string]int)
+	for _, userEvent := range usersToBeAttributed {
+		accountID := getAccountForCoalUserID(coalUserIDToAccount, userEvent.CoalUserID)
+		accountEventKey := fmt.Sprintf("%s:%s:%d", accountID, userEvent.EventName, userEvent.EventType)
+
+		if index, exists := indexOfAccountEvent[accountEventKey]; exists {
+			if userEvent.Timestamp < accountsToBeAttributed[index].Timestamp {
+				accountsToBeAttributed[index].Timestamp = userEvent.Timestamp
+			}
+			continue
+		}
+		indexOfAccountEvent[accountEventKey] = len(accountsToBeAttributed)
+		accountsToBeAttributed = append(accountsToBeAttributed, UserEventInfo{CoalUserID: accountID,
+			EventName: userEvent.EventName, Timestamp: userEvent.Timestamp, EventType: userEvent.EventType})
+	}
+	return accountsToBeAttributed, accountConversionTimestamp
+}
+
+// ApplyBuyingCommitteeWeights scales the credit of each key by the role weight of the key
+// on the account, keeping the total credit of the account same. Works on the output of
+// any attribution methodology.
+func ApplyBuyingCommitteeWeights(accountsAttribution map[string][]AttributionKeyWeight,
+	accountKeyRoleWeights map[string]map[string]float64) {
+
+	if len(accountKeyRoleWeights) == 0 {
+		return
+	}
+
+	for accountID, keyWeights := range accountsAttribution {
+		roleWeights, exists := accountKeyRoleWeights[accountID]
+		if !exists || len(keyWeights) < 2 {
+			continue
+		}
+
+		var total, weightedTotal float64
+		for _, keyWeight := range keyWeights {
+			roleWeight, exists := roleWeights[keyWeight.Key]
+			if !exists {
+				roleWeight = DefaultBuyingCommitteeRoleWeight
+			}
+			total += keyWeight.Weight
+			weightedTotal += keyWeight.Weight * roleWeight
+		}
+		if weightedTotal <= 0 {
+			continue
+		}
+
+		for i := range keyWeights {
+			roleWeight, exists := roleWeights[keyWeights[i].Key]
+			if !exists {
+				roleWeight = DefaultBuyingCommitteeRoleWeight
+			}
+			keyWeights[i].Weight = keyWeights[i].Weight * roleWeight * total / weightedTotal
+		}
+	}
+}
+
+// RollUpKPIUsersToAccounts adds all the users of the accounts of the users of each KPI to
+// the KPI, for the touch points of the whole account to be attributed to the KPI.
+func RollUpKPIUsersToAccounts(kpiData map[string]KPIInfo, accountUsers map[string]AttributionAccountUser) {
+	coalUserIDToAccount, _ := GetCoalUserIDToAccountMaps(accountUsers)
+	accountUserIDs := make(map[string][]string)
+	accountCoalUserIDs := make(map[string][]string)
+	for userID, accountUser := range accountUsers {
+		accountUserIDs[accountUser.AccountID] = append(accountUserIDs[accountUser.AccountID], userID)
+		accountCoalUserIDs[accountUser.AccountID] = append(accountCoalUserIDs[accountUser.AccountID], accountUser.CoalUserID)
+	}
+
+	for kpiID, kpiInfo := range kpiData {
+		accounts := make(map[string]bool)
+		for _, userID := range kpiInfo.KpiUserIds {
+			if accountUser, exists := accountUsers[userID]; exists {
+				accounts[accountUser.AccountID] = true
+			}
+		}
+		for _, coalUserID := range kpiInfo.KpiCoalUserIds {
+			if accountID, exists := coalUserIDToAccount[coalUserID]; exists {
+				accounts[accountID] = true
+			}
+		}
+		if len(accounts) == 0 {
+			continue
+		}
+
+		userIDs := append([]string{}, kpiInfo.KpiUserIds...)
+		coalUserIDs := append([]string{}, kpiInfo.KpiCoalUserIds...)
+		for accountID := range accounts {
+			userIDs = append(userIDs, accountUserIDs[accountID]...)
+			coalUserIDs = append(coalUserIDs, accountCoalUserIDs[accountID]...)
+		}
+		kpiInfo.KpiUserIds = U.RemoveDuplicateStringInArray(userIDs)
+		kpiInfo.KpiCoalUserIds = U.RemoveDuplicateStringInArray(coalUserIDs)
+		kpiData[kpiID] = kpiInfo
+	}
+}
+
+// GetKPIKeyRoleWeights returns the role weight of each key per KPI, which is the highest
+// weight among the roles of the users which touched the key.
+func GetKPIKeyRoleWeights(kpiData map[string]KPIInfo, sessions map[string]map[string]UserSessionData,
+	accountUsers map[string]AttributionAccountUser, roleWeights map[string]float64) map[string]map[string]float64 {
+
+	roles := make(map[string]string)
+	for userID, accountUser := range accountUsers {
+		roles[userID] = accountUser.Role
+		if roles[accountUser.CoalUserID] == "" {
+			roles[accountUser.CoalUserID] = accountUser.Role
+		}
+	}
+
+	kpiKeyRoleWeights := make(map[string]map[string]float64)
+	for kpiID, kpiInfo := range kpiData {
+		keyRoleWeights := make(map[string]float64)
+		for _, userID := range append(append([]string{}, kpiInfo.KpiCoalUserIds...), kpiInfo.KpiUserIds...) {
+			roleWeight := GetBuyingCommitteeRoleWeight(roleWeights, roles[userID])
+			for attributionKey := range sessions[userID] {
+				if existingWeight, exists := keyRoleWeights[attributionKey]; !exists || roleWeight > existingWeight {
+					keyRoleWeights[attributionKey] = roleWeight
+				}
+			}
+		}
+		kpiKeyRoleWeights[kpiID] = keyRoleWeights
+	}
+	return kpiKeyRoleWeights
+}
+
+func ApplyBuyingCommitteeWeightsForLinkedEvents(linkedEventAccountsAttribution map[string]map[string][]AttributionKeyWeight,
+	accountKeyRoleWeights map[string]map[string]float64) {
+
+	for _, accountsAttribution := range linkedEventAccountsAttribution {
+		ApplyBuyingCommitteeWeights(accountsAttribution, accountKeyRoleWeights)
+	}
+}
@@ -0,0 +1,375 @@
+package model
+
+import (
+	U "factors/util"
+	"reflect"
+	"sort"
+	"time"
+)
+
+const (
+	// partials not updated within the expiry are not used and deleted.
+	AttributionDayPartialExpiryInSecs = 35 * SecsInADay
+	// partials larger than the limit are not cached, the day is computed on every run.
+	AttributionDayPartialMaxSizeInBytes = 16 * 1024 * 1024
+	// days which ended recently are computed on every run, as sessions for them could still be getting created.
+	AttributionDayPartialCacheDelayInSecs = 6 * 60 * 60
+)
+
+// AttributionDayPartial holds the users converted on a day, with their
+// touch points within the lookback of the conversion. Conversions of the V1
+// queries are held as the kpi data of the day.
+type AttributionDayPartial struct {
+	From                          int64                                 `json:"from"`
+	To                            int64                                 `json:"to"`
+	CoalUserIdConversionTimestamp map[string]int64                      `json:"conv_ts"`
+	UsersToBeAttributed           []UserEventInfo                       `json:"users"`
+	Sessions                      map[string]map[string]UserSessionData `json:"sessions"`
+	KPIData                       map[string]KPIInfo                    `json:"kpi_data,omitempty"`
+	KPIHeaders                    []string                              `json:"kpi_headers,omitempty"`
+	KPIAggFunctionTypes           []string                              `json:"kpi_agg_fun_types,omitempty"`
+	// versions of the inputs the partial was computed with. Partial is
+	// recomputed when the marketing reports or the coalesced users change.
+	MarketingVersion string `json:"marketing_version"`
+	UsersVersion     string `json:"users_version"`
+	// ids of the users converted on the day, to leave them out of the
+	// non converted paths.
+	UserIDs []string `json:"user_ids"`
+}
+
+// AttributionDayPartialResult is the partial of a day of the query, kept on the attribution_day_partials table.
+type AttributionDayPartialResult struct {
+	ProjectID int64     `gorm:"primary_key:true" json:"project_id"`
+	QueryHash string    `gorm:"primary_key:true" json:"query_hash"`
+	DayFrom   int64     `gorm:"primary_key:true" json:"day_from"`
+	Partial   []byte    `gorm:"not null" json:"partial"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (AttributionDayPartialResult) TableName() string {
+	return "attribution_day_partials"
+}
+
+// IsStale tells if the partial was computed with different inputs. Partials with conversions
+// cached before the user ids were kept are stale too.
+func (partial *AttributionDayPartial) IsStale(marketingVersion, usersVersion string) bool {
+	if partial.UserIDs == nil && len(partial.CoalUserIdConversionTimestamp) > 0 {
+		return true
+	}
+	return partial.MarketingVersion != marketingVersion || partial.UsersVersion != usersVersion
+}
+
+// GetCoalUserIDs returns the coalesced ids of the users converted on the day.
+func (partial *AttributionDayPartial) GetCoalUserIDs() []string {
+	coalUserIDs := make([]string, 0)
+	seenCoalUserIDs := make(map[string]bool)
+	for coalUserID := range partial.CoalUserIdConversionTimestamp {
+		seenCoalUserIDs[coalUserID] = true
+		coalUserIDs = append(coalUserIDs, coalUserID)
+	}
+	for _, kpiInfo := range partial.KPIData {
+		for _, coalUserID := range kpiInfo.KpiCoalUserIds {
+			if !seenCoalUserIDs[coalUserID] {
+				seenCoalUserIDs[coalUserID] = true
+				coalUserIDs = append(coalUserIDs, coalUserID)
+			}
+		}
+	}
+	sort.Strings(coalUserIDs)
+	return coalUserIDs
+}
+
+// AttributionDayRange is a part of the query range within a single day.
+type AttributionDayRange struct {
+	From int64
+	To   int64
+	// range covers the whole day, which has ended long enough to be cached.
+	Cacheable bool
+}
+
+// IsAttributionDayPartialsSupported tells if the query result can be built by merging per day partials.
+// Attribution of each converted user depends only on their first conversion and the touch points
+// before it, which holds only for conversion based user queries on a single event.
+func IsAttributionDayPartialsSupported(query *AttributionQuery) bool {
+	return query.AnalyzeType == AnalyzeTypeUsers &&
+		query.QueryType == AttributionQueryTypeConversionBased &&
+		len(query.LinkedEvents) == 0 &&
+		query.ConversionEventCompare.Name == "" &&
+		!IsAccountLevelAttribution(query) &&
+		query.To-query.From > SecsInADay
+}
+
+// GetAttributionDayRanges splits the query range into days on the query timezone.
+func GetAttributionDayRanges(from, to int64, timezone string, now int64) []AttributionDayRange {
+	timezoneString := U.TimeZoneString(timezone)
+	if timezone == "" {
+		timezoneString = U.TimeZoneStringIST
+	}
+
+	dayRanges := make([]AttributionDayRange, 0)
+	for dayFrom := from; dayFrom <= to; {
+		dayStart := U.GetBeginningOfDayTimestampIn(dayFrom, timezoneString)
+		dayEnd := U.GetEndOfDayTimestampIn(dayFrom, timezoneString)
+		dayTo := dayEnd
+		if dayTo > to {
+			dayTo = to
+		}
+
+		dayRanges = append(dayRanges, AttributionDayRange{
+			From:      dayFrom,
+			To:        dayTo,
+			Cacheable: dayFrom == dayStart && dayTo == dayEnd && dayEnd+AttributionDayPartialCacheDelayInSecs < now,
+		})
+		dayFrom = dayEnd + 1
+	}
+	return dayRanges
+}
+
+// IsAttributionDayPartialsSupportedV1 tells if the V1 query result can be built by merging per day partials.
+// Kpi rows are returned per day, and attributed on the touch points before the row, so conversion based
+// queries can be split by day.
+func IsAttributionDayPartialsSupportedV1(query *AttributionQueryV1) bool {
+	return len(query.KPIQueries) > 0 &&
+		query.QueryType == AttributionQueryTypeConversionBased &&
+		len(query.LinkedEvents) == 0 &&
+		query.ConversionEventCompare.Name == "" &&
+		!IsAccountLevelAttributionV1(query) &&
+		query.To-query.From > SecsInADay
+}
+
+// GetAttributionDayPartialQueryHash hashes the parts of the query, which change the converted users or their touch points.
+func GetAttributionDayPartialQueryHash(query *AttributionQuery) (string, error) {
+	return U.GenerateHashStringForStruct(struct {
+		ConversionEvent               QueryEventWithProperties `json:"ce"`
+		AttributionKey                string                   `json:"attribution_key"`
+		AttributionKeyDimension       []string                 `json:"attribution_key_dimensions"`
+		AttributionContentGroups      []string                 `json:"attribution_content_groups"`
+		AttributionKeyCustomDimension []string                 `json:"attribution_key_custom_dimensions"`
+		LookbackDays                  int                      `json:"lbw"`
+		QueryType                     string                   `json:"query_type"`
+		TacticOfferType               string                   `json:"tactic_offer_type"`
+		Timezone                      string                   `json:"time_zone"`
+	}{query.ConversionEvent, query.AttributionKey, query.AttributionKeyDimension, query.AttributionContentGroups,
+		query.AttributionKeyCustomDimension, query.LookbackDays, query.QueryType, query.TacticOfferType, query.Timezone})
+}
+
+// GetAttributionDayPartialQueryHashV1 hashes the parts of the V1 query, which change the kpi data or the touch points.
+// Range of the kpi queries is set from the attribution query on execution, hence left out.
+func GetAttributionDayPartialQueryHashV1(query *AttributionQueryV1) (string, error) {
+	var kpiQueries []AttributionKPIQueries
+	U.DeepCopy(query.KPIQueries, &kpiQueries)
+	for i := range kpiQueries {
+		for j := range kpiQueries[i].KPI.Queries {
+			kpiQueries[i].KPI.Queries[j].From = 0
+			kpiQueries[i].KPI.Queries[j].To = 0
+		}
+	}
+
+	return U.GenerateHashStringForStruct(struct {
+		KPIQueries                    []AttributionKPIQueries `json:"kpi_queries"`
+		AttributionKey                string                  `json:"attribution_key"`
+		AttributionKeyDimension       []string                `json:"attribution_key_dimensions"`
+		AttributionContentGroups      []string                `json:"attribution_content_groups"`
+		AttributionKeyCustomDimension []string                `json:"attribution_key_custom_dimensions"`
+		LookbackDays                  int                     `json:"lbw"`
+		QueryType                     string                  `json:"query_type"`
+		TacticOfferType               string                  `json:"tactic_offer_type"`
+		Timezone                      string                  `json:"time_zone"`
+	}{kpiQueries, query.AttributionKey, query.AttributionKeyDimension, query.AttributionContentGroups,
+		query.AttributionKeyCustomDimension, query.LookbackDays, query.QueryType, query.TacticOfferType, query.Timezone})
+}
+
+// GetAttributionMarketingReportsVersion hashes the dimensions of the marketing reports, referred by the touch points
+// of the sessions. Performance metrics are left out, as they change on every sync and don't change the touch points.
+func GetAttributionMarketingReportsVersion(marketingReports *MarketingReports,
+	sessions map[string]map[string]UserSessionData) (string, error) {
+
+	if marketingReports == nil {
+		return "", nil
+	}
+
+	referredIDs := make(map[string]bool)
+	for _, userSessions := range sessions {
+		for _, sessionData := range userSessions {
+			for _, id := range []string{sessionData.MarketingInfo.CampaignID, sessionData.MarketingInfo.AdgroupID,
+				sessionData.MarketingInfo.KeywordID} {
+				if U.IsNonEmptyKey(id) {
+					referredIDs[id] = true
+				}
+			}
+		}
+	}
+
+	dimensions := make(map[string]map[string]MarketingData)
+	reportsValue := reflect.ValueOf(*marketingReports)
+	for i := 0; i < reportsValue.NumField(); i++ {
+		report, ok := reportsValue.Field(i).Interface().(map[string]MarketingData)
+		if !ok {
+			continue
+		}
+		reportDimensions := make(map[string]MarketingData)
+		for key, data := range report {
+			if !referredIDs[data.ID] {
+				continue
+			}
+			data.Impressions, data.Clicks, data.Spend = 0, 0, 0
+			reportDimensions[key] = data
+		}
+		dimensions[reportsValue.Type().Field(i).Name] = reportDimensions
+	}
+	return U.GenerateHashStringForStruct(dimensions)
+}
+
+// GetAttributionUsersVersion hashes the coalesced id of each user of the converted users.
+func GetAttributionUsersVersion(userIDToCoalUserID map[string]string) (string, error) {
+	return U.GenerateHashStringForStruct(userIDToCoalUserID)
+}
+
+// MergeAttributionDayPartials merges the partials of all the days of the query range. A user converted on
+// multiple days is attributed on the first conversion, with the touch points of that day's partial.
+// Ids of the users converted on any of the days are returned too.
+func MergeAttributionDayPartials(partials []*AttributionDayPartial) (map[string]int64, []UserEventInfo,
+	[]string, map[string]map[string]UserSessionData) {
+
+	coalUserIdConversionTimestamp := make(map[string]int64)
+	partialOfUser := make(map[string]*AttributionDayPartial)
+	for _, partial := range partials {
+		for coalUserID, timestamp := range partial.CoalUserIdConversionTimestamp {
+			if existing, exists := coalUserIdConversionTimestamp[coalUserID]; !exists || timestamp < existing {
+				coalUserIdConversionTimestamp[coalUserID] = timestamp
+				partialOfUser[coalUserID] = partial
+			}
+		}
+	}
+
+	usersToBeAttributed := make([]UserEventInfo, 0, len(coalUserIdConversionTimestamp))
+	sessions := make(map[string]map[string]UserSessionData)
+	for _, partial := range partials {
+		for _, userEvent := range partial.UsersToBeAttributed {
+			if partialOfUser[userEvent.CoalUserID] == partial {
+				usersToBeAttributed = append(usersToBeAttributed, userEvent)
+			}
+		}
+	}
+	for coalUserID, partial := range partialOfUser {
+		if userSessions, exists := partial.Sessions[coalUserID]; exists {
+			sessions[coalUserID] = userSessions
+		}
+	}
+
+	userIDs := make([]string, 0)
+	seenUserIDs := make(map[string]bool)
+	for _, partial := range partials {
+		for _, userID := range partial.UserIDs {
+			if !seenUserIDs[userID] {
+				seenUserIDs[userID] = true
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+	return coalUserIdConversionTimestamp, usersToBeAttributed, userIDs, sessions
+}
+
+// MergeAttributionDayPartialsV1 merges the kpi data and the touch points of all the days of the query range.
+// Rows of a kpi on different days are merged on the kpi, touch points of a user from all the days are merged.
+func MergeAttributionDayPartialsV1(partials []*AttributionDayPartial) (map[string]KPIInfo, []string, []string,
+	[]string, map[string]map[string]UserSessionData) {
+
+	kpiData := make(map[string]KPIInfo)
+	var kpiHeaders, kpiAggFunctionTypes []string
+	for _, partial := range partials {
+		if len(kpiHeaders) == 0 && len(partial.KPIHeaders) > 0 {
+			kpiHeaders = partial.KPIHeaders
+			kpiAggFunctionTypes = partial.KPIAggFunctionTypes
+		}
+
+		for kpiID, kpiInfo := range partial.KPIData {
+			existing, exists := kpiData[kpiID]
+			if !exists {
+				kpiData[kpiID] = kpiInfo
+				continue
+			}
+			existing.KpiValuesList = append(existing.KpiValuesList, kpiInfo.KpiValuesList...)
+			existing.KpiUserIds = U.RemoveDuplicateStringInArray(append(existing.KpiUserIds, kpiInfo.KpiUserIds...))
+			existing.KpiCoalUserIds = U.RemoveDuplicateStringInArray(append(existing.KpiCoalUserIds, kpiInfo.KpiCoalUserIds...))
+			kpiData[kpiID] = existing
+		}
+	}
+
+	usersIDsToAttribute := make([]string, 0)
+	seenUserIDs := make(map[string]bool)
+	for _, kpiInfo := range kpiData {
+		for _, userID := range kpiInfo.KpiUserIds {
+			if !seenUserIDs[userID] {
+				seenUserIDs[userID] = true
+				usersIDsToAttribute = append(usersIDsToAttribute, userID)
+			}
+		}
+	}
+
+	sessions := make(map[string]map[string]UserSessionData)
+	for _, partial := range partials {
+		for coalUserID, userSessions := range partial.Sessions {
+			if _, exists := sessions[coalUserID]; !exists {
+				sessions[coalUserID] = make(map[string]UserSessionData)
+			}
+			for key, sessionData := range userSessions {
+				existing, exists := sessions[coalUserID][key]
+				if !exists {
+					sessions[coalUserID][key] = sessionData
+					continue
+				}
+				sessions[coalUserID][key] = mergeUserSessionData(existing, sessionData)
+			}
+		}
+	}
+	return kpiData, kpiHeaders, kpiAggFunctionTypes, usersIDsToAttribute, sessions
+}
+
+// SetAttributionDayPartialsWithinQueryPeriod evaluates the touch points of the partials against the range
+// of the whole query. Partials are computed and cached with the range of their day, which is not the
+// period the merged touch points are attributed on.
+func SetAttributionDayPartialsWithinQueryPeriod(partials []*AttributionDayPartial, queryType string,
+	lookbackDays int, from, to int64) {
+
+	for _, partial := range partials {
+		for _, userSessions := range partial.Sessions {
+			for key, sessionData := range userSessions {
+				sessionData.WithinQueryPeriod = false
+				for _, timestamp := range sessionData.TimeStamps {
+					if isSessionWithinQueryPeriod(queryType, lookbackDays, from, to, timestamp) {
+						sessionData.WithinQueryPeriod = true
+						break
+					}
+				}
+				userSessions[key] = sessionData
+			}
+		}
+	}
+}
+
+// mergeUserSessionData merges the touch points of a key, pulled for overlapping lookback windows of two days.
+func mergeUserSessionData(sessionData, other UserSessionData) UserSessionData {
+	seenTimestamps := make(map[int64]bool)
+	for _, timestamp := range sessionData.TimeStamps {
+		seenTimestamps[timestamp] = true
+	}
+	for _, timestamp := range other.TimeStamps {
+		if !seenTimestamps[timestamp] {
+			seenTimestamps[timestamp] = true
+			sessionData.TimeStamps = append(sessionData.TimeStamps, timestamp)
+		}
+	}
+	sort.Slice(sessionData.TimeStamps, func(i, j int) bool { return sessionData.TimeStamps[i] < sessionData.TimeStamps[j] })
+
+	if other.MinTimestamp < sessionData.MinTimestamp {
+		sessionData.MinTimestamp = other.MinTimestamp
+	}
+	if other.MaxTimestamp > sessionData.MaxTimestamp {
+		sessionData.MaxTimestamp = other.MaxTimestamp
+		sessionData.MarketingInfo = other.MarketingInfo
+	}
+	sessionData.WithinQueryPeriod = sessionData.WithinQueryPeriod || other.WithinQueryPeriod
+	return sessionData
+}
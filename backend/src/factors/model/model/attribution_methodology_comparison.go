@@ -0,0 +1,266 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	MetaMethodologyComparisonKeys  = "MethodologyComparisonKeys"
+	MetaMethodologyComparisonPaths = "MethodologyComparisonPaths"
+
+	DefaultMethodologyComparisonTopPaths = 10
+	MethodologyComparisonPathSeparator   = " > "
+)
+
+// MethodologyComparisonHits holds the attribution of the conversions under both the methodologies of
+// the query, along with the sessions and conversion time used, kept by the attribution run for the
+// methodology comparison details.
+type MethodologyComparisonHits struct {
+	UserConversionHit        map[string][]AttributionKeyWeight
+	UserConversionCompareHit map[string][]AttributionKeyWeight
+	Sessions                 map[string]map[string]UserSessionData
+	ConversionTimestamp      map[string]int64
+}
+
+type methodologyComparisonQuery struct {
+	queryType          string
+	attributionKey     string
+	methodology        string
+	methodologyCompare string
+	lookbackDays       int
+	from               int64
+	to                 int64
+}
+
+// GetKPIConversionTimestamps returns the time of the latest converted value of each KPI.
+func GetKPIConversionTimestamps(kpiData map[string]KPIInfo) map[string]int64 {
+	conversionTimestamps := make(map[string]int64)
+	for kpiID, kpiInfo := range kpiData {
+		for _, value := range kpiInfo.KpiValuesList {
+			if value.IsConverted && value.Timestamp > conversionTimestamps[kpiID] {
+				conversionTimestamps[kpiID] = value.Timestamp
+			}
+		}
+	}
+	return conversionTimestamps
+}
+
+// GetMethodologyComparisonMeta returns the credit of each key under both the methodologies of the query
+// along with the delta, and the top converting paths ordered by the credit moved across keys between the
+// two methodologies, which explain the delta. Uses the attribution kept by the run, nil if not kept.
+func GetMethodologyComparisonMeta(query *AttributionQuery, attributionData *map[string]*AttributionData) []HeaderRows {
+	return getMethodologyComparisonMeta(methodologyComparisonQuery{
+		queryType:          query.QueryType,
+		attributionKey:     query.AttributionKey,
+		methodology:        query.AttributionMethodology,
+		methodologyCompare: query.AttributionMethodologyCompare,
+		lookbackDays:       query.LookbackDays,
+		from:               query.From,
+		to:                 query.To,
+	}, query.MethodologyComparisonHits, attributionData)
+}
+
+// GetMethodologyComparisonMetaV1 is GetMethodologyComparisonMeta for V1 queries, with a path per KPI.
+func GetMethodologyComparisonMetaV1(query *AttributionQueryV1, attributionData *map[string]*AttributionData) []HeaderRows {
+	return getMethodologyComparisonMeta(methodologyComparisonQuery{
+		queryType:          query.QueryType,
+		attributionKey:     query.AttributionKey,
+		methodology:        query.AttributionMethodology,
+		methodologyCompare: query.AttributionMethodologyCompare,
+		lookbackDays:       query.LookbackDays,
+		from:               query.From,
+		to:                 query.To,
+	}, query.MethodologyComparisonHits, attributionData)
+}
+
+func getMethodologyComparisonMeta(query methodologyComparisonQuery, hits *MethodologyComparisonHits,
+	attributionData *map[string]*AttributionData) []HeaderRows {
+
+	if hits == nil {
+		return nil
+	}
+
+	keysMeta := getMethodologyComparisonKeysMeta(query, hits.UserConversionHit, hits.UserConversionCompareHit, attributionData)
+	pathsMeta := getMethodologyComparisonPathsMeta(query, hits.UserConversionHit, hits.UserConversionCompareHit,
+		hits.Sessions, hits.ConversionTimestamp, attributionData, DefaultMethodologyComparisonTopPaths)
+	return []HeaderRows{keysMeta, pathsMeta}
+}
+
+func getKeyCredits(usersAttribution []AttributionKeyWeight) map[string]float64 {
+	credits := make(map[string]float64)
+	for _, keyWeight := range usersAttribution {
+		credits[keyWeight.Key] += keyWeight.Weight
+	}
+	return credits
+}
+
+func getAttributionKeyDisplayName(attributionData *map[string]*AttributionData, key string) string {
+	if attributionData != nil {
+		if data, exists := (*attributionData)[key]; exists && data != nil && data.Name != "" {
+			return data.Name
+		}
+	}
+	return key
+}
+
+func getMethodologyComparisonKeysMeta(query methodologyComparisonQuery, userConversionHit,
+	userConversionCompareHit map[string][]AttributionKeyWeight, attributionData *map[string]*AttributionData) HeaderRows {
+
+	credit := make(map[string]float64)
+	creditCompare := make(map[string]float64)
+	for _, keyWeights := range userConversionHit {
+		for key, weight := range getKeyCredits(keyWeights) {
+			credit[key] += weight
+		}
+	}
+	for _, keyWeights := range userConversionCompareHit {
+		for key, weight := range getKeyCredits(keyWeights) {
+			creditCompare[key] += weight
+		}
+	}
+
+	keys := make([]string, 0)
+	for key := range credit {
+		keys = append(keys, key)
+	}
+	for key := range creditCompare {
+		if _, exists := credit[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	// keys with the largest change first.
+	sort.SliceStable(keys, func(i, j int) bool {
+		deltaI := math.Abs(creditCompare[keys[i]] - credit[keys[i]])
+		deltaJ := math.Abs(creditCompare[keys[j]] - credit[keys[j]])
+		if deltaI != deltaJ {
+			return deltaI > deltaJ
+		}
+		return keys[i] < keys[j]
+	})
+
+	rows := make([][]interface{}, 0, len(keys))
+	for _, key := range keys {
+		delta := creditCompare[key] - credit[key]
+		var deltaPercentage float64
+		if credit[key] > 0 {
+			deltaPercentage = delta * 100 / credit[key]
+		}
+		rows = append(rows, []interface{}{getAttributionKeyDisplayName(attributionData, key), credit[key],
+			creditCompare[key], delta, deltaPercentage})
+	}
+
+	return HeaderRows{
+		Title: MetaMethodologyComparisonKeys,
+		Headers: []string{query.attributionKey, query.methodology, query.methodologyCompare,
+			"Delta", "Delta(%)"},
+		Rows: rows,
+	}
+}
+
+type methodologyComparisonPath struct {
+	path        string
+	conversions int64
+	creditMoved float64
+	keyDeltas   map[string]float64
+}
+
+func getMethodologyComparisonPathsMeta(query methodologyComparisonQuery, userConversionHit,
+	userConversionCompareHit map[string][]AttributionKeyWeight, sessions map[string]map[string]UserSessionData,
+	coalUserIdConversionTimestamp map[string]int64, attributionData *map[string]*AttributionData, limit int) HeaderRows {
+
+	lookbackPeriod := int64(query.lookbackDays) * SecsInADay
+	paths := make(map[string]*methodologyComparisonPath)
+	for userID := range userConversionHit {
+		if _, exists := userConversionCompareHit[userID]; !exists {
+			continue
+		}
+
+		touchPoints := getTouchPointPath(query.queryType, sessions[userID], coalUserIdConversionTimestamp[userID],
+			lookbackPeriod, query.from, query.to)
+		if len(touchPoints) == 0 {
+			continue
+		}
+		displayPath := make([]string, 0, len(touchPoints))
+		for i, key := range touchPoints {
+			// repeated touches on the same key are shown once.
+			if i > 0 && touchPoints[i-1] == key {
+				continue
+			}
+			displayPath = append(displayPath, getAttributionKeyDisplayName(attributionData, key))
+		}
+		pathString := strings.Join(displayPath, MethodologyComparisonPathSeparator)
+
+		if _, exists := paths[pathString]; !exists {
+			paths[pathString] = &methodologyComparisonPath{path: pathString, keyDeltas: make(map[string]float64)}
+		}
+		path := paths[pathString]
+		path.conversions++
+
+		credit := getKeyCredits(userConversionHit[userID])
+		creditCompare := getKeyCredits(userConversionCompareHit[userID])
+		for key := range creditCompare {
+			if _, exists := credit[key]; !exists {
+				credit[key] = 0
+			}
+		}
+		var moved float64
+		for key := range credit {
+			delta := creditCompare[key] - credit[key]
+			path.keyDeltas[getAttributionKeyDisplayName(attributionData, key)] += delta
+			moved += math.Abs(delta)
+		}
+		// credit moved is counted once, as the credit lost by a key is gained by another.
+		path.creditMoved += moved / 2
+	}
+
+	sortedPaths := make([]*methodologyComparisonPath, 0, len(paths))
+	for _, path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.SliceStable(sortedPaths, func(i, j int) bool {
+		if sortedPaths[i].creditMoved != sortedPaths[j].creditMoved {
+			return sortedPaths[i].creditMoved > sortedPaths[j].creditMoved
+		}
+		if sortedPaths[i].conversions != sortedPaths[j].conversions {
+			return sortedPaths[i].conversions > sortedPaths[j].conversions
+		}
+		return sortedPaths[i].path < sortedPaths[j].path
+	})
+	if limit > 0 && len(sortedPaths) > limit {
+		sortedPaths = sortedPaths[:limit]
+	}
+
+	rows := make([][]interface{}, 0, len(sortedPaths))
+	for _, path := range sortedPaths {
+		gainer, loser := getTopGainerAndLoser(path.keyDeltas)
+		rows = append(rows, []interface{}{path.path, path.conversions, path.creditMoved, gainer, loser})
+	}
+
+	return HeaderRows{
+		Title:   MetaMethodologyComparisonPaths,
+		Headers: []string{"Path", "Conversions", "Credit Moved", "Top Gainer", "Top Loser"},
+		Rows:    rows,
+	}
+}
+
+// getTopGainerAndLoser returns the keys which gained and lost the most credit on the compare methodology.
+func getTopGainerAndLoser(keyDeltas map[string]float64) (string, string) {
+	gainer, loser := PropertyValueNone, PropertyValueNone
+	var maxGain, maxLoss float64
+	keys := make([]string, 0, len(keyDeltas))
+	for key := range keyDeltas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if keyDeltas[key] > maxGain {
+			gainer, maxGain = key, keyDeltas[key]
+		}
+		if keyDeltas[key] < maxLoss {
+			loser, maxLoss = key, keyDeltas[key]
+		}
+	}
+	return gainer, loser
+}
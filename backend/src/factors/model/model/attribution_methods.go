@@ -2,6 +2,7 @@ package model
 
 import (
 	C "factors/config"
+	DD "factors/dd_attribution"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"math"
@@ -17,6 +18,7 @@ type AttributionKeyWeight struct {
 func ApplyAttributionKPI(attributionType string,
 	method string,
 	sessions map[string]map[string]UserSessionData,
+	nonConvertedPaths [][]string,
 	kpiData map[string]KPIInfo,
 	lookbackDays int, campaignFrom, campaignTo int64,
 	attributionKey string) (map[string][]AttributionKeyWeight, error) {
@@ -24,6 +26,18 @@ func ApplyAttributionKPI(attributionType string,
 	usersAttribution := make(map[string][]AttributionKeyWeight)
 	lookbackPeriod := int64(lookbackDays) * SecsInADay
 
+	var removalEffects map[string]float64
+	if method == AttributionMethodDataDriven {
+		var paths [][]string
+		for kpiID, kpiInfo := range kpiData {
+			for _, value := range kpiInfo.KpiValuesList {
+				paths = append(paths, getTouchPointPath(attributionType, sessions[kpiID], value.Timestamp,
+					lookbackPeriod, campaignFrom, campaignTo))
+			}
+		}
+		removalEffects = DD.ComputeRemovalEffects(paths, nonConvertedPaths)
+	}
+
 	for kpiID, kpiInfo := range kpiData {
 		// kpiID := kpiInfo.KpiID
 		var attributionKeys []AttributionKeyWeight
@@ -77,6 +91,10 @@ func ApplyAttributionKPI(attributionType string,
 				attributionKeys = getWShaped(attributionType, userSessions, conversionTime,
 					lookbackPeriod, campaignFrom, campaignTo)
 				break
+			case AttributionMethodDataDriven:
+				attributionKeys = getDataDriven(attributionType, userSessions, conversionTime,
+					lookbackPeriod, campaignFrom, campaignTo, removalEffects)
+				break
 
 			default:
 				break
@@ -107,7 +125,7 @@ func ApplyAttributionKPI(attributionType string,
 	return usersAttribution, nil
 }
 func ApplyAttribution(attributionType string, method string, conversionEvent string, usersToBeAttributed []UserEventInfo,
-	sessions map[string]map[string]UserSessionData, coalUserIdConversionTimestamp map[string]int64,
+	sessions map[string]map[string]UserSessionData, nonConvertedPaths [][]string, coalUserIdConversionTimestamp map[string]int64,
 	lookbackDays int, campaignFrom, campaignTo int64, attributionKey string, logCtx log.Entry) (map[string][]AttributionKeyWeight,
 	map[string]map[string][]AttributionKeyWeight, error) {
 
@@ -115,6 +133,16 @@ func ApplyAttribution(attributionType string, method string, conversionEvent str
 	linkedEventUserCampaign := make(map[string]map[string][]AttributionKeyWeight)
 	lookbackPeriod := int64(lookbackDays) * SecsInADay
 
+	var removalEffects map[string]float64
+	if method == AttributionMethodDataDriven {
+		var paths [][]string
+		for _, val := range usersToBeAttributed {
+			paths = append(paths, getTouchPointPath(attributionType, sessions[val.CoalUserID],
+				coalUserIdConversionTimestamp[val.CoalUserID], lookbackPeriod, campaignFrom, campaignTo))
+		}
+		removalEffects = DD.ComputeRemovalEffects(paths, nonConvertedPaths)
+	}
+
 	for _, val := range usersToBeAttributed {
 		userId := val.CoalUserID
 		eventName := val.EventName
@@ -169,6 +197,10 @@ func ApplyAttribution(attributionType string, method string, conversionEvent str
 			attributionKeys = getWShaped(attributionType, userSessions, conversionTime,
 				lookbackPeriod, campaignFrom, campaignTo)
 			break
+		case AttributionMethodDataDriven:
+			attributionKeys = getDataDriven(attributionType, userSessions, conversionTime,
+				lookbackPeriod, campaignFrom, campaignTo, removalEffects)
+			break
 
 		default:
 			break
@@ -430,6 +462,82 @@ func getTimeDecay(attributionType string, attributionTimerange map[string]UserSe
 	return keys
 }
 
+// getTouchPointPath returns the time ordered attribution keys of the interactions considered for the conversion.
+func getTouchPointPath(attributionType string, attributionTimerange map[string]UserSessionData,
+	conversionTime, lookbackPeriod, from, to int64) []string {
+
+	var path []string
+	interactions := getMergedInteractions(attributionTimerange)
+	interactions = SortInteractionTime(interactions, SortASC)
+
+	for _, interaction := range interactions {
+		if !isAdTouchWithinLookback(interaction.InteractionTime, conversionTime, lookbackPeriod) {
+			continue
+		}
+		if attributionType == AttributionQueryTypeEngagementBased &&
+			!isAdTouchWithinCampaignOrQueryPeriod(interaction.InteractionTime, from, to) {
+			continue
+		}
+		path = append(path, interaction.AttributionKey)
+	}
+	return path
+}
+
+// IsDataDrivenAttribution checks if any of the methodologies of the query is data driven.
+func IsDataDrivenAttribution(method, methodCompare string) bool {
+	return method == AttributionMethodDataDriven || methodCompare == AttributionMethodDataDriven
+}
+
+// GetNonConvertedTouchPointPaths returns the touch point paths of the users not on convertedSessions,
+// with the end of the query period as the end of the journey.
+func GetNonConvertedTouchPointPaths(attributionType string, sessions map[string]map[string]UserSessionData,
+	convertedSessions map[string]map[string]UserSessionData, lookbackDays int, from, to int64) [][]string {
+
+	lookbackPeriod := int64(lookbackDays) * SecsInADay
+	paths := make([][]string, 0)
+	for userID, userSessions := range sessions {
+		if _, converted := convertedSessions[userID]; converted {
+			continue
+		}
+		path := getTouchPointPath(attributionType, userSessions, to, lookbackPeriod, from, to)
+		if len(path) == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// returns list of unique attribution keys with weights proportional to the removal effect of the key,
+// computed over the markov chain of the converted and the non converted touch point paths.
+func getDataDriven(attributionType string, attributionTimerange map[string]UserSessionData,
+	conversionTime, lookbackPeriod, from, to int64, removalEffects map[string]float64) []AttributionKeyWeight {
+
+	var keys []AttributionKeyWeight
+	path := getTouchPointPath(attributionType, attributionTimerange, conversionTime, lookbackPeriod, from, to)
+
+	seen := make(map[string]bool)
+	totalWeight := 0.0
+	for _, key := range path {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, AttributionKeyWeight{Key: key, Weight: removalEffects[key]})
+		totalWeight += removalEffects[key]
+	}
+
+	// Falls back to equal credit when none of the keys has a removal effect.
+	for i := range keys {
+		if totalWeight > 0 {
+			keys[i].Weight = keys[i].Weight / totalWeight
+		} else {
+			keys[i].Weight = 1 / float64(len(keys))
+		}
+	}
+	return keys
+}
+
 // returns the first attributionId and corresponding weight
 func getFirstTouchId(attributionType string, attributionTimerange map[string]UserSessionData,
 	conversionTime, lookbackPeriod, from, to int64) []AttributionKeyWeight {
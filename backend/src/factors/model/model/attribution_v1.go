@@ -36,6 +36,21 @@ type AttributionQueryV1 struct {
 	// Tactic or Offer or TacticOffer
 	TacticOfferType string `json:"tactic_offer_type"`
 	Timezone        string `json:"time_zone"`
+	// touch point paths of a sample of the non converted users, pulled for the data driven methodology
+	NonConvertedPaths [][]string `json:"-"`
+	// user (default) or account, account rolls up touch points of all the users of a group
+	AttributionLevel string `json:"attribution_level,omitempty"`
+	// group used as account on account level, defaults to $domains
+	AccountGroupName string `json:"account_group_name,omitempty"`
+	// user property holding the buying committee role and the weight per role, roles not listed weigh 1
+	RoleProperty string             `json:"role_property,omitempty"`
+	RoleWeights  map[string]float64 `json:"role_weights,omitempty"`
+	// computed while running account level attribution, kpi -> key -> role weight
+	KPIKeyRoleWeights map[string]map[string]float64 `json:"-"`
+	// adds credit per key under both the methodologies and the top paths explaining the delta to the result meta
+	MethodologyComparisonDetails bool `json:"methodology_comparison_details,omitempty"`
+	// attribution under both the methodologies, kept by the run for the methodology comparison details
+	MethodologyComparisonHits *MethodologyComparisonHits `json:"-"`
 }
 
 type AttributionKPIQueries struct {
@@ -0,0 +1,272 @@
+package model
+
+import (
+	"errors"
+	U "factors/util"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CRMCustomSource is a CRM without an integration, i.e Pipedrive, Zoho or an in-house CRM,
+// registered by the project admin before pushing the objects through the CRM API.
+type CRMCustomSource struct {
+	ProjectID int64       `gorm:"primary_key:true;auto_increment:false" json:"project_id"`
+	Source    U.CRMSource `gorm:"primary_key:true;auto_increment:false" json:"source"`
+	Name      string      `gorm:"not null" json:"name"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+/*
+Object types supported for the custom sources. Contacts and leads are enriched as users
+and activities as events of the contact or lead.
+*/
+const (
+	CRMCustomSourceObjectTypeContact  = 1
+	CRMCustomSourceObjectTypeLead     = 2
+	CRMCustomSourceObjectTypeActivity = 3
+
+	CRMCustomSourceObjectTypeNameContact  = "contact"
+	CRMCustomSourceObjectTypeNameLead     = "lead"
+	CRMCustomSourceObjectTypeNameActivity = "activity"
+)
+
+const (
+	MaxCRMCustomSourcesPerProject = 10
+	MaxCRMCustomRecordsPerRequest = 1000
+)
+
+var CRMCustomSourceObjectTypeAlias = map[int]string{
+	CRMCustomSourceObjectTypeContact:  CRMCustomSourceObjectTypeNameContact,
+	CRMCustomSourceObjectTypeLead:     CRMCustomSourceObjectTypeNameLead,
+	CRMCustomSourceObjectTypeActivity: CRMCustomSourceObjectTypeNameActivity,
+}
+
+var CRMCustomSourceUserTypes = map[int]bool{
+	CRMCustomSourceObjectTypeContact: true,
+	CRMCustomSourceObjectTypeLead:    true,
+}
+
+var CRMCustomSourceActivityTypes = map[int]bool{
+	CRMCustomSourceObjectTypeActivity: true,
+}
+
+// Only lowercase alphanumeric, to keep the property keys of a source unambiguous
+// from the other sources, i.e $custom_crm_<name>_<object_type>_<property>.
+var crmCustomSourceNameRegex = regexp.MustCompile(`^[a-z][a-z0-9]{1,31}$`)
+
+// Names used on the event names and properties of the other sources.
+var reservedCRMCustomSourceNames = map[string]bool{
+	"sf":      true,
+	"crm":     true,
+	"custom":  true,
+	"session": true,
+}
+
+func IsCRMCustomSource(source U.CRMSource) bool {
+	return source >= U.CRM_SOURCE_CUSTOM_MIN
+}
+
+// ValidateCRMCustomSourceName validates the name given on the CRM API for the source.
+func ValidateCRMCustomSourceName(name string) error {
+	if !crmCustomSourceNameRegex.MatchString(name) {
+		return errors.New("source should be 2 to 32 lowercase alphanumeric characters starting with a letter")
+	}
+
+	if IsCRMSource(name) || IsValidUserSource(name) || reservedCRMCustomSourceNames[name] {
+		return fmt.Errorf("source %s is reserved", name)
+	}
+
+	return nil
+}
+
+// GetCRMCustomSourceAlias returns the alias used on the event names, properties and
+// smart event filters of the custom source.
+func GetCRMCustomSourceAlias(name string) string {
+	return U.CRM_SOURCE_NAME_CUSTOM + "_" + name
+}
+
+func (source *CRMCustomSource) GetSourceAlias() string {
+	return GetCRMCustomSourceAlias(source.Name)
+}
+
+func IsCRMCustomSourceAlias(sourceAlias string) bool {
+	return strings.HasPrefix(sourceAlias, U.CRM_SOURCE_NAME_CUSTOM+"_")
+}
+
+func GetCRMCustomSourceNameByAlias(sourceAlias string) string {
+	return strings.TrimPrefix(sourceAlias, U.CRM_SOURCE_NAME_CUSTOM+"_")
+}
+
+func GetCRMCustomSourceObjectType(typeAlias string) int {
+	for objectType, alias := range CRMCustomSourceObjectTypeAlias {
+		if alias == typeAlias {
+			return objectType
+		}
+	}
+
+	return 0
+}
+
+// GetCRMCustomSourceUserEventName returns the name of the event created for the contact or lead.
+func GetCRMCustomSourceUserEventName(sourceAlias, typeAlias string, action CRMAction) string {
+	if action == CRMActionCreated {
+		return fmt.Sprintf("%s_%s_%s", U.NAME_PREFIX+sourceAlias, typeAlias, "created")
+	}
+
+	return fmt.Sprintf("%s_%s_%s", U.NAME_PREFIX+sourceAlias, typeAlias, "updated")
+}
+
+// GetCRMCustomTimestampInSecs allows the timestamp in milliseconds as
+// most of the CRMs use milliseconds on the api.
+func GetCRMCustomTimestampInSecs(timestamp int64) int64 {
+	if timestamp >= 10000000000 {
+		return timestamp / 1000
+	}
+
+	return timestamp
+}
+
+// CRMCustomUserPayload is the contact or lead pushed through the CRM API.
+// Every push of the same id creates a new state of the user.
+type CRMCustomUserPayload struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Timestamp  int64           `json:"timestamp"`
+	Email      string          `json:"email"`
+	Phone      string          `json:"phone"`
+	Properties U.PropertiesMap `json:"properties"`
+}
+
+func (payload *CRMCustomUserPayload) GetCRMUser(projectID int64, source U.CRMSource) (*CRMUser, error) {
+	if payload.ID == "" {
+		return nil, errors.New("missing id")
+	}
+
+	objectType := GetCRMCustomSourceObjectType(payload.Type)
+	if !CRMCustomSourceUserTypes[objectType] {
+		return nil, fmt.Errorf("invalid type %s, should be %s or %s", payload.Type,
+			CRMCustomSourceObjectTypeNameContact, CRMCustomSourceObjectTypeNameLead)
+	}
+
+	if payload.Timestamp <= 0 {
+		return nil, errors.New("missing timestamp")
+	}
+
+	if len(payload.Properties) == 0 {
+		return nil, errors.New("missing properties")
+	}
+
+	properties, err := U.EncodeStructTypeToPostgresJsonb(payload.Properties)
+	if err != nil {
+		return nil, errors.New("invalid properties")
+	}
+
+	return &CRMUser{
+		ID:         payload.ID,
+		ProjectID:  projectID,
+		Source:     source,
+		Type:       objectType,
+		Timestamp:  GetCRMCustomTimestampInSecs(payload.Timestamp),
+		Email:      payload.Email,
+		Phone:      payload.Phone,
+		Properties: properties,
+	}, nil
+}
+
+// CRMCustomActivityPayload is the activity of a contact or lead pushed through the CRM API.
+// Name of the activity is used on the event name.
+type CRMCustomActivityPayload struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    string          `json:"actor_id"`
+	Timestamp  int64           `json:"timestamp"`
+	Properties U.PropertiesMap `json:"properties"`
+}
+
+func (payload *CRMCustomActivityPayload) GetCRMActivity(projectID int64, source U.CRMSource) (*CRMActivity, error) {
+	if payload.ID == "" {
+		return nil, errors.New("missing id")
+	}
+
+	if strings.TrimSpace(payload.Name) == "" {
+		return nil, errors.New("missing name")
+	}
+
+	actorType := GetCRMCustomSourceObjectType(payload.ActorType)
+	if !CRMCustomSourceUserTypes[actorType] {
+		return nil, fmt.Errorf("invalid actor_type %s, should be %s or %s", payload.ActorType,
+			CRMCustomSourceObjectTypeNameContact, CRMCustomSourceObjectTypeNameLead)
+	}
+
+	if payload.ActorID == "" {
+		return nil, errors.New("missing actor_id")
+	}
+
+	if payload.Timestamp <= 0 {
+		return nil, errors.New("missing timestamp")
+	}
+
+	if len(payload.Properties) == 0 {
+		return nil, errors.New("missing properties")
+	}
+
+	properties, err := U.EncodeStructTypeToPostgresJsonb(payload.Properties)
+	if err != nil {
+		return nil, errors.New("invalid properties")
+	}
+
+	return &CRMActivity{
+		ProjectID:          projectID,
+		ExternalActivityID: payload.ID,
+		Source:             source,
+		Name:               strings.TrimSpace(payload.Name),
+		Type:               CRMCustomSourceObjectTypeActivity,
+		ActorType:          actorType,
+		ActorID:            payload.ActorID,
+		Timestamp:          GetCRMCustomTimestampInSecs(payload.Timestamp),
+		Properties:         properties,
+	}, nil
+}
+
+// CRMCustomPropertyPayload is the label or data type of a property of the object type.
+// Data type is required only for datetime and numerical properties.
+type CRMCustomPropertyPayload struct {
+	ObjectType string `json:"object_type"`
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	DataType   string `json:"data_type"`
+}
+
+func (payload *CRMCustomPropertyPayload) GetCRMProperty(projectID int64, source U.CRMSource) (*CRMProperty, error) {
+	objectType := GetCRMCustomSourceObjectType(payload.ObjectType)
+	if objectType == 0 {
+		return nil, fmt.Errorf("invalid object_type %s", payload.ObjectType)
+	}
+
+	if payload.Name == "" {
+		return nil, errors.New("missing name")
+	}
+
+	if payload.Label == "" && payload.DataType == "" {
+		return nil, errors.New("missing label and data_type")
+	}
+
+	if payload.DataType != "" && !IsValidCRMMappedDataType(payload.DataType) {
+		return nil, fmt.Errorf("invalid data_type %s, should be %s or %s", payload.DataType,
+			U.PropertyTypeDateTime, U.PropertyTypeNumerical)
+	}
+
+	return &CRMProperty{
+		ProjectID:        projectID,
+		Source:           source,
+		Type:             objectType,
+		Name:             payload.Name,
+		Label:            payload.Label,
+		ExternalDataType: payload.DataType,
+		MappedDataType:   payload.DataType,
+	}, nil
+}
@@ -49,7 +49,7 @@ var CRM_SOURCE = map[U.CRMSource]string{
 }
 
 func AllowedCRMBySource(crmSource U.CRMSource) bool {
-	return ALLOWED_CRM_SOURCES[crmSource]
+	return ALLOWED_CRM_SOURCES[crmSource] || IsCRMCustomSource(crmSource)
 }
 
 func IsCRMSource(source string) bool {
@@ -82,7 +82,8 @@ var (
 	CustomMetricProfilesAggregateFunctions   = []string{SumAggregateFunction, UniqueAggregateFunction, AverageAggregateFunction}
 	CustomEventsAggregateFunctions           = []string{SumAggregateFunction, UniqueAggregateFunction, AverageAggregateFunction, CountAggregateFunction}
 	CustomKPIProfileSectionDisplayCategories = []string{HubspotContactsDisplayCategory, HubspotCompaniesDisplayCategory, HubspotDealsDisplayCategory,
-		SalesforceUsersDisplayCategory, SalesforceAccountsDisplayCategory, SalesforceOpportunitiesDisplayCategory, MarketoLeadsDisplayCategory, LeadSquaredLeadsDisplayCategory}
+		SalesforceUsersDisplayCategory, SalesforceAccountsDisplayCategory, SalesforceOpportunitiesDisplayCategory, MarketoLeadsDisplayCategory, LeadSquaredLeadsDisplayCategory,
+		CustomCRMUsersDisplayCategory}
 	CustomKPIProfilesMetricTypes = []string{DateTypeDiffMetricType}
 	MapOfCustomMetricTypeToOp    = map[string]string{DateTypeDiffMetricType: "-"}
 	ProfileQueryType             = 1
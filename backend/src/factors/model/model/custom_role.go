@@ -0,0 +1,353 @@
+package model
+
+import (
+	U "factors/util"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
+)
+
+// CustomRole is a project defined role with a list of permissions, assigned to
+// agents of the project in place of the permissions of the predefined role.
+type CustomRole struct {
+	ID          string          `gorm:"primary_key:true;type:varchar(255)" json:"id"`
+	ProjectID   int64           `gorm:"primary_key:true" json:"project_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Permissions *postgres.Jsonb `json:"permissions"`
+	CreatedBy   string          `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Predefined roles, in addition to AGENT and ADMIN.
+const (
+	VIEWER            = 3
+	ANALYST           = 4
+	MARKETER          = 5
+	INTEGRATION_ADMIN = 6
+)
+
+const (
+	PermissionResourceDashboards   = "dashboards"
+	PermissionResourceReports      = "reports"
+	PermissionResourceSegments     = "segments"
+	PermissionResourceProfiles     = "profiles"
+	PermissionResourceAlerts       = "alerts"
+	PermissionResourceWorkflows    = "workflows"
+	PermissionResourceIntegrations = "integrations"
+	PermissionResourceSettings     = "settings"
+	// admin resource is of the admin only actions, like managing the agents, api keys and
+	// billing. Write on it is only with the ADMIN role and can not be given to custom roles.
+	PermissionResourceAdmin = "admin"
+
+	PermissionActionRead = "read"
+	// write permission implies read permission on the resource.
+	PermissionActionWrite = "write"
+
+	MaxCustomRolesPerProject = 50
+)
+
+var PermissionResources = []string{
+	PermissionResourceDashboards,
+	PermissionResourceReports,
+	PermissionResourceSegments,
+	PermissionResourceProfiles,
+	PermissionResourceAlerts,
+	PermissionResourceWorkflows,
+	PermissionResourceIntegrations,
+	PermissionResourceSettings,
+}
+
+// resourceByRoutePrefix maps the first segment of the route, after project_id and
+// the version, to the resource. GET routes not on the map are allowed for all roles,
+// other routes not on the map are denied.
+var resourceByRoutePrefix = map[string]string{
+	"dashboards":             PermissionResourceDashboards,
+	"dashboard":              PermissionResourceDashboards,
+	"dashboard_folder":       PermissionResourceDashboards,
+	"dashboard_template":     PermissionResourceDashboards,
+	"attribution/dashboards": PermissionResourceDashboards,
+	"predefined_dashboards":  PermissionResourceDashboards,
+	"queries":                PermissionResourceReports,
+	"query":                  PermissionResourceReports,
+	"attribution/queries":    PermissionResourceReports,
+	"attribution/query":      PermissionResourceReports,
+	"pathanalysis":           PermissionResourceReports,
+	"shareable_url":          PermissionResourceReports,
+	"templates":              PermissionResourceReports,
+	"kpi":                    PermissionResourceReports,
+	"explain":                PermissionResourceReports,
+	"explainV2":              PermissionResourceReports,
+	"explainV3":              PermissionResourceReports,
+	"factor":                 PermissionResourceReports,
+	"sixsignal":              PermissionResourceReports,
+	"weeklyinsights":         PermissionResourceReports,
+	"segments":               PermissionResourceSegments,
+	"segment_folders":        PermissionResourceSegments,
+	"segment_folders_item":   PermissionResourceSegments,
+	"profiles":               PermissionResourceProfiles,
+	"uploadlist":             PermissionResourceSegments,
+	"alerts":                 PermissionResourceAlerts,
+	"all_alerts":             PermissionResourceAlerts,
+	"eventtriggeralert":      PermissionResourceAlerts,
+	"workflow":               PermissionResourceWorkflows,
+	"bingads":                PermissionResourceIntegrations,
+	"marketo":                PermissionResourceIntegrations,
+	"slack":                  PermissionResourceIntegrations,
+	"teams":                  PermissionResourceIntegrations,
+	"leadsquaredsettings":    PermissionResourceIntegrations,
+	"paragon":                PermissionResourceIntegrations,
+	"crm_status":             PermissionResourceIntegrations,
+	"integrations_status":    PermissionResourceIntegrations,
+	"linkedin_capi":          PermissionResourceIntegrations,
+	"linkedin_capping":       PermissionResourceIntegrations,
+	"factors_deanon":         PermissionResourceIntegrations,
+	"settings":               PermissionResourceSettings,
+	"":                       PermissionResourceSettings,
+	"features":               PermissionResourceSettings,
+	"feature_gates":          PermissionResourceSettings,
+	"otp_rules":              PermissionResourceSettings,
+	"smart_event":            PermissionResourceSettings,
+	"smart_properties":       PermissionResourceSettings,
+	"contentgroup":           PermissionResourceSettings,
+	"custom_metrics":         PermissionResourceSettings,
+	"filters":                PermissionResourceSettings,
+	"factors":                PermissionResourceSettings,
+	"events":                 PermissionResourceSettings,
+	"accscore":               PermissionResourceSettings,
+	"kpi/property_mappings":  PermissionResourceSettings,
+	"profiles/events_config": PermissionResourceSettings,
+	"agents":                 PermissionResourceAdmin,
+	"custom_roles":           PermissionResourceAdmin,
+	"api_keys":               PermissionResourceAdmin,
+	"plan":                   PermissionResourceAdmin,
+	"billing":                PermissionResourceAdmin,
+	"crm_custom_sources":     PermissionResourceIntegrations,
+	// routes out of the project, with the root of the route as prefix.
+	"integrations":              PermissionResourceIntegrations,
+	"common/dashboard_template": PermissionResourceDashboards,
+	"common/alert_templates":    PermissionResourceSettings,
+}
+
+// routeRootsWithoutProjectPrefix are the roots of the routes, which are not under
+// the project on the url. The permission of these routes is mapped by the path
+// from the root, without the project_id param.
+var routeRootsWithoutProjectPrefix = map[string]bool{
+	"integrations": true,
+	"common":       true,
+}
+
+// routesAllowedForAllRoles are the routes of the agent's own actions on the project,
+// which do not need any permission. Agents remove handler allows the non admins only
+// to remove themselves and the invite handlers allow inviting only the roles within
+// the permissions of the inviter.
+var routesAllowedForAllRoles = map[string]bool{
+	"feedback":           true,
+	"checklist/update":   true,
+	"agents/remove":      true,
+	"agents/invite":      true,
+	"agents/batchinvite": true,
+}
+
+// querySegments are the segments of the POST routes which only query the resource.
+var querySegments = map[string]bool{
+	"query":             true,
+	"filter_values":     true,
+	"holdout_lift":      true,
+	"compare":           true,
+	"common_properties": true,
+}
+
+// queryRoutes are the POST routes, without any query segment, which only query the resource.
+var queryRoutes = map[string]bool{
+	"factor":                 true,
+	"profiles/users":         true,
+	"profiles/accounts":      true,
+	"sixsignal":              true,
+	"sixsignal/publicreport": true,
+}
+
+func GetPermission(resource, action string) string {
+	return fmt.Sprintf("%s:%s", resource, action)
+}
+
+func IsValidPermission(permission string) bool {
+	parts := strings.Split(permission, ":")
+	if len(parts) != 2 {
+		return false
+	}
+	if parts[1] != PermissionActionRead && parts[1] != PermissionActionWrite {
+		return false
+	}
+	if parts[0] == PermissionResourceAdmin {
+		return parts[1] == PermissionActionRead
+	}
+	for _, resource := range PermissionResources {
+		if parts[0] == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func getPermissionsOnResources(action string, resources ...string) []string {
+	permissions := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		permissions = append(permissions, GetPermission(resource, action))
+	}
+	return permissions
+}
+
+func getPredefinedRolePermissions() map[uint64][]string {
+	allWrite := getPermissionsOnResources(PermissionActionWrite, PermissionResources...)
+	allRead := append(getPermissionsOnResources(PermissionActionRead, PermissionResources...),
+		GetPermission(PermissionResourceAdmin, PermissionActionRead))
+
+	return map[uint64][]string{
+		// AGENT keeps the access it had before the permissions, except the admin only actions.
+		AGENT:  append(allWrite, GetPermission(PermissionResourceAdmin, PermissionActionRead)),
+		ADMIN:  append(allWrite, GetPermission(PermissionResourceAdmin, PermissionActionWrite)),
+		VIEWER: allRead,
+		ANALYST: append(getPermissionsOnResources(PermissionActionWrite, PermissionResourceDashboards,
+			PermissionResourceReports, PermissionResourceSegments, PermissionResourceProfiles), allRead...),
+		MARKETER: append(getPermissionsOnResources(PermissionActionWrite, PermissionResourceDashboards,
+			PermissionResourceReports, PermissionResourceSegments, PermissionResourceProfiles,
+			PermissionResourceAlerts, PermissionResourceWorkflows), allRead...),
+		INTEGRATION_ADMIN: append(getPermissionsOnResources(PermissionActionWrite,
+			PermissionResourceIntegrations, PermissionResourceSettings), allRead...),
+	}
+}
+
+var predefinedRolePermissions = getPredefinedRolePermissions()
+
+func IsValidRole(role int64) bool {
+	_, exists := predefinedRolePermissions[uint64(role)]
+	return exists
+}
+
+func GetPredefinedRolePermissions(role uint64) []string {
+	return predefinedRolePermissions[role]
+}
+
+// HasPermission checks the permission on the list of permissions. Write permission
+// on the resource allows read.
+func HasPermission(permissions []string, permission string) bool {
+	parts := strings.Split(permission, ":")
+	for i := range permissions {
+		if permissions[i] == permission {
+			return true
+		}
+		if len(parts) == 2 && parts[1] == PermissionActionRead &&
+			permissions[i] == GetPermission(parts[0], PermissionActionWrite) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRoleWithinPermissions checks if all the permissions of the predefined role are
+// on the list of permissions, i.e the role is not above the one with the permissions.
+func IsRoleWithinPermissions(role uint64, permissions []string) bool {
+	for _, permission := range GetPredefinedRolePermissions(role) {
+		if !HasPermission(permissions, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetPermissionForRequest returns the permission required for the route of the
+// request, if the route is of a resource with permissions. Reads are GET requests
+// and the POST requests for querying. The permission is empty for the routes allowed
+// for all roles.
+func GetPermissionForRequest(method, fullPath string) (string, bool) {
+	path, exists := getPermissionPathForRoute(fullPath)
+	if !exists {
+		return "", false
+	}
+	if routesAllowedForAllRoles[path] {
+		return "", true
+	}
+	segments := strings.Split(path, "/")
+
+	resource, exists := "", false
+	if len(segments) > 1 {
+		resource, exists = resourceByRoutePrefix[segments[0]+"/"+segments[1]]
+	}
+	if !exists {
+		resource, exists = resourceByRoutePrefix[segments[0]]
+	}
+	if !exists {
+		return "", false
+	}
+
+	action := PermissionActionWrite
+	if method == http.MethodGet {
+		action = PermissionActionRead
+	} else if method == http.MethodPost {
+		if queryRoutes[path] {
+			action = PermissionActionRead
+		}
+		for _, segment := range segments {
+			if querySegments[segment] {
+				action = PermissionActionRead
+				break
+			}
+		}
+	}
+
+	return GetPermission(resource, action), true
+}
+
+// getPermissionPathForRoute returns the path after the project_id for the project routes
+// and the path from the root for the routes with a root without project prefix.
+func getPermissionPathForRoute(fullPath string) (string, bool) {
+	segments := strings.Split(strings.Trim(fullPath, "/"), "/")
+	for i, segment := range segments {
+		if routeRootsWithoutProjectPrefix[segment] {
+			pathSegments := make([]string, 0, len(segments)-i)
+			for _, pathSegment := range segments[i:] {
+				if pathSegment != ":project_id" {
+					pathSegments = append(pathSegments, pathSegment)
+				}
+			}
+			return strings.Join(pathSegments, "/"), true
+		}
+
+		if segment == ":project_id" {
+			return strings.TrimPrefix(strings.Join(segments[i+1:], "/"), "v1/"), true
+		}
+	}
+	return "", false
+}
+
+func GetCustomRolePermissions(customRole *CustomRole) ([]string, error) {
+	permissions := make([]string, 0)
+	if customRole == nil || customRole.Permissions == nil {
+		return permissions, nil
+	}
+
+	err := U.DecodePostgresJsonbToStructType(customRole.Permissions, &permissions)
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// ValidateCustomRolePermissions checks the permissions and returns them as json.
+func ValidateCustomRolePermissions(permissions []string) (*postgres.Jsonb, error) {
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("permissions are required")
+	}
+	for _, permission := range permissions {
+		if !IsValidPermission(permission) {
+			return nil, fmt.Errorf("invalid permission %s", permission)
+		}
+	}
+
+	return U.EncodeStructTypeToPostgresJsonb(permissions)
+}
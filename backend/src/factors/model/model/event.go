@@ -175,6 +175,59 @@ func AreMarketingPropertiesMatching(event1 Event, event2 Event) bool {
 	return true
 }
 
+// IsNewSessionRequired checks if the next event of the user should start a new session,
+// as per the session definition of the project, instead of continuing with the session of the event.
+// landingReferrerDomain is the referrer domain of the landing page of the session of the event.
+func IsNewSessionRequired(definition SessionDefinition, event, nextEvent *Event, landingReferrerDomain string,
+	timezone U.TimeZoneString) bool {
+	if (nextEvent.Timestamp - event.Timestamp) > definition.GetInactivityTimeoutInSecs() {
+		return true
+	}
+
+	if definition.SplitAtMidnight &&
+		U.GetDateOnlyFormatFromTimestampAndTimezone(event.Timestamp, timezone) !=
+			U.GetDateOnlyFormatFromTimestampAndTimezone(nextEvent.Timestamp, timezone) {
+		return true
+	}
+
+	if definition.NewSessionOnReferrerChange {
+		return isReferrerDomainChanged(landingReferrerDomain, nextEvent)
+	}
+
+	return false
+}
+
+// isReferrerDomainChanged checks if the next event is a page view from an external
+// referrer domain, which is not the referrer domain of the landing page of the session.
+func isReferrerDomainChanged(landingReferrerDomain string, nextEvent *Event) bool {
+	nextEventProperties, err := U.DecodePostgresJsonb(&nextEvent.Properties)
+	if err != nil {
+		return false
+	}
+	nextEventPropertiesMap := U.PropertiesMap(*nextEventProperties)
+	if !U.IsPageViewEvent(&nextEventPropertiesMap) {
+		return false
+	}
+
+	referrerDomain := strings.ToLower(U.GetPropertyValueAsString(nextEventPropertiesMap[U.EP_REFERRER_DOMAIN]))
+	pageDomain := strings.ToLower(U.GetPropertyValueAsString(nextEventPropertiesMap[U.EP_PAGE_DOMAIN]))
+	// Navigation within the website.
+	if referrerDomain == "" || referrerDomain == pageDomain {
+		return false
+	}
+
+	return referrerDomain != strings.ToLower(landingReferrerDomain)
+}
+
+// GetEventReferrerDomain returns the referrer domain on the properties of the event.
+func GetEventReferrerDomain(event *Event) string {
+	eventProperties, err := U.DecodePostgresJsonb(&event.Properties)
+	if err != nil {
+		return ""
+	}
+	return U.GetPropertyValueAsString((*eventProperties)[U.EP_REFERRER_DOMAIN])
+}
+
 func GetChannelGroup(project Project, sessionPropertiesMap U.PropertiesMap) (string, string) {
 
 	var channelGroupRules []ChannelPropertyRule
@@ -47,6 +47,7 @@ const TYPE_FILTER_EVENT_NAME = "FE"
 const TYPE_INTERNAL_EVENT_NAME = "IE"
 const TYPE_CRM_SALESFORCE = "CS"
 const TYPE_CRM_HUBSPOT = "CH"
+const TYPE_CRM_CUSTOM = "CC"
 const EVENT_NAME_REQUEST_TYPE_APPROX = "approx"
 const EVENT_NAME_REQUEST_TYPE_EXACT = "exact"
 const EVENT_NAME_TYPE_SMART_EVENT = "SE"
@@ -59,6 +60,7 @@ var ALLOWED_TYPES = [...]string{
 	TYPE_INTERNAL_EVENT_NAME,
 	TYPE_CRM_SALESFORCE,
 	TYPE_CRM_HUBSPOT,
+	TYPE_CRM_CUSTOM,
 }
 
 var AllowedEventNamesForHubspot = []string{
@@ -166,8 +168,15 @@ const (
 
 	SmartCRMEventSalesforceCurrPropertyPrefix = SmartCRMEventCurrentPropertyPrefix + SmartCRMEventSourceSalesforce + "_"
 	SmartCRMEventHubspotCurrPropertyPrefix    = SmartCRMEventCurrentPropertyPrefix + SmartCRMEventSourceHubspot + "_"
+
+	// source of the filter on custom sources is the source alias, i.e custom_crm_pipedrive
+	SmartCRMEventCustomCRMPrevPropertyPrefix = SmartCRMEventPreviousPropertyPrefix + U.CRM_SOURCE_NAME_CUSTOM + "_"
+	SmartCRMEventCustomCRMCurrPropertyPrefix = SmartCRMEventCurrentPropertyPrefix + U.CRM_SOURCE_NAME_CUSTOM + "_"
 )
 
+// SmartCRMEventTypes event name types of the CRM smart events
+var SmartCRMEventTypes = []string{TYPE_CRM_SALESFORCE, TYPE_CRM_HUBSPOT, TYPE_CRM_CUSTOM}
+
 var ErrorSmartEventFiterEmptyString = errors.New("empty string")
 
 // GetDecodedSmartEventFilterExp unmarhsal encoded CRM smart event filter exp to SmartCRMEventFilter struct
@@ -211,12 +220,14 @@ func getCurrPropertyName(pName, source, objectType string) string {
 // GetPropertyNameByTrimmedSmartEventPropertyPrefix removes smart event property property prefix
 func GetPropertyNameByTrimmedSmartEventPropertyPrefix(pName string) string {
 	if strings.HasPrefix(pName, SmartCRMEventSalesforcePrevPropertyPrefix) ||
-		strings.HasPrefix(pName, SmartCRMEventHubspotPrevPropertyPrefix) {
+		strings.HasPrefix(pName, SmartCRMEventHubspotPrevPropertyPrefix) ||
+		strings.HasPrefix(pName, SmartCRMEventCustomCRMPrevPropertyPrefix) {
 		return U.NAME_PREFIX + strings.TrimPrefix(pName, SmartCRMEventPreviousPropertyPrefix)
 	}
 
 	if strings.HasPrefix(pName, SmartCRMEventSalesforceCurrPropertyPrefix) ||
-		strings.HasPrefix(pName, SmartCRMEventHubspotCurrPropertyPrefix) {
+		strings.HasPrefix(pName, SmartCRMEventHubspotCurrPropertyPrefix) ||
+		strings.HasPrefix(pName, SmartCRMEventCustomCRMCurrPropertyPrefix) {
 		return U.NAME_PREFIX + strings.TrimPrefix(pName, SmartCRMEventCurrentPropertyPrefix)
 	}
 
@@ -279,7 +290,7 @@ func isSameSourceAndObjectType(existingFilter *SmartCRMEventFilter, incomingFilt
 
 // IsEventNameTypeSmartEvent validates event name is of type smart event
 func IsEventNameTypeSmartEvent(eventType string) bool {
-	return eventType == TYPE_CRM_HUBSPOT || eventType == TYPE_CRM_SALESFORCE
+	return eventType == TYPE_CRM_HUBSPOT || eventType == TYPE_CRM_SALESFORCE || eventType == TYPE_CRM_CUSTOM
 }
 
 func isDuplicateTimestampReferenceField(existingFilter, incomingFilter *SmartCRMEventFilter) bool {
@@ -619,6 +630,11 @@ func isValidSmartCRMFilterObjectType(smartCRMFilter *SmartCRMEventFilter) bool {
 		}
 	}
 
+	// registration of the custom source for the project is checked on the store.
+	if IsCRMCustomSourceAlias(smartCRMFilter.Source) {
+		return CRMCustomSourceUserTypes[GetCRMCustomSourceObjectType(smartCRMFilter.ObjectType)]
+	}
+
 	return false
 }
 
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
+)
+
+const (
+	DeliveryStatusSuccess = "success"
+	DeliveryStatusFailure = "failure"
+
+	DefaultDeliveriesLimit = 100
+	MaxDeliveriesLimit     = 1000
+	// Deliveries older than the retention are deleted by the cleanup job.
+	DeliveryRetentionDays = 30
+)
+
+// EventTriggerAlertDelivery is the log of a single send of an alert to one of its delivery options.
+type EventTriggerAlertDelivery struct {
+	ID             string          `gorm:"column:id" json:"id"`
+	ProjectID      int64           `gorm:"column:project_id; primary_key:true" json:"project_id"`
+	AlertID        string          `gorm:"column:alert_id" json:"alert_id"`
+	DeliveryOption string          `gorm:"column:delivery_option" json:"delivery_option"`
+	Destination    string          `gorm:"column:destination" json:"destination"`
+	Payload        *postgres.Jsonb `gorm:"column:payload" json:"payload"`
+	Status         string          `gorm:"column:status" json:"status"`
+	Error          string          `gorm:"column:error" json:"error"`
+	LatencyInMs    int64           `gorm:"column:latency_in_ms" json:"latency_in_ms"`
+	IsRetry        bool            `gorm:"column:is_retry" json:"is_retry"`
+	ReplayOf       string          `gorm:"column:replay_of" json:"replay_of"`
+	ReplayedAt     *time.Time      `gorm:"column:replayed_at" json:"replayed_at"`
+	CreatedAt      time.Time       `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time       `gorm:"column:updated_at; autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,361 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"factors/cache"
+	cacheRedis "factors/cache/redis"
+	"fmt"
+	"html"
+	"strings"
+
+	U "factors/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultEmailDigestIntervalInMins = 60
+	// MaxMessagesInEmailDigest caps the alerts listed on a digest, the count still includes all.
+	MaxMessagesInEmailDigest = 50
+
+	// digest items list key structure = ETA:Digest:pid:<project_id>:<alert_id>
+	// pending digests sorted set key structure = ETA:Digest:pid:<project_id>
+	// digest send state key structure = ETA:DigestSend:pid:<project_id>:<alert_id>
+	prefixNameforDigest     = "ETA:Digest"
+	prefixNameforDigestSend = "ETA:DigestSend"
+)
+
+// EventTriggerAlertEmailDigest holds the alerts batched for a single email,
+// until the digest interval of the alert has passed.
+type EventTriggerAlertEmailDigest struct {
+	AlertID   string                        `json:"alert_id"`
+	StartedAt int64                         `json:"started_at"`
+	Count     int64                         `json:"count"`
+	Items     []EventTriggerAlertDigestItem `json:"items"`
+	// SentTo is the recipients the digest is already sent to, on a partially failed send.
+	SentTo []string `json:"sent_to"`
+}
+
+type EventTriggerAlertDigestItem struct {
+	Message    EventTriggerAlertMessage `json:"message"`
+	AccountURL string                   `json:"account_url"`
+	Timestamp  int64                    `json:"timestamp"`
+}
+
+// eventTriggerAlertDigestSend is the state of a partially failed send of the digest. The
+// retry sends the same alerts, only to the recipients which haven't received the digest.
+type eventTriggerAlertDigestSend struct {
+	Count  int64    `json:"count"`
+	SentTo []string `json:"sent_to"`
+}
+
+func GetEventTriggerAlertDigestCacheKey(projectID int64, alertID string) (*cache.Key, error) {
+	key, err := cache.NewKey(projectID, prefixNameforDigest, alertID)
+	if err != nil || key == nil {
+		log.WithError(err).Error("cacheKey NewKey function failure")
+		return nil, err
+	}
+	return key, err
+}
+
+func GetPendingEventTriggerAlertDigestsCacheKey(projectID int64) (*cache.Key, error) {
+	return cache.NewKeyWithOnlyPrefix(fmt.Sprintf("%s:pid:%d", prefixNameforDigest, projectID))
+}
+
+func getEventTriggerAlertDigestSendCacheKey(projectID int64, alertID string) (*cache.Key, error) {
+	return cache.NewKey(projectID, prefixNameforDigestSend, alertID)
+}
+
+// AddToEventTriggerAlertEmailDigest appends the alert to the digest of the alert
+// and marks the digest as pending for the project. The items are appended with
+// RPUSH, for the concurrent adds to not overwrite each other.
+func AddToEventTriggerAlertEmailDigest(projectID int64, alertID string, item EventTriggerAlertDigestItem) error {
+	key, err := GetEventTriggerAlertDigestCacheKey(projectID, alertID)
+	if err != nil {
+		return err
+	}
+	if item.Timestamp == 0 {
+		item.Timestamp = U.TimeNowUnix()
+	}
+	itemJson, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if _, err = cacheRedis.RPushPersistent(key, string(itemJson), float64(cacheExpiry)); err != nil {
+		log.WithError(err).Error("Failed to add to cache for event trigger alert digest.")
+		return err
+	}
+
+	return markEventTriggerAlertDigestPending(projectID, alertID)
+}
+
+func markEventTriggerAlertDigestPending(projectID int64, alertID string) error {
+	ssKey, err := GetPendingEventTriggerAlertDigestsCacheKey(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = cacheRedis.ZincrPersistentBatch(true, cacheRedis.SortedSetKeyValueTuple{Key: ssKey, Value: alertID})
+	return err
+}
+
+// GetEventTriggerAlertEmailDigest returns nil if there is no digest pending for the alert.
+// On a partially failed send, the digest is limited to the alerts of the failed send.
+func GetEventTriggerAlertEmailDigest(projectID int64, alertID string) (*EventTriggerAlertEmailDigest, error) {
+	key, err := GetEventTriggerAlertDigestCacheKey(projectID, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := cacheRedis.LLenPersistent(key)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	send, err := getEventTriggerAlertDigestSend(projectID, alertID)
+	if err != nil {
+		return nil, err
+	}
+	digest := EventTriggerAlertEmailDigest{AlertID: alertID, Count: count, SentTo: make([]string, 0)}
+	if send != nil && send.Count > 0 && send.Count <= count {
+		digest.Count = send.Count
+		digest.SentTo = send.SentTo
+	}
+
+	itemsCount := digest.Count
+	if itemsCount > MaxMessagesInEmailDigest {
+		itemsCount = MaxMessagesInEmailDigest
+	}
+	itemsJson, err := cacheRedis.LRangePersistent(key, 0, itemsCount-1)
+	if err != nil {
+		return nil, err
+	}
+
+	digest.Items = make([]EventTriggerAlertDigestItem, 0, len(itemsJson))
+	for i := range itemsJson {
+		var item EventTriggerAlertDigestItem
+		if err := json.Unmarshal([]byte(itemsJson[i]), &item); err != nil {
+			return nil, err
+		}
+		digest.Items = append(digest.Items, item)
+	}
+	if len(digest.Items) > 0 {
+		digest.StartedAt = digest.Items[0].Timestamp
+	}
+	return &digest, nil
+}
+
+func getEventTriggerAlertDigestSend(projectID int64, alertID string) (*eventTriggerAlertDigestSend, error) {
+	key, err := getEventTriggerAlertDigestSendCacheKey(projectID, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	sendJson, exists, err := cacheRedis.GetIfExistsPersistent(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var send eventTriggerAlertDigestSend
+	if err := json.Unmarshal([]byte(sendJson), &send); err != nil {
+		return nil, err
+	}
+	return &send, nil
+}
+
+// SetEventTriggerAlertEmailDigestSentTo keeps the recipients the digest is sent to,
+// for the retry of a partially failed send to skip them.
+func SetEventTriggerAlertEmailDigestSentTo(projectID int64, digest *EventTriggerAlertEmailDigest, sentTo []string) error {
+	key, err := getEventTriggerAlertDigestSendCacheKey(projectID, digest.AlertID)
+	if err != nil {
+		return err
+	}
+
+	sendJson, err := json.Marshal(eventTriggerAlertDigestSend{Count: digest.Count, SentTo: sentTo})
+	if err != nil {
+		return err
+	}
+	return cacheRedis.SetPersistent(key, string(sendJson), float64(cacheExpiry))
+}
+
+// CompleteEventTriggerAlertEmailDigest removes the alerts sent on the digest. The alerts
+// added after the digest was read are kept pending for the next digest.
+func CompleteEventTriggerAlertEmailDigest(projectID int64, digest *EventTriggerAlertEmailDigest) error {
+	key, err := GetEventTriggerAlertDigestCacheKey(projectID, digest.AlertID)
+	if err != nil {
+		return err
+	}
+	if err := cacheRedis.LTrimPersistent(key, digest.Count, -1); err != nil {
+		return err
+	}
+
+	sendKey, err := getEventTriggerAlertDigestSendCacheKey(projectID, digest.AlertID)
+	if err != nil {
+		return err
+	}
+	if err := cacheRedis.DelPersistent(sendKey); err != nil {
+		return err
+	}
+
+	ssKey, err := GetPendingEventTriggerAlertDigestsCacheKey(projectID)
+	if err != nil {
+		return err
+	}
+	if _, err := cacheRedis.ZRemPersistent(ssKey, true, digest.AlertID); err != nil {
+		return err
+	}
+
+	// Alerts added while completing are marked pending again.
+	remaining, err := cacheRedis.LLenPersistent(key)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return markEventTriggerAlertDigestPending(projectID, digest.AlertID)
+	}
+	return nil
+}
+
+// GetPendingEventTriggerAlertDigests returns the ids of the alerts with a digest pending.
+func GetPendingEventTriggerAlertDigests(projectID int64) ([]string, error) {
+	ssKey, err := GetPendingEventTriggerAlertDigestsCacheKey(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := cacheRedis.ZrangeWithScoresPersistent(true, ssKey)
+	if err != nil {
+		return nil, err
+	}
+
+	alertIDs := make([]string, 0, len(members))
+	for alertID := range members {
+		alertIDs = append(alertIDs, alertID)
+	}
+	return alertIDs, nil
+}
+
+func DeleteEventTriggerAlertEmailDigest(projectID int64, alertID string) error {
+	key, err := GetEventTriggerAlertDigestCacheKey(projectID, alertID)
+	if err != nil {
+		return err
+	}
+	sendKey, err := getEventTriggerAlertDigestSendCacheKey(projectID, alertID)
+	if err != nil {
+		return err
+	}
+	if err := cacheRedis.DelPersistent(key, sendKey); err != nil {
+		return err
+	}
+
+	ssKey, err := GetPendingEventTriggerAlertDigestsCacheKey(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = cacheRedis.ZRemPersistent(ssKey, true, alertID)
+	return err
+}
+
+// IsEventTriggerAlertDigestDue tells if the digest interval has passed since the first alert on the digest.
+func IsEventTriggerAlertDigestDue(digest *EventTriggerAlertEmailDigest, intervalInMins int64, now int64) bool {
+	if intervalInMins <= 0 {
+		intervalInMins = DefaultEmailDigestIntervalInMins
+	}
+	return now-digest.StartedAt >= intervalInMins*60
+}
+
+func getDigestIntervalString(intervalInMins int64) string {
+	if intervalInMins <= 0 {
+		intervalInMins = DefaultEmailDigestIntervalInMins
+	}
+
+	switch {
+	case intervalInMins%(24*60) == 0:
+		return pluralizeInterval(intervalInMins/(24*60), "day")
+	case intervalInMins%60 == 0:
+		return pluralizeInterval(intervalInMins/60, "hour")
+	default:
+		return pluralizeInterval(intervalInMins, "minute")
+	}
+}
+
+func pluralizeInterval(count int64, unit string) string {
+	if count == 1 {
+		return unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
+// GetEventTriggerAlertDigestEmailContent returns the subject, html and text of the digest email.
+// Ex subject: 12 accounts - "ICP visited pricing" in the last hour
+func GetEventTriggerAlertDigestEmailContent(title, eventLevel string, digest *EventTriggerAlertEmailDigest,
+	intervalInMins int64) (string, string, string, error) {
+
+	if digest == nil || digest.Count == 0 {
+		return "", "", "", errors.New("empty digest")
+	}
+
+	noun := "users"
+	if eventLevel == EventLevelAccount {
+		noun = "accounts"
+	}
+	if digest.Count == 1 {
+		noun = strings.TrimSuffix(noun, "s")
+	}
+	subject := fmt.Sprintf("%d %s - \"%s\" in the last %s", digest.Count, noun, title,
+		getDigestIntervalString(intervalInMins))
+
+	var htmlBody, textBody strings.Builder
+	htmlBody.WriteString(fmt.Sprintf("<h3>%s</h3>", html.EscapeString(subject)))
+	textBody.WriteString(subject + "\n")
+	for _, item := range digest.Items {
+		htmlBody.WriteString("<hr/><table>")
+		textBody.WriteString("\n")
+		for _, prop := range getOrderedMessageProperties(item.Message.MessageProperty) {
+			htmlBody.WriteString(fmt.Sprintf("<tr><td><b>%s</b></td><td>%s</td></tr>",
+				html.EscapeString(prop.DisplayName), html.EscapeString(fmt.Sprintf("%v", prop.PropValue))))
+			textBody.WriteString(fmt.Sprintf("%s: %v\n", prop.DisplayName, prop.PropValue))
+		}
+		htmlBody.WriteString("</table>")
+		if item.AccountURL != "" {
+			htmlBody.WriteString(fmt.Sprintf("<a href=\"%s\">View in Factors</a>", html.EscapeString(item.AccountURL)))
+			textBody.WriteString(fmt.Sprintf("View in Factors: %s\n", item.AccountURL))
+		}
+	}
+	if remaining := digest.Count - int64(len(digest.Items)); remaining > 0 {
+		htmlBody.WriteString(fmt.Sprintf("<hr/><p>and %d more.</p>", remaining))
+		textBody.WriteString(fmt.Sprintf("\nand %d more.\n", remaining))
+	}
+
+	return subject, htmlBody.String(), textBody.String(), nil
+}
+
+// getOrderedMessageProperties returns the message properties in the order configured on the alert.
+func getOrderedMessageProperties(propMap U.PropertiesMap) []MessagePropMapStruct {
+	props := make([]MessagePropMapStruct, 0)
+	for i := 0; i < len(propMap); i++ {
+		pp := propMap[fmt.Sprintf("%d", i)]
+		if pp == nil {
+			continue
+		}
+
+		var prop MessagePropMapStruct
+		switch value := pp.(type) {
+		case MessagePropMapStruct:
+			prop = value
+		case map[string]interface{}:
+			if err := U.DecodeInterfaceMapToStructType(value, &prop); err != nil {
+				log.Warn("cannot convert interface map to struct type")
+				continue
+			}
+		default:
+			log.Warn("cannot convert interface to map[string]interface{} type")
+			continue
+		}
+		if prop.PropValue == nil || prop.PropValue == "" {
+			prop.PropValue = "<nil>"
+		}
+		props = append(props, prop)
+	}
+	return props
+}
@@ -21,6 +21,7 @@ const (
 	WEBHOOK             = "webhook"
 	prefixNameforAlerts = "ETA"
 	TEAMS               = "teams"
+	EMAIL               = "email"
 	counterIndex        = "Counter"
 	cacheExpiry         = 7 * 24 * 60 * 60
 	cacheCounterExpiry  = 24 * 60 * 60
@@ -44,7 +45,7 @@ const (
 	// coolDownKeyCounter structure = ETA:CoolDown:pid:<project_id>:<alert_id>:<prop>:<value>:....:
 	// failure sorted set key structure = ETA:Fail:pid:<project_id>
 	// failure key = <fail_point>:ETA:pid:<project_id>:<alert_id>:<UnixTime>
-	// 		-> fail_point = Slack/WH/Teams
+	// 		-> fail_point = Slack/WH/Teams/Email
 	// Poison queue sorted set cache key = ETA:Poison:pid:<project_id>
 )
 
@@ -90,6 +91,14 @@ type EventTriggerAlertConfig struct {
 	IsFactorsUrlInPayload bool            `json:"is_factors_url_in_payload"`
 	Teams                 bool            `json:"teams"`
 	TeamsChannelsConfig   *postgres.Jsonb `json:"teams_channels_config"`
+	Email                 bool            `json:"email"`
+	Emails                []string        `json:"emails"`
+	// EmailDigestIntervalInMins is the interval over which alerts are batched into a single email.
+	EmailDigestIntervalInMins int64 `json:"email_digest_interval_in_mins"`
+	// IsLegacySecretHeaderDisabled stops sending the deprecated secret hash header along with
+	// the webhook signature. Unset on the existing alerts, for their receivers to keep working
+	// till they move to the signature.
+	IsLegacySecretHeaderDisabled bool `json:"is_legacy_secret_header_disabled"`
 }
 
 type AlertInfo struct {
@@ -109,6 +118,9 @@ type CachedEventTriggerAlert struct {
 	FieldTags      map[string]string
 	IsWorkflow     bool
 	IsLinkedInCAPI bool
+	// ReplayOf and ReplayTo are set when a failed delivery is re-sent to a single delivery option.
+	ReplayOf string `json:",omitempty"`
+	ReplayTo string `json:",omitempty"`
 }
 
 type EventTriggerAlertMessage struct {
@@ -127,6 +139,8 @@ type EventTriggerWebhook struct {
 	Url                   string          `json:"url"`
 	Secret                string          `json:"secret"`
 	IsFactorsUrlInPayload bool            `json:"is_factors_url_in_payload"`
+	// IsLegacySecretHeaderDisabled stops sending the deprecated secret hash header along with the signature.
+	IsLegacySecretHeaderDisabled bool `json:"is_legacy_secret_header_disabled"`
 }
 
 type MessagePropMapStruct struct {
@@ -36,6 +36,7 @@ const GROUP_NAME_SIX_SIGNAL = "$6signal"
 const GROUP_NAME_DOMAINS = "$domains"
 const GROUP_NAME_LINKEDIN_COMPANY = "$linkedin_company"
 const GROUP_NAME_G2 = "$g2"
+const GROUP_NAME_SEGMENT_ACCOUNT = "$segment_account"
 
 // AllowedGroupNames list of allowed group names
 var AllowedGroupNames = map[string]bool{
@@ -46,6 +47,7 @@ var AllowedGroupNames = map[string]bool{
 	GROUP_NAME_SIX_SIGNAL:             true,
 	GROUP_NAME_LINKEDIN_COMPANY:       true,
 	GROUP_NAME_G2:                     true,
+	GROUP_NAME_SEGMENT_ACCOUNT:        true,
 }
 var AccountGroupNames = map[string]bool{
 	GROUP_NAME_HUBSPOT_COMPANY:    true,
@@ -53,6 +55,7 @@ var AccountGroupNames = map[string]bool{
 	GROUP_NAME_SIX_SIGNAL:         true,
 	GROUP_NAME_LINKEDIN_COMPANY:   true,
 	GROUP_NAME_G2:                 true,
+	GROUP_NAME_SEGMENT_ACCOUNT:    true,
 }
 
 var AllowedGroupToDomainsGroup = map[string]bool{
@@ -61,12 +64,14 @@ var AllowedGroupToDomainsGroup = map[string]bool{
 	GROUP_NAME_SIX_SIGNAL:         true,
 	GROUP_NAME_LINKEDIN_COMPANY:   true,
 	GROUP_NAME_G2:                 true,
+	GROUP_NAME_SEGMENT_ACCOUNT:    true,
 }
 
 var DomainNameSourcePropertyKey = map[string][]string{
 	GROUP_NAME_SIX_SIGNAL:         {U.SIX_SIGNAL_DOMAIN},
 	GROUP_NAME_HUBSPOT_COMPANY:    {"$hubspot_company_domain", "$hubspot_company_website"},
 	GROUP_NAME_SALESFORCE_ACCOUNT: {"$salesforce_account_website"},
+	GROUP_NAME_SEGMENT_ACCOUNT:    {U.SEGMENT_ACCOUNT_DOMAIN},
 }
 
 func GetDomainNameSourcePropertyKey(groupName string) []string {
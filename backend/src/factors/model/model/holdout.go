@@ -0,0 +1,310 @@
+package model
+
+import (
+	"errors"
+	U "factors/util"
+	"math"
+)
+
+const (
+	HoldoutSplitTypeUserProperty    = "user_property"
+	HoldoutSplitTypeSegment         = "segment"
+	HoldoutSplitTypeLinkedinCapping = "linkedin_capping"
+
+	DefaultHoldoutConfidenceLevel = 0.95
+
+	HoldoutGroupExposed = "exposed"
+	HoldoutGroupControl = "control"
+)
+
+// z score of the two sided confidence levels supported.
+var holdoutConfidenceLevelToZScore = map[float64]float64{
+	0.80: 1.282,
+	0.90: 1.645,
+	0.95: 1.960,
+	0.99: 2.576,
+}
+
+var HoldoutLiftResultHeaders = []string{"Metric", "Exposed Population", "Exposed Value", "Exposed Per Unit",
+	"Control Population", "Control Value", "Control Per Unit", "Lift(%)", "Lift Lower(%)", "Lift Upper(%)", "Significant"}
+
+// HoldoutSplit defines the exposed and held-out (control) populations.
+//   - user_property: users with the property set to one of the control values are held out,
+//     users with one of the exposed values (or any other value, when none given) are exposed.
+//   - segment: members of the control segment are held out, members of the exposed segment are exposed.
+//   - linkedin_capping: companies excluded on linkedin by the capping rules (or the given rule)
+//     during the query range are held out, all the other linkedin companies are exposed.
+type HoldoutSplit struct {
+	Type             string   `json:"type"`
+	PropertyName     string   `json:"property_name"`
+	ControlValues    []string `json:"control_values"`
+	ExposedValues    []string `json:"exposed_values"`
+	ControlSegmentID string   `json:"control_segment_id"`
+	ExposedSegmentID string   `json:"exposed_segment_id"`
+	CappingRuleID    string   `json:"capping_rule_id"`
+}
+
+type HoldoutLiftQuery struct {
+	Split           HoldoutSplit  `json:"split"`
+	KPI             KPIQueryGroup `json:"kpi_query_group"`
+	ConfidenceLevel float64       `json:"confidence_level"`
+}
+
+// HoldoutPopulation is the kpi query and the filters on users which restrict it to one group of the split.
+type HoldoutPopulation struct {
+	Group        string
+	KPI          KPIQueryGroup
+	PropertyName string
+	// users with property value in Values, or with property set and not in Values when Exclude.
+	Values    []string
+	Exclude   bool
+	SegmentID string
+}
+
+func (query *HoldoutLiftQuery) IsValid() (bool, string) {
+	if len(query.KPI.Queries) == 0 {
+		return false, "Invalid kpi query group."
+	}
+	if len(query.KPI.GlobalGroupBy) > 0 {
+		return false, "Group by is not supported on holdout lift."
+	}
+	for _, kpiQuery := range query.KPI.Queries {
+		// channel metrics like linkedin and adwords are not attributable to users, so can not be split.
+		if kpiQuery.Category == ChannelCategory || kpiQuery.Category == CustomChannelCategory {
+			return false, "Channel kpis are not supported on holdout lift."
+		}
+	}
+	if query.ConfidenceLevel != 0 {
+		if _, exists := holdoutConfidenceLevelToZScore[query.ConfidenceLevel]; !exists {
+			return false, "Invalid confidence level. Supported 0.8, 0.9, 0.95 and 0.99."
+		}
+	}
+
+	split := query.Split
+	switch split.Type {
+	case HoldoutSplitTypeUserProperty:
+		if split.PropertyName == "" || len(split.ControlValues) == 0 {
+			return false, "Property name and control values are required for user property split."
+		}
+	case HoldoutSplitTypeSegment:
+		if split.ControlSegmentID == "" || split.ExposedSegmentID == "" {
+			return false, "Control and exposed segments are required for segment split."
+		}
+		if split.ControlSegmentID == split.ExposedSegmentID {
+			return false, "Control and exposed segments should be different."
+		}
+	case HoldoutSplitTypeLinkedinCapping:
+	default:
+		return false, "Invalid holdout split type."
+	}
+	return true, ""
+}
+
+func (query *HoldoutLiftQuery) GetZScore() float64 {
+	if zScore, exists := holdoutConfidenceLevelToZScore[query.ConfidenceLevel]; exists {
+		return zScore
+	}
+	return holdoutConfidenceLevelToZScore[DefaultHoldoutConfidenceLevel]
+}
+
+// GetHoldoutPopulations returns the exposed and control populations of the split. Companies excluded by
+// the linkedin capping rules are passed as org ids for the linkedin capping split.
+func GetHoldoutPopulations(query *HoldoutLiftQuery, excludedLinkedinOrgIDs []string) (HoldoutPopulation, HoldoutPopulation, error) {
+	exposed := HoldoutPopulation{Group: HoldoutGroupExposed}
+	control := HoldoutPopulation{Group: HoldoutGroupControl}
+
+	split := query.Split
+	switch split.Type {
+	case HoldoutSplitTypeUserProperty:
+		exposed.PropertyName, control.PropertyName = split.PropertyName, split.PropertyName
+		control.Values = split.ControlValues
+		if len(split.ExposedValues) > 0 {
+			exposed.Values = split.ExposedValues
+		} else {
+			exposed.Values, exposed.Exclude = split.ControlValues, true
+		}
+	case HoldoutSplitTypeSegment:
+		exposed.SegmentID = split.ExposedSegmentID
+		control.SegmentID = split.ControlSegmentID
+	case HoldoutSplitTypeLinkedinCapping:
+		if len(excludedLinkedinOrgIDs) == 0 {
+			return exposed, control, errors.New("no companies excluded by linkedin capping in the range")
+		}
+		exposed.PropertyName, control.PropertyName = U.LI_ORGANIZATION_ID, U.LI_ORGANIZATION_ID
+		control.Values = excludedLinkedinOrgIDs
+		exposed.Values, exposed.Exclude = excludedLinkedinOrgIDs, true
+	default:
+		return exposed, control, errors.New("invalid holdout split type")
+	}
+
+	exposed.KPI = getKPIQueryGroupForHoldoutPopulation(query.KPI, exposed)
+	control.KPI = getKPIQueryGroupForHoldoutPopulation(query.KPI, control)
+	return exposed, control, nil
+}
+
+func getKPIQueryGroupForHoldoutPopulation(kpiQueryGroup KPIQueryGroup, population HoldoutPopulation) KPIQueryGroup {
+	var populationKPI KPIQueryGroup
+	U.DeepCopy(&kpiQueryGroup, &populationKPI)
+
+	if population.SegmentID != "" {
+		populationKPI.SegmentID = population.SegmentID
+		return populationKPI
+	}
+
+	if population.Exclude {
+		// property is set and is none of the values.
+		populationKPI.GlobalFilters = append(populationKPI.GlobalFilters, KPIFilter{Entity: PropertyEntityUser,
+			PropertyName: population.PropertyName, PropertyDataType: U.PropertyTypeCategorical,
+			Condition: NotEqualOpStr, Value: PropertyValueNone, LogicalOp: LOGICAL_OP_AND})
+		for _, value := range population.Values {
+			populationKPI.GlobalFilters = append(populationKPI.GlobalFilters, KPIFilter{Entity: PropertyEntityUser,
+				PropertyName: population.PropertyName, PropertyDataType: U.PropertyTypeCategorical,
+				Condition: NotEqualOpStr, Value: value, LogicalOp: LOGICAL_OP_AND})
+		}
+		return populationKPI
+	}
+
+	for index, value := range population.Values {
+		logicalOp := LOGICAL_OP_OR
+		if index == 0 {
+			logicalOp = LOGICAL_OP_AND
+		}
+		populationKPI.GlobalFilters = append(populationKPI.GlobalFilters, KPIFilter{Entity: PropertyEntityUser,
+			PropertyName: population.PropertyName, PropertyDataType: U.PropertyTypeCategorical,
+			Condition: EqualsOpStr, Value: value, LogicalOp: logicalOp})
+	}
+	return populationKPI
+}
+
+// GetHoldoutTotalsResult returns the result of the kpi query group without group by timestamp,
+// which has a single row of totals of the metrics.
+func GetHoldoutTotalsResult(results []QueryResult) (QueryResult, bool) {
+	for _, result := range results {
+		if len(result.Headers) == 0 || U.ContainsStringInArray(result.Headers, AliasDateTime) {
+			continue
+		}
+		return result, true
+	}
+	return QueryResult{}, false
+}
+
+// GetHoldoutCountMetrics returns the metrics of the kpi query group which are counts of events or
+// users, using the predefined event metrics and the custom metrics of the project.
+func GetHoldoutCountMetrics(kpiQueryGroup KPIQueryGroup, customMetrics []CustomMetric) map[string]bool {
+	countMetrics := make(map[string]bool)
+	for _, query := range kpiQueryGroup.Queries {
+		for _, metric := range query.Metrics {
+			if transformations, exists := TransformationOfKPIMetricsToEventAnalyticsQuery[query.DisplayCategory][metric]; exists {
+				if len(transformations) == 1 && transformations[0].Metrics.Aggregation == CountAggregateFunction &&
+					transformations[0].Metrics.Operator == "" {
+					countMetrics[metric] = true
+				}
+				continue
+			}
+
+			for i := range customMetrics {
+				if customMetrics[i].Name != metric || customMetrics[i].ObjectType != query.DisplayCategory {
+					continue
+				}
+				if isCountCustomMetric(&customMetrics[i]) {
+					countMetrics[metric] = true
+				}
+				break
+			}
+		}
+	}
+	return countMetrics
+}
+
+func isCountCustomMetric(customMetric *CustomMetric) bool {
+	if customMetric.TypeOfQuery != ProfileQueryType && customMetric.TypeOfQuery != EventBasedQueryType {
+		return false
+	}
+	if customMetric.MetricType != "" {
+		return false
+	}
+
+	var transformation CustomMetricTransformation
+	if err := U.DecodePostgresJsonbToStructType(customMetric.Transformations, &transformation); err != nil {
+		return false
+	}
+	return transformation.AggregateFunction == CountAggregateFunction ||
+		transformation.AggregateFunction == UniqueAggregateFunction
+}
+
+// HoldoutLift is the lift of the per unit value of a metric on the exposed population over the control population.
+// Confidence interval is on the ratio of the per unit values, using log normal approximation with the metric
+// values considered as poisson counts. It is computed only for count metrics.
+type HoldoutLift struct {
+	Lift        float64
+	LiftLower   float64
+	LiftUpper   float64
+	Significant bool
+}
+
+func ComputeHoldoutLift(exposedValue, exposedPopulation, controlValue, controlPopulation float64, zScore float64) (HoldoutLift, error) {
+	if exposedPopulation <= 0 || controlPopulation <= 0 {
+		return HoldoutLift{}, errors.New("empty population")
+	}
+	if controlValue <= 0 || exposedValue <= 0 {
+		return HoldoutLift{}, errors.New("lift is not defined for zero values")
+	}
+
+	exposedPerUnit := exposedValue / exposedPopulation
+	controlPerUnit := controlValue / controlPopulation
+	logRatio := math.Log(exposedPerUnit / controlPerUnit)
+	standardError := math.Sqrt(1/exposedValue + 1/controlValue)
+
+	lift := HoldoutLift{
+		Lift:      (exposedPerUnit/controlPerUnit - 1) * 100,
+		LiftLower: (math.Exp(logRatio-zScore*standardError) - 1) * 100,
+		LiftUpper: (math.Exp(logRatio+zScore*standardError) - 1) * 100,
+	}
+	lift.Significant = lift.LiftLower > 0 || lift.LiftUpper < 0
+	return lift, nil
+}
+
+// GetHoldoutLiftResult builds the result with a row for each metric of the kpi query. The confidence
+// interval and significance are only added for the count metrics, as the poisson approximation
+// doesn't hold for metrics like revenue.
+func GetHoldoutLiftResult(metrics []string, countMetrics map[string]bool, exposedValues []float64, exposedPopulation int64,
+	controlValues []float64, controlPopulation int64, zScore float64) QueryResult {
+
+	rows := make([][]interface{}, 0, len(metrics))
+	for index, metric := range metrics {
+		var exposedValue, controlValue float64
+		if index < len(exposedValues) {
+			exposedValue = exposedValues[index]
+		}
+		if index < len(controlValues) {
+			controlValue = controlValues[index]
+		}
+
+		var exposedPerUnit, controlPerUnit float64
+		if exposedPopulation > 0 {
+			exposedPerUnit = exposedValue / float64(exposedPopulation)
+		}
+		if controlPopulation > 0 {
+			controlPerUnit = controlValue / float64(controlPopulation)
+		}
+
+		row := []interface{}{metric, exposedPopulation, exposedValue, exposedPerUnit,
+			controlPopulation, controlValue, controlPerUnit}
+		lift, err := ComputeHoldoutLift(exposedValue, float64(exposedPopulation), controlValue,
+			float64(controlPopulation), zScore)
+		if err != nil {
+			// lift can't be computed without data on both the groups.
+			row = append(row, nil, nil, nil, false)
+		} else if !countMetrics[metric] {
+			liftValue, _ := U.FloatRoundOffWithPrecision(lift.Lift, 2)
+			row = append(row, liftValue, nil, nil, false)
+		} else {
+			liftValue, _ := U.FloatRoundOffWithPrecision(lift.Lift, 2)
+			liftLower, _ := U.FloatRoundOffWithPrecision(lift.LiftLower, 2)
+			liftUpper, _ := U.FloatRoundOffWithPrecision(lift.LiftUpper, 2)
+			row = append(row, liftValue, liftLower, liftUpper, lift.Significant)
+		}
+		rows = append(rows, row)
+	}
+	return QueryResult{Headers: HoldoutLiftResultHeaders, Rows: rows}
+}
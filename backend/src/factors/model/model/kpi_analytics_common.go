@@ -751,7 +751,11 @@ func TransformCRMPropertiesToKPIConfigProperties(properties map[string][]string,
 	case "$marketo":
 		category = "Marketo"
 	default:
-		category = "OTHERS"
+		if strings.HasPrefix(prefix, U.CUSTOM_CRM_PROPERTY_PREFIX) {
+			category = strings.Title(strings.TrimPrefix(prefix, U.CUSTOM_CRM_PROPERTY_PREFIX))
+		} else {
+			category = "OTHERS"
+		}
 	}
 	for dataType, propertyNames := range properties {
 		for _, propertyName := range propertyNames {
@@ -0,0 +1,6 @@
+package model
+
+const (
+	// Contacts and leads of all the custom crm sources of the project.
+	CustomCRMUsersDisplayCategory = "custom_crm_users"
+)
@@ -438,7 +438,7 @@ func GetObjectTypeForFilterValues(displayCategory string, metric string) string
 	} else if displayCategory == FormSubmissionsDisplayCategory {
 		objectType = U.EVENT_NAME_FORM_SUBMITTED
 	} else if U.ContainsStringInArray([]string{HubspotContactsDisplayCategory, HubspotCompaniesDisplayCategory, SalesforceUsersDisplayCategory,
-		SalesforceAccountsDisplayCategory, SalesforceOpportunitiesDisplayCategory, MarketoLeadsDisplayCategory, LeadSquaredLeadsDisplayCategory}, displayCategory) {
+		SalesforceAccountsDisplayCategory, SalesforceOpportunitiesDisplayCategory, MarketoLeadsDisplayCategory, LeadSquaredLeadsDisplayCategory, CustomCRMUsersDisplayCategory}, displayCategory) {
 		metricsData := MapOfMetricsToData[displayCategory][metric]
 		objectType = metricsData["object_type"]
 	} else { // pageViews case as default.
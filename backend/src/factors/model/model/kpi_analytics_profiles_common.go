@@ -19,6 +19,7 @@ var MapOfKPIToProfileType = map[string]string{
 	SalesforceOpportunitiesDisplayCategory: UserSourceSalesforceString,
 	MarketoLeadsDisplayCategory:            UserSourceMarketo,
 	LeadSquaredLeadsDisplayCategory:        UserSourceLeadSquared,
+	CustomCRMUsersDisplayCategory:          UserSourceCustomCRMString,
 }
 
 var MapOfKPICategoryToProfileGroupAnalysis = map[string]string{
@@ -30,6 +31,7 @@ var MapOfKPICategoryToProfileGroupAnalysis = map[string]string{
 	SalesforceOpportunitiesDisplayCategory: GROUP_NAME_SALESFORCE_OPPORTUNITY,
 	MarketoLeadsDisplayCategory:            USERS,
 	LeadSquaredLeadsDisplayCategory:        USERS,
+	CustomCRMUsersDisplayCategory:          USERS,
 }
 
 // Setting and getting Time for profiles query is 0,0. Need to understand.
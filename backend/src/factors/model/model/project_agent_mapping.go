@@ -16,6 +16,8 @@ type ProjectAgentMapping struct {
 	ChecklistDismissed bool   `json:"checklist_dismissed"`
 	// Created as pointer to allow storing NULL in db
 	InvitedBy *string `gorm:"type:varchar(255)" json:"invited_by"`
+	// Permissions of the custom role are used in place of the role, when set.
+	CustomRoleID *string `gorm:"type:varchar(255)" json:"custom_role_id"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	U "factors/util"
 	"fmt"
 	"strings"
@@ -167,6 +168,12 @@ type ProjectSetting struct {
 	// demandbase integration setting
 	IntClientDemandbase *bool  `gorm:"not null;default:false" json:"int_client_demandbase,omitempty"`
 	ClientDemandbaseKey string `json:"client_demandbase_key"`
+
+	// ordered company enrichment providers. Default order is used, if not given.
+	CompanyEnrichmentWaterfall *postgres.Jsonb `json:"company_enrichment_waterfall"`
+
+	// rules for starting a new session. Default definition is used, if not given.
+	SessionDefinition *postgres.Jsonb `json:"session_definition"`
 }
 
 type SAMLConfiguration struct {
@@ -314,6 +321,147 @@ type DeanonVendorConfig struct {
 	TrafficFraction float64 `json:"traffic_fraction"`
 }
 
+const (
+	COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE     = "demandbase"
+	COMPANY_ENRICHMENT_PROVIDER_CLEARBIT       = "clearbit"
+	COMPANY_ENRICHMENT_PROVIDER_SIX_SIGNAL     = "six_signal"
+	COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON = "factors_deanon"
+)
+
+var CompanyEnrichmentProviders = []string{
+	COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE,
+	COMPANY_ENRICHMENT_PROVIDER_CLEARBIT,
+	COMPANY_ENRICHMENT_PROVIDER_SIX_SIGNAL,
+	COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON,
+}
+
+// CompanyEnrichmentWaterfall is the order in which the providers are tried for enrichment.
+// Only the first eligible provider is tried, unless fallback is enabled, which tries the
+// next eligible provider when the enrichment fails.
+type CompanyEnrichmentWaterfall struct {
+	Providers         []CompanyEnrichmentProviderConfig `json:"providers"`
+	FallbackOnFailure bool                              `json:"fallback_on_failure"`
+}
+
+type CompanyEnrichmentProviderConfig struct {
+	Name string `json:"name"`
+	// country and page rules of the provider, in addition to the eligibility of the provider.
+	Rules *SixSignalConfig `json:"rules,omitempty"`
+}
+
+// DefaultCompanyEnrichmentWaterfall tries the providers integrated with the keys of the customer first.
+func DefaultCompanyEnrichmentWaterfall() CompanyEnrichmentWaterfall {
+	waterfall := CompanyEnrichmentWaterfall{Providers: make([]CompanyEnrichmentProviderConfig, 0)}
+	for _, name := range CompanyEnrichmentProviders {
+		waterfall.Providers = append(waterfall.Providers, CompanyEnrichmentProviderConfig{Name: name})
+	}
+	return waterfall
+}
+
+func IsValidCompanyEnrichmentProvider(name string) bool {
+	for _, provider := range CompanyEnrichmentProviders {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateCompanyEnrichmentWaterfall(waterfall CompanyEnrichmentWaterfall) error {
+	if len(waterfall.Providers) == 0 {
+		return errors.New("no providers on company enrichment waterfall")
+	}
+	providers := make(map[string]bool)
+	for _, provider := range waterfall.Providers {
+		if !IsValidCompanyEnrichmentProvider(provider.Name) {
+			return fmt.Errorf("invalid company enrichment provider %s", provider.Name)
+		}
+		if providers[provider.Name] {
+			return fmt.Errorf("duplicate company enrichment provider %s", provider.Name)
+		}
+		providers[provider.Name] = true
+	}
+	return nil
+}
+
+// GetCompanyEnrichmentWaterfall returns the waterfall on the settings or the default waterfall.
+func GetCompanyEnrichmentWaterfall(projectSettings *ProjectSetting) (CompanyEnrichmentWaterfall, error) {
+	if projectSettings.CompanyEnrichmentWaterfall == nil || U.IsEmptyPostgresJsonb(projectSettings.CompanyEnrichmentWaterfall) {
+		return DefaultCompanyEnrichmentWaterfall(), nil
+	}
+
+	var waterfall CompanyEnrichmentWaterfall
+	if err := U.DecodePostgresJsonbToStructType(projectSettings.CompanyEnrichmentWaterfall, &waterfall); err != nil {
+		return DefaultCompanyEnrichmentWaterfall(), err
+	}
+	return waterfall, nil
+}
+
+// SessionDefinition is the set of rules for starting a new session of the user.
+// The inactivity timeout always applies, the other rules are in addition to it.
+type SessionDefinition struct {
+	InactivityTimeoutInMins int64 `json:"inactivity_timeout_in_mins"`
+	// Splits the session at midnight on the timezone of the project.
+	SplitAtMidnight bool `json:"split_at_midnight"`
+	// Starts a new session when the page view has a different utm or campaign property.
+	NewSessionOnCampaignChange bool `json:"new_session_on_campaign_change"`
+	// Starts a new session when the page view is from a different external referrer domain.
+	NewSessionOnReferrerChange bool `json:"new_session_on_referrer_change"`
+}
+
+const (
+	SessionMinInactivityTimeoutInMins = 1
+	SessionMaxInactivityTimeoutInMins = 24 * 60
+)
+
+// DefaultSessionDefinition is the definition used before it was configurable per project.
+func DefaultSessionDefinition() SessionDefinition {
+	return SessionDefinition{
+		InactivityTimeoutInMins:    NewUserSessionInactivityInSeconds / 60,
+		NewSessionOnCampaignChange: true,
+	}
+}
+
+func ValidateSessionDefinition(definition SessionDefinition) error {
+	if definition.InactivityTimeoutInMins < SessionMinInactivityTimeoutInMins ||
+		definition.InactivityTimeoutInMins > SessionMaxInactivityTimeoutInMins {
+		return fmt.Errorf("session inactivity timeout should be between %d and %d mins",
+			SessionMinInactivityTimeoutInMins, SessionMaxInactivityTimeoutInMins)
+	}
+	return nil
+}
+
+// DecodeSessionDefinition decodes the session definition json and validates it. Rules not on the
+// json are taken from the default definition.
+func DecodeSessionDefinition(definitionJsonb *postgres.Jsonb) (SessionDefinition, error) {
+	definition := DefaultSessionDefinition()
+	if err := U.DecodePostgresJsonbToStructType(definitionJsonb, &definition); err != nil {
+		return definition, err
+	}
+	if err := ValidateSessionDefinition(definition); err != nil {
+		return definition, err
+	}
+	return definition, nil
+}
+
+// GetSessionDefinition returns the session definition on the settings or the default definition.
+func GetSessionDefinition(projectSettings *ProjectSetting) (SessionDefinition, error) {
+	if projectSettings == nil || projectSettings.SessionDefinition == nil ||
+		U.IsEmptyPostgresJsonb(projectSettings.SessionDefinition) {
+		return DefaultSessionDefinition(), nil
+	}
+
+	definition, err := DecodeSessionDefinition(projectSettings.SessionDefinition)
+	if err != nil {
+		return DefaultSessionDefinition(), err
+	}
+	return definition, nil
+}
+
+func (definition *SessionDefinition) GetInactivityTimeoutInSecs() int64 {
+	return definition.InactivityTimeoutInMins * 60
+}
+
 type FilterIps struct {
 	BlockIps []string `json:"block_ips"`
 }
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	U "factors/util"
+)
+
+const (
+	SegmentMembershipEntered = "entered"
+	SegmentMembershipExited  = "exited"
+
+	DefaultSegmentMembershipHistoryLimit = 100
+	MaxSegmentMembershipHistoryLimit     = 1000
+)
+
+// SegmentMembershipChange is a single entry to or exit from a segment, recorded by the segment marker.
+type SegmentMembershipChange struct {
+	ID        string    `gorm:"column:id; primary_key:true" json:"id"`
+	ProjectID int64     `gorm:"column:project_id; primary_key:true" json:"project_id"`
+	SegmentID string    `gorm:"column:segment_id" json:"segment_id"`
+	UserID    string    `gorm:"column:user_id" json:"user_id"`
+	Action    string    `gorm:"column:action" json:"action"`
+	Timestamp int64     `gorm:"column:timestamp" json:"timestamp"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// GetSegmentMembershipEventName returns the event tracked on the user for the membership action.
+func GetSegmentMembershipEventName(action string) string {
+	if action == SegmentMembershipExited {
+		return U.EVENT_NAME_SEGMENT_EXITED
+	}
+	return U.EVENT_NAME_SEGMENT_ENTERED
+}
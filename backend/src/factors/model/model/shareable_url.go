@@ -1,29 +1,39 @@
 package model
 
 import (
+	"encoding/json"
+	"factors/cache"
+	cacheRedis "factors/cache/redis"
+	U "factors/util"
+	"strings"
 	"time"
 )
 
 type ShareableURL struct {
-	ID         string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-	QueryID    string `gorm:"not null" json:"query_id"`
-	EntityType int    `gorm:"not null" json:"entity_type"`
-	ShareType  int    `gorm:"not null" json:"share_type"`
-	// AllowedUsers   string    `gorm:"type:varchar" json:"allowed_users"`
-	EntityID  int64     `gorm:"not null" json:"entity_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	IsDeleted bool      `gorm:"not null;default:false" json:"is_deleted"`
-	ExpiresAt int64     `json:"expires_at"`
-	ProjectID int64     `gorm:"not null" json:"project_id"`
-	CreatedBy string    `gorm:"not null;type:varchar(255)" json:"created_by"`
+	ID           string    `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	QueryID      string    `gorm:"not null" json:"query_id"`
+	EntityType   int       `gorm:"not null" json:"entity_type"`
+	ShareType    int       `gorm:"not null" json:"share_type"`
+	AllowedUsers string    `gorm:"type:varchar" json:"allowed_users"`
+	PasswordHash string    `json:"-"`
+	EntityID     int64     `gorm:"not null" json:"entity_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	IsDeleted    bool      `gorm:"not null;default:false" json:"is_deleted"`
+	ExpiresAt    int64     `json:"expires_at"`
+	ProjectID    int64     `gorm:"not null" json:"project_id"`
+	CreatedBy    string    `gorm:"not null;type:varchar(255)" json:"created_by"`
 }
 
 // Keep them ordered in ascending order with respect to scope of the share type
 const (
 	ShareableURLShareTypePublic int = iota + 1
-	// ShareableURLShareTypeAllProjectUsers
-	// ShareableURLShareTypeAllowedUsers
+	// Anyone with the link and the password.
+	ShareableURLShareTypePassword
+	// Emails on the allowed users, verified by a one-time login link.
+	ShareableURLShareTypeAllowedUsers
+	// Only the members of the project.
+	ShareableURLShareTypeAllProjectUsers
 )
 
 const (
@@ -34,7 +44,10 @@ const (
 )
 
 var ValidShareTypes = map[int]bool{
-	ShareableURLShareTypePublic: true,
+	ShareableURLShareTypePublic:          true,
+	ShareableURLShareTypePassword:        true,
+	ShareableURLShareTypeAllowedUsers:    true,
+	ShareableURLShareTypeAllProjectUsers: true,
 }
 
 var ValidShareEntityTypes = map[int]bool{
@@ -43,3 +56,180 @@ var ValidShareEntityTypes = map[int]bool{
 	ShareableURLEntityTypeDashboard: true,
 	ShareableURLEntityTypeSixSignal: true,
 }
+
+const (
+	ShareAccessTokenHeader = "X-Share-Access-Token"
+
+	ShareLoginLinkExpiryInSecs   = 15 * 60
+	ShareAccessTokenExpiryInSecs = 7 * 24 * 60 * 60
+	ShareMinPasswordLength       = 8
+	ShareMaxAllowedUsers         = 100
+
+	shareLoginTokenCacheKeyPrefix  = "share:login"
+	shareAccessTokenCacheKeyPrefix = "share:access"
+)
+
+// ShareAccess is the access of a viewer, who is not a member of the project,
+// to a restricted share. Stored against the login and access tokens.
+type ShareAccess struct {
+	ShareID string `json:"sid"`
+	Email   string `json:"em"`
+	// Issued time in milliseconds.
+	IssuedAt int64 `json:"iat"`
+}
+
+// GetAllowedUsersAsString normalises the emails and returns them as a comma separated string.
+func GetAllowedUsersAsString(emails []string) (string, string) {
+	allowedUsers := make([]string, 0, len(emails))
+	seen := make(map[string]bool)
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" || seen[email] {
+			continue
+		}
+		if !U.IsEmail(email) {
+			return "", "Invalid email on allowed users."
+		}
+		seen[email] = true
+		allowedUsers = append(allowedUsers, email)
+	}
+
+	if len(allowedUsers) > ShareMaxAllowedUsers {
+		return "", "Too many allowed users."
+	}
+	return strings.Join(allowedUsers, ","), ""
+}
+
+func (share *ShareableURL) GetAllowedUsers() []string {
+	if share.AllowedUsers == "" {
+		return []string{}
+	}
+	return strings.Split(share.AllowedUsers, ",")
+}
+
+func (share *ShareableURL) IsAllowedUser(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+	for _, allowedUser := range share.GetAllowedUsers() {
+		if allowedUser == email {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAccessibleWithShareAccess checks the access issued for a restricted share. Access
+// issued before the last update of the share, i.e change of password or allowed users, is not valid.
+func (share *ShareableURL) IsAccessibleWithShareAccess(access *ShareAccess) bool {
+	if access == nil || access.ShareID != share.ID || access.IssuedAt < share.UpdatedAt.UnixMilli() {
+		return false
+	}
+
+	switch share.ShareType {
+	case ShareableURLShareTypePassword:
+		return true
+	case ShareableURLShareTypeAllowedUsers:
+		return share.IsAllowedUser(access.Email)
+	default:
+		return false
+	}
+}
+
+func getShareLoginTokenCacheKey(projectID int64, token string) (*cache.Key, error) {
+	return cache.NewKey(projectID, shareLoginTokenCacheKeyPrefix, token)
+}
+
+func getShareAccessTokenCacheKey(projectID int64, token string) (*cache.Key, error) {
+	return cache.NewKey(projectID, shareAccessTokenCacheKeyPrefix, token)
+}
+
+func setShareAccessOnCache(key *cache.Key, access *ShareAccess, expiryInSecs int64) error {
+	accessJSON, err := json.Marshal(access)
+	if err != nil {
+		return err
+	}
+	return cacheRedis.SetPersistent(key, string(accessJSON), float64(expiryInSecs))
+}
+
+func getShareAccessFromCache(key *cache.Key) (*ShareAccess, error) {
+	accessJSON, exists, err := cacheRedis.GetIfExistsPersistent(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var access ShareAccess
+	if err := json.Unmarshal([]byte(accessJSON), &access); err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// CreateShareLoginToken creates the token for the one-time login link of the allowed user.
+func CreateShareLoginToken(share *ShareableURL, email string) (string, error) {
+	token := U.GetUUID()
+	key, err := getShareLoginTokenCacheKey(share.ProjectID, token)
+	if err != nil {
+		return "", err
+	}
+
+	access := &ShareAccess{ShareID: share.ID, Email: strings.ToLower(strings.TrimSpace(email))}
+	if err := setShareAccessOnCache(key, access, ShareLoginLinkExpiryInSecs); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumeShareLoginToken returns the access of the login token and deletes it,
+// so that the login link can be used only once.
+func ConsumeShareLoginToken(projectID int64, token string) (*ShareAccess, error) {
+	key, err := getShareLoginTokenCacheKey(projectID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := getShareAccessFromCache(key)
+	if err != nil || access == nil {
+		return nil, err
+	}
+	// Only the request which deletes the token gets the access, on concurrent use of the link.
+	deleted, err := cacheRedis.DelWithCountPersistent(key)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, nil
+	}
+	return access, nil
+}
+
+// CreateShareAccessToken creates the token, used by the viewer on the requests to the
+// restricted share, valid till the share expires or for a week, whichever is earlier.
+func CreateShareAccessToken(share *ShareableURL, email string) (string, int64, error) {
+	token := U.GetUUID()
+	key, err := getShareAccessTokenCacheKey(share.ProjectID, token)
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := U.TimeNowUnix()
+	expiresAt := now + ShareAccessTokenExpiryInSecs
+	if share.ExpiresAt > 0 && share.ExpiresAt < expiresAt {
+		expiresAt = share.ExpiresAt
+	}
+
+	access := &ShareAccess{ShareID: share.ID, Email: email, IssuedAt: time.Now().UnixMilli()}
+	if err := setShareAccessOnCache(key, access, expiresAt-now); err != nil {
+		return "", 0, err
+	}
+	return token, expiresAt, nil
+}
+
+func GetShareAccessWithAccessToken(projectID int64, token string) (*ShareAccess, error) {
+	key, err := getShareAccessTokenCacheKey(projectID, token)
+	if err != nil {
+		return nil, err
+	}
+	return getShareAccessFromCache(key)
+}
@@ -149,6 +149,7 @@ var SourceGroupUser = map[int]string{
 	UserSourceLinkedinCompany: U.GROUP_NAME_LINKEDIN_COMPANY,
 	UserSourceDomains:         U.GROUP_NAME_DOMAINS,
 	UserSourceG2:              U.GROUP_NAME_G2,
+	UserSourceSegmentAccount:  U.GROUP_NAME_SEGMENT_ACCOUNT,
 }
 
 // source name to hostname
@@ -158,6 +159,7 @@ var HostNameGroup = map[string]string{
 	U.GROUP_NAME_SIX_SIGNAL:         U.SIX_SIGNAL_DOMAIN,
 	U.GROUP_NAME_LINKEDIN_COMPANY:   U.LI_DOMAIN,
 	U.GROUP_NAME_G2:                 U.G2_DOMAIN,
+	U.GROUP_NAME_SEGMENT_ACCOUNT:    U.SEGMENT_ACCOUNT_DOMAIN,
 }
 
 // source name to company name
@@ -167,6 +169,7 @@ var AccountNames = map[string]string{
 	U.GROUP_NAME_SIX_SIGNAL:         U.SIX_SIGNAL_NAME,
 	U.GROUP_NAME_LINKEDIN_COMPANY:   U.LI_LOCALIZED_NAME,
 	U.GROUP_NAME_G2:                 U.G2_NAME,
+	U.GROUP_NAME_SEGMENT_ACCOUNT:    U.SEGMENT_ACCOUNT_NAME,
 }
 
 // host and company name list
@@ -396,6 +399,7 @@ var GROUP_TO_COMPANY_NAME_MAP = map[string]string{
 	U.GROUP_NAME_SIX_SIGNAL:         U.SIX_SIGNAL_DOMAIN,
 	U.GROUP_NAME_LINKEDIN_COMPANY:   U.LI_LOCALIZED_NAME,
 	U.GROUP_NAME_G2:                 U.G2_NAME,
+	U.GROUP_NAME_SEGMENT_ACCOUNT:    U.SEGMENT_ACCOUNT_NAME,
 }
 
 type TimelinesConfig struct {
@@ -452,6 +456,7 @@ var GroupPropertyPrefixList = []string{
 	U.GROUP_NAME_SIX_SIGNAL,
 	U.LI_PROPERTIES_PREFIX,
 	U.GROUP_NAME_G2,
+	U.SEGMENT_ACCOUNT_PROPERTIES_PREFIX,
 	U.GROUP_NAME_HUBSPOT_DEAL,
 	U.GROUP_NAME_SALESFORCE_OPPORTUNITY,
 }
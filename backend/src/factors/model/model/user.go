@@ -152,6 +152,10 @@ const (
 	UserSourceLinkedinCompanyString = "linkedin_company"
 	UserSourceG2                    = 11
 	UserSourceG2String              = "g2"
+	UserSourceSegmentAccount        = 12
+	UserSourceSegmentAccountString  = "segment_account"
+	UserSourceCustomCRM             = 13
+	UserSourceCustomCRMString       = U.CRM_SOURCE_NAME_CUSTOM
 )
 
 var UserSourceMap = map[string]int{
@@ -164,6 +168,8 @@ var UserSourceMap = map[string]int{
 	UserSourceDomainsString:         UserSourceDomains,
 	UserSourceLinkedinCompanyString: UserSourceLinkedinCompany,
 	UserSourceG2String:              UserSourceG2,
+	UserSourceSegmentAccountString:  UserSourceSegmentAccount,
+	UserSourceCustomCRMString:       UserSourceCustomCRM,
 }
 
 var UserSourceCRM = map[string]int{
@@ -171,6 +177,7 @@ var UserSourceCRM = map[string]int{
 	UserSourceSalesforceString: 3,
 	UserSourceMarketo:          6,
 	UserSourceLeadSquared:      7,
+	UserSourceCustomCRMString:  UserSourceCustomCRM,
 }
 
 var GroupUserSource = map[string]int{
@@ -182,6 +189,7 @@ var GroupUserSource = map[string]int{
 	U.GROUP_NAME_DOMAINS:                UserSourceDomains,
 	U.GROUP_NAME_LINKEDIN_COMPANY:       UserSourceLinkedinCompany,
 	U.GROUP_NAME_G2:                     UserSourceG2,
+	U.GROUP_NAME_SEGMENT_ACCOUNT:        UserSourceSegmentAccount,
 }
 
 const USERS = "users"
@@ -940,6 +948,7 @@ var (
 		SmartCRMEventSourceSalesforce: true,
 		U.CRM_SOURCE_NAME_MARKETO:     true,
 		UserSourceLeadSquared:         true,
+		UserSourceCustomCRMString:     true,
 	}
 
 	/*
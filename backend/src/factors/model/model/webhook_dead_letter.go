@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
+)
+
+// WebhookDeadLetter is the record kept for a webhook delivery which failed on all attempts.
+type WebhookDeadLetter struct {
+	ProjectID      int64           `gorm:"column:project_id; primary_key:true" json:"project_id"`
+	DeliveryID     string          `gorm:"column:delivery_id; primary_key:true" json:"delivery_id"`
+	Url            string          `gorm:"column:url" json:"url"`
+	Payload        *postgres.Jsonb `gorm:"column:payload" json:"payload"`
+	Attempts       int             `gorm:"column:attempts" json:"attempts"`
+	LastStatusCode int             `gorm:"column:last_status_code" json:"last_status_code"`
+	LastError      string          `gorm:"column:last_error" json:"last_error"`
+	FailedAt       time.Time       `gorm:"column:failed_at" json:"failed_at"`
+	CreatedAt      time.Time       `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+}
@@ -13,6 +13,7 @@ import (
 
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -449,6 +450,107 @@ func (store *MemSQL) GetPerAccountScore(projectId int64, timestamp string, userI
 	return result, weights, engagementLevel, nil
 }
 
+// GetAccountScoreExplanation returns the score of the account on the day, as stored on the
+// aggregated counts of the day, with the contribution of each weight rule and event, the trend
+// and the change since the previous day.
+func (store *MemSQL) GetAccountScoreExplanation(projectId int64, timestamp string, userId string,
+	numDaysToTrend int) (*model.AccScoreExplanation, int) {
+
+	logFields := log.Fields{
+		"project_id": projectId,
+		"id":         userId,
+		"timestamp":  timestamp,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if projectId == 0 || userId == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	weights, errStatus := store.GetWeightsByProject(projectId)
+	if errStatus != http.StatusFound {
+		return nil, http.StatusNotFound
+	}
+
+	var rtNull sql.NullString
+	db := C.GetServices().Db
+	stmt := "select event_aggregate from users where id=? and project_id=?"
+	if err := db.Raw(stmt, userId, projectId).Row().Scan(&rtNull); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusNotFound
+		}
+		logCtx.WithError(err).Error("Unable to read user counts data from DB")
+		return nil, http.StatusInternalServerError
+	}
+	if !rtNull.Valid {
+		return nil, http.StatusNotFound
+	}
+
+	countsMapDays := make(map[string]model.LatestScore)
+	if err := json.Unmarshal([]byte(rtNull.String), &countsMapDays); err != nil {
+		logCtx.WithError(err).Error("Failed to unmarshall json counts for users per day")
+		return nil, http.StatusInternalServerError
+	}
+
+	// same aggregated counts and decay as the score of the account on the day.
+	countsOnDay, exists := countsMapDays[timestamp]
+	if !exists {
+		return nil, http.StatusNotFound
+	}
+	ruleContributions, score := getAccScoreContributionsOnDay(projectId, weights, countsOnDay)
+
+	currentDate := U.GetDateFromString(timestamp)
+	previousContributions := make([]model.AccScoreContribution, 0)
+	previousScore := float64(0)
+	previousDay := U.GetDateOnlyFromTimestamp(time.Unix(currentDate, 0).AddDate(0, 0, -1).Unix())
+	if countsOnPreviousDay, exists := countsMapDays[previousDay]; exists {
+		previousContributions, previousScore = getAccScoreContributionsOnDay(projectId, weights, countsOnPreviousDay)
+	}
+
+	if numDaysToTrend > model.MAX_NUM_TREND_DAYS {
+		numDaysToTrend = model.MAX_NUM_TREND_DAYS
+	}
+	prevDateTotrend := time.Unix(currentDate, 0).AddDate(0, 0, -1*numDaysToTrend).Unix()
+	_, scoreOnDays, _, err := CalculatescoresPerAccount(projectId, weights, currentDate, prevDateTotrend, countsMapDays)
+	if err != nil {
+		logCtx.WithError(err).Error("Unable to compute trend of account score")
+		return nil, http.StatusInternalServerError
+	}
+
+	// same buckets as the score of the account, for the engagement on both the days.
+	ev := countsMapDays[model.LAST_EVENT]
+	buckets, err := store.GetEngagementBucketsOnProject(projectId, U.GetDateOnlyFromTimestamp(ev.Date))
+	if err != nil {
+		logCtx.WithError(err).Error("unable to get bucket ranges from DB")
+	}
+
+	explanation := model.AccScoreExplanation{
+		Id:                 userId,
+		Timestamp:          timestamp,
+		Score:              float32(score),
+		Engagement:         model.GetEngagement(score, buckets),
+		PreviousScore:      float32(previousScore),
+		PreviousEngagement: model.GetEngagement(previousScore, buckets),
+		RuleContributions:  ruleContributions,
+		EventContributions: model.GetAccScoreEventContributions(ruleContributions, score),
+		Changes:            model.GetAccScoreContributionChanges(ruleContributions, previousContributions),
+		Trend:              scoreOnDays,
+	}
+	return &explanation, http.StatusFound
+}
+
+// getAccScoreContributionsOnDay returns the contributions of the weight rules and the score
+// from the aggregated counts of a day, scored as on GetAccountsScore.
+func getAccScoreContributionsOnDay(projectId int64, weights *model.AccWeights,
+	countsOnDay model.LatestScore) ([]model.AccScoreContribution, float64) {
+
+	day := U.GetDateOnlyFromTimestamp(countsOnDay.Date)
+	contributions, _ := model.ComputeAccScoreContributions(weights, countsOnDay.EventsCount,
+		model.ComputeDecayValueForWeights(day, weights))
+	return contributions, ComputeScoreWithWeightsAndCounts(projectId, weights, countsOnDay.EventsCount, day)
+}
+
 func (store *MemSQL) GetEngagementBucketsOnProject(projectId int64, timestamp string) (model.BucketRanges, error) {
 	// get score of each account on current date
 
@@ -508,7 +610,7 @@ func computeTrendInPeriod(projectId int64, startDate int64, dates []string, coun
 		if !ok {
 			countsPerday.EventsCount = make(map[string]float64)
 		}
-		decay := model.ComputeDecayValueGivenStartEndTS(endDateUnix, startDate, weights.SaleWindow)
+		decay := model.ComputeDecayValueGivenStartEndTSForWeights(endDateUnix, startDate, weights)
 		accountScore := ComputeScoreWithWeightsAndCountsWithDecay(projectId, weights, countsPerday.EventsCount, decay)
 		score += accountScore
 
@@ -702,12 +804,11 @@ func ComputeAccountScore(weights model.AccWeights, eventsCount map[string]int64,
 	var accountScore float32
 	var eventsCountMap map[string]int64 = make(map[string]int64)
 	weightValue := weights.WeightConfig
-	saleWindow := weights.SaleWindow
-	decay_value := model.ComputeDecayValue(ts, saleWindow)
+	decay_value := model.ComputeDecayValueForWeights(ts, &weights)
 
 	for _, w := range weightValue {
 		if ew, ok := eventsCount[w.WeightId]; ok {
-			accountScore += float32(ew) * w.Weight_value
+			accountScore += float32(ew) * model.GetScoringWeightValue(&weights, w)
 			eventsCountMap[w.WeightId] = ew
 		}
 	}
@@ -1079,7 +1180,7 @@ func addTopEvents(result *model.PerUserScoreOnDay, le *model.LatestScore, weight
 func ComputeScoreWithWeightsAndCounts(projectId int64, weights *model.AccWeights, counts map[string]float64, day string) float64 {
 
 	var score float64
-	decay := model.ComputeDecayValue(day, weights.SaleWindow)
+	decay := model.ComputeDecayValueForWeights(day, weights)
 	accountScoref, _ := ComputeAccountScoreOnLastEvent(projectId, *weights, counts)
 	score = decay * accountScoref
 	return score
@@ -1107,9 +1208,156 @@ func ComputeAccountScoreOnLastEvent(project_id int64, weights model.AccWeights,
 	for _, w := range weightValue {
 		if !w.Is_deleted {
 			if ew, ok := eventsCount[w.WeightId]; ok {
-				accountScore += ew * float64(w.Weight_value)
+				accountScore += ew * float64(model.GetScoringWeightValue(&weights, w))
 			}
 		}
 	}
 	return accountScore, nil
 }
+
+type accDealOutcomeProperties struct {
+	groupName   string
+	isClosedKey string
+	isWonKey    string
+	closeDayKey string
+}
+
+var accDealOutcomeGroups = []accDealOutcomeProperties{
+	{model.GROUP_NAME_HUBSPOT_DEAL, "$hubspot_deal_hs_is_closed", "$hubspot_deal_hs_is_closed_won", "$hubspot_deal_closedate"},
+	{model.GROUP_NAME_SALESFORCE_OPPORTUNITY, "$salesforce_opportunity_isclosed", "$salesforce_opportunity_iswon", "$salesforce_opportunity_closedate"},
+}
+
+// GetAccountDealOutcomes returns the outcome of the latest closed deal of the accounts
+// (domain group users) from the CRM deals and opportunities associated to the domains.
+func (store *MemSQL) GetAccountDealOutcomes(projectId int64) ([]model.AccDealOutcome, int) {
+	logFields := log.Fields{
+		"project_id": projectId,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+	MAX_LIMIT := 100000
+
+	domainGroup, status := store.GetGroup(projectId, model.GROUP_NAME_DOMAINS)
+	if status != http.StatusFound {
+		return nil, status
+	}
+
+	db := C.GetServices().Db
+	outcomesByAccount := make(map[string]model.AccDealOutcome)
+	for _, dealGroup := range accDealOutcomeGroups {
+		group, status := store.GetGroup(projectId, dealGroup.groupName)
+		if status != http.StatusFound {
+			continue
+		}
+
+		stmt := fmt.Sprintf("SELECT group_%d_user_id, JSON_EXTRACT_STRING(properties, ?), JSON_EXTRACT_STRING(properties, ?)"+
+			" FROM users WHERE project_id = ? AND is_group_user = 1 AND group_%d_id IS NOT NULL AND group_%d_user_id IS NOT NULL"+
+			" AND JSON_EXTRACT_STRING(properties, ?) = 'true' LIMIT %d",
+			domainGroup.ID, group.ID, domainGroup.ID, MAX_LIMIT)
+		rows, err := db.Raw(stmt, dealGroup.isWonKey, dealGroup.closeDayKey, projectId, dealGroup.isClosedKey).Rows()
+		if err != nil {
+			logCtx.WithError(err).WithField("group", dealGroup.groupName).Error("Failed to get closed deals of accounts.")
+			return nil, http.StatusInternalServerError
+		}
+
+		for rows.Next() {
+			var accountID string
+			var isWon, closeDay sql.NullString
+			if err := rows.Scan(&accountID, &isWon, &closeDay); err != nil {
+				logCtx.WithError(err).Error("Failed to scan closed deal of account.")
+				continue
+			}
+
+			outcome := model.AccDealOutcome{AccountID: accountID, Won: isWon.String == "true"}
+			if closedAt, err := strconv.ParseFloat(closeDay.String, 64); err == nil {
+				outcome.ClosedAt = U.CheckAndGetStandardTimestamp(int64(closedAt))
+			}
+			if existing, exists := outcomesByAccount[accountID]; !exists || existing.ClosedAt < outcome.ClosedAt {
+				outcomesByAccount[accountID] = outcome
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			logCtx.WithError(err).Error("Error while fetching closed deals of accounts.")
+			return nil, http.StatusInternalServerError
+		}
+	}
+
+	outcomes := make([]model.AccDealOutcome, 0, len(outcomesByAccount))
+	for _, outcome := range outcomesByAccount {
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, http.StatusFound
+}
+
+// CreateAccountDealSnapshots keeps the counts of the accounts on the close of their deals. Snapshot
+// taken first on the close of a deal is kept, as the counts of the later runs miss the older days.
+func (store *MemSQL) CreateAccountDealSnapshots(projectId int64, snapshots []model.AccDealSnapshot) int {
+	logFields := log.Fields{
+		"project_id": projectId,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+	db := C.GetServices().Db
+
+	for _, snapshot := range snapshots {
+		countsJson, err := json.Marshal(snapshot.Counts)
+		if err != nil {
+			logCtx.WithError(err).WithField("account_id", snapshot.AccountID).Error("Failed to marshal counts of deal snapshot.")
+			return http.StatusInternalServerError
+		}
+
+		transTime := gorm.NowFunc()
+		stmt := "INSERT IGNORE INTO `account_deal_snapshots` (`project_id`,`account_id`,`closed_at`,`won`,`counts`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)"
+		err = db.Exec(stmt, projectId, snapshot.AccountID, snapshot.ClosedAt, snapshot.Won, string(countsJson), transTime, transTime).Error
+		if err != nil {
+			logCtx.WithError(err).WithField("account_id", snapshot.AccountID).Error("Failed to create deal snapshot.")
+			return http.StatusInternalServerError
+		}
+	}
+	return http.StatusCreated
+}
+
+// GetAccountDealSnapshots returns the snapshots of the closed deals of the accounts.
+func (store *MemSQL) GetAccountDealSnapshots(projectId int64) ([]model.AccDealSnapshot, int) {
+	logFields := log.Fields{
+		"project_id": projectId,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+	MAX_LIMIT := 100000
+	db := C.GetServices().Db
+
+	stmt := fmt.Sprintf("SELECT account_id, won, closed_at, counts FROM account_deal_snapshots WHERE project_id = ? LIMIT %d", MAX_LIMIT)
+	rows, err := db.Raw(stmt, projectId).Rows()
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get deal snapshots.")
+		return nil, http.StatusInternalServerError
+	}
+	defer rows.Close()
+
+	snapshots := make([]model.AccDealSnapshot, 0)
+	for rows.Next() {
+		var snapshot model.AccDealSnapshot
+		var countsString string
+		if err := rows.Scan(&snapshot.AccountID, &snapshot.Won, &snapshot.ClosedAt, &countsString); err != nil {
+			logCtx.WithError(err).Error("Failed to scan deal snapshot.")
+			continue
+		}
+		if err := json.Unmarshal([]byte(countsString), &snapshot.Counts); err != nil {
+			logCtx.WithError(err).WithField("account_id", snapshot.AccountID).Error("Failed to unmarshal counts of deal snapshot.")
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		logCtx.WithError(err).Error("Error while fetching deal snapshots.")
+		return nil, http.StatusInternalServerError
+	}
+
+	if len(snapshots) == 0 {
+		return snapshots, http.StatusNotFound
+	}
+	return snapshots, http.StatusFound
+}
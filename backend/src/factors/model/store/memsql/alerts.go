@@ -416,6 +416,10 @@ func (store *MemSQL) validateAlertBody(projectID int64, alert model.Alert) (bool
 			return false, http.StatusBadRequest, "Invalid Operator for Alert"
 		}
 	}
+	// anomaly operators compare against the history, not against a single previous period.
+	if model.IsAnomalyAlertOperator(alertDescription.Operator) && alert.AlertType != model.ALERT_TYPE_SINGLE_RANGE {
+		return false, http.StatusBadRequest, "Invalid Alert Type for Operator"
+	}
 	if alert.AlertType == model.ALERT_TYPE_SINGLE_RANGE {
 		if !store.isValidDateRange(alertDescription.DateRange) {
 			return false, http.StatusBadRequest, "Invalid Date Range"
@@ -0,0 +1,408 @@
+package memsql
+
+import (
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+)
+
+func createAPIKeyAudit(db *gorm.DB, projectID int64, apiKeyID, action, agentUUID string) error {
+	audit := model.APIKeyAudit{
+		ID:        U.GetUUID(),
+		ProjectID: projectID,
+		APIKeyID:  apiKeyID,
+		Action:    action,
+		AgentUUID: agentUUID,
+		CreatedAt: U.TimeNowZ(),
+	}
+	return db.Create(&audit).Error
+}
+
+func newAPIKey(projectID int64, name string, scopes []string, expiresAt *time.Time,
+	agentUUID string) (*model.APIKey, string, error) {
+
+	scopesJsonb, err := model.ValidateAPIKeyScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, keyPrefix, keyHash, err := model.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := U.TimeNowZ()
+	apiKey := &model.APIKey{
+		ID:        U.GetUUID(),
+		ProjectID: projectID,
+		Name:      name,
+		KeyPrefix: keyPrefix,
+		KeyHash:   keyHash,
+		Scopes:    scopesJsonb,
+		ExpiresAt: expiresAt,
+		CreatedBy: agentUUID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return apiKey, key, nil
+}
+
+// CreateAPIKey creates the key and returns it with the key, which is not stored.
+func (store *MemSQL) CreateAPIKey(projectID int64, agentUUID, name string, scopes []string,
+	expiresAt *time.Time) (*model.APIKey, string, int, string) {
+
+	logFields := log.Fields{
+		"project_id": projectID,
+		"agent_uuid": agentUUID,
+		"name":       name,
+		"scopes":     scopes,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	name = strings.TrimSpace(name)
+	if projectID == 0 || name == "" {
+		return nil, "", http.StatusBadRequest, "Name is required."
+	}
+	if expiresAt != nil && !expiresAt.After(U.TimeNowZ()) {
+		return nil, "", http.StatusBadRequest, "Expiry should be in the future."
+	}
+
+	apiKeys, errCode := store.GetAPIKeys(projectID)
+	if errCode != http.StatusFound {
+		return nil, "", http.StatusInternalServerError, "Failed to get api keys."
+	}
+	activeAPIKeys := 0
+	for i := range apiKeys {
+		if model.ValidateAPIKeyForScope(&apiKeys[i], "", U.TimeNowZ()) == nil {
+			activeAPIKeys++
+		}
+	}
+	if activeAPIKeys >= model.MaxAPIKeysPerProject {
+		return nil, "", http.StatusBadRequest, "API keys limit exceeded."
+	}
+
+	apiKey, key, err := newAPIKey(projectID, name, scopes, expiresAt, agentUUID)
+	if err != nil {
+		return nil, "", http.StatusBadRequest, err.Error()
+	}
+
+	db := C.GetServices().Db
+	if err := db.Create(apiKey).Error; err != nil {
+		logCtx.WithError(err).Error("Failed to create api key.")
+		return nil, "", http.StatusInternalServerError, "Failed to create api key."
+	}
+	if err := createAPIKeyAudit(db, projectID, apiKey.ID, model.APIKeyAuditActionCreated, agentUUID); err != nil {
+		// audit failure should not fail the creation.
+		logCtx.WithError(err).Error("Failed to create api key audit.")
+	}
+
+	return apiKey, key, http.StatusCreated, ""
+}
+
+func (store *MemSQL) GetAPIKeys(projectID int64) ([]model.APIKey, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 {
+		return nil, http.StatusBadRequest
+	}
+
+	apiKeys := make([]model.APIKey, 0)
+	db := C.GetServices().Db
+	if err := db.Where("project_id = ?", projectID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get api keys.")
+		return nil, http.StatusInternalServerError
+	}
+
+	return apiKeys, http.StatusFound
+}
+
+func (store *MemSQL) GetAPIKey(projectID int64, id string) (*model.APIKey, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || id == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	var apiKey model.APIKey
+	db := C.GetServices().Db
+	if err := db.Where("project_id = ? AND id = ?", projectID, id).First(&apiKey).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+		log.WithFields(logFields).WithError(err).Error("Failed to get api key.")
+		return nil, http.StatusInternalServerError
+	}
+
+	return &apiKey, http.StatusFound
+}
+
+// GetAPIKeyByKey returns the api key by the key, irrespective of its state.
+func (store *MemSQL) GetAPIKeyByKey(key string) (*model.APIKey, int) {
+	key = strings.TrimSpace(key)
+	if !model.IsAPIKeyToken(key) {
+		return nil, http.StatusBadRequest
+	}
+	logFields := log.Fields{
+		"key_prefix": key[:len(model.APIKeyTokenPrefix)],
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	var apiKey model.APIKey
+	db := C.GetServices().Db
+	if err := db.Where("key_hash = ?", model.GetAPIKeyHash(key)).First(&apiKey).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+		log.WithFields(logFields).WithError(err).Error("Failed to get api key by key.")
+		return nil, http.StatusInternalServerError
+	}
+
+	return &apiKey, http.StatusFound
+}
+
+// RotateAPIKey creates a new key with the name and scopes of the key. The old key
+// expires after the grace period, to allow the callers to move to the new key.
+func (store *MemSQL) RotateAPIKey(projectID int64, id, agentUUID string,
+	gracePeriod time.Duration) (*model.APIKey, string, int, string) {
+
+	logFields := log.Fields{
+		"project_id":   projectID,
+		"id":           id,
+		"agent_uuid":   agentUUID,
+		"grace_period": gracePeriod,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if gracePeriod < 0 || gracePeriod > model.MaxAPIKeyRotationGracePeriod {
+		return nil, "", http.StatusBadRequest, "Invalid grace period."
+	}
+
+	oldAPIKey, errCode := store.GetAPIKey(projectID, id)
+	if errCode != http.StatusFound {
+		return nil, "", errCode, "Failed to get api key."
+	}
+	now := U.TimeNowZ()
+	if err := model.ValidateAPIKeyForScope(oldAPIKey, "", now); err != nil {
+		return nil, "", http.StatusBadRequest, "Only active api keys can be rotated."
+	}
+
+	scopes, err := model.GetAPIKeyScopes(oldAPIKey)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to decode scopes of api key.")
+		return nil, "", http.StatusInternalServerError, "Failed to rotate api key."
+	}
+	apiKey, key, err := newAPIKey(projectID, oldAPIKey.Name, scopes, oldAPIKey.ExpiresAt, agentUUID)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to generate api key on rotation.")
+		return nil, "", http.StatusInternalServerError, "Failed to rotate api key."
+	}
+	apiKey.RotatedFrom = &oldAPIKey.ID
+
+	oldKeyExpiresAt := now.Add(gracePeriod)
+	if oldAPIKey.ExpiresAt != nil && oldAPIKey.ExpiresAt.Before(oldKeyExpiresAt) {
+		oldKeyExpiresAt = *oldAPIKey.ExpiresAt
+	}
+
+	// new key is created first, to not leave the callers without an active key on failure.
+	db := C.GetServices().Db
+	if err := db.Create(apiKey).Error; err != nil {
+		logCtx.WithError(err).Error("Failed to create api key on rotation.")
+		return nil, "", http.StatusInternalServerError, "Failed to rotate api key."
+	}
+	err = db.Model(&model.APIKey{}).Where("project_id = ? AND id = ?", projectID, id).
+		Updates(map[string]interface{}{"expires_at": oldKeyExpiresAt, "updated_at": now}).Error
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to expire api key on rotation.")
+		return nil, "", http.StatusInternalServerError, "Failed to rotate api key."
+	}
+
+	if err := createAPIKeyAudit(db, projectID, oldAPIKey.ID, model.APIKeyAuditActionRotated, agentUUID); err != nil {
+		logCtx.WithError(err).Error("Failed to create api key audit.")
+	}
+	if err := createAPIKeyAudit(db, projectID, apiKey.ID, model.APIKeyAuditActionCreated, agentUUID); err != nil {
+		logCtx.WithError(err).Error("Failed to create api key audit.")
+	}
+
+	return apiKey, key, http.StatusCreated, ""
+}
+
+// RevokeAPIKey revokes the key immediately.
+func (store *MemSQL) RevokeAPIKey(projectID int64, id, agentUUID string) (int, string) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+		"agent_uuid": agentUUID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	apiKey, errCode := store.GetAPIKey(projectID, id)
+	if errCode != http.StatusFound {
+		return errCode, "Failed to get api key."
+	}
+	if apiKey.RevokedAt != nil {
+		return http.StatusNotModified, "API key is revoked already."
+	}
+
+	now := U.TimeNowZ()
+	db := C.GetServices().Db
+	err := db.Model(&model.APIKey{}).Where("project_id = ? AND id = ?", projectID, id).
+		Updates(map[string]interface{}{"revoked_at": now, "updated_at": now}).Error
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to revoke api key.")
+		return http.StatusInternalServerError, "Failed to revoke api key."
+	}
+	if err := createAPIKeyAudit(db, projectID, id, model.APIKeyAuditActionRevoked, agentUUID); err != nil {
+		logCtx.WithError(err).Error("Failed to create api key audit.")
+	}
+
+	return http.StatusAccepted, ""
+}
+
+func (store *MemSQL) UpdateAPIKeyLastUsedAt(projectID int64, id string, lastUsedAt time.Time) int {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	db := C.GetServices().Db
+	err := db.Model(&model.APIKey{}).Where("project_id = ? AND id = ?", projectID, id).
+		UpdateColumn("last_used_at", lastUsedAt).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to update last used at of api key.")
+		return http.StatusInternalServerError
+	}
+	return http.StatusAccepted
+}
+
+func (store *MemSQL) GetAPIKeyAudits(projectID int64, id string) ([]model.APIKeyAudit, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || id == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	audits := make([]model.APIKeyAudit, 0)
+	db := C.GetServices().Db
+	err := db.Where("project_id = ? AND api_key_id = ?", projectID, id).Order("created_at DESC").Find(&audits).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get api key audits.")
+		return nil, http.StatusInternalServerError
+	}
+	return audits, http.StatusFound
+}
+
+const (
+	insertAPIKeyUsagesStr = "INSERT INTO api_key_usages (project_id,api_key_id,method,route,status,timestamp,count,client_ip,created_at,updated_at) VALUES "
+	// Adds the count to the existing usage of the minute.
+	onDuplicateAPIKeyUsagesStr  = " ON DUPLICATE KEY UPDATE count = count + VALUES(count), client_ip = VALUES(client_ip), updated_at = VALUES(updated_at)"
+	apiKeyUsagesInsertBatchSize = 500
+)
+
+// CreateOrIncrementAPIKeyUsages adds the counts of the requests made with the api keys
+// to the usages of the minute, in batches.
+func (store *MemSQL) CreateOrIncrementAPIKeyUsages(usages []model.APIKeyUsage) int {
+	logFields := log.Fields{
+		"usages": len(usages),
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	for i := range usages {
+		if usages[i].ProjectID == 0 || usages[i].APIKeyID == "" || usages[i].Count <= 0 {
+			log.WithFields(logFields).WithField("usage", usages[i]).Error("Invalid api key usage.")
+			return http.StatusBadRequest
+		}
+	}
+
+	db := C.GetServices().Db
+	now := U.TimeNowZ()
+	for start := 0; start < len(usages); start += apiKeyUsagesInsertBatchSize {
+		end := start + apiKeyUsagesInsertBatchSize
+		if end > len(usages) {
+			end = len(usages)
+		}
+
+		insertValuesStatement := make([]string, 0, end-start)
+		insertValues := make([]interface{}, 0, (end-start)*10)
+		for _, usage := range usages[start:end] {
+			insertValuesStatement = append(insertValuesStatement, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			insertValues = append(insertValues, usage.ProjectID, usage.APIKeyID, usage.Method, usage.Route,
+				usage.Status, usage.Timestamp, usage.Count, usage.ClientIP, now, now)
+		}
+
+		insertStatement := insertAPIKeyUsagesStr + joinWithComma(insertValuesStatement...) + onDuplicateAPIKeyUsagesStr
+		if err := db.Exec(insertStatement, insertValues...).Error; err != nil {
+			log.WithFields(logFields).WithError(err).Error("Failed to create api key usages.")
+			return http.StatusInternalServerError
+		}
+	}
+	return http.StatusCreated
+}
+
+// DeleteAPIKeyUsagesOlderThanGivenDays deletes the usages of all the api keys older than the expiry.
+func (store *MemSQL) DeleteAPIKeyUsagesOlderThanGivenDays(expiry int) (int, error) {
+	logFields := log.Fields{
+		"expiry": expiry,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if expiry <= 0 {
+		return http.StatusBadRequest, nil
+	}
+
+	db := C.GetServices().Db
+	err := db.Where("timestamp < ?", U.TimeNowZ().AddDate(0, 0, -expiry).Unix()).
+		Delete(&model.APIKeyUsage{}).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to delete api key usages older than given days.")
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// GetAPIKeyUsages returns the latest usages of the api key, by the minute.
+func (store *MemSQL) GetAPIKeyUsages(projectID int64, id string, limit int) ([]model.APIKeyUsage, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+		"limit":      limit,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || id == "" {
+		return nil, http.StatusBadRequest
+	}
+	if limit <= 0 || limit > model.MaxAPIKeyUsagesLimit {
+		limit = model.MaxAPIKeyUsagesLimit
+	}
+
+	usages := make([]model.APIKeyUsage, 0)
+	db := C.GetServices().Db
+	err := db.Where("project_id = ? AND api_key_id = ?", projectID, id).
+		Order("timestamp DESC").Limit(limit).Find(&usages).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get api key usages.")
+		return nil, http.StatusInternalServerError
+	}
+	return usages, http.StatusFound
+}
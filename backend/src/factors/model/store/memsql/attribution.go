@@ -7,6 +7,7 @@ import (
 	U "factors/util"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -65,6 +66,10 @@ func (store *MemSQL) ExecuteAttributionQueryV0(projectID int64, queryOriginal *m
 		return nil, errors.New("can not get all page view level report for Tactic")
 	}
 
+	if model.IsAccountLevelAttribution(query) && query.AnalyzeType != model.AnalyzeTypeUsers {
+		return nil, errors.New("account level attribution is supported only for users analyze type")
+	}
+
 	// for existing queries and backward support
 	if query.QueryType == "" {
 		query.QueryType = model.AttributionQueryTypeConversionBased
@@ -123,30 +128,63 @@ func (store *MemSQL) ExecuteAttributionQueryV0(projectID int64, queryOriginal *m
 		conversionTo = model.LookbackAdjustedTo(query.To, query.LookbackDays)
 	}
 
-	coalUserIdConversionTimestamp, userInfo, kpiData, kpiHeaders, kpiAggFunctionType, usersIDsToAttribute, err3 := store.PullConvertedUsers(projectID, query, conversionFrom, conversionTo, eventNameToIDList,
-		debugQueryKey, enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, logCtx)
+	var coalUserIdConversionTimestamp map[string]int64
+	var userInfo []model.UserEventInfo
+	var kpiHeaders, kpiAggFunctionType []string
+	var userData map[string]map[string]model.UserSessionData
+	var accountUsers map[string]model.AttributionAccountUser
+	if C.IsAllowedAttributionDayPartials(projectID) && model.IsAttributionDayPartialsSupported(query) {
+		// converted users and their sessions are pulled per day, only the days not cached are computed.
+		kpiData = make(map[string]model.KPIInfo)
+		coalUserIdConversionTimestamp, userInfo, usersIDsToAttribute, userData, err = store.PullConvertedUsersAndSessionsByDay(projectID, query,
+			sessionEventNameID, eventNameToIDList, marketingReports, contentGroupNamesList, logCtx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err3 error
+		coalUserIdConversionTimestamp, userInfo, kpiData, kpiHeaders, kpiAggFunctionType, usersIDsToAttribute, err3 = store.PullConvertedUsers(projectID, query, conversionFrom, conversionTo, eventNameToIDList,
+			debugQueryKey, enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, logCtx)
 
-	if C.GetAttributionDebug() == 1 {
-		log.WithFields(log.Fields{"KPIAttribution": "Debug",
-			"kpiData":                       kpiData,
-			"coalUserIdConversionTimestamp": coalUserIdConversionTimestamp,
-			"usersIDsToAttribute":           usersIDsToAttribute}).Info("Attributable users list - ConvertedUsers")
-	}
+		if C.GetAttributionDebug() == 1 {
+			log.WithFields(log.Fields{"KPIAttribution": "Debug",
+				"kpiData":                       kpiData,
+				"coalUserIdConversionTimestamp": coalUserIdConversionTimestamp,
+				"usersIDsToAttribute":           usersIDsToAttribute}).Info("Attributable users list - ConvertedUsers")
+		}
 
-	if err3 != nil {
-		return nil, err3
-	}
+		if err3 != nil {
+			return nil, err3
+		}
 
-	var userData map[string]map[string]model.UserSessionData
-	var err4 error
-	if query.AttributionKey == model.AttributionKeyAllPageView {
-		userData, err4 = store.PullPagesOfConvertedUsers(projectID, query, sessionEventNameID, usersIDsToAttribute, marketingReports, contentGroupNamesList, logCtx)
-	} else {
-		userData, err4 = store.PullSessionsOfConvertedUsers(projectID, query, sessionEventNameID, usersIDsToAttribute, marketingReports, contentGroupNamesList, logCtx)
-	}
+		// on account level, touch points of all the users of the converted accounts are attributed.
+		if model.IsAccountLevelAttribution(query) {
+			accountUsers, err = store.GetAccountUsersForAttribution(projectID, model.GetAttributionAccountGroupName(query),
+				query.RoleProperty, usersIDsToAttribute, *logCtx)
+			if err != nil {
+				return nil, err
+			}
+			convertedUserIDs := make(map[string]bool)
+			for _, userID := range usersIDsToAttribute {
+				convertedUserIDs[userID] = true
+			}
+			for userID := range accountUsers {
+				if !convertedUserIDs[userID] {
+					usersIDsToAttribute = append(usersIDsToAttribute, userID)
+				}
+			}
+		}
 
-	if err4 != nil {
-		return nil, err4
+		var err4 error
+		if query.AttributionKey == model.AttributionKeyAllPageView {
+			userData, err4 = store.PullPagesOfConvertedUsers(projectID, query, sessionEventNameID, usersIDsToAttribute, marketingReports, contentGroupNamesList, logCtx)
+		} else {
+			userData, err4 = store.PullSessionsOfConvertedUsers(projectID, query, sessionEventNameID, usersIDsToAttribute, marketingReports, contentGroupNamesList, logCtx)
+		}
+
+		if err4 != nil {
+			return nil, err4
+		}
 	}
 
 	if query.AnalyzeType == model.AnalyzeTypeUserKPI {
@@ -169,6 +207,22 @@ func (store *MemSQL) ExecuteAttributionQueryV0(projectID int64, queryOriginal *m
 
 	userData, _ = model.FilterNoneKeyForKeywordReport(userData, query.AttributionKey)
 
+	if model.IsAccountLevelAttribution(query) {
+		coalUserIDToAccount, coalUserIDToRole := model.GetCoalUserIDToAccountMaps(accountUsers)
+		userData, query.AccountKeyRoleWeights = model.RollUpSessionsToAccounts(userData, coalUserIDToAccount,
+			coalUserIDToRole, query.RoleWeights)
+		userInfo, coalUserIdConversionTimestamp = model.RollUpConversionsToAccounts(userInfo,
+			coalUserIdConversionTimestamp, coalUserIDToAccount)
+	}
+
+	if model.IsDataDrivenAttribution(query.AttributionMethodology, query.AttributionMethodologyCompare) {
+		query.NonConvertedPaths, err = store.PullNonConvertedPaths(projectID, query, sessionEventNameID,
+			userData, marketingReports, contentGroupNamesList, logCtx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if C.GetAttributionDebug() == 1 && query.AttributionKey == model.AttributionKeyKeyword {
 		log.WithFields(log.Fields{"Attribution": "Debug",
 			"Method":   "ExecuteAttributionQueryV0",
@@ -192,6 +246,12 @@ func (store *MemSQL) ExecuteAttributionQueryV0(projectID int64, queryOriginal *m
 
 	result := ProcessAttributionDataToResult(projectID, query, attributionData, isCompare, queryStartTime, marketingReports, kpiData, kpiHeaders, kpiAggFunctionType, logCtx)
 
+	if query.AnalyzeType == model.AnalyzeTypeUsers && query.AttributionMethodologyCompare != "" &&
+		query.MethodologyComparisonDetails {
+		result.Meta.MetaMetrics = append(result.Meta.MetaMetrics,
+			model.GetMethodologyComparisonMeta(query, attributionData)...)
+	}
+
 	result.Meta.Currency = ""
 	if projectSetting.IntAdwordsCustomerAccountId != nil && *projectSetting.IntAdwordsCustomerAccountId != "" {
 		currency, _ := store.GetAdwordsCurrency(projectID, *projectSetting.IntAdwordsCustomerAccountId, query.From, query.To, *logCtx)
@@ -207,6 +267,169 @@ func (store *MemSQL) ExecuteAttributionQueryV0(projectID int64, queryOriginal *m
 	return result, nil
 }
 
+// getSessionUserIDsForPeriod returns the users with a session on the period, as user_id -> coal_user_id,
+// for a sample of at most coalUserIDsLimit coal_user_ids. The sample is taken on the query, ordered by
+// the hash of the coal_user_id, for it to be bounded and the same across the runs. All the user ids of
+// a sampled coal_user_id are returned.
+func (store *MemSQL) getSessionUserIDsForPeriod(projectID int64, sessionEventNameID string, from, to int64,
+	coalUserIDsLimit int, logCtx log.Entry) (map[string]string, error) {
+
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logCtx.Data)
+
+	querySessionUsers := "WITH session_users AS (" +
+		" SELECT events_users.user_id, COALESCE(users.customer_user_id, users.id) AS coal_user_id" +
+		" FROM (SELECT DISTINCT user_id FROM events WHERE project_id=? AND event_name_id=? AND timestamp BETWEEN ? AND ?) AS events_users" +
+		" JOIN users ON users.project_id=? AND users.id=events_users.user_id)," +
+		" sampled_users AS (SELECT coal_user_id FROM session_users GROUP BY coal_user_id ORDER BY MD5(coal_user_id) LIMIT ?)" +
+		" SELECT session_users.user_id, session_users.coal_user_id FROM session_users" +
+		" JOIN sampled_users ON session_users.coal_user_id=sampled_users.coal_user_id"
+	rows, tx, err, reqID := store.ExecQueryWithContext(querySessionUsers,
+		[]interface{}{projectID, sessionEventNameID, from, to, projectID, coalUserIDsLimit})
+	if err != nil {
+		logCtx.WithError(err).Error("SQL Query failed for getSessionUserIDsForPeriod")
+		return nil, err
+	}
+	defer U.CloseReadQuery(rows, tx)
+
+	startReadTime := time.Now()
+	userIDToCoalUserID := make(map[string]string)
+	for rows.Next() {
+		var userID, coalUserID string
+		if err = rows.Scan(&userID, &coalUserID); err != nil {
+			logCtx.WithError(err).Error("SQL Parse failed. Ignoring row. Continuing")
+			continue
+		}
+		userIDToCoalUserID[userID] = coalUserID
+	}
+	if err = rows.Err(); err != nil {
+		logCtx.WithError(err).Error("Error in executing query in getSessionUserIDsForPeriod")
+		return nil, err
+	}
+	U.LogReadTimeWithQueryRequestID(startReadTime, reqID, &log.Fields{"project_id": projectID})
+	return userIDToCoalUserID, nil
+}
+
+// getNonConvertedUserIDs returns the user ids of a sample of the users with a session on the lookback
+// adjusted period, whose coal_user_id is not on the converted sessions. The sample is taken with room
+// for the converted users, which are dropped after. All the user ids of a sampled coal_user_id are returned.
+func (store *MemSQL) getNonConvertedUserIDs(projectID int64, sessionEventNameID string, from int64, to int64,
+	lookbackDays int, convertedSessions map[string]map[string]model.UserSessionData, logCtx log.Entry) ([]string, error) {
+
+	userIDToCoalUserID, err := store.getSessionUserIDsForPeriod(projectID, sessionEventNameID,
+		model.LookbackAdjustedFrom(from, lookbackDays), to,
+		model.DataDrivenNonConvertedUsersLimit+len(convertedSessions), logCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	coalUserIDToUserIDs := make(map[string][]string)
+	for userID, coalUserID := range userIDToCoalUserID {
+		if _, converted := convertedSessions[coalUserID]; converted {
+			continue
+		}
+		coalUserIDToUserIDs[coalUserID] = append(coalUserIDToUserIDs[coalUserID], userID)
+	}
+
+	coalUserIDs := make([]string, 0, len(coalUserIDToUserIDs))
+	for coalUserID := range coalUserIDToUserIDs {
+		coalUserIDs = append(coalUserIDs, coalUserID)
+	}
+	if len(coalUserIDs) > model.DataDrivenNonConvertedUsersLimit {
+		logCtx.WithFields(log.Fields{"non_converted_users": len(coalUserIDs),
+			"limit": model.DataDrivenNonConvertedUsersLimit}).Warn("Sampling the non converted users for data driven attribution.")
+		sort.Strings(coalUserIDs)
+		coalUserIDs = coalUserIDs[:model.DataDrivenNonConvertedUsersLimit]
+	}
+
+	nonConvertedUserIDs := make([]string, 0)
+	for _, coalUserID := range coalUserIDs {
+		nonConvertedUserIDs = append(nonConvertedUserIDs, coalUserIDToUserIDs[coalUserID]...)
+	}
+	return nonConvertedUserIDs, nil
+}
+
+// PullNonConvertedPaths returns the touch point paths of a sample of the non converted users,
+// for the data driven methodology to account the journeys which did not convert. On account level,
+// the sessions are rolled up to the accounts, as the converted sessions are.
+func (store *MemSQL) PullNonConvertedPaths(projectID int64, query *model.AttributionQuery, sessionEventNameID string,
+	convertedSessions map[string]map[string]model.UserSessionData, marketingReports *model.MarketingReports,
+	contentGroupNamesList []string, logCtx *log.Entry) ([][]string, error) {
+
+	nonConvertedUserIDs, err := store.getNonConvertedUserIDs(projectID, sessionEventNameID, query.From, query.To,
+		query.LookbackDays, convertedSessions, *logCtx)
+	if err != nil || len(nonConvertedUserIDs) == 0 {
+		return nil, err
+	}
+
+	var sessions map[string]map[string]model.UserSessionData
+	if query.AttributionKey == model.AttributionKeyAllPageView {
+		sessions, err = store.PullPagesOfConvertedUsers(projectID, query, sessionEventNameID, nonConvertedUserIDs,
+			marketingReports, contentGroupNamesList, logCtx)
+	} else {
+		sessions, err = store.PullSessionsOfConvertedUsers(projectID, query, sessionEventNameID, nonConvertedUserIDs,
+			marketingReports, contentGroupNamesList, logCtx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sessions, _ = model.FilterNoneKeyForKeywordReport(sessions, query.AttributionKey)
+
+	if model.IsAccountLevelAttribution(query) {
+		accountUsers, err := store.GetAccountUsersForAttribution(projectID, model.GetAttributionAccountGroupName(query),
+			query.RoleProperty, nonConvertedUserIDs, *logCtx)
+		if err != nil {
+			return nil, err
+		}
+		coalUserIDToAccount, coalUserIDToRole := model.GetCoalUserIDToAccountMaps(accountUsers)
+		sessions, _ = model.RollUpSessionsToAccounts(sessions, coalUserIDToAccount, coalUserIDToRole, query.RoleWeights)
+	}
+
+	return model.GetNonConvertedTouchPointPaths(query.QueryType, sessions, convertedSessions,
+		query.LookbackDays, query.From, query.To), nil
+}
+
+// PullNonConvertedPathsV1 returns the touch point paths of a sample of the non converted users,
+// for the data driven methodology to account the journeys which did not convert. On account level,
+// the sessions are rolled up to the accounts, as on PullNonConvertedPaths.
+func (store *MemSQL) PullNonConvertedPathsV1(projectID int64, query *model.AttributionQueryV1,
+	convertedSessions map[string]map[string]model.UserSessionData, marketingReports *model.MarketingReports,
+	logCtx *log.Entry) ([][]string, error) {
+
+	sessionEventNameID, _, err := store.getEventInformationV1(projectID, query, *logCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	nonConvertedUserIDs, err := store.getNonConvertedUserIDs(projectID, sessionEventNameID, query.From, query.To,
+		query.LookbackDays, convertedSessions, *logCtx)
+	if err != nil || len(nonConvertedUserIDs) == 0 {
+		return nil, err
+	}
+
+	sessions, err := store.GetUserSessions(projectID, query, logCtx, nonConvertedUserIDs, marketingReports)
+	if err != nil {
+		return nil, err
+	}
+	sessions, _ = model.FilterNoneKeyForKeywordReport(sessions, query.AttributionKey)
+
+	// On account level, the non converted sessions are rolled up to the accounts, for the paths to be
+	// of the same level as the converted paths. Accounts with a converted user are not non converted.
+	if model.IsAccountLevelAttributionV1(query) {
+		accountUsers, err := store.GetAccountUsersForAttribution(projectID, model.GetAttributionAccountGroupNameV1(query),
+			query.RoleProperty, nonConvertedUserIDs, *logCtx)
+		if err != nil {
+			return nil, err
+		}
+		coalUserIDToAccount, coalUserIDToRole := model.GetCoalUserIDToAccountMaps(accountUsers)
+		sessions, _ = model.RollUpSessionsToAccounts(sessions, coalUserIDToAccount, coalUserIDToRole, query.RoleWeights)
+		convertedSessions, _ = model.RollUpSessionsToAccounts(convertedSessions, coalUserIDToAccount,
+			coalUserIDToRole, query.RoleWeights)
+	}
+
+	return model.GetNonConvertedTouchPointPaths(query.QueryType, sessions, convertedSessions,
+		query.LookbackDays, query.From, query.To), nil
+}
+
 func (store *MemSQL) AppendOTPSessions(projectID int64, query *model.AttributionQuery,
 	sessions *map[string]map[string]model.UserSessionData, logCtx log.Entry) {
 
@@ -0,0 +1,83 @@
+package memsql
+
+import (
+	"errors"
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetAccountUsersForAttribution returns all the users of the accounts, the given users belong to,
+// as user_id -> account user. The group_user_id of the account group is used as account id and
+// the value of the role property as the buying committee role of the user.
+func (store *MemSQL) GetAccountUsersForAttribution(projectID int64, groupName string, roleProperty string,
+	userIDs []string, logCtx log.Entry) (map[string]model.AttributionAccountUser, error) {
+
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logCtx.Data)
+
+	accountUsers := make(map[string]model.AttributionAccountUser)
+	if len(userIDs) == 0 {
+		return accountUsers, nil
+	}
+
+	group, errCode := store.GetGroup(projectID, groupName)
+	if errCode != http.StatusFound || group == nil {
+		return nil, errors.New("failed to get the account group for attribution")
+	}
+
+	roleSelect := "''"
+	if roleProperty != "" {
+		roleSelect = "JSON_EXTRACT_STRING(properties, ?)"
+	}
+
+	for _, users := range U.GetStringListAsBatch(userIDs, model.UserBatchSize) {
+		queryAccountUsers := fmt.Sprintf("SELECT id, COALESCE(customer_user_id, id) AS coal_user_id, group_%d_user_id, %s"+" "+
+			"FROM users WHERE project_id = ? AND (is_group_user IS NULL OR is_group_user = false) AND group_%d_user_id IN"+" "+
+			"(SELECT group_%d_user_id FROM users WHERE project_id = ? AND group_%d_user_id IS NOT NULL AND id IN (%s))",
+			group.ID, roleSelect, group.ID, group.ID, group.ID, U.GetValuePlaceHolder(len(users)))
+
+		var params []interface{}
+		if roleProperty != "" {
+			params = append(params, roleProperty)
+		}
+		params = append(params, projectID, projectID)
+		params = append(params, U.GetInterfaceList(users)...)
+
+		rows, tx, err, reqID := store.ExecQueryWithContext(queryAccountUsers, params)
+		if err != nil {
+			logCtx.WithError(err).Error("SQL Query failed for GetAccountUsersForAttribution")
+			return nil, err
+		}
+		startReadTime := time.Now()
+		for rows.Next() {
+			var userID, coalUserID, accountID string
+			var role *string
+			if err = rows.Scan(&userID, &coalUserID, &accountID, &role); err != nil {
+				logCtx.WithError(err).Error("SQL Parse failed. Ignoring row. Continuing")
+				continue
+			}
+			accountUser := model.AttributionAccountUser{CoalUserID: coalUserID, AccountID: accountID}
+			if role != nil {
+				accountUser.Role = *role
+			}
+			accountUsers[userID] = accountUser
+		}
+		err = rows.Err()
+		U.CloseReadQuery(rows, tx)
+		if err != nil {
+			logCtx.WithError(err).Error("Error in executing query in GetAccountUsersForAttribution")
+			return nil, err
+		}
+		U.LogReadTimeWithQueryRequestID(startReadTime, reqID, &log.Fields{"project_id": projectID})
+	}
+
+	if C.GetAttributionDebug() == 1 {
+		logCtx.WithFields(log.Fields{"account_user_count": len(accountUsers)}).Info("GetAccountUsersForAttribution")
+	}
+	return accountUsers, nil
+}
@@ -0,0 +1,294 @@
+package memsql
+
+import (
+	"encoding/json"
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PullConvertedUsersAndSessionsByDay pulls the converted users and their sessions day by day. Partials of the
+// days computed earlier are read from the db, so only the recent days are computed on every run.
+func (store *MemSQL) PullConvertedUsersAndSessionsByDay(projectID int64, query *model.AttributionQuery,
+	sessionEventNameID string, eventNameToIDList map[string][]interface{}, marketingReports *model.MarketingReports,
+	contentGroupNamesList []string, logCtx *log.Entry) (map[string]int64, []model.UserEventInfo, []string,
+	map[string]map[string]model.UserSessionData, error) {
+
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logCtx.Data)
+
+	queryHash, err := model.GetAttributionDayPartialQueryHash(query)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	partials, err := store.getAttributionDayPartials(projectID, queryHash, query.From, query.To, query.Timezone,
+		marketingReports, logCtx, func(dayRange model.AttributionDayRange) (*model.AttributionDayPartial, error) {
+			return store.computeAttributionDayPartial(projectID, query, dayRange, sessionEventNameID,
+				eventNameToIDList, marketingReports, contentGroupNamesList, logCtx)
+		})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	model.SetAttributionDayPartialsWithinQueryPeriod(partials, query.QueryType, query.LookbackDays, query.From, query.To)
+	coalUserIdConversionTimestamp, usersToBeAttributed, usersIDsToAttribute, sessions := model.MergeAttributionDayPartials(partials)
+	return coalUserIdConversionTimestamp, usersToBeAttributed, usersIDsToAttribute, sessions, nil
+}
+
+// PullKPIDataAndSessionsByDayV1 pulls the kpi data and the sessions of its users day by day, for the V1 query.
+// Partials of the days computed earlier are read from the db, so only the recent days are computed on every run.
+func (store *MemSQL) PullKPIDataAndSessionsByDayV1(projectID int64, query *model.AttributionQueryV1,
+	debugQueryKey string, enableOptimisedFilterOnProfileQuery bool, enableOptimisedFilterOnEventUserQuery bool,
+	marketingReports *model.MarketingReports, logCtx *log.Entry) (map[string]model.KPIInfo, []string, []string,
+	[]string, map[string]map[string]model.UserSessionData, error) {
+
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logCtx.Data)
+
+	queryHash, err := model.GetAttributionDayPartialQueryHashV1(query)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	partials, err := store.getAttributionDayPartials(projectID, queryHash, query.From, query.To, query.Timezone,
+		marketingReports, logCtx, func(dayRange model.AttributionDayRange) (*model.AttributionDayPartial, error) {
+			return store.computeAttributionDayPartialV1(projectID, query, dayRange, debugQueryKey,
+				enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, marketingReports, logCtx)
+		})
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	model.SetAttributionDayPartialsWithinQueryPeriod(partials, query.QueryType, query.LookbackDays, query.From, query.To)
+	kpiData, kpiHeaders, kpiAggFunctionTypes, usersIDsToAttribute, sessions := model.MergeAttributionDayPartialsV1(partials)
+	return kpiData, kpiHeaders, kpiAggFunctionTypes, usersIDsToAttribute, sessions, nil
+}
+
+// getAttributionDayPartials returns the partials of all the days of the range. Cached partials computed with
+// different marketing reports or coalesced users are stale and computed again.
+func (store *MemSQL) getAttributionDayPartials(projectID int64, queryHash string, from, to int64, timezone string,
+	marketingReports *model.MarketingReports, logCtx *log.Entry,
+	computePartial func(dayRange model.AttributionDayRange) (*model.AttributionDayPartial, error)) ([]*model.AttributionDayPartial, error) {
+
+	dayRanges := model.GetAttributionDayRanges(from, to, timezone, U.TimeNowUnix())
+	partials := make([]*model.AttributionDayPartial, 0, len(dayRanges))
+	cacheHits, staleHits := 0, 0
+	for _, dayRange := range dayRanges {
+		if dayRange.Cacheable {
+			partial, err := store.getAttributionDayPartial(projectID, queryHash, dayRange.From)
+			if err != nil {
+				logCtx.WithError(err).Warn("Failed to get attribution day partial. Computing.")
+			}
+			if partial != nil {
+				marketingVersion, err := model.GetAttributionMarketingReportsVersion(marketingReports, partial.Sessions)
+				if err != nil {
+					return nil, err
+				}
+				usersVersion, err := store.getAttributionUsersVersion(projectID, partial.GetCoalUserIDs(), logCtx)
+				if err != nil {
+					return nil, err
+				}
+				if !partial.IsStale(marketingVersion, usersVersion) {
+					partials = append(partials, partial)
+					cacheHits++
+					continue
+				}
+				staleHits++
+			}
+		}
+
+		partial, err := computePartial(dayRange)
+		if err != nil {
+			return nil, err
+		}
+		partials = append(partials, partial)
+
+		if dayRange.Cacheable {
+			partial.MarketingVersion, err = model.GetAttributionMarketingReportsVersion(marketingReports, partial.Sessions)
+			if err != nil {
+				return nil, err
+			}
+			partial.UsersVersion, err = store.getAttributionUsersVersion(projectID, partial.GetCoalUserIDs(), logCtx)
+			if err != nil {
+				return nil, err
+			}
+			// failing to cache only makes the next run slower.
+			store.setAttributionDayPartial(projectID, queryHash, partial, logCtx)
+		}
+	}
+
+	if C.GetAttributionDebug() == 1 {
+		logCtx.WithFields(log.Fields{"days": len(dayRanges), "cache_hits": cacheHits, "stale_hits": staleHits}).
+			Info("Pulled attribution day partials")
+	}
+	return partials, nil
+}
+
+// getAttributionDayPartial returns nil if the partial of the day is not cached or has expired.
+func (store *MemSQL) getAttributionDayPartial(projectID int64, queryHash string, dayFrom int64) (*model.AttributionDayPartial, error) {
+	db := C.GetServices().Db
+	var results []model.AttributionDayPartialResult
+	err := db.Where("project_id = ? AND query_hash = ? AND day_from = ? AND updated_at >= ?", projectID, queryHash, dayFrom,
+		U.TimeNowZ().Add(-time.Duration(model.AttributionDayPartialExpiryInSecs)*time.Second)).
+		Limit(1).Find(&results).Error
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+
+	var partial model.AttributionDayPartial
+	if err := json.Unmarshal(results[0].Partial, &partial); err != nil {
+		return nil, err
+	}
+	return &partial, nil
+}
+
+// setAttributionDayPartial caches the partial of the day and deletes the expired partials of the project.
+// Partials larger than model.AttributionDayPartialMaxSizeInBytes are not cached.
+func (store *MemSQL) setAttributionDayPartial(projectID int64, queryHash string, partial *model.AttributionDayPartial,
+	logCtx *log.Entry) error {
+
+	partialJson, err := json.Marshal(partial)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to marshal attribution day partial.")
+		return err
+	}
+	if len(partialJson) > model.AttributionDayPartialMaxSizeInBytes {
+		logCtx.WithFields(log.Fields{"day_from": partial.From, "size": len(partialJson)}).
+			Warn("Attribution day partial exceeds the max size. Not caching.")
+		return nil
+	}
+
+	db := C.GetServices().Db
+	now := U.TimeNowZ()
+	err = db.Exec("REPLACE INTO attribution_day_partials (project_id, query_hash, day_from, partial, created_at, updated_at)"+" "+
+		"VALUES (?, ?, ?, ?, ?, ?)", projectID, queryHash, partial.From, partialJson, now, now).Error
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to set attribution day partial.")
+		return err
+	}
+
+	err = db.Where("project_id = ? AND updated_at < ?", projectID,
+		now.Add(-time.Duration(model.AttributionDayPartialExpiryInSecs)*time.Second)).
+		Delete(&model.AttributionDayPartialResult{}).Error
+	if err != nil {
+		logCtx.WithError(err).Warn("Failed to delete expired attribution day partials.")
+	}
+	return nil
+}
+
+// getAttributionUsersVersion returns the version of the coalesced users, which changes when
+// a user is identified with or merged into one of the given coalesced users.
+func (store *MemSQL) getAttributionUsersVersion(projectID int64, coalUserIDs []string, logCtx *log.Entry) (string, error) {
+	userIDToCoalUserID := make(map[string]string)
+	for _, coalUserIDsBatch := range U.GetStringListAsBatch(coalUserIDs, model.UserBatchSize) {
+		placeHolder := U.GetValuePlaceHolder(len(coalUserIDsBatch))
+		value := U.GetInterfaceList(coalUserIDsBatch)
+		stmnt := "SELECT id, COALESCE(customer_user_id, id) FROM users WHERE project_id = ?" + " " +
+			"AND (customer_user_id IN (" + placeHolder + ") OR id IN (" + placeHolder + "))"
+		params := []interface{}{projectID}
+		params = append(params, value...)
+		params = append(params, value...)
+
+		rows, tx, err, reqID := store.ExecQueryWithContext(stmnt, params)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to get users for attribution users version.")
+			return "", err
+		}
+
+		startReadTime := time.Now()
+		for rows.Next() {
+			var userID, coalUserID string
+			if err = rows.Scan(&userID, &coalUserID); err != nil {
+				logCtx.WithError(err).Error("Failed to scan users for attribution users version.")
+				U.CloseReadQuery(rows, tx)
+				return "", err
+			}
+			userIDToCoalUserID[userID] = coalUserID
+		}
+		err = rows.Err()
+		U.CloseReadQuery(rows, tx)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to read users for attribution users version.")
+			return "", err
+		}
+		U.LogReadTimeWithQueryRequestID(startReadTime, reqID, &log.Fields{"project_id": projectID})
+	}
+
+	return model.GetAttributionUsersVersion(userIDToCoalUserID)
+}
+
+func (store *MemSQL) computeAttributionDayPartial(projectID int64, query *model.AttributionQuery,
+	dayRange model.AttributionDayRange, sessionEventNameID string, eventNameToIDList map[string][]interface{},
+	marketingReports *model.MarketingReports, contentGroupNamesList []string,
+	logCtx *log.Entry) (*model.AttributionDayPartial, error) {
+
+	var dayQuery *model.AttributionQuery
+	U.DeepCopy(query, &dayQuery)
+	dayQuery.From = dayRange.From
+	dayQuery.To = dayRange.To
+
+	userIDToInfoConverted, usersToBeAttributed, coalUserIdConversionTimestamp, err := store.GetConvertedUsers(projectID,
+		dayRange.From, dayRange.To, dayQuery.ConversionEvent, dayQuery, eventNameToIDList, *logCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	usersIDsToAttribute := make([]string, 0, len(userIDToInfoConverted))
+	for id := range userIDToInfoConverted {
+		usersIDsToAttribute = append(usersIDsToAttribute, id)
+	}
+
+	var sessions map[string]map[string]model.UserSessionData
+	if query.AttributionKey == model.AttributionKeyAllPageView {
+		sessions, err = store.PullPagesOfConvertedUsers(projectID, dayQuery, sessionEventNameID, usersIDsToAttribute,
+			marketingReports, contentGroupNamesList, logCtx)
+	} else {
+		sessions, err = store.PullSessionsOfConvertedUsers(projectID, dayQuery, sessionEventNameID, usersIDsToAttribute,
+			marketingReports, contentGroupNamesList, logCtx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AttributionDayPartial{
+		From:                          dayRange.From,
+		To:                            dayRange.To,
+		CoalUserIdConversionTimestamp: coalUserIdConversionTimestamp,
+		UsersToBeAttributed:           usersToBeAttributed,
+		UserIDs:                       usersIDsToAttribute,
+		Sessions:                      sessions,
+	}, nil
+}
+
+func (store *MemSQL) computeAttributionDayPartialV1(projectID int64, query *model.AttributionQueryV1,
+	dayRange model.AttributionDayRange, debugQueryKey string, enableOptimisedFilterOnProfileQuery bool,
+	enableOptimisedFilterOnEventUserQuery bool, marketingReports *model.MarketingReports,
+	logCtx *log.Entry) (*model.AttributionDayPartial, error) {
+
+	var dayQuery *model.AttributionQueryV1
+	U.DeepCopy(query, &dayQuery)
+	dayQuery.From = dayRange.From
+	dayQuery.To = dayRange.To
+
+	_, _, kpiData, kpiHeaders, kpiAggFunctionTypes, usersIDsToAttribute, err := store.PullConvertedUsersV1(projectID,
+		dayQuery, debugQueryKey, enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, logCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := store.GetUserSessions(projectID, dayQuery, logCtx, usersIDsToAttribute, marketingReports)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AttributionDayPartial{
+		From:                dayRange.From,
+		To:                  dayRange.To,
+		Sessions:            sessions,
+		KPIData:             kpiData,
+		KPIHeaders:          kpiHeaders,
+		KPIAggFunctionTypes: kpiAggFunctionTypes,
+	}, nil
+}
@@ -895,6 +895,7 @@ func (store *MemSQL) runAttributionKPI(
 		query.QueryType,
 		query.AttributionMethodology,
 		sessions,
+		query.NonConvertedPaths,
 		kpiData,
 		query.LookbackDays, query.From, query.To, query.AttributionKey)
 	if err != nil {
@@ -931,12 +932,15 @@ func (store *MemSQL) runAttributionKPIV1(
 		query.QueryType,
 		query.AttributionMethodology,
 		sessions,
+		query.NonConvertedPaths,
 		kpiData,
 		query.LookbackDays, query.From, query.To, query.AttributionKey)
 	if err != nil {
 		return nil, err
 	}
 
+	model.ApplyBuyingCommitteeWeights(userConversionHit, query.KPIKeyRoleWeights)
+
 	// update attribution weight
 	updateSessionWT(sessionWT, kpiData)
 
@@ -978,6 +982,7 @@ func (store *MemSQL) RunAttributionForMethodologyComparisonKpi(query *model.Attr
 		query.QueryType,
 		query.AttributionMethodology,
 		sessions,
+		query.NonConvertedPaths,
 		kpiData,
 		query.LookbackDays, query.From, query.To, query.AttributionKey)
 	if err != nil {
@@ -993,6 +998,7 @@ func (store *MemSQL) RunAttributionForMethodologyComparisonKpi(query *model.Attr
 		query.QueryType,
 		query.AttributionMethodologyCompare,
 		sessions,
+		query.NonConvertedPaths,
 		kpiData,
 		query.LookbackDays, query.From, query.To, query.AttributionKey)
 	if err != nil {
@@ -1047,11 +1053,13 @@ func (store *MemSQL) RunAttributionForMethodologyComparisonKpiV1(query *model.At
 		query.QueryType,
 		query.AttributionMethodology,
 		sessions,
+		query.NonConvertedPaths,
 		kpiData,
 		query.LookbackDays, query.From, query.To, query.AttributionKey)
 	if err != nil {
 		return nil, err
 	}
+	model.ApplyBuyingCommitteeWeights(userConversionHit, query.KPIKeyRoleWeights)
 	// update attribution weight
 	updateSessionWT(sessionWT, kpiData)
 
@@ -1062,6 +1070,7 @@ func (store *MemSQL) RunAttributionForMethodologyComparisonKpiV1(query *model.At
 		query.QueryType,
 		query.AttributionMethodologyCompare,
 		sessions,
+		query.NonConvertedPaths,
 		kpiData,
 		query.LookbackDays, query.From, query.To, query.AttributionKey)
 	if err != nil {
@@ -1071,8 +1080,18 @@ func (store *MemSQL) RunAttributionForMethodologyComparisonKpiV1(query *model.At
 	if err != nil {
 		return nil, err
 	}
+	model.ApplyBuyingCommitteeWeights(userConversionCompareHit, query.KPIKeyRoleWeights)
 	attributionDataCompare := model.AddUpConversionEventCount(userConversionCompareHit, sessionWT)
 
+	if query.MethodologyComparisonDetails {
+		query.MethodologyComparisonHits = &model.MethodologyComparisonHits{
+			UserConversionHit:        userConversionHit,
+			UserConversionCompareHit: userConversionCompareHit,
+			Sessions:                 sessions,
+			ConversionTimestamp:      model.GetKPIConversionTimestamps(kpiData),
+		}
+	}
+
 	// Merge compare data into attributionData.
 	for key := range attributionData {
 		if _, exists := attributionDataCompare[key]; exists {
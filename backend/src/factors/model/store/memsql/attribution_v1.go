@@ -144,27 +144,55 @@ func (store *MemSQL) ExecuteAttributionQueryV1(projectID int64, queryOriginal *m
 
 	var usersIDsToAttribute []string
 	var kpiData map[string]model.KPIInfo
+	var kpiHeaders, kpiAggFunctionType []string
+	var userData map[string]map[string]model.UserSessionData
+	var accountUsers map[string]model.AttributionAccountUser
 
-	coalUserIdConversionTimestamp, userInfo, kpiData, kpiHeaders, kpiAggFunctionType, usersIDsToAttribute, err := store.PullConvertedUsersV1(projectID,
-		query, debugQueryKey, enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, logCtx)
+	if C.IsAllowedAttributionDayPartials(projectID) && model.IsAttributionDayPartialsSupportedV1(query) {
+		// kpi data and sessions are pulled per day, only the days not cached are computed.
+		kpiData, kpiHeaders, kpiAggFunctionType, usersIDsToAttribute, userData, err = store.PullKPIDataAndSessionsByDayV1(projectID,
+			query, debugQueryKey, enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, marketingReports, logCtx)
+		if err != nil {
+			log.Error("Failed to PullKPIDataAndSessionsByDayV1 -V1")
+			return nil, err
+		}
+	} else {
+		var coalUserIdConversionTimestamp map[string]int64
+		var userInfo []model.UserEventInfo
+		coalUserIdConversionTimestamp, userInfo, kpiData, kpiHeaders, kpiAggFunctionType, usersIDsToAttribute, err = store.PullConvertedUsersV1(projectID,
+			query, debugQueryKey, enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery, logCtx)
 
-	if C.GetAttributionDebug() == 1 {
-		log.WithFields(log.Fields{"KPIAttribution": "Debug",
-			"kpiData":                       kpiData,
-			"coalUserIdConversionTimestamp": coalUserIdConversionTimestamp,
-			"userInfo":                      userInfo,
-			"usersIDsToAttribute":           usersIDsToAttribute}).Warn("Attributable users list - ConvertedUsers")
-	}
-	if err != nil {
-		log.Error("Failed to PullConvertedUsersV1 -V1")
-		return nil, err
-	}
+		if C.GetAttributionDebug() == 1 {
+			log.WithFields(log.Fields{"KPIAttribution": "Debug",
+				"kpiData":                       kpiData,
+				"coalUserIdConversionTimestamp": coalUserIdConversionTimestamp,
+				"userInfo":                      userInfo,
+				"usersIDsToAttribute":           usersIDsToAttribute}).Warn("Attributable users list - ConvertedUsers")
+		}
+		if err != nil {
+			log.Error("Failed to PullConvertedUsersV1 -V1")
+			return nil, err
+		}
 
-	var userData map[string]map[string]model.UserSessionData
-	userData, err = store.GetUserSessions(projectID, query, logCtx, usersIDsToAttribute, marketingReports)
-	if err != nil {
-		log.Error("Failed to GetUserSessions -V1")
-		return nil, err
+		// on account level, touch points of all the users of the converted accounts are attributed.
+		if model.IsAccountLevelAttributionV1(query) {
+			accountUsers, err = store.GetAccountUsersForAttribution(projectID, model.GetAttributionAccountGroupNameV1(query),
+				query.RoleProperty, usersIDsToAttribute, *logCtx)
+			if err != nil {
+				return nil, err
+			}
+			model.RollUpKPIUsersToAccounts(kpiData, accountUsers)
+			for userID := range accountUsers {
+				usersIDsToAttribute = append(usersIDsToAttribute, userID)
+			}
+			usersIDsToAttribute = U.RemoveDuplicateStringInArray(usersIDsToAttribute)
+		}
+
+		userData, err = store.GetUserSessions(projectID, query, logCtx, usersIDsToAttribute, marketingReports)
+		if err != nil {
+			log.Error("Failed to GetUserSessions -V1")
+			return nil, err
+		}
 	}
 
 	// Pull Offline touch points for all the cases: "Tactic",  "Offer", "TacticOffer"
@@ -188,6 +216,18 @@ func (store *MemSQL) ExecuteAttributionQueryV1(projectID int64, queryOriginal *m
 			"sessions": userData}).Info("Attribution sessions after FilterNoneKeyForKeywordReport")
 	}
 
+	if model.IsAccountLevelAttributionV1(query) {
+		query.KPIKeyRoleWeights = model.GetKPIKeyRoleWeights(kpiData, userData, accountUsers, query.RoleWeights)
+	}
+
+	if model.IsDataDrivenAttribution(query.AttributionMethodology, query.AttributionMethodologyCompare) {
+		query.NonConvertedPaths, err = store.PullNonConvertedPathsV1(projectID, query, userData,
+			marketingReports, logCtx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Run Attribution core logic
 	attributionData, isCompare, err2 := store.GetAttributionDataV1(projectID, query, userData, marketingReports,
 		kpiData, kpiHeaders, kpiAggFunctionType, logCtx)
@@ -221,6 +261,11 @@ func (store *MemSQL) ExecuteAttributionQueryV1(projectID int64, queryOriginal *m
 	result := store.ProcessAttributionDataToResultV1(projectID, query, attributionData, isCompare, queryStartTime,
 		marketingReports, kpiData, kpiHeaders, kpiAggFunctionType, logCtx)
 
+	if query.AttributionMethodologyCompare != "" && query.MethodologyComparisonDetails {
+		result.Meta.MetaMetrics = append(result.Meta.MetaMetrics,
+			model.GetMethodologyComparisonMetaV1(query, attributionData)...)
+	}
+
 	if C.GetAttributionDebug() == 1 {
 		logCtx.WithFields(log.Fields{"TimePassedInMins": float64(time.Now().UTC().Unix()-queryStartTime) / 60}).
 			Info("Total query took time")
@@ -284,6 +329,15 @@ func isQueryValid(query *model.AttributionQueryV1) error {
 	if query.AttributionKey == model.AttributionKeyAllPageView && query.TacticOfferType == model.MarketingEventTypeTactic {
 		return errors.New("can not get all page view level report for Tactic")
 	}
+
+	if model.IsAccountLevelAttributionV1(query) && len(query.KPIQueries) == 0 {
+		return errors.New("account level attribution is supported only for kpi queries")
+	}
+	for role, weight := range query.RoleWeights {
+		if weight < 0 {
+			return fmt.Errorf("invalid weight for the role %s", role)
+		}
+	}
 	return nil
 }
 
@@ -1374,23 +1428,34 @@ func (store *MemSQL) RunAttributionForMethodologyComparisonV1(query *model.Attri
 
 	// Attribution based on given attribution methodology.
 	userConversionHit, _, err := model.ApplyAttribution(query.QueryType, query.AttributionMethodology,
-		query.ConversionEvent.Name, *usersToBeAttributed, sessions, *coalUserIdConversionTimestamp,
+		query.ConversionEvent.Name, *usersToBeAttributed, sessions, query.NonConvertedPaths, *coalUserIdConversionTimestamp,
 		query.LookbackDays, query.From, query.To, query.AttributionKey, logCtx)
 	if err != nil {
 		return nil, err
 	}
+	model.ApplyBuyingCommitteeWeights(userConversionHit, query.AccountKeyRoleWeights)
 
 	attributionData := model.AddUpConversionEventCount(userConversionHit, sessionWT)
 
 	// Attribution based on given attributionMethodologyCompare methodology.
 	userConversionCompareHit, _, err := model.ApplyAttribution(query.QueryType, query.AttributionMethodologyCompare,
-		query.ConversionEvent.Name, *usersToBeAttributed, sessions, *coalUserIdConversionTimestamp,
+		query.ConversionEvent.Name, *usersToBeAttributed, sessions, query.NonConvertedPaths, *coalUserIdConversionTimestamp,
 		query.LookbackDays, query.From, query.To, query.AttributionKey, logCtx)
 	if err != nil {
 		return nil, err
 	}
+	model.ApplyBuyingCommitteeWeights(userConversionCompareHit, query.AccountKeyRoleWeights)
 	attributionDataCompare := model.AddUpConversionEventCount(userConversionCompareHit, sessionWT)
 
+	if query.MethodologyComparisonDetails {
+		query.MethodologyComparisonHits = &model.MethodologyComparisonHits{
+			UserConversionHit:        userConversionHit,
+			UserConversionCompareHit: userConversionCompareHit,
+			Sessions:                 sessions,
+			ConversionTimestamp:      *coalUserIdConversionTimestamp,
+		}
+	}
+
 	// Merge compare data into attributionData.
 	for key := range attributionData {
 		if _, exists := attributionDataCompare[key]; exists {
@@ -1435,7 +1500,7 @@ func (store *MemSQL) runAttributionV1(goalEvent model.QueryEventWithProperties,
 
 	// 4. Apply attribution based on given attribution methodology
 	userConversionHit, userLinkedFEHit, err := model.ApplyAttribution(query.QueryType, query.AttributionMethodology,
-		goalEventName, *usersToBeAttributed, sessions, *coalUserIdConversionTimestamp,
+		goalEventName, *usersToBeAttributed, sessions, query.NonConvertedPaths, *coalUserIdConversionTimestamp,
 		query.LookbackDays, query.From, query.To, query.AttributionKey, logCtx)
 
 	if C.GetAttributionDebug() == 1 {
@@ -1444,6 +1509,8 @@ func (store *MemSQL) runAttributionV1(goalEvent model.QueryEventWithProperties,
 	if err != nil {
 		return nil, err
 	}
+	model.ApplyBuyingCommitteeWeights(userConversionHit, query.AccountKeyRoleWeights)
+	model.ApplyBuyingCommitteeWeightsForLinkedEvents(userLinkedFEHit, query.AccountKeyRoleWeights)
 
 	attributionData := make(map[string]*model.AttributionData)
 	attributionData = model.AddUpConversionEventCount(userConversionHit, sessionWT)
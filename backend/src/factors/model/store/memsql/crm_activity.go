@@ -267,8 +267,8 @@ func (store *MemSQL) UpdateCRMActivityAsSynced(projectID int64, source U.CRMSour
 	return crmActivity, http.StatusAccepted
 }
 
-func (store *MemSQL) GetActivitiesDistinctEventNamesByType(projectID int64, objectTypes []int) (map[int][]string, int) {
-	logFields := log.Fields{"project_id": projectID, "object_types": objectTypes}
+func (store *MemSQL) GetActivitiesDistinctEventNamesByType(projectID int64, source U.CRMSource, objectTypes []int) (map[int][]string, int) {
+	logFields := log.Fields{"project_id": projectID, "source": source, "object_types": objectTypes}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
 	logCtx := log.WithFields(logFields)
@@ -284,7 +284,7 @@ func (store *MemSQL) GetActivitiesDistinctEventNamesByType(projectID int64, obje
 		Type int
 	}
 
-	err := db.Table("crm_activities").Where("project_id = ? AND type = ?", projectID, objectTypes).
+	err := db.Table("crm_activities").Where("project_id = ? AND source = ? AND type IN (?)", projectID, source, objectTypes).
 		Select("DISTINCT(name) as name, type").Find(&distinctNames).Error
 	if err != nil {
 		if gorm.IsRecordNotFoundError(err) {
@@ -0,0 +1,321 @@
+package memsql
+
+import (
+	"encoding/json"
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"net/http"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateOrGetCRMCustomSource returns the custom source of the project by name, registers it if not exists.
+// Source ids are allocated per project, starting from CRM_SOURCE_CUSTOM_MIN.
+func (store *MemSQL) CreateOrGetCRMCustomSource(projectID int64, name string) (*model.CRMCustomSource, int, string) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"name":       name,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if projectID == 0 {
+		return nil, http.StatusBadRequest, "Invalid project."
+	}
+
+	if err := model.ValidateCRMCustomSourceName(name); err != nil {
+		return nil, http.StatusBadRequest, err.Error()
+	}
+
+	customSource, status := store.GetCRMCustomSourceByName(projectID, name)
+	if status != http.StatusNotFound {
+		if status == http.StatusFound {
+			return customSource, http.StatusFound, ""
+		}
+		return nil, http.StatusInternalServerError, "Failed to get custom source."
+	}
+
+	customSources, status := store.GetCRMCustomSources(projectID)
+	if status != http.StatusFound && status != http.StatusNotFound {
+		return nil, http.StatusInternalServerError, "Failed to get custom sources."
+	}
+
+	if len(customSources) >= model.MaxCRMCustomSourcesPerProject {
+		return nil, http.StatusBadRequest, "Custom sources limit exceeded."
+	}
+
+	source := U.CRM_SOURCE_CUSTOM_MIN
+	for i := range customSources {
+		if customSources[i].Source >= source {
+			source = customSources[i].Source + 1
+		}
+	}
+
+	customSource = &model.CRMCustomSource{
+		ProjectID: projectID,
+		Source:    source,
+		Name:      name,
+	}
+
+	db := C.GetServices().Db
+	if err := db.Create(customSource).Error; err != nil {
+		if IsDuplicateRecordError(err) {
+			// registered by a parallel request.
+			customSource, status = store.GetCRMCustomSourceByName(projectID, name)
+			if status == http.StatusFound {
+				return customSource, http.StatusFound, ""
+			}
+			return nil, http.StatusConflict, "Failed to register custom source. Retry the request."
+		}
+
+		logCtx.WithError(err).Error("Failed to create crm custom source.")
+		return nil, http.StatusInternalServerError, "Failed to register custom source."
+	}
+
+	return customSource, http.StatusCreated, ""
+}
+
+func (store *MemSQL) GetCRMCustomSourceByName(projectID int64, name string) (*model.CRMCustomSource, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"name":       name,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || name == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	var customSource model.CRMCustomSource
+	db := C.GetServices().Db
+	err := db.Where("project_id = ? AND name = ?", projectID, name).Limit(1).Find(&customSource).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+
+		log.WithFields(logFields).WithError(err).Error("Failed to get crm custom source by name.")
+		return nil, http.StatusInternalServerError
+	}
+
+	return &customSource, http.StatusFound
+}
+
+func (store *MemSQL) GetCRMCustomSources(projectID int64) ([]model.CRMCustomSource, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 {
+		return nil, http.StatusBadRequest
+	}
+
+	var customSources []model.CRMCustomSource
+	db := C.GetServices().Db
+	err := db.Where("project_id = ?", projectID).Order("source").Find(&customSources).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get crm custom sources.")
+		return nil, http.StatusInternalServerError
+	}
+
+	if len(customSources) == 0 {
+		return nil, http.StatusNotFound
+	}
+
+	return customSources, http.StatusFound
+}
+
+// GetAllCRMCustomSources returns the custom sources of all the projects, by project_id.
+func (store *MemSQL) GetAllCRMCustomSources() (map[int64][]model.CRMCustomSource, int) {
+	logFields := log.Fields{}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	var customSources []model.CRMCustomSource
+	db := C.GetServices().Db
+	err := db.Order("project_id, source").Find(&customSources).Error
+	if err != nil {
+		log.WithError(err).Error("Failed to get all crm custom sources.")
+		return nil, http.StatusInternalServerError
+	}
+
+	if len(customSources) == 0 {
+		return nil, http.StatusNotFound
+	}
+
+	customSourcesByProject := make(map[int64][]model.CRMCustomSource)
+	for i := range customSources {
+		projectID := customSources[i].ProjectID
+		customSourcesByProject[projectID] = append(customSourcesByProject[projectID], customSources[i])
+	}
+
+	return customSourcesByProject, http.StatusFound
+}
+
+// GetLastSyncedCRMUserBeforeTimestamp returns the previous synced state of the crm user, used as previous
+// properties on smart events.
+func (store *MemSQL) GetLastSyncedCRMUserBeforeTimestamp(projectID int64, source U.CRMSource, id string,
+	userType int, timestamp int64) (*model.CRMUser, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"source":     source,
+		"id":         id,
+		"user_type":  userType,
+		"timestamp":  timestamp,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || source == 0 || id == "" || userType == 0 || timestamp == 0 {
+		return nil, http.StatusBadRequest
+	}
+
+	var crmUser model.CRMUser
+	db := C.GetServices().Db
+	err := db.Model(&model.CRMUser{}).Where("project_id = ? AND source = ? AND id = ? AND type = ? AND synced = true AND timestamp < ?",
+		projectID, source, id, userType, timestamp).Order("timestamp desc").Limit(1).Find(&crmUser).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+
+		log.WithFields(logFields).WithError(err).Error("Failed to get last synced crm user.")
+		return nil, http.StatusInternalServerError
+	}
+
+	if crmUser.ID == "" {
+		return nil, http.StatusNotFound
+	}
+
+	return &crmUser, http.StatusFound
+}
+
+func (store *MemSQL) getLatestCRMCustomSourceUserProperties(projectID int64, sourceAlias, objectType string, limit int) ([]map[string]interface{}, U.CRMSource) {
+	logFields := log.Fields{
+		"project_id":   projectID,
+		"source_alias": sourceAlias,
+		"object_type":  objectType,
+	}
+	logCtx := log.WithFields(logFields)
+
+	userType := model.GetCRMCustomSourceObjectType(objectType)
+	if projectID == 0 || !model.CRMCustomSourceUserTypes[userType] {
+		return nil, 0
+	}
+
+	customSource, status := store.GetCRMCustomSourceByName(projectID, model.GetCRMCustomSourceNameByAlias(sourceAlias))
+	if status != http.StatusFound {
+		return nil, 0
+	}
+
+	var crmUsers []model.CRMUser
+	db := C.GetServices().Db
+	err := db.Model(&model.CRMUser{}).Where("project_id = ? AND source = ? AND type = ?", projectID, customSource.Source, userType).
+		Order("timestamp desc").Limit(limit).Find(&crmUsers).Error
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get latest crm users of custom source.")
+		return nil, 0
+	}
+
+	usersProperties := make([]map[string]interface{}, 0, len(crmUsers))
+	for i := range crmUsers {
+		var properties map[string]interface{}
+		if err := json.Unmarshal(crmUsers[i].Properties.RawMessage, &properties); err != nil {
+			logCtx.WithError(err).Error("Failed to unmarshal crm user properties of custom source.")
+			continue
+		}
+		usersProperties = append(usersProperties, properties)
+	}
+
+	return usersProperties, customSource.Source
+}
+
+// GetCRMCustomSourceObjectPropertiesName returns the categorical and datetime properties of the contact or lead,
+// used on the smart event filters. Datetime properties are the properties with datetime data type pushed.
+func (store *MemSQL) GetCRMCustomSourceObjectPropertiesName(projectID int64, sourceAlias, objectType string) ([]string, []string) {
+	logFields := log.Fields{
+		"project_id":   projectID,
+		"source_alias": sourceAlias,
+		"object_type":  objectType,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	usersProperties, source := store.getLatestCRMCustomSourceUserProperties(projectID, sourceAlias, objectType, 1000)
+	if source == 0 {
+		return nil, nil
+	}
+
+	var dateTimeProperties []model.CRMProperty
+	db := C.GetServices().Db
+	err := db.Model(&model.CRMProperty{}).Where("project_id = ? AND source = ? AND type = ? AND mapped_data_type = ?",
+		projectID, source, model.GetCRMCustomSourceObjectType(objectType), U.PropertyTypeDateTime).Find(&dateTimeProperties).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get datetime crm properties of custom source.")
+	}
+
+	isDateTime := make(map[string]bool)
+	for i := range dateTimeProperties {
+		isDateTime[dateTimeProperties[i].Name] = true
+	}
+
+	seen := make(map[string]bool)
+	categoricalPropertiesArray, dateTimePropertiesArray := make([]string, 0), make([]string, 0)
+	for i := range usersProperties {
+		for key := range usersProperties[i] {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if isDateTime[key] {
+				dateTimePropertiesArray = append(dateTimePropertiesArray, key)
+			} else {
+				categoricalPropertiesArray = append(categoricalPropertiesArray, key)
+			}
+		}
+	}
+
+	return categoricalPropertiesArray, dateTimePropertiesArray
+}
+
+// GetCRMCustomSourceObjectValuesByPropertyName returns the top values of the property of the contact or lead.
+func (store *MemSQL) GetCRMCustomSourceObjectValuesByPropertyName(projectID int64, sourceAlias, objectType string, propertyName string) []interface{} {
+	logFields := log.Fields{
+		"project_id":    projectID,
+		"source_alias":  sourceAlias,
+		"object_type":   objectType,
+		"property_name": propertyName,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	usersProperties, source := store.getLatestCRMCustomSourceUserProperties(projectID, sourceAlias, objectType, 1000)
+	if source == 0 {
+		return nil
+	}
+
+	valuesAggregate := make(map[interface{}]int, 0)
+	for i := range usersProperties {
+		value := usersProperties[i][propertyName]
+		if value == nil || value == "" {
+			continue
+		}
+
+		// values of the objects and lists are not comparable on the filters.
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+
+		valuesAggregate[value] = valuesAggregate[value] + 1
+	}
+
+	propertyValueTuples := getPropertyValueTuples(valuesAggregate, 100)
+	propertyValues := make([]interface{}, len(propertyValueTuples))
+	for i := range propertyValueTuples {
+		propertyValues[i] = propertyValueTuples[i].Name
+	}
+
+	return propertyValues
+}
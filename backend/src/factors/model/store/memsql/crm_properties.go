@@ -128,19 +128,20 @@ func (store *MemSQL) CreateCRMProperties(crmProperty *model.CRMProperty) (int, e
 	return http.StatusCreated, nil
 }
 
-func (store *MemSQL) GetCRMPropertiesForSync(projectID int64) ([]model.CRMProperty, int) {
-	logFields := log.Fields{"project_id": projectID}
+func (store *MemSQL) GetCRMPropertiesForSync(projectID int64, source U.CRMSource) ([]model.CRMProperty, int) {
+	logFields := log.Fields{"project_id": projectID, "source": source}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
 	logCtx := log.WithFields(logFields)
-	if projectID == 0 {
-		logCtx.Error("Invalid project id.")
+	if projectID == 0 || source == 0 {
+		logCtx.Error("Invalid project id or source.")
+		return nil, http.StatusBadRequest
 	}
 
 	db := C.GetServices().Db
 
 	var properties []model.CRMProperty
-	err := db.Model(model.CRMProperty{}).Where("project_id = ? AND synced = false", projectID).
+	err := db.Model(model.CRMProperty{}).Where("project_id = ? AND source = ? AND synced = false", projectID, source).
 		Order("timestamp").Find(&properties).Error
 	if err != nil {
 		logCtx.WithError(err).Error("Failed to get crm properties for sync.")
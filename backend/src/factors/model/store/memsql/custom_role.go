@@ -0,0 +1,266 @@
+package memsql
+
+import (
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+)
+
+func (store *MemSQL) isCustomRoleNameExists(projectID int64, name string, excludeID string) (bool, int) {
+	db := C.GetServices().Db
+
+	var count int64
+	query := db.Model(&model.CustomRole{}).Where("project_id = ? AND name = ?", projectID, name)
+	if excludeID != "" {
+		query = query.Where("id != ?", excludeID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		log.WithField("project_id", projectID).WithError(err).Error("Failed to check custom role name.")
+		return false, http.StatusInternalServerError
+	}
+	return count > 0, http.StatusOK
+}
+
+func (store *MemSQL) CreateCustomRole(projectID int64, customRole *model.CustomRole) (*model.CustomRole, int, string) {
+	logFields := log.Fields{
+		"project_id":  projectID,
+		"custom_role": customRole,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if projectID == 0 || customRole == nil {
+		return nil, http.StatusBadRequest, "Invalid project id or role."
+	}
+	customRole.Name = strings.TrimSpace(customRole.Name)
+	if customRole.Name == "" {
+		return nil, http.StatusBadRequest, "Name is required."
+	}
+	if _, err := model.GetCustomRolePermissions(customRole); err != nil {
+		return nil, http.StatusBadRequest, "Invalid permissions."
+	}
+
+	customRoles, errCode := store.GetCustomRoles(projectID)
+	if errCode != http.StatusFound {
+		return nil, http.StatusInternalServerError, "Failed to get custom roles."
+	}
+	if len(customRoles) >= model.MaxCustomRolesPerProject {
+		return nil, http.StatusBadRequest, "Custom roles limit exceeded."
+	}
+	for i := range customRoles {
+		if customRoles[i].Name == customRole.Name {
+			return nil, http.StatusConflict, "Custom role with the name already exists."
+		}
+	}
+
+	customRole.ID = U.GetUUID()
+	customRole.ProjectID = projectID
+	customRole.CreatedAt = U.TimeNowZ()
+	customRole.UpdatedAt = customRole.CreatedAt
+
+	db := C.GetServices().Db
+	if err := db.Create(customRole).Error; err != nil {
+		logCtx.WithError(err).Error("Failed to create custom role.")
+		return nil, http.StatusInternalServerError, "Failed to create custom role."
+	}
+
+	return customRole, http.StatusCreated, ""
+}
+
+func (store *MemSQL) GetCustomRoles(projectID int64) ([]model.CustomRole, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 {
+		return nil, http.StatusBadRequest
+	}
+
+	customRoles := make([]model.CustomRole, 0)
+	db := C.GetServices().Db
+	err := db.Where("project_id = ?", projectID).Order("created_at ASC").Find(&customRoles).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get custom roles.")
+		return nil, http.StatusInternalServerError
+	}
+
+	return customRoles, http.StatusFound
+}
+
+func (store *MemSQL) GetCustomRole(projectID int64, id string) (*model.CustomRole, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || id == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	var customRole model.CustomRole
+	db := C.GetServices().Db
+	err := db.Where("project_id = ? AND id = ?", projectID, id).First(&customRole).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+		log.WithFields(logFields).WithError(err).Error("Failed to get custom role.")
+		return nil, http.StatusInternalServerError
+	}
+
+	return &customRole, http.StatusFound
+}
+
+func (store *MemSQL) UpdateCustomRole(projectID int64, id string, customRole *model.CustomRole) (int, string) {
+	logFields := log.Fields{
+		"project_id":  projectID,
+		"id":          id,
+		"custom_role": customRole,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || id == "" || customRole == nil {
+		return http.StatusBadRequest, "Invalid project id or role."
+	}
+
+	updateFields := map[string]interface{}{
+		"description": customRole.Description,
+		"updated_at":  U.TimeNowZ(),
+	}
+	customRole.Name = strings.TrimSpace(customRole.Name)
+	if customRole.Name != "" {
+		exists, errCode := store.isCustomRoleNameExists(projectID, customRole.Name, id)
+		if errCode != http.StatusOK {
+			return errCode, "Failed to check custom role name."
+		}
+		if exists {
+			return http.StatusConflict, "Custom role with the name already exists."
+		}
+		updateFields["name"] = customRole.Name
+	}
+	if customRole.Permissions != nil {
+		if _, err := model.GetCustomRolePermissions(customRole); err != nil {
+			return http.StatusBadRequest, "Invalid permissions."
+		}
+		updateFields["permissions"] = customRole.Permissions
+	}
+
+	db := C.GetServices().Db
+	query := db.Model(&model.CustomRole{}).Where("project_id = ? AND id = ?", projectID, id).Updates(updateFields)
+	if query.Error != nil {
+		log.WithFields(logFields).WithError(query.Error).Error("Failed to update custom role.")
+		return http.StatusInternalServerError, "Failed to update custom role."
+	}
+	if query.RowsAffected == 0 {
+		return http.StatusNotFound, "Custom role not found."
+	}
+
+	return http.StatusAccepted, ""
+}
+
+// DeleteCustomRole deletes the role and moves the agents of the role back to
+// the permissions of their predefined role.
+func (store *MemSQL) DeleteCustomRole(projectID int64, id string) (int, string) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"id":         id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if projectID == 0 || id == "" {
+		return http.StatusBadRequest, "Invalid project id or role."
+	}
+
+	db := C.GetServices().Db
+	err := db.Model(&model.ProjectAgentMapping{}).Where("project_id = ? AND custom_role_id = ?", projectID, id).
+		Updates(map[string]interface{}{"custom_role_id": nil, "updated_at": U.TimeNowZ()}).Error
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to remove custom role from project agent mappings.")
+		return http.StatusInternalServerError, "Failed to remove custom role from agents."
+	}
+
+	query := db.Where("project_id = ? AND id = ?", projectID, id).Delete(&model.CustomRole{})
+	if query.Error != nil {
+		logCtx.WithError(query.Error).Error("Failed to delete custom role.")
+		return http.StatusInternalServerError, "Failed to delete custom role."
+	}
+	if query.RowsAffected == 0 {
+		return http.StatusNotFound, "Custom role not found."
+	}
+
+	return http.StatusAccepted, ""
+}
+
+// SetCustomRoleForProjectAgentMapping assigns the custom role to the agent of the
+// project. The agent is moved to AGENT role, as admin only actions are not part
+// of the permissions. Empty customRoleID removes the custom role.
+func (store *MemSQL) SetCustomRoleForProjectAgentMapping(projectID int64, agentUUID string, customRoleID string) int {
+	logFields := log.Fields{
+		"project_id":     projectID,
+		"agent_uuid":     agentUUID,
+		"custom_role_id": customRoleID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || agentUUID == "" {
+		return http.StatusBadRequest
+	}
+
+	updateFields := map[string]interface{}{"custom_role_id": nil, "updated_at": U.TimeNowZ()}
+	if customRoleID != "" {
+		if _, errCode := store.GetCustomRole(projectID, customRoleID); errCode != http.StatusFound {
+			return errCode
+		}
+		updateFields["custom_role_id"] = customRoleID
+		updateFields["role"] = model.AGENT
+	}
+
+	db := C.GetServices().Db
+	query := db.Model(&model.ProjectAgentMapping{}).Where("project_id = ? AND agent_uuid = ?", projectID, agentUUID).
+		Updates(updateFields)
+	if query.Error != nil {
+		log.WithFields(logFields).WithError(query.Error).Error("Failed to set custom role on project agent mapping.")
+		return http.StatusInternalServerError
+	}
+	if query.RowsAffected == 0 {
+		return http.StatusNotFound
+	}
+
+	return http.StatusAccepted
+}
+
+// GetProjectAgentMappingPermissions returns the permissions of the custom role of
+// the mapping, if assigned, else the permissions of the predefined role.
+func (store *MemSQL) GetProjectAgentMappingPermissions(pam *model.ProjectAgentMapping) ([]string, int) {
+	if pam == nil {
+		return nil, http.StatusBadRequest
+	}
+	if pam.CustomRoleID == nil || *pam.CustomRoleID == "" {
+		return model.GetPredefinedRolePermissions(pam.Role), http.StatusFound
+	}
+
+	customRole, errCode := store.GetCustomRole(pam.ProjectID, *pam.CustomRoleID)
+	if errCode == http.StatusNotFound {
+		// role deleted in between, fallback to the predefined role.
+		return model.GetPredefinedRolePermissions(pam.Role), http.StatusFound
+	}
+	if errCode != http.StatusFound {
+		return nil, errCode
+	}
+
+	permissions, err := model.GetCustomRolePermissions(customRole)
+	if err != nil {
+		log.WithField("custom_role", customRole).WithError(err).Error("Failed to decode custom role permissions.")
+		return nil, http.StatusInternalServerError
+	}
+	return permissions, http.StatusFound
+}
@@ -505,6 +505,36 @@ func existsEventByCustomerEventID(projectID int64, userID, customerEventID strin
 	return false
 }
 
+// GetEventIDByCustomerEventID returns the id of the event of the project with the customer_event_id,
+// across the users of the project.
+func (store *MemSQL) GetEventIDByCustomerEventID(projectID int64, customerEventID string) (string, int) {
+	logFields := log.Fields{
+		"project_id":        projectID,
+		"customer_event_id": customerEventID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || customerEventID == "" {
+		return "", http.StatusBadRequest
+	}
+
+	var event model.Event
+	db := C.GetServices().Db
+	if err := db.Limit(1).Where("project_id = ? AND customer_event_id = ?", projectID, customerEventID).
+		Select("id").Find(&event).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return "", http.StatusNotFound
+		}
+		log.WithFields(logFields).WithError(err).Error("Failed to get event by customer_event_id.")
+		return "", http.StatusInternalServerError
+	}
+
+	if event.ID == "" {
+		return "", http.StatusNotFound
+	}
+	return event.ID, http.StatusFound
+}
+
 func (store *MemSQL) GetEvent(projectId int64, userId string, id string) (*model.Event, int) {
 	logFields := log.Fields{
 		"project_id": projectId,
@@ -1037,10 +1067,33 @@ func doesEventIsPageViewAndHasMarketingProperty(event *model.Event) (bool, error
 	return isPageAndHasMarketingProperty, nil
 }
 
-func filterEventsForSession(events []model.Event, endTimestamp int64) []*model.Event {
+// getSessionLandingReferrerDomain returns the referrer domain of the landing page of the session started
+// by the event, or of the existing session, when the event continues it.
+func (store *MemSQL) getSessionLandingReferrerDomain(projectId int64, event *model.Event, logCtx *log.Entry) string {
+	if event.SessionId == nil {
+		return model.GetEventReferrerDomain(event)
+	}
+
+	sessionEvent, errCode := store.GetEventById(projectId, *event.SessionId, event.UserId)
+	if errCode != http.StatusFound {
+		logCtx.WithField("err_code", errCode).WithField("session_id", *event.SessionId).
+			Warn("Failed to get session event for landing referrer. Using referrer of the event.")
+		return model.GetEventReferrerDomain(event)
+	}
+	sessionProperties, err := U.DecodePostgresJsonb(&sessionEvent.Properties)
+	if err != nil {
+		logCtx.WithError(err).Warn("Failed to decode session properties for landing referrer. Using referrer of the event.")
+		return model.GetEventReferrerDomain(event)
+	}
+	return U.GetPropertyValueAsString((*sessionProperties)[U.SP_INITIAL_REFERRER_DOMAIN])
+}
+
+func filterEventsForSession(events []model.Event, endTimestamp int64,
+	sessionDefinition model.SessionDefinition) []*model.Event {
 	logFields := log.Fields{
-		"events":        events,
-		"end_timestamp": endTimestamp,
+		"events":             events,
+		"end_timestamp":      endTimestamp,
+		"session_definition": sessionDefinition,
 	}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
@@ -1056,7 +1109,8 @@ func filterEventsForSession(events []model.Event, endTimestamp int64) []*model.E
 	// Remove the session continuation event (first event with session_id)
 	// when the first event to add session have marketing property,
 	// to avoid continuing session.
-	if len(filteredEvents) > 1 && filteredEvents[0].SessionId != nil {
+	if sessionDefinition.NewSessionOnCampaignChange &&
+		len(filteredEvents) > 1 && filteredEvents[0].SessionId != nil {
 		hasMarketingProperty, err := doesEventIsPageViewAndHasMarketingProperty(filteredEvents[1])
 		if err != nil {
 			log.WithError(err).Error("Failed to decode properties Jsonb.")
@@ -1231,20 +1285,22 @@ func (store *MemSQL) DissociateEventsFromSession(projectID int64, events []model
 // AddSessionForUser - Wrapper for addSessionForUser to handle creating
 // new session for last event when new session conditions met.
 func (store *MemSQL) AddSessionForUser(projectId int64, userId string, userEvents []model.Event,
-	bufferTimeBeforeSessionCreateInSecs int64, sessionEventNameId string) (int, int, bool, int, int) {
+	bufferTimeBeforeSessionCreateInSecs int64, sessionEventNameId string,
+	sessionDefinition model.SessionDefinition) (int, int, bool, int, int) {
 	logFields := log.Fields{
 		"project_id":  projectId,
 		"user_id":     userId,
 		"user_events": userEvents,
 		"buffer_time_before_session_create_in_secs": bufferTimeBeforeSessionCreateInSecs,
 		"session_event_name_id":                     sessionEventNameId,
+		"session_definition":                        sessionDefinition,
 	}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
 	noOfFilteredEvents, noOfSessionsCreated, sessionContinuedFlag,
 		noOfUserPropertiesUpdated, isLastEventToBeProcessed,
 		errCode := store.addSessionForUser(projectId, userId, userEvents,
-		bufferTimeBeforeSessionCreateInSecs, sessionEventNameId)
+		bufferTimeBeforeSessionCreateInSecs, sessionEventNameId, sessionDefinition)
 
 	if errCode == http.StatusInternalServerError || errCode == http.StatusBadRequest {
 		return noOfFilteredEvents, noOfSessionsCreated, sessionContinuedFlag,
@@ -1257,7 +1313,7 @@ func (store *MemSQL) AddSessionForUser(projectId int64, userId string, userEvent
 	if isLastEventToBeProcessed {
 		lastUserEventAsList := userEvents[len(userEvents)-1:]
 		_, _, _, _, _, errCode = store.addSessionForUser(projectId, userId, lastUserEventAsList,
-			bufferTimeBeforeSessionCreateInSecs, sessionEventNameId)
+			bufferTimeBeforeSessionCreateInSecs, sessionEventNameId, sessionDefinition)
 
 		noOfSessionsCreated++
 	}
@@ -1281,13 +1337,15 @@ e2 - t2
 e3 - t3
 */
 func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvents []model.Event,
-	bufferTimeBeforeSessionCreateInSecs int64, sessionEventNameId string) (int, int, bool, int, bool, int) {
+	bufferTimeBeforeSessionCreateInSecs int64, sessionEventNameId string,
+	sessionDefinition model.SessionDefinition) (int, int, bool, int, bool, int) {
 	logFields := log.Fields{
 		"project_id":  projectId,
 		"user_id":     userId,
 		"user_events": userEvents,
 		"buffer_time_before_session_create_in_secs": bufferTimeBeforeSessionCreateInSecs,
 		"session_event_name_id":                     sessionEventNameId,
+		"session_definition":                        sessionDefinition,
 	}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
@@ -1298,7 +1356,8 @@ func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvent
 
 	isV2Enabled := C.EnableUserLevelEventPullForAddSessionByProjectID(projectId)
 	if isV2Enabled {
-		eventWithSession, status := store.GetLastEventWithSessionByUser(projectId, userEvents[0].UserId, userEvents[0].Timestamp)
+		eventWithSession, status := store.GetLastEventWithSessionByUser(projectId, userEvents[0].UserId,
+			userEvents[0].Timestamp, sessionDefinition.GetInactivityTimeoutInSecs())
 		if status == http.StatusFound && eventWithSession != nil {
 			userEvents = model.PrependEvent(*eventWithSession, userEvents)
 		}
@@ -1316,7 +1375,7 @@ func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvent
 		endTimestamp = latestUserEvent.Timestamp
 	}
 
-	events := filterEventsForSession(userEvents, endTimestamp)
+	events := filterEventsForSession(userEvents, endTimestamp, sessionDefinition)
 	if len(events) == 0 {
 		return 0, 0, false, 0, false, http.StatusNotModified
 	}
@@ -1326,6 +1385,7 @@ func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvent
 		logCtx.WithField("err_code", errCode).Error("Failed to get project on addSessionForUser")
 		return 0, 0, false, 0, false, http.StatusNotModified
 	}
+	timezone := U.TimeZoneString(project.TimeZone)
 
 	noOfFilteredEvents := len(events)
 
@@ -1347,6 +1407,7 @@ func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvent
 	// period or has marketing property, use current_event - 1 as session end
 	// and update. Update current_event as session start and do the same till the end.
 	var currentSessionCandidateEvent model.Event
+	var landingReferrerDomain string
 	isFirstEvent := true
 	updateEventSessionUserPropertiesRecordMap := make(map[string]model.SessionUserProperties, 0)
 	updateEventPropertiesParams := make([]model.UpdateEventPropertiesParams, 0)
@@ -1355,17 +1416,26 @@ func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvent
 		if isFirstEvent {
 			currentSessionCandidateEvent = *events[i]
 			isFirstEvent = false
+			if sessionDefinition.NewSessionOnReferrerChange {
+				landingReferrerDomain = store.getSessionLandingReferrerDomain(projectId, events[i], logCtx)
+			}
 		}
-		hasMarketingProperty, err := doesEventIsPageViewAndHasMarketingProperty(events[i])
-		if err != nil {
-			logCtx.WithError(err).
-				Error("Failed to check marketing property on event properties.")
-			return noOfFilteredEvents, noOfSessionsCreated, sessionContinuedFlag, 0,
-				isLastEventToBeProcessed, http.StatusInternalServerError
+		// Marketing property on the event starts a new session only with campaign change on the definition.
+		hasMarketingProperty := false
+		if sessionDefinition.NewSessionOnCampaignChange {
+			var err error
+			hasMarketingProperty, err = doesEventIsPageViewAndHasMarketingProperty(events[i])
+			if err != nil {
+				logCtx.WithError(err).
+					Error("Failed to check marketing property on event properties.")
+				return noOfFilteredEvents, noOfSessionsCreated, sessionContinuedFlag, 0,
+					isLastEventToBeProcessed, http.StatusInternalServerError
+			}
 		}
 
 		isNewSessionRequired := (i == 0 && len(events) == 1) ||
-			(i+1 < len(events) && ((events[i+1].Timestamp - events[i].Timestamp) > model.NewUserSessionInactivityInSeconds))
+			(i+1 < len(events) && model.IsNewSessionRequired(sessionDefinition, events[i], events[i+1],
+				landingReferrerDomain, timezone))
 
 		// Balance events on the list after creating session for the previous set.
 		isLastSetOfEvents := i == len(events)-1
@@ -1380,7 +1450,7 @@ func (store *MemSQL) addSessionForUser(projectId int64, userId string, userEvent
 		backMatch := false
 		forwardMatch := false
 		// Skip or Continue adding this event to the session
-		if !isNewSessionRequired && (*events[i]).SessionId == nil {
+		if sessionDefinition.NewSessionOnCampaignChange && !isNewSessionRequired && (*events[i]).SessionId == nil {
 			// Backward properties matching case
 			if i-1 >= 0 && model.AreMarketingPropertiesMatching(*events[i-1], *events[i]) {
 				hasMarketingProperty = false
@@ -2478,11 +2548,13 @@ func (store *MemSQL) IsSmartEventAlreadyExist(projectID int64, userID, eventName
 SELECT * FROM events WHERE project_id = ? AND user_id = ? AND session_id IS NOT NULL AND timestamp < ?
 ORDER BY timestamp, created_at DESC LIMIT 1;
 */
-func (store *MemSQL) GetLastEventWithSessionByUser(projectId int64, userId string, firstEventTimestamp int64) (*model.Event, int) {
+func (store *MemSQL) GetLastEventWithSessionByUser(projectId int64, userId string, firstEventTimestamp int64,
+	inactivityTimeoutInSecs int64) (*model.Event, int) {
 	logFields := log.Fields{
-		"project_id":            projectId,
-		"user_id":               userId,
-		"first_event_timestamp": firstEventTimestamp,
+		"project_id":                 projectId,
+		"user_id":                    userId,
+		"first_event_timestamp":      firstEventTimestamp,
+		"inactivity_timeout_in_secs": inactivityTimeoutInSecs,
 	}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
@@ -2494,7 +2566,7 @@ func (store *MemSQL) GetLastEventWithSessionByUser(projectId int64, userId strin
 
 	// Max window size by the inactivity period allowed for session continuation.
 	// We don't consider last event with session, which is older than the inactivity period.
-	startTimestamp := (firstEventTimestamp - model.NewUserSessionInactivityInSeconds) + 2
+	startTimestamp := (firstEventTimestamp - inactivityTimeoutInSecs) + 2
 
 	db := C.GetServices().Db
 	if err := db.Limit(1).Order("timestamp, created_at DESC").
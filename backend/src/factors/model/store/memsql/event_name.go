@@ -226,6 +226,11 @@ func (store *MemSQL) CreateOrGetCRMSmartEventFilterEventName(projectID int64, ev
 		return nil, http.StatusBadRequest
 	}
 
+	if !store.isRegisteredSmartEventFilterSource(projectID, filterExpr) {
+		logCtx.Error("Custom crm source on smart event filter is not registered for the project.")
+		return nil, http.StatusBadRequest
+	}
+
 	dupEventName, duplicate := store.checkDuplicateSmartEventFilter(projectID, filterExpr)
 	if duplicate { // re-enable the smart event name
 		if dupEventName.Deleted == true {
@@ -1254,7 +1259,7 @@ func (store *MemSQL) GetSmartEventFilterEventNames(projectID int64, includeDelet
 
 	var eventNames []model.EventName
 	if err := db.Where(whereStmnt,
-		projectID, []string{model.TYPE_CRM_SALESFORCE, model.TYPE_CRM_HUBSPOT}).Find(&eventNames).Error; err != nil {
+		projectID, model.SmartCRMEventTypes).Find(&eventNames).Error; err != nil {
 		log.WithFields(log.Fields{"project_id": projectID}).WithError(err).Error("Failed getting filter_event_names")
 
 		return nil, http.StatusInternalServerError
@@ -1288,7 +1293,7 @@ func (store *MemSQL) GetSmartEventFilterEventNameByID(projectID int64, id string
 
 	var eventName model.EventName
 	if err := db.Limit(1).Where(whereStmnt,
-		projectID, []string{model.TYPE_CRM_SALESFORCE, model.TYPE_CRM_HUBSPOT}, id).Find(&eventName).Error; err != nil {
+		projectID, model.SmartCRMEventTypes, id).Find(&eventName).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return nil, http.StatusNotFound
 		}
@@ -1512,9 +1517,23 @@ func getCRMSmartEventNameType(source string) string {
 	if source == model.SmartCRMEventSourceHubspot {
 		return model.TYPE_CRM_HUBSPOT
 	}
+
+	if model.IsCRMCustomSourceAlias(source) {
+		return model.TYPE_CRM_CUSTOM
+	}
 	return ""
 }
 
+// isRegisteredSmartEventFilterSource checks the custom crm source on the filter is registered for the project.
+func (store *MemSQL) isRegisteredSmartEventFilterSource(projectID int64, filterExpr *model.SmartCRMEventFilter) bool {
+	if filterExpr == nil || !model.IsCRMCustomSourceAlias(filterExpr.Source) {
+		return true
+	}
+
+	_, status := store.GetCRMCustomSourceByName(projectID, model.GetCRMCustomSourceNameByAlias(filterExpr.Source))
+	return status == http.StatusFound
+}
+
 func (store *MemSQL) UpdateCRMSmartEventFilter(projectID int64, id string, eventName *model.EventName,
 	filterExpr *model.SmartCRMEventFilter) (*model.EventName, int) {
 	logFields := log.Fields{
@@ -1525,6 +1544,10 @@ func (store *MemSQL) UpdateCRMSmartEventFilter(projectID int64, id string, event
 	}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 
+	if !store.isRegisteredSmartEventFilterSource(projectID, filterExpr) {
+		return nil, http.StatusBadRequest
+	}
+
 	_, duplicate := store.checkDuplicateSmartEventFilter(projectID, filterExpr)
 	if duplicate {
 		return nil, http.StatusConflict
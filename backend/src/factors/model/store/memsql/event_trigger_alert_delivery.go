@@ -0,0 +1,219 @@
+package memsql
+
+import (
+	"errors"
+	cacheRedis "factors/cache/redis"
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"net/http"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+)
+
+func (store *MemSQL) CreateEventTriggerAlertDelivery(delivery *model.EventTriggerAlertDelivery) int {
+	logFields := log.Fields{
+		"project_id": delivery.ProjectID,
+		"alert_id":   delivery.AlertID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if delivery.ProjectID == 0 || delivery.AlertID == "" || delivery.DeliveryOption == "" {
+		logCtx.Error("Invalid event trigger alert delivery.")
+		return http.StatusBadRequest
+	}
+
+	delivery.ID = U.GetUUID()
+	delivery.CreatedAt = U.TimeNowZ()
+	delivery.UpdatedAt = delivery.CreatedAt
+
+	db := C.GetServices().Db
+	if err := db.Create(delivery).Error; err != nil {
+		logCtx.WithError(err).Error("Failed to create event trigger alert delivery.")
+		return http.StatusInternalServerError
+	}
+	return http.StatusCreated
+}
+
+// GetEventTriggerAlertDeliveries returns the latest deliveries of the alert, optionally filtered by status.
+func (store *MemSQL) GetEventTriggerAlertDeliveries(projectID int64, alertID, status string, limit int) ([]model.EventTriggerAlertDelivery, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"alert_id":   alertID,
+		"status":     status,
+		"limit":      limit,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if limit <= 0 {
+		limit = model.DefaultDeliveriesLimit
+	}
+	if limit > model.MaxDeliveriesLimit {
+		limit = model.MaxDeliveriesLimit
+	}
+
+	db := C.GetServices().Db
+	query := db.Where("project_id = ? AND alert_id = ?", projectID, alertID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	deliveries := make([]model.EventTriggerAlertDelivery, 0)
+	if err := query.Order("created_at DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		logCtx.WithError(err).Error("Failed to get event trigger alert deliveries.")
+		return deliveries, http.StatusInternalServerError
+	}
+	return deliveries, http.StatusFound
+}
+
+func (store *MemSQL) GetEventTriggerAlertDeliveryByID(projectID int64, alertID, id string) (*model.EventTriggerAlertDelivery, int) {
+	logFields := log.Fields{
+		"project_id":  projectID,
+		"alert_id":    alertID,
+		"delivery_id": id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	var delivery model.EventTriggerAlertDelivery
+	db := C.GetServices().Db
+	err := db.Where("project_id = ? AND alert_id = ? AND id = ?", projectID, alertID, id).
+		Limit(1).Find(&delivery).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+		log.WithFields(logFields).WithError(err).Error("Failed to get event trigger alert delivery.")
+		return nil, http.StatusInternalServerError
+	}
+	return &delivery, http.StatusFound
+}
+
+// ReplayEventTriggerAlertDelivery re-queues the payload of a failed delivery, to be sent again
+// by the event trigger alerts job only to the delivery option which failed.
+func (store *MemSQL) ReplayEventTriggerAlertDelivery(projectID int64, alertID, id string) (int, error) {
+	logFields := log.Fields{
+		"project_id":  projectID,
+		"alert_id":    alertID,
+		"delivery_id": id,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	delivery, errCode := store.GetEventTriggerAlertDeliveryByID(projectID, alertID, id)
+	if errCode != http.StatusFound {
+		return errCode, errors.New("delivery not found")
+	}
+	if delivery.Status != model.DeliveryStatusFailure {
+		return http.StatusBadRequest, errors.New("only failed deliveries can be replayed")
+	}
+	if delivery.DeliveryOption == model.EMAIL {
+		// Failed email digests are kept and sent again by the job.
+		return http.StatusBadRequest, errors.New("email digests are retried automatically")
+	}
+	if delivery.ReplayedAt != nil {
+		return http.StatusConflict, errors.New("delivery has already been replayed")
+	}
+
+	var alert model.CachedEventTriggerAlert
+	if err := U.DecodePostgresJsonbToStructType(delivery.Payload, &alert); err != nil {
+		logCtx.WithError(err).Error("Failed to decode delivery payload.")
+		return http.StatusInternalServerError, err
+	}
+	alert.ReplayOf = delivery.ID
+	alert.ReplayTo = delivery.DeliveryOption
+
+	errCode, err := setEventTriggerAlertDeliveryReplayed(projectID, alertID, id)
+	if errCode != http.StatusAccepted {
+		if errCode == http.StatusInternalServerError {
+			logCtx.WithError(err).Error("Failed to mark delivery as replayed.")
+		}
+		return errCode, err
+	}
+
+	errCode, err = enqueueEventTriggerAlertReplay(projectID, alertID, &alert, logCtx)
+	if errCode != http.StatusAccepted {
+		if err := unsetEventTriggerAlertDeliveryReplayed(projectID, alertID, id); err != nil {
+			logCtx.WithError(err).Error("Failed to unmark delivery as replayed.")
+		}
+		return errCode, err
+	}
+	return http.StatusAccepted, nil
+}
+
+// enqueueEventTriggerAlertReplay adds the alert to the cache and the sorted set, for the job to send it.
+func enqueueEventTriggerAlertReplay(projectID int64, alertID string, alert *model.CachedEventTriggerAlert,
+	logCtx *log.Entry) (int, error) {
+	timestamp := time.Now().UnixNano()
+	key, err := model.GetEventTriggerAlertCacheKey(projectID, timestamp, alertID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err := model.SetCacheForEventTriggerAlert(key, alert); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	ssKey, err := getSortedSetCacheKey(projectID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	ssValue, err := key.Key()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if _, err := cacheRedis.ZincrPersistentBatch(true, cacheRedis.SortedSetKeyValueTuple{Key: ssKey, Value: ssValue}); err != nil {
+		logCtx.WithError(err).Error("Failed to add replayed delivery to sorted set.")
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusAccepted, nil
+}
+
+// setEventTriggerAlertDeliveryReplayed marks the delivery as replayed, only if it is not
+// replayed already, so that concurrent replays of the delivery enqueue it only once.
+func setEventTriggerAlertDeliveryReplayed(projectID int64, alertID, id string) (int, error) {
+	db := C.GetServices().Db
+	dbx := db.Model(&model.EventTriggerAlertDelivery{}).
+		Where("project_id = ? AND alert_id = ? AND id = ? AND replayed_at IS NULL", projectID, alertID, id).
+		Updates(map[string]interface{}{"replayed_at": U.TimeNowZ(), "updated_at": U.TimeNowZ()})
+	if dbx.Error != nil {
+		return http.StatusInternalServerError, dbx.Error
+	}
+	if dbx.RowsAffected == 0 {
+		return http.StatusConflict, errors.New("delivery has already been replayed")
+	}
+	return http.StatusAccepted, nil
+}
+
+// unsetEventTriggerAlertDeliveryReplayed allows the delivery to be replayed again,
+// when it could not be enqueued after being marked as replayed.
+func unsetEventTriggerAlertDeliveryReplayed(projectID int64, alertID, id string) error {
+	db := C.GetServices().Db
+	return db.Model(&model.EventTriggerAlertDelivery{}).
+		Where("project_id = ? AND alert_id = ? AND id = ?", projectID, alertID, id).
+		Updates(map[string]interface{}{"replayed_at": gorm.Expr("NULL"), "updated_at": U.TimeNowZ()}).Error
+}
+
+// DeleteEventTriggerAlertDeliveriesOlderThanGivenDays deletes the deliveries of all the alerts older than the expiry.
+func (store *MemSQL) DeleteEventTriggerAlertDeliveriesOlderThanGivenDays(expiry int) (int, error) {
+	logFields := log.Fields{
+		"expiry": expiry,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if expiry <= 0 {
+		return http.StatusBadRequest, nil
+	}
+
+	db := C.GetServices().Db
+	err := db.Where("created_at < ?", U.TimeNowZ().AddDate(0, 0, -expiry)).
+		Delete(&model.EventTriggerAlertDelivery{}).Error
+	if err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to delete event trigger alert deliveries older than given days.")
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
@@ -158,6 +158,13 @@ func (store *MemSQL) convertEventTriggerAlertToEventTriggerAlertInfo(list []mode
 				deliveryOption += "& Teams"
 			}
 		}
+		if alert.Email {
+			if deliveryOption == "" {
+				deliveryOption += "Email"
+			} else {
+				deliveryOption += "& Email"
+			}
+		}
 
 		internalStatus := ""
 		if obj.InternalStatus == model.Active || obj.InternalStatus == model.Paused {
@@ -348,7 +355,7 @@ func (store *MemSQL) isValidEventTriggerAlertBody(projectID int64, agentID strin
 		logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
 		return false, http.StatusBadRequest, errMsg
 	}
-	if !alert.Slack && !alert.Webhook && !alert.Teams {
+	if !alert.Slack && !alert.Webhook && !alert.Teams && !alert.Email {
 		errMsg := "Please choose at least 1 destination to receive the alerts in before saving."
 		logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
 		return false, http.StatusBadRequest, errMsg
@@ -383,6 +390,23 @@ func (store *MemSQL) isValidEventTriggerAlertBody(projectID int64, agentID strin
 		logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
 		return false, http.StatusBadRequest, errMsg
 	}
+	if alert.Email && len(alert.Emails) == 0 {
+		errMsg := "Please enter at least 1 email address to receive the alert digest."
+		logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
+		return false, http.StatusBadRequest, errMsg
+	}
+	for _, email := range alert.Emails {
+		if !U.IsEmail(email) {
+			errMsg := fmt.Sprintf("Invalid email address %s.", email)
+			logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
+			return false, http.StatusBadRequest, errMsg
+		}
+	}
+	if alert.EmailDigestIntervalInMins < 0 {
+		errMsg := "Email digest interval cannot be negative."
+		logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
+		return false, http.StatusBadRequest, errMsg
+	}
 	if isEmptyPostgresJsonb(alert.MessageProperty) {
 		errMsg := "Please add at least 1 property to send in the alert"
 		logCtx.WithError(fmt.Errorf(errMsg)).Error("alert body validation failure")
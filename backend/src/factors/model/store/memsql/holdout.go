@@ -0,0 +1,157 @@
+package memsql
+
+import (
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ExecuteHoldoutLiftQuery runs the kpi query on the exposed and the control populations of the split and
+// returns the lift of each metric, per unit of the population, with the confidence interval.
+func (store *MemSQL) ExecuteHoldoutLiftQuery(projectID int64, reqID string, query model.HoldoutLiftQuery,
+	enableOptimisedFilterOnProfileQuery bool, enableOptimisedFilterOnEventUserQuery bool) (model.QueryResult, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"query":      query,
+		"req_id":     reqID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if valid, errMsg := query.IsValid(); !valid {
+		logCtx.Error(errMsg)
+		return model.QueryResult{}, http.StatusBadRequest
+	}
+
+	var excludedLinkedinOrgIDs []string
+	if query.Split.Type == model.HoldoutSplitTypeLinkedinCapping {
+		var status int
+		excludedLinkedinOrgIDs, status = store.getLinkedinCappingExcludedOrgIDs(projectID, query)
+		if status != http.StatusOK {
+			return model.QueryResult{}, status
+		}
+	}
+
+	exposed, control, err := model.GetHoldoutPopulations(&query, excludedLinkedinOrgIDs)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get holdout populations.")
+		return model.QueryResult{}, http.StatusBadRequest
+	}
+
+	customMetrics, _, status := store.GetCustomMetricsByProjectId(projectID)
+	if status != http.StatusFound && status != http.StatusNotFound {
+		logCtx.Error("Failed to get custom metrics for holdout lift query.")
+		return model.QueryResult{}, http.StatusInternalServerError
+	}
+	countMetrics := model.GetHoldoutCountMetrics(query.KPI, customMetrics)
+
+	from, to := query.KPI.GetQueryDateRange()
+	var metrics []string
+	populationSizes := make(map[string]int64)
+	populationValues := make(map[string][]float64)
+	for _, population := range []model.HoldoutPopulation{exposed, control} {
+		results, status := store.ExecuteKPIQueryGroup(projectID, reqID, population.KPI,
+			enableOptimisedFilterOnProfileQuery, enableOptimisedFilterOnEventUserQuery)
+		if status != http.StatusOK {
+			logCtx.WithField("group", population.Group).Error("Failed to execute kpi query for holdout population.")
+			return model.QueryResult{}, status
+		}
+
+		totals, exists := model.GetHoldoutTotalsResult(results)
+		if !exists {
+			logCtx.WithField("group", population.Group).Error("No totals on kpi query result for holdout population.")
+			return model.QueryResult{}, http.StatusInternalServerError
+		}
+		metrics = totals.Headers
+		values := make([]float64, 0, len(totals.Headers))
+		if len(totals.Rows) > 0 {
+			for _, value := range totals.Rows[0] {
+				values = append(values, U.SafeConvertToFloat64(value))
+			}
+		}
+		populationValues[population.Group] = values
+
+		size, status := store.getHoldoutPopulationSize(projectID, population, from, to)
+		if status != http.StatusFound {
+			logCtx.WithField("group", population.Group).Error("Failed to get size of holdout population.")
+			return model.QueryResult{}, http.StatusInternalServerError
+		}
+		populationSizes[population.Group] = size
+	}
+
+	return model.GetHoldoutLiftResult(metrics, countMetrics, populationValues[model.HoldoutGroupExposed],
+		populationSizes[model.HoldoutGroupExposed], populationValues[model.HoldoutGroupControl],
+		populationSizes[model.HoldoutGroupControl], query.GetZScore()), http.StatusOK
+}
+
+// getLinkedinCappingExcludedOrgIDs returns the distinct linkedin orgs excluded by the
+// capping rules, or by the rule on the split, during the query range.
+func (store *MemSQL) getLinkedinCappingExcludedOrgIDs(projectID int64, query model.HoldoutLiftQuery) ([]string, int) {
+	from, to := query.KPI.GetQueryDateRange()
+	timezone := query.KPI.GetTimeZone()
+
+	exclusions, status := store.GetAllLinkedinCappingExclusionsForTimerange(projectID,
+		U.GetDateAsStringIn(from, timezone), U.GetDateAsStringIn(to, timezone))
+	if status != http.StatusFound && status != http.StatusOK {
+		return nil, status
+	}
+
+	orgIDs := make([]string, 0)
+	seenOrgIDs := make(map[string]bool)
+	for _, exclusion := range exclusions {
+		if query.Split.CappingRuleID != "" && exclusion.RuleID != query.Split.CappingRuleID {
+			continue
+		}
+		if exclusion.OrgID == "" || seenOrgIDs[exclusion.OrgID] {
+			continue
+		}
+		seenOrgIDs[exclusion.OrgID] = true
+		orgIDs = append(orgIDs, exclusion.OrgID)
+	}
+	return orgIDs, http.StatusOK
+}
+
+// getHoldoutPopulationSize returns the count of distinct (coalesced) users in the population who were
+// active during the query range. Group users are not counted.
+func (store *MemSQL) getHoldoutPopulationSize(projectID int64, population model.HoldoutPopulation, from, to int64) (int64, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"population": population,
+		"from":       from,
+		"to":         to,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	stmnt := "SELECT COUNT(DISTINCT COALESCE(customer_user_id, id)) FROM users WHERE project_id = ?" + " " +
+		"AND (is_group_user IS NULL OR is_group_user = false) AND join_timestamp <= ? AND last_event_at >= ?"
+	params := []interface{}{projectID, to, time.Unix(from, 0).UTC()}
+	if population.SegmentID != "" {
+		stmnt = stmnt + " " + "AND JSON_EXTRACT_STRING(associated_segments, ?) IS NOT NULL"
+		params = append(params, population.SegmentID)
+	} else if population.Exclude {
+		stmnt = stmnt + " " + fmt.Sprintf("AND JSON_EXTRACT_STRING(properties, ?) IS NOT NULL"+" "+
+			"AND JSON_EXTRACT_STRING(properties, ?) != '' AND JSON_EXTRACT_STRING(properties, ?) NOT IN (%s)",
+			U.GetValuePlaceHolder(len(population.Values)))
+		params = append(params, population.PropertyName, population.PropertyName, population.PropertyName)
+		params = append(params, U.GetInterfaceList(population.Values)...)
+	} else {
+		stmnt = stmnt + " " + fmt.Sprintf("AND JSON_EXTRACT_STRING(properties, ?) IN (%s)",
+			U.GetValuePlaceHolder(len(population.Values)))
+		params = append(params, population.PropertyName)
+		params = append(params, U.GetInterfaceList(population.Values)...)
+	}
+
+	var count int64
+	db := C.GetServices().Db
+	if err := db.Raw(stmnt, params...).Row().Scan(&count); err != nil {
+		logCtx.WithError(err).Error("Failed to get holdout population size.")
+		return 0, http.StatusInternalServerError
+	}
+	return count, http.StatusFound
+}
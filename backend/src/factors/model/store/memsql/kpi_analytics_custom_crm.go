@@ -0,0 +1,67 @@
+package memsql
+
+import (
+	C "factors/config"
+	"factors/model/model"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (store *MemSQL) GetKPIConfigsForCustomCRMUsers(projectID int64, reqID string, includeDerivedKPIs bool) (map[string]interface{}, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"req_id":     reqID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	_, status := store.GetCRMCustomSources(projectID)
+	if status != http.StatusFound {
+		if status != http.StatusNotFound {
+			log.WithFields(logFields).Warn("Failed in getting custom crm sources.")
+		}
+		return nil, http.StatusOK
+	}
+
+	displayCategory := model.CustomCRMUsersDisplayCategory
+	rMetrics := store.GetCustomMetricAndDerivedMetricByProjectIdAndDisplayCategory(projectID, displayCategory, includeDerivedKPIs)
+
+	return map[string]interface{}{
+		"category":         model.ProfileCategory,
+		"display_category": displayCategory,
+		"metrics":          rMetrics,
+		"properties":       store.GetPropertiesForCustomCRM(projectID, reqID),
+	}, http.StatusOK
+}
+
+// GetPropertiesForCustomCRM returns the user properties of all the custom crm sources of the project.
+func (store *MemSQL) GetPropertiesForCustomCRM(projectID int64, reqID string) []map[string]string {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"req_id":     reqID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	customSources, status := store.GetCRMCustomSources(projectID)
+	if status != http.StatusFound {
+		return make([]map[string]string, 0)
+	}
+
+	properties, propertiesToDisplayNames, err := store.GetRequiredUserPropertiesByProject(projectID, 2500, C.GetLookbackWindowForEventUserCache())
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get custom crm properties. Internal error")
+		return make([]map[string]string, 0)
+	}
+
+	// transforming to kpi structure.
+	customCRMOnlyProperties := make([]map[string]string, 0)
+	for i := range customSources {
+		customCRMOnlyProperties = append(customCRMOnlyProperties, model.TransformCRMPropertiesToKPIConfigProperties(properties,
+			propertiesToDisplayNames, "$"+customSources[i].GetSourceAlias())...)
+	}
+
+	standardUserProperties := store.GetKPIConfigFromStandardUserProperties(projectID)
+	return append(standardUserProperties, customCRMOnlyProperties...)
+}
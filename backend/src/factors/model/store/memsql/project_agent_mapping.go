@@ -273,6 +273,8 @@ func (store *MemSQL) EditProjectAgentMapping(projectId int64, agentUUIDToEdit st
 
 	updateFields := make(map[string]interface{}, 0)
 	updateFields["role"] = role
+	// predefined role replaces the custom role of the agent.
+	updateFields["custom_role_id"] = nil
 
 	err := db.Model(&model.ProjectAgentMapping{}).Where("project_id = ? AND agent_uuid = ?", projectId, agentUUIDToEdit).Update(updateFields).Error
 
@@ -743,6 +743,39 @@ func (store *MemSQL) UpdateProjectSettings(projectId int64, settings *model.Proj
 		}
 	}
 
+	// validate company enrichment waterfall
+	if settings.CompanyEnrichmentWaterfall != nil {
+		var waterfall model.CompanyEnrichmentWaterfall
+		err := U.DecodePostgresJsonbToStructType(settings.CompanyEnrichmentWaterfall, &waterfall)
+		if err != nil {
+			log.WithFields(log.Fields{"project_id": projectId, "setting": settings}).WithError(
+				err).Error("Failed decoding company enrichment waterfall json. Aborting.")
+			return nil, http.StatusBadRequest
+		}
+		if err := model.ValidateCompanyEnrichmentWaterfall(waterfall); err != nil {
+			log.WithFields(log.Fields{"project_id": projectId, "waterfall": waterfall}).WithError(
+				err).Error("Invalid company enrichment waterfall. Aborting")
+			return nil, http.StatusBadRequest
+		}
+	}
+
+	// validate session definition
+	if settings.SessionDefinition != nil {
+		sessionDefinition, err := model.DecodeSessionDefinition(settings.SessionDefinition)
+		if err != nil {
+			log.WithFields(log.Fields{"project_id": projectId, "session_definition": settings.SessionDefinition}).WithError(
+				err).Error("Invalid session definition. Aborting")
+			return nil, http.StatusBadRequest
+		}
+		// rules not on the partial definition are stored with the defaults.
+		settings.SessionDefinition, err = U.EncodeStructTypeToPostgresJsonb(&sessionDefinition)
+		if err != nil {
+			log.WithFields(log.Fields{"project_id": projectId, "session_definition": sessionDefinition}).WithError(
+				err).Error("Failed encoding session definition. Aborting")
+			return nil, http.StatusInternalServerError
+		}
+	}
+
 	// validate saml config
 
 	if settings.SamlConfiguration != nil {
@@ -1807,6 +1840,7 @@ func (store *MemSQL) GetTimelinesConfig(projectID int64) (model.TimelinesConfig,
 
 func (store *MemSQL) UpdateAccScoreWeights(projectId int64, weights model.AccWeights) error {
 
+	weights.Version = model.GetAccWeightsVersion(&weights)
 	weightjsonb, err := U.EncodeStructTypeToPostgresJsonb(weights)
 	if err != nil {
 		log.WithError(err).Error("Error creating postgres jsonb in acc score")
@@ -447,6 +447,10 @@ func (store *MemSQL) getPropertyDetailsForSmartEventName(projectID int64, eventN
 				eventName = util.EVENT_NAME_HUBSPOT_DEAL_STATE_CHANGED
 			}
 		}
+
+		if model.IsCRMCustomSourceAlias(smartEventFilter.Source) {
+			eventName = model.GetCRMCustomSourceUserEventName(smartEventFilter.Source, smartEventFilter.ObjectType, model.CRMActionCreated)
+		}
 	}
 
 	if eventName == "" {
@@ -0,0 +1,110 @@
+package memsql
+
+import (
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	insertSegmentMembershipChangesStr       = "INSERT INTO segment_membership_changes (id,project_id,segment_id,user_id,action,timestamp,created_at) VALUES "
+	segmentMembershipChangesInsertBatchSize = 500
+)
+
+func (store *MemSQL) CreateSegmentMembershipChanges(projectID int64, changes []model.SegmentMembershipChange) int {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"changes":    len(changes),
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if projectID == 0 {
+		logCtx.Error("Invalid project_id on create segment membership changes.")
+		return http.StatusBadRequest
+	}
+
+	for i := range changes {
+		change := &changes[i]
+		if change.SegmentID == "" || change.UserID == "" ||
+			(change.Action != model.SegmentMembershipEntered && change.Action != model.SegmentMembershipExited) {
+			logCtx.WithField("change", change).Error("Invalid segment membership change.")
+			return http.StatusBadRequest
+		}
+
+		change.ID = U.GetUUID()
+		change.ProjectID = projectID
+		change.CreatedAt = U.TimeNowZ()
+		if change.Timestamp == 0 {
+			change.Timestamp = U.TimeNowUnix()
+		}
+	}
+
+	db := C.GetServices().Db
+	for start := 0; start < len(changes); start += segmentMembershipChangesInsertBatchSize {
+		end := start + segmentMembershipChangesInsertBatchSize
+		if end > len(changes) {
+			end = len(changes)
+		}
+
+		insertValuesStatement := make([]string, 0, end-start)
+		insertValues := make([]interface{}, 0, (end-start)*7)
+		for _, change := range changes[start:end] {
+			insertValuesStatement = append(insertValuesStatement, "(?, ?, ?, ?, ?, ?, ?)")
+			insertValues = append(insertValues, change.ID, change.ProjectID, change.SegmentID,
+				change.UserID, change.Action, change.Timestamp, change.CreatedAt)
+		}
+
+		insertStatement := insertSegmentMembershipChangesStr + joinWithComma(insertValuesStatement...)
+		if err := db.Exec(insertStatement, insertValues...).Error; err != nil {
+			logCtx.WithError(err).Error("Failed to create segment membership changes.")
+			return http.StatusInternalServerError
+		}
+	}
+	return http.StatusCreated
+}
+
+// GetSegmentMembershipHistory returns the latest membership changes of the segment within the
+// time range, optionally for a single user.
+func (store *MemSQL) GetSegmentMembershipHistory(projectID int64, segmentID, userID string,
+	from, to int64, limit int) ([]model.SegmentMembershipChange, int) {
+	logFields := log.Fields{
+		"project_id": projectID,
+		"segment_id": segmentID,
+		"user_id":    userID,
+		"from":       from,
+		"to":         to,
+		"limit":      limit,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if limit <= 0 {
+		limit = model.DefaultSegmentMembershipHistoryLimit
+	}
+	if limit > model.MaxSegmentMembershipHistoryLimit {
+		limit = model.MaxSegmentMembershipHistoryLimit
+	}
+
+	db := C.GetServices().Db
+	query := db.Where("project_id = ? AND segment_id = ?", projectID, segmentID)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if from > 0 {
+		query = query.Where("timestamp >= ?", from)
+	}
+	if to > 0 {
+		query = query.Where("timestamp <= ?", to)
+	}
+
+	changes := make([]model.SegmentMembershipChange, 0)
+	if err := query.Order("timestamp DESC").Limit(limit).Find(&changes).Error; err != nil {
+		log.WithFields(logFields).WithError(err).Error("Failed to get segment membership history.")
+		return changes, http.StatusInternalServerError
+	}
+	return changes, http.StatusFound
+}
@@ -128,6 +128,38 @@ func (store *MemSQL) GetShareableURLWithShareStringWithLargestScope(projectID in
 	return &shareableURL, http.StatusFound
 }
 
+// GetActiveShareableURLsWithShareString returns all the shares of the entity which are not deleted or expired,
+// ordered from the largest scope of share type.
+func (store *MemSQL) GetActiveShareableURLsWithShareString(projectID int64, shareString string, entityType int) ([]*model.ShareableURL, int) {
+	logFields := log.Fields{
+		"project_id":     projectID,
+		"share_query_id": shareString,
+		"entity_type":    entityType,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	shareableURLs := make([]*model.ShareableURL, 0)
+	if shareString == "" || projectID == 0 || entityType == 0 {
+		logCtx.Error("Invalid share string/project id/entity type")
+		return shareableURLs, http.StatusBadRequest
+	}
+
+	db := C.GetServices().Db
+	err := db.Order("share_type ASC").
+		Where("query_id = ? AND project_id = ? AND entity_type = ? AND is_deleted = ? AND expires_at > ?",
+			shareString, projectID, entityType, false, time.Now().Unix()).
+		Find(&shareableURLs).Error
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get active shareable urls")
+		return shareableURLs, http.StatusInternalServerError
+	}
+	if len(shareableURLs) == 0 {
+		return shareableURLs, http.StatusNotFound
+	}
+	return shareableURLs, http.StatusFound
+}
+
 // func (store *MemSQL) GetShareableURLWithID(projectID uint64, shareId string) (*model.ShareableURL, int) {
 // 	logFields := log.Fields{
 // 		"project_id": projectID,
@@ -174,30 +206,44 @@ func (store *MemSQL) updateShareableURL(whereFields, updateFields map[string]int
 	return http.StatusAccepted
 }
 
-// func (store *MemSQL) UpdateShareableURLShareTypeWithShareIDandCreatedBy(projectID uint64, shareId, createdBy string, shareType int, allowedUsers string) int {
-// 	logFields := log.Fields{
-// 		"project_id":    projectID,
-// 		"share_id":      shareId,
-// 		"share_type":    shareType,
-// 		"allowed_users": allowedUsers,
-// 	}
-// 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
-// 	logCtx := log.WithFields(logFields)
+// UpdateShareableURLShareTypeWithShareIDandCreatedBy updates the share type of the share created by the agent.
+// The allowed users and the password hash are kept only for the share types which use them.
+func (store *MemSQL) UpdateShareableURLShareTypeWithShareIDandCreatedBy(projectID int64, shareId, createdBy string,
+	shareType int, allowedUsers, passwordHash string) int {
+	logFields := log.Fields{
+		"project_id":    projectID,
+		"share_id":      shareId,
+		"share_type":    shareType,
+		"allowed_users": allowedUsers,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
 
-// 	if shareId == "" || createdBy == "" || !model.ValidShareEntityTypes[shareType] || projectID == 0 {
-// 		logCtx.Error("Invalid share id/created by/share type/project id")
-// 		return http.StatusBadRequest
-// 	}
+	if shareId == "" || createdBy == "" || !model.ValidShareTypes[shareType] || projectID == 0 {
+		logCtx.Error("Invalid share id/created by/share type/project id")
+		return http.StatusBadRequest
+	}
+	if errMsg := validateShareTypeParams(shareType, allowedUsers, passwordHash); errMsg != "" {
+		logCtx.Error(errMsg)
+		return http.StatusBadRequest
+	}
 
-// 	whereFields := map[string]interface{}{"id": shareId, "project_id": projectID, "created_by": createdBy}
+	whereFields := map[string]interface{}{"id": shareId, "project_id": projectID, "created_by": createdBy}
 
-// 	updateFields := make(map[string]interface{}, 0)
-// 	updateFields["share_type"] = shareType
-// 	// if shareType == model.ShareableURLShareTypeAllowedUsers {
-// 	// 	updateFields["allowed_users"] = allowedUsers
-// 	// }
-// 	return store.updateShareableURL(whereFields, updateFields)
-// }
+	updateFields := make(map[string]interface{}, 0)
+	updateFields["share_type"] = shareType
+	updateFields["allowed_users"] = ""
+	updateFields["password_hash"] = ""
+	if shareType == model.ShareableURLShareTypeAllowedUsers {
+		updateFields["allowed_users"] = allowedUsers
+	}
+	if shareType == model.ShareableURLShareTypePassword {
+		updateFields["password_hash"] = passwordHash
+	}
+	// Access issued to the viewers before the update is not valid after it.
+	updateFields["updated_at"] = U.TimeNowZ()
+	return store.updateShareableURL(whereFields, updateFields)
+}
 
 func (store *MemSQL) DeleteShareableURLWithShareIDandAgentID(projectID int64, shareId, createdBy string) int {
 	logFields := log.Fields{
@@ -327,6 +373,16 @@ func (store *MemSQL) ValidateCreateShareableURLRequest(params *model.ShareableUR
 		return false, "Invalid share type."
 	}
 
+	if errMsg := validateShareTypeParams(params.ShareType, params.AllowedUsers, params.PasswordHash); errMsg != "" {
+		return false, errMsg
+	}
+	if params.ShareType != model.ShareableURLShareTypeAllowedUsers {
+		params.AllowedUsers = ""
+	}
+	if params.ShareType != model.ShareableURLShareTypePassword {
+		params.PasswordHash = ""
+	}
+
 	if params.EntityType == model.ShareableURLEntityTypeQuery {
 		query, err := store.GetQueryWithQueryId(projectID, params.EntityID)
 		logCtx.Info("Query fetched : ", query)
@@ -352,3 +408,13 @@ func (store *MemSQL) ValidateCreateShareableURLRequest(params *model.ShareableUR
 	logCtx.Info("Shareable URls is valid: ", params)
 	return true, ""
 }
+
+func validateShareTypeParams(shareType int, allowedUsers, passwordHash string) string {
+	if shareType == model.ShareableURLShareTypeAllowedUsers && allowedUsers == "" {
+		return "Allowed users are required for the share type."
+	}
+	if shareType == model.ShareableURLShareTypePassword && passwordHash == "" {
+		return "Password is required for the share type."
+	}
+	return ""
+}
@@ -2,17 +2,18 @@ package memsql
 
 import (
 	C "factors/config"
-	U "factors/util"
 	"factors/model/model"
+	U "factors/util"
 	"net/http"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func (store *MemSQL) CreateSharableURLAudit(sharableURL *model.ShareableURL, agentId string) int {
+func (store *MemSQL) CreateSharableURLAudit(sharableURL *model.ShareableURL, agentId, viewerEmail, clientIP string) int {
 	logFields := log.Fields{
 		"agent_uuid":    agentId,
+		"viewer_email":  viewerEmail,
 		"shareable_url": sharableURL,
 	}
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
@@ -24,16 +25,19 @@ func (store *MemSQL) CreateSharableURLAudit(sharableURL *model.ShareableURL, age
 	}
 
 	audit := &model.ShareableURLAudit{
-		ID:			U.GetUUID(),
-		ShareID:    sharableURL.ID,
-		ProjectID:  sharableURL.ProjectID,
-		QueryID:    sharableURL.QueryID,
-		EntityType: sharableURL.EntityType,
-		ShareType:  sharableURL.ShareType,
-		EntityID:   sharableURL.EntityID,
-		IsDeleted:  sharableURL.IsDeleted,
-		ExpiresAt:  sharableURL.ExpiresAt,
-		AccessedBy: agentId,
+		ID:           U.GetUUID(),
+		ShareID:      sharableURL.ID,
+		ProjectID:    sharableURL.ProjectID,
+		QueryID:      sharableURL.QueryID,
+		EntityType:   sharableURL.EntityType,
+		ShareType:    sharableURL.ShareType,
+		AllowedUsers: sharableURL.AllowedUsers,
+		EntityID:     sharableURL.EntityID,
+		IsDeleted:    sharableURL.IsDeleted,
+		ExpiresAt:    sharableURL.ExpiresAt,
+		AccessedBy:   agentId,
+		ViewerEmail:  viewerEmail,
+		ClientIP:     clientIP,
 	}
 
 	db := C.GetServices().Db
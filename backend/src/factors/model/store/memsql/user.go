@@ -1283,6 +1283,16 @@ func getUserIDBySegementAnonymousID(segAnonID string) string {
 	return "seg" + "-" + segAnonID
 }
 
+// getUserIDByImportAnonymousID returns the id of the user of the import anonymous id. Being
+// derived from the anonymous id, the unique (project_id, id) key keeps one user per anonymous id.
+func getUserIDByImportAnonymousID(anonymousID string) string {
+	if anonymousID == "" {
+		return ""
+	}
+
+	return "imp" + "-" + anonymousID
+}
+
 func getDomainUserIDByDomainGroupIndexANDDomainName(domainGroupIndex int, domainName string) string {
 	if domainGroupIndex == 0 || domainName == "" {
 		return ""
@@ -1618,6 +1628,86 @@ func (store *MemSQL) CreateOrGetAMPUser(projectId int64, ampUserId string, times
 	return user.ID, http.StatusCreated
 }
 
+func (store *MemSQL) GetUserIDByImportAnonymousID(projectID int64, anonymousID string) (string, int) {
+	logFields := log.Fields{
+		"project_id":   projectID,
+		"anonymous_id": anonymousID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	// id of the import user is derived from the anonymous id.
+	db := C.GetServices().Db
+	var user model.User
+	err := db.Limit(1).Where("project_id = ? AND id = ?",
+		projectID, getUserIDByImportAnonymousID(anonymousID)).Select("id").Find(&user).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return "", http.StatusNotFound
+		}
+
+		logCtx.WithError(err).Error("Failed to get user by import anonymous id")
+		return "", http.StatusInternalServerError
+	}
+
+	if user.ID == "" {
+		return "", http.StatusNotFound
+	}
+
+	return user.ID, http.StatusFound
+}
+
+// CreateOrGetImportUser returns the user of the anonymous id of the events import file, creates
+// the user with the anonymous id if not exists, for the rows of the same anonymous id imported
+// on different runs to be on the same user.
+func (store *MemSQL) CreateOrGetImportUser(projectID int64, anonymousID string, timestamp int64) (string, int) {
+	logFields := log.Fields{
+		"project_id":   projectID,
+		"anonymous_id": anonymousID,
+		"timestamp":    timestamp,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	if projectID == 0 || anonymousID == "" {
+		return "", http.StatusBadRequest
+	}
+
+	logCtx := log.WithFields(logFields)
+
+	userID, errCode := store.GetUserIDByImportAnonymousID(projectID, anonymousID)
+	if errCode == http.StatusInternalServerError {
+		return "", errCode
+	}
+	if errCode == http.StatusFound {
+		return userID, errCode
+	}
+
+	source := model.UserSourceWeb
+	user, err := store.createUserWithError(&model.User{
+		ID:            getUserIDByImportAnonymousID(anonymousID),
+		ProjectId:     projectID,
+		JoinTimestamp: timestamp,
+		Source:        &source,
+	})
+	if err != nil {
+		if IsDuplicateRecordError(err) {
+			// Unique (project_id, id) constraint, with the id derived from the anonymous id.
+			// The user is created by a concurrent import of the same anonymous id.
+			userID, errCode := store.GetUserIDByImportAnonymousID(projectID, anonymousID)
+			if errCode == http.StatusFound {
+				return userID, errCode
+			}
+			logCtx.WithField("err_code", errCode).Error("Failed to get user by import anonymous id after duplicate on CreateOrGetImportUser")
+			return "", http.StatusInternalServerError
+		}
+
+		logCtx.WithError(err).Error("Failed to create user by import anonymous id on CreateOrGetImportUser")
+		return "", http.StatusInternalServerError
+	}
+
+	return user.ID, http.StatusCreated
+}
+
 // GetRecentUserPropertyKeysWithLimits This method gets all the recent 'limit' property keys from DB for a given project
 func (store *MemSQL) GetRecentUserPropertyKeysWithLimits(projectID int64, usersLimit int, propertyLimit int, seedDate time.Time) ([]U.Property, error) {
 	logFields := log.Fields{
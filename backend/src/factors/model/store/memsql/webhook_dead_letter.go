@@ -0,0 +1,59 @@
+package memsql
+
+import (
+	C "factors/config"
+	"factors/model/model"
+	U "factors/util"
+	"net/http"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+)
+
+func (store *MemSQL) CreateWebhookDeadLetter(deadLetter *model.WebhookDeadLetter) int {
+	logFields := log.Fields{
+		"project_id":  deadLetter.ProjectID,
+		"delivery_id": deadLetter.DeliveryID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+	logCtx := log.WithFields(logFields)
+
+	if deadLetter.ProjectID == 0 || deadLetter.DeliveryID == "" {
+		logCtx.Error("Invalid webhook dead letter.")
+		return http.StatusBadRequest
+	}
+
+	deadLetter.CreatedAt = U.TimeNowZ()
+
+	db := C.GetServices().Db
+	if err := db.Create(deadLetter).Error; err != nil {
+		if IsDuplicateRecordError(err) {
+			return http.StatusConflict
+		}
+		logCtx.WithError(err).Error("Failed to create webhook dead letter.")
+		return http.StatusInternalServerError
+	}
+	return http.StatusCreated
+}
+
+func (store *MemSQL) GetWebhookDeadLetter(projectID int64, deliveryID string) (*model.WebhookDeadLetter, int) {
+	logFields := log.Fields{
+		"project_id":  projectID,
+		"delivery_id": deliveryID,
+	}
+	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
+
+	var deadLetter model.WebhookDeadLetter
+	db := C.GetServices().Db
+	err := db.Where("project_id = ? AND delivery_id = ?", projectID, deliveryID).
+		Limit(1).Find(&deadLetter).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, http.StatusNotFound
+		}
+		log.WithFields(logFields).WithError(err).Error("Failed to get webhook dead letter.")
+		return nil, http.StatusInternalServerError
+	}
+	return &deadLetter, http.StatusFound
+}
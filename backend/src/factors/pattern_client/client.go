@@ -29,6 +29,14 @@ const (
 	OperationNameGetUserAndEventsInfo     = "GetUserAndEventsInfo"
 )
 
+// Failover is sent on the request retried after some of the pattern servers failed, so
+// that the replicas serve only the keys of the failed servers. RetryNodes are the servers
+// failed on the last attempt and FailedNodes are all the servers failed till now.
+type Failover struct {
+	FailedNodes []string `json:"fn,omitempty"`
+	RetryNodes  []string `json:"rn,omitempty"`
+}
+
 type GenericRPCResp struct {
 	ProjectId int64  `json:"pid"`
 	ModelId   uint64 `json:"mid"`
@@ -37,6 +45,7 @@ type GenericRPCResp struct {
 }
 
 type GetAllPatternsRequest struct {
+	Failover
 	ProjectId  int64  `json:"pid"`
 	ModelId    uint64 `json:"mid"`
 	StartEvent string `json:"se"` // optional filter.
@@ -49,6 +58,7 @@ type GetAllPatternsResponse struct {
 }
 
 type GetAllContainingPatternsRequest struct {
+	Failover
 	ProjectId int64  `json:"pid"`
 	ModelId   uint64 `json:"mid"`
 	Event     string `json:"en"`
@@ -60,6 +70,7 @@ type GetAllContainingPatternsResponse struct {
 }
 
 type GetPatternsRequest struct {
+	Failover
 	ProjectId     int64      `json:"pid"`
 	ModelId       uint64     `json:"mid"`
 	PatternEvents [][]string `json:"pe"`
@@ -71,6 +82,7 @@ type GetPatternsResponse struct {
 }
 
 type GetTotalEventCountRequest struct {
+	Failover
 	ProjectId int64  `json:"pid"`
 	ModelId   uint64 `json:"mid"` // Optional, if not passed latest modelId will be used
 }
@@ -88,6 +100,7 @@ type ModelInfo struct {
 }
 
 type GetUserAndEventsInfoRequest struct {
+	Failover
 	ProjectId int64  `json:"pid"`
 	ModelId   uint64 `json:"mid"` // Optional, if not passed latest modelId will be used
 }
@@ -121,29 +134,17 @@ func GetAllPatterns(reqId string, projectId int64, modelId uint64, startEvent, e
 		StartEvent: startEvent,
 		EndEvent:   endEvent,
 	}
-	paramBytes, err := rpcJson.EncodeClientRequest(RPCServiceName+Separator+OperationNameGetAllPatterns, params)
+	gatherResp, err := callPatternServers(reqId, OperationNameGetAllPatterns, func(failover Failover) interface{} {
+		params.Failover = failover
+		return params
+	})
 	if err != nil {
 		return []*pattern.Pattern{}, err
 	}
-	serverAddrs := C.GetServices().GetPatternServerAddresses()
-
-	gatherResp := make(chan httpResp, len(serverAddrs))
-	headers := map[string]string{
-		"content-type": "application/json",
-		"X-Req-Id":     reqId,
-	}
-
-	urls := make([]string, 0, 0)
-	for _, serverAddr := range serverAddrs {
-		url := fmt.Sprintf("http://%s%s", serverAddr, RPCEndpoint)
-		urls = append(urls, url)
-	}
-
-	httpDo(http.MethodPost, urls, paramBytes, headers, gatherResp)
 
 	patterns := make([]*pattern.Pattern, 0, 0)
 
-	for r := range gatherResp {
+	for _, r := range gatherResp {
 		if r.err != nil {
 			log.WithError(r.err).Error("Error Ignoring GetAllPatternsResponse")
 			continue
@@ -182,29 +183,17 @@ func GetAllContainingPatterns(reqId string, projectId int64, modelId uint64, eve
 		ModelId:   modelId,
 		Event:     event,
 	}
-	paramBytes, err := rpcJson.EncodeClientRequest(RPCServiceName+Separator+OperationNameGetAllContainingPatterns, params)
+	gatherResp, err := callPatternServers(reqId, OperationNameGetAllContainingPatterns, func(failover Failover) interface{} {
+		params.Failover = failover
+		return params
+	})
 	if err != nil {
 		return []*pattern.Pattern{}, err
 	}
-	serverAddrs := C.GetServices().GetPatternServerAddresses()
-
-	gatherResp := make(chan httpResp, len(serverAddrs))
-	headers := map[string]string{
-		"content-type": "application/json",
-		"X-Req-Id":     reqId,
-	}
-
-	urls := make([]string, 0, 0)
-	for _, serverAddr := range serverAddrs {
-		url := fmt.Sprintf("http://%s%s", serverAddr, RPCEndpoint)
-		urls = append(urls, url)
-	}
-
-	httpDo(http.MethodPost, urls, paramBytes, headers, gatherResp)
 
 	patterns := make([]*pattern.Pattern, 0, 0)
 
-	for r := range gatherResp {
+	for _, r := range gatherResp {
 		if r.err != nil {
 			log.WithError(r.err).Error("Error Ignoring GetAllContainingPatternsResponse")
 			continue
@@ -243,29 +232,17 @@ func GetPatterns(reqId string, projectId int64, modelId uint64, patternEvents []
 		ModelId:       modelId,
 		PatternEvents: patternEvents,
 	}
-	paramBytes, err := rpcJson.EncodeClientRequest(RPCServiceName+Separator+OperationNameGetPatterns, params)
+	gatherResp, err := callPatternServers(reqId, OperationNameGetPatterns, func(failover Failover) interface{} {
+		params.Failover = failover
+		return params
+	})
 	if err != nil {
 		return []*pattern.Pattern{}, err
 	}
-	serverAddrs := C.GetServices().GetPatternServerAddresses()
-
-	gatherResp := make(chan httpResp, len(serverAddrs))
-	headers := map[string]string{
-		"content-type": "application/json",
-		"X-Req-Id":     reqId,
-	}
-
-	urls := make([]string, 0, 0)
-	for _, serverAddr := range serverAddrs {
-		url := fmt.Sprintf("http://%s%s", serverAddr, RPCEndpoint)
-		urls = append(urls, url)
-	}
-
-	httpDo(http.MethodPost, urls, paramBytes, headers, gatherResp)
 
 	patterns := make([]*pattern.Pattern, 0, 0)
 
-	for r := range gatherResp {
+	for _, r := range gatherResp {
 		if r.err != nil {
 			log.WithError(r.err).Error("Error Ignoring GetPatternsResponse")
 			continue
@@ -303,31 +280,18 @@ func GetUserAndEventsInfo(reqId string, projectId int64, modelId uint64) (*patte
 		ProjectId: projectId,
 		ModelId:   modelId,
 	}
-	paramBytes, err := rpcJson.EncodeClientRequest(RPCServiceName+Separator+OperationNameGetUserAndEventsInfo, params)
+	gatherResp, err := callPatternServers(reqId, OperationNameGetUserAndEventsInfo, func(failover Failover) interface{} {
+		params.Failover = failover
+		return params
+	})
 	if err != nil {
 		return nil, modelId, err
 	}
 
-	serverAddrs := C.GetServices().GetPatternServerAddresses()
-
-	gatherResp := make(chan httpResp, len(serverAddrs))
-	headers := map[string]string{
-		"content-type": "application/json",
-		"X-Req-Id":     reqId,
-	}
-
-	urls := make([]string, 0, 0)
-	for _, serverAddr := range serverAddrs {
-		url := fmt.Sprintf("http://%s%s", serverAddr, RPCEndpoint)
-		urls = append(urls, url)
-	}
-
-	httpDo(http.MethodPost, urls, paramBytes, headers, gatherResp)
-
 	var respUserAndEventsInfo pattern.UserAndEventsInfo
 	// If modelId is not provided respond with the userAndEventsInfo of the latest modelId.
 	var respModelId uint64
-	for r := range gatherResp {
+	for _, r := range gatherResp {
 		if r.err != nil {
 			log.WithError(r.err).Error("Error Ignoring GetUserAndEventsInfoResponse")
 			continue
@@ -360,29 +324,16 @@ func GetTotalEventCount(reqId string, projectId int64, modelId uint64) (uint64,
 		ProjectId: projectId,
 		ModelId:   modelId,
 	}
-	paramBytes, err := rpcJson.EncodeClientRequest(RPCServiceName+Separator+OperationNameGetTotalEventCount, params)
+	gatherResp, err := callPatternServers(reqId, OperationNameGetTotalEventCount, func(failover Failover) interface{} {
+		params.Failover = failover
+		return params
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	serverAddrs := C.GetServices().GetPatternServerAddresses()
-
-	gatherResp := make(chan httpResp, len(serverAddrs))
-	headers := map[string]string{
-		"content-type": "application/json",
-		"X-Req-Id":     reqId,
-	}
-
-	urls := make([]string, 0, 0)
-	for _, serverAddr := range serverAddrs {
-		url := fmt.Sprintf("http://%s%s", serverAddr, RPCEndpoint)
-		urls = append(urls, url)
-	}
-
-	httpDo(http.MethodPost, urls, paramBytes, headers, gatherResp)
-
 	var totalEventCount uint64 = 0
-	for r := range gatherResp {
+	for _, r := range gatherResp {
 		if r.err != nil {
 			log.WithError(r.err).Error("Error Ignoring GetTotalEventCountResponse")
 			continue
@@ -411,10 +362,83 @@ func GetTotalEventCount(reqId string, projectId int64, modelId uint64) (uint64,
 }
 
 type httpResp struct {
+	url  string
 	resp *http.Response
 	err  error
 }
 
+func isFailedResp(r httpResp) bool {
+	return r.err != nil || r.resp == nil || r.resp.StatusCode >= http.StatusInternalServerError
+}
+
+// callPatternServers sends the request to all the pattern servers. When some of the servers
+// fail, the request is retried on the rest, with the failover details, for the replicas of
+// the failed servers to serve their keys. Responses of the servers not failed are returned.
+func callPatternServers(reqId, operation string, getParams func(failover Failover) interface{}) ([]httpResp, error) {
+	headers := map[string]string{
+		"content-type": "application/json",
+		"X-Req-Id":     reqId,
+	}
+
+	serverAddrs := C.GetServices().GetPatternServerAddresses()
+	failover := Failover{}
+	resps := make([]httpResp, 0, len(serverAddrs))
+	for len(serverAddrs) > 0 {
+		paramBytes, err := rpcJson.EncodeClientRequest(RPCServiceName+Separator+operation, getParams(failover))
+		if err != nil {
+			return resps, err
+		}
+
+		addrByURL := make(map[string]string, len(serverAddrs))
+		urls := make([]string, 0, len(serverAddrs))
+		for _, serverAddr := range serverAddrs {
+			url := fmt.Sprintf("http://%s%s", serverAddr, RPCEndpoint)
+			addrByURL[url] = serverAddr
+			urls = append(urls, url)
+		}
+
+		gatherResp := make(chan httpResp, len(urls))
+		httpDo(http.MethodPost, urls, paramBytes, headers, gatherResp)
+
+		failedAddrs := make([]string, 0)
+		for r := range gatherResp {
+			if !isFailedResp(r) {
+				resps = append(resps, r)
+				continue
+			}
+
+			if r.resp != nil {
+				r.resp.Body.Close()
+			}
+			log.WithError(r.err).WithFields(log.Fields{"reqId": reqId, "operation": operation,
+				"server": addrByURL[r.url]}).Error("Pattern server failed. Retrying on replicas.")
+			failedAddrs = append(failedAddrs, addrByURL[r.url])
+		}
+		if len(failedAddrs) == 0 {
+			break
+		}
+
+		failedAddrsMap := make(map[string]bool, len(failedAddrs))
+		for _, addr := range failedAddrs {
+			failedAddrsMap[addr] = true
+		}
+		remainingAddrs := make([]string, 0, len(serverAddrs))
+		for _, addr := range serverAddrs {
+			if !failedAddrsMap[addr] {
+				remainingAddrs = append(remainingAddrs, addr)
+			}
+		}
+
+		serverAddrs = remainingAddrs
+		failover = Failover{
+			FailedNodes: append(failover.FailedNodes, failedAddrs...),
+			RetryNodes:  failedAddrs,
+		}
+	}
+
+	return resps, nil
+}
+
 // TODO(Ankit):
 // Do not create new httpClient for each request
 
@@ -427,7 +451,7 @@ func httpDo(method string, urls []string, paramBytes []byte, headers map[string]
 			defer func() {
 				wg.Done()
 			}()
-			resp := httpResp{}
+			resp := httpResp{url: u}
 			req, err := http.NewRequest(http.MethodPost, u, bytes.NewBuffer(paramBytes))
 			if err != nil {
 				resp.err = err
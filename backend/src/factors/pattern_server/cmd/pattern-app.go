@@ -6,9 +6,11 @@ import (
 	"factors/filestore"
 	PC "factors/pattern_client"
 	patternserver "factors/pattern_server"
+	"factors/pattern_server/membership"
 	serviceDisk "factors/services/disk"
 	serviceEtcd "factors/services/etcd"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	"flag"
 	"fmt"
 	"math"
@@ -54,20 +56,22 @@ func makeProjectModelChunkLookup(projectDatas []model.ProjectModelMetadata) map[
 }
 
 type config struct {
-	Environment   string
-	IP            string
-	RPCPort       string
-	HTTPPort      string
-	EtcdEndpoints []string
-	BucketName    string
-	DiskBaseDir   string
+	Environment    string
+	IP             string
+	RPCPort        string
+	HTTPPort       string
+	Membership     string
+	EtcdEndpoints  []string
+	MembershipFile string
+	BucketName     string
+	DiskBaseDir    string
 }
 
 func isValidEnv(env string) bool {
 	return env == Development || env == Staging || env == Production
 }
 
-func NewConfig(env, ip, rpcPort, httpPort, etcd, diskBaseDir, bucketName string) (*config, error) {
+func NewConfig(env, ip, rpcPort, httpPort, membershipType, etcd, membershipFile, diskBaseDir, bucketName string) (*config, error) {
 	if !isValidEnv(env) {
 		return nil, errors.New("Invalid Environment")
 	}
@@ -90,19 +94,29 @@ func NewConfig(env, ip, rpcPort, httpPort, etcd, diskBaseDir, bucketName string)
 		return nil, errors.New("Invalid BucketName")
 	}
 
+	if membershipType != membership.TypeEtcd && membershipType != membership.TypeStatic {
+		return nil, errors.New("Invalid Membership")
+	}
+
 	etcds := strings.Split(etcd, ",")
-	if len(etcds) == 0 {
+	if membershipType == membership.TypeEtcd && len(etcds) == 0 {
 		return nil, errors.New("Invalid EtcdEndpoints")
 	}
 
+	if membershipType == membership.TypeStatic && membershipFile == "" {
+		return nil, errors.New("Invalid MembershipFile")
+	}
+
 	c := config{
-		Environment:   env,
-		IP:            ip,
-		RPCPort:       rpcPort,
-		HTTPPort:      httpPort,
-		EtcdEndpoints: etcds,
-		DiskBaseDir:   diskBaseDir,
-		BucketName:    bucketName,
+		Environment:    env,
+		IP:             ip,
+		RPCPort:        rpcPort,
+		HTTPPort:       httpPort,
+		Membership:     membershipType,
+		EtcdEndpoints:  etcds,
+		MembershipFile: membershipFile,
+		DiskBaseDir:    diskBaseDir,
+		BucketName:     bucketName,
 	}
 
 	return &c, nil
@@ -132,10 +146,18 @@ func (c *config) GetBaseDiskDir() string {
 	return c.DiskBaseDir
 }
 
+func (c *config) GetMembership() string {
+	return c.Membership
+}
+
 func (c *config) GetEtcdEndpoints() []string {
 	return c.EtcdEndpoints
 }
 
+func (c *config) GetMembershipFile() string {
+	return c.MembershipFile
+}
+
 func (c *config) GetBucketName() string {
 	return c.BucketName
 }
@@ -144,7 +166,10 @@ func (c *config) GetBucketName() string {
 // TTL on etcd is 10 seconds.
 // Monit / Kubernetes will keep trying to restart the process, it should succeed after 10 seconds / till key expires.
 
-// ./pattern-app --env=development --ip=127.0.0.1 --ps_rpc_port=8100 --ps_http_port=8101 --etcd=localhost:2379 --disk_dir=/usr/local/var/factors/local_disk --bucket_name=/usr/local/var/factors/cloud_storage --chunk_cache_size=5 --event_info_cache_size=10 --aws_region=us-east-1 --aws_key=dummy --aws_secret=dummy --email_sender=support@factors.ai --error_reporting_interval=300
+// With --membership=static, pattern servers and project version are read from the membership file instead of etcd.
+// e.g {"pattern_servers": ["127.0.0.1:8100"], "project_version": "version1"}
+
+// ./pattern-app --env=development --ip=127.0.0.1 --ps_rpc_port=8100 --ps_http_port=8101 --etcd=localhost:2379 --disk_dir=/usr/local/var/factors/local_disk --bucket_name=/usr/local/var/factors/cloud_storage --chunk_cache_size=5 --event_info_cache_size=10 --replication_factor=1 --aws_region=us-east-1 --aws_key=dummy --aws_secret=dummy --email_sender=support@factors.ai --error_reporting_interval=300
 func main() {
 
 	env := flag.String("env", Development, "")
@@ -152,12 +177,17 @@ func main() {
 	rpc_port := flag.String("ps_rpc_port", "8100", "")
 	http_port := flag.String("ps_http_port", "8101", "")
 	etcd := flag.String("etcd", "localhost:2379", "Comma separated list of etcd endpoints localhost:2379,localhost:2378")
+	membershipType := flag.String("membership", membership.TypeEtcd, "Discovery of pattern servers with etcd or static file.")
+	membershipFile := flag.String("membership_file", "", "Json file with pattern_servers and project_version, for static membership.")
 
 	diskBaseDir := flag.String("disk_dir", "/usr/local/var/factors/local_disk", "")
 	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRoot := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	chunkCacheSize := flag.Int("chunk_cache_size", 5, "")
 	eventInfoCacheSize := flag.Int("event_info_cache_size", 10, "")
+	replicationFactor := flag.Int("replication_factor", patternserver.DefaultReplicationFactor,
+		"No.of pattern servers each chunk is served from. Queries fail over to the replicas when a server is down.")
 
 	sentryDSN := flag.String("sentry_dsn", "", "Sentry DSN")
 
@@ -172,7 +202,7 @@ func main() {
 
 	flag.Parse()
 
-	config, err := NewConfig(*env, *ip, *rpc_port, *http_port, *etcd, *diskBaseDir, *bucketName)
+	config, err := NewConfig(*env, *ip, *rpc_port, *http_port, *membershipType, *etcd, *membershipFile, *diskBaseDir, *bucketName)
 	if err != nil {
 		panic(err)
 	}
@@ -211,12 +241,14 @@ func main() {
 	log.SetFormatter(&log.JSONFormatter{})
 
 	log.WithFields(log.Fields{
-		"IP":            config.GetIP(),
-		"Port":          config.GetRPCPort(),
-		"Env":           config.GetEnvironment(),
-		"EtcdEndpoints": config.GetEtcdEndpoints(),
-		"DiskBaseDir":   config.GetBaseDiskDir(),
-		"BucketName":    config.GetBucketName(),
+		"IP":             config.GetIP(),
+		"Port":           config.GetRPCPort(),
+		"Env":            config.GetEnvironment(),
+		"Membership":     config.GetMembership(),
+		"EtcdEndpoints":  config.GetEtcdEndpoints(),
+		"MembershipFile": config.GetMembershipFile(),
+		"DiskBaseDir":    config.GetBaseDiskDir(),
+		"BucketName":     config.GetBucketName(),
 	}).Infoln("Initialising with config")
 
 	if config.IsDevelopment() {
@@ -229,16 +261,28 @@ func main() {
 	})
 
 	logCtx.WithFields(log.Fields{
-		"EtcdEndpoints": config.GetEtcdEndpoints(),
+		"Membership":     config.GetMembership(),
+		"EtcdEndpoints":  config.GetEtcdEndpoints(),
+		"MembershipFile": config.GetMembershipFile(),
 	}).Infoln("Pattern Server")
 
-	etcdClient, err := serviceEtcd.New(config.GetEtcdEndpoints())
-	if err != nil {
-		logCtx.WithError(err).Errorln("Falied to initialize etcd client")
-		panic(err)
+	var psMembership membership.Membership
+	if config.GetMembership() == membership.TypeStatic {
+		psMembership, err = membership.NewStaticMembership(config.GetMembershipFile(), membership.DefaultStaticPollInterval)
+		if err != nil {
+			logCtx.WithError(err).Errorln("Falied to initialize static membership")
+			panic(err)
+		}
+	} else {
+		etcdClient, err := serviceEtcd.New(config.GetEtcdEndpoints())
+		if err != nil {
+			logCtx.WithError(err).Errorln("Falied to initialize etcd client")
+			panic(err)
+		}
+		psMembership = membership.NewEtcdMembership(etcdClient, DefaultTTLSeconds)
 	}
 
-	if isRegistered, err := etcdClient.IsRegistered(config.GetIP(), config.GetRPCPort()); err != nil {
+	if isRegistered, err := psMembership.IsRegistered(config.GetIP(), config.GetRPCPort()); err != nil {
 		logCtx.WithError(err).Errorln("Falied to check registered status with etcd")
 		panic(err)
 	} else if isRegistered {
@@ -248,7 +292,9 @@ func main() {
 	}
 
 	var cloudManager filestore.FileManager
-	if config.IsDevelopment() {
+	if *localFSRoot != "" {
+		cloudManager = serviceLocalFS.New(*localFSRoot, config.GetBucketName())
+	} else if config.IsDevelopment() {
 		cloudManager = serviceDisk.New(config.GetBucketName())
 	} else {
 		cloudManager, err = serviceGCS.New(config.GetBucketName())
@@ -260,33 +306,21 @@ func main() {
 
 	diskManager := serviceDisk.New(config.GetBaseDiskDir())
 
-	ps, err := patternserver.New(config.GetIP(), config.GetRPCPort(), config.GetHTTPPort(), etcdClient, diskManager, cloudManager, *chunkCacheSize, *eventInfoCacheSize)
+	ps, err := patternserver.New(config.GetIP(), config.GetRPCPort(), config.GetHTTPPort(), psMembership, diskManager, cloudManager, *chunkCacheSize, *eventInfoCacheSize, *replicationFactor)
 	if err != nil {
 		logCtx.WithError(err).Errorln("Failed to init New PatternServer")
 		panic(err)
 	}
 
-	lease, err := etcdClient.GrantLease(DefaultTTLSeconds)
+	// registration with etcd is kept alive, till the process is.
+	err = ps.GetMembership().Register(config.GetIP(), config.GetRPCPort())
 	if err != nil {
-		logCtx.WithError(err).Errorln("Failed to register lease with etcd")
+		logCtx.WithError(err).Errorln("Failed to register with membership")
 		panic(err)
 	}
+	logCtx.Infoln("Success: Registered with membership")
 
-	logCtx.WithFields(log.Fields{
-		"TTL":     lease.TTL,
-		"LeaseID": lease.ID,
-	}).Infoln("Success: Registered lease with etcd")
-
-	ps.SetLeaseId(lease.ID)
-
-	err = ps.GetEtcdClient().RegisterPatternServer(config.GetIP(), config.GetRPCPort(), lease.ID)
-	if err != nil {
-		logCtx.WithError(err).Errorln("Failed to register with etcd")
-		panic(err)
-	}
-	logCtx.Infoln("Success: Registered with etcd")
-
-	patternServersRegisteredWithEtcd, err := ps.GetEtcdClient().DiscoverPatternServers()
+	patternServersRegisteredWithEtcd, err := ps.GetMembership().DiscoverPatternServers()
 	if err != nil {
 		logCtx.WithError(err).Errorln("Failed to disconver pattern servers from etcd")
 		panic(err)
@@ -301,10 +335,10 @@ func main() {
 		panic("calculate my num failed: pattern server not registered")
 	}
 
-	version, err := etcdClient.GetProjectVersion()
+	version, err := psMembership.GetProjectVersion()
 	if err != nil {
 		logCtx.WithError(err).Errorln("Failed to fetch projects metadata version from etcd")
-		err = etcdClient.SetProjectVersion(InitialDefaultMetadata)
+		err = psMembership.SetProjectVersion(InitialDefaultMetadata)
 		if err != nil {
 			logCtx.WithError(err).Errorln("Failed to set projects metadata version from etcd")
 			panic(err)
@@ -318,8 +352,6 @@ func main() {
 
 	go watchAndHandleEtcdEvents(ps, cloudManager)
 
-	go keepEtcdLeaseAlive(ps)
-
 	go runHttpStatus(ps.GetIp(), ps.GetHTTPPort(), ps, config.IsDevelopment())
 
 	addr := ps.GetIp() + ":" + ps.GetRPCPort()
@@ -332,7 +364,7 @@ func main() {
 }
 
 func handlePatternServerNodeUpdates(ps *patternserver.PatternServer) error {
-	psNodes, err := ps.GetEtcdClient().DiscoverPatternServers()
+	psNodes, err := ps.GetMembership().DiscoverPatternServers()
 	if err != nil {
 		log.WithError(err).Errorln("failed to disover pattern servers")
 		return err
@@ -377,7 +409,7 @@ func isListOfServersEqual(curNodes, newNodes []serviceEtcd.KV) bool {
 }
 
 func handleRecomputation(ps *patternserver.PatternServer) error {
-	psNodes, err := ps.GetEtcdClient().DiscoverPatternServers()
+	psNodes, err := ps.GetMembership().DiscoverPatternServers()
 	if err != nil {
 		log.WithError(err).Errorln("failed to disover pattern servers")
 		return err
@@ -427,46 +459,30 @@ func watchAndHandleEtcdEvents(ps *patternserver.PatternServer, cloudManger files
 			}
 		case psUpdateEvent := <-psUpdateChan:
 
-			if err := psUpdateEvent.Err(); err != nil {
-				logCtx.WithError(err).Errorln("psUpdateEvent Channel Received err from etcd")
+			if err := psUpdateEvent.Err; err != nil {
+				logCtx.WithError(err).Errorln("psUpdateEvent Channel Received err from membership")
 				panic(err)
 			}
 
-			if len(psUpdateEvent.Events) > 0 {
-				for _, event := range psUpdateEvent.Events {
-					logCtx.WithFields(log.Fields{
-						"UnitType": event.Type,
-						"Key":      string(event.Kv.Key),
-						"Value":    string(event.Kv.Value),
-					}).Infoln("Event Received on PatternServerUpdateChannel")
-				}
-				err := handlePatternServerNodeUpdates(ps)
-				if err != nil {
-					logCtx.WithError(err).Errorln("failed to Update list of pattern servers")
-					panic(err)
-				}
+			err := handlePatternServerNodeUpdates(ps)
+			if err != nil {
+				logCtx.WithError(err).Errorln("failed to Update list of pattern servers")
+				panic(err)
 			}
 
 		case versionFileUpdateEvent := <-versionFileUpdateChan:
 
-			if err := versionFileUpdateEvent.Err(); err != nil {
-				logCtx.WithError(err).Errorln("versionFileUpdateEvent Channel Received err from etcd")
+			if err := versionFileUpdateEvent.Err; err != nil {
+				logCtx.WithError(err).Errorln("versionFileUpdateEvent Channel Received err from membership")
 				panic(err)
 			}
 
-			for _, event := range versionFileUpdateEvent.Events {
-				logCtx.WithFields(log.Fields{
-					"UnitType": event.Type,
-					"Key":      string(event.Kv.Key),
-					"Value":    string(event.Kv.Value),
-				}).Infoln("Event Received on versionFileUpdateChan")
-				newVersion := string(event.Kv.Value)
-				logCtx.WithField("NewVersion", newVersion).Infoln("Update ProjectFileVersion")
-				err := handlerVersionFileUpdates(ps, newVersion, cloudManger)
-				if err != nil {
-					logCtx.WithError(err).Errorln("failed to Update ProjectFileVersion")
-					panic(err)
-				}
+			newVersion := versionFileUpdateEvent.Version
+			logCtx.WithField("NewVersion", newVersion).Infoln("Update ProjectFileVersion")
+			err := handlerVersionFileUpdates(ps, newVersion, cloudManger)
+			if err != nil {
+				logCtx.WithError(err).Errorln("failed to Update ProjectFileVersion")
+				panic(err)
 			}
 		}
 
@@ -484,17 +500,6 @@ func getProjectsDataFromVersion(version string, cloudManger filestore.FileManage
 	return makeProjectModelChunkLookup(modelMetadata), nil
 }
 
-func keepEtcdLeaseAlive(ps *patternserver.PatternServer) {
-	log.Println("Starting to watch on keepAliveChannel")
-	keepAliveChannel, err := ps.KeepAlive()
-	if err != nil {
-		log.WithError(err).Errorln("Error ETCD Keep Alive")
-	}
-	for {
-		<-keepAliveChannel
-	}
-}
-
 func runHttpStatus(ip, port string, ps *patternserver.PatternServer, isDev bool) {
 	r := initHttpStatusServer(isDev, ps)
 	addr := ip + ":" + port
@@ -0,0 +1,112 @@
+package hashring
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// DefaultVirtualNodes is the number of points of each node on the ring. More points
+// spread the keys evenly, at the cost of memory and lookup time.
+const DefaultVirtualNodes = 128
+
+type point struct {
+	hash uint64
+	node string
+}
+
+// Ring places keys on nodes using consistent hashing. Adding or removing a node only moves
+// the keys placed on it, instead of reshuffling all the keys as with hash % no_of_nodes.
+type Ring struct {
+	points []point
+	nodes  []string
+}
+
+// New creates the ring with the given nodes. Duplicate and empty nodes are ignored.
+func New(nodes []string, virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	ring := &Ring{
+		points: make([]point, 0, len(nodes)*virtualNodes),
+		nodes:  make([]string, 0, len(nodes)),
+	}
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		if node == "" || seen[node] {
+			continue
+		}
+		seen[node] = true
+		ring.nodes = append(ring.nodes, node)
+
+		for i := 0; i < virtualNodes; i++ {
+			ring.points = append(ring.points, point{hash: Hash(fmt.Sprintf("%s#%d", node, i)), node: node})
+		}
+	}
+
+	sort.Slice(ring.points, func(i, j int) bool {
+		if ring.points[i].hash == ring.points[j].hash {
+			return ring.points[i].node < ring.points[j].node
+		}
+		return ring.points[i].hash < ring.points[j].hash
+	})
+	return ring
+}
+
+// Hash returns the position of the string on the ring.
+func Hash(str string) uint64 {
+	f := fnv.New64a()
+	f.Write([]byte(str)) // write never returns error
+
+	// fnv doesn't spread strings differing only on the last few bytes, which is the case
+	// with the virtual nodes and the chunk keys. Finalizer of splitmix64 is used to mix it.
+	hash := f.Sum64()
+	hash ^= hash >> 30
+	hash *= 0xbf58476d1ce4e5b9
+	hash ^= hash >> 27
+	hash *= 0x94d049bb133111eb
+	hash ^= hash >> 31
+	return hash
+}
+
+// GetNodes returns the distinct nodes to place the key on, in the order of preference,
+// walking clockwise on the ring from the position of the key.
+func (ring *Ring) GetNodes(key string, replicas int) []string {
+	if replicas > len(ring.nodes) {
+		replicas = len(ring.nodes)
+	}
+	if replicas <= 0 {
+		return []string{}
+	}
+
+	hash := Hash(key)
+	start := sort.Search(len(ring.points), func(i int) bool { return ring.points[i].hash >= hash })
+
+	nodes := make([]string, 0, replicas)
+	seen := make(map[string]bool, replicas)
+	for i := 0; i < len(ring.points) && len(nodes) < replicas; i++ {
+		node := ring.points[(start+i)%len(ring.points)].node
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// GetNodesList returns the nodes on the ring.
+func (ring *Ring) GetNodesList() []string {
+	return ring.nodes
+}
+
+// GetServingNode returns the first of the nodes which is not failed, if any.
+func GetServingNode(nodes []string, failedNodes map[string]bool) (string, bool) {
+	for _, node := range nodes {
+		if !failedNodes[node] {
+			return node, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,122 @@
+package membership
+
+import (
+	serviceEtcd "factors/services/etcd"
+
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/clientv3"
+)
+
+// EtcdMembership registers the pattern server on etcd with a lease, which expires
+// in ttl seconds after the process stops keeping it alive.
+type EtcdMembership struct {
+	client     *serviceEtcd.EtcdClient
+	ttlSeconds int64
+	leaseID    clientv3.LeaseID
+}
+
+func NewEtcdMembership(client *serviceEtcd.EtcdClient, ttlSeconds int64) *EtcdMembership {
+	return &EtcdMembership{client: client, ttlSeconds: ttlSeconds}
+}
+
+func (m *EtcdMembership) GetType() string {
+	return TypeEtcd
+}
+
+func (m *EtcdMembership) GetEtcdClient() *serviceEtcd.EtcdClient {
+	return m.client
+}
+
+func (m *EtcdMembership) GetLeaseID() clientv3.LeaseID {
+	return m.leaseID
+}
+
+func (m *EtcdMembership) Register(ip, port string) error {
+	lease, err := m.client.GrantLease(m.ttlSeconds)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{"TTL": lease.TTL, "LeaseID": lease.ID}).Infoln("Success: Registered lease with etcd")
+	m.leaseID = lease.ID
+
+	if err := m.client.RegisterPatternServer(ip, port, lease.ID); err != nil {
+		return err
+	}
+
+	keepAliveChannel, err := m.client.KeepAlive(lease.ID)
+	if err != nil {
+		return err
+	}
+	go func() {
+		log.Println("Starting to watch on keepAliveChannel")
+		for {
+			<-keepAliveChannel
+		}
+	}()
+	return nil
+}
+
+func (m *EtcdMembership) IsRegistered(ip, port string) (bool, error) {
+	return m.client.IsRegistered(ip, port)
+}
+
+func (m *EtcdMembership) DiscoverPatternServers() ([]serviceEtcd.KV, error) {
+	return m.client.DiscoverPatternServers()
+}
+
+func (m *EtcdMembership) GetProjectVersion() (string, error) {
+	return m.client.GetProjectVersion()
+}
+
+func (m *EtcdMembership) SetProjectVersion(version string) error {
+	return m.client.SetProjectVersion(version)
+}
+
+func (m *EtcdMembership) WatchPatternServers() <-chan Event {
+	events := make(chan Event)
+	watchChan := m.client.Watch(serviceEtcd.PatternServerPrefix, clientv3.WithPrefix())
+	go func() {
+		for watchResp := range watchChan {
+			if err := watchResp.Err(); err != nil {
+				events <- Event{Err: err}
+				continue
+			}
+			if len(watchResp.Events) == 0 {
+				continue
+			}
+
+			for _, event := range watchResp.Events {
+				log.WithFields(log.Fields{
+					"UnitType": event.Type,
+					"Key":      string(event.Kv.Key),
+					"Value":    string(event.Kv.Value),
+				}).Infoln("Event Received on PatternServerUpdateChannel")
+			}
+			events <- Event{}
+		}
+	}()
+	return events
+}
+
+func (m *EtcdMembership) WatchProjectVersion() <-chan Event {
+	events := make(chan Event)
+	watchChan := m.client.Watch(serviceEtcd.ProjectVersionKey)
+	go func() {
+		for watchResp := range watchChan {
+			if err := watchResp.Err(); err != nil {
+				events <- Event{Err: err}
+				continue
+			}
+
+			for _, event := range watchResp.Events {
+				log.WithFields(log.Fields{
+					"UnitType": event.Type,
+					"Key":      string(event.Kv.Key),
+					"Value":    string(event.Kv.Value),
+				}).Infoln("Event Received on versionFileUpdateChan")
+				events <- Event{Version: string(event.Kv.Value)}
+			}
+		}
+	}()
+	return events
+}
@@ -0,0 +1,36 @@
+package membership
+
+import (
+	serviceEtcd "factors/services/etcd"
+)
+
+const (
+	TypeEtcd   = "etcd"
+	TypeStatic = "static"
+)
+
+// Event is sent on the watch channels, on change of the pattern server nodes
+// or the project data version. Version is set only on the project version events.
+type Event struct {
+	Err     error
+	Version string
+}
+
+// Membership discovers the pattern server nodes and the version of the project data
+// to be served by them.
+type Membership interface {
+	GetType() string
+	// Register registers the pattern server as a node and keeps it registered, till the process is alive.
+	Register(ip, port string) error
+	IsRegistered(ip, port string) (bool, error)
+	// DiscoverPatternServers returns the pattern server nodes sorted by key. Value is ip:port of the node.
+	DiscoverPatternServers() ([]serviceEtcd.KV, error)
+	GetProjectVersion() (string, error)
+	SetProjectVersion(version string) error
+	WatchPatternServers() <-chan Event
+	WatchProjectVersion() <-chan Event
+}
+
+func getNodeKey(address string) string {
+	return serviceEtcd.PatternServerPrefix + address
+}
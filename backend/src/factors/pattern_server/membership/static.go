@@ -0,0 +1,244 @@
+package membership
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	serviceEtcd "factors/services/etcd"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const DefaultStaticPollInterval = 10 * time.Second
+
+// StaticMembershipFile is the content of the membership file.
+// e.g {"pattern_servers": ["127.0.0.1:8100"], "project_version": "version1"}
+type StaticMembershipFile struct {
+	PatternServers []string `json:"pattern_servers"`
+	ProjectVersion string   `json:"project_version"`
+}
+
+// StaticMembership reads the pattern server nodes and the project data version from a local
+// file, polled for changes. It is used to run pattern servers without etcd, on development,
+// tests or single node setups. Nodes can't register themselves, they have to be on the file.
+type StaticMembership struct {
+	filePath     string
+	pollInterval time.Duration
+
+	lock           sync.RWMutex
+	file           StaticMembershipFile
+	fileModTime    time.Time
+	projectVersion string
+	// version on the file, last notified to the watchers.
+	lastWatchedVersion string
+
+	watchOnce       sync.Once
+	watchersLock    sync.Mutex
+	nodesWatchers   []chan Event
+	versionWatchers []chan Event
+}
+
+func NewStaticMembership(filePath string, pollInterval time.Duration) (*StaticMembership, error) {
+	if filePath == "" {
+		return nil, errors.New("membership file path is empty")
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultStaticPollInterval
+	}
+
+	m := &StaticMembership{filePath: filePath, pollInterval: pollInterval}
+	file, modTime, err := readStaticMembershipFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	m.file, m.fileModTime, m.projectVersion = file, modTime, file.ProjectVersion
+	m.lastWatchedVersion = file.ProjectVersion
+	return m, nil
+}
+
+func readStaticMembershipFile(filePath string) (StaticMembershipFile, time.Time, error) {
+	var file StaticMembershipFile
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return file, time.Time{}, err
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return file, time.Time{}, err
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return file, time.Time{}, fmt.Errorf("invalid membership file %s: %v", filePath, err)
+	}
+	return file, fileInfo.ModTime(), nil
+}
+
+func (m *StaticMembership) GetType() string {
+	return TypeStatic
+}
+
+// Register only checks the node is on the file.
+func (m *StaticMembership) Register(ip, port string) error {
+	address := ip + ":" + port
+	for _, node := range m.getFile().PatternServers {
+		if node == address {
+			return nil
+		}
+	}
+	return fmt.Errorf("pattern server %s is not on the membership file %s", address, m.filePath)
+}
+
+// IsRegistered is always false, as the nodes on the file are not known to be running.
+func (m *StaticMembership) IsRegistered(ip, port string) (bool, error) {
+	return false, nil
+}
+
+func (m *StaticMembership) DiscoverPatternServers() ([]serviceEtcd.KV, error) {
+	return getStaticNodes(m.getFile().PatternServers), nil
+}
+
+func getStaticNodes(addresses []string) []serviceEtcd.KV {
+	nodes := make([]serviceEtcd.KV, 0, len(addresses))
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		nodes = append(nodes, serviceEtcd.KV{Key: getNodeKey(address), Value: address})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Key < nodes[j].Key })
+	return nodes
+}
+
+func (m *StaticMembership) GetProjectVersion() (string, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.projectVersion == "" {
+		return "", serviceEtcd.NotFound
+	}
+	return m.projectVersion, nil
+}
+
+// SetProjectVersion sets the version in memory, till it is changed on the file.
+func (m *StaticMembership) SetProjectVersion(version string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.projectVersion = version
+	return nil
+}
+
+func (m *StaticMembership) getFile() StaticMembershipFile {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.file
+}
+
+func (m *StaticMembership) WatchPatternServers() <-chan Event {
+	events := make(chan Event, 1)
+	m.watchersLock.Lock()
+	m.nodesWatchers = append(m.nodesWatchers, events)
+	m.watchersLock.Unlock()
+
+	m.watchOnce.Do(func() { go m.pollFile() })
+	return events
+}
+
+func (m *StaticMembership) WatchProjectVersion() <-chan Event {
+	events := make(chan Event, 1)
+	m.watchersLock.Lock()
+	m.versionWatchers = append(m.versionWatchers, events)
+	m.watchersLock.Unlock()
+
+	m.watchOnce.Do(func() { go m.pollFile() })
+	return events
+}
+
+func (m *StaticMembership) pollFile() {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.checkFileForChanges()
+	}
+}
+
+// checkFileForChanges reloads the file, if modified, and notifies the watchers on the changes.
+// A file which can't be read is logged and skipped, previous content is used till it is fixed.
+func (m *StaticMembership) checkFileForChanges() {
+	logCtx := log.WithField("file", m.filePath)
+
+	fileInfo, err := os.Stat(m.filePath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to stat membership file.")
+		return
+	}
+
+	m.lock.RLock()
+	isModified := !fileInfo.ModTime().Equal(m.fileModTime)
+	m.lock.RUnlock()
+	if !isModified {
+		return
+	}
+
+	file, modTime, err := readStaticMembershipFile(m.filePath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to read membership file.")
+		return
+	}
+
+	m.lock.Lock()
+	previousFile := m.file
+	m.file, m.fileModTime = file, modTime
+	isVersionChanged := file.ProjectVersion != "" && file.ProjectVersion != m.lastWatchedVersion
+	if isVersionChanged {
+		m.projectVersion = file.ProjectVersion
+		m.lastWatchedVersion = file.ProjectVersion
+	}
+	m.lock.Unlock()
+
+	m.watchersLock.Lock()
+	nodesWatchers := append([]chan Event{}, m.nodesWatchers...)
+	versionWatchers := append([]chan Event{}, m.versionWatchers...)
+	m.watchersLock.Unlock()
+
+	if !reflect.DeepEqual(getStaticNodes(previousFile.PatternServers), getStaticNodes(file.PatternServers)) {
+		logCtx.WithField("pattern_servers", file.PatternServers).Info("Pattern servers changed on membership file.")
+		for _, watcher := range nodesWatchers {
+			notifyWatcher(watcher, Event{})
+		}
+	}
+	if isVersionChanged {
+		logCtx.WithField("project_version", file.ProjectVersion).Info("Project version changed on membership file.")
+		for _, watcher := range versionWatchers {
+			notifyWatcher(watcher, Event{Version: file.ProjectVersion})
+		}
+	}
+}
+
+// notifyWatcher sends the event without blocking on a watcher which is not reading. An event
+// not read yet is replaced, so the watcher gets the latest one.
+func notifyWatcher(watcher chan Event, event Event) {
+	for {
+		select {
+		case watcher <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-watcher:
+		default:
+		}
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"factors/filestore"
 	"factors/pattern"
 	client "factors/pattern_client"
+	"factors/pattern_server/hashring"
+	"factors/pattern_server/membership"
 	store "factors/pattern_server/store"
 	serviceEtcd "factors/services/etcd"
 	"fmt"
-	"hash/fnv"
 	"net/http"
 	"strings"
 	"sync"
@@ -17,12 +18,13 @@ import (
 	"github.com/gin-gonic/gin"
 	E "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"go.etcd.io/etcd/clientv3"
 )
 
 const (
 	IdSeparator           = ":"
 	PatternServerNotFound = -1
+
+	DefaultReplicationFactor = 1
 )
 
 type ModelData struct {
@@ -39,6 +41,9 @@ type state struct {
 	patternServerNodes []serviceEtcd.KV
 	myNum              int
 
+	// ring of the pattern server nodes, keys are placed on it with the replication factor.
+	ring *hashring.Ring
+
 	projectDataVersion string
 
 	// project_id -> ModelChunkMapping
@@ -62,6 +67,10 @@ func (s *state) getMyNum() int {
 	return s.myNum
 }
 
+func (s *state) getRing() *hashring.Ring {
+	return s.ring
+}
+
 func (s *state) getProjectDataVersion() string {
 	return s.projectDataVersion
 }
@@ -83,11 +92,13 @@ func (s *state) getProjectModelChunksToServe() map[string]bool {
 }
 
 type PatternServer struct {
-	ip          string
-	rpcPort     string
-	httpPort    string
-	etcdLeaseID clientv3.LeaseID
-	etcdClient  *serviceEtcd.EtcdClient
+	ip         string
+	rpcPort    string
+	httpPort   string
+	membership membership.Membership
+
+	// no.of pattern servers, each project, model and chunk is served from.
+	replicationFactor int
 
 	stateLock sync.RWMutex
 	state     *state
@@ -95,7 +106,7 @@ type PatternServer struct {
 	store *store.PatternStore
 }
 
-func New(ip, rpcPort, httpPort string, etcdClient *serviceEtcd.EtcdClient, diskFileManager, cloudFileManger filestore.FileManager, chunkCacheSize, eventInfoCacheSize int) (*PatternServer, error) {
+func New(ip, rpcPort, httpPort string, membership membership.Membership, diskFileManager, cloudFileManger filestore.FileManager, chunkCacheSize, eventInfoCacheSize, replicationFactor int) (*PatternServer, error) {
 
 	store, err := store.New(chunkCacheSize, eventInfoCacheSize, diskFileManager, cloudFileManger)
 	if err != nil {
@@ -104,16 +115,22 @@ func New(ip, rpcPort, httpPort string, etcdClient *serviceEtcd.EtcdClient, diskF
 
 	state := &state{
 		myNum:                  -1,
+		ring:                   hashring.New([]string{}, hashring.DefaultVirtualNodes),
 		projectsModelChunkData: make(map[int64]ModelChunkMapping),
 	}
 
+	if replicationFactor <= 0 {
+		replicationFactor = DefaultReplicationFactor
+	}
+
 	ps := &PatternServer{
-		ip:         ip,
-		rpcPort:    rpcPort,
-		httpPort:   httpPort,
-		state:      state,
-		etcdClient: etcdClient,
-		store:      store,
+		ip:                ip,
+		rpcPort:           rpcPort,
+		httpPort:          httpPort,
+		state:             state,
+		membership:        membership,
+		replicationFactor: replicationFactor,
+		store:             store,
 	}
 
 	return ps, nil
@@ -123,16 +140,24 @@ func (ps *PatternServer) SetState(myNum int, psNodes []serviceEtcd.KV, projectDa
 
 	log.Debugln("Computing New State")
 
+	nodes := make([]string, 0, len(psNodes))
+	for _, node := range psNodes {
+		nodes = append(nodes, node.Value)
+	}
+
 	newState := state{
 		myNum:                  myNum,
 		patternServerNodes:     psNodes,
+		ring:                   hashring.New(nodes, hashring.DefaultVirtualNodes),
 		projectDataVersion:     projectDataVersion,
 		projectsModelChunkData: projectsModelChunkData,
 	}
 
-	projectsToServe := computeProjectsToServe(newState.projectsModelChunkData, uint64(newState.myNum), uint64(len(newState.patternServerNodes)))
-	modelsToServe := computeModelsToServe(newState.projectsModelChunkData, uint64(newState.myNum), uint64(len(newState.patternServerNodes)))
-	chunksToServe := computeChunksToServe(newState.projectsModelChunkData, uint64(newState.myNum), uint64(len(newState.patternServerNodes)))
+	myAddr := ps.GetAddr()
+	replicationFactor := ps.GetReplicationFactor()
+	projectsToServe := computeProjectsToServe(newState.projectsModelChunkData, newState.ring, myAddr, replicationFactor)
+	modelsToServe := computeModelsToServe(newState.projectsModelChunkData, newState.ring, myAddr, replicationFactor)
+	chunksToServe := computeChunksToServe(newState.projectsModelChunkData, newState.ring, myAddr, replicationFactor)
 
 	ps.stateLock.Lock()
 	defer ps.stateLock.Unlock()
@@ -160,35 +185,37 @@ func (ps *PatternServer) GetState() *state {
 	return ps.state
 }
 
-func computeProjectsToServe(projectDatas map[int64]ModelChunkMapping, myNum, noOfPatternServers uint64) map[int64]bool {
+func computeProjectsToServe(projectDatas map[int64]ModelChunkMapping, ring *hashring.Ring, myAddr string, replicationFactor int) map[int64]bool {
 	projectsToServe := make(map[int64]bool)
 	for projectId := range projectDatas {
-		if serveProject(projectId, myNum, noOfPatternServers) {
+		if isReplicaOfKey(fmt.Sprintf("%v", projectId), ring, myAddr, replicationFactor) {
 			projectsToServe[projectId] = true
 		}
 	}
 	return projectsToServe
 }
 
-func computeModelsToServe(projectDatas map[int64]ModelChunkMapping, myNum, noOfPatternServers uint64) map[string]bool {
+func computeModelsToServe(projectDatas map[int64]ModelChunkMapping, ring *hashring.Ring, myAddr string, replicationFactor int) map[string]bool {
 	modelsToServe := make(map[string]bool)
 	for projectId, pD := range projectDatas {
 		for modelId := range pD {
-			if serveProjectModel(projectId, modelId, myNum, noOfPatternServers) {
-				modelsToServe[GetModelKey(projectId, modelId)] = true
+			modelKey := GetModelKey(projectId, modelId)
+			if isReplicaOfKey(modelKey, ring, myAddr, replicationFactor) {
+				modelsToServe[modelKey] = true
 			}
 		}
 	}
 	return modelsToServe
 }
 
-func computeChunksToServe(projectDatas map[int64]ModelChunkMapping, myNum, noOfPatternServers uint64) map[string]bool {
+func computeChunksToServe(projectDatas map[int64]ModelChunkMapping, ring *hashring.Ring, myAddr string, replicationFactor int) map[string]bool {
 	chunksToServe := make(map[string]bool)
 	for projectId, pd := range projectDatas {
 		for modelId, modelData := range pd {
 			for _, chunk := range modelData.Chunks {
-				if serveProjectModelChunk(projectId, modelId, chunk, myNum, noOfPatternServers) {
-					chunksToServe[GetChunkKey(projectId, modelId, chunk)] = true
+				chunkKey := GetChunkKey(projectId, modelId, chunk)
+				if isReplicaOfKey(chunkKey, ring, myAddr, replicationFactor) {
+					chunksToServe[chunkKey] = true
 				}
 			}
 		}
@@ -196,31 +223,41 @@ func computeChunksToServe(projectDatas map[int64]ModelChunkMapping, myNum, noOfP
 	return chunksToServe
 }
 
-func generateHashNum(str string) uint64 {
-	f := fnv.New64a()
-	_, err := f.Write([]byte(str)) // write never returns error
-	if err != nil {
-		log.WithError(err).WithField("str", str).Errorln("Failed to generate hash num for string")
+func isReplicaOfKey(key string, ring *hashring.Ring, myAddr string, replicationFactor int) bool {
+	for _, node := range ring.GetNodes(key, replicationFactor) {
+		if node == myAddr {
+			return true
+		}
 	}
-	return f.Sum64()
+	return false
 }
 
-func serveProject(projectId int64, myNum, noOfPatternServers uint64) bool {
-	str := fmt.Sprintf("%v", projectId)
-	hashN := generateHashNum(str)
-	return hashN%noOfPatternServers == myNum%noOfPatternServers
-}
+// isKeyServableOnRequest tells if the key has to be served by this server for the request. Out of the
+// replicas of the key, it is served by the first one not failed. On a failover request, only the keys
+// which were to be served by the failed servers on the last attempt are served, rest are served already.
+func (ps *PatternServer) isKeyServableOnRequest(key string, failover client.Failover) bool {
+	replicas := ps.GetState().getRing().GetNodes(key, ps.GetReplicationFactor())
 
-func serveProjectModel(projectId int64, modelId, myNum, noOfPatternServers uint64) bool {
-	str := GetModelKey(projectId, modelId)
-	hashN := generateHashNum(str)
-	return hashN%noOfPatternServers == myNum%noOfPatternServers
-}
+	failedNodes := make(map[string]bool, len(failover.FailedNodes))
+	for _, node := range failover.FailedNodes {
+		failedNodes[node] = true
+	}
+	servingNode, exists := hashring.GetServingNode(replicas, failedNodes)
+	if !exists || servingNode != ps.GetAddr() {
+		return false
+	}
+
+	if len(failover.RetryNodes) == 0 {
+		return true
+	}
 
-func serveProjectModelChunk(projectId int64, modelId uint64, chunkId string, myNum, noOfPatternServers uint64) bool {
-	str := GetChunkKey(projectId, modelId, chunkId)
-	hashN := generateHashNum(str)
-	return hashN%noOfPatternServers == myNum%noOfPatternServers
+	retryNodes := make(map[string]bool, len(failover.RetryNodes))
+	for _, node := range failover.RetryNodes {
+		retryNodes[node] = true
+		delete(failedNodes, node)
+	}
+	lastServingNode, exists := hashring.GetServingNode(replicas, failedNodes)
+	return exists && retryNodes[lastServingNode]
 }
 
 func (ps *PatternServer) GetProjectModelChunks(projectId int64, modelId uint64) ([]string, bool) {
@@ -252,24 +289,12 @@ func (ps *PatternServer) GetProjectDataVersion() string {
 	return ps.GetState().getProjectDataVersion()
 }
 
-func (ps *PatternServer) WatchPatternServers() clientv3.WatchChan {
-	return ps.etcdClient.Watch(serviceEtcd.PatternServerPrefix, clientv3.WithPrefix())
-}
-
-func (ps *PatternServer) WatchProjectsFile() clientv3.WatchChan {
-	return ps.etcdClient.Watch(serviceEtcd.ProjectVersionKey)
-}
-
-func (ps *PatternServer) KeepAlive() (<-chan *clientv3.LeaseKeepAliveResponse, error) {
-	return ps.etcdClient.KeepAlive(ps.etcdLeaseID)
-}
-
-func (ps *PatternServer) SetLeaseId(leaseId clientv3.LeaseID) {
-	ps.etcdLeaseID = leaseId
+func (ps *PatternServer) WatchPatternServers() <-chan membership.Event {
+	return ps.membership.WatchPatternServers()
 }
 
-func (ps *PatternServer) GetLeaseId() clientv3.LeaseID {
-	return ps.etcdLeaseID
+func (ps *PatternServer) WatchProjectsFile() <-chan membership.Event {
+	return ps.membership.WatchProjectVersion()
 }
 
 func (ps *PatternServer) GetMyNum() int {
@@ -284,8 +309,8 @@ func (ps *PatternServer) GetNoOfPatternNodes() int {
 	return len(ps.GetState().getPatternServerNodes())
 }
 
-func (ps *PatternServer) GetEtcdClient() *serviceEtcd.EtcdClient {
-	return ps.etcdClient
+func (ps *PatternServer) GetMembership() membership.Membership {
+	return ps.membership
 }
 
 func (ps *PatternServer) GetIp() string {
@@ -300,6 +325,18 @@ func (ps *PatternServer) GetHTTPPort() string {
 	return ps.httpPort
 }
 
+// GetAddr returns the address the pattern server is registered with, as a node on the ring.
+func (ps *PatternServer) GetAddr() string {
+	return ps.ip + ":" + ps.rpcPort
+}
+
+func (ps *PatternServer) GetReplicationFactor() int {
+	if ps.replicationFactor <= 0 {
+		return DefaultReplicationFactor
+	}
+	return ps.replicationFactor
+}
+
 func (ps *PatternServer) GetProjectModelsToServe() map[string]bool {
 	return ps.GetState().getProjectModelsToServe()
 }
@@ -327,6 +364,18 @@ func (ps *PatternServer) IsProjectModelChunkServable(projectId int64, modelId ui
 	return val && exists
 }
 
+// IsProjectModelServableOnRequest tells if the model is to be served on the request, out of its replicas.
+func (ps *PatternServer) IsProjectModelServableOnRequest(projectId int64, modelId uint64, failover client.Failover) bool {
+	return ps.IsProjectModelServable(projectId, modelId) &&
+		ps.isKeyServableOnRequest(GetModelKey(projectId, modelId), failover)
+}
+
+// IsProjectModelChunkServableOnRequest tells if the chunk is to be served on the request, out of its replicas.
+func (ps *PatternServer) IsProjectModelChunkServableOnRequest(projectId int64, modelId uint64, chunkId string, failover client.Failover) bool {
+	return ps.IsProjectModelChunkServable(projectId, modelId, chunkId) &&
+		ps.isKeyServableOnRequest(GetChunkKey(projectId, modelId, chunkId), failover)
+}
+
 func (ps *PatternServer) GetModelEventInfo(projectId int64, modelId uint64) (pattern.UserAndEventsInfo, error) {
 	return ps.store.GetModelEventInfo(projectId, modelId)
 }
@@ -355,7 +404,8 @@ func (ps *PatternServer) DebugState(c *gin.Context) {
 		"data": map[string]interface{}{
 			"num":                           ps.GetMyNum(),
 			"ip":                            ps.GetIp(),
-			"etcd_lease_id":                 ps.GetLeaseId(),
+			"replication_factor":            ps.GetReplicationFactor(),
+			"membership":                    ps.GetMembership().GetType(),
 			"project_data_version":          ps.GetProjectDataVersion(),
 			"projects_to_serve":             ps.GetState().getProjectsToServe(),
 			"project_models_to_serve":       ps.GetState().getProjectModelsToServe(),
@@ -100,7 +100,7 @@ func (ps *PatternServer) GetAllPatterns(
 
 	chunksToServe := make([]string, 0, 0)
 	for _, chunkId := range chunkIds {
-		if ps.IsProjectModelChunkServable(args.ProjectId, modelId, chunkId) {
+		if ps.IsProjectModelChunkServableOnRequest(args.ProjectId, modelId, chunkId, args.Failover) {
 			chunksToServe = append(chunksToServe, chunkId)
 		}
 	}
@@ -208,7 +208,7 @@ func (ps *PatternServer) GetAllContainingPatterns(
 
 	chunksToServe := make([]string, 0, 0)
 	for _, chunkId := range chunkIds {
-		if ps.IsProjectModelChunkServable(args.ProjectId, modelId, chunkId) {
+		if ps.IsProjectModelChunkServableOnRequest(args.ProjectId, modelId, chunkId, args.Failover) {
 			chunksToServe = append(chunksToServe, chunkId)
 		}
 	}
@@ -299,7 +299,7 @@ func (ps *PatternServer) GetPatterns(
 
 	chunksToServe := make([]string, 0, 0)
 	for _, chunkId := range chunkIds {
-		if ps.IsProjectModelChunkServable(args.ProjectId, modelId, chunkId) {
+		if ps.IsProjectModelChunkServableOnRequest(args.ProjectId, modelId, chunkId, args.Failover) {
 			chunksToServe = append(chunksToServe, chunkId)
 		}
 	}
@@ -360,7 +360,7 @@ func (ps *PatternServer) GetUserAndEventsInfo(
 		modelId = latestInterval.ModelId
 	}
 
-	if !ps.IsProjectModelServable(args.ProjectId, modelId) {
+	if !ps.IsProjectModelServableOnRequest(args.ProjectId, modelId, args.Failover) {
 		result.Ignored = true
 		return nil
 	}
@@ -426,7 +426,7 @@ func (ps *PatternServer) GetTotalEventCount(
 
 	chunksToServe := make([]string, 0, 0)
 	for _, chunkId := range chunkIds {
-		if ps.IsProjectModelChunkServable(args.ProjectId, modelId, chunkId) {
+		if ps.IsProjectModelChunkServableOnRequest(args.ProjectId, modelId, chunkId, args.Failover) {
 			chunksToServe = append(chunksToServe, chunkId)
 		}
 	}
@@ -0,0 +1,31 @@
+package quickchart
+
+import (
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// regularFont is the Go Regular TTF embedded on the binary, to render the same
+// text on any host, without depending on the fonts installed.
+var regularFont = mustParseFont(goregular.TTF)
+
+func mustParseFont(ttf []byte) *sfnt.Font {
+	parsedFont, err := opentype.Parse(ttf)
+	if err != nil {
+		panic("failed to parse the embedded chart font: " + err.Error())
+	}
+	return parsedFont
+}
+
+// newFontFace returns the face of the font for the size in pixels.
+// Face is not safe for concurrent use, it is created for each canvas.
+func newFontFace(sizeInPixels float64) (font.Face, error) {
+	// size in points is same as the size in pixels on 72 DPI.
+	return opentype.NewFace(regularFont, &opentype.FaceOptions{
+		Size:    sizeInPixels,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
@@ -0,0 +1,126 @@
+package quickchart
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	C "factors/config"
+	U "factors/util"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ROUTE_CHART_IMAGES = "/charts/images"
+	// links to the images on the older reports stop working after expiry.
+	IMAGE_URL_EXPIRY_IN_SECS = 30 * 24 * 60 * 60
+)
+
+// GetChartImageURL renders the chart, stores the image and returns the signed url to it.
+func GetChartImageURL(projectID int64, config ChartConfig, format string) (string, error) {
+	image, err := RenderChart(config, format)
+	if err != nil {
+		return "", err
+	}
+	return storeImageAndGetSignedURL(projectID, image, format)
+}
+
+// GetTableImageURL renders the table, stores the image and returns the signed url to it.
+func GetTableImageURL(projectID int64, config TableConfig, format string) (string, error) {
+	image, err := RenderTable(config, format)
+	if err != nil {
+		return "", err
+	}
+	return storeImageAndGetSignedURL(projectID, image, format)
+}
+
+func storeImageAndGetSignedURL(projectID int64, image []byte, format string) (string, error) {
+	if C.GetChartImageURLSigningKey() == "" {
+		return "", errors.New("signing key for chart image urls is not configured")
+	}
+	cloudManager := C.GetCloudManager()
+	if cloudManager == nil {
+		return "", errors.New("file manager is not initialised for chart images")
+	}
+
+	// creation time on the name is used for deleting the images after the expiry of the url.
+	createdAt := U.TimeNowUnix()
+	imageName := fmt.Sprintf("%d_%s.%s", createdAt, U.GetUUID(), format)
+	path, name := cloudManager.GetChartImageFilePathAndName(projectID, imageName)
+	if err := cloudManager.Create(path, name, bytes.NewReader(image)); err != nil {
+		return "", err
+	}
+	return GetSignedImageURL(projectID, imageName, createdAt+IMAGE_URL_EXPIRY_IN_SECS), nil
+}
+
+// DeleteExpiredChartImages deletes the images of the project, which are not accessible
+// anymore as the urls to them are expired. Returns the no.of images deleted.
+func DeleteExpiredChartImages(projectID int64) (int, error) {
+	cloudManager := C.GetCloudManager()
+	if cloudManager == nil {
+		return 0, errors.New("file manager is not initialised for chart images")
+	}
+
+	path, _ := cloudManager.GetChartImageFilePathAndName(projectID, "")
+	deleted := 0
+	for _, file := range cloudManager.ListFiles(path) {
+		imageName := file[strings.LastIndex(file, "/")+1:]
+		createdAt, exists := getImageCreatedAt(imageName)
+		if !exists || createdAt+IMAGE_URL_EXPIRY_IN_SECS >= U.TimeNowUnix() {
+			continue
+		}
+
+		if err := cloudManager.Delete(path, imageName); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// getImageCreatedAt returns the creation time from the name of the image.
+func getImageCreatedAt(imageName string) (int64, bool) {
+	index := strings.Index(imageName, "_")
+	if index <= 0 {
+		return 0, false
+	}
+	createdAt, err := strconv.ParseInt(imageName[:index], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return createdAt, true
+}
+
+// GetSignedImageURL returns the url of the image on the app, valid till the expiry.
+func GetSignedImageURL(projectID int64, imageName string, expiresAt int64) string {
+	return fmt.Sprintf("%s%s%s/%d/%s?expires=%d&signature=%s", C.GetProtocol(), C.GetAPIDomain(), ROUTE_CHART_IMAGES,
+		projectID, imageName, expiresAt, GetImageURLSignature(projectID, imageName, expiresAt))
+}
+
+func GetImageURLSignature(projectID int64, imageName string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(C.GetChartImageURLSigningKey()))
+	mac.Write([]byte(fmt.Sprintf("%d:%s:%d", projectID, imageName, expiresAt)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsValidImageURLSignature checks the signature of the image url and that it is not expired.
+func IsValidImageURLSignature(projectID int64, imageName string, expiresAt int64, signature string) bool {
+	if C.GetChartImageURLSigningKey() == "" || expiresAt < U.TimeNowUnix() {
+		return false
+	}
+	expectedSignature := GetImageURLSignature(projectID, imageName, expiresAt)
+	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+}
+
+// GetImageFormat returns the format of the stored image by the extension of the name.
+func GetImageFormat(imageName string) (string, bool) {
+	for _, format := range []string{IMAGE_FORMAT_PNG, IMAGE_FORMAT_SVG} {
+		if strings.HasSuffix(imageName, "."+format) {
+			return format, true
+		}
+	}
+	return "", false
+}
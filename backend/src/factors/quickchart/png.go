@@ -0,0 +1,112 @@
+package quickchart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// PNG is drawn at twice the size, to stay sharp on high density screens.
+const pngPixelRatio = 2
+
+type pngCanvas struct {
+	image *image.RGBA
+	// font faces by the font size.
+	fontFaces map[float64]font.Face
+}
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	c := &pngCanvas{
+		image:     image.NewRGBA(image.Rect(0, 0, width*pngPixelRatio, height*pngPixelRatio)),
+		fontFaces: make(map[float64]font.Face),
+	}
+	draw.Draw(c.image, c.image.Bounds(), &image.Uniform{C: colorWhite}, image.Point{}, draw.Src)
+	return c
+}
+
+func (c *pngCanvas) fillPixels(x, y, width, height float64, fill color.RGBA) {
+	rect := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+width)), int(math.Round(y+height)))
+	draw.Draw(c.image, rect, &image.Uniform{C: fill}, image.Point{}, draw.Src)
+}
+
+func (c *pngCanvas) FillRect(x, y, width, height float64, fill color.RGBA) {
+	c.fillPixels(x*pngPixelRatio, y*pngPixelRatio, width*pngPixelRatio, height*pngPixelRatio, fill)
+}
+
+// Line is drawn by stamping squares of the stroke width along the line.
+func (c *pngCanvas) Line(x1, y1, x2, y2 float64, stroke color.RGBA, strokeWidth float64) {
+	x1, y1, x2, y2 = x1*pngPixelRatio, y1*pngPixelRatio, x2*pngPixelRatio, y2*pngPixelRatio
+	size := math.Max(strokeWidth*pngPixelRatio, 1)
+	steps := int(math.Ceil(math.Max(math.Abs(x2-x1), math.Abs(y2-y1))))
+	for step := 0; step <= steps; step++ {
+		ratio := 0.0
+		if steps > 0 {
+			ratio = float64(step) / float64(steps)
+		}
+		x, y := x1+(x2-x1)*ratio, y1+(y2-y1)*ratio
+		c.fillPixels(x-size/2, y-size/2, size, size, stroke)
+	}
+}
+
+func (c *pngCanvas) Text(x, y float64, text string, size float64, fill color.RGBA, anchor string) {
+	face := c.getFontFace(size)
+	if face == nil {
+		return
+	}
+
+	startX := x * pngPixelRatio
+	switch anchor {
+	case "middle":
+		startX -= c.TextWidth(text, size) * pngPixelRatio / 2
+	case "end":
+		startX -= c.TextWidth(text, size) * pngPixelRatio
+	}
+	// baseline is placed for the text between the ascent and the descent to be centered on y.
+	metrics := face.Metrics()
+	baselineY := y*pngPixelRatio + float64(metrics.Ascent-metrics.Descent)/2/64
+
+	drawer := &font.Drawer{
+		Dst:  c.image,
+		Src:  image.NewUniform(fill),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(math.Round(startX * 64)), Y: fixed.Int26_6(math.Round(baselineY * 64))},
+	}
+	drawer.DrawString(text)
+}
+
+func (c *pngCanvas) TextWidth(text string, size float64) float64 {
+	face := c.getFontFace(size)
+	if face == nil {
+		return 0
+	}
+	return float64(font.MeasureString(face, text)) / 64 / pngPixelRatio
+}
+
+func (c *pngCanvas) Encode() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, c.image); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (c *pngCanvas) getFontFace(size float64) font.Face {
+	if face, exists := c.fontFaces[size]; exists {
+		return face
+	}
+
+	face, err := newFontFace(size * pngPixelRatio)
+	if err != nil {
+		log.WithError(err).WithField("size", size).Error("Failed to create font face of the chart font.")
+		return nil
+	}
+	c.fontFaces[size] = face
+	return face
+}
@@ -0,0 +1,391 @@
+package quickchart
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	IMAGE_FORMAT_PNG = "png"
+	IMAGE_FORMAT_SVG = "svg"
+)
+
+const (
+	chartWidth       = 800
+	chartHeight      = 400
+	imagePadding     = 16
+	chartFontSize    = 12
+	titleFontSize    = 16
+	legendBoxSize    = 12
+	maxYTicks        = 5
+	maxLabelLength   = 18
+	maxLegendWidth   = 320
+	tableMaxWidth    = 1200
+	tableRowHeight   = 28
+	tableTitleHeight = 40
+	tableCellPadding = 10
+	tableMinWidth    = 400
+	tableMaxColWidth = 300
+)
+
+var (
+	colorWhite     = color.RGBA{255, 255, 255, 255}
+	colorText      = color.RGBA{51, 51, 51, 255}
+	colorLightText = color.RGBA{115, 115, 115, 255}
+	colorGrid      = color.RGBA{230, 230, 230, 255}
+	colorAxis      = color.RGBA{191, 191, 191, 255}
+	colorHeader    = color.RGBA{242, 242, 242, 255}
+	colorStripe    = color.RGBA{250, 250, 250, 255}
+
+	chartColors = []color.RGBA{
+		{91, 143, 249, 255}, {90, 216, 166, 255}, {246, 189, 22, 255}, {232, 104, 74, 255},
+		{109, 200, 236, 255}, {146, 112, 202, 255}, {255, 157, 77, 255}, {38, 154, 153, 255},
+	}
+)
+
+// canvas is drawn by the chart and the table renderers. It is implemented for each of the image formats.
+type canvas interface {
+	FillRect(x, y, width, height float64, fill color.RGBA)
+	Line(x1, y1, x2, y2 float64, stroke color.RGBA, strokeWidth float64)
+	// Text is drawn vertically centered on y. Anchor is one of start, middle or end.
+	Text(x, y float64, text string, size float64, fill color.RGBA, anchor string)
+	TextWidth(text string, size float64) float64
+	Encode() ([]byte, error)
+}
+
+func newCanvas(format string, width, height int) (canvas, error) {
+	switch format {
+	case IMAGE_FORMAT_PNG:
+		return newPNGCanvas(width, height), nil
+	case IMAGE_FORMAT_SVG:
+		return newSVGCanvas(width, height), nil
+	default:
+		return nil, fmt.Errorf("invalid image format %s", format)
+	}
+}
+
+// GetImageContentType returns the content type of the image format.
+func GetImageContentType(format string) string {
+	if format == IMAGE_FORMAT_SVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+/*
+RenderChart draws the line or bar chart of the config, without calling any external service.
+ 1. Labels of the config are drawn on the x axis, timestamps are shortened to the date.
+ 2. Values of the datasets are scaled on the y axis with rounded ticks.
+ 3. Labels of the datasets are drawn as legend on the top.
+*/
+func RenderChart(config ChartConfig, format string) ([]byte, error) {
+	if len(config.Data.DataSets) == 0 {
+		return nil, errors.New("no datasets on chart config")
+	}
+	c, err := newCanvas(format, chartWidth, chartHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	// legend
+	legendX, legendY := float64(imagePadding), float64(imagePadding+legendBoxSize/2)
+	for index, dataSet := range config.Data.DataSets {
+		label := truncateText(c, dataSet.Label, chartFontSize, maxLegendWidth)
+		itemWidth := legendBoxSize + 6 + c.TextWidth(label, chartFontSize) + 16
+		if legendX+itemWidth > chartWidth-imagePadding && legendX > imagePadding {
+			legendX = imagePadding
+			legendY += legendBoxSize + 8
+		}
+		c.FillRect(legendX, legendY-legendBoxSize/2, legendBoxSize, legendBoxSize, getChartColor(index))
+		c.Text(legendX+legendBoxSize+6, legendY, label, chartFontSize, colorText, "start")
+		legendX += itemWidth
+	}
+
+	values := make([][]float64, len(config.Data.DataSets))
+	numPoints := len(config.Data.Labels)
+	minValue, maxValue := 0.0, 0.0
+	for index, dataSet := range config.Data.DataSets {
+		values[index] = make([]float64, len(dataSet.Data))
+		for pointIndex, data := range dataSet.Data {
+			value, ok := getFloatValue(data)
+			if !ok {
+				value = math.NaN()
+			} else {
+				minValue, maxValue = math.Min(minValue, value), math.Max(maxValue, value)
+			}
+			values[index][pointIndex] = value
+		}
+		if len(dataSet.Data) > numPoints {
+			numPoints = len(dataSet.Data)
+		}
+	}
+	if numPoints == 0 {
+		return nil, errors.New("no data on chart config")
+	}
+
+	ticks := getAxisTicks(minValue, maxValue, maxYTicks)
+	yLabelWidth := 0.0
+	for _, tick := range ticks {
+		yLabelWidth = math.Max(yLabelWidth, c.TextWidth(formatAxisValue(tick), chartFontSize))
+	}
+	left := imagePadding + yLabelWidth + 8
+	right := float64(chartWidth - imagePadding)
+	top := legendY + legendBoxSize + 12
+	bottom := float64(chartHeight - imagePadding - 24)
+	minTick, maxTick := ticks[0], ticks[len(ticks)-1]
+	getY := func(value float64) float64 {
+		return bottom - (value-minTick)/(maxTick-minTick)*(bottom-top)
+	}
+
+	for _, tick := range ticks {
+		y := getY(tick)
+		c.Line(left, y, right, y, colorGrid, 1)
+		c.Text(left-8, y, formatAxisValue(tick), chartFontSize, colorLightText, "end")
+	}
+	c.Line(left, getY(0), right, getY(0), colorAxis, 1)
+
+	bandWidth := (right - left) / float64(numPoints)
+	getX := func(pointIndex int) float64 {
+		return left + bandWidth*(float64(pointIndex)+0.5)
+	}
+
+	maxLabelWidth := 0.0
+	labels := make([]string, numPoints)
+	for pointIndex := range labels {
+		if pointIndex < len(config.Data.Labels) {
+			labels[pointIndex] = formatChartLabel(config.Data.Labels[pointIndex])
+		}
+		maxLabelWidth = math.Max(maxLabelWidth, c.TextWidth(labels[pointIndex], chartFontSize))
+	}
+	labelStep := int(math.Ceil((maxLabelWidth + 8) / bandWidth))
+	if labelStep < 1 {
+		labelStep = 1
+	}
+	for pointIndex, label := range labels {
+		if pointIndex%labelStep == 0 {
+			c.Text(getX(pointIndex), bottom+14, label, chartFontSize, colorLightText, "middle")
+		}
+	}
+
+	if config.Type == "bar" {
+		groupWidth := bandWidth * 0.7
+		barWidth := groupWidth / float64(len(values))
+		for index, dataSetValues := range values {
+			for pointIndex, value := range dataSetValues {
+				if math.IsNaN(value) {
+					continue
+				}
+				x := getX(pointIndex) - groupWidth/2 + barWidth*float64(index)
+				y0, y1 := getY(0), getY(value)
+				c.FillRect(x, math.Min(y0, y1), math.Max(barWidth-1, 1), math.Abs(y0-y1), getChartColor(index))
+			}
+		}
+	} else {
+		for index, dataSetValues := range values {
+			for pointIndex, value := range dataSetValues {
+				if math.IsNaN(value) {
+					continue
+				}
+				x, y := getX(pointIndex), getY(value)
+				if pointIndex > 0 && !math.IsNaN(dataSetValues[pointIndex-1]) {
+					c.Line(getX(pointIndex-1), getY(dataSetValues[pointIndex-1]), x, y, getChartColor(index), 2)
+				}
+				c.FillRect(x-2, y-2, 4, 4, getChartColor(index))
+			}
+		}
+	}
+
+	return c.Encode()
+}
+
+// RenderTable draws the title and the rows of the table config, without calling any external service.
+func RenderTable(config TableConfig, format string) ([]byte, error) {
+	if len(config.Columns) == 0 {
+		return nil, errors.New("no columns on table config")
+	}
+	// canvas is sized after measuring the cells, measurement doesn't depend on the size.
+	measure, err := newCanvas(format, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(config.DataSource))
+	for _, data := range config.DataSource {
+		dataMap, _ := data.(map[string]interface{})
+		row := make([]string, len(config.Columns))
+		for index, column := range config.Columns {
+			row[index] = formatTableValue(dataMap[column.DataIndex])
+		}
+		rows = append(rows, row)
+	}
+
+	columnWidths := make([]float64, len(config.Columns))
+	tableWidth := 0.0
+	for index, column := range config.Columns {
+		width := math.Max(float64(column.Width), measure.TextWidth(column.Title, chartFontSize))
+		for _, row := range rows {
+			width = math.Max(width, measure.TextWidth(row[index], chartFontSize))
+		}
+		columnWidths[index] = math.Min(width, tableMaxColWidth) + 2*tableCellPadding
+		tableWidth += columnWidths[index]
+	}
+	titleWidth := math.Min(measure.TextWidth(config.Title, titleFontSize), tableMaxWidth)
+	width := math.Max(math.Max(tableWidth, titleWidth)+2*imagePadding, tableMinWidth)
+	height := 2*imagePadding + tableTitleHeight + tableRowHeight*float64(len(rows)+1)
+
+	c, err := newCanvas(format, int(math.Ceil(width)), int(math.Ceil(height)))
+	if err != nil {
+		return nil, err
+	}
+
+	y := float64(imagePadding)
+	c.Text(imagePadding, y+tableTitleHeight/2, truncateText(c, config.Title, titleFontSize, width-2*imagePadding),
+		titleFontSize, colorText, "start")
+	y += tableTitleHeight
+
+	drawRow := func(cells []string, fill, textColor color.RGBA) {
+		c.FillRect(imagePadding, y, tableWidth, tableRowHeight, fill)
+		x := float64(imagePadding)
+		for index, cell := range cells {
+			cell = truncateText(c, cell, chartFontSize, columnWidths[index]-2*tableCellPadding)
+			c.Text(x+tableCellPadding, y+tableRowHeight/2, cell, chartFontSize, textColor, "start")
+			x += columnWidths[index]
+		}
+		c.Line(imagePadding, y+tableRowHeight, imagePadding+tableWidth, y+tableRowHeight, colorGrid, 1)
+		y += tableRowHeight
+	}
+
+	titles := make([]string, len(config.Columns))
+	for index, column := range config.Columns {
+		titles[index] = column.Title
+	}
+	drawRow(titles, colorHeader, colorText)
+	for index, row := range rows {
+		fill := colorWhite
+		if index%2 == 1 {
+			fill = colorStripe
+		}
+		drawRow(row, fill, colorText)
+	}
+
+	return c.Encode()
+}
+
+func getChartColor(index int) color.RGBA {
+	return chartColors[index%len(chartColors)]
+}
+
+func getFloatValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		floatValue, err := strconv.ParseFloat(v, 64)
+		return floatValue, err == nil
+	}
+	return 0, false
+}
+
+// getAxisTicks returns evenly spaced ticks rounded to 1, 2 or 5 times a power of 10, covering the range.
+func getAxisTicks(minValue, maxValue float64, maxTicks int) []float64 {
+	if maxValue == minValue {
+		maxValue = minValue + 1
+	}
+	roughStep := (maxValue - minValue) / float64(maxTicks-1)
+	magnitude := math.Pow(10, math.Floor(math.Log10(roughStep)))
+	step := magnitude * 10
+	for _, multiple := range []float64{1, 2, 5, 10} {
+		if multiple*magnitude >= roughStep {
+			step = multiple * magnitude
+			break
+		}
+	}
+
+	ticks := []float64{math.Floor(minValue/step) * step}
+	for ticks[len(ticks)-1] < maxValue {
+		ticks = append(ticks, ticks[len(ticks)-1]+step)
+	}
+	if len(ticks) < 2 {
+		ticks = append(ticks, ticks[0]+step)
+	}
+	return ticks
+}
+
+func formatAxisValue(value float64) string {
+	absValue := math.Abs(value)
+	switch {
+	case absValue >= 1e9:
+		return trimDecimals(value/1e9) + "B"
+	case absValue >= 1e6:
+		return trimDecimals(value/1e6) + "M"
+	case absValue >= 1e3:
+		return trimDecimals(value/1e3) + "K"
+	}
+	return trimDecimals(value)
+}
+
+func trimDecimals(value float64) string {
+	return strings.TrimRight(strings.TrimRight(strconv.FormatFloat(value, 'f', 2, 64), "0"), ".")
+}
+
+func formatChartLabel(label interface{}) string {
+	labelString := fmt.Sprint(label)
+	if timestamp, err := time.Parse(time.RFC3339, labelString); err == nil {
+		return timestamp.Format("02 Jan")
+	}
+	return truncateLabel(labelString)
+}
+
+func truncateLabel(label string) string {
+	runes := []rune(label)
+	if len(runes) > maxLabelLength {
+		return string(runes[:maxLabelLength-2]) + ".."
+	}
+	return label
+}
+
+func formatTableValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	floatValue, ok := getFloatValue(value)
+	if _, isString := value.(string); !ok || isString {
+		return fmt.Sprint(value)
+	}
+
+	formattedValue := trimDecimals(math.Abs(floatValue))
+	parts := strings.SplitN(formattedValue, ".", 2)
+	integerPart := parts[0]
+	for index := len(integerPart) - 3; index > 0; index -= 3 {
+		integerPart = integerPart[:index] + "," + integerPart[index:]
+	}
+	if len(parts) == 2 {
+		integerPart += "." + parts[1]
+	}
+	if floatValue < 0 {
+		return "-" + integerPart
+	}
+	return integerPart
+}
+
+func truncateText(c canvas, text string, size, maxWidth float64) string {
+	if c.TextWidth(text, size) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && c.TextWidth(string(runes)+"..", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ".."
+}
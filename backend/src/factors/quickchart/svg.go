@@ -0,0 +1,55 @@
+package quickchart
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+)
+
+// average width of a character of the sans-serif font, as a ratio of the font size.
+const svgCharWidthRatio = 0.6
+
+type svgCanvas struct {
+	width  int
+	height int
+	body   strings.Builder
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (c *svgCanvas) FillRect(x, y, width, height float64, fill color.RGBA) {
+	fmt.Fprintf(&c.body, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`,
+		x, y, width, height, getHexColor(fill))
+}
+
+func (c *svgCanvas) Line(x1, y1, x2, y2 float64, stroke color.RGBA, strokeWidth float64) {
+	fmt.Fprintf(&c.body, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%.1f" stroke-linecap="round"/>`,
+		x1, y1, x2, y2, getHexColor(stroke), strokeWidth)
+}
+
+func (c *svgCanvas) Text(x, y float64, text string, size float64, fill color.RGBA, anchor string) {
+	fmt.Fprintf(&c.body, `<text x="%.1f" y="%.1f" font-size="%.0f" fill="%s" text-anchor="%s" dominant-baseline="central">%s</text>`,
+		x, y, size, getHexColor(fill), anchor, html.EscapeString(text))
+}
+
+func (c *svgCanvas) TextWidth(text string, size float64) float64 {
+	return float64(utf8.RuneCountInString(text)) * size * svgCharWidthRatio
+}
+
+func (c *svgCanvas) Encode() ([]byte, error) {
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`,
+		c.width, c.height, c.width, c.height)
+	fmt.Fprintf(&svg, `<rect width="100%%" height="100%%" fill="%s"/>`, getHexColor(colorWhite))
+	svg.WriteString(c.body.String())
+	svg.WriteString(`</svg>`)
+	return []byte(svg.String()), nil
+}
+
+func getHexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
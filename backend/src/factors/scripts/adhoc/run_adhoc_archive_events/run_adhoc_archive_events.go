@@ -12,6 +12,7 @@ import (
 	"factors/filestore"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	U "factors/util"
 
@@ -21,6 +22,7 @@ import (
 func main() {
 	envFlag := flag.String("env", C.DEVELOPMENT, "Environment. Could be development|staging|production.")
 	bucketNameFlag := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "Bucket name for production.")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	localDiskTmpDirFlag := flag.String("tmp_dir", "/usr/local/var/factors/local_disk/tmp", "Local directory path for putting tmp files.")
 	projectIDFlag := flag.String("project_id", "", "Comma separated list of project ids to run")
 	numRoutinesFlag := flag.Int("num_routines", 2, "Number of projects to run in parallel")
@@ -84,7 +86,9 @@ func main() {
 	defer C.SafeFlushAllCollectors()
 
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*bucketNameFlag)
 	} else {
 		cloudManager, err = serviceGCS.New(*bucketNameFlag)
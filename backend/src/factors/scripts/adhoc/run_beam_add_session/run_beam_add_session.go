@@ -232,13 +232,14 @@ func (f *getAddSessionAllowedProjectsFn) ProcessElement(ctx context.Context, pro
 
 // EventsByProjectResponse Struct to store events pull by projectId response and emit.
 type EventsByProjectResponse struct {
-	ProjectID        int64
-	UserID           string
-	TimeTaken        int64
-	Events           []model.Event
-	SessionEventName *model.EventName
-	Status           *StatusBeam
-	ErrorCode        int
+	ProjectID         int64
+	UserID            string
+	TimeTaken         int64
+	Events            []model.Event
+	SessionEventName  *model.EventName
+	SessionDefinition model.SessionDefinition
+	Status            *StatusBeam
+	ErrorCode         int
 }
 
 type pullEventsByProjectIdFn struct {
@@ -301,6 +302,13 @@ func (f *pullEventsByProjectIdFn) ProcessElement(ctx context.Context,
 		logCtx.WithField("log_type", stepTrace).WithFields(log.Fields{"project_id": projectID, "err_code": errCode, "error_detail": errorDetail}).Info("No events found for the project. Exiting.")
 		return
 	}
+
+	sessionDefinition, errCode, errorDetail := session.GetSessionDefinitionByProject(projectID, logCtx)
+	if errCode != http.StatusFound {
+		logCtx.WithField("log_type", stepTrace).WithFields(log.Fields{"project_id": projectID, "err_code": errCode, "error_detail": errorDetail}).Error("Failed to get session definition for the project. Exiting.")
+		return
+	}
+
 	usersCount := 0
 	if noOfEventsDownloaded > 0 {
 		beamStatus.NoOfEvents = noOfEventsDownloaded
@@ -308,12 +316,13 @@ func (f *pullEventsByProjectIdFn) ProcessElement(ctx context.Context,
 		for userId, events := range *userEventsMap {
 			usersCount++
 			eventsResponse := EventsByProjectResponse{
-				ProjectID:        projectID,
-				UserID:           userId,
-				Events:           events,
-				SessionEventName: sessionEventName,
-				Status:           beamStatus,
-				ErrorCode:        http.StatusFound,
+				ProjectID:         projectID,
+				UserID:            userId,
+				Events:            events,
+				SessionEventName:  sessionEventName,
+				SessionDefinition: sessionDefinition,
+				Status:            beamStatus,
+				ErrorCode:         http.StatusFound,
 			}
 			emit(eventsResponse)
 		}
@@ -393,7 +402,7 @@ func (f *addSessionsByUserIDProjectIDFn) ProcessElement(ctx context.Context, eve
 
 	noOfProcessedEvents, noOfCreated, isContinuedFirst, noOfUserPropUpdates,
 		errCode := store.GetStore().AddSessionForUser(eventsInput.ProjectID, eventsInput.UserID, eventsInput.Events,
-		f.JobProps.BufferTimeBeforeSessionCreateInSecs, eventsInput.SessionEventName.ID)
+		f.JobProps.BufferTimeBeforeSessionCreateInSecs, eventsInput.SessionEventName.ID, eventsInput.SessionDefinition)
 
 	if errCode == http.StatusInternalServerError || errCode == http.StatusBadRequest {
 		msg := fmt.Sprintf("failed to get user events map on add session for project, errCode: %v", errCode)
@@ -12,6 +12,7 @@ import (
 	"factors/filestore"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	"factors/util"
 	"flag"
@@ -31,6 +32,7 @@ func main() {
 	envFlag := flag.String("env", "development", "")
 	localDiskTmpDirFlag := flag.String("local_disk_tmp_dir", "/usr/local/var/factors/local_disk/tmp", "--local_disk_tmp_dir=/usr/local/var/factors/local_disk/tmp pass directory")
 	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
 	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
@@ -78,7 +80,9 @@ func main() {
 	defer db.Close()
 	// Connect to cloud storage to fetch the required flag to local disk.
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketName)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*bucketName)
 	} else {
 		cloudManager, err = serviceGCS.New(*bucketName)
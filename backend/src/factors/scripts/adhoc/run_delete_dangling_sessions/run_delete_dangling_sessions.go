@@ -9,6 +9,7 @@ import (
 	"factors/model/store"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	"factors/util"
 	"flag"
 	"fmt"
@@ -82,6 +83,7 @@ func getUnusedSessionIDsFromArchiveFile(store filestore.FileManager, path, filen
 
 func main() {
 	env := flag.String("env", "development", "")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
 	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
@@ -145,7 +147,9 @@ func main() {
 
 	// Init storage with archival bucket.
 	var cloudStorage filestore.FileManager
-	if C.IsDevelopment() {
+	if *localFSRootFlag != "" {
+		cloudStorage = serviceLocalFS.New(*localFSRootFlag, "factors-production-archival")
+	} else if C.IsDevelopment() {
 		cloudStorage = serviceDisk.New("factors-production-archival")
 	} else {
 		cloudStorage, err = serviceGCS.New("factors-production-archival")
@@ -13,6 +13,7 @@ import (
 	"factors/model/store"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	U "factors/util"
 
@@ -32,6 +33,7 @@ func main() {
 	sessionPropertyFlag := flag.String("session_property", "$campaign", "Propert of $session event shown along path")
 
 	bucketNameFlag := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "Bucket name for production")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	localDiskTmpDirFlag := flag.String("tmp_dir", "/usr/local/var/factors/local_disk/tmp", "Local directory path for putting tmp files.")
 
 	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
@@ -86,7 +88,9 @@ func main() {
 	db := C.GetServices().Db
 
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*bucketNameFlag)
 	} else {
 		cloudManager, err = serviceGCS.New(*bucketNameFlag)
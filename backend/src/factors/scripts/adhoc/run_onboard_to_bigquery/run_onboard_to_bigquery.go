@@ -14,6 +14,7 @@ import (
 	BQ "factors/services/bigquery"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	"factors/util"
 
 	log "github.com/sirupsen/logrus"
@@ -26,6 +27,7 @@ func main() {
 	envFlag := flag.String("env", "development", "Environment. Could be development|staging|production.")
 	projectIDFlag := flag.Uint64("project_id", 0, "Project id to be run for.")
 	bucketNameFlag := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "Bucket name for production.")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	bigqueryProjectIDFlag := flag.String("bq_project_id", "", "Project id to be run for.")
 	bigqueryDatasetFlag := flag.String("bq_dataset", "", "Dataset for the bigquery.")
 	bigqueryCredentialsFileFlag := flag.String("bq_credentials_json", "", "Filename for credentials json. Must be present in bucket at bigquery/<projectID>/")
@@ -76,7 +78,10 @@ func main() {
 
 	var cloudManager filestore.FileManager
 	var fileDir string
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketNameFlag)
+		fileDir = fmt.Sprintf("factors-bq-cred/%d/", *projectIDFlag)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*bucketNameFlag)
 		fileDir = fmt.Sprintf("%s/factors-bq-cred/%d/", *bucketNameFlag, *projectIDFlag)
 	} else {
@@ -11,6 +11,7 @@ import (
 	serviceDisk "factors/services/disk"
 	serviceEtcd "factors/services/etcd"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	AS "factors/task/account_scoring"
 
@@ -56,6 +57,7 @@ func main() {
 	localDiskTmpDirFlag := flag.String("local_disk_tmp_dir", "/usr/local/var/factors/local_disk/tmp",
 		"--local_disk_tmp_dir=/usr/local/var/factors/local_disk/tmp pass directory")
 	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	numRoutinesFlag := flag.Int("num_routines", 3, "No of routines")
 	numWorkersFlag := flag.Int("num_beam_workers", 100, "Num of beam workers")
 	DayTimestamp := flag.Int64("day_time_stamp", time.Now().Unix(), "time stamp for day")
@@ -183,7 +185,9 @@ func main() {
 	}
 
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *tmpBucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*tmpBucketNameFlag)
 	} else {
 		cloudManager, err = serviceGCS.New(*tmpBucketNameFlag)
@@ -237,7 +241,11 @@ func main() {
 		var archiveCloudManager filestore.FileManager
 		var sortedCloudManager filestore.FileManager
 		var modelCloudManager filestore.FileManager
-		if *envFlag == "development" {
+		if *localFSRootFlag != "" {
+			modelCloudManager = serviceLocalFS.New(*localFSRootFlag, *modelBucketNameFlag)
+			archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+			sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+		} else if *envFlag == "development" {
 			modelCloudManager = serviceDisk.New(*modelBucketNameFlag)
 			archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 			sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
@@ -260,7 +268,9 @@ func main() {
 		configs["sortedCloudManager"] = &sortedCloudManager
 	} else {
 		var cloudManager filestore.FileManager
-		if *envFlag == "development" {
+		if *localFSRootFlag != "" {
+			cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketName)
+		} else if *envFlag == "development" {
 			cloudManager = serviceDisk.New(*bucketName)
 		} else {
 			cloudManager, err = serviceGCS.New(*bucketName)
@@ -62,6 +62,12 @@ func main() {
 		0, "Enables filter optimisation logic for events and users query.")
 	enableFeatureGatesV2 := flag.Bool("enable_feature_gates_v2", false, "")
 	skipResultsValidationFlag := flag.Bool("skip_kpi_result_validation", false, "meant to be only used in weekly alerts job")
+	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
+	enableSelfHostedChartRendering := flag.Bool("enable_self_hosted_chart_rendering", true,
+		"Renders the images of the shared reports in-process, instead of using quickchart.")
+	chartImageURLSigningKey := flag.String("chart_image_url_signing_key", "", "Key to sign the urls of the chart images.")
+	apiDomain := flag.String("api_domain", "factors-dev.com:8080", "Domain of the app server, which serves the chart images.")
 
 	flag.Parse()
 	if *env != "development" &&
@@ -98,6 +104,9 @@ func main() {
 		EnableOptimisedFilterOnEventUserQuery: *enableOptimisedFilterOnEventUserQuery != 0,
 		EnableFeatureGatesV2:                  *enableFeatureGatesV2,
 		SkipKpiResultValidation:               *skipResultsValidationFlag,
+		EnableSelfHostedChartRendering:        *enableSelfHostedChartRendering,
+		ChartImageURLSigningKey:               *chartImageURLSigningKey,
+		APIDomain:                             *apiDomain,
 	}
 	defaultHealthcheckPingID := C.HealthcheckComputeAndSendAlertsPingID
 	healthcheckPingID := C.GetHealthcheckPingID(defaultHealthcheckPingID, *overrideHealthcheckPingID)
@@ -105,6 +114,14 @@ func main() {
 	C.InitSenderEmail(C.GetFactorsSenderEmail())
 	C.InitMailClient(config.AWSKey, config.AWSSecret, config.AWSRegion)
 	C.InitRedisPersistent(config.RedisHostPersistent, config.RedisPortPersistent)
+	// file manager stores the images of the shared reports.
+	if C.IsSelfHostedChartRenderingEnabled() {
+		if *localFSRootFlag != "" {
+			C.InitLocalFSFilemanager(*localFSRootFlag, *bucketName, config)
+		} else {
+			C.InitFilemanager(*bucketName, *env, config)
+		}
+	}
 	err := C.InitDB(*config)
 	if err != nil {
 		log.Fatal("Init failed.")
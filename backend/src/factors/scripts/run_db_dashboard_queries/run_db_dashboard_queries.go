@@ -37,6 +37,7 @@ func main() {
 	memSQLCertificate := flag.String("memsql_cert", "", "")
 	primaryDatastore := flag.String("primary_datastore", C.DatastoreTypeMemSQL, "Primary datastore type as memsql or postgres")
 	attributionDebug := flag.Int("attribution_debug", 0, "Enables debug logging for attribution queries")
+	attributionDayPartials := flag.String("attribution_day_partials", "", "For given projects, compute attribution queries by merging cached per day partials.")
 
 	memSQLDBMaxOpenConnections := flag.Int("memsql_max_open_connections", 100, "Max no.of open connections allowed on connection pool of memsql")
 	memSQLDBMaxIdleConnections := flag.Int("memsql_max_idle_connections", 50, "Max no.of idle connections allowed on connection pool of memsql")
@@ -127,6 +128,7 @@ func main() {
 		AllowProfilesGroupSupport:             *allowProfilesGroupSupport,
 		FilterPropertiesStartTimestamp:        *filterPropertiesStartTimestamp,
 		AttributionDebug:                      *attributionDebug,
+		AttributionDayPartials:                *attributionDayPartials,
 		IsRunningForMemsql:                    *runningForMemsql,
 		IsHourlyRunEnabled:                    *hourlyRun,
 		SkipEventNameStepByProjectID:          *skipEventNameStepByProjectID,
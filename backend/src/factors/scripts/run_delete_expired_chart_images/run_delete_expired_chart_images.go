@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	C "factors/config"
+	"factors/model/store"
+	qc "factors/quickchart"
+)
+
+func main() {
+	env := flag.String("env", C.DEVELOPMENT, "")
+
+	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
+	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
+	memSQLPort := flag.Int("memsql_port", C.MemSQLDefaultDBParams.Port, "")
+	memSQLUser := flag.String("memsql_user", C.MemSQLDefaultDBParams.User, "")
+	memSQLName := flag.String("memsql_name", C.MemSQLDefaultDBParams.Name, "")
+	memSQLPass := flag.String("memsql_pass", C.MemSQLDefaultDBParams.Password, "")
+	memSQLCertificate := flag.String("memsql_cert", "", "")
+	primaryDatastore := flag.String("primary_datastore", C.DatastoreTypeMemSQL, "Primary datastore type as memsql or postgres")
+
+	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRoot := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
+
+	sentryDSN := flag.String("sentry_dsn", "", "Sentry DSN")
+
+	overrideHealthcheckPingID := flag.String("healthcheck_ping_id", "", "Override default healthcheck ping id.")
+	overrideAppName := flag.String("app_name", "", "Override default app_name.")
+
+	flag.Parse()
+
+	if *env != "development" &&
+		*env != "staging" &&
+		*env != "production" {
+		err := fmt.Errorf("env [ %s ] not recognised", *env)
+		panic(err)
+	}
+
+	defaultAppName := "delete_expired_chart_images_job"
+	healthcheckPingID := C.GetHealthcheckPingID("", *overrideHealthcheckPingID)
+	appName := C.GetAppName(defaultAppName, *overrideAppName)
+	defer C.PingHealthcheckForPanic(appName, *env, healthcheckPingID)
+
+	config := &C.Configuration{
+		AppName: appName,
+		Env:     *env,
+		MemSQLInfo: C.DBConf{
+			Host:        *memSQLHost,
+			IsPSCHost:   *isPSCHost,
+			Port:        *memSQLPort,
+			User:        *memSQLUser,
+			Name:        *memSQLName,
+			Password:    *memSQLPass,
+			Certificate: *memSQLCertificate,
+			AppName:     appName,
+		},
+		PrimaryDatastore: *primaryDatastore,
+		SentryDSN:        *sentryDSN,
+	}
+
+	C.InitConf(config)
+	C.InitSentryLogging(config.SentryDSN, config.AppName)
+	if *localFSRoot != "" {
+		C.InitLocalFSFilemanager(*localFSRoot, *bucketName, config)
+	} else {
+		C.InitFilemanager(*bucketName, *env, config)
+	}
+
+	err := C.InitDB(*config)
+	if err != nil {
+		log.Error("Failed to initialize DB.")
+		os.Exit(1)
+	}
+
+	projectIDs, status := store.GetStore().GetAllProjectIDs()
+	if status != http.StatusFound {
+		C.PingHealthcheckForFailure(healthcheckPingID, "Delete expired chart images run failed. Failed to get projects.")
+		return
+	}
+
+	deletedByProject := make(map[int64]int)
+	failedProjects := make([]int64, 0)
+	for _, projectID := range projectIDs {
+		deleted, err := qc.DeleteExpiredChartImages(projectID)
+		if deleted > 0 {
+			deletedByProject[projectID] = deleted
+		}
+		if err != nil {
+			log.WithError(err).WithField("project_id", projectID).Error("Failed to delete expired chart images.")
+			failedProjects = append(failedProjects, projectID)
+		}
+	}
+
+	report := map[string]interface{}{"deleted": deletedByProject, "failed_projects": failedProjects}
+	if len(failedProjects) > 0 {
+		C.PingHealthcheckForFailure(healthcheckPingID, report)
+		return
+	}
+	C.PingHealthcheckForSuccess(healthcheckPingID, report)
+}
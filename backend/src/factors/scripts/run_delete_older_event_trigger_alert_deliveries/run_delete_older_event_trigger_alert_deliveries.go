@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	C "factors/config"
+	"factors/model/model"
+	"factors/model/store"
+)
+
+func main() {
+	env := flag.String("env", C.DEVELOPMENT, "")
+
+	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
+	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
+	memSQLPort := flag.Int("memsql_port", C.MemSQLDefaultDBParams.Port, "")
+	memSQLUser := flag.String("memsql_user", C.MemSQLDefaultDBParams.User, "")
+	memSQLName := flag.String("memsql_name", C.MemSQLDefaultDBParams.Name, "")
+	memSQLPass := flag.String("memsql_pass", C.MemSQLDefaultDBParams.Password, "")
+	memSQLCertificate := flag.String("memsql_cert", "", "")
+	primaryDatastore := flag.String("primary_datastore", C.DatastoreTypeMemSQL, "Primary datastore type as memsql or postgres")
+
+	sentryDSN := flag.String("sentry_dsn", "", "Sentry DSN")
+
+	overrideHealthcheckPingID := flag.String("healthcheck_ping_id", "", "Override default healthcheck ping id.")
+	overrideAppName := flag.String("app_name", "", "Override default app_name.")
+
+	retentionDays := flag.Int("retention_days", model.DeliveryRetentionDays, "Number of days to keep the deliveries of the event trigger alerts.")
+
+	flag.Parse()
+
+	if *env != "development" &&
+		*env != "staging" &&
+		*env != "production" {
+		err := fmt.Errorf("env [ %s ] not recognised", *env)
+		panic(err)
+	}
+
+	defaultAppName := "delete_older_event_trigger_alert_deliveries_job"
+	healthcheckPingID := C.GetHealthcheckPingID("", *overrideHealthcheckPingID)
+	appName := C.GetAppName(defaultAppName, *overrideAppName)
+	defer C.PingHealthcheckForPanic(appName, *env, healthcheckPingID)
+
+	config := &C.Configuration{
+		AppName: appName,
+		Env:     *env,
+		MemSQLInfo: C.DBConf{
+			Host:        *memSQLHost,
+			IsPSCHost:   *isPSCHost,
+			Port:        *memSQLPort,
+			User:        *memSQLUser,
+			Name:        *memSQLName,
+			Password:    *memSQLPass,
+			Certificate: *memSQLCertificate,
+			AppName:     appName,
+		},
+		PrimaryDatastore: *primaryDatastore,
+		SentryDSN:        *sentryDSN,
+	}
+
+	C.InitConf(config)
+	C.InitSentryLogging(config.SentryDSN, config.AppName)
+
+	err := C.InitDB(*config)
+	if err != nil {
+		log.Error("Failed to initialize DB.")
+		os.Exit(1)
+	}
+
+	status, _ := store.GetStore().DeleteEventTriggerAlertDeliveriesOlderThanGivenDays(*retentionDays)
+	if status != http.StatusOK {
+		C.PingHealthcheckForFailure(healthcheckPingID, "Delete event_trigger_alert_deliveries run failed.")
+		return
+	}
+	C.PingHealthcheckForSuccess(healthcheckPingID, "Delete event_trigger_alert_deliveries run success.")
+}
@@ -16,6 +16,7 @@ import (
 	"factors/pull"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	taskWrapper "factors/task/task_wrapper"
 	"factors/util"
@@ -57,6 +58,7 @@ func mainRunDeltaInsights() {
 	envFlag := flag.String("env", "development", "")
 	localDiskTmpDirFlag := flag.String("local_disk_tmp_dir", "/usr/local/var/factors/local_disk/tmp", "--local_disk_tmp_dir=/usr/local/var/factors/local_disk/tmp pass directory")
 	tmpBucketNameFlag := flag.String("bucket_name_tmp", "/usr/local/var/factors/cloud_storage_tmp", "--bucket_name=/usr/local/var/factors/cloud_storage_tmp pass bucket name for tmp artifacts")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	archiveBucketNameFlag := flag.String("archive_bucket_name", "/usr/local/var/factors/cloud_storage_archive", "--bucket_name=/usr/local/var/factors/cloud_storage_archive pass archive bucket name")
 	sortedBucketNameFlag := flag.String("sorted_bucket_name", "/usr/local/var/factors/cloud_storage_sorted", "--bucket_name=/usr/local/var/factors/cloud_storage_sorted pass sorted data bucket name")
 	modelBucketNameFlag := flag.String("model_bucket_name", "/usr/local/var/factors/cloud_storage_models", "--bucket_name=/usr/local/var/factors/cloud_storage_models pass model bucket name")
@@ -215,7 +217,9 @@ func mainRunDeltaInsights() {
 		configs := make(map[string]interface{})
 
 		var cloudManagerTmp filestore.FileManager
-		if *envFlag == "development" {
+		if *localFSRootFlag != "" {
+			cloudManagerTmp = serviceLocalFS.New(*localFSRootFlag, *tmpBucketNameFlag)
+		} else if *envFlag == "development" {
 			cloudManagerTmp = serviceDisk.New(*tmpBucketNameFlag)
 		} else {
 			cloudManagerTmp, err = serviceGCS.New(*tmpBucketNameFlag)
@@ -227,7 +231,11 @@ func mainRunDeltaInsights() {
 		var archiveCloudManager filestore.FileManager
 		var sortedCloudManager filestore.FileManager
 		var modelCloudManager filestore.FileManager
-		if *envFlag == "development" {
+		if *localFSRootFlag != "" {
+			modelCloudManager = serviceLocalFS.New(*localFSRootFlag, *modelBucketNameFlag)
+			archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+			sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+		} else if *envFlag == "development" {
 			modelCloudManager = serviceDisk.New(*modelBucketNameFlag)
 			archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 			sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
@@ -386,7 +394,10 @@ func mainRunDeltaInsights() {
 
 		var archiveCloudManager filestore.FileManager
 		var sortedCloudManager filestore.FileManager
-		if *envFlag == "development" {
+		if *localFSRootFlag != "" {
+			archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+			sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+		} else if *envFlag == "development" {
 			archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 			sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
 		} else {
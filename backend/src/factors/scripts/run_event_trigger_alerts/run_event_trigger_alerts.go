@@ -13,6 +13,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	teams "factors/integration/ms_teams"
 	"factors/integration/paragon"
@@ -34,6 +36,7 @@ const (
 	SLACK                        = "Slack"
 	TEAMS                        = "Teams"
 	WEBHOOK                      = "WH"
+	EMAIL                        = "Email"
 	ParagonUrlRune               = "zeus.useparagon.com"
 )
 
@@ -44,8 +47,30 @@ type SendReportLogCount struct {
 	TeamsFail      int
 	WebhookSuccess int
 	WebhookFail    int
+	// Webhooks whose first attempt failed and are being retried in the background.
+	WebhookRetrying int
+	EmailSuccess    int
+	EmailFail       int
+	// Alerts added to the email digest, sent later by SendEventTriggerAlertEmailDigests.
+	EmailQueued int
 }
 
+// retriedWebhookDelivery is the outcome of a webhook delivery retried in the background,
+// completed on the main path by completeRetriedWebhookDeliveries.
+type retriedWebhookDelivery struct {
+	eta         *model.EventTriggerAlert
+	alert       *model.CachedEventTriggerAlert
+	destination string
+	payload     *postgres.Jsonb
+	response    map[string]interface{}
+	err         error
+	startTime   time.Time
+	retry       bool
+}
+
+var retriedWebhookDeliveriesMutex sync.Mutex
+var retriedWebhookDeliveries = make([]retriedWebhookDelivery, 0)
+
 type BlockedAlertList struct {
 	alertID map[string]int
 	keys    []string
@@ -81,6 +106,10 @@ func main() {
 	teamsApplicationID := flag.String("teams_application_id", "", "")
 	enableFeatureGatesV2 := flag.Bool("enable_feature_gates_v2", false, "")
 	appDomain := flag.String("app_domain", "factors-dev.com:3000", "")
+	factorsEmailSender := flag.String("email_sender", "support-dev@factors.ai", "")
+	awsRegion := flag.String("aws_region", "us-east-1", "")
+	awsAccessKeyId := flag.String("aws_key", "dummy", "")
+	awsSecretAccessKey := flag.String("aws_secret", "dummy", "")
 	// blacklistedAlerts := flag.String("blacklisted_alerts", "", "")
 
 	flag.Parse()
@@ -118,6 +147,10 @@ func main() {
 		TeamsApplicationID:   *teamsApplicationID,
 		EnableFeatureGatesV2: *enableFeatureGatesV2,
 		APPDomain:            *appDomain,
+		EmailSender:          *factorsEmailSender,
+		AWSKey:               *awsAccessKeyId,
+		AWSSecret:            *awsSecretAccessKey,
+		AWSRegion:            *awsRegion,
 	}
 	defaultHealthcheckPingID := C.HealthcheckEventTriggerAlertPingID
 	highPriorityHealthCheckPingID := C.HealthcheckEventTriggerAlertForHighPriorityPingID
@@ -125,6 +158,8 @@ func main() {
 	C.InitConf(config)
 	C.InitSentryLogging(config.SentryDSN, config.AppName)
 	C.InitRedisPersistent(config.RedisHostPersistent, config.RedisPortPersistent)
+	C.InitSenderEmail(C.GetFactorsSenderEmail())
+	C.InitMailClient(config.AWSKey, config.AWSSecret, config.AWSRegion)
 
 	err := C.InitDB(*config)
 	if err != nil {
@@ -145,6 +180,7 @@ func main() {
 
 	successfulProjectsCount := 0 //Total number of projects with no failures
 	failedProjectsCount := 0     //Total number of projects with atleast one failure
+	projectsSuccess := make(map[int64]bool)
 
 	for _, projectID := range projectIDs {
 		available := true
@@ -158,6 +194,8 @@ func main() {
 
 		if !available {
 			log.Error("Feature Not Available... Skipping event trigger alerts job for project ID ", projectID)
+			// Webhooks of the previous projects being retried are still recorded.
+			completeRetriedWebhookDeliveries()
 			return
 		}
 
@@ -171,15 +209,6 @@ func main() {
 		}
 
 		sendReportForProject, blockedAlertList, projectSuccess := EventTriggerAlertsSender(projectID, conf, blockedAlertMap)
-		if !projectSuccess {
-			log.WithFields(log.Fields{"project_id": projectID}).Error("Event Trigger Alert job failing")
-		}
-
-		if projectSuccess {
-			successfulProjectsCount++
-		} else {
-			failedProjectsCount++
-		}
 
 		if sendReportForProject.SlackSuccess > 0 {
 			finalStatus[fmt.Sprintf("Success-SLACK-%v", projectID)] = sendReportForProject.SlackSuccess
@@ -199,6 +228,23 @@ func main() {
 		if sendReportForProject.WebhookFail > 0 {
 			finalStatus[fmt.Sprintf("Failure-WEBHOOK-%v", projectID)] = sendReportForProject.WebhookFail
 		}
+		if sendReportForProject.EmailFail > 0 {
+			finalStatus[fmt.Sprintf("Failure-EMAIL-%v", projectID)] = sendReportForProject.EmailFail
+		}
+		if sendReportForProject.EmailQueued > 0 {
+			finalStatus[fmt.Sprintf("Queued-EMAIL-%v", projectID)] = sendReportForProject.EmailQueued
+		}
+		if sendReportForProject.WebhookRetrying > 0 {
+			finalStatus[fmt.Sprintf("Retrying-WEBHOOK-%v", projectID)] = sendReportForProject.WebhookRetrying
+		}
+
+		digestReport := SendEventTriggerAlertEmailDigests(projectID)
+		if digestReport.EmailSuccess > 0 {
+			finalStatus[fmt.Sprintf("Success-EMAIL-DIGEST-%v", projectID)] = digestReport.EmailSuccess
+		}
+		if digestReport.EmailFail > 0 {
+			finalStatus[fmt.Sprintf("Failure-EMAIL-DIGEST-%v", projectID)] = digestReport.EmailFail
+		}
 		if len(blockedAlertList.alertID) > 0 {
 			finalStatus[fmt.Sprintf("Blocked-alert-ids-%v", projectID)] = blockedAlertList.alertID
 			finalStatus[fmt.Sprintf("Blocked-alert-keys-%v", projectID)] = blockedAlertList.keys
@@ -254,6 +300,39 @@ func main() {
 				finalStatus[fmt.Sprintf("Rejected Queue Failure-WEBHOOK-%v", projectID)] = sendReportForProject.WebhookFail
 			}
 		}
+
+		projectsSuccess[projectID] = projectSuccess
+	}
+
+	// Webhooks being retried in the background are completed once for all the projects,
+	// to not hold the next projects on the retries of a slow receiver.
+	retriedReports := completeRetriedWebhookDeliveries()
+	for _, projectID := range projectIDs {
+		projectSuccess, exists := projectsSuccess[projectID]
+		if !exists {
+			continue
+		}
+
+		retriedReport := retriedReports[projectID]
+		if retriedReport.WebhookSuccess > 0 {
+			finalStatus[fmt.Sprintf("Background Retry Success-WEBHOOK-%v", projectID)] = retriedReport.WebhookSuccess
+		}
+		if retriedReport.WebhookFail > 0 {
+			finalStatus[fmt.Sprintf("Background Retry Failure-WEBHOOK-%v", projectID)] = retriedReport.WebhookFail
+			// Alerts with the webhook being retried are not failures on the send, the project
+			// is successful only if the retries are.
+			projectSuccess = false
+		}
+
+		if !projectSuccess {
+			log.WithFields(log.Fields{"project_id": projectID}).Error("Event Trigger Alert job failing")
+		}
+
+		if projectSuccess {
+			successfulProjectsCount++
+		} else {
+			failedProjectsCount++
+		}
 	}
 
 	if successfulProjectsCount/3 <= failedProjectsCount {
@@ -303,6 +382,7 @@ func EventTriggerAlertsSender(projectID int64, configs map[string]interface{},
 	logCtx := log.WithFields(logFields)
 
 	ok := int(0)
+	retrying := int(0)
 	sendReportForProject := SendReportLogCount{}
 	blockedAlertList := NewBlockedAlertList()
 	ssKey, err := getSortedSetCacheKey(SortedSetKeyPrefix, projectID)
@@ -352,6 +432,10 @@ func EventTriggerAlertsSender(projectID int64, configs map[string]interface{},
 		sendReport := SendReportLogCount{}
 		if msg.IsWorkflow {
 			totalSuccess, _, sendReport = ProcessWorkflow(cacheKey, &msg, alertID, false, "")
+		} else if msg.ReplayTo != "" {
+			// Replayed deliveries are sent only to the delivery option which failed.
+			totalSuccess, _, sendReport = sendHelperForEventTriggerAlert(cacheKey, &msg, alertID, true,
+				getSendToForDeliveryOption(msg.ReplayTo))
 		} else {
 			totalSuccess, _, sendReport = sendHelperForEventTriggerAlert(cacheKey, &msg, alertID, false, "")
 		}
@@ -361,7 +445,13 @@ func EventTriggerAlertsSender(projectID int64, configs map[string]interface{},
 			if err != nil {
 				logCtx.WithField("alert_key", key).WithError(err).Error("failed to remove alert from cache")
 			}
-			ok++
+			if sendReport.WebhookRetrying > 0 {
+				// Webhook of the alert is not delivered yet, its outcome is counted
+				// by completeRetriedWebhookDeliveries.
+				retrying++
+			} else {
+				ok++
+			}
 		}
 		cc, err := cacheRedis.ZRemPersistent(ssKey, true, key)
 		if err != nil || cc != 1 {
@@ -370,7 +460,8 @@ func EventTriggerAlertsSender(projectID int64, configs map[string]interface{},
 
 		sendReportForProject.addToSendReport(sendReport)
 	}
-	return sendReportForProject, blockedAlertList, ok == len(allKeys)
+	// Failures on the retries of the webhooks are counted on the project by the caller.
+	return sendReportForProject, blockedAlertList, ok+retrying == len(allKeys)
 }
 
 func SendKeyToRejectedQueue(strKey string, key, ssKey *cache.Key, projectID int64) error {
@@ -418,6 +509,10 @@ func (projReport *SendReportLogCount) addToSendReport(alertReport SendReportLogC
 	projReport.TeamsSuccess += alertReport.TeamsSuccess
 	projReport.WebhookFail += alertReport.WebhookFail
 	projReport.WebhookSuccess += alertReport.WebhookSuccess
+	projReport.WebhookRetrying += alertReport.WebhookRetrying
+	projReport.EmailFail += alertReport.EmailFail
+	projReport.EmailSuccess += alertReport.EmailSuccess
+	projReport.EmailQueued += alertReport.EmailQueued
 }
 
 /*
@@ -425,8 +520,8 @@ func (projReport *SendReportLogCount) addToSendReport(alertReport SendReportLogC
 # Partial success is if atleast one send is a success
 */
 func findTotalAndPartialSuccess(report SendReportLogCount) (bool, bool) {
-	total := report.SlackFail + report.TeamsFail + report.WebhookFail
-	partial := report.SlackSuccess + report.TeamsSuccess + report.WebhookSuccess
+	total := report.SlackFail + report.TeamsFail + report.WebhookFail + report.EmailFail
+	partial := report.SlackSuccess + report.TeamsSuccess + report.WebhookSuccess + report.EmailSuccess
 
 	return total == 0, partial > 0
 }
@@ -527,6 +622,12 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 		rejectedQueue = true
 	}
 
+	// Payload is kept for the delivery log before the internal properties are removed below.
+	deliveryPayload, err := U.EncodeStructTypeToPostgresJsonb(alert)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to encode alert payload for delivery log")
+	}
+
 	var accountUrl, hubspotAccountUrl, salesforceAccountUrl string
 	isAccounAlert := alertConfiguration.EventLevel == model.EventLevelAccount
 	if isAccounAlert {
@@ -562,8 +663,11 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 				Error("failed to check slack integration")
 		}
 		if isSlackIntergrated {
+			startTime := time.Now()
 			partialSlackSuccess, _, errMsg := sendSlackAlertForEventTriggerAlert(eta.ProjectID,
 				eta.SlackChannelAssociatedBy, alert, alertConfiguration.SlackChannels, alertConfiguration.SlackMentions, alertConfiguration.IsHyperlinkDisabled, isAccounAlert, accountUrl, hubspotAccountUrl, salesforceAccountUrl)
+			recordEventTriggerAlertDelivery(eta, alert, model.SLACK, getJsonbAsString(alertConfiguration.SlackChannels),
+				deliveryPayload, partialSlackSuccess, errMsg, startTime, retry)
 			log.WithFields(log.Fields{
 				"project_id": eta.ProjectID,
 				"alert_id":   eta.ID,
@@ -599,8 +703,11 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 				Error("failed to check teams integration")
 		}
 		if isTeamsIntergrated {
+			startTime := time.Now()
 			teamsSuccess, errMsg := sendTeamsAlertForEventTriggerAlert(eta.ProjectID,
 				eta.TeamsChannelAssociatedBy, alert.Message, alertConfiguration.TeamsChannelsConfig, isAccounAlert, accountUrl)
+			recordEventTriggerAlertDelivery(eta, alert, model.TEAMS, getJsonbAsString(alertConfiguration.TeamsChannelsConfig),
+				deliveryPayload, teamsSuccess, errMsg, startTime, retry)
 			log.WithFields(log.Fields{
 				"project_id": eta.ProjectID,
 				"alert_id":   eta.ID,
@@ -622,6 +729,29 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 
 	}
 
+	// If retry is true and sendTo var is set for Email option
+	// OR
+	// If the retry is off, meaning the alert is from the first try or rejected queue and configuration
+	//		for alert is set for Email option
+	// Alerts are only added to the digest here, digests are sent by SendEventTriggerAlertEmailDigests.
+	if (retry && strings.EqualFold(EMAIL, sendTo)) || (!retry && alertConfiguration.Email) {
+		digestItem := model.EventTriggerAlertDigestItem{
+			Message:    alert.Message,
+			AccountURL: accountUrl,
+			Timestamp:  U.TimeNowUnix(),
+		}
+		err := model.AddToEventTriggerAlertEmailDigest(eta.ProjectID, eta.ID, digestItem)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to add alert to email digest.")
+			sendReport.EmailFail++
+			errMessage = append(errMessage, "Failed to add alert to the email digest.")
+			deliveryFailures = append(deliveryFailures, EMAIL)
+		} else {
+			// Counted as success once the digest is sent.
+			sendReport.EmailQueued++
+		}
+	}
+
 	// If retry is true and sendTo var is set for Webhook option
 	// OR
 	// If the retry is off, meaning the alert is from the first try or rejected queue and configuration
@@ -645,6 +775,7 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 			}
 		}
 
+		startTime := time.Now()
 		if strings.Contains(alertConfiguration.WebhookURL, ParagonUrlRune) {
 			response, err = paragon.SendPayloadToParagonForTheAlert(eta.ProjectID, eta.ID, &alertConfiguration, alert)
 			if err != nil {
@@ -653,7 +784,13 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 			}
 			logCtx.WithField("response", response).Info("paragon response")
 		} else {
-			response, err = webhook.DropWebhook(alertConfiguration.WebhookURL, alertConfiguration.Secret, alert.Message)
+			response, err = webhook.DropWebhookWithRetries(eta.ProjectID, alertConfiguration.WebhookURL,
+				alertConfiguration.Secret, !alertConfiguration.IsLegacySecretHeaderDisabled, alert.Message,
+				func(response map[string]interface{}, err error) {
+					addRetriedWebhookDelivery(retriedWebhookDelivery{eta: eta, alert: alert,
+						destination: alertConfiguration.WebhookURL, payload: deliveryPayload,
+						response: response, err: err, startTime: startTime, retry: retry})
+				})
 			if err != nil {
 				logCtx.WithFields(log.Fields{"alert_id": alertID, "server_response": response}).
 					WithError(err).Error("Webhook failure")
@@ -678,25 +815,59 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 			"is_payload_null": isPayloadNull,
 		}).Info("ALERT TRACKER.")
 
-		if response["error"] == "<nil>" {
-			response["error"] = "an"
-		}
-		if stat != "success" {
-			log.WithField("status", stat).WithField("response", response).Error("Web hook error details")
-			sendReport.WebhookFail++
-			errMessage = append(errMessage, fmt.Sprintf("Webhook host reported %v error", response["error"]))
-			deliveryFailures = append(deliveryFailures, WEBHOOK)
-
+		if stat == webhook.DeliveryStatusRetrying {
+			// Outcome of the delivery is recorded by completeRetriedWebhookDeliveries.
+			sendReport.WebhookRetrying++
 		} else {
-			sendReport.WebhookSuccess++
+			webhookErrMsg := ""
+			if stat != "success" {
+				webhookErrMsg = fmt.Sprintf("%v", response["error"])
+				if err != nil {
+					webhookErrMsg = err.Error()
+				}
+			}
+			recordEventTriggerAlertDelivery(eta, alert, model.WEBHOOK, alertConfiguration.WebhookURL,
+				deliveryPayload, stat == "success", webhookErrMsg, startTime, retry)
+
+			if response["error"] == "<nil>" {
+				response["error"] = "an"
+			}
+			if stat != "success" {
+				log.WithField("status", stat).WithField("response", response).Error("Web hook error details")
+				sendReport.WebhookFail++
+				errMessage = append(errMessage, fmt.Sprintf("Webhook host reported %v error", response["error"]))
+				deliveryFailures = append(deliveryFailures, WEBHOOK)
+
+			} else {
+				sendReport.WebhookSuccess++
+			}
 		}
 
 	}
 
+	totalSuccess, partialSuccess = updateEventTriggerAlertSendStatus(key, eta, sendReport,
+		deliveryFailures, errMessage, rejectedQueue, true)
+	return totalSuccess, partialSuccess, sendReport
+}
+
+// updateEventTriggerAlertSendStatus writes the outcome of the sends of the alert on the
+// alert, last_fail_details on a failure and last_alert_at on a success. Failures are added
+// to the retries of the alert only if addToRetries is set.
+func updateEventTriggerAlertSendStatus(key *cache.Key, eta *model.EventTriggerAlert,
+	sendReport SendReportLogCount, deliveryFailures, errMessage []string,
+	rejectedQueue, addToRetries bool) (totalSuccess bool, partialSuccess bool) {
+
+	logCtx := log.WithFields(log.Fields{"project_id": eta.ProjectID, "alert_id": eta.ID, "key": key})
+
 	totalSuccess, partialSuccess = findTotalAndPartialSuccess(sendReport)
 	// not total success means there has been atleast one failure
 	if !totalSuccess {
-		err := EventTriggerDeliveryFailureExecution(key, eta, deliveryFailures, errMessage, rejectedQueue, partialSuccess)
+		var err error
+		if addToRetries {
+			err = EventTriggerDeliveryFailureExecution(key, eta, deliveryFailures, errMessage, rejectedQueue, partialSuccess)
+		} else {
+			err = updateEventTriggerAlertLastFailDetails(eta, deliveryFailures, errMessage)
+		}
 		if err != nil {
 			logCtx.WithError(err).Error("failed while updating teams-fail flow")
 		}
@@ -711,7 +882,258 @@ func sendHelperForEventTriggerAlert(key *cache.Key, alert *model.CachedEventTrig
 		}
 	}
 
-	return totalSuccess, partialSuccess, sendReport
+	return totalSuccess, partialSuccess
+}
+
+// recordEventTriggerAlertDelivery adds the outcome of a send to the delivery log of the alert.
+// Failures to record are only logged, to not affect the delivery itself.
+func recordEventTriggerAlertDelivery(eta *model.EventTriggerAlert, alert *model.CachedEventTriggerAlert,
+	deliveryOption, destination string, payload *postgres.Jsonb, success bool, errMsg string,
+	startTime time.Time, retry bool) {
+
+	status := model.DeliveryStatusSuccess
+	if !success {
+		status = model.DeliveryStatusFailure
+	}
+
+	delivery := model.EventTriggerAlertDelivery{
+		ProjectID:      eta.ProjectID,
+		AlertID:        eta.ID,
+		DeliveryOption: deliveryOption,
+		Destination:    destination,
+		Payload:        payload,
+		Status:         status,
+		Error:          errMsg,
+		LatencyInMs:    time.Since(startTime).Milliseconds(),
+		IsRetry:        retry,
+		ReplayOf:       alert.ReplayOf,
+	}
+	if errCode := store.GetStore().CreateEventTriggerAlertDelivery(&delivery); errCode != http.StatusCreated {
+		log.WithFields(log.Fields{"project_id": eta.ProjectID, "alert_id": eta.ID, "delivery_option": deliveryOption}).
+			Error("Failed to record event trigger alert delivery.")
+	}
+}
+
+// addRetriedWebhookDelivery keeps the outcome of a webhook delivery retried in the
+// background. It is called from the retries, so it only collects the outcome.
+func addRetriedWebhookDelivery(delivery retriedWebhookDelivery) {
+	retriedWebhookDeliveriesMutex.Lock()
+	defer retriedWebhookDeliveriesMutex.Unlock()
+	retriedWebhookDeliveries = append(retriedWebhookDeliveries, delivery)
+}
+
+// completeRetriedWebhookDeliveries waits for the webhook deliveries being retried in the
+// background and records their outcome on the alerts, through the same path as the other
+// sends. Failed deliveries are kept as dead letters by the webhooks package, so they are
+// not added to the retries of the alert. Returns the report by project.
+func completeRetriedWebhookDeliveries() map[int64]SendReportLogCount {
+	webhook.WaitForRetries()
+
+	retriedWebhookDeliveriesMutex.Lock()
+	deliveries := retriedWebhookDeliveries
+	retriedWebhookDeliveries = make([]retriedWebhookDelivery, 0)
+	retriedWebhookDeliveriesMutex.Unlock()
+
+	reports := make(map[int64]SendReportLogCount)
+	for _, delivery := range deliveries {
+		sendReport := SendReportLogCount{}
+		deliveryFailures := make([]string, 0)
+		errMessage := make([]string, 0)
+
+		success := delivery.err == nil && delivery.response["status"] == "success"
+		errMsg := ""
+		if success {
+			sendReport.WebhookSuccess++
+		} else {
+			errMsg = fmt.Sprintf("%v", delivery.response["error"])
+			if delivery.err != nil {
+				errMsg = delivery.err.Error()
+			}
+			log.WithFields(log.Fields{"project_id": delivery.eta.ProjectID, "alert_id": delivery.eta.ID,
+				"response": delivery.response}).WithError(delivery.err).Error("Webhook failed after all retries.")
+			sendReport.WebhookFail++
+			deliveryFailures = append(deliveryFailures, WEBHOOK)
+			errMessage = append(errMessage, fmt.Sprintf("Webhook host reported %v error", errMsg))
+		}
+		recordEventTriggerAlertDelivery(delivery.eta, delivery.alert, model.WEBHOOK, delivery.destination,
+			delivery.payload, success, errMsg, delivery.startTime, delivery.retry)
+
+		updateEventTriggerAlertSendStatus(nil, delivery.eta, sendReport, deliveryFailures, errMessage, false, false)
+		report := reports[delivery.eta.ProjectID]
+		report.addToSendReport(sendReport)
+		reports[delivery.eta.ProjectID] = report
+	}
+	return reports
+}
+
+// getSendToForDeliveryOption maps the delivery option of the delivery log to the sendTo of retries.
+func getSendToForDeliveryOption(deliveryOption string) string {
+	switch deliveryOption {
+	case model.SLACK:
+		return SLACK
+	case model.TEAMS:
+		return TEAMS
+	case model.WEBHOOK:
+		return WEBHOOK
+	case model.EMAIL:
+		return EMAIL
+	}
+	return ""
+}
+
+/*
+EVENT TRIGGER ALERT EMAIL DIGESTS
+
+# Picks alert ids from ETA:Digest:pid:<project_id> sorted set
+# Sends the digest of an alert as a single email to each recipient, once the digest interval has passed
+# Digests of the alerts with email turned off are dropped, digests of paused or disabled alerts are kept
+# On success the sent alerts are removed from the digest, on failure the recipients already sent to are
+kept and the digest is sent only to the rest on the next run
+*/
+func SendEventTriggerAlertEmailDigests(projectID int64) SendReportLogCount {
+	logCtx := log.WithField("project_id", projectID)
+	sendReport := SendReportLogCount{}
+
+	alertIDs, err := model.GetPendingEventTriggerAlertDigests(projectID)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get pending email digests.")
+		return sendReport
+	}
+
+	for _, alertID := range alertIDs {
+		digestLogCtx := logCtx.WithField("alert_id", alertID)
+
+		digest, err := model.GetEventTriggerAlertEmailDigest(projectID, alertID)
+		if err != nil {
+			digestLogCtx.WithError(err).Error("Failed to get email digest.")
+			continue
+		}
+
+		eta, errCode := store.GetStore().GetEventTriggerAlertByID(alertID)
+		if errCode == http.StatusNotFound || digest == nil {
+			if err := model.DeleteEventTriggerAlertEmailDigest(projectID, alertID); err != nil {
+				digestLogCtx.WithError(err).Error("Failed to remove email digest.")
+			}
+			continue
+		}
+		if errCode != http.StatusFound {
+			digestLogCtx.WithField("err_code", errCode).Error("Failed to fetch alert from db.")
+			continue
+		}
+
+		var alertConfiguration model.EventTriggerAlertConfig
+		err = U.DecodePostgresJsonbToStructType(eta.EventTriggerAlert, &alertConfiguration)
+		if err != nil {
+			digestLogCtx.WithError(err).Error("Failed to decode Jsonb to struct type")
+			continue
+		}
+
+		// Alert could have changed after the alerts were added to the digest.
+		if !alertConfiguration.Email || len(alertConfiguration.Emails) == 0 {
+			digestLogCtx.Info("Email is turned off for the alert. Dropping the email digest.")
+			if err := model.DeleteEventTriggerAlertEmailDigest(projectID, alertID); err != nil {
+				digestLogCtx.WithError(err).Error("Failed to remove email digest.")
+			}
+			continue
+		}
+		if eta.InternalStatus == model.Paused || eta.InternalStatus == model.Disabled {
+			digestLogCtx.WithField("internal_status", eta.InternalStatus).Info("Alert is not active. Skipping the email digest.")
+			continue
+		}
+
+		if !model.IsEventTriggerAlertDigestDue(digest, alertConfiguration.EmailDigestIntervalInMins, U.TimeNowUnix()) {
+			continue
+		}
+
+		sentCount, failedCount := sendEmailDigestForEventTriggerAlert(eta, &alertConfiguration, digest)
+		sendReport.EmailSuccess += sentCount
+		sendReport.EmailFail += failedCount
+		if failedCount > 0 {
+			digestLogCtx.WithField("failed_count", failedCount).Error("Failed to send email digest.")
+			continue
+		}
+
+		if err := model.CompleteEventTriggerAlertEmailDigest(projectID, digest); err != nil {
+			digestLogCtx.WithError(err).Error("Failed to remove email digest.")
+		}
+		status, err := store.GetStore().UpdateEventTriggerAlertField(eta.ProjectID, eta.ID,
+			map[string]interface{}{"last_alert_at": U.TimeNowZ()})
+		if status != http.StatusAccepted || err != nil {
+			digestLogCtx.WithError(err).Error("Failed to update db field")
+		}
+	}
+	return sendReport
+}
+
+// sendEmailDigestForEventTriggerAlert sends the digest to the recipients which haven't
+// received it yet and returns the count of emails sent and failed. On failure, the
+// recipients sent to are kept on the digest, for the next run to skip them.
+func sendEmailDigestForEventTriggerAlert(eta *model.EventTriggerAlert, alertConfiguration *model.EventTriggerAlertConfig,
+	digest *model.EventTriggerAlertEmailDigest) (int, int) {
+
+	logCtx := log.WithField("project_id", eta.ProjectID).WithField("alert_id", eta.ID)
+
+	startTime := time.Now()
+	subject, html, text, err := model.GetEventTriggerAlertDigestEmailContent(alertConfiguration.Title,
+		alertConfiguration.EventLevel, digest, alertConfiguration.EmailDigestIntervalInMins)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get email digest content.")
+		return 0, 1
+	}
+
+	payload, err := U.EncodeStructTypeToPostgresJsonb(digest)
+	if err != nil {
+		log.WithError(err).Error("Failed to encode email digest for delivery log")
+	}
+
+	sentTo := append(make([]string, 0), digest.SentTo...)
+	sentCount, failedCount := 0, 0
+	for _, email := range alertConfiguration.Emails {
+		if U.ContainsStringInArray(sentTo, email) {
+			continue
+		}
+
+		recipientStartTime := time.Now()
+		err := C.GetServices().Mailer.SendMail(email, C.GetFactorsSenderEmail(), subject, html, text)
+		errMsg := ""
+		if err != nil {
+			logCtx.WithField("email", email).WithError(err).Error("Failed to send email digest to recipient.")
+			errMsg = fmt.Sprintf("Failed to send email to %s", email)
+			failedCount++
+		} else {
+			sentTo = append(sentTo, email)
+			sentCount++
+		}
+		recordEventTriggerAlertDelivery(eta, &model.CachedEventTriggerAlert{}, model.EMAIL,
+			email, payload, err == nil, errMsg, recipientStartTime, len(digest.SentTo) > 0)
+	}
+
+	if failedCount > 0 && sentCount > 0 {
+		if err := model.SetEventTriggerAlertEmailDigestSentTo(eta.ProjectID, digest, sentTo); err != nil {
+			logCtx.WithError(err).Error("Failed to keep the recipients of the email digest.")
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"project_id":   eta.ProjectID,
+		"alert_id":     eta.ID,
+		"mode":         EMAIL,
+		"digest_count": digest.Count,
+		"sent_count":   sentCount,
+		"failed_count": failedCount,
+		"is_success":   failedCount == 0,
+		"latency_ms":   time.Since(startTime).Milliseconds(),
+		"tag":          "alert_tracker",
+	}).Info("ALERT TRACKER.")
+
+	return sentCount, failedCount
+}
+
+func getJsonbAsString(value *postgres.Jsonb) string {
+	if value == nil {
+		return ""
+	}
+	return string(value.RawMessage)
 }
 
 /*
@@ -752,6 +1174,13 @@ func EventTriggerDeliveryFailureExecution(key *cache.Key, eta *model.EventTrigge
 		}
 	}
 
+	return updateEventTriggerAlertLastFailDetails(eta, deliveryFailures, errMsg)
+}
+
+// updateEventTriggerAlertLastFailDetails updates the last_fail_details column of the alert.
+func updateEventTriggerAlertLastFailDetails(eta *model.EventTriggerAlert, deliveryFailures, errMsg []string) error {
+	logCtx := log.WithFields(log.Fields{"project_id": eta.ProjectID, "alert_id": eta.ID})
+
 	errDetails := model.LastFailDetails{
 		FailTime: U.TimeNowZ(),
 		FailedAt: deliveryFailures,
@@ -1250,6 +1679,9 @@ func RetryFailedEventTriggerAlerts(projectID int64, blockedAlerts map[string]boo
 		if strings.Contains(orgKey[0], "Teams") {
 			sendTo = "Teams"
 		}
+		if strings.Contains(orgKey[0], "Email") {
+			sendTo = "Email"
+		}
 
 		var totalSuccess bool
 		sendReport := SendReportLogCount{}
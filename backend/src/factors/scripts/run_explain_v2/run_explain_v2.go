@@ -11,6 +11,7 @@ import (
 	serviceDisk "factors/services/disk"
 	serviceEtcd "factors/services/etcd"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	"factors/util"
 	"flag"
@@ -56,6 +57,7 @@ func main() {
 	numWorkersFlag := flag.Int("num_beam_workers", 100, "Num of beam workers")
 
 	tmpBucketNameFlag := flag.String("bucket_name_tmp", "/usr/local/var/factors/cloud_storage_tmp", "--bucket_name=/usr/local/var/factors/cloud_storage_tmp pass bucket name for tmp artifacts")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	archiveBucketNameFlag := flag.String("archive_bucket_name", "/usr/local/var/factors/cloud_storage_archive", "--bucket_name=/usr/local/var/factors/cloud_storage_archive pass archive bucket name")
 	sortedBucketNameFlag := flag.String("sorted_bucket_name", "/usr/local/var/factors/cloud_storage_sorted", "--bucket_name=/usr/local/var/factors/cloud_storage_sorted pass sorted bucket name")
 	modelBucketNameFlag := flag.String("model_bucket_name", "/usr/local/var/factors/cloud_storage_models", "--bucket_name=/usr/local/var/factors/cloud_storage_models pass models bucket name")
@@ -199,7 +201,9 @@ func main() {
 	}
 
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *tmpBucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*tmpBucketNameFlag)
 	} else {
 		cloudManager, err = serviceGCS.New(*tmpBucketNameFlag)
@@ -251,7 +255,11 @@ func main() {
 	var archiveCloudManager filestore.FileManager
 	var sortedCloudManager filestore.FileManager
 	var modelCloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		modelCloudManager = serviceLocalFS.New(*localFSRootFlag, *modelBucketNameFlag)
+		archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+		sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+	} else if *envFlag == "development" {
 		modelCloudManager = serviceDisk.New(*modelBucketNameFlag)
 		archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 		sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	C "factors/config"
+	"factors/filestore"
+	serviceDisk "factors/services/disk"
+	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
+	eventsImport "factors/task/events_import"
+	"flag"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Imports the historical events on a csv or ndjson file to the project. Columns are mapped to
+// the track payload using the mapping config and the rejected rows are written to the errors
+// file on the same location. Sessions are not created, run add_session for the imported range.
+func main() {
+	envFlag := flag.String("env", C.DEVELOPMENT, "Environment. Could be development|staging|production.")
+	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
+	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
+	memSQLPort := flag.Int("memsql_port", C.MemSQLDefaultDBParams.Port, "")
+	memSQLUser := flag.String("memsql_user", C.MemSQLDefaultDBParams.User, "")
+	memSQLName := flag.String("memsql_name", C.MemSQLDefaultDBParams.Name, "")
+	memSQLPass := flag.String("memsql_pass", C.MemSQLDefaultDBParams.Password, "")
+	memSQLCertificate := flag.String("memsql_cert", "", "")
+	primaryDatastore := flag.String("primary_datastore", C.DatastoreTypeMemSQL, "Primary datastore type as memsql or postgres")
+
+	redisHost := flag.String("redis_host", "localhost", "")
+	redisPort := flag.Int("redis_port", 6379, "")
+	redisHostPersistent := flag.String("redis_host_ps", "localhost", "")
+	redisPortPersistent := flag.Int("redis_port_ps", 6379, "")
+
+	projectID := flag.Int64("project_id", 0, "Project to import the events to.")
+	bucketNameFlag := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "--bucket_name=/usr/local/var/factors/cloud_storage pass bucket name")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
+	fileDir := flag.String("file_dir", "", "Dir of the file on the bucket.")
+	fileName := flag.String("file_name", "", "Name of the csv or ndjson file.")
+	mapping := flag.String("mapping", "", `Mapping config as json. e.g {"format": "csv", "event_name": "event", "timestamp": "time", "timestamp_format": "unix", "customer_user_id": "email", "event_properties": {"$page_url": "url"}}`)
+	dryRun := flag.Bool("dry_run", false, "Validate and map the rows, without importing.")
+	allowPastEventsEnrichmentByProjectID := flag.String("allow_past_events_enrichment_by_project_id", "",
+		"Projects to use the user properties of the row on the imported event, instead of the current properties of the user.")
+	overrideHealthcheckPingID := flag.String("healthcheck_ping_id", "", "Healthcheck ping id, if any.")
+
+	flag.Parse()
+
+	appName := "import_events"
+	healthcheckPingID := *overrideHealthcheckPingID
+	defer C.PingHealthcheckForPanic(appName, *envFlag, healthcheckPingID)
+
+	if *projectID == 0 || *fileName == "" || *mapping == "" {
+		log.Fatal("project_id, file_name and mapping are required.")
+	}
+
+	var mappingConfig eventsImport.MappingConfig
+	if err := json.Unmarshal([]byte(*mapping), &mappingConfig); err != nil {
+		log.WithError(err).Fatal("Failed to decode mapping config.")
+	}
+	if err := mappingConfig.Validate(); err != nil {
+		log.WithError(err).Fatal("Invalid mapping config.")
+	}
+
+	config := &C.Configuration{
+		AppName: appName,
+		Env:     *envFlag,
+		MemSQLInfo: C.DBConf{
+			Host:        *memSQLHost,
+			IsPSCHost:   *isPSCHost,
+			Port:        *memSQLPort,
+			User:        *memSQLUser,
+			Name:        *memSQLName,
+			Password:    *memSQLPass,
+			Certificate: *memSQLCertificate,
+			AppName:     appName,
+		},
+		PrimaryDatastore:    *primaryDatastore,
+		RedisHost:           *redisHost,
+		RedisPort:           *redisPort,
+		RedisHostPersistent: *redisHostPersistent,
+		RedisPortPersistent: *redisPortPersistent,
+		AllowHubspotPastEventsEnrichmentByProjectID: *allowPastEventsEnrichmentByProjectID,
+	}
+	C.InitConf(config)
+
+	err := C.InitDB(*config)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize DB.")
+	}
+	db := C.GetServices().Db
+	defer db.Close()
+
+	C.InitRedis(config.RedisHost, config.RedisPort)
+	C.InitRedisPersistent(config.RedisHostPersistent, config.RedisPortPersistent)
+
+	var cloudManager filestore.FileManager
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketNameFlag)
+	} else if *envFlag == "development" {
+		cloudManager = serviceDisk.New(*bucketNameFlag)
+	} else {
+		cloudManager, err = serviceGCS.New(*bucketNameFlag)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to init cloud manager.")
+		}
+	}
+
+	status, err := eventsImport.ImportEvents(*projectID, cloudManager, *fileDir, *fileName, &mappingConfig, *dryRun)
+	if err != nil {
+		log.WithError(err).WithField("status", status).Error("Events import failed.")
+		if healthcheckPingID != "" {
+			C.PingHealthcheckForFailure(healthcheckPingID, "Events import failed.")
+		}
+		return
+	}
+
+	log.WithField("status", status).Info("Events import completed.")
+	if healthcheckPingID != "" {
+		C.PingHealthcheckForSuccess(healthcheckPingID, status)
+	}
+}
@@ -19,6 +19,7 @@ import (
 func main() {
 	env := flag.String("env", C.DEVELOPMENT, "")
 	bucketNameFlag := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "--bucket_name=/usr/local/var/factors/cloud_storage pass bucket name")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
 	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
@@ -76,7 +77,11 @@ func main() {
 	C.InitConf(config)
 	C.InitSenderEmail(C.GetFactorsSenderEmail())
 	C.InitMailClient(config.AWSKey, config.AWSSecret, config.AWSRegion)
-	C.InitFilemanager(*bucketNameFlag, *env, config)
+	if *localFSRootFlag != "" {
+		C.InitLocalFSFilemanager(*localFSRootFlag, *bucketNameFlag, config)
+	} else {
+		C.InitFilemanager(*bucketNameFlag, *env, config)
+	}
 	err := C.InitDB(*config)
 	if err != nil {
 		log.Fatal("Init failed.")
@@ -9,6 +9,7 @@ import (
 	"factors/pull"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	"factors/util"
 	"flag"
 	"fmt"
@@ -55,6 +56,7 @@ func mainRunNumericalBucketing() {
 	localDiskTmpDirFlag := flag.String("local_disk_tmp_dir", "/usr/local/var/factors/local_disk/tmp", "--local_disk_tmp_dir=/usr/local/var/factors/local_disk/tmp pass directory")
 
 	tmpBucketNameFlag := flag.String("bucket_name_tmp", "/usr/local/var/factors/cloud_storage_tmp", "--bucket_name=/usr/local/var/factors/cloud_storage_tmp pass bucket name for tmp artifacts")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	archiveBucketNameFlag := flag.String("archive_bucket_name", "/usr/local/var/factors/cloud_storage_archive", "--bucket_name=/usr/local/var/factors/cloud_storage_archive pass archive bucket name")
 	sortedBucketNameFlag := flag.String("sorted_bucket_name", "/usr/local/var/factors/cloud_storage_sorted", "--bucket_name=/usr/local/var/factors/cloud_storage_sorted pass sorted bucket name")
 
@@ -178,7 +180,9 @@ func mainRunNumericalBucketing() {
 	configs := make(map[string]interface{})
 
 	var cloudManagerTmp filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManagerTmp = serviceLocalFS.New(*localFSRootFlag, *tmpBucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManagerTmp = serviceDisk.New(*tmpBucketNameFlag)
 	} else {
 		cloudManagerTmp, err = serviceGCS.New(*tmpBucketNameFlag)
@@ -190,7 +194,10 @@ func mainRunNumericalBucketing() {
 
 	var archiveCloudManager filestore.FileManager
 	var sortedCloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+		sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+	} else if *envFlag == "development" {
 		archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 		sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
 	} else {
@@ -17,6 +17,7 @@ import (
 	"factors/filestore"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 
 	"factors/model/store"
 
@@ -43,6 +44,7 @@ func registerStructs() {
 func main() {
 	env := flag.String("env", C.DEVELOPMENT, "")
 	tmpBucketNameFlag := flag.String("bucket_name_tmp", "/usr/local/var/factors/cloud_storage_tmp", "--bucket_name=/usr/local/var/factors/cloud_storage_tmp pass bucket name for tmp artifacts")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	archiveBucketNameFlag := flag.String("archive_bucket_name", "/usr/local/var/factors/cloud_storage_archive", "--bucket_name=/usr/local/var/factors/cloud_storage_archive pass archive bucket name")
 	sortedBucketNameFlag := flag.String("sorted_bucket_name", "/usr/local/var/factors/cloud_storage_sorted", "--bucket_name=/usr/local/var/factors/cloud_storage_sorted pass sorted bucket name")
 	modelBucketNameFlag := flag.String("model_bucket_name", "/usr/local/var/factors/cloud_storage_models", "--bucket_name=/usr/local/var/factors/cloud_storage_models pass models bucket name")
@@ -145,7 +147,9 @@ func main() {
 	configs := make(map[string]interface{})
 	// Init cloud manager.
 	var cloudManagerTmp filestore.FileManager
-	if *env == "development" {
+	if *localFSRootFlag != "" {
+		cloudManagerTmp = serviceLocalFS.New(*localFSRootFlag, *tmpBucketNameFlag)
+	} else if *env == "development" {
 		cloudManagerTmp = serviceDisk.New(*tmpBucketNameFlag)
 	} else {
 		cloudManagerTmp, err = serviceGCS.New(*tmpBucketNameFlag)
@@ -157,7 +161,11 @@ func main() {
 	var archiveCloudManager filestore.FileManager
 	var sortedCloudManager filestore.FileManager
 	var modelCloudManager filestore.FileManager
-	if *env == "development" {
+	if *localFSRootFlag != "" {
+		modelCloudManager = serviceLocalFS.New(*localFSRootFlag, *modelBucketNameFlag)
+		archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+		sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+	} else if *env == "development" {
 		modelCloudManager = serviceDisk.New(*modelBucketNameFlag)
 		archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 		sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
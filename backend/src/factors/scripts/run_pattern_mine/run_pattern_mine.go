@@ -11,6 +11,7 @@ import (
 	serviceDisk "factors/services/disk"
 	serviceEtcd "factors/services/etcd"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	taskWrapper "factors/task/task_wrapper"
 	"factors/util"
@@ -55,6 +56,7 @@ func main() {
 	localDiskTmpDirFlag := flag.String("local_disk_tmp_dir", "/usr/local/var/factors/local_disk/tmp",
 		"--local_disk_tmp_dir=/usr/local/var/factors/local_disk/tmp pass directory")
 	tmpBucketNameFlag := flag.String("bucket_name_tmp", "/usr/local/var/factors/cloud_storage_tmp", "--bucket_name=/usr/local/var/factors/cloud_storage_tmp pass bucket name for tmp artifacts")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	archiveBucketNameFlag := flag.String("archive_bucket_name", "/usr/local/var/factors/cloud_storage_archive", "--bucket_name=/usr/local/var/factors/cloud_storage_archive pass archive bucket name")
 	sortedBucketNameFlag := flag.String("sorted_bucket_name", "/usr/local/var/factors/cloud_storage_sorted", "--bucket_name=/usr/local/var/factors/cloud_storage_sorted pass sorted data bucket name")
 	modelBucketNameFlag := flag.String("model_bucket_name", "/usr/local/var/factors/cloud_storage_models", "--bucket_name=/usr/local/var/factors/cloud_storage_models pass model bucket name")
@@ -224,7 +226,9 @@ func main() {
 	configs := make(map[string]interface{})
 
 	var cloudManagerTmp filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManagerTmp = serviceLocalFS.New(*localFSRootFlag, *tmpBucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManagerTmp = serviceDisk.New(*tmpBucketNameFlag)
 	} else {
 		cloudManagerTmp, err = serviceGCS.New(*tmpBucketNameFlag)
@@ -237,7 +241,11 @@ func main() {
 	var archiveCloudManager filestore.FileManager
 	var sortedCloudManager filestore.FileManager
 	var modelCloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		modelCloudManager = serviceLocalFS.New(*localFSRootFlag, *modelBucketNameFlag)
+		archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+		sortedCloudManager = serviceLocalFS.New(*localFSRootFlag, *sortedBucketNameFlag)
+	} else if *envFlag == "development" {
 		modelCloudManager = serviceDisk.New(*modelBucketNameFlag)
 		archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 		sortedCloudManager = serviceDisk.New(*sortedBucketNameFlag)
@@ -10,6 +10,7 @@ import (
 	P "factors/pattern"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	U "factors/util"
 	"flag"
 	"fmt"
@@ -233,6 +234,7 @@ var validations = make(map[string]map[string]validationRule)
 func main() {
 
 	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	envFlag := flag.String("env", "development", "environment")
 	date := time.Now().AddDate(0, 0, -7)
 	for date.Weekday() != time.Sunday {
@@ -262,12 +264,18 @@ func main() {
 	C.InitSenderEmail(C.GetFactorsSenderEmail())
 	//C.InitMailClient(config.AWSKey, config.AWSSecret, config.AWSRegion)
 	C.InitRedisPersistent(config.RedisHostPersistent, config.RedisPortPersistent)
-	C.InitFilemanager(*bucketName, *envFlag, config)
+	if *localFSRootFlag != "" {
+		C.InitLocalFSFilemanager(*localFSRootFlag, *bucketName, config)
+	} else {
+		C.InitFilemanager(*bucketName, *envFlag, config)
+	}
 	//emails := strings.Split(*emailString, ",")
 	flag.Parse()
 
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketName)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*bucketName)
 	} else {
 		var err error
@@ -12,6 +12,7 @@ import (
 	"factors/model/store"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	taskWrapper "factors/task/task_wrapper"
 	U "factors/util"
@@ -26,6 +27,7 @@ import (
 func main() {
 	env := flag.String("env", C.DEVELOPMENT, "")
 	archiveBucketNameFlag := flag.String("archive_bucket_name", "/usr/local/var/factors/cloud_storage_archive", "--bucket_name=/usr/local/var/factors/cloud_storage_archive pass archive bucket name")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	memSQLHost := flag.String("memsql_host", C.MemSQLDefaultDBParams.Host, "")
 	isPSCHost := flag.Int("memsql_is_psc_host", C.MemSQLDefaultDBParams.IsPSCHost, "")
@@ -210,7 +212,9 @@ func main() {
 	configs := make(map[string]interface{})
 	// Init cloud manager.
 	var archiveCloudManager filestore.FileManager
-	if *env == "development" {
+	if *localFSRootFlag != "" {
+		archiveCloudManager = serviceLocalFS.New(*localFSRootFlag, *archiveBucketNameFlag)
+	} else if *env == "development" {
 		archiveCloudManager = serviceDisk.New(*archiveBucketNameFlag)
 	} else {
 		archiveCloudManager, err = serviceGCS.New(*archiveBucketNameFlag)
@@ -9,6 +9,7 @@ import (
 	"factors/filestore"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	T "factors/task"
 	"factors/util"
 
@@ -18,6 +19,7 @@ import (
 func main() {
 	envFlag := flag.String("env", "development", "Environment. Could be development|staging|production.")
 	bucketNameFlag := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "Bucket name for production.")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 	projectIDFlag := flag.Int64("project_id", 0, "Project id to be run for.")
 	runForAllFlag := flag.Bool("all", false, "Whether to run for all project with bigquery enabled.")
 	startDateFlag := flag.String("start_date", "", "Start date in format YYYY-MM-DD to process older files. Inclusive.")
@@ -87,7 +89,9 @@ func main() {
 	defer C.WaitAndFlushAllCollectors(65 * time.Second)
 
 	var cloudManager filestore.FileManager
-	if *envFlag == "development" {
+	if *localFSRootFlag != "" {
+		cloudManager = serviceLocalFS.New(*localFSRootFlag, *bucketNameFlag)
+	} else if *envFlag == "development" {
 		cloudManager = serviceDisk.New(*bucketNameFlag)
 	} else {
 		cloudManager, err = serviceGCS.New(*bucketNameFlag)
@@ -58,6 +58,7 @@ func main() {
 	memSQLDBMaxOpenConnections := flag.Int("memsql_max_open_connections", 100, "Max no.of open connections allowed on connection pool of memsql")
 	memSQLDBMaxIdleConnections := flag.Int("memsql_max_idle_connections", 50, "Max no.of idle connections allowed on connection pool of memsql")
 	bucketName := flag.String("bucket_name", "/usr/local/var/factors/cloud_storage", "")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	flag.Parse()
 
@@ -113,7 +114,11 @@ func main() {
 	C.InitConf(config)
 	C.InitRedisPersistent(config.RedisHostPersistent, config.RedisPortPersistent)
 	C.InitSentryLogging(config.SentryDSN, config.AppName)
-	C.InitFilemanager(*bucketName, *env, config)
+	if *localFSRootFlag != "" {
+		C.InitLocalFSFilemanager(*localFSRootFlag, *bucketName, config)
+	} else {
+		C.InitFilemanager(*bucketName, *env, config)
+	}
 
 	err := C.InitDB(*config)
 	if err != nil {
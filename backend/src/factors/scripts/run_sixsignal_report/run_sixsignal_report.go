@@ -7,6 +7,7 @@ import (
 	"factors/model/store"
 	serviceDisk "factors/services/disk"
 	serviceGCS "factors/services/gcstorage"
+	serviceLocalFS "factors/services/localfs"
 	U "factors/util"
 	"flag"
 	"fmt"
@@ -19,6 +20,7 @@ import (
 func main() {
 	env := flag.String("env", C.DEVELOPMENT, "")
 	modelBucketNameFlag := flag.String("model_bucket_name", "/usr/local/var/factors/cloud_storage_models", "--bucket_name=/usr/local/var/factors/cloud_storage_models pass models bucket name")
+	localFSRootFlag := flag.String("local_fs_root", "", "Root dir to keep buckets on the local filesystem, instead of cloud storage.")
 
 	hardPull := flag.Bool("hard_pull", false, "replace the files already present")
 	localDiskTmpDirFlag := flag.String("local_disk_tmp_dir", "/usr/local/var/factors/local_disk/tmp", "--local_disk_tmp_dir=/usr/local/var/factors/local_disk/tmp pass directory.")
@@ -107,7 +109,9 @@ func main() {
 	configs := make(map[string]interface{})
 
 	var modelCloudManager filestore.FileManager
-	if *env == "development" {
+	if *localFSRootFlag != "" {
+		modelCloudManager = serviceLocalFS.New(*localFSRootFlag, *modelBucketNameFlag)
+	} else if *env == "development" {
 		modelCloudManager = serviceDisk.New(*modelBucketNameFlag)
 	} else {
 		modelCloudManager, err = serviceGCS.New(*modelBucketNameFlag)
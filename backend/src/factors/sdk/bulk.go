@@ -0,0 +1,294 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"factors/model/model"
+	"factors/model/store"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	BulkItemTypeTrack             = "track"
+	BulkItemTypeIdentify          = "identify"
+	BulkItemTypeAddUserProperties = "add_user_properties"
+
+	// BulkMaxItems is the maximum number of items processed on a bulk request.
+	BulkMaxItems = 50000
+	// bulkMaxItemSizeInBytes is the maximum size of a line on ndjson bulk request.
+	bulkMaxItemSizeInBytes = 1024 * 1024
+)
+
+var ErrBulkItemsLimitExceeded = fmt.Errorf("request exceeds the limit of %d items", BulkMaxItems)
+
+// BulkRequestItem is an item of the bulk request. Payload is the request payload of the type,
+// i.e TrackPayload, IdentifyPayload or AddUserPropertiesPayload. Type defaults to track.
+type BulkRequestItem struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type BulkItemResponse struct {
+	Index    int         `json:"index"`
+	Type     string      `json:"type,omitempty"`
+	Status   int         `json:"status"`
+	Response interface{} `json:"response,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	// Duplicate is set on the track items skipped as the c_event_id is tracked already.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+type BulkResponse struct {
+	Items      []*BulkItemResponse `json:"items"`
+	Succeeded  int                 `json:"succeeded"`
+	Failed     int                 `json:"failed"`
+	Duplicates int                 `json:"duplicates"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// BulkRequestContext holds the request level details used for processing each item.
+type BulkRequestContext struct {
+	Token              string
+	ClientIP           string
+	UserAgent          string
+	QueueAllowedTokens []string
+	// c_event_id to index of the first item with it.
+	seenCustomerEventIDs map[string]int
+	// project of the token, resolved on the first item with c_event_id.
+	projectID        int64
+	projectIDErrCode int
+}
+
+func NewBulkRequestContext(token, clientIP, userAgent string, queueAllowedTokens []string) *BulkRequestContext {
+	return &BulkRequestContext{
+		Token:                token,
+		ClientIP:             clientIP,
+		UserAgent:            userAgent,
+		QueueAllowedTokens:   queueAllowedTokens,
+		seenCustomerEventIDs: make(map[string]int),
+	}
+}
+
+// getEventIDByCustomerEventID looks up the event tracked earlier with the c_event_id, on the project of the token.
+func (requestContext *BulkRequestContext) getEventIDByCustomerEventID(customerEventID string) (string, int) {
+	if requestContext.projectIDErrCode == 0 {
+		requestContext.projectID, requestContext.projectIDErrCode = store.GetStore().GetProjectIDByToken(requestContext.Token)
+	}
+	if requestContext.projectIDErrCode != http.StatusFound {
+		return "", requestContext.projectIDErrCode
+	}
+	return store.GetStore().GetEventIDByCustomerEventID(requestContext.projectID, customerEventID)
+}
+
+// IsBulkItemSuccess tells if the item is processed. Bot requests skipped are also considered processed.
+func IsBulkItemSuccess(status int) bool {
+	return status == http.StatusOK || status == http.StatusNotModified
+}
+
+// ReadBulkRequestItems reads the items of the body one by one and calls process with each. Body can be
+// a json array or newline delimited json, optionally gzipped. An item which can't be decoded is passed
+// with the error, so that it is responded on the item, while the rest of the items are processed.
+func ReadBulkRequestItems(body io.Reader, isGzip bool, process func(index int, item *BulkRequestItem, err error)) error {
+	reader := bufio.NewReader(body)
+
+	// gzip is identified by the magic bytes too, as clients miss setting the header.
+	if magic, err := reader.Peek(2); isGzip || (err == nil && magic[0] == 0x1f && magic[1] == 0x8b) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = bufio.NewReader(gzipReader)
+	}
+
+	isArray, err := isJSONArrayBody(reader)
+	if err != nil {
+		return err
+	}
+	if isArray {
+		return readBulkRequestItemsFromJSONArray(reader, process)
+	}
+	return readBulkRequestItemsFromNDJSON(reader, process)
+}
+
+// isJSONArrayBody checks the first non space character of the body, without consuming it.
+func isJSONArrayBody(reader *bufio.Reader) (bool, error) {
+	for {
+		char, _, err := reader.ReadRune()
+		if err != nil {
+			return false, err
+		}
+		if strings.TrimSpace(string(char)) == "" {
+			continue
+		}
+		return char == '[', reader.UnreadRune()
+	}
+}
+
+func decodeBulkRequestItem(rawItem []byte) (*BulkRequestItem, error) {
+	var item BulkRequestItem
+	if err := json.Unmarshal(rawItem, &item); err != nil {
+		return nil, err
+	}
+	if item.Type == "" {
+		item.Type = BulkItemTypeTrack
+	}
+	return &item, nil
+}
+
+func readBulkRequestItemsFromJSONArray(reader io.Reader, process func(index int, item *BulkRequestItem, err error)) error {
+	decoder := json.NewDecoder(reader)
+	// opening bracket of the array.
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	index := 0
+	for decoder.More() {
+		if index >= BulkMaxItems {
+			return ErrBulkItemsLimitExceeded
+		}
+
+		var rawItem json.RawMessage
+		if err := decoder.Decode(&rawItem); err != nil {
+			// decoding can't continue on a malformed array.
+			return err
+		}
+		item, err := decodeBulkRequestItem(rawItem)
+		process(index, item, err)
+		index++
+	}
+	return nil
+}
+
+func readBulkRequestItemsFromNDJSON(reader io.Reader, process func(index int, item *BulkRequestItem, err error)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), bulkMaxItemSizeInBytes)
+
+	index := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if index >= BulkMaxItems {
+			return ErrBulkItemsLimitExceeded
+		}
+
+		item, err := decodeBulkRequestItem(line)
+		process(index, item, err)
+		index++
+	}
+	return scanner.Err()
+}
+
+// ValidateTrackPayload validates the track payload received from outside.
+func ValidateTrackPayload(payload *TrackPayload) error {
+	if payload.EventId != "" {
+		return errors.New("event_id is not allowed")
+	}
+	if strings.TrimSpace(payload.Name) == "" {
+		return errors.New("event_name cannot be omitted or left empty")
+	}
+	if payload.Timestamp < 0 {
+		return errors.New("invalid timestamp")
+	}
+	if payload.CustomerEventId != nil && *payload.CustomerEventId == "" {
+		return errors.New("c_event_id cannot be empty")
+	}
+	return nil
+}
+
+// ProcessBulkRequestItem validates and processes the item with the queue enabled method of its type.
+func ProcessBulkRequestItem(requestContext *BulkRequestContext, index int, item *BulkRequestItem) *BulkItemResponse {
+	response := &BulkItemResponse{Index: index, Type: item.Type}
+
+	switch item.Type {
+	case BulkItemTypeTrack:
+		var payload TrackPayload
+		if err := json.Unmarshal(item.Payload, &payload); err != nil {
+			response.Status, response.Error = http.StatusBadRequest, "Invalid track payload."
+			return response
+		}
+		if err := ValidateTrackPayload(&payload); err != nil {
+			response.Status, response.Error = http.StatusBadRequest, fmt.Sprintf("Invalid track payload. %s.", err.Error())
+			return response
+		}
+
+		if payload.CustomerEventId != nil {
+			if firstIndex, exists := requestContext.seenCustomerEventIDs[*payload.CustomerEventId]; exists {
+				response.Status, response.Duplicate = http.StatusConflict, true
+				response.Error = fmt.Sprintf("Duplicate c_event_id of item %d.", firstIndex)
+				return response
+			}
+			requestContext.seenCustomerEventIDs[*payload.CustomerEventId] = index
+
+			// on failure of the lookup, the item is tracked and the event creation rejects the duplicate.
+			eventID, status := requestContext.getEventIDByCustomerEventID(*payload.CustomerEventId)
+			if status == http.StatusFound {
+				response.Status, response.Duplicate = http.StatusConflict, true
+				response.Response = &TrackResponse{EventId: eventID, CustomerEventId: payload.CustomerEventId,
+					Message: "Event with the c_event_id is tracked already."}
+				return response
+			}
+		}
+
+		payload.ClientIP = requestContext.ClientIP
+		payload.UserAgent = requestContext.UserAgent
+		payload.RequestSource = model.UserSourceWeb
+		status, trackResponse := TrackWithQueue(requestContext.Token, &payload, requestContext.QueueAllowedTokens)
+		response.Status, response.Response = status, trackResponse
+		if trackResponse != nil {
+			response.Error = trackResponse.Error
+		}
+
+	case BulkItemTypeIdentify:
+		var payload IdentifyPayload
+		if err := json.Unmarshal(item.Payload, &payload); err != nil {
+			response.Status, response.Error = http.StatusBadRequest, "Invalid identify payload."
+			return response
+		}
+		if payload.CustomerUserId == "" {
+			response.Status, response.Error = http.StatusBadRequest, "Invalid identify payload. c_uid cannot be empty."
+			return response
+		}
+
+		payload.RequestSource = model.UserSourceWeb
+		status, identifyResponse := IdentifyWithQueue(requestContext.Token, &payload, requestContext.QueueAllowedTokens)
+		response.Status, response.Response = status, identifyResponse
+		if identifyResponse != nil {
+			response.Error = identifyResponse.Error
+		}
+
+	case BulkItemTypeAddUserProperties:
+		var payload AddUserPropertiesPayload
+		if err := json.Unmarshal(item.Payload, &payload); err != nil {
+			response.Status, response.Error = http.StatusBadRequest, "Invalid add user properties payload."
+			return response
+		}
+		if len(payload.Properties) == 0 {
+			response.Status, response.Error = http.StatusBadRequest, "Invalid add user properties payload. properties cannot be empty."
+			return response
+		}
+
+		payload.ClientIP = requestContext.ClientIP
+		payload.RequestSource = model.UserSourceWeb
+		status, addPropertiesResponse := AddUserPropertiesWithQueue(requestContext.Token, &payload,
+			requestContext.QueueAllowedTokens)
+		response.Status, response.Response = status, addPropertiesResponse
+		if addPropertiesResponse != nil {
+			response.Error = addPropertiesResponse.Error
+		}
+
+	default:
+		response.Status, response.Error = http.StatusBadRequest, fmt.Sprintf("Invalid item type %s.", item.Type)
+	}
+
+	return response
+}
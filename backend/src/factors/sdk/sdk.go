@@ -6,10 +6,8 @@ import (
 	"factors/cache"
 	pCache "factors/cache/persistent"
 	cacheRedis "factors/cache/redis"
-	"factors/company_enrichment/clearbit"
-	"factors/company_enrichment/demandbase"
+	"factors/company_enrichment"
 	"factors/company_enrichment/factors_deanon"
-	"factors/company_enrichment/sixsignal"
 	"factors/model/model"
 	"factors/model/store"
 	"factors/util"
@@ -159,6 +157,9 @@ const (
 	SourceHubspot     = "hubspot"
 	SourceSalesforce  = "salesforce"
 	SourceRudderstack = "rudderstack"
+
+	// SourceEventsImport is the source of events imported from files, by the events import job.
+	SourceEventsImport = "events_import"
 )
 
 // RequestQueue - Name of the primary queue which will
@@ -859,7 +860,8 @@ func Track(projectId int64, request *TrackPayload,
 	}
 	event.Properties = postgres.Jsonb{RawMessage: eventPropsJSON}
 
-	if C.PastEventEnrichmentEnabled(projectId) && U.IsCRM(source) && request.IsPast {
+	isPastEnrichmentSource := C.PastEventEnrichmentEnabled(projectId) && (U.IsCRM(source) || source == SourceEventsImport)
+	if isPastEnrichmentSource && request.IsPast {
 		event.IsFromPast = true
 
 		userProperties, err := U.EncodeToPostgresJsonb((*map[string]interface{})(&request.UserProperties))
@@ -907,58 +909,25 @@ func FillCompanyIdentificationUserProperties(projectId int64, clientIP string, p
 		"project_id": projectId,
 		"logId":      fmt.Sprintf("%v+%v", projectId, clientIP)})
 
-	var customerDemandbase demandbase.CustomerDemandbase
-	var customerClearbit clearbit.CustomerClearbit
-	var customerSixSignal sixsignal.CustomerSixSignal
-	var factorsDeanon factors_deanon.FactorsDeanon
-
-	if enrichByCustomerDemandbase, _ := customerDemandbase.IsEligible(projectSettings, logCtx); enrichByCustomerDemandbase {
-		if _, ok := customerDemandbase.Enrich(projectSettings, userProperties, userId, clientIP, logCtx); ok == 1 {
-			status := store.GetStore().UpdateProjectSettingsIntegrationStatus(projectId, model.FEATURE_DEMANDBASE, model.SUCCESS)
-			if status != http.StatusAccepted {
-				log.WithFields(log.Fields{"project_id": projectId}).Warn("Failed to update integration status")
-
-			}
-		}
-	} else if enrichByCustomerClearbit, _ := customerClearbit.IsEligible(projectSettings, logCtx); enrichByCustomerClearbit {
-		if _, ok := customerClearbit.Enrich(projectSettings, userProperties, userId, clientIP, logCtx); ok == 1 {
-			status := store.GetStore().UpdateProjectSettingsIntegrationStatus(projectId, model.FEATURE_CLEARBIT, model.SUCCESS)
-			if status != http.StatusAccepted {
-				log.WithFields(log.Fields{"project_id": projectId}).Warn("Failed to update integration status")
-
-			}
-		}
-	} else if enrichByCustomerSixSignal, _ := customerSixSignal.IsEligible(projectSettings, logCtx); enrichByCustomerSixSignal {
-		if _, ok := customerSixSignal.Enrich(projectSettings, userProperties, userId, clientIP, logCtx); ok == 1 {
-			status := store.GetStore().UpdateProjectSettingsIntegrationStatus(projectId, model.FEATURE_SIX_SIGNAL, model.SUCCESS)
-			if status != http.StatusAccepted {
-				log.WithFields(log.Fields{"project_id": projectId}).Warn("Failed to update integration status")
-
-			}
-		}
-	} else if enrichByFactorsDeanon, _ := factorsDeanon.IsEligible(projectSettings, isoCode, pageUrl, logCtx); enrichByFactorsDeanon {
-		domain, status := factorsDeanon.Enrich(projectSettings, userProperties, eventProperties, userId, clientIP, logCtx)
-		if status == 1 {
-			factorsDeanon.Meter(projectId, domain, logCtx)
-
-			status := store.GetStore().UpdateProjectSettingsIntegrationStatus(projectId, model.FEATURE_FACTORS_DEANONYMISATION, model.SUCCESS)
-			if status != http.StatusAccepted {
-				log.WithFields(log.Fields{"project_id": projectId}).Warn("Failed to update integration status")
-
-			}
-		}
+	request := company_enrichment.EnrichmentRequest{
+		ProjectSettings: projectSettings,
+		UserProperties:  userProperties,
+		EventProperties: eventProperties,
+		UserId:          userId,
+		ClientIP:        clientIP,
+		IsoCode:         isoCode,
+		PageURL:         pageUrl,
+	}
+	company_enrichment.EnrichByWaterfall(&request, logCtx)
 
+	// alerts on the usage of the account limit, after every deanonymisation attempt.
+	if request.IsAttemptedBy(model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON) {
+		var factorsDeanon factors_deanon.FactorsDeanon
 		errCode, err := factorsDeanon.HandleAccountLimitAlert(projectId, &http.Client{}, logCtx)
 		if errCode != http.StatusOK && errCode != http.StatusForbidden {
 			logCtx.WithField("error", err).Error("Failed to send account limit alert.")
 		}
-	} else if isDeanonQuotaAvailable, err := factors_deanon.CheckingFactorsDeanonQuotaLimit(projectId); err == nil && !isDeanonQuotaAvailable {
-		status := store.GetStore().UpdateProjectSettingsIntegrationStatus(projectId, model.FEATURE_FACTORS_DEANONYMISATION, model.LIMIT_EXCEED)
-		if status != http.StatusAccepted {
-			log.WithFields(log.Fields{"project_id": projectId}).Warn("Failed to update integration status")
-		}
 	}
-
 }
 
 func getURLFromPageEvent(properties U.PropertiesMap) string {
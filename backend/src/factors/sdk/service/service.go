@@ -5,7 +5,6 @@ import (
 	H "factors/handler"
 
 	"flag"
-	"strconv"
 
 	mid "factors/middleware"
 
@@ -150,6 +149,8 @@ func main() {
 		return
 	}
 	defer C.SafeFlushAllCollectors()
+	// Usages of the api keys counted in memory are written before exit.
+	defer mid.FlushAPIKeyUsages()
 
 	C.InitPropertiesTypeCache(*enablePropertyTypeFromDB, *propertiesTypeCacheSize,
 		*whitelistedProjectIDPropertyTypeFromDB, *blacklistedProjectIDPropertyTypeFromDB)
@@ -172,5 +173,6 @@ func main() {
 	}
 	H.InitSDKServiceRoutes(r)
 	H.InitAccountRoutes(r)
-	r.Run(":" + strconv.Itoa(C.GetConfig().Port))
+	H.InitCRMRoutes(r)
+	C.RunServerWithGracefulShutdown(r, C.GetConfig().Port)
 }
@@ -108,6 +108,14 @@ func (dd *DiskDriver) GetObjectSize(path, fileName string) (int64, error) {
 	return objSize, err
 }
 
+func (dd *DiskDriver) Delete(path, fileName string) error {
+	if !strings.HasSuffix(path, separator) {
+		// Append / to the end if not present.
+		path = path + separator
+	}
+	return os.Remove(path + fileName)
+}
+
 // ListFiles List files present in a directory.
 func (dd *DiskDriver) ListFiles(path string) []string {
 	var files []string
@@ -575,6 +583,11 @@ func (dd *DiskDriver) GetSixSignalAnalysisTempFilePathAndName(id string, project
 	return path, "results.txt"
 }
 
+func (dd *DiskDriver) GetChartImageFilePathAndName(projectId int64, imageName string) (string, string) {
+	path := dd.GetProjectDir(projectId)
+	return fmt.Sprintf("%schart_images/", path), imageName
+}
+
 func (dd *DiskDriver) GetAccScoreDir(projectId int64) string {
 	proj_dir := dd.GetProjectDir(projectId)
 	path := fmt.Sprintf("%saccscore/", proj_dir)
@@ -106,6 +106,15 @@ func (gcsd *GCSDriver) GetObjectSize(dir, fileName string) (int64, error) {
 	}
 }
 
+func (gcsd *GCSDriver) Delete(dir, fileName string) error {
+	ctx := context.Background()
+	if !strings.HasSuffix(dir, separator) {
+		// Append / to the end if not present.
+		dir = dir + separator
+	}
+	return gcsd.client.Bucket(gcsd.BucketName).Object(dir + fileName).Delete(ctx)
+}
+
 // ListFiles List files present in a folder in cloud storage. Prefix has to be without bucket name.
 // Must not have leading '/' and should have trailing '/' in prefix. Ex: archive/3/.
 func (gcsd *GCSDriver) ListFiles(prefix string) []string {
@@ -570,6 +579,11 @@ func (gcsd *GCSDriver) GetSixSignalAnalysisTempFilePathAndName(id string, projec
 	return path, "results.txt"
 }
 
+func (gcsd *GCSDriver) GetChartImageFilePathAndName(projectId int64, imageName string) (string, string) {
+	path := gcsd.GetProjectDir(projectId)
+	return fmt.Sprintf("%schart_images/", path), imageName
+}
+
 func (gcsd *GCSDriver) GetAccScoreDir(projectId int64) string {
 	proj_path := gcsd.GetProjectDir(projectId)
 	path := fmt.Sprintf("%saccscore", proj_path)
@@ -0,0 +1,145 @@
+package localfs
+
+import (
+	"factors/filestore"
+	serviceGCS "factors/services/gcstorage"
+	"io"
+	"os"
+	pb "path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	separator = "/"
+	// Suffix of files being written. These are not visible to Get or ListFiles
+	// until closed, like objects on cloud storage.
+	tempFileSuffix = ".localfs_tmp"
+)
+
+var _ filestore.FileManager = (*LocalFSDriver)(nil)
+
+// LocalFSDriver keeps files of a bucket under <rootDir>/<bucketName>, using the same
+// path layout as the cloud storage driver. Paths are relative to the bucket, so data
+// written by the pipeline jobs can be read back by any other job pointing to the same root.
+type LocalFSDriver struct {
+	// Path layout is shared with cloud storage.
+	serviceGCS.GCSDriver
+	rootDir string
+}
+
+func New(rootDir, bucketName string) *LocalFSDriver {
+	return &LocalFSDriver{
+		GCSDriver: serviceGCS.GCSDriver{BucketName: bucketName},
+		rootDir:   rootDir,
+	}
+}
+
+// GetBucketDir returns the directory on disk which holds the files of the bucket.
+func (ld *LocalFSDriver) GetBucketDir() string {
+	return pb.Join(ld.rootDir, ld.BucketName)
+}
+
+func (ld *LocalFSDriver) getFilePath(dir, fileName string) string {
+	return pb.Join(ld.GetBucketDir(), dir, fileName)
+}
+
+func (ld *LocalFSDriver) Create(dir, fileName string, reader io.Reader) error {
+	writer, err := ld.GetWriter(dir, fileName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		writer.(*fileWriter).abort()
+		return err
+	}
+	return writer.Close()
+}
+
+// GetWriter returns a writer to the file. Caller should close the writer
+// for the file to be available.
+func (ld *LocalFSDriver) GetWriter(dir, fileName string) (io.WriteCloser, error) {
+	filePath := ld.getFilePath(dir, fileName)
+	if err := os.MkdirAll(pb.Dir(filePath), 0755); err != nil {
+		log.WithError(err).WithField("path", filePath).Error("Failed to create dir")
+		return nil, err
+	}
+
+	file, err := os.Create(filePath + tempFileSuffix)
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{File: file, filePath: filePath}, nil
+}
+
+// Get opens a file in read only mode.
+// Caller should take care of closing the returned io.ReadCloser.
+func (ld *LocalFSDriver) Get(dir, fileName string) (io.ReadCloser, error) {
+	return os.Open(ld.getFilePath(dir, fileName))
+}
+
+func (ld *LocalFSDriver) GetObjectSize(dir, fileName string) (int64, error) {
+	fileInfo, err := os.Stat(ld.getFilePath(dir, fileName))
+	if err != nil {
+		return 0, err
+	}
+	return fileInfo.Size(), nil
+}
+
+func (ld *LocalFSDriver) Delete(dir, fileName string) error {
+	return os.Remove(ld.getFilePath(dir, fileName))
+}
+
+// ListFiles lists all the files under the prefix recursively, same as cloud storage.
+// Returned names are relative to the bucket. Ex: archive/3/events.txt.
+func (ld *LocalFSDriver) ListFiles(prefix string) []string {
+	files := make([]string, 0)
+	if !strings.HasSuffix(prefix, separator) {
+		prefix = prefix + separator
+	}
+
+	bucketDir := ld.GetBucketDir()
+	err := pb.Walk(pb.Join(bucketDir, prefix), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(path, tempFileSuffix) {
+			return nil
+		}
+
+		relativePath, err := pb.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, pb.ToSlash(relativePath))
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("prefix", prefix).Error("Failed to list files.")
+	}
+
+	// Cloud storage lists objects in lexicographic order.
+	sort.Strings(files)
+	return files
+}
+
+// fileWriter writes to a temp file which is moved to the actual path on close.
+type fileWriter struct {
+	*os.File
+	filePath string
+}
+
+func (fw *fileWriter) Close() error {
+	if err := fw.File.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fw.File.Name(), fw.filePath)
+}
+
+func (fw *fileWriter) abort() {
+	fw.File.Close()
+	os.Remove(fw.File.Name())
+}
@@ -0,0 +1,59 @@
+package localfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	U "factors/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bucketName = "test-bucket"
+
+func TestPathLayoutSameAsCloudStorage(t *testing.T) {
+	localFSDriver := New(t.TempDir(), bucketName)
+	projectId := U.RandomInt64()
+	modelId := U.RandomUint64()
+
+	assert.Equal(t, fmt.Sprintf("projects/%d/", projectId), localFSDriver.GetProjectDir(projectId))
+	assert.Equal(t, fmt.Sprintf("projects/%d/models/%d/", projectId, modelId),
+		localFSDriver.GetProjectModelDir(projectId, modelId))
+	assert.Equal(t, bucketName, localFSDriver.GetBucketName())
+}
+
+func TestCreateGetAndList(t *testing.T) {
+	localFSDriver := New(t.TempDir(), bucketName)
+	projectId := U.RandomInt64()
+	dir := localFSDriver.GetProjectDir(projectId)
+	content := []byte("line1\nline2\n")
+
+	err := localFSDriver.Create(dir, "a.txt", bytes.NewReader(content))
+	assert.Nil(t, err)
+	err = localFSDriver.Create(dir+"nested/", "b.txt", bytes.NewReader(content))
+	assert.Nil(t, err)
+
+	reader, err := localFSDriver.Get(dir, "a.txt")
+	assert.Nil(t, err)
+	got, err := ioutil.ReadAll(reader)
+	reader.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, content, got)
+
+	size, err := localFSDriver.GetObjectSize(dir, "a.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)), size)
+
+	// Files being written are not listed until closed.
+	writer, err := localFSDriver.GetWriter(dir, "c.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{dir + "a.txt", dir + "nested/b.txt"}, localFSDriver.ListFiles(dir))
+	_, err = writer.Write(content)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	assert.Equal(t, []string{dir + "a.txt", dir + "c.txt", dir + "nested/b.txt"}, localFSDriver.ListFiles(dir))
+	assert.Empty(t, localFSDriver.ListFiles(fmt.Sprintf("projects/%d/missing/", projectId)))
+}
@@ -253,6 +253,10 @@ func (sd *S3Driver) GetDailyChannelArchiveFilePathAndName(channel string, projec
 }
 
 // ListFiles - Placeholder definition. Has to be implemented.
+func (sd *S3Driver) Delete(dir, fileName string) error {
+	return nil
+}
+
 func (sd *S3Driver) ListFiles(path string) []string {
 	return []string{}
 }
@@ -448,6 +452,11 @@ func (sd *S3Driver) GetSixSignalAnalysisTempFilePathAndName(id string, projectId
 	return path, "results.txt"
 }
 
+func (sd *S3Driver) GetChartImageFilePathAndName(projectId int64, imageName string) (string, string) {
+	path := sd.GetProjectDir(projectId)
+	return pb.Join(path, "chart_images"), imageName
+}
+
 func (sd *S3Driver) GetAccScoreDir(projectId int64) string {
 	proj_path := sd.GetProjectDir(projectId)
 	path := fmt.Sprintf("%saccscore", proj_path)
@@ -64,6 +64,7 @@ func DeduplicateWeights(weights M.AccWeights) (M.AccWeights, error) {
 
 	updatedWeights.WeightConfig = weightrulelist
 	updatedWeights.SaleWindow = weights.SaleWindow
+	M.CopyScoringMode(&weights, &updatedWeights)
 	return updatedWeights, nil
 }
 
@@ -93,6 +93,13 @@ func BuildAccScoringDaily(projectId int64, configs map[string]interface{}) (map[
 		delete(userEventMap, model.LAST_EVENT)
 	}
 
+	// on failure to fit, scores are computed with the previously fitted weights, if fitted on the same config.
+	if weights.WeightMode == M.ACC_SCORE_WEIGHT_MODE_FITTED && IsFitDue(&weights, time.Now().Unix()) {
+		if err := UpdateFittedWeights(projectId, &weights); err != nil {
+			logCtx.WithError(err).Warn("Unable to fit weights from deals")
+		}
+	}
+
 	mweights, _ := CreateweightMap(&weights)
 	for tm.Unix() <= start_time.Unix() {
 		dateString := U.GetDateOnlyFromTimestamp(tm.Unix())
@@ -109,9 +116,12 @@ func BuildAccScoringDaily(projectId int64, configs map[string]interface{}) (map[
 
 	// from prevusers pick groups which are avaialable
 	now := time.Now()
-	_, err = WriteUserCountsAndRangesToDB(projectId, now.Unix(), prevCountsOfUser, updatedUsers, updatedGroups, groupUserMap, weights, salewindow, lookback)
+	groupCounts, err := WriteUserCountsAndRangesToDB(projectId, now.Unix(), prevCountsOfUser, updatedUsers, updatedGroups, groupUserMap, weights, salewindow, lookback)
 	if err != nil {
 		logCtx.WithError(err).Errorf("error in updating user counts to DB")
+	} else if err := SnapshotDealCounts(projectId, weights, groupCounts, now.Unix()); err != nil {
+		// counts of the accounts on the close of the deals are used for fitting the weights.
+		logCtx.WithError(err).Warn("Unable to snapshot counts of closed deals")
 	}
 
 	logCtx.Infof("Number of updated groups:%d", len(updatedGroups))
@@ -581,7 +591,7 @@ func UpdateLastEventsDay(prevCountsOfUser map[string]map[string]M.LatestScore,
 				if !ook {
 					counts.EventsCount = make(map[string]float64, 0)
 				}
-				decayval := model.ComputeDecayValue(dateOfCount, int64(saleWindow))
+				decayval := model.ComputeDecayValueForWeights(dateOfCount, &weigths)
 				if decayval > 0 {
 					if len(counts.EventsCount) > 0 {
 						lastEventDay = dateOfCount
@@ -893,6 +903,7 @@ func RemoveDeletedWeights(weights *M.AccWeights) M.AccWeights {
 	var mweights M.AccWeights
 
 	mweights.SaleWindow = weights.SaleWindow
+	M.CopyScoringMode(weights, &mweights)
 	mweights.WeightConfig = make([]M.AccEventWeight, 0)
 
 	for _, w := range weights.WeightConfig {
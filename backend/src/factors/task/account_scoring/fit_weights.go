@@ -0,0 +1,287 @@
+package accountscoring
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	M "factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	MIN_DEALS_PER_OUTCOME_TO_FIT = 5
+	MAX_FITTED_WEIGHT            = 100
+	FIT_ITERATIONS               = 1000
+	FIT_LEARNING_RATE            = 0.5
+	FIT_L2_PENALTY               = 0.01
+	// weights are refitted on the interval, or when the config changes.
+	FIT_INTERVAL_IN_DAYS = 7
+	// deals closed before are not snapshotted, as the counts of the days before the close
+	// are not kept on the account anymore.
+	MAX_DAYS_TO_SNAPSHOT_CLOSED_DEAL = 7
+)
+
+// SnapshotDealCounts keeps the counts of the accounts on the sale window before the close
+// of their recently closed deals, for fitting the weights on them later.
+func SnapshotDealCounts(projectId int64, weights M.AccWeights,
+	countsOfUser map[string]map[string]M.LatestScore, currentTS int64) error {
+
+	outcomes, status := store.GetStore().GetAccountDealOutcomes(projectId)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	if status != http.StatusFound {
+		return fmt.Errorf("unable to get deal outcomes of accounts : %d", status)
+	}
+
+	snapshots := BuildDealSnapshots(weights, outcomes, countsOfUser, currentTS)
+	if len(snapshots) == 0 {
+		return nil
+	}
+	if status := store.GetStore().CreateAccountDealSnapshots(projectId, snapshots); status != http.StatusCreated {
+		return fmt.Errorf("unable to create deal snapshots : %d", status)
+	}
+	return nil
+}
+
+// BuildDealSnapshots returns the counts of the accounts on the sale window before the close of
+// the deals closed on the last MAX_DAYS_TO_SNAPSHOT_CLOSED_DEAL days. Accounts without events
+// on the window are skipped.
+func BuildDealSnapshots(weights M.AccWeights, outcomes []M.AccDealOutcome,
+	countsOfUser map[string]map[string]M.LatestScore, currentTS int64) []M.AccDealSnapshot {
+
+	snapshots := make([]M.AccDealSnapshot, 0)
+	for _, outcome := range outcomes {
+		closedAt := outcome.ClosedAt
+		if closedAt <= 0 || closedAt > currentTS || closedAt < currentTS-MAX_DAYS_TO_SNAPSHOT_CLOSED_DEAL*U.SECONDS_IN_A_DAY {
+			continue
+		}
+		countsOnDays, ok := countsOfUser[outcome.AccountID]
+		if !ok {
+			continue
+		}
+		windowStart := closedAt - weights.SaleWindow*U.SECONDS_IN_A_DAY
+
+		counts := make(map[string]M.LatestScore)
+		for day, countsOnDay := range countsOnDays {
+			if day == M.LAST_EVENT || countsOnDay.Date > closedAt || countsOnDay.Date < windowStart ||
+				len(countsOnDay.EventsCount) == 0 {
+				continue
+			}
+			counts[day] = M.LatestScore{Date: countsOnDay.Date, EventsCount: countsOnDay.EventsCount}
+		}
+		if len(counts) == 0 {
+			continue
+		}
+
+		snapshots = append(snapshots, M.AccDealSnapshot{AccountID: outcome.AccountID, Won: outcome.Won,
+			ClosedAt: closedAt, Counts: counts})
+	}
+	return snapshots
+}
+
+// FitWeightsFromDeals fits the weight values from the won and lost deals of the accounts,
+// using the decayed counts of the weights over the sale window before the deal was closed,
+// as snapshotted on the close of the deal.
+func FitWeightsFromDeals(projectId int64, weights M.AccWeights) (map[string]float32, error) {
+
+	logCtx := log.WithField("projectId", projectId)
+
+	snapshots, status := store.GetStore().GetAccountDealSnapshots(projectId)
+	if status != http.StatusFound {
+		return nil, fmt.Errorf("unable to get deal snapshots of accounts : %d", status)
+	}
+
+	weightIds := make([]string, 0)
+	for _, w := range weights.WeightConfig {
+		if !w.Is_deleted {
+			weightIds = append(weightIds, w.WeightId)
+		}
+	}
+	if len(weightIds) == 0 {
+		return nil, fmt.Errorf("no weights to fit")
+	}
+
+	features, labels := BuildDealTrainingSet(weights, weightIds, snapshots)
+	numWon := 0
+	for _, label := range labels {
+		if label == 1 {
+			numWon++
+		}
+	}
+	numLost := len(labels) - numWon
+	if numWon < MIN_DEALS_PER_OUTCOME_TO_FIT || numLost < MIN_DEALS_PER_OUTCOME_TO_FIT {
+		return nil, fmt.Errorf("not enough won (%d) and lost (%d) deals to fit weights", numWon, numLost)
+	}
+
+	coefficients, _ := FitLogisticRegression(features, labels, FIT_ITERATIONS, FIT_LEARNING_RATE, FIT_L2_PENALTY)
+	fittedWeights, err := ScaleCoefficientsToWeights(weightIds, coefficients)
+	if err != nil {
+		return nil, err
+	}
+
+	logCtx.WithFields(log.Fields{"won": numWon, "lost": numLost, "weights": fittedWeights}).Info("Fitted account scoring weights")
+	return fittedWeights, nil
+}
+
+// BuildDealTrainingSet returns the decayed counts of the weights on the sale window before the
+// close of the deal of the snapshots as features and the outcome of the deal as label. Snapshots
+// without events of the weights on the window are skipped.
+func BuildDealTrainingSet(weights M.AccWeights, weightIds []string, snapshots []M.AccDealSnapshot) ([][]float64, []float64) {
+
+	features := make([][]float64, 0)
+	labels := make([]float64, 0)
+	for _, snapshot := range snapshots {
+		closedAt := snapshot.ClosedAt
+		windowStart := closedAt - weights.SaleWindow*U.SECONDS_IN_A_DAY
+
+		counts := make([]float64, len(weightIds))
+		hasEvents := false
+		for _, countsOnDay := range snapshot.Counts {
+			// events before the window don't explain the outcome, sale window could be updated after the snapshot.
+			if countsOnDay.Date > closedAt || countsOnDay.Date < windowStart {
+				continue
+			}
+			decay := M.ComputeDecayValueGivenStartEndTSForWeights(closedAt, countsOnDay.Date, &weights)
+			if decay <= 0 {
+				continue
+			}
+			for idx, weightId := range weightIds {
+				if count, exists := countsOnDay.EventsCount[weightId]; exists && count > 0 {
+					counts[idx] += decay * count
+					hasEvents = true
+				}
+			}
+		}
+		if !hasEvents {
+			continue
+		}
+
+		features = append(features, counts)
+		if snapshot.Won {
+			labels = append(labels, 1)
+		} else {
+			labels = append(labels, 0)
+		}
+	}
+	return features, labels
+}
+
+// FitLogisticRegression fits the coefficients and the intercept with batch gradient descent
+// and L2 regularisation. Features are scaled by the max value of the feature for the fit
+// and the coefficients are returned on the original scale.
+func FitLogisticRegression(features [][]float64, labels []float64, iterations int,
+	learningRate float64, l2Penalty float64) ([]float64, float64) {
+
+	if len(features) == 0 {
+		return nil, 0
+	}
+	numFeatures := len(features[0])
+	scales := make([]float64, numFeatures)
+	for _, row := range features {
+		for idx, value := range row {
+			scales[idx] = math.Max(scales[idx], math.Abs(value))
+		}
+	}
+	for idx := range scales {
+		if scales[idx] == 0 {
+			scales[idx] = 1
+		}
+	}
+
+	coefficients := make([]float64, numFeatures)
+	intercept := 0.0
+	numRows := float64(len(features))
+	for iteration := 0; iteration < iterations; iteration++ {
+		gradients := make([]float64, numFeatures)
+		interceptGradient := 0.0
+		for rowIdx, row := range features {
+			z := intercept
+			for idx, value := range row {
+				z += coefficients[idx] * value / scales[idx]
+			}
+			diff := sigmoid(z) - labels[rowIdx]
+			for idx, value := range row {
+				gradients[idx] += diff * value / scales[idx]
+			}
+			interceptGradient += diff
+		}
+		for idx := range coefficients {
+			coefficients[idx] -= learningRate * (gradients[idx]/numRows + l2Penalty*coefficients[idx])
+		}
+		intercept -= learningRate * interceptGradient / numRows
+	}
+
+	for idx := range coefficients {
+		coefficients[idx] = coefficients[idx] / scales[idx]
+	}
+	return coefficients, intercept
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// ScaleCoefficientsToWeights maps the coefficients to weight values with the highest
+// weight as MAX_FITTED_WEIGHT. Weights with negative coefficients are set to 0, as the
+// scores are not expected to go down on an event.
+func ScaleCoefficientsToWeights(weightIds []string, coefficients []float64) (map[string]float32, error) {
+	maxCoefficient := 0.0
+	for _, coefficient := range coefficients {
+		maxCoefficient = math.Max(maxCoefficient, coefficient)
+	}
+	if maxCoefficient <= 0 {
+		return nil, fmt.Errorf("none of the weights are positively associated with won deals")
+	}
+
+	fittedWeights := make(map[string]float32, len(weightIds))
+	for idx, weightId := range weightIds {
+		value := math.Max(coefficients[idx], 0) / maxCoefficient * MAX_FITTED_WEIGHT
+		fittedWeights[weightId] = float32(math.Round(value*100) / 100)
+	}
+	return fittedWeights, nil
+}
+
+// IsFitDue tells whether the weights are to be fitted, when not fitted on the current config
+// or not fitted on the last FIT_INTERVAL_IN_DAYS.
+func IsFitDue(weights *M.AccWeights, currentTS int64) bool {
+	if weights.FittedVersion != M.GetAccWeightsVersion(weights) {
+		return true
+	}
+	return currentTS-weights.FittedAt >= FIT_INTERVAL_IN_DAYS*U.SECONDS_IN_A_DAY
+}
+
+// UpdateFittedWeights fits the weights and stores them on the project, weights on the
+// config are kept as it is, for switching back to the manual mode.
+func UpdateFittedWeights(projectId int64, weights *M.AccWeights) error {
+
+	fittedWeights, err := FitWeightsFromDeals(projectId, *weights)
+	if err != nil {
+		return err
+	}
+
+	storedWeights, errStatus := store.GetStore().GetWeightsByProject(projectId)
+	if errStatus != http.StatusFound {
+		return fmt.Errorf("unable to get weights : %d", errStatus)
+	}
+	// weights fitted on the config on the run, are not used if the config is updated in between.
+	if M.GetAccWeightsVersion(storedWeights) != M.GetAccWeightsVersion(weights) {
+		return fmt.Errorf("weights updated while fitting")
+	}
+	storedWeights.FittedWeights = fittedWeights
+	storedWeights.FittedAt = time.Now().Unix()
+	storedWeights.FittedVersion = M.GetAccWeightsVersion(storedWeights)
+	if err := store.GetStore().UpdateAccScoreWeights(projectId, *storedWeights); err != nil {
+		return err
+	}
+
+	weights.FittedWeights = storedWeights.FittedWeights
+	weights.FittedAt = storedWeights.FittedAt
+	weights.FittedVersion = storedWeights.FittedVersion
+	return nil
+}
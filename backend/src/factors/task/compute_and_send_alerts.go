@@ -37,6 +37,9 @@ type Message struct {
 	ComparedTo    string
 	From          int64
 	To            int64
+	// only for anomaly operators.
+	Baseline       model.AlertBaseline
+	DeviationScore float64
 }
 
 type dateRanges struct {
@@ -170,7 +173,14 @@ func ComputeAndSendAlerts(projectID int64, configs map[string]interface{}) (map[
 			log.Errorf("failed to convert value to float64 for alertName: %s", alert.AlertName)
 			continue
 		}
-		notify, err := sendAlert(alertDescription.Operator, actualValue, comparedValue, value)
+		var notify bool
+		var baseline model.AlertBaseline
+		if model.IsAnomalyAlertOperator(alertDescription.Operator) {
+			baseline, err = getAnomalyBaseline(projectID, alertDescription.Operator, value, dateRange, timezoneString, kpiQuery)
+			notify = err == nil && baseline.IsOutsideExpectedRange(actualValue)
+		} else {
+			notify, err = sendAlert(alertDescription.Operator, actualValue, comparedValue, value)
+		}
 		if err != nil {
 			log.Errorf("failed to compare results for project_id: %v,alert_name: %s, error: %v", projectID, alert.AlertName, err)
 			continue
@@ -190,7 +200,9 @@ func ComputeAndSendAlerts(projectID int64, configs map[string]interface{}) (map[
 				ComparedTo:    alertDescription.ComparedTo,
 				From:          dateRange.from,
 				To:            dateRange.to,
+				Baseline:      baseline,
 			}
+			msg.DeviationScore = baseline.GetDeviationScore(actualValue)
 			if alertConfiguration.IsEmailEnabled {
 				sendEmailAlert(projectID, msg, dateRange, timezoneString, alertConfiguration.Emails)
 			}
@@ -421,6 +433,107 @@ func sendAlert(operator string, actualValue float64, comparedValue float64, valu
 	return false, nil
 }
 
+// getAnomalyBaseline computes the expected range of the kpi from the values of the same period in the history.
+func getAnomalyBaseline(projectID int64, operator string, deviations float64, dateRange dateRanges,
+	timezone U.TimeZoneString, kpiQuery model.KPIQuery) (model.AlertBaseline, error) {
+
+	history := make([]float64, 0)
+	for _, baselineRange := range GetAnomalyBaselineDateRanges(dateRange.from, dateRange.to, timezone, model.ANOMALY_BASELINE_PERIODS) {
+		baselineDateRange := dateRanges{from: baselineRange.Start, to: baselineRange.End}
+		statusCode, value, _, err := executeAlertsKPIQuery(projectID, model.ALERT_TYPE_SINGLE_RANGE, baselineDateRange, kpiQuery)
+		if err != nil || statusCode != http.StatusOK {
+			log.WithFields(log.Fields{"project_id": projectID, "from": baselineDateRange.from, "status_code": statusCode}).
+				WithError(err).Warn("Failed to execute query for anomaly baseline period.")
+			continue
+		}
+		history = append(history, value)
+	}
+
+	return model.ComputeAlertBaseline(operator, history, deviations)
+}
+
+/*
+GetAnomalyBaselineDateRanges returns the history periods of the range from and to, for the baseline
+of the anomaly alerts. Periods are shifted back by whole weeks, so that every period has the same
+length and the same mix of the days of the week as the current period.
+*/
+func GetAnomalyBaselineDateRanges(fromUnix, toUnix int64, timezone U.TimeZoneString, numPeriods int) []U.TimestampRange {
+	from := U.ConvertTimeIn(time.Unix(fromUnix, 0), timezone)
+	to := U.ConvertTimeIn(time.Unix(toUnix, 0), timezone)
+	weeksPerPeriod := int(math.Ceil(float64(toUnix-fromUnix+1) / float64(7*U.SECONDS_IN_A_DAY)))
+
+	baselineDateRanges := make([]U.TimestampRange, 0, numPeriods)
+	for period := 1; period <= numPeriods; period++ {
+		days := -7 * weeksPerPeriod * period
+		baselineDateRanges = append(baselineDateRanges, U.TimestampRange{
+			Start: from.AddDate(0, 0, days).Unix(),
+			End:   to.AddDate(0, 0, days).Unix(),
+		})
+	}
+	return baselineDateRanges
+}
+
+// resolveAlertOperator resolves the increased or decreased operators by the direction of the change, and
+// the percentage operators to the increased or decreased operators, with "%" as the percentage symbol.
+func resolveAlertOperator(msg Message) (string, string) {
+	operator := msg.Operator
+	if operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN || operator == model.PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN {
+		isIncreased := msg.ActualValue > msg.ComparedValue
+		if operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
+			operator = model.DECREASED_BY_MORE_THAN
+			if isIncreased {
+				operator = model.INCREASED_BY_MORE_THAN
+			}
+		} else {
+			operator = model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN
+			if isIncreased {
+				operator = model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN
+			}
+		}
+	}
+
+	switch operator {
+	case model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN:
+		return model.INCREASED_BY_MORE_THAN, "%"
+	case model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN:
+		return model.DECREASED_BY_MORE_THAN, "%"
+	}
+	return operator, ""
+}
+
+// getAlertOperatorStatement returns the statement of the resolved operator, e.g "increased by more than 1,000%".
+func getAlertOperatorStatement(operator string, value float64, percentageSymbol string) string {
+	return fmt.Sprintf("%s %s%s", strings.ReplaceAll(operator, "_", " "), AddCommaToNumber(fmt.Sprint(value)), percentageSymbol)
+}
+
+// GetAnomalyStatements returns the operator statement and the expected range statement for the anomaly alerts.
+func GetAnomalyStatements(msg Message) (string, string) {
+	direction := "above"
+	if msg.ActualValue < msg.Baseline.Expected {
+		direction = "below"
+	}
+	operatorStatement := fmt.Sprintf("is %s the expected range", direction)
+	rangeStatement := fmt.Sprintf(" (expected %s to %s, deviation score %.1f) ",
+		getAlertValueStatement(msg.Baseline.LowerBound, msg.CategoryType),
+		getAlertValueStatement(msg.Baseline.UpperBound, msg.CategoryType), msg.DeviationScore)
+	return operatorStatement, rangeStatement
+}
+
+func getAlertValueStatement(value float64, categoryType string) string {
+	if categoryType == model.MetricsDateType {
+		// durations less than a second are empty on conversion.
+		if value < 1 {
+			return "0 s"
+		}
+		return strings.TrimSpace(convertTimeFromSeconds(value))
+	}
+	valueStatement := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+	if strings.HasPrefix(valueStatement, "-") {
+		return "-" + AddCommaToNumber(strings.TrimPrefix(valueStatement, "-"))
+	}
+	return AddCommaToNumber(valueStatement)
+}
+
 func sendEmailAlert(projectID int64, msg Message, dateRange dateRanges, timezone U.TimeZoneString, emails []string) {
 	var success, fail int
 	sub := "Factors Alert"
@@ -435,30 +548,8 @@ func sendEmailAlert(projectID int64, msg Message, dateRange dateRanges, timezone
 	from := fromTime.Format("02 Jan 2006")
 	to := toTime.Format("02 Jan 2006")
 
-	if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN || msg.Operator == model.PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN {
-		if msg.ActualValue > msg.ComparedValue {
-			if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
-				msg.Operator = model.INCREASED_BY_MORE_THAN
-			} else {
-				msg.Operator = model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN
-			}
-		} else {
-			if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
-				msg.Operator = model.DECREASED_BY_MORE_THAN
-			} else {
-				msg.Operator = model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN
-			}
-		}
-	}
-	percentageSymbol := ""
-	if msg.Operator == model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN || msg.Operator == model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN {
-		percentageSymbol = "%"
-		if msg.Operator == model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN {
-			msg.Operator = model.INCREASED_BY_MORE_THAN
-		} else {
-			msg.Operator = model.DECREASED_BY_MORE_THAN
-		}
-	}
+	var percentageSymbol string
+	msg.Operator, percentageSymbol = resolveAlertOperator(msg)
 	var actualValue string
 	if msg.CategoryType == model.MetricsDateType {
 		actualValue = convertTimeFromSeconds(msg.ActualValue)
@@ -487,9 +578,13 @@ func sendEmailAlert(projectID int64, msg Message, dateRange dateRanges, timezone
 		}
 		comparedToStatement = "compared to " + previousPeriod
 	}
+	operatorStatement := getAlertOperatorStatement(msg.Operator, msg.Value, percentageSymbol)
+	if model.IsAnomalyAlertOperator(msg.Operator) {
+		operatorStatement, comparedValue = GetAnomalyStatements(msg)
+	}
 	// For the last week (10 may 2022 - 16 may 2022) compared to previous period
 	// Sessions is increased by more than 100 :  400(250)
-	statement = fmt.Sprintf(`For the %s (%s to %s) %s<br> <b> %s %s %s%s : %s%s . </b>`, strings.ReplaceAll(msg.DateRange, "_", " "), from, to, comparedToStatement, strings.ReplaceAll(msg.AlertName, "_", " "), strings.ReplaceAll(msg.Operator, "_", " "), AddCommaToNumber(fmt.Sprint(msg.Value)), percentageSymbol, actualValue, comparedValue)
+	statement = fmt.Sprintf(`For the %s (%s to %s) %s<br> <b> %s %s : %s%s . </b>`, strings.ReplaceAll(msg.DateRange, "_", " "), from, to, comparedToStatement, strings.ReplaceAll(msg.AlertName, "_", " "), operatorStatement, actualValue, comparedValue)
 	html := U.CreateAlertTemplate(statement)
 	dryRunFlag := C.GetConfig().EnableDryRunAlerts
 	if dryRunFlag {
@@ -554,31 +649,15 @@ func getSlackMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 	from := fromTime.Format("02 Jan 2006")
 	to := toTime.Format("02 Jan 2006")
 
-	if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN || msg.Operator == model.PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN {
-		if msg.ActualValue > msg.ComparedValue {
-			if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
-				msg.Operator = model.INCREASED_BY_MORE_THAN
-			} else {
-				msg.Operator = model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN
-			}
-		} else {
-			if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
-				msg.Operator = model.DECREASED_BY_MORE_THAN
-			} else {
-				msg.Operator = model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN
-			}
-		}
-	}
-	percentageSymbol := ""
-	if msg.Operator == model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN || msg.Operator == model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN {
-		percentageSymbol = "%"
-		if msg.Operator == model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN {
-			msg.Operator = model.INCREASED_BY_MORE_THAN
-		} else {
-			msg.Operator = model.DECREASED_BY_MORE_THAN
+	var percentageSymbol string
+	msg.Operator, percentageSymbol = resolveAlertOperator(msg)
+	emoji := getEmojiForSlackByOperator(msg.Operator)
+	if model.IsAnomalyAlertOperator(msg.Operator) {
+		emoji = getEmojiForSlackByOperator(model.IS_GREATER_THAN)
+		if msg.ActualValue < msg.Baseline.Expected {
+			emoji = getEmojiForSlackByOperator(model.IS_LESS_THAN)
 		}
 	}
-	emoji := getEmojiForSlackByOperator(msg.Operator)
 	var actualValue string
 	if msg.CategoryType == model.MetricsDateType {
 		actualValue = convertTimeFromSeconds(msg.ActualValue)
@@ -599,6 +678,10 @@ func getSlackMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 		}
 		ComparedValue = " (" + ComparedValue + ") "
 	}
+	operatorStatement := getAlertOperatorStatement(msg.Operator, msg.Value, percentageSymbol)
+	if model.IsAnomalyAlertOperator(msg.Operator) {
+		operatorStatement, ComparedValue = GetAnomalyStatements(msg)
+	}
 
 	// added next line to support double quotes(") and backslash(\) in slack templates
 	// MUST NOT be done for slackBlocks variable
@@ -617,7 +700,7 @@ func getSlackMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 						"type": "header",
 						"text": {
 							"type": "plain_text",
-							"text": "%s %s %s%s "
+							"text": "%s %s "
 						}
 					},
 					{
@@ -650,7 +733,7 @@ func getSlackMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 						"type": "divider"
 					}
 				]
-				`, strings.ReplaceAll(msg.DateRange, "_", " "), from, to, comparedToStatement, strings.ReplaceAll(alertName, "_", " "), strings.ReplaceAll(msg.Operator, "_", " "), AddCommaToNumber(fmt.Sprint(msg.Value)), percentageSymbol, actualValue, ComparedValue, emoji)
+				`, strings.ReplaceAll(msg.DateRange, "_", " "), from, to, comparedToStatement, strings.ReplaceAll(alertName, "_", " "), operatorStatement, actualValue, ComparedValue, emoji)
 
 	return slackMsg
 }
@@ -696,30 +779,8 @@ func getTeamsMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 	from := fromTime.Format("02 Jan 2006")
 	to := toTime.Format("02 Jan 2006")
 
-	if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN || msg.Operator == model.PERCENTAGE_HAS_INCREASED_OR_DECREASED_BY_MORE_THAN {
-		if msg.ActualValue > msg.ComparedValue {
-			if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
-				msg.Operator = model.INCREASED_BY_MORE_THAN
-			} else {
-				msg.Operator = model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN
-			}
-		} else {
-			if msg.Operator == model.INCREASED_OR_DECREASED_BY_MORE_THAN {
-				msg.Operator = model.DECREASED_BY_MORE_THAN
-			} else {
-				msg.Operator = model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN
-			}
-		}
-	}
-	percentageSymbol := ""
-	if msg.Operator == model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN || msg.Operator == model.PERCENTAGE_HAS_DECREASED_BY_MORE_THAN {
-		percentageSymbol = "%"
-		if msg.Operator == model.PERCENTAGE_HAS_INCREASED_BY_MORE_THAN {
-			msg.Operator = model.INCREASED_BY_MORE_THAN
-		} else {
-			msg.Operator = model.DECREASED_BY_MORE_THAN
-		}
-	}
+	var percentageSymbol string
+	msg.Operator, percentageSymbol = resolveAlertOperator(msg)
 	var actualValue string
 	if msg.CategoryType == model.MetricsDateType {
 		actualValue = convertTimeFromSeconds(msg.ActualValue)
@@ -740,6 +801,10 @@ func getTeamsMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 		}
 		ComparedValue = " (" + ComparedValue + ") "
 	}
+	operatorStatement := getAlertOperatorStatement(msg.Operator, msg.Value, percentageSymbol)
+	if model.IsAnomalyAlertOperator(msg.Operator) {
+		operatorStatement, ComparedValue = GetAnomalyStatements(msg)
+	}
 
 	// added next line to support double quotes(") and backslash(\) in slack templates
 	// MUST NOT be done for slackBlocks variable
@@ -753,7 +818,7 @@ func getTeamsMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 			"summary": "KPI Alert for last week",
 			"sections": [{
 				"activityText": "For the %s (%s - %s) %s",
-				"activityTitle": "%s %s %s%s ",
+				"activityTitle": "%s %s ",
 				"activitySubtitle": "%s %s",
 				"markdown": true
 			}],
@@ -766,7 +831,7 @@ func getTeamsMessage(msg Message, dateRange dateRanges, timezone U.TimeZoneStrin
 				}]
 			}]
 		}
-	}`, strings.ReplaceAll(msg.DateRange, "_", " "), from, to, comparedToStatement, strings.ReplaceAll(alertName, "_", " "), strings.ReplaceAll(msg.Operator, "_", " "), AddCommaToNumber(fmt.Sprint(msg.Value)), percentageSymbol, actualValue, ComparedValue)
+	}`, strings.ReplaceAll(msg.DateRange, "_", " "), from, to, comparedToStatement, strings.ReplaceAll(alertName, "_", " "), operatorStatement, actualValue, ComparedValue)
 
 	return teamsMsg
 }
@@ -1112,7 +1177,13 @@ func handleShareQueryTypeKPI(alert model.Alert, query *model.Queries, configs ma
 func getUrlsForSavedQuerySharing(alert model.Alert, queryClass, reportTitle, dateRange string, containsBreakdown bool, noOfBreakdowns int, result []model.QueryResult) (string, string, bool, error) {
 	tableConfig := buildTableConfigForSavedQuerySharing(alert, queryClass, reportTitle, dateRange, containsBreakdown, result)
 
-	tableUrl, err := qc.GetTableURLfromTableConfig(tableConfig)
+	var tableUrl string
+	var err error
+	if C.IsSelfHostedChartRenderingEnabled() {
+		tableUrl, err = qc.GetTableImageURL(alert.ProjectID, tableConfig, qc.IMAGE_FORMAT_PNG)
+	} else {
+		tableUrl, err = qc.GetTableURLfromTableConfig(tableConfig)
+	}
 	if err != nil {
 		log.WithError(err).Error("Failed to get table url from table config")
 		return "", "", false, err
@@ -1123,7 +1194,11 @@ func getUrlsForSavedQuerySharing(alert model.Alert, queryClass, reportTitle, dat
 		_, displayNames := store.GetStore().GetDisplayNamesForAllEvents(alert.ProjectID)
 		displayNameEvents := GetDisplayEventNamesHandler(displayNames)
 		chartConfig := buildChartConfigForSavedQuerySharing(queryClass, result, containsBreakdown, noOfBreakdowns, displayNameEvents)
-		chartUrl, err = qc.GetChartImageUrlForConfig(chartConfig)
+		if C.IsSelfHostedChartRenderingEnabled() {
+			chartUrl, err = qc.GetChartImageURL(alert.ProjectID, chartConfig, qc.IMAGE_FORMAT_PNG)
+		} else {
+			chartUrl, err = qc.GetChartImageUrlForConfig(chartConfig)
+		}
 		if err != nil {
 			log.WithError(err).Error("Failed to get chart url from chart config")
 			return "", "", false, err
@@ -0,0 +1,72 @@
+package crm_custom_enrich
+
+import (
+	enrichment "factors/crm_enrichment"
+	"factors/model/store"
+	U "factors/util"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type EnrichStatus struct {
+	Source         string                    `json:"source"`
+	PropertyEnrich []enrichment.EnrichStatus `json:"enrich_status"`
+	Enrich         []enrichment.EnrichStatus `json:"enrich"`
+}
+
+// RunCRMCustomEnrich enriches the objects of all the custom crm sources of the project.
+func RunCRMCustomEnrich(projectID int64, config map[string]interface{}) (map[string]interface{}, bool) {
+	numDocRoutines := config["document_routines"].(int)
+	minSyncTimestamp := config["min_sync_timestamp"].(int64)
+	recordProcessLimit := config["record_process_limit"].(int)
+
+	logCtx := log.WithFields(log.Fields{"project_id": projectID})
+
+	customSources, status := store.GetStore().GetCRMCustomSources(projectID)
+	if status != http.StatusFound {
+		if status != http.StatusNotFound {
+			logCtx.Error("Failed to get crm custom sources.")
+			return nil, false
+		}
+		return nil, true
+	}
+
+	commonEnrichStatus := U.CRM_SYNC_STATUS_SUCCESS
+	sourcesEnrichStatus := make([]EnrichStatus, 0, len(customSources))
+	for i := range customSources {
+		sourceConfig, err := enrichment.NewCustomCRMEnrichmentConfig(&customSources[i], recordProcessLimit)
+		if err != nil {
+			logCtx.WithField("source", customSources[i].Name).WithError(err).
+				Error("Failed to create new crm enrichment config for custom source.")
+			commonEnrichStatus = U.CRM_SYNC_STATUS_FAILURES
+			continue
+		}
+
+		propertyEnrichStatus := enrichment.SyncProperties(projectID, sourceConfig)
+		for j := range propertyEnrichStatus {
+			if propertyEnrichStatus[j].Status == U.CRM_SYNC_STATUS_FAILURES {
+				commonEnrichStatus = U.CRM_SYNC_STATUS_FAILURES
+			}
+		}
+
+		enrichStatus := enrichment.Enrich(projectID, sourceConfig, numDocRoutines, minSyncTimestamp)
+		for j := range enrichStatus {
+			if enrichStatus[j].Status == U.CRM_SYNC_STATUS_FAILURES {
+				commonEnrichStatus = U.CRM_SYNC_STATUS_FAILURES
+			}
+		}
+
+		sourcesEnrichStatus = append(sourcesEnrichStatus, EnrichStatus{
+			Source:         customSources[i].Name,
+			PropertyEnrich: propertyEnrichStatus,
+			Enrich:         enrichStatus,
+		})
+	}
+
+	projectEnrichStatus := map[string]interface{}{
+		commonEnrichStatus: sourcesEnrichStatus,
+	}
+
+	return projectEnrichStatus, true
+}
@@ -0,0 +1,437 @@
+package events_import
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"factors/filestore"
+	"factors/model/model"
+	"factors/model/store"
+	SDK "factors/sdk"
+	U "factors/util"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+
+	TimestampFormatUnix   = "unix"
+	TimestampFormatUnixMs = "unix_ms"
+
+	maxRowSizeInBytes = 1024 * 1024
+	logProgressEvery  = 10000
+)
+
+// MappingConfig maps the columns of csv, or the keys of ndjson, to the fields of the track payload.
+type MappingConfig struct {
+	Format string `json:"format"`
+	// column with the event name. default_event_name is used when the column is not given or empty.
+	EventName        string `json:"event_name"`
+	DefaultEventName string `json:"default_event_name"`
+	Timestamp        string `json:"timestamp"`
+	// unix, unix_ms or a go time layout. Defaults to RFC3339.
+	TimestampFormat string `json:"timestamp_format"`
+	// identity of the user on the source. Rows with the same anonymous_id are tracked on the same user,
+	// across the imports, and rows with customer_user_id are identified with it.
+	CustomerUserID string `json:"customer_user_id"`
+	AnonymousID    string `json:"anonymous_id"`
+	// rows with the c_event_id of an event on the project, imported earlier, are skipped as duplicates.
+	CustomerEventID string `json:"c_event_id"`
+	// property name to column.
+	EventProperties map[string]string `json:"event_properties"`
+	UserProperties  map[string]string `json:"user_properties"`
+}
+
+func (config *MappingConfig) Validate() error {
+	if config.Format != FormatCSV && config.Format != FormatNDJSON {
+		return errors.New("format should be csv or ndjson")
+	}
+	if config.EventName == "" && config.DefaultEventName == "" {
+		return errors.New("event_name or default_event_name is required")
+	}
+	if config.Timestamp == "" {
+		return errors.New("timestamp is required")
+	}
+	if config.CustomerUserID == "" && config.AnonymousID == "" {
+		return errors.New("customer_user_id or anonymous_id is required")
+	}
+	return nil
+}
+
+// ImportRow is a row of the file, with the identity of its user.
+type ImportRow struct {
+	Number         int
+	Payload        *SDK.TrackPayload
+	CustomerUserID string
+	AnonymousID    string
+}
+
+type ImportStatus struct {
+	Total      int    `json:"total"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+	Rejected   int    `json:"rejected"`
+	ErrorsFile string `json:"errors_file,omitempty"`
+}
+
+type rejectedRow struct {
+	Row   int         `json:"row"`
+	Error string      `json:"error"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+func getColumnValueAsString(row map[string]interface{}, column string) string {
+	if column == "" {
+		return ""
+	}
+	return strings.TrimSpace(U.GetPropertyValueAsString(row[column]))
+}
+
+// ParseTimestamp parses the value of the timestamp column as unix timestamp in seconds.
+func ParseTimestamp(value interface{}, format string) (int64, error) {
+	valueString := strings.TrimSpace(U.GetPropertyValueAsString(value))
+	if valueString == "" {
+		return 0, errors.New("timestamp is empty")
+	}
+
+	switch format {
+	case TimestampFormatUnix, TimestampFormatUnixMs:
+		timestamp, err := strconv.ParseFloat(valueString, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s timestamp", format)
+		}
+		if format == TimestampFormatUnixMs {
+			timestamp = timestamp / 1000
+		}
+		return int64(timestamp), nil
+	default:
+		layout := format
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		parsedTime, err := time.Parse(layout, valueString)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp for the layout %s", layout)
+		}
+		return parsedTime.Unix(), nil
+	}
+}
+
+// GetImportRowFromFileRow maps the row of the file to track payload using the config.
+func GetImportRowFromFileRow(number int, row map[string]interface{}, config *MappingConfig) (*ImportRow, error) {
+	eventName := getColumnValueAsString(row, config.EventName)
+	if eventName == "" {
+		eventName = config.DefaultEventName
+	}
+	if eventName == "" {
+		return nil, errors.New("event name is empty")
+	}
+
+	timestamp, err := ParseTimestamp(row[config.Timestamp], config.TimestampFormat)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp <= 0 || timestamp > U.TimeNowUnix() {
+		return nil, errors.New("timestamp is not in the past")
+	}
+
+	importRow := &ImportRow{
+		Number:         number,
+		CustomerUserID: getColumnValueAsString(row, config.CustomerUserID),
+		AnonymousID:    getColumnValueAsString(row, config.AnonymousID),
+	}
+	if importRow.CustomerUserID == "" && importRow.AnonymousID == "" {
+		return nil, errors.New("customer_user_id and anonymous_id are empty")
+	}
+
+	eventProperties := make(U.PropertiesMap)
+	for property, column := range config.EventProperties {
+		if value, exists := row[column]; exists && value != nil && value != "" {
+			eventProperties[property] = value
+		}
+	}
+	userProperties := make(U.PropertiesMap)
+	for property, column := range config.UserProperties {
+		if value, exists := row[column]; exists && value != nil && value != "" {
+			userProperties[property] = value
+		}
+	}
+
+	importRow.Payload = &SDK.TrackPayload{
+		Name:            eventName,
+		Timestamp:       timestamp,
+		EventProperties: eventProperties,
+		UserProperties:  userProperties,
+		RequestSource:   model.UserSourceWeb,
+		// user properties of the row are used on the event, instead of the current properties of the user,
+		// on projects with past events enrichment enabled.
+		IsPast: true,
+	}
+	if customerEventID := getColumnValueAsString(row, config.CustomerEventID); customerEventID != "" {
+		importRow.Payload.CustomerEventId = &customerEventID
+	}
+	return importRow, nil
+}
+
+// ReadFileRows reads the rows of csv (with header) or ndjson one by one. Rows which can't be
+// read are passed with the error, to be rejected, while the rest of the rows are processed.
+func ReadFileRows(reader io.Reader, format string, process func(number int, row map[string]interface{}, err error)) error {
+	if format == FormatCSV {
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		header, err := csvReader.Read()
+		if err != nil {
+			return err
+		}
+
+		for number := 1; ; number++ {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				if _, isParseError := err.(*csv.ParseError); !isParseError {
+					return err
+				}
+				process(number, nil, err)
+				continue
+			}
+			if len(record) != len(header) {
+				process(number, nil, errors.New("number of columns does not match the header"))
+				continue
+			}
+
+			row := make(map[string]interface{}, len(header))
+			for i := range header {
+				row[header[i]] = record[i]
+			}
+			process(number, row, nil)
+		}
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRowSizeInBytes)
+	number := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		number++
+
+		var row map[string]interface{}
+		if err := json.Unmarshal(line, &row); err != nil {
+			process(number, nil, err)
+			continue
+		}
+		process(number, row, nil)
+	}
+	return scanner.Err()
+}
+
+// errEventExists is returned for the rows with the c_event_id of an event on the project.
+var errEventExists = errors.New("event with the c_event_id exists")
+
+// importer tracks the rows of the file and holds the users created or identified on the import.
+type importer struct {
+	projectID                int64
+	userIDByCustomerUserID   map[string]string
+	userIDByAnonymousID      map[string]string
+	rowNumberByCustomerEvent map[string]int
+}
+
+// getAnonymousUserID returns the user of the anonymous_id, created with the anonymous_id on the
+// first import it is seen, for the rows of the anonymous_id imported later to be on the same user.
+func (imp *importer) getAnonymousUserID(row *ImportRow) (string, error) {
+	if row.AnonymousID == "" {
+		return "", nil
+	}
+	if userID, exists := imp.userIDByAnonymousID[row.AnonymousID]; exists {
+		return userID, nil
+	}
+
+	userID, status := store.GetStore().CreateOrGetImportUser(imp.projectID, row.AnonymousID, row.Payload.Timestamp)
+	if status != http.StatusFound && status != http.StatusCreated {
+		return "", errors.New("failed to create or get the user of anonymous_id")
+	}
+	imp.userIDByAnonymousID[row.AnonymousID] = userID
+	return userID, nil
+}
+
+func (imp *importer) getUserID(row *ImportRow) (string, error) {
+	anonymousUserID, err := imp.getAnonymousUserID(row)
+	if err != nil {
+		return "", err
+	}
+	if row.CustomerUserID == "" {
+		return anonymousUserID, nil
+	}
+
+	if userID, exists := imp.userIDByCustomerUserID[row.CustomerUserID]; exists {
+		return userID, nil
+	}
+
+	// identify the user of the anonymous_id, if any, else the latest user of customer_user_id is used.
+	status, response := SDK.Identify(imp.projectID, &SDK.IdentifyPayload{
+		UserId:         anonymousUserID,
+		CustomerUserId: row.CustomerUserID,
+		Timestamp:      row.Payload.Timestamp,
+		JoinTimestamp:  row.Payload.Timestamp,
+		RequestSource:  model.UserSourceWeb,
+	}, false)
+	if status != http.StatusOK {
+		return "", fmt.Errorf("failed to identify user. %s", response.Error)
+	}
+
+	userID := response.UserId
+	if userID == "" {
+		userID = anonymousUserID
+	}
+	imp.userIDByCustomerUserID[row.CustomerUserID] = userID
+	if row.AnonymousID != "" {
+		imp.userIDByAnonymousID[row.AnonymousID] = userID
+	}
+	return userID, nil
+}
+
+func (imp *importer) importRow(row *ImportRow) error {
+	if row.Payload.CustomerEventId != nil {
+		if firstRow, exists := imp.rowNumberByCustomerEvent[*row.Payload.CustomerEventId]; exists {
+			return fmt.Errorf("duplicate c_event_id of row %d", firstRow)
+		}
+		imp.rowNumberByCustomerEvent[*row.Payload.CustomerEventId] = row.Number
+
+		_, status := store.GetStore().GetEventIDByCustomerEventID(imp.projectID, *row.Payload.CustomerEventId)
+		if status == http.StatusFound {
+			return errEventExists
+		}
+		if status != http.StatusNotFound {
+			return errors.New("failed to check the event of c_event_id")
+		}
+	}
+
+	userID, err := imp.getUserID(row)
+	if err != nil {
+		return err
+	}
+	row.Payload.UserId = userID
+
+	// sessions are not created on track, add_session has to be run for the imported range.
+	status, response := SDK.Track(imp.projectID, row.Payload, true, SDK.SourceEventsImport, "")
+	if status != http.StatusOK && status != http.StatusFound && status != http.StatusNotModified {
+		return fmt.Errorf("failed to track event. %s", response.Error)
+	}
+	return nil
+}
+
+// GetErrorsFileName returns the name of the file, the rejected rows of the file are reported to.
+func GetErrorsFileName(fileName string) string {
+	return fileName + ".errors.ndjson"
+}
+
+// ImportEvents imports the events on the file of the file manager location to the project. Rows
+// rejected are written to the errors file on the same location, with the row number and the reason.
+func ImportEvents(projectID int64, fileManager filestore.FileManager, dir, fileName string,
+	config *MappingConfig, dryRun bool) (*ImportStatus, error) {
+
+	logCtx := log.WithFields(log.Fields{"project_id": projectID, "dir": dir, "file_name": fileName})
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	file, err := fileManager.Get(dir, fileName)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get the events import file.")
+		return nil, err
+	}
+	defer file.Close()
+
+	imp := &importer{
+		projectID:                projectID,
+		userIDByCustomerUserID:   make(map[string]string),
+		userIDByAnonymousID:      make(map[string]string),
+		rowNumberByCustomerEvent: make(map[string]int),
+	}
+
+	status := &ImportStatus{}
+	// rejected rows are written to the errors file as they are read, the writer is created on the first one.
+	var errorsFileWriter io.WriteCloser
+	var errorsFileErr error
+	reject := func(number int, data interface{}, err error) {
+		status.Rejected++
+		if errorsFileErr != nil {
+			return
+		}
+		if errorsFileWriter == nil {
+			status.ErrorsFile = GetErrorsFileName(fileName)
+			errorsFileWriter, errorsFileErr = fileManager.GetWriter(dir, status.ErrorsFile)
+			if errorsFileErr != nil {
+				logCtx.WithError(errorsFileErr).Error("Failed to create the errors file of events import.")
+				return
+			}
+		}
+		errorsFileErr = json.NewEncoder(errorsFileWriter).Encode(rejectedRow{Row: number, Error: err.Error(), Data: data})
+		if errorsFileErr != nil {
+			logCtx.WithError(errorsFileErr).Error("Failed to write to the errors file of events import.")
+		}
+	}
+
+	err = ReadFileRows(file, config.Format, func(number int, fileRow map[string]interface{}, err error) {
+		status.Total++
+		if status.Total%logProgressEvery == 0 {
+			logCtx.WithField("status", status).Info("Events import in progress.")
+		}
+
+		if err != nil {
+			reject(number, nil, err)
+			return
+		}
+
+		row, err := GetImportRowFromFileRow(number, fileRow, config)
+		if err != nil {
+			reject(number, fileRow, err)
+			return
+		}
+		if dryRun {
+			status.Imported++
+			return
+		}
+
+		if err := imp.importRow(row); err != nil {
+			if err == errEventExists {
+				status.Duplicates++
+				return
+			}
+			reject(number, fileRow, err)
+			return
+		}
+		status.Imported++
+	})
+	if err != nil {
+		// rows read till the failure are imported already.
+		logCtx.WithError(err).WithField("status", status).Error("Failed reading the events import file.")
+	}
+
+	if errorsFileWriter != nil {
+		if closeErr := errorsFileWriter.Close(); closeErr != nil && errorsFileErr == nil {
+			logCtx.WithError(closeErr).Error("Failed to close the errors file of events import.")
+			errorsFileErr = closeErr
+		}
+	}
+	if err == nil {
+		err = errorsFileErr
+	}
+
+	return status, err
+}
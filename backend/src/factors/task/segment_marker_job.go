@@ -14,6 +14,7 @@ import (
 
 	"factors/model/store"
 
+	SDK "factors/sdk"
 	U "factors/util"
 )
 
@@ -295,13 +296,16 @@ func processDomainsInBatches(projectID int64, domainIDList []string, segments []
 
 	for ci := range domainIDChunks {
 		var wg sync.WaitGroup
+		membershipChanges := &segmentMembershipChanges{}
 
 		wg.Add(len(domainIDChunks[ci]))
 		for _, domID := range domainIDChunks[ci] {
 			go fetchAndProcessFromDomainList(projectID, domID, segments, segmentsRulesArr, domainGroupId,
-				eventNameIDsMap, &wg, &statusArr, userCount, domainUpdateCount, fileValuesMap, segmentIds)
+				eventNameIDsMap, &wg, &statusArr, userCount, domainUpdateCount, fileValuesMap, segmentIds, membershipChanges)
 		}
 		wg.Wait()
+
+		recordSegmentMembershipChanges(projectID, membershipChanges.changes, segments, model.UserSourceDomains)
 	}
 
 	endTime := time.Now().Unix()
@@ -320,7 +324,8 @@ func processDomainsInBatches(projectID int64, domainIDList []string, segments []
 
 func fetchAndProcessFromDomainList(projectID int64, domainID string, segments []model.Segment, segmentsRulesArr []model.Query,
 	domainGroupId int, eventNameIDsMap map[string]string, waitGroup *sync.WaitGroup, statusArr *[]bool, userCount *int64,
-	domainUpdateCount *int64, fileValuesMap map[string]map[string]bool, segmentIds []string) {
+	domainUpdateCount *int64, fileValuesMap map[string]map[string]bool, segmentIds []string,
+	membershipChanges *segmentMembershipChanges) {
 
 	logFields := log.Fields{
 		"project_id":      projectID,
@@ -341,7 +346,7 @@ func fetchAndProcessFromDomainList(projectID int64, domainID string, segments []
 	}
 
 	status, err := domainUsersProcessingWithErrcode(projectID, domainID, users, segments,
-		segmentsRulesArr, eventNameIDsMap, domainUpdateCount, fileValuesMap, segmentIds)
+		segmentsRulesArr, eventNameIDsMap, domainUpdateCount, fileValuesMap, segmentIds, membershipChanges)
 
 	if status != http.StatusOK || err != nil {
 		log.WithField("project_id", projectID).Error("Unable to update associated_segments to the domain user.")
@@ -407,7 +412,7 @@ func usersProcessing(projectID int64, user model.User, allSegmentsMap map[string
 	defer model.LogOnSlowExecutionWithParams(time.Now(), &logFields)
 	defer waitGroup.Done()
 
-	status := userProcessingWithErrcode(projectID, user, allSegmentsMap, decodedSegmentRulesMap, eventNameIDsMap, fileValuesMap)
+	status := UserProcessingWithErrcode(projectID, user, allSegmentsMap, decodedSegmentRulesMap, eventNameIDsMap, fileValuesMap)
 
 	if status != http.StatusOK {
 		(*statusMap)[user.ID] = status
@@ -415,9 +420,13 @@ func usersProcessing(projectID int64, user model.User, allSegmentsMap map[string
 
 }
 
-func userProcessingWithErrcode(projectID int64, user model.User, allSegmentsMap map[string][]model.Segment,
+// UserProcessingWithErrcode applies the user level segments on the user, updates its
+// associated_segments and records the segments entered and exited by the user.
+func UserProcessingWithErrcode(projectID int64, user model.User, allSegmentsMap map[string][]model.Segment,
 	decodedSegmentRulesMap map[string][]model.Query, eventNameIDsMap map[string]string, fileValuesMap map[string]map[string]bool) int {
 	userAssociatedSegments := make(map[string]model.AssociatedSegments)
+	userSegments := make([]model.Segment, 0)
+	var timezone U.TimeZoneString
 
 	// decoding user properties col
 	decodedProps, err := U.DecodePostgresJsonb(&user.Properties)
@@ -444,11 +453,19 @@ func userProcessingWithErrcode(projectID int64, user model.User, allSegmentsMap
 					// update associated_segments map on the basis of segment rule applied
 					userAssociatedSegments = updateSegmentMap(matched, user,
 						userAssociatedSegments, segment.Id)
+					userSegments = append(userSegments, segment)
+
+					// setting timezone
+					if timezone == "" {
+						timezone = U.TimeZoneString(segmentQuery.Timezone)
+					}
 				}
 			}
 		}
 	}
 
+	existingAssociatedSegment, existingStatus := store.GetStore().GetAssociatedSegmentForUser(projectID, user.ID)
+
 	// update associated_segments in db
 	status, _ := store.GetStore().UpdateAssociatedSegments(projectID, user.ID,
 		userAssociatedSegments)
@@ -456,12 +473,49 @@ func userProcessingWithErrcode(projectID int64, user model.User, allSegmentsMap
 		log.WithField("project_id", projectID).Error("Unable to update associated_segments to the user.")
 		return status
 	}
+
+	// Without the existing segments of the user, all the segments would be taken as entered.
+	if existingStatus != http.StatusFound && existingStatus != http.StatusNotFound {
+		log.WithFields(log.Fields{"project_id": projectID, "user_id": user.ID, "status": existingStatus}).
+			Error("Failed to get existing associated_segments. Skipped recording segment membership changes.")
+		return http.StatusOK
+	}
+
+	requestSource := model.UserSourceWeb
+	if user.Source != nil {
+		requestSource = *user.Source
+	}
+	changes := getSegmentMembershipChanges(user.ID, userAssociatedSegments, existingAssociatedSegment, U.TimeNowIn(timezone))
+	recordSegmentMembershipChanges(projectID, changes, userSegments, requestSource)
+
 	return http.StatusOK
 }
 
+// getSegmentMembershipChanges returns the segments entered and exited by the user,
+// by comparing the newly computed associated_segments with the existing ones.
+func getSegmentMembershipChanges(userID string, associatedSegments map[string]model.AssociatedSegments,
+	oldAssociatedSegments map[string]interface{}, timeOfActionPerformed time.Time) []model.SegmentMembershipChange {
+	changes := make([]model.SegmentMembershipChange, 0)
+
+	for segID := range associatedSegments {
+		if _, exists := oldAssociatedSegments[segID]; !exists {
+			changes = append(changes, newSegmentMembershipChange(segID, userID, model.SegmentMembershipEntered, timeOfActionPerformed))
+		}
+	}
+
+	for segID := range oldAssociatedSegments {
+		if _, exists := associatedSegments[segID]; !exists {
+			changes = append(changes, newSegmentMembershipChange(segID, userID, model.SegmentMembershipExited, timeOfActionPerformed))
+		}
+	}
+
+	return changes
+}
+
 func domainUsersProcessingWithErrcode(projectID int64, domId string, usersArray []model.User,
 	segments []model.Segment, segmentsRulesArr []model.Query, eventNameIDsMap map[string]string,
-	domainUpdateCount *int64, fileValuesMap map[string]map[string]bool, segmentIds []string) (int, error) {
+	domainUpdateCount *int64, fileValuesMap map[string]map[string]bool, segmentIds []string,
+	membershipChanges *segmentMembershipChanges) (int, error) {
 	associatedSegments := make(map[string]model.AssociatedSegments)
 	decodedPropsArr := make([]map[string]interface{}, 0)
 	for _, user := range usersArray {
@@ -491,21 +545,22 @@ func domainUsersProcessingWithErrcode(projectID int64, domId string, usersArray
 	}
 
 	// check whether associated_segments need to be updated
-	existingAssociatedSegment, status := store.GetStore().GetAssociatedSegmentForUser(projectID, domId)
+	existingAssociatedSegment, existingStatus := store.GetStore().GetAssociatedSegmentForUser(projectID, domId)
 
-	if status != http.StatusFound {
+	if existingStatus != http.StatusFound {
 		if len(associatedSegments) == 0 {
 			return http.StatusOK, nil
 		}
 	}
 
 	var updateAssociatedSegment bool
+	var changes []model.SegmentMembershipChange
 
 	if len(segmentIds) > 0 {
-		updateAssociatedSegment, associatedSegments = compareGivenSegments(projectID, domId, associatedSegments, existingAssociatedSegment,
-			segmentIds, timezone)
+		updateAssociatedSegment, associatedSegments, changes = compareGivenSegments(projectID, domId, associatedSegments,
+			existingAssociatedSegment, segmentIds, timezone)
 	} else {
-		updateAssociatedSegment = IsMapsNotMatching(projectID, domId, associatedSegments, existingAssociatedSegment, timezone)
+		updateAssociatedSegment, changes = IsMapsNotMatching(projectID, domId, associatedSegments, existingAssociatedSegment, timezone)
 	}
 
 	if !updateAssociatedSegment {
@@ -521,12 +576,80 @@ func domainUsersProcessingWithErrcode(projectID int64, domId string, usersArray
 		return status, err
 	}
 
+	// Without the existing segments of the domain, all the segments would be taken as entered.
+	if existingStatus != http.StatusFound && existingStatus != http.StatusNotFound {
+		log.WithFields(log.Fields{"project_id": projectID, "domain_id": domId, "status": existingStatus}).
+			Error("Failed to get existing associated_segments. Skipped recording segment membership changes.")
+		return http.StatusOK, nil
+	}
+	membershipChanges.add(changes)
+
 	return http.StatusOK, nil
 }
 
+// segmentMembershipChanges collects the membership changes of the domains processed
+// concurrently in a batch, to be recorded together once the batch is done.
+type segmentMembershipChanges struct {
+	mutex   sync.Mutex
+	changes []model.SegmentMembershipChange
+}
+
+func (m *segmentMembershipChanges) add(changes []model.SegmentMembershipChange) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.changes = append(m.changes, changes...)
+}
+
+// recordSegmentMembershipChanges adds the changes to the membership history and tracks
+// $segment_entered / $segment_exited events on the users, with the source of the users.
+// Failures are only logged, as associated_segments is already updated.
+func recordSegmentMembershipChanges(projectID int64, changes []model.SegmentMembershipChange,
+	segments []model.Segment, requestSource int) {
+	if len(changes) == 0 {
+		return
+	}
+	logCtx := log.WithFields(log.Fields{"project_id": projectID, "changes": len(changes)})
+
+	if status := store.GetStore().CreateSegmentMembershipChanges(projectID, changes); status != http.StatusCreated {
+		logCtx.WithField("status", status).Error("Failed to record segment membership history.")
+	}
+
+	segmentNames := make(map[string]string)
+	for _, segment := range segments {
+		segmentNames[segment.Id] = segment.Name
+	}
+
+	for _, change := range changes {
+		payload := &SDK.TrackPayload{
+			Name:      model.GetSegmentMembershipEventName(change.Action),
+			UserId:    change.UserID,
+			Timestamp: change.Timestamp,
+			EventProperties: U.PropertiesMap{
+				U.EP_SEGMENT_ID:   change.SegmentID,
+				U.EP_SEGMENT_NAME: segmentNames[change.SegmentID],
+			},
+			RequestSource: requestSource,
+		}
+		if status, response := SDK.Track(projectID, payload, true, "", ""); status != http.StatusOK {
+			logCtx.WithFields(log.Fields{"status": status, "user_id": change.UserID, "segment_id": change.SegmentID,
+				"error": response.Error}).Error("Failed to track segment membership event.")
+		}
+	}
+}
+
+func newSegmentMembershipChange(segmentID, userID, action string, timeOfActionPerformed time.Time) model.SegmentMembershipChange {
+	return model.SegmentMembershipChange{
+		SegmentID: segmentID,
+		UserID:    userID,
+		Action:    action,
+		Timestamp: timeOfActionPerformed.Unix(),
+	}
+}
+
 func IsMapsNotMatching(projectID int64, domId string, associatedSegments map[string]model.AssociatedSegments,
-	oldAssociatedSegments map[string]interface{}, timezone U.TimeZoneString) bool {
+	oldAssociatedSegments map[string]interface{}, timezone U.TimeZoneString) (bool, []model.SegmentMembershipChange) {
 	isDifferent := false
+	changes := make([]model.SegmentMembershipChange, 0)
 
 	// length check
 	if len(associatedSegments) != len(oldAssociatedSegments) {
@@ -543,6 +666,7 @@ func IsMapsNotMatching(projectID int64, domId string, associatedSegments map[str
 			// alert for entering segment
 			timeOfActionPerformed := U.TimeNowIn(timezone)
 			store.GetStore().FindAndCacheAlertForCurrentSegment(projectID, segID, domId, model.ACTION_SEGMENT_ENTRY, timeOfActionPerformed)
+			changes = append(changes, newSegmentMembershipChange(segID, domId, model.SegmentMembershipEntered, timeOfActionPerformed))
 		}
 	}
 
@@ -557,14 +681,15 @@ func IsMapsNotMatching(projectID int64, domId string, associatedSegments map[str
 			// alert for exiting segment
 			timeOfActionPerformed := U.TimeNowIn(timezone)
 			store.GetStore().FindAndCacheAlertForCurrentSegment(projectID, segID, domId, model.ACTION_SEGMENT_EXIT, timeOfActionPerformed)
+			changes = append(changes, newSegmentMembershipChange(segID, domId, model.SegmentMembershipExited, timeOfActionPerformed))
 		}
 	}
 
-	return isDifferent
+	return isDifferent, changes
 }
 
 func compareGivenSegments(projectID int64, domId string, associatedSegments map[string]model.AssociatedSegments, oldAssociatedSegments map[string]interface{},
-	segmentIds []string, timezone U.TimeZoneString) (bool, map[string]model.AssociatedSegments) {
+	segmentIds []string, timezone U.TimeZoneString) (bool, map[string]model.AssociatedSegments, []model.SegmentMembershipChange) {
 
 	segmentIdsMap := make(map[string]bool)
 
@@ -576,6 +701,7 @@ func compareGivenSegments(projectID int64, domId string, associatedSegments map[
 	// the map to be updated
 	updatedAssociatedSegments := make(map[string]model.AssociatedSegments)
 	isDifferent := false
+	changes := make([]model.SegmentMembershipChange, 0)
 
 	// only appending the segment_ids that already exists and we are not running marker for
 	for segID := range oldAssociatedSegments {
@@ -613,7 +739,7 @@ func compareGivenSegments(projectID int64, domId string, associatedSegments map[
 		// alert for entering segment
 		timeOfActionPerformed := U.TimeNowIn(timezone)
 		store.GetStore().FindAndCacheAlertForCurrentSegment(projectID, segID, domId, model.ACTION_SEGMENT_ENTRY, timeOfActionPerformed)
-
+		changes = append(changes, newSegmentMembershipChange(segID, domId, model.SegmentMembershipEntered, timeOfActionPerformed))
 	}
 
 	// checking old map, if newly computed segment does not exist [leaving condition]
@@ -637,9 +763,10 @@ func compareGivenSegments(projectID int64, domId string, associatedSegments map[
 		// alert for exiting segment
 		timeOfActionPerformed := U.TimeNowIn(timezone)
 		store.GetStore().FindAndCacheAlertForCurrentSegment(projectID, segID, domId, model.ACTION_SEGMENT_EXIT, timeOfActionPerformed)
+		changes = append(changes, newSegmentMembershipChange(segID, domId, model.SegmentMembershipExited, timeOfActionPerformed))
 	}
 
-	return isDifferent, updatedAssociatedSegments
+	return isDifferent, updatedAssociatedSegments, changes
 }
 
 func isRuleMatched(projectID int64, segment model.Segment, decodedProperties *map[string]interface{},
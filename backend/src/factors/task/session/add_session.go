@@ -84,6 +84,12 @@ func addSessionByProjectId(projectId int64, maxLookbackTimestamp, startTimestamp
 		return status, errCode
 	}
 
+	// Session definition is loaded once for all the users of the project.
+	sessionDefinition, errCode, _ := GetSessionDefinitionByProject(projectId, logCtx)
+	if errCode != http.StatusFound {
+		return status, errCode
+	}
+
 	status.NoOfEvents = noOfEventsDownloaded
 	status.NoOfUsers = len(*userEventsMap)
 
@@ -112,7 +118,7 @@ func addSessionByProjectId(projectId int64, maxLookbackTimestamp, startTimestamp
 			}
 
 			go addSessionUserEventsWorker(projectId, userID, events, sessionEventName.ID,
-				bufferTimeBeforeSessionCreateInSecs, &wg, status)
+				bufferTimeBeforeSessionCreateInSecs, sessionDefinition, &wg, status)
 		}
 		wg.Wait() // Wait till all units of batch is processed.
 
@@ -212,6 +218,25 @@ func GetNextSessionAndPullEvent(projectId int64, maxLookbackTimestamp int64, sta
 	return false, sessionEventName, errCode, userEventsMap, noOfEventsDownloaded, ""
 }
 
+// GetSessionDefinitionByProject - Returns the session definition on the project settings.
+// Invalid definition falls back to the default. Failure to get the settings fails the project,
+// instead of adding session to the users with a different definition.
+func GetSessionDefinitionByProject(projectId int64, logCtx *log.Entry) (model.SessionDefinition, int, string) {
+	projectSettings, errCode := store.GetStore().GetProjectSetting(projectId)
+	if errCode != http.StatusFound {
+		msg := fmt.Sprintf("failed to get project settings on add session, errCode: %v", errCode)
+		logCtx.Error(msg)
+		return model.SessionDefinition{}, http.StatusInternalServerError, msg
+	}
+
+	sessionDefinition, err := model.GetSessionDefinition(projectSettings)
+	if err != nil {
+		logCtx.WithError(err).Error("Invalid session definition on project settings. Using default.")
+	}
+
+	return sessionDefinition, http.StatusFound, ""
+}
+
 func GetAddSessionAllowedProjects(allowedProjectsList, disallowedProjectsList string) ([]int64, int) {
 	isAllProjects, projectIDsMap, skipProjectIdMap := C.GetProjectsFromListWithAllProjectSupport(
 		allowedProjectsList, disallowedProjectsList)
@@ -302,14 +327,14 @@ func addSessionProjectWorker(projectId int64, maxLookbackTimestamp, startTimesta
 
 func addSessionUserEventsWorker(projectID int64, userID string, events []model.Event,
 	sessionEventNameID string, bufferTimeBeforeSessionCreateInSecs int64,
-	wg *sync.WaitGroup, status *Status) {
+	sessionDefinition model.SessionDefinition, wg *sync.WaitGroup, status *Status) {
 	logCtx := log.WithField("project_id", projectID).WithField("user_id", userID)
 
 	defer wg.Done()
 
 	noOfProcessedEvents, noOfCreated, isContinuedFirst, noOfUserPropUpdates,
 		errCode := store.GetStore().AddSessionForUser(projectID, userID, events,
-		bufferTimeBeforeSessionCreateInSecs, sessionEventNameID)
+		bufferTimeBeforeSessionCreateInSecs, sessionEventNameID, sessionDefinition)
 
 	var seenFailure bool
 	if errCode == http.StatusInternalServerError || errCode == http.StatusBadRequest {
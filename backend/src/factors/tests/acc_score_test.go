@@ -951,3 +951,147 @@ func TestUpdateEventChannel(t *testing.T) {
 	assert.Nil(t, err)
 
 }
+
+func TestAccScoreHalfLifeDecay(t *testing.T) {
+	end := time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC).Unix()
+	daysBefore := func(days int) int64 {
+		return time.Unix(end, 0).AddDate(0, 0, -days).Unix()
+	}
+
+	weights := M.AccWeights{SaleWindow: 90, Decay: M.ACC_SCORE_DECAY_HALF_LIFE, HalfLife: 30}
+	assert.Equal(t, float64(1), M.ComputeDecayValueGivenStartEndTSForWeights(end, end, &weights))
+	assert.InDelta(t, 0.5, M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(30), &weights), 0.0001)
+	assert.InDelta(t, 0.25, M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(60), &weights), 0.0001)
+	// event yesterday counts more than an event 60 days ago.
+	assert.Greater(t, M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(1), &weights),
+		M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(60), &weights))
+	// beyond the sale window.
+	assert.Equal(t, float64(0), M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(91), &weights))
+
+	// default half life.
+	weights.HalfLife = 0
+	assert.InDelta(t, 0.5, M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(int(M.DEFAULT_HALF_LIFE_DAYS)), &weights), 0.0001)
+
+	// linear decay, by default.
+	weights.Decay = ""
+	assert.Equal(t, M.ComputeDecayValueGivenStartEndTS(end, daysBefore(60), 90),
+		M.ComputeDecayValueGivenStartEndTSForWeights(end, daysBefore(60), &weights))
+
+	assert.Nil(t, M.ValidateScoringMode(&M.AccWeights{SaleWindow: 90, Decay: M.ACC_SCORE_DECAY_HALF_LIFE, HalfLife: 30,
+		WeightMode: M.ACC_SCORE_WEIGHT_MODE_FITTED}))
+	assert.NotNil(t, M.ValidateScoringMode(&M.AccWeights{SaleWindow: 90, Decay: "exponential"}))
+	assert.NotNil(t, M.ValidateScoringMode(&M.AccWeights{SaleWindow: 90, HalfLife: 120}))
+	assert.NotNil(t, M.ValidateScoringMode(&M.AccWeights{SaleWindow: 90, WeightMode: "auto"}))
+}
+
+func TestAccScoreFitWeightsFromDeals(t *testing.T) {
+	closedAt := time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC).Unix()
+	day := U.GetDateOnlyFromTimestamp(closedAt)
+	weights := M.AccWeights{SaleWindow: 30, WeightConfig: []M.AccEventWeight{
+		{WeightId: "demo", Weight_value: 10}, {WeightId: "pageview", Weight_value: 10}}}
+
+	// won accounts request demos, all accounts view pages.
+	countsOfUser := make(map[string]map[string]M.LatestScore)
+	outcomes := make([]M.AccDealOutcome, 0)
+	for idx := 0; idx < 20; idx++ {
+		won := idx%2 == 0
+		counts := map[string]float64{"pageview": float64(5 + idx%3)}
+		if won {
+			counts["demo"] = float64(1 + idx%3)
+		}
+		accountID := fmt.Sprintf("account_%d", idx)
+		countsOfUser[accountID] = map[string]M.LatestScore{day: {Date: U.GetDateFromString(day), EventsCount: counts}}
+		outcomes = append(outcomes, M.AccDealOutcome{AccountID: accountID, Won: won, ClosedAt: closedAt})
+	}
+	// account without events is skipped.
+	outcomes = append(outcomes, M.AccDealOutcome{AccountID: "account_no_events", Won: true, ClosedAt: closedAt})
+	// deal without close date and deal with events only after the close or before the sale window are skipped.
+	countsOfUser["account_no_close"] = map[string]M.LatestScore{day: {Date: U.GetDateFromString(day),
+		EventsCount: map[string]float64{"demo": 1}}}
+	outcomes = append(outcomes, M.AccDealOutcome{AccountID: "account_no_close", Won: true})
+	afterClose := time.Unix(closedAt, 0).AddDate(0, 0, 1).Unix()
+	beforeWindow := time.Unix(closedAt, 0).AddDate(0, 0, -31).Unix()
+	countsOfUser["account_out_of_window"] = map[string]M.LatestScore{
+		U.GetDateOnlyFromTimestamp(afterClose):   {Date: afterClose, EventsCount: map[string]float64{"demo": 1}},
+		U.GetDateOnlyFromTimestamp(beforeWindow): {Date: beforeWindow, EventsCount: map[string]float64{"demo": 1}},
+	}
+	outcomes = append(outcomes, M.AccDealOutcome{AccountID: "account_out_of_window", Won: true, ClosedAt: closedAt})
+
+	// counts are snapshotted only on the days after the close, the older days are not kept on the account.
+	snapshots := T.BuildDealSnapshots(weights, outcomes, countsOfUser, afterClose)
+	assert.Equal(t, 20, len(snapshots))
+	assert.Equal(t, 0, len(T.BuildDealSnapshots(weights, outcomes, countsOfUser,
+		closedAt+(T.MAX_DAYS_TO_SNAPSHOT_CLOSED_DEAL+1)*U.SECONDS_IN_A_DAY)))
+
+	weightIds := []string{"demo", "pageview"}
+	features, labels := T.BuildDealTrainingSet(weights, weightIds, snapshots)
+	assert.Equal(t, 20, len(features))
+	assert.Equal(t, 20, len(labels))
+
+	coefficients, _ := T.FitLogisticRegression(features, labels, T.FIT_ITERATIONS, T.FIT_LEARNING_RATE, T.FIT_L2_PENALTY)
+	assert.Greater(t, coefficients[0], coefficients[1])
+
+	fittedWeights, err := T.ScaleCoefficientsToWeights(weightIds, coefficients)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(T.MAX_FITTED_WEIGHT), fittedWeights["demo"])
+	assert.Less(t, fittedWeights["pageview"], fittedWeights["demo"])
+	assert.GreaterOrEqual(t, fittedWeights["pageview"], float32(0))
+
+	_, err = T.ScaleCoefficientsToWeights(weightIds, []float64{-1, -2})
+	assert.NotNil(t, err)
+
+	// fitted weights are used for scoring only on the fitted mode, on the version fitted on.
+	weights.FittedWeights = fittedWeights
+	weights.Version = M.GetAccWeightsVersion(&weights)
+	weights.FittedVersion = weights.Version
+	assert.Equal(t, float32(10), M.GetScoringWeightValue(&weights, weights.WeightConfig[0]))
+	weights.WeightMode = M.ACC_SCORE_WEIGHT_MODE_FITTED
+	assert.Equal(t, fittedWeights["demo"], M.GetScoringWeightValue(&weights, weights.WeightConfig[0]))
+
+	// refitted on the interval or on update of the config.
+	weights.FittedAt = closedAt
+	assert.False(t, T.IsFitDue(&weights, closedAt+U.SECONDS_IN_A_DAY))
+	assert.True(t, T.IsFitDue(&weights, closedAt+T.FIT_INTERVAL_IN_DAYS*U.SECONDS_IN_A_DAY))
+	weights.WeightConfig = append(weights.WeightConfig, M.AccEventWeight{WeightId: "webinar", Weight_value: 10})
+	weights.Version = M.GetAccWeightsVersion(&weights)
+	assert.True(t, T.IsFitDue(&weights, closedAt+U.SECONDS_IN_A_DAY))
+	assert.Equal(t, float32(10), M.GetScoringWeightValue(&weights, weights.WeightConfig[0]))
+	// deleted weights don't change the version.
+	weights.WeightConfig[2].Is_deleted = true
+	assert.Equal(t, weights.FittedVersion, M.GetAccWeightsVersion(&weights))
+}
+
+func TestAccScoreContributions(t *testing.T) {
+	weights := M.AccWeights{SaleWindow: 10, WeightConfig: []M.AccEventWeight{
+		{WeightId: "w1", FilterName: "Demo requested", EventName: "$form_submitted", Weight_value: 40},
+		{WeightId: "w2", FilterName: "Pricing page", EventName: "$session", Weight_value: 10},
+		{WeightId: "w3", FilterName: "Paid session", EventName: "$session", Weight_value: 5},
+		{WeightId: "w4", FilterName: "Deleted", EventName: "$session", Weight_value: 100, Is_deleted: true},
+	}}
+	eventsCount := map[string]float64{"w1": 1, "w2": 1, "w3": 1, "w4": 10}
+
+	contributions, score := M.ComputeAccScoreContributions(&weights, eventsCount, 1)
+	assert.InDelta(t, 40+10+5, score, 0.0001)
+	assert.InDelta(t, score, mm.ComputeScoreWithWeightsAndCountsWithDecay(0, &weights, eventsCount, 1), 0.0001)
+	assert.Equal(t, 3, len(contributions))
+	assert.Equal(t, "w1", contributions[0].WeightId)
+	assert.InDelta(t, 72.73, contributions[0].Percentage, 0.01)
+	assert.Equal(t, "w2", contributions[1].WeightId)
+	assert.InDelta(t, 1, contributions[1].Count, 0.0001)
+
+	eventContributions := M.GetAccScoreEventContributions(contributions, score)
+	assert.Equal(t, 2, len(eventContributions))
+	assert.Equal(t, "$form_submitted", eventContributions[0].EventName)
+	assert.Equal(t, "$session", eventContributions[1].EventName)
+	assert.InDelta(t, 15, eventContributions[1].Score, 0.0001)
+
+	// demo request happened today, other counts decayed since yesterday.
+	previousContributions, previousScore := M.ComputeAccScoreContributions(&weights,
+		map[string]float64{"w2": 2, "w3": 2}, 0.6)
+	assert.InDelta(t, 10*2*0.6+5*2*0.6, previousScore, 0.0001)
+	changes := M.GetAccScoreContributionChanges(contributions, previousContributions)
+	assert.Equal(t, 3, len(changes))
+	assert.Equal(t, "w1", changes[0].WeightId)
+	assert.InDelta(t, 40, changes[0].Change, 0.0001)
+	assert.InDelta(t, -2, changes[1].Change, 0.0001)
+}
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	C "factors/config"
+	H "factors/handler"
+	mid "factors/middleware"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyGenerateAndValidate(t *testing.T) {
+	key, keyPrefix, keyHash, err := model.GenerateAPIKey()
+	assert.Nil(t, err)
+	assert.True(t, model.IsAPIKeyToken(key))
+	assert.True(t, strings.HasPrefix(key, keyPrefix))
+	assert.NotEqual(t, key, keyPrefix)
+	assert.Equal(t, model.GetAPIKeyHash(key), keyHash)
+	assert.NotContains(t, keyHash, key)
+
+	anotherKey, _, _, err := model.GenerateAPIKey()
+	assert.Nil(t, err)
+	assert.NotEqual(t, key, anotherKey)
+
+	// private token of the project is not an api key.
+	assert.False(t, model.IsAPIKeyToken(strings.Repeat("a", 32)))
+
+	_, err = model.ValidateAPIKeyScopes(nil)
+	assert.NotNil(t, err)
+	_, err = model.ValidateAPIKeyScopes([]string{model.APIKeyScopeQueryRead, "query:write"})
+	assert.NotNil(t, err)
+	scopes, err := model.ValidateAPIKeyScopes([]string{model.APIKeyScopeSDKWrite, model.APIKeyScopeProfilesRead})
+	assert.Nil(t, err)
+
+	now := time.Now().UTC()
+	apiKey := &model.APIKey{Scopes: scopes}
+	assert.Nil(t, model.ValidateAPIKeyForScope(apiKey, model.APIKeyScopeSDKWrite, now))
+	assert.Nil(t, model.ValidateAPIKeyForScope(apiKey, model.APIKeyScopeProfilesRead, now))
+	assert.NotNil(t, model.ValidateAPIKeyForScope(apiKey, model.APIKeyScopeQueryRead, now))
+
+	// expiry.
+	expiresAt := now.Add(time.Hour)
+	apiKey.ExpiresAt = &expiresAt
+	assert.Nil(t, model.ValidateAPIKeyForScope(apiKey, model.APIKeyScopeSDKWrite, now))
+	assert.NotNil(t, model.ValidateAPIKeyForScope(apiKey, model.APIKeyScopeSDKWrite, now.Add(2*time.Hour)))
+
+	// revocation.
+	apiKey.ExpiresAt = nil
+	apiKey.RevokedAt = &now
+	assert.NotNil(t, model.ValidateAPIKeyForScope(apiKey, model.APIKeyScopeSDKWrite, now))
+	assert.NotNil(t, model.ValidateAPIKeyForScope(apiKey, "", now))
+}
+
+func TestAPIKeyQueryScopes(t *testing.T) {
+	r := gin.Default()
+	H.InitPrivateQueryRoutes(r)
+
+	project, agent, err := SetupProjectWithAgentDAO()
+	assert.Nil(t, err)
+
+	_, queryKey, status, _ := store.GetStore().CreateAPIKey(project.ID, agent.UUID, "query",
+		[]string{model.APIKeyScopeQueryRead}, nil)
+	assert.Equal(t, http.StatusCreated, status)
+	_, profilesKey, status, _ := store.GetStore().CreateAPIKey(project.ID, agent.UUID, "profiles",
+		[]string{model.APIKeyScopeProfilesRead}, nil)
+	assert.Equal(t, http.StatusCreated, status)
+
+	sendQueryReq := func(uri, key string) *httptest.ResponseRecorder {
+		rb := C.NewRequestBuilderWithPrefix(http.MethodPost, uri).
+			WithHeader("Authorization", key).
+			WithPostParams(map[string]interface{}{})
+		req, err := rb.Build()
+		assert.Nil(t, err)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// keys are allowed only on the routes of their scope.
+	w := sendQueryReq("/private/v1/query", profilesKey)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = sendQueryReq("/private/v1/query", queryKey)
+	assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+	w = sendQueryReq("/private/v1/profiles/query", queryKey)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = sendQueryReq("/private/v1/profiles/query", profilesKey)
+	assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+
+	// private token of the project is not accepted.
+	w = sendQueryReq("/private/v1/query", project.PrivateToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = sendQueryReq("/private/v1/profiles/query", project.PrivateToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPIKeyUsage(t *testing.T) {
+	r := gin.Default()
+	H.InitAccountRoutes(r)
+	uriCreate := "/sdk/account/create"
+
+	project, agent, err := SetupProjectWithAgentDAO()
+	assert.Nil(t, err)
+
+	_, status := store.GetStore().CreateOrGetDomainsGroup(project.ID)
+	assert.Equal(t, http.StatusCreated, status)
+
+	apiKey, key, status, _ := store.GetStore().CreateAPIKey(project.ID, agent.UUID, "accounts",
+		[]string{model.APIKeyScopeAccountsWrite}, nil)
+	assert.Equal(t, http.StatusCreated, status)
+	crmKey, crmKeyToken, status, _ := store.GetStore().CreateAPIKey(project.ID, agent.UUID, "crm",
+		[]string{model.APIKeyScopeCRMWrite}, nil)
+	assert.Equal(t, http.StatusCreated, status)
+
+	w := ServePostRequestWithHeaders(r, uriCreate, []byte(`{"domain": "usage.com"}`),
+		map[string]string{"Authorization": key})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = ServePostRequestWithHeaders(r, uriCreate, []byte(`{"domain": "usage.com"}`),
+		map[string]string{"Authorization": key})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// key without the scope is rejected and not recorded.
+	w = ServePostRequestWithHeaders(r, uriCreate, []byte(`{"domain": "usage2.com"}`),
+		map[string]string{"Authorization": crmKeyToken})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// private token requests are not recorded.
+	w = ServePostRequestWithHeaders(r, uriCreate, []byte(`{"domain": "usage3.com"}`),
+		map[string]string{"Authorization": project.PrivateToken})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// usages are counted in memory until flushed.
+	usages, status := store.GetStore().GetAPIKeyUsages(project.ID, apiKey.ID, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, usages, 0)
+
+	w = ServePostRequestWithHeaders(r, uriCreate, []byte(`{"domain": "usage.com"}`),
+		map[string]string{"Authorization": key})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mid.FlushAPIKeyUsages()
+
+	usages, status = store.GetStore().GetAPIKeyUsages(project.ID, apiKey.ID, 0)
+	assert.Equal(t, http.StatusFound, status)
+	countByStatus := make(map[int]int64)
+	for i := range usages {
+		assert.Equal(t, apiKey.ID, usages[i].APIKeyID)
+		assert.Equal(t, http.MethodPost, usages[i].Method)
+		assert.Equal(t, uriCreate, usages[i].Route)
+		assert.Equal(t, int64(0), usages[i].Timestamp%60)
+		countByStatus[usages[i].Status] += usages[i].Count
+	}
+	assert.Equal(t, map[int]int64{http.StatusCreated: 1, http.StatusConflict: 2}, countByStatus)
+
+	// flushed counts are added to the usage of the minute.
+	w = ServePostRequestWithHeaders(r, uriCreate, []byte(`{"domain": "usage.com"}`),
+		map[string]string{"Authorization": key})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mid.FlushAPIKeyUsages()
+	usages, status = store.GetStore().GetAPIKeyUsages(project.ID, apiKey.ID, 0)
+	assert.Equal(t, http.StatusFound, status)
+	countByStatus = make(map[int]int64)
+	for i := range usages {
+		countByStatus[usages[i].Status] += usages[i].Count
+	}
+	assert.Equal(t, int64(3), countByStatus[http.StatusConflict])
+
+	usages, status = store.GetStore().GetAPIKeyUsages(project.ID, apiKey.ID, 1)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, usages, 1)
+
+	usages, status = store.GetStore().GetAPIKeyUsages(project.ID, crmKey.ID, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, usages, 0)
+
+	// usages beyond the retention are deleted.
+	oldTimestamp := U.TimeNowZ().AddDate(0, 0, -(model.APIKeyUsageRetentionDays + 1)).Truncate(time.Minute).Unix()
+	status = store.GetStore().CreateOrIncrementAPIKeyUsages([]model.APIKeyUsage{{ProjectID: project.ID,
+		APIKeyID: crmKey.ID, Method: http.MethodPost, Route: uriCreate, Status: http.StatusOK,
+		Timestamp: oldTimestamp, Count: 1}})
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = store.GetStore().DeleteAPIKeyUsagesOlderThanGivenDays(model.APIKeyUsageRetentionDays)
+	assert.Equal(t, http.StatusOK, status)
+	usages, status = store.GetStore().GetAPIKeyUsages(project.ID, crmKey.ID, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, usages, 0)
+	usages, status = store.GetStore().GetAPIKeyUsages(project.ID, apiKey.ID, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.NotEmpty(t, usages)
+}
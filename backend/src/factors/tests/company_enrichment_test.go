@@ -3,6 +3,7 @@ package tests
 import (
 	"factors/cache"
 	cacheRedis "factors/cache/redis"
+	"factors/company_enrichment"
 	"factors/company_enrichment/demandbase"
 	"factors/company_enrichment/factors_deanon"
 	"factors/model/model"
@@ -222,3 +223,215 @@ func TestDemandbaseEnrich(t *testing.T) {
 	assert.Equal(t, 1, status)
 
 }
+
+// mockEnrichmentProvider records the calls on the provider to calls.
+type mockEnrichmentProvider struct {
+	name           string
+	eligible       bool
+	enrichedDomain string
+	calls          *[]string
+}
+
+func (p *mockEnrichmentProvider) Name() string {
+	return p.name
+}
+
+func (p *mockEnrichmentProvider) Feature() string {
+	return p.name
+}
+
+func (p *mockEnrichmentProvider) IsQuotaAvailable(projectId int64) (bool, error) {
+	return true, nil
+}
+
+func (p *mockEnrichmentProvider) IsEligible(request *company_enrichment.EnrichmentRequest, logCtx *log.Entry) (bool, error) {
+	return p.eligible, nil
+}
+
+func (p *mockEnrichmentProvider) Enrich(request *company_enrichment.EnrichmentRequest, logCtx *log.Entry) (string, int) {
+	*p.calls = append(*p.calls, "enrich:"+p.name)
+	if p.enrichedDomain == "" {
+		return "", 0
+	}
+	(*request.UserProperties)[U.ENRICHMENT_SOURCE] = p.name
+	(*request.EventProperties)[U.EP_COMPANY_ENRICHED] = p.name
+	return p.enrichedDomain, 1
+}
+
+func (p *mockEnrichmentProvider) Meter(projectId int64, domain string, logCtx *log.Entry) {
+	*p.calls = append(*p.calls, "meter:"+p.name)
+}
+
+func TestCompanyEnrichmentWaterfall(t *testing.T) {
+
+	t.Run("DefaultWaterfall", func(t *testing.T) {
+		waterfall, err := model.GetCompanyEnrichmentWaterfall(&model.ProjectSetting{})
+		assert.Nil(t, err)
+		assert.Nil(t, model.ValidateCompanyEnrichmentWaterfall(waterfall))
+		assert.False(t, waterfall.FallbackOnFailure)
+		assert.Len(t, waterfall.Providers, len(model.CompanyEnrichmentProviders))
+		assert.Equal(t, model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE, waterfall.Providers[0].Name)
+	})
+
+	t.Run("ValidateWaterfall", func(t *testing.T) {
+		err := model.ValidateCompanyEnrichmentWaterfall(model.CompanyEnrichmentWaterfall{})
+		assert.NotNil(t, err)
+
+		err = model.ValidateCompanyEnrichmentWaterfall(model.CompanyEnrichmentWaterfall{
+			Providers: []model.CompanyEnrichmentProviderConfig{{Name: "unknown"}},
+		})
+		assert.NotNil(t, err)
+
+		err = model.ValidateCompanyEnrichmentWaterfall(model.CompanyEnrichmentWaterfall{
+			Providers: []model.CompanyEnrichmentProviderConfig{
+				{Name: model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT},
+				{Name: model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT},
+			},
+		})
+		assert.NotNil(t, err)
+
+		err = model.ValidateCompanyEnrichmentWaterfall(model.CompanyEnrichmentWaterfall{
+			Providers: []model.CompanyEnrichmentProviderConfig{
+				{Name: model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON},
+				{Name: model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT},
+			},
+			FallbackOnFailure: true,
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("ProviderRules", func(t *testing.T) {
+		assert.True(t, company_enrichment.IsEnrichmentRulesPassed(nil, "US", "https://example.com/pricing"))
+
+		rules := &model.SixSignalConfig{
+			CountryInclude: []model.SixSignalFilter{{Value: "US", Type: model.EqualsOpStr}},
+			PagesInclude:   []model.SixSignalFilter{{Value: "pricing", Type: model.ContainsOpStr}},
+		}
+		assert.True(t, company_enrichment.IsEnrichmentRulesPassed(rules, "US", "https://example.com/pricing"))
+		assert.False(t, company_enrichment.IsEnrichmentRulesPassed(rules, "IN", "https://example.com/pricing"))
+		assert.False(t, company_enrichment.IsEnrichmentRulesPassed(rules, "US", "https://example.com/blog"))
+	})
+
+	t.Run("SharedCacheOfUnavailableProvider", func(t *testing.T) {
+		project, err := SetupProjectReturnDAO()
+		assert.Nil(t, err)
+
+		intClearbit := false
+		waterfall, err := U.EncodeStructTypeToPostgresJsonb(&model.CompanyEnrichmentWaterfall{
+			Providers: []model.CompanyEnrichmentProviderConfig{{Name: model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT}},
+		})
+		assert.Nil(t, err)
+		projectSettings := &model.ProjectSetting{ProjectId: project.ID, IntClearBit: &intClearbit,
+			CompanyEnrichmentWaterfall: waterfall}
+
+		clientIP := "89.76.236.198"
+		logCtx := log.WithField("project_id", project.ID)
+		for _, provider := range []string{model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT, model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE} {
+			company_enrichment.SetCompanyEnrichmentCacheResult(project.ID, clientIP, company_enrichment.CompanyEnrichmentCacheResult{
+				Provider:        provider,
+				Domain:          "example.com",
+				Properties:      U.PropertiesMap{U.ENRICHMENT_SOURCE: provider},
+				EventProperties: U.PropertiesMap{U.EP_COMPANY_ENRICHED: provider},
+			})
+
+			// provider disabled on the project, or not on the waterfall, is not used from the cache.
+			userProperties := make(U.PropertiesMap)
+			eventProperties := make(U.PropertiesMap)
+			domain, status := company_enrichment.EnrichByWaterfall(&company_enrichment.EnrichmentRequest{
+				ProjectSettings: projectSettings,
+				UserProperties:  &userProperties,
+				EventProperties: &eventProperties,
+				ClientIP:        clientIP,
+			}, logCtx)
+			assert.Equal(t, 0, status, provider)
+			assert.Equal(t, "", domain, provider)
+			assert.NotContains(t, userProperties, U.ENRICHMENT_SOURCE, provider)
+			assert.NotContains(t, eventProperties, U.EP_COMPANY_ENRICHED, provider)
+		}
+	})
+
+	t.Run("FallbackOrderAndSharedCache", func(t *testing.T) {
+		project, err := SetupProjectReturnDAO()
+		assert.Nil(t, err)
+
+		calls := make([]string, 0)
+		for _, provider := range []*mockEnrichmentProvider{
+			{name: model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT, eligible: true},
+			{name: model.COMPANY_ENRICHMENT_PROVIDER_SIX_SIGNAL, eligible: false, enrichedDomain: "sixsignal.com"},
+			{name: model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE, eligible: true, enrichedDomain: "demandbase.com"},
+			{name: model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON, eligible: true, enrichedDomain: "deanon.com"},
+		} {
+			provider.calls = &calls
+			defer company_enrichment.RegisterProvider(provider)()
+		}
+
+		getProjectSettings := func(fallbackOnFailure bool) *model.ProjectSetting {
+			waterfall, err := U.EncodeStructTypeToPostgresJsonb(&model.CompanyEnrichmentWaterfall{
+				Providers: []model.CompanyEnrichmentProviderConfig{
+					{Name: model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT},
+					{Name: model.COMPANY_ENRICHMENT_PROVIDER_SIX_SIGNAL},
+					{Name: model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE},
+					{Name: model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON},
+				},
+				FallbackOnFailure: fallbackOnFailure,
+			})
+			assert.Nil(t, err)
+			return &model.ProjectSetting{ProjectId: project.ID, CompanyEnrichmentWaterfall: waterfall}
+		}
+		logCtx := log.WithField("project_id", project.ID)
+		var request company_enrichment.EnrichmentRequest
+		enrich := func(projectSettings *model.ProjectSetting, clientIP string) (string, int, U.PropertiesMap, U.PropertiesMap) {
+			userProperties := make(U.PropertiesMap)
+			eventProperties := make(U.PropertiesMap)
+			request = company_enrichment.EnrichmentRequest{
+				ProjectSettings: projectSettings,
+				UserProperties:  &userProperties,
+				EventProperties: &eventProperties,
+				ClientIP:        clientIP,
+			}
+			domain, status := company_enrichment.EnrichByWaterfall(&request, logCtx)
+			return domain, status, userProperties, eventProperties
+		}
+
+		// enrichment stops on the failure of the first eligible provider, without fallback.
+		domain, status, _, _ := enrich(getProjectSettings(false), "89.76.236.101")
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "", domain)
+		assert.Equal(t, []string{"enrich:" + model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT}, calls)
+		assert.Equal(t, []string{model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT}, request.AttemptedProviders)
+		assert.False(t, request.IsAttemptedBy(model.COMPANY_ENRICHMENT_PROVIDER_FACTORS_DEANON))
+
+		// not eligible providers are skipped and the enrichment stops on the first success.
+		calls = calls[:0]
+		domain, status, userProperties, eventProperties := enrich(getProjectSettings(true), "89.76.236.102")
+		assert.Equal(t, 1, status)
+		assert.Equal(t, "demandbase.com", domain)
+		assert.Equal(t, []string{
+			"enrich:" + model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT,
+			"enrich:" + model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE,
+			"meter:" + model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE,
+		}, calls)
+		assert.Equal(t, []string{model.COMPANY_ENRICHMENT_PROVIDER_CLEARBIT,
+			model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE}, request.AttemptedProviders)
+		assert.Equal(t, model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE, userProperties[U.ENRICHMENT_SOURCE])
+		assert.Equal(t, model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE, eventProperties[U.EP_COMPANY_ENRICHED])
+
+		// enrichment of the IP is served from the shared cache and metered as by the provider.
+		calls = calls[:0]
+		domain, status, userProperties, eventProperties = enrich(getProjectSettings(true), "89.76.236.102")
+		assert.Equal(t, 1, status)
+		assert.Equal(t, "demandbase.com", domain)
+		assert.Equal(t, []string{"meter:" + model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE}, calls)
+		assert.True(t, request.IsAttemptedBy(model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE))
+		assert.Equal(t, model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE, userProperties[U.ENRICHMENT_SOURCE])
+		assert.Equal(t, model.COMPANY_ENRICHMENT_PROVIDER_DEMANDBASE, eventProperties[U.EP_COMPANY_ENRICHED])
+	})
+
+	t.Run("ProvidersRegistered", func(t *testing.T) {
+		for _, name := range model.CompanyEnrichmentProviders {
+			provider, exists := company_enrichment.GetProvider(name)
+			assert.True(t, exists)
+			assert.Equal(t, name, provider.Name())
+		}
+	})
+}
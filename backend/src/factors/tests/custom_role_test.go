@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	C "factors/config"
+	H "factors/handler"
+	"factors/handler/helpers"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomRolePermissionForRequest(t *testing.T) {
+	for _, tc := range []struct {
+		method     string
+		path       string
+		permission string
+		exists     bool
+	}{
+		{http.MethodGet, "/projects/:project_id/dashboards", "dashboards:read", true},
+		{http.MethodPut, "/projects/:project_id/dashboards/:dashboard_id", "dashboards:write", true},
+		{http.MethodPost, "/projects/:project_id/dashboard/:dashboard_id/units/query/web_analytics", "dashboards:read", true},
+		{http.MethodPost, "/projects/:project_id/v1/dashboards/multi/:dashboard_ids/units", "dashboards:write", true},
+		{http.MethodGet, "/projects/:project_id/v1/attribution/dashboards", "dashboards:read", true},
+		{http.MethodPost, "/projects/:project_id/v1/attribution/queries", "reports:write", true},
+		{http.MethodPut, "/projects/:project_id/v1/profiles/segments/:segment_id/table_properties", "profiles:write", true},
+		{http.MethodPost, "/projects/:project_id/segments/:segment_id/analytics/widget_group/:widget_group_id/query", "segments:read", true},
+		{http.MethodDelete, "/projects/:project_id/v1/eventtriggeralert/:id", "alerts:write", true},
+		{http.MethodPost, "/projects/:project_id/v1/workflow", "workflows:write", true},
+		{http.MethodPut, "/projects/:project_id/settings", "settings:write", true},
+		{http.MethodDelete, "/projects/:project_id/v1/marketo/disable", "integrations:write", true},
+		{http.MethodPut, "/projects/:project_id", "settings:write", true},
+		{http.MethodPost, "/projects/:project_id/agents/invite", "", true},
+		{http.MethodPost, "/projects/:project_id/agents/batchinvite", "", true},
+		{http.MethodPut, "/projects/:project_id/agents/update", "admin:write", true},
+		{http.MethodGet, "/projects/:project_id/agents", "admin:read", true},
+		{http.MethodPost, "/projects/:project_id/billing/upgrade", "admin:write", true},
+		{http.MethodGet, "/projects/:project_id/event_names", "", false},
+		{http.MethodPut, "/projects/:project_id/v1/profiles/:type/table_properties", "profiles:write", true},
+		{http.MethodPost, "/projects/:project_id/v1/profiles/users", "profiles:read", true},
+		{http.MethodPut, "/projects/:project_id/v1/profiles/events_config/:event_name", "settings:write", true},
+		{http.MethodPost, "/projects/:project_id/shareable_url", "reports:write", true},
+		{http.MethodPost, "/projects/:project_id/v1/kpi/query", "reports:read", true},
+		{http.MethodPost, "/projects/:project_id/v1/kpi/property_mappings", "settings:write", true},
+		{http.MethodPost, "/projects/:project_id/v1/kpi/property_mappings/common_properties", "settings:read", true},
+		{http.MethodPut, "/projects/:project_id/v1/accscore/weights", "settings:write", true},
+		{http.MethodPost, "/projects/:project_id/feedback", "", true},
+		{http.MethodPut, "/projects/:project_id/agents/remove", "", true},
+		{http.MethodPost, "/projects/:project_id/v1/crm_custom_sources", "integrations:write", true},
+		{http.MethodGet, "/projects/list", "", false},
+		{http.MethodPost, "/integrations/adwords/enable", "integrations:write", true},
+		{http.MethodPost, "/integrations/hubspot/auth", "integrations:write", true},
+		{http.MethodPost, "/integrations/linkedin/ad_accounts", "integrations:write", true},
+		{http.MethodDelete, "/integrations/:project_id/:channel_name", "integrations:write", true},
+		{http.MethodPost, "/common/dashboard_template/create", "dashboards:write", true},
+		{http.MethodDelete, "/mql/common/alert_templates/:id", "settings:write", true},
+		{http.MethodPost, "/common/unknown", "", false},
+	} {
+		permission, exists := model.GetPermissionForRequest(tc.method, tc.path)
+		assert.Equal(t, tc.exists, exists, tc.path)
+		assert.Equal(t, tc.permission, permission, tc.path)
+	}
+}
+
+func TestCustomRolePredefinedRolePermissions(t *testing.T) {
+	for _, role := range []int64{model.AGENT, model.ADMIN, model.VIEWER, model.ANALYST, model.MARKETER, model.INTEGRATION_ADMIN} {
+		assert.True(t, model.IsValidRole(role))
+	}
+	assert.False(t, model.IsValidRole(0))
+	assert.False(t, model.IsValidRole(7))
+
+	// agent keeps write access on all resources, except the admin only actions.
+	for _, resource := range model.PermissionResources {
+		assert.True(t, model.HasPermission(model.GetPredefinedRolePermissions(model.AGENT),
+			model.GetPermission(resource, model.PermissionActionWrite)))
+	}
+	assert.False(t, model.HasPermission(model.GetPredefinedRolePermissions(model.AGENT), "admin:write"))
+	assert.True(t, model.HasPermission(model.GetPredefinedRolePermissions(model.ADMIN), "admin:write"))
+	assert.True(t, model.HasPermission(model.GetPredefinedRolePermissions(model.VIEWER), "admin:read"))
+
+	// roles can not be above the inviter's role.
+	assert.True(t, model.IsRoleWithinPermissions(model.ADMIN, model.GetPredefinedRolePermissions(model.ADMIN)))
+	assert.True(t, model.IsRoleWithinPermissions(model.VIEWER, model.GetPredefinedRolePermissions(model.ANALYST)))
+	assert.False(t, model.IsRoleWithinPermissions(model.ADMIN, model.GetPredefinedRolePermissions(model.AGENT)))
+	assert.False(t, model.IsRoleWithinPermissions(model.AGENT, model.GetPredefinedRolePermissions(model.INTEGRATION_ADMIN)))
+	assert.False(t, model.IsRoleWithinPermissions(model.MARKETER, model.GetPredefinedRolePermissions(model.ANALYST)))
+
+	viewer := model.GetPredefinedRolePermissions(model.VIEWER)
+	assert.True(t, model.HasPermission(viewer, "dashboards:read"))
+	assert.False(t, model.HasPermission(viewer, "dashboards:write"))
+	assert.False(t, model.HasPermission(viewer, "integrations:write"))
+
+	analyst := model.GetPredefinedRolePermissions(model.ANALYST)
+	assert.True(t, model.HasPermission(analyst, "segments:write"))
+	assert.True(t, model.HasPermission(analyst, "profiles:write"))
+	// segments access does not give access to the profiles.
+	assert.False(t, model.HasPermission([]string{"segments:write"}, "profiles:read"))
+	assert.True(t, model.HasPermission(analyst, "alerts:read"))
+	assert.False(t, model.HasPermission(analyst, "alerts:write"))
+
+	marketer := model.GetPredefinedRolePermissions(model.MARKETER)
+	assert.True(t, model.HasPermission(marketer, "workflows:write"))
+	assert.False(t, model.HasPermission(marketer, "integrations:write"))
+
+	integrationAdmin := model.GetPredefinedRolePermissions(model.INTEGRATION_ADMIN)
+	assert.True(t, model.HasPermission(integrationAdmin, "integrations:write"))
+	assert.False(t, model.HasPermission(integrationAdmin, "dashboards:write"))
+
+	// write implies read.
+	assert.True(t, model.HasPermission([]string{"reports:write"}, "reports:read"))
+	assert.False(t, model.HasPermission([]string{"reports:read"}, "reports:write"))
+}
+
+func TestCustomRolePermissionsValidation(t *testing.T) {
+	_, err := model.ValidateCustomRolePermissions(nil)
+	assert.NotNil(t, err)
+	_, err = model.ValidateCustomRolePermissions([]string{"dashboards:read", "dashboards:delete"})
+	assert.NotNil(t, err)
+	_, err = model.ValidateCustomRolePermissions([]string{"unknown:read"})
+	assert.NotNil(t, err)
+	_, err = model.ValidateCustomRolePermissions([]string{"admin:write"})
+	assert.NotNil(t, err)
+
+	permissionsJson, err := model.ValidateCustomRolePermissions([]string{"dashboards:read", "segments:write"})
+	assert.Nil(t, err)
+	permissions, err := model.GetCustomRolePermissions(&model.CustomRole{Permissions: permissionsJson})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dashboards:read", "segments:write"}, permissions)
+}
+
+func TestCustomRoleAllMutatingRoutesHavePermission(t *testing.T) {
+	r := gin.Default()
+	H.InitAppRoutes(r)
+
+	for _, route := range r.Routes() {
+		if route.Method == http.MethodGet || !strings.Contains(route.Path, H.ROUTE_PROJECTS_ROOT+"/:project_id") {
+			continue
+		}
+
+		_, exists := model.GetPermissionForRequest(route.Method, route.Path)
+		assert.True(t, exists, route.Method+" "+route.Path)
+	}
+}
+
+func TestCustomRoleViewerDeniedOnIntegrationRoutes(t *testing.T) {
+	r := gin.Default()
+	H.InitIntRoutes(r)
+
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+	viewer, errCode := SetupAgentReturnDAO(getRandomEmail(), "+3423647568")
+	assert.Equal(t, http.StatusCreated, errCode)
+	_, errCode = store.GetStore().CreateProjectAgentMappingWithDependencies(&model.ProjectAgentMapping{
+		ProjectID: project.ID,
+		AgentUUID: viewer.UUID,
+		Role:      model.VIEWER,
+	})
+	assert.Equal(t, http.StatusCreated, errCode)
+	authData, err := helpers.GetAuthData(viewer.Email, viewer.UUID, viewer.Salt, time.Second*1000)
+	assert.Nil(t, err)
+	cookie := &http.Cookie{Name: C.GetFactorsCookieName(), Value: authData, MaxAge: 1000}
+
+	rb := U.NewRequestBuilder(http.MethodPost, "/integrations/adwords/enable").
+		WithPostParams(map[string]string{"project_id": fmt.Sprintf("%d", project.ID)}).
+		WithCookie(cookie)
+	req, err := rb.Build()
+	assert.Nil(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	rb = U.NewRequestBuilder(http.MethodDelete, fmt.Sprintf("/integrations/%d/adwords", project.ID)).
+		WithCookie(cookie)
+	req, err = rb.Build()
+	assert.Nil(t, err)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// project on the payload has to be of the agent.
+	rb = U.NewRequestBuilder(http.MethodPost, "/integrations/adwords/enable").
+		WithPostParams(map[string]string{"project_id": fmt.Sprintf("%d", project.ID+1)}).
+		WithCookie(cookie)
+	req, err = rb.Build()
+	assert.Nil(t, err)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
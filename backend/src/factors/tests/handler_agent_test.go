@@ -241,6 +241,10 @@ func TestAPIAgentInvite(t *testing.T) {
 		assert.Nil(t, err)
 		w := sendAgentInviteRequest(emailToAdd, model.AGENT, project.ID, authData, 100, r)
 		assert.Equal(t, http.StatusCreated, w.Code)
+
+		// non admin can not invite an admin.
+		w = sendAgentInviteRequest(getRandomEmail(), model.ADMIN, project.ID, authData, 100, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
 	})
 
 	t.Run("InviteAgentSuccessWithRoleId", func(t *testing.T) {
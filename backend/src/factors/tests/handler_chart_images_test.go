@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bytes"
+	C "factors/config"
+	H "factors/handler"
+	qc "factors/quickchart"
+	U "factors/util"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestChartConfig(chartType string) qc.ChartConfig {
+	return qc.ChartConfig{
+		Type: chartType,
+		Data: qc.ChartData{
+			Labels: []interface{}{"2024-01-01T00:00:00+05:30", "2024-01-08T00:00:00+05:30", "2024-01-15T00:00:00+05:30"},
+			DataSets: []qc.Dataset{
+				{Label: "Sessions", Data: []interface{}{1200.0, 1500.0, 900.0}},
+				{Label: "Form Submissions", Data: []interface{}{200, int64(300), "450"}},
+			},
+		},
+	}
+}
+
+func TestRenderChartAndTableImages(t *testing.T) {
+	t.Run("ChartPNG", func(t *testing.T) {
+		for _, chartType := range []string{"line", "bar"} {
+			image, err := qc.RenderChart(getTestChartConfig(chartType), qc.IMAGE_FORMAT_PNG)
+			assert.Nil(t, err)
+			decodedImage, err := png.Decode(bytes.NewReader(image))
+			assert.Nil(t, err)
+			assert.Equal(t, 1600, decodedImage.Bounds().Dx())
+			assert.Equal(t, 800, decodedImage.Bounds().Dy())
+		}
+	})
+
+	t.Run("ChartSVG", func(t *testing.T) {
+		image, err := qc.RenderChart(getTestChartConfig("line"), qc.IMAGE_FORMAT_SVG)
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(image), "<svg"))
+		assert.Contains(t, string(image), ">Sessions</text>")
+		assert.Contains(t, string(image), ">08 Jan</text>")
+	})
+
+	t.Run("TableSVG", func(t *testing.T) {
+		tableConfig := qc.TableConfig{
+			Title:   "Sessions <by> Source",
+			Columns: []qc.Column{{Title: "Source", DataIndex: "source"}, {Title: "Sessions", DataIndex: "sessions"}},
+			DataSource: []interface{}{
+				map[string]interface{}{"source": "google", "sessions": 12345.678},
+				map[string]interface{}{"source": "linkedin", "sessions": 42},
+			},
+		}
+		image, err := qc.RenderTable(tableConfig, qc.IMAGE_FORMAT_SVG)
+		assert.Nil(t, err)
+		assert.Contains(t, string(image), ">Sessions &lt;by&gt; Source</text>")
+		assert.Contains(t, string(image), ">12,345.68</text>")
+
+		_, err = qc.RenderTable(tableConfig, qc.IMAGE_FORMAT_PNG)
+		assert.Nil(t, err)
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := qc.RenderChart(qc.ChartConfig{Type: "line"}, qc.IMAGE_FORMAT_PNG)
+		assert.NotNil(t, err)
+		_, err = qc.RenderChart(getTestChartConfig("line"), "gif")
+		assert.NotNil(t, err)
+	})
+}
+
+func sendGetChartImageReq(r *gin.Engine, imageURL string) *httptest.ResponseRecorder {
+	parsedURL, _ := url.Parse(imageURL)
+	req, _ := http.NewRequest(http.MethodGet, parsedURL.RequestURI(), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIGetChartImage(t *testing.T) {
+	r := gin.Default()
+	H.InitAppRoutes(r)
+
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	signingKey := C.GetConfig().ChartImageURLSigningKey
+	C.GetConfig().ChartImageURLSigningKey = "test_signing_key"
+	defer func() { C.GetConfig().ChartImageURLSigningKey = signingKey }()
+
+	imageURL, err := qc.GetChartImageURL(project.ID, getTestChartConfig("line"), qc.IMAGE_FORMAT_PNG)
+	assert.Nil(t, err)
+	assert.NotContains(t, imageURL, "Sessions")
+
+	t.Run("ValidSignature", func(t *testing.T) {
+		w := sendGetChartImageReq(r, imageURL)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		_, err := png.Decode(w.Body)
+		assert.Nil(t, err)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		w := sendGetChartImageReq(r, strings.Replace(imageURL, "signature=", "signature=0", 1))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// signature of one project is not valid for the other.
+		w = sendGetChartImageReq(r, strings.Replace(imageURL, qc.ROUTE_CHART_IMAGES+"/"+U.GetPropertyValueAsString(project.ID),
+			qc.ROUTE_CHART_IMAGES+"/"+U.GetPropertyValueAsString(project.ID+1), 1))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("ExpiredURL", func(t *testing.T) {
+		parsedURL, _ := url.Parse(imageURL)
+		imageName := parsedURL.Path[strings.LastIndex(parsedURL.Path, "/")+1:]
+		w := sendGetChartImageReq(r, qc.GetSignedImageURL(project.ID, imageName, U.TimeNowUnix()-1))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,289 @@
+package tests
+
+import (
+	"encoding/json"
+	C "factors/config"
+	enrichment "factors/crm_enrichment"
+	H "factors/handler"
+	"factors/handler/helpers"
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCRMCustomSourceName(t *testing.T) {
+	for _, name := range []string{"pipedrive", "zoho", "acme2"} {
+		assert.Nil(t, model.ValidateCRMCustomSourceName(name))
+	}
+
+	for _, name := range []string{"", "a", "Pipedrive", "zoho_crm", "2crm", "salesforce", "hubspot", "marketo", "sf", "custom"} {
+		assert.NotNil(t, model.ValidateCRMCustomSourceName(name), name)
+	}
+
+	assert.Equal(t, "custom_crm_pipedrive", model.GetCRMCustomSourceAlias("pipedrive"))
+	assert.True(t, model.IsCRMCustomSourceAlias("custom_crm_pipedrive"))
+	assert.False(t, model.IsCRMCustomSourceAlias(U.CRM_SOURCE_NAME_MARKETO))
+	assert.Equal(t, "pipedrive", model.GetCRMCustomSourceNameByAlias("custom_crm_pipedrive"))
+	assert.Equal(t, "$custom_crm_pipedrive_contact_created",
+		model.GetCRMCustomSourceUserEventName("custom_crm_pipedrive", model.CRMCustomSourceObjectTypeNameContact, model.CRMActionCreated))
+}
+
+func sendCRMCustomRecordsReq(r *gin.Engine, source, objectName, privateToken string, records interface{}) (int, map[string]interface{}) {
+	payload, _ := json.Marshal(map[string]interface{}{"records": records})
+	w := ServePostRequestWithHeaders(r, fmt.Sprintf("%s/%s/%s", H.ROUTE_CRM_ROOT, source, objectName), payload,
+		map[string]string{"Authorization": privateToken})
+	return w.Code, DecodeJSONResponseToMap(w.Body)
+}
+
+func sendRegisterCRMCustomSourceReq(r *gin.Engine, projectID int64, agent *model.Agent, name string) *httptest.ResponseRecorder {
+	cookieData, err := helpers.GetAuthData(agent.Email, agent.UUID, agent.Salt, 100*time.Second)
+	if err != nil {
+		log.WithError(err).Error("Error creating cookieData")
+	}
+
+	rb := C.NewRequestBuilderWithPrefix(http.MethodPost, "/projects/"+strconv.FormatInt(projectID, 10)+"/v1/crm_custom_sources").
+		WithPostParams(map[string]interface{}{"name": name}).
+		WithCookie(&http.Cookie{
+			Name:   C.GetFactorsCookieName(),
+			Value:  cookieData,
+			MaxAge: 1000,
+		})
+
+	req, err := rb.Build()
+	if err != nil {
+		log.WithError(err).Error("Error building register crm custom source request.")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCRMCustomAPIAndEnrichment(t *testing.T) {
+	r := gin.Default()
+	H.InitAppRoutes(r)
+	H.InitCRMRoutes(r)
+
+	project, agent, err := SetupProjectWithAdminAgentDAO()
+	assert.Nil(t, err)
+
+	source := "acme"
+	timestamp := time.Now().AddDate(0, 0, -1).Unix()
+
+	t.Run("InvalidRequests", func(t *testing.T) {
+		status, _ := sendCRMCustomRecordsReq(r, source, "users", "invalid_token", []interface{}{})
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		status, _ = sendCRMCustomRecordsReq(r, "hubspot", "users", project.PrivateToken,
+			[]map[string]interface{}{{"id": "1", "type": "contact", "timestamp": timestamp, "properties": map[string]interface{}{"name": "a"}}})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = sendCRMCustomRecordsReq(r, source, "users", project.PrivateToken, []interface{}{})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		// invalid record fails the whole request.
+		status, response := sendCRMCustomRecordsReq(r, source, "users", project.PrivateToken, []map[string]interface{}{
+			{"id": "1", "type": "contact", "timestamp": timestamp, "properties": map[string]interface{}{"name": "a"}},
+			{"id": "2", "type": "account", "timestamp": timestamp, "properties": map[string]interface{}{"name": "b"}},
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, response["Error"], "index 1")
+
+		// push to the source not registered by the admin is rejected, without registering it.
+		status, _ = sendCRMCustomRecordsReq(r, source, "users", project.PrivateToken,
+			[]map[string]interface{}{{"id": "1", "type": "contact", "timestamp": timestamp, "properties": map[string]interface{}{"name": "a"}}})
+		assert.Equal(t, http.StatusNotFound, status)
+
+		_, status = store.GetStore().GetCRMCustomSources(project.ID)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("RegisterSources", func(t *testing.T) {
+		nonAdmin, err := SetupAgentWithProject(project.ID)
+		assert.Nil(t, err)
+		w := sendRegisterCRMCustomSourceReq(r, project.ID, nonAdmin, source)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = sendRegisterCRMCustomSourceReq(r, project.ID, agent, "hubspot")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = sendRegisterCRMCustomSourceReq(r, project.ID, agent, source)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = sendRegisterCRMCustomSourceReq(r, project.ID, agent, source)
+		assert.Equal(t, http.StatusFound, w.Code)
+
+		w = sendRegisterCRMCustomSourceReq(r, project.ID, agent, "zoho")
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	contacts := []map[string]interface{}{
+		{"id": "c1", "type": "contact", "timestamp": timestamp * 1000, "email": "c1@example.com",
+			"properties": map[string]interface{}{"name": "c1", "stage": "lead", "score": 10}},
+		{"id": "c1", "type": "contact", "timestamp": timestamp + 100, "email": "c1@example.com",
+			"properties": map[string]interface{}{"name": "c1", "stage": "customer", "score": 20}},
+		{"id": "l1", "type": "lead", "timestamp": timestamp, "properties": map[string]interface{}{"name": "l1"}},
+	}
+
+	t.Run("PushUsersActivitiesAndProperties", func(t *testing.T) {
+		status, response := sendCRMCustomRecordsReq(r, source, "users", project.PrivateToken, contacts)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, float64(3), response["created"])
+		assert.Equal(t, float64(0), response["duplicates"])
+
+		status, response = sendCRMCustomRecordsReq(r, source, "users", project.PrivateToken, contacts[:1])
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, float64(0), response["created"])
+		assert.Equal(t, float64(1), response["duplicates"])
+
+		customSource, status := store.GetStore().GetCRMCustomSourceByName(project.ID, source)
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, U.CRM_SOURCE_CUSTOM_MIN, customSource.Source)
+
+		status, response = sendCRMCustomRecordsReq(r, source, "activities", project.PrivateToken, []map[string]interface{}{
+			{"id": "a1", "name": "email_opened", "actor_type": "contact", "actor_id": "c1", "timestamp": timestamp + 50,
+				"properties": map[string]interface{}{"subject": "welcome"}},
+		})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, float64(1), response["created"])
+
+		status, response = sendCRMCustomRecordsReq(r, source, "properties", project.PrivateToken, []map[string]interface{}{
+			{"object_type": "contact", "name": "score", "label": "Score", "data_type": U.PropertyTypeNumerical},
+			{"object_type": "contact", "name": "stage", "data_type": "text"},
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, response["Error"], "index 1")
+
+		status, response = sendCRMCustomRecordsReq(r, source, "properties", project.PrivateToken, []map[string]interface{}{
+			{"object_type": "contact", "name": "score", "label": "Score", "data_type": U.PropertyTypeNumerical},
+		})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, float64(1), response["created"])
+
+		// second registered source of the project gets the next source id.
+		status, _ = sendCRMCustomRecordsReq(r, "zoho", "users", project.PrivateToken, contacts[2:])
+		assert.Equal(t, http.StatusOK, status)
+		zohoSource, status := store.GetStore().GetCRMCustomSourceByName(project.ID, "zoho")
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, U.CRM_SOURCE_CUSTOM_MIN+1, zohoSource.Source)
+	})
+
+	sourceAlias := model.GetCRMCustomSourceAlias(source)
+	smartEventFilter := &model.SmartCRMEventFilter{
+		Source:               sourceAlias,
+		ObjectType:           model.CRMCustomSourceObjectTypeNameContact,
+		Description:          "acme contact became customer",
+		FilterEvaluationType: model.FilterEvaluationTypeSpecific,
+		Filters: []model.PropertyFilter{
+			{
+				Name: "stage",
+				Rules: []model.CRMFilterRule{
+					{
+						PropertyState: model.CurrentState,
+						Value:         "customer",
+						Operator:      model.COMPARE_EQUAL,
+					},
+					{
+						PropertyState: model.PreviousState,
+						Value:         "lead",
+						Operator:      model.COMPARE_EQUAL,
+					},
+				},
+				LogicalOp: model.LOGICAL_OP_AND,
+			},
+		},
+		LogicalOp:               model.LOGICAL_OP_AND,
+		TimestampReferenceField: model.TimestampReferenceTypeDocument,
+	}
+
+	t.Run("SmartEventFilter", func(t *testing.T) {
+		smartEventName, status := store.GetStore().CreateOrGetCRMSmartEventFilterEventName(project.ID,
+			&model.EventName{ProjectId: project.ID, Name: "acme customer"}, smartEventFilter)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, model.TYPE_CRM_CUSTOM, smartEventName.Type)
+
+		// source not registered on the project.
+		unregisteredFilter := *smartEventFilter
+		unregisteredFilter.Source = model.GetCRMCustomSourceAlias("pipedrive")
+		_, status = store.GetStore().CreateOrGetCRMSmartEventFilterEventName(project.ID,
+			&model.EventName{ProjectId: project.ID, Name: "pipedrive customer"}, &unregisteredFilter)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		categorical, _ := store.GetStore().GetCRMCustomSourceObjectPropertiesName(project.ID, sourceAlias, "contact")
+		assert.Contains(t, categorical, "stage")
+		values := store.GetStore().GetCRMCustomSourceObjectValuesByPropertyName(project.ID, sourceAlias, "contact", "stage")
+		assert.Contains(t, values, "customer")
+	})
+
+	t.Run("Enrichment", func(t *testing.T) {
+		customSource, status := store.GetStore().GetCRMCustomSourceByName(project.ID, source)
+		assert.Equal(t, http.StatusFound, status)
+
+		sourceConfig, err := enrichment.NewCustomCRMEnrichmentConfig(customSource, 0)
+		assert.Nil(t, err)
+
+		enrichStatus := enrichment.SyncProperties(project.ID, sourceConfig)
+		for i := range enrichStatus {
+			assert.Equal(t, U.CRM_SYNC_STATUS_SUCCESS, enrichStatus[i].Status)
+		}
+
+		enrichStatus = enrichment.Enrich(project.ID, sourceConfig, 2, 0)
+		assert.NotEmpty(t, enrichStatus)
+		for i := range enrichStatus {
+			assert.Equal(t, U.CRM_SYNC_STATUS_SUCCESS, enrichStatus[i].Status)
+		}
+
+		crmUser, status := store.GetStore().GetCRMUserByTypeAndAction(project.ID, customSource.Source, "c1",
+			model.CRMCustomSourceObjectTypeContact, model.CRMActionCreated)
+		assert.Equal(t, http.StatusFound, status)
+		assert.NotEqual(t, "", crmUser.UserID)
+		assert.Equal(t, timestamp, crmUser.Timestamp)
+		userID := crmUser.UserID
+
+		user, status := store.GetStore().GetUser(project.ID, userID)
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, "c1@example.com", user.CustomerUserId)
+		var userProperties map[string]interface{}
+		json.Unmarshal(user.Properties.RawMessage, &userProperties)
+		assert.Equal(t, "customer", userProperties["$custom_crm_acme_contact_stage"])
+		assert.Equal(t, float64(20), userProperties["$custom_crm_acme_contact_score"])
+
+		for _, name := range []string{
+			"$custom_crm_acme_contact_created",
+			"$custom_crm_acme_contact_updated",
+			"$custom_crm_acme_email_opened",
+			"acme customer",
+		} {
+			eventName, status := store.GetStore().GetEventName(name, project.ID)
+			assert.Equal(t, http.StatusFound, status, name)
+			events, status := store.GetStore().GetUserEventsByEventNameId(project.ID, userID, eventName.ID)
+			assert.Equal(t, http.StatusFound, status, name)
+			assert.Len(t, events, 1, name)
+
+			if name == "acme customer" {
+				var eventProperties map[string]interface{}
+				json.Unmarshal(events[0].Properties.RawMessage, &eventProperties)
+				assert.Equal(t, "customer", eventProperties["$curr_custom_crm_acme_contact_stage"])
+				assert.Equal(t, "lead", eventProperties["$prev_custom_crm_acme_contact_stage"])
+				assert.Equal(t, timestamp+101, events[0].Timestamp)
+			}
+		}
+
+		// objects of the other source are not enriched.
+		zohoSource, status := store.GetStore().GetCRMCustomSourceByName(project.ID, "zoho")
+		assert.Equal(t, http.StatusFound, status)
+		crmUser, status = store.GetStore().GetCRMUserByTypeAndAction(project.ID, zohoSource.Source, "l1",
+			model.CRMCustomSourceObjectTypeLead, model.CRMActionCreated)
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, "", crmUser.UserID)
+	})
+}
@@ -2311,6 +2311,206 @@ func TestIntSegmentHandlerWithIdentifyEvent(t *testing.T) {
 	assertKeysExistAndNotEmpty(t, userPropertiesMap["address"].(map[string]interface{}), []string{"street", "city"})
 }
 
+func TestIntSegmentHandlerWithGroupAndAliasEvent(t *testing.T) {
+	// Initialize routes and dependent data.
+	r := gin.Default()
+	H.InitSDKServiceRoutes(r)
+	uri := "/integrations/segment"
+
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+	assert.NotNil(t, project)
+	enable := true
+	_, errCode := store.GetStore().UpdateProjectSettings(project.ID, &model.ProjectSetting{IntSegment: &enable})
+	assert.Equal(t, http.StatusAccepted, errCode)
+
+	t.Run("GroupWithWebsite", func(t *testing.T) {
+		payload := `
+		{
+			"anonymousId": "anon_group_1",
+			"userId": "user_group_1",
+			"type": "group",
+			"groupId": "initech_1",
+			"timestamp": "2019-06-24T15:32:33Z",
+			"traits": {
+				"name": "Initech",
+				"website": "https://www.initech.com",
+				"employees": 329,
+				"address": {
+					"city": "San Francisco"
+				}
+			}
+		}
+		`
+
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+		jsonResponse, _ := ioutil.ReadAll(w.Body)
+		var jsonResponseMap map[string]interface{}
+		json.Unmarshal(jsonResponse, &jsonResponseMap)
+		assert.Nil(t, jsonResponseMap["error"])
+
+		groupUser, status := store.GetStore().GetGroupUserByGroupID(project.ID, model.GROUP_NAME_SEGMENT_ACCOUNT, "initech.com")
+		assert.Equal(t, http.StatusFound, status)
+		groupProperties, err := U.DecodePostgresJsonb(&groupUser.Properties)
+		assert.Nil(t, err)
+		assert.Equal(t, "Initech", (*groupProperties)[U.SEGMENT_ACCOUNT_NAME])
+		assert.Equal(t, "initech_1", (*groupProperties)[U.SEGMENT_ACCOUNT_ID])
+		assert.Equal(t, float64(329), (*groupProperties)["$segment_account_employees"])
+		// nested traits are skipped.
+		assert.Nil(t, (*groupProperties)["$segment_account_address"])
+
+		group, status := store.GetStore().GetGroup(project.ID, model.GROUP_NAME_SEGMENT_ACCOUNT)
+		assert.Equal(t, http.StatusFound, status)
+		user, status := store.GetStore().GetUser(project.ID, jsonResponseMap["user_id"].(string))
+		assert.Equal(t, http.StatusFound, status)
+		groupUserID, err := model.GetUserGroupUserID(user, group.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, groupUser.ID, groupUserID)
+	})
+
+	t.Run("GroupsSharingDomain", func(t *testing.T) {
+		payload := `
+		{
+			"anonymousId": "anon_group_2",
+			"userId": "user_group_2",
+			"type": "group",
+			"groupId": "initech_2",
+			"timestamp": "2019-06-24T15:32:33Z",
+			"traits": {
+				"name": "Initech Europe",
+				"website": "https://www.initech.com"
+			}
+		}
+		`
+
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// groups sharing the domain are tracked on the same group user.
+		groupUser, status := store.GetStore().GetGroupUserByGroupID(project.ID, model.GROUP_NAME_SEGMENT_ACCOUNT, "initech.com")
+		assert.Equal(t, http.StatusFound, status)
+		groupProperties, err := U.DecodePostgresJsonb(&groupUser.Properties)
+		assert.Nil(t, err)
+		assert.Equal(t, "Initech Europe", (*groupProperties)[U.SEGMENT_ACCOUNT_NAME])
+		assert.Equal(t, "initech_2", (*groupProperties)[U.SEGMENT_ACCOUNT_ID])
+	})
+
+	t.Run("GroupWithDomainAsGroupID", func(t *testing.T) {
+		payload := `
+		{
+			"anonymousId": "anon_group_3",
+			"userId": "user_group_3",
+			"type": "group",
+			"groupId": "globex.com",
+			"timestamp": "2019-06-24T15:32:33Z",
+			"traits": {
+				"name": "Globex"
+			}
+		}
+		`
+
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		groupUser, status := store.GetStore().GetGroupUserByGroupID(project.ID, model.GROUP_NAME_SEGMENT_ACCOUNT, "globex.com")
+		assert.Equal(t, http.StatusFound, status)
+		groupProperties, err := U.DecodePostgresJsonb(&groupUser.Properties)
+		assert.Nil(t, err)
+		assert.Equal(t, "Globex", (*groupProperties)[U.SEGMENT_ACCOUNT_NAME])
+		assert.Equal(t, "globex.com", (*groupProperties)[U.SEGMENT_ACCOUNT_DOMAIN])
+	})
+
+	t.Run("GroupWithoutDomain", func(t *testing.T) {
+		payload := `
+		{
+			"anonymousId": "anon_group_4",
+			"userId": "user_group_4",
+			"type": "group",
+			"groupId": "umbrella_1",
+			"timestamp": "2019-06-24T15:32:33Z",
+			"traits": {
+				"name": "Umbrella"
+			}
+		}
+		`
+
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("AliasAnonymousID", func(t *testing.T) {
+		payload := `
+		{
+			"anonymousId": "anon_alias_1",
+			"type": "page",
+			"timestamp": "2019-06-24T15:32:33Z",
+			"name": "Home",
+			"properties": {
+				"url": "https://segment.com"
+			}
+		}
+		`
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+		anonymousUser, status := store.GetStore().GetUserBySegmentAnonymousId(project.ID, "anon_alias_1")
+		assert.Equal(t, http.StatusFound, status)
+		assert.Empty(t, anonymousUser.CustomerUserId)
+
+		payload = `
+		{
+			"type": "alias",
+			"previousId": "anon_alias_1",
+			"userId": "user_alias_1",
+			"timestamp": "2019-06-24T15:33:33Z"
+		}
+		`
+		w = ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		anonymousUser, status = store.GetStore().GetUser(project.ID, anonymousUser.ID)
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, "user_alias_1", anonymousUser.CustomerUserId)
+	})
+
+	t.Run("AliasPreviousUserID", func(t *testing.T) {
+		payload := `
+		{
+			"type": "alias",
+			"previousId": "user_alias_1",
+			"userId": "user_alias_2",
+			"timestamp": "2019-06-24T15:34:33Z"
+		}
+		`
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		users, status := store.GetStore().GetUsersByCustomerUserID(project.ID, "user_alias_2")
+		assert.Equal(t, http.StatusFound, status)
+		assert.NotEmpty(t, users)
+	})
+
+	t.Run("AliasWithoutPreviousID", func(t *testing.T) {
+		payload := `
+		{
+			"type": "alias",
+			"userId": "user_alias_2",
+			"timestamp": "2019-06-24T15:34:33Z"
+		}
+		`
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
+			map[string]string{"Authorization": project.PrivateToken})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestIntSegmentHandlerWithPayloadFromSegmentPlatform(t *testing.T) {
 	// Initialize routes and dependent data.
 	r := gin.Default()
@@ -2398,8 +2598,8 @@ func TestIntSegmentHandlerWithPayloadFromSegmentPlatform(t *testing.T) {
 
 		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
 			map[string]string{"Authorization": basicAuthToken})
-		// Event type group is not supported yet.
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		// Group is skipped without segment accounts enabled on the project.
+		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
 	t.Run("PlatformTestTrack:UserEnablesIntegrationPayload", func(t *testing.T) {
@@ -2934,8 +3134,8 @@ func TestIntRudderstackHandlerWithPayloadFromRudderstackPlatform(t *testing.T) {
 
 		w := ServePostRequestWithHeaders(r, uri, []byte(payload),
 			map[string]string{"Authorization": basicAuthToken})
-		// Event type group is not supported yet.
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		// Group is skipped without segment accounts enabled on the project.
+		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
 	t.Run("PlatformTestTrack:UserEnablesIntegrationPayload", func(t *testing.T) {
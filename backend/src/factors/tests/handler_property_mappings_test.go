@@ -361,7 +361,7 @@ func sendGetCommonPropertyMappings(r *gin.Engine, project_id int64, agent *model
 	if err != nil {
 		log.WithError(err).Error("Error Creating cookieData")
 	}
-	url := "/projects/" + strconv.FormatUint(uint64(project_id), 10) + "/v1/kpi/property_mappings/commom_properties"
+	url := "/projects/" + strconv.FormatUint(uint64(project_id), 10) + "/v1/kpi/property_mappings/common_properties"
 	rb := C.NewRequestBuilderWithPrefix(http.MethodPost, url).
 		WithPostParams(payload).
 		WithCookie(&http.Cookie{
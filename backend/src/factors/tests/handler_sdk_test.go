@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -2475,6 +2477,102 @@ func TestSDKBulk(t *testing.T) {
 
 }
 
+func TestSDKBulkWithPartialSuccess(t *testing.T) {
+	r := gin.Default()
+	H.InitSDKServiceRoutes(r)
+
+	project, user, err := SetupProjectUserReturnDAO()
+	assert.Nil(t, err)
+	uri := "/sdk/bulk"
+
+	decodeBulkResponse := func(body io.Reader) SDK.BulkResponse {
+		var resp SDK.BulkResponse
+		jsonResponse, _ := ioutil.ReadAll(body)
+		json.Unmarshal(jsonResponse, &resp)
+		return resp
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		payload := fmt.Sprintf("[%s,%s,%s]",
+			`{"type": "track", "payload": {"event_name": "signup", "event_properties": {"mobile" : "true"}}}`,
+			fmt.Sprintf(`{"type": "identify", "payload": {"user_id": "%s", "c_uid": "bulk_user_1"}}`, user.ID),
+			fmt.Sprintf(`{"type": "add_user_properties", "payload": {"user_id": "%s", "properties": {"plan": "pro"}}}`, user.ID))
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload), map[string]string{"Authorization": project.Token})
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBulkResponse(w.Body)
+		assert.Equal(t, 3, resp.Succeeded)
+		assert.Equal(t, 0, resp.Failed)
+		assert.Len(t, resp.Items, 3)
+	})
+
+	t.Run("PartialSuccessNDJSON", func(t *testing.T) {
+		payload := strings.Join([]string{
+			`{"payload": {"event_name": "test", "c_event_id": "bulk_1"}}`,
+			`{"payload": {"event_name": "test2", "c_event_id": "bulk_1"}}`,
+			`{"payload": {"event_name": ""}}`,
+			`not json`,
+			`{"type": "unknown", "payload": {}}`,
+			`{"payload": {"event_name": "test3"}}`,
+		}, "\n")
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload), map[string]string{"Authorization": project.Token})
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		resp := decodeBulkResponse(w.Body)
+		assert.Equal(t, 2, resp.Succeeded)
+		assert.Equal(t, 3, resp.Failed)
+		assert.Equal(t, 1, resp.Duplicates)
+		assert.Len(t, resp.Items, 6)
+
+		assert.Equal(t, http.StatusOK, resp.Items[0].Status)
+		assert.Equal(t, http.StatusConflict, resp.Items[1].Status)
+		assert.True(t, resp.Items[1].Duplicate)
+		assert.Equal(t, http.StatusBadRequest, resp.Items[2].Status)
+		assert.Equal(t, http.StatusBadRequest, resp.Items[3].Status)
+		assert.Equal(t, http.StatusBadRequest, resp.Items[4].Status)
+		assert.Equal(t, http.StatusOK, resp.Items[5].Status)
+		assert.Equal(t, 5, resp.Items[5].Index)
+	})
+
+	t.Run("DuplicateOfTrackedEvent", func(t *testing.T) {
+		payload := strings.Join([]string{
+			`{"payload": {"event_name": "test", "c_event_id": "bulk_1"}}`,
+			`{"payload": {"event_name": "test", "c_event_id": "bulk_2"}}`,
+		}, "\n")
+		w := ServePostRequestWithHeaders(r, uri, []byte(payload), map[string]string{"Authorization": project.Token})
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		resp := decodeBulkResponse(w.Body)
+		assert.Equal(t, 1, resp.Succeeded)
+		assert.Equal(t, 0, resp.Failed)
+		assert.Equal(t, 1, resp.Duplicates)
+
+		// event tracked by the earlier request is responded on the duplicate.
+		eventID, status := store.GetStore().GetEventIDByCustomerEventID(project.ID, "bulk_1")
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, http.StatusConflict, resp.Items[0].Status)
+		assert.True(t, resp.Items[0].Duplicate)
+		assert.Equal(t, eventID, resp.Items[0].Response.(map[string]interface{})["event_id"])
+		assert.Equal(t, http.StatusOK, resp.Items[1].Status)
+		assert.False(t, resp.Items[1].Duplicate)
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		var body bytes.Buffer
+		gzipWriter := gzip.NewWriter(&body)
+		gzipWriter.Write([]byte(`{"payload": {"event_name": "gzipped"}}` + "\n"))
+		gzipWriter.Close()
+
+		w := ServePostRequestWithHeaders(r, uri, body.Bytes(),
+			map[string]string{"Authorization": project.Token, "Content-Encoding": "gzip"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		resp := decodeBulkResponse(w.Body)
+		assert.Equal(t, 1, resp.Succeeded)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		w := ServePostRequestWithHeaders(r, uri, []byte(`[{"payload": `), map[string]string{"Authorization": project.Token})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func getAutoTrackedEventIdWithPageRawURL(t *testing.T, projectAuthToken, pageRawURL string) (string, string) {
 	r := gin.Default()
 	H.InitSDKServiceRoutes(r)
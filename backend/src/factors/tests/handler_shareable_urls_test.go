@@ -6,11 +6,13 @@ import (
 	H "factors/handler"
 	"factors/handler/helpers"
 	"factors/model/model"
+	"factors/model/store"
 	U "factors/util"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -726,3 +728,233 @@ func TestAPIRevokeShareableURLHandler(t *testing.T) {
 	}
 	assert.Equal(t, 0, len(shares))
 }
+
+func executeSharedQueryReqWithAccessToken(r *gin.Engine, projectId int64, shareString, accessToken string) *httptest.ResponseRecorder {
+	rb := C.NewRequestBuilderWithPrefix(http.MethodPost, fmt.Sprintf("/projects/%d/query?query_id=%s", projectId, shareString)).
+		WithHeader(model.ShareAccessTokenHeader, accessToken)
+
+	req, err := rb.Build()
+	if err != nil {
+		log.WithError(err).Error("Error creating shared query req with access token.")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func sendSharedEntityLoginReq(r *gin.Engine, projectID int64, shareString, action string, params interface{}) *httptest.ResponseRecorder {
+	rb := C.NewRequestBuilderWithPrefix(http.MethodPost, fmt.Sprintf("/projects/%d/shareable_url/%s/%s", projectID, shareString, action)).
+		WithPostParams(params)
+
+	req, err := rb.Build()
+	if err != nil {
+		log.WithError(err).Error("Error creating shared entity login req.")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func sendUpdateShareableUrlReq(r *gin.Engine, projectID int64, agent *model.Agent, shareId string, params *H.UpdateShareableURLParams) *httptest.ResponseRecorder {
+
+	cookieData, err := helpers.GetAuthData(agent.Email, agent.UUID, agent.Salt, 100*time.Second)
+	if err != nil {
+		log.WithError(err).Error("Error creating cookie data.")
+	}
+
+	rb := C.NewRequestBuilderWithPrefix(http.MethodPut, fmt.Sprintf("/projects/%d/shareable_url/%s", projectID, shareId)).
+		WithPostParams(params).
+		WithCookie(&http.Cookie{
+			Name:   C.GetFactorsCookieName(),
+			Value:  cookieData,
+			MaxAge: 1000,
+		})
+
+	req, err := rb.Build()
+	if err != nil {
+		log.WithError(err).Error("Error creating update shareable url req.")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIRestrictedShareableURLHandler(t *testing.T) {
+	r := gin.Default()
+	H.InitAppRoutes(r)
+
+	project, agent, err := SetupProjectWithAgentDAO()
+	assert.Nil(t, err)
+	assert.NotNil(t, project)
+
+	allowedAgent, errCode := SetupAgentReturnDAO("", "")
+	assert.Equal(t, http.StatusCreated, errCode)
+	otherAgent, errCode := SetupAgentReturnDAO("", "")
+	assert.Equal(t, http.StatusCreated, errCode)
+
+	query := model.Query{
+		EventsCondition: model.EventCondAnyGivenEvent,
+		From:            1556602834,
+		To:              1557207634,
+		Type:            model.QueryTypeEventsOccurrence,
+		EventsWithProperties: []model.QueryEventWithProperties{
+			{
+				Name: "event1",
+			},
+		},
+		OverridePeriod: true,
+	}
+	queryJson, err := json.Marshal(query)
+	assert.Nil(t, err)
+
+	w := sendCreateQueryReq(r, project.ID, agent, &H.SavedQueryRequestPayload{
+		Title: U.RandomString(5),
+		Type:  model.QueryTypeSavedQuery,
+		Query: &postgres.Jsonb{RawMessage: json.RawMessage(queryJson)},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	responseMap := DecodeJSONResponseToMap(w.Body)
+	queryIdNum, _ := strconv.Atoi(responseMap["id"].(string))
+	queryId := int64(queryIdNum)
+	shareString := responseMap["id_text"].(string)
+
+	params := &H.ShareableURLParams{
+		EntityID:   queryId,
+		EntityType: model.ShareableURLEntityTypeQuery,
+		ShareType:  model.ShareableURLShareTypePassword,
+		Password:   "short",
+	}
+	w = sendCreateShareableUrlReq(r, project.ID, agent, params)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	params.Password = "agency@password"
+	w = sendCreateShareableUrlReq(r, project.ID, agent, params)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var share model.ShareableURL
+	err = json.NewDecoder(w.Body).Decode(&share)
+	assert.Nil(t, err)
+	assert.Equal(t, "", share.PasswordHash)
+
+	t.Run("PasswordShare", func(t *testing.T) {
+		w := executeSharedQueryReq(r, project.ID, nil, shareString)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, []interface{}{float64(model.ShareableURLShareTypePassword)}, DecodeJSONResponseToMap(w.Body)["share_types"])
+
+		w = sendSharedEntityLoginReq(r, project.ID, shareString, "password", &H.SharedEntityPasswordParams{Password: "wrong@password"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = sendSharedEntityLoginReq(r, project.ID, shareString, "password", &H.SharedEntityPasswordParams{Password: "agency@password"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		accessToken := DecodeJSONResponseToMap(w.Body)["access_token"].(string)
+
+		w = executeSharedQueryReqWithAccessToken(r, project.ID, shareString, accessToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = executeSharedQueryReqWithAccessToken(r, project.ID, shareString, "invalid")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("AllowedUsersShare", func(t *testing.T) {
+		w := sendSharedEntityLoginReq(r, project.ID, shareString, "password", &H.SharedEntityPasswordParams{Password: "agency@password"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		passwordAccessToken := DecodeJSONResponseToMap(w.Body)["access_token"].(string)
+
+		w = sendUpdateShareableUrlReq(r, project.ID, agent, share.ID, &H.UpdateShareableURLParams{
+			ShareType:    model.ShareableURLShareTypeAllowedUsers,
+			AllowedUsers: []string{"invalid_email"},
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = sendUpdateShareableUrlReq(r, project.ID, agent, share.ID, &H.UpdateShareableURLParams{
+			ShareType:    model.ShareableURLShareTypeAllowedUsers,
+			AllowedUsers: []string{" " + strings.ToUpper(allowedAgent.Email), "viewer@agency.com"},
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// Access issued before the update is not valid.
+		w = executeSharedQueryReqWithAccessToken(r, project.ID, shareString, passwordAccessToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = sendSharedEntityLoginReq(r, project.ID, shareString, "password", &H.SharedEntityPasswordParams{Password: "agency@password"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// Logged in agent on the allowed users does not need the login link.
+		w = executeSharedQueryReq(r, project.ID, allowedAgent, shareString)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = executeSharedQueryReq(r, project.ID, otherAgent, shareString)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = sendSharedEntityLoginReq(r, project.ID, shareString, "login_link", &H.SharedEntityLoginLinkParams{Email: otherAgent.Email})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		shares, errCode := store.GetStore().GetActiveShareableURLsWithShareString(project.ID, shareString, model.ShareableURLEntityTypeQuery)
+		assert.Equal(t, http.StatusFound, errCode)
+		loginToken, err := model.CreateShareLoginToken(shares[0], "viewer@agency.com")
+		assert.Nil(t, err)
+
+		w = sendSharedEntityLoginReq(r, project.ID, shareString, "verify", &H.SharedEntityVerifyParams{Token: loginToken})
+		assert.Equal(t, http.StatusOK, w.Code)
+		accessToken := DecodeJSONResponseToMap(w.Body)["access_token"].(string)
+
+		// Login link can be used only once.
+		w = sendSharedEntityLoginReq(r, project.ID, shareString, "verify", &H.SharedEntityVerifyParams{Token: loginToken})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = executeSharedQueryReqWithAccessToken(r, project.ID, shareString, accessToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var audits []model.ShareableURLAudit
+		err = C.GetServices().Db.Where("project_id = ? AND share_id = ?", project.ID, share.ID).Find(&audits).Error
+		assert.Nil(t, err)
+		viewerEmails := make(map[string]bool)
+		for _, audit := range audits {
+			viewerEmails[audit.ViewerEmail] = true
+		}
+		assert.True(t, viewerEmails["viewer@agency.com"])
+		assert.True(t, viewerEmails[allowedAgent.Email])
+	})
+
+	t.Run("AllProjectUsersShare", func(t *testing.T) {
+		w := sendUpdateShareableUrlReq(r, project.ID, agent, share.ID, &H.UpdateShareableURLParams{
+			ShareType: model.ShareableURLShareTypeAllProjectUsers,
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = executeSharedQueryReq(r, project.ID, allowedAgent, shareString)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = executeSharedQueryReq(r, project.ID, agent, shareString)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("LoginRateLimitedByClient", func(t *testing.T) {
+		sendPasswordReq := func(clientIP string) *httptest.ResponseRecorder {
+			rb := C.NewRequestBuilderWithPrefix(http.MethodPost, fmt.Sprintf("/projects/%d/shareable_url/%s/password", project.ID, shareString)).
+				WithHeader("X-Forwarded-For", clientIP).
+				WithPostParams(&H.SharedEntityPasswordParams{Password: "wrong@password"})
+			req, err := rb.Build()
+			assert.Nil(t, err)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		var w *httptest.ResponseRecorder
+		for i := 0; i < 12; i++ {
+			w = sendPasswordReq("10.0.0.1")
+		}
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		// attempts of a client don't lock the other viewers of the share out.
+		w = sendPasswordReq("10.0.0.2")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// attempts from a new client ip on every request are capped by the share.
+		for i := 0; i < 110; i++ {
+			w = sendPasswordReq(fmt.Sprintf("10.0.%d.%d", i/250+1, i%250+1))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+}
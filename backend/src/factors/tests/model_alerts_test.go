@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"factors/model/model"
 	"factors/model/store"
+	T "factors/task"
+	U "factors/util"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jinzhu/gorm/dialects/postgres"
 	log "github.com/sirupsen/logrus"
@@ -39,3 +42,108 @@ func TestGetAlertNamesByProjectIdTypeAndName(t *testing.T) {
 	assert.Equal(t, statusCode, http.StatusFound)
 	assert.Len(t, alertNames, 1)
 }
+
+func TestComputeAlertBaseline(t *testing.T) {
+	t.Run("StdDev", func(t *testing.T) {
+		history := []float64{90, 110, 100, 95, 105, 100, 100, 100}
+		baseline, err := model.ComputeAlertBaseline(model.IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV, history, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, float64(100), baseline.Expected)
+		assert.InDelta(t, 5.98, baseline.Spread, 0.01)
+		assert.InDelta(t, 88.04, baseline.LowerBound, 0.01)
+		assert.InDelta(t, 111.96, baseline.UpperBound, 0.01)
+		assert.False(t, baseline.IsOutsideExpectedRange(105))
+		assert.True(t, baseline.IsOutsideExpectedRange(80))
+		assert.InDelta(t, -3.34, baseline.GetDeviationScore(80), 0.01)
+	})
+
+	t.Run("MADIgnoresOutliersInHistory", func(t *testing.T) {
+		history := []float64{100, 102, 98, 101, 99, 1000}
+		baseline, err := model.ComputeAlertBaseline(model.IS_OUTSIDE_EXPECTED_RANGE_BY_MAD, history, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, float64(100.5), baseline.Expected)
+		assert.Equal(t, float64(model.ANOMALY_DEFAULT_DEVIATIONS), baseline.Deviations)
+		assert.True(t, baseline.IsOutsideExpectedRange(130))
+		assert.False(t, baseline.IsOutsideExpectedRange(103))
+
+		// std dev baseline is widened by the outlier and misses the anomaly.
+		stdDevBaseline, err := model.ComputeAlertBaseline(model.IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV, history, 0)
+		assert.Nil(t, err)
+		assert.False(t, stdDevBaseline.IsOutsideExpectedRange(130))
+	})
+
+	t.Run("HistoryWithoutVariation", func(t *testing.T) {
+		baseline, err := model.ComputeAlertBaseline(model.IS_OUTSIDE_EXPECTED_RANGE_BY_MAD, []float64{50, 50, 50, 50}, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, 0.5, baseline.Spread)
+		assert.False(t, baseline.IsOutsideExpectedRange(51))
+		assert.True(t, baseline.IsOutsideExpectedRange(52))
+	})
+
+	t.Run("NotEnoughHistory", func(t *testing.T) {
+		_, err := model.ComputeAlertBaseline(model.IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV, []float64{1, 2}, 3)
+		assert.NotNil(t, err)
+		_, err = model.ComputeAlertBaseline(model.IS_LESS_THAN, []float64{1, 2, 3, 4}, 3)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetAnomalyBaselineDateRanges(t *testing.T) {
+	timezone := U.TimeZoneString("America/Los_Angeles")
+	location, err := time.LoadLocation(string(timezone))
+	assert.Nil(t, err)
+	getDateRange := func(from, to time.Time) U.TimestampRange {
+		return U.TimestampRange{Start: from.Unix(), End: to.Unix()}
+	}
+
+	// week after the daylight saving change is shifted to the weeks before it, at the local midnight.
+	baselineDateRanges := T.GetAnomalyBaselineDateRanges(time.Date(2023, 3, 13, 0, 0, 0, 0, location).Unix(),
+		time.Date(2023, 3, 19, 23, 59, 59, 0, location).Unix(), timezone, 2)
+	assert.Equal(t, []U.TimestampRange{
+		getDateRange(time.Date(2023, 3, 6, 0, 0, 0, 0, location), time.Date(2023, 3, 12, 23, 59, 59, 0, location)),
+		getDateRange(time.Date(2023, 2, 27, 0, 0, 0, 0, location), time.Date(2023, 3, 5, 23, 59, 59, 0, location)),
+	}, baselineDateRanges)
+
+	// month of 31 days is shifted by 5 weeks, to keep the same days of the week.
+	baselineDateRanges = T.GetAnomalyBaselineDateRanges(time.Date(2023, 1, 1, 0, 0, 0, 0, location).Unix(),
+		time.Date(2023, 1, 31, 23, 59, 59, 0, location).Unix(), timezone, 2)
+	assert.Equal(t, []U.TimestampRange{
+		getDateRange(time.Date(2022, 11, 27, 0, 0, 0, 0, location), time.Date(2022, 12, 27, 23, 59, 59, 0, location)),
+		getDateRange(time.Date(2022, 10, 23, 0, 0, 0, 0, location), time.Date(2022, 11, 22, 23, 59, 59, 0, location)),
+	}, baselineDateRanges)
+	for _, baselineDateRange := range baselineDateRanges {
+		assert.Equal(t, time.Sunday, U.ConvertTimeIn(time.Unix(baselineDateRange.Start, 0), timezone).Weekday())
+	}
+
+	// month of 28 days is shifted by 4 weeks.
+	baselineDateRanges = T.GetAnomalyBaselineDateRanges(time.Date(2023, 2, 1, 0, 0, 0, 0, location).Unix(),
+		time.Date(2023, 2, 28, 23, 59, 59, 0, location).Unix(), timezone, model.ANOMALY_BASELINE_PERIODS)
+	assert.Len(t, baselineDateRanges, model.ANOMALY_BASELINE_PERIODS)
+	assert.Equal(t, getDateRange(time.Date(2023, 1, 4, 0, 0, 0, 0, location), time.Date(2023, 1, 31, 23, 59, 59, 0, location)),
+		baselineDateRanges[0])
+}
+
+func TestGetAnomalyStatements(t *testing.T) {
+	msg := T.Message{
+		Operator:       model.IS_OUTSIDE_EXPECTED_RANGE_BY_STD_DEV,
+		ActualValue:    2500,
+		Baseline:       model.AlertBaseline{Expected: 1000, LowerBound: 400.5, UpperBound: 1600},
+		DeviationScore: 7.54,
+	}
+	operatorStatement, rangeStatement := T.GetAnomalyStatements(msg)
+	assert.Equal(t, "is above the expected range", operatorStatement)
+	assert.Equal(t, " (expected 400.5 to 1,600, deviation score 7.5) ", rangeStatement)
+
+	msg.ActualValue = 100
+	msg.Baseline.LowerBound = -200
+	msg.DeviationScore = -3
+	operatorStatement, rangeStatement = T.GetAnomalyStatements(msg)
+	assert.Equal(t, "is below the expected range", operatorStatement)
+	assert.Equal(t, " (expected -200 to 1,600, deviation score -3.0) ", rangeStatement)
+
+	// date type kpis are in duration, with the negative bound as zero.
+	msg.CategoryType = model.MetricsDateType
+	msg.Baseline = model.AlertBaseline{Expected: 3600, LowerBound: -60, UpperBound: 7260}
+	_, rangeStatement = T.GetAnomalyStatements(msg)
+	assert.Equal(t, " (expected 0 s to 2 h 1 m, deviation score -3.0) ", rangeStatement)
+}
@@ -3781,7 +3781,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"linear_touch",
 			args{model.AttributionMethodLinear,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3796,7 +3796,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"first_touch",
 			args{model.AttributionMethodFirstTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3811,7 +3811,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"last_touch",
 			args{model.AttributionMethodLastTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3826,7 +3826,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"u_shaped",
 			args{model.AttributionMethodUShaped,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3841,7 +3841,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"u_shaped",
 			args{model.AttributionMethodUShaped,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession2,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3856,7 +3856,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"time_decay",
 			args{model.AttributionMethodTimeDecay,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3871,7 +3871,7 @@ func TestAttributionMethodologies(t *testing.T) {
 		{"time_decay",
 			args{model.AttributionMethodTimeDecay,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession2,
 				coalUserIdConversionTimestamp,
 				lookbackDays,
@@ -3886,7 +3886,7 @@ func TestAttributionMethodologies(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, got1, err := model.ApplyAttribution(tt.args.queryType, tt.args.method, tt.args.conversionEvent,
-				tt.args.usersToBeAttributed, tt.args.userInitialSession, tt.args.coalUserIdConversionTimestamp,
+				tt.args.usersToBeAttributed, tt.args.userInitialSession, nil, tt.args.coalUserIdConversionTimestamp,
 				tt.args.lookbackDays, int64(queryFrom), int64(queryTo), tt.args.attributionKey, log.Entry{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("applyAttribution() error = %v, wantErr %v", err, tt.wantErr)
@@ -3905,6 +3905,80 @@ func TestAttributionMethodologies(t *testing.T) {
 	}
 }
 
+func TestAttributionMethodologyDataDriven(t *testing.T) {
+
+	conversionEvent := "$Form_Submitted"
+	user1 := "user1"
+	user2 := "user2"
+	camp1 := "adwords:-:campaign1"
+	camp2 := "adwords:-:campaign2"
+
+	queryFrom := 0
+	queryTo := 10000000
+	lookbackDays := 20
+	coalUserIdConversionTimestamp := map[string]int64{user1: 1000, user2: 1000}
+
+	// user1: camp1 -> camp2 -> conversion, user2: camp2 -> conversion.
+	// Removing camp2 drops all conversions and removing camp1 drops half of them.
+	userSession := make(map[string]map[string]model.UserSessionData)
+	userSession[user1] = map[string]model.UserSessionData{
+		camp1: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}},
+		camp2: {MinTimestamp: 200, MaxTimestamp: 300, TimeStamps: []int64{200, 300}},
+	}
+	userSession[user2] = map[string]model.UserSessionData{
+		camp2: {MinTimestamp: 500, MaxTimestamp: 500, TimeStamps: []int64{500}},
+	}
+
+	usersToBeAttributed := []model.UserEventInfo{
+		{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent},
+		{CoalUserID: user2, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user2], EventType: model.EventTypeGoalEvent},
+	}
+	got, _, err := model.ApplyAttribution(model.AttributionQueryTypeConversionBased, model.AttributionMethodDataDriven,
+		conversionEvent, usersToBeAttributed, userSession, nil, coalUserIdConversionTimestamp,
+		lookbackDays, int64(queryFrom), int64(queryTo), model.AttributionKeyCampaign, log.Entry{})
+	assert.Nil(t, err)
+	assert.True(t, isEqualGotAndWantAttribution(got[user1],
+		[]model.AttributionKeyWeight{{Key: camp1, Weight: 1.0 / 3.0}, {Key: camp2, Weight: 2.0 / 3.0}}), got[user1])
+	assert.True(t, isEqualGotAndWantAttribution(got[user2],
+		[]model.AttributionKeyWeight{{Key: camp2, Weight: 1}}), got[user2])
+
+	// user1: camp1 -> camp2 -> conversion, user2: camp2 -> camp1 -> conversion, equal credit
+	// without the non converting journeys.
+	userSession[user2] = map[string]model.UserSessionData{
+		camp2: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}},
+		camp1: {MinTimestamp: 200, MaxTimestamp: 200, TimeStamps: []int64{200}},
+	}
+	got, _, err = model.ApplyAttribution(model.AttributionQueryTypeConversionBased, model.AttributionMethodDataDriven,
+		conversionEvent, usersToBeAttributed, userSession, nil, coalUserIdConversionTimestamp,
+		lookbackDays, int64(queryFrom), int64(queryTo), model.AttributionKeyCampaign, log.Entry{})
+	assert.Nil(t, err)
+	assert.True(t, isEqualGotAndWantAttribution(got[user1],
+		[]model.AttributionKeyWeight{{Key: camp1, Weight: 0.5}, {Key: camp2, Weight: 0.5}}), got[user1])
+
+	// many of the non converting journeys drop after camp2, camp2 gets less credit.
+	nonConvertedSessions := make(map[string]map[string]model.UserSessionData)
+	for i := 0; i < 10; i++ {
+		nonConvertedSessions[fmt.Sprintf("non_converted_user%d", i)] = map[string]model.UserSessionData{
+			camp1: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}},
+			camp2: {MinTimestamp: 200, MaxTimestamp: 200, TimeStamps: []int64{200}},
+		}
+	}
+	nonConvertedPaths := model.GetNonConvertedTouchPointPaths(model.AttributionQueryTypeConversionBased,
+		nonConvertedSessions, userSession, lookbackDays, int64(queryFrom), coalUserIdConversionTimestamp[user1])
+	assert.Len(t, nonConvertedPaths, 10)
+	got, _, err = model.ApplyAttribution(model.AttributionQueryTypeConversionBased, model.AttributionMethodDataDriven,
+		conversionEvent, usersToBeAttributed, userSession, nonConvertedPaths, coalUserIdConversionTimestamp,
+		lookbackDays, int64(queryFrom), int64(queryTo), model.AttributionKeyCampaign, log.Entry{})
+	assert.Nil(t, err)
+	weights := make(map[string]float64)
+	for _, keyWeight := range got[user1] {
+		weights[keyWeight.Key] = keyWeight.Weight
+	}
+	assert.Less(t, weights[camp2], 0.5)
+	assert.Greater(t, weights[camp1], 0.5)
+	assert.InDelta(t, 1, weights[camp1]+weights[camp2], 0.0001)
+}
+
 func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 
 	conversionEvent := "$Form_Submitted"
@@ -3946,7 +4020,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 		{"linear_touch",
 			args{model.AttributionMethodLinear,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -3961,7 +4035,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 		{"first_touch",
 			args{model.AttributionMethodFirstTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -3975,7 +4049,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 		{"last_touch",
 			args{model.AttributionMethodLastTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -3989,7 +4063,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 		{"first_touch_nd",
 			args{model.AttributionMethodFirstTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4003,7 +4077,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4017,7 +4091,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastCampaignTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4030,7 +4104,7 @@ func TestAttributionMethodologiesFirstTouchNonDirect(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, got1, err := model.ApplyAttribution(tt.args.queryType, tt.args.method, tt.args.conversionEvent,
-				tt.args.usersToBeAttributed, tt.args.userInitialSession,
+				tt.args.usersToBeAttributed, tt.args.userInitialSession, nil,
 				tt.args.coalUserIdConversionTimestamp, tt.args.lookbackDays,
 				int64(queryFrom), int64(queryTo), tt.args.attributionKey, log.Entry{})
 			if (err != nil) != tt.wantErr {
@@ -4093,7 +4167,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 		{"linear_touch",
 			args{model.AttributionMethodLinear,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4107,7 +4181,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 		{"first_touch",
 			args{model.AttributionMethodFirstTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4121,7 +4195,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 		{"last_touch",
 			args{model.AttributionMethodLastTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4135,7 +4209,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 		{"first_touch_nd",
 			args{model.AttributionMethodFirstTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4149,7 +4223,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4162,7 +4236,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastCampaignTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4175,7 +4249,7 @@ func TestAttributionMethodologiesLastTouchNonDirect(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, got1, err := model.ApplyAttribution(tt.args.queryType, tt.args.method, tt.args.conversionEvent,
-				tt.args.usersToBeAttributed, tt.args.userInitialSession,
+				tt.args.usersToBeAttributed, tt.args.userInitialSession, nil,
 				tt.args.coalUserIdConversionTimestamp, tt.args.lookbackDays, int64(queryFrom), int64(queryTo), tt.args.attributionKey, log.Entry{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("applyAttribution() error = %v, wantErr %v", err, tt.wantErr)
@@ -4236,7 +4310,7 @@ func TestAttributionMethodologiesNonDirectAdgroup(t *testing.T) {
 		{"first_touch_nd",
 			args{model.AttributionMethodFirstTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4250,7 +4324,7 @@ func TestAttributionMethodologiesNonDirectAdgroup(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4263,7 +4337,7 @@ func TestAttributionMethodologiesNonDirectAdgroup(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastCampaignTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4276,7 +4350,7 @@ func TestAttributionMethodologiesNonDirectAdgroup(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, got1, err := model.ApplyAttribution(tt.args.queryType, tt.args.method, tt.args.conversionEvent,
-				tt.args.usersToBeAttributed, tt.args.userInitialSession, tt.args.coalUserIdConversionTimestamp, tt.args.lookbackDays,
+				tt.args.usersToBeAttributed, tt.args.userInitialSession, nil, tt.args.coalUserIdConversionTimestamp, tt.args.lookbackDays,
 				int64(queryFrom), int64(queryTo), tt.args.attributionKey, log.Entry{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("applyAttribution() error = %v, wantErr %v", err, tt.wantErr)
@@ -4331,7 +4405,7 @@ func TestAttributionMethodologiesNonDirectKeyword(t *testing.T) {
 		{"first_touch_nd",
 			args{model.AttributionMethodFirstTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4345,7 +4419,7 @@ func TestAttributionMethodologiesNonDirectKeyword(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastTouchNonDirect,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4358,7 +4432,7 @@ func TestAttributionMethodologiesNonDirectKeyword(t *testing.T) {
 		{"last_touch_nd",
 			args{model.AttributionMethodLastCampaignTouch,
 				conversionEvent,
-				[]model.UserEventInfo{{user1, conversionEvent, coalUserIdConversionTimestamp[user1], model.EventTypeGoalEvent}},
+				[]model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: coalUserIdConversionTimestamp[user1], EventType: model.EventTypeGoalEvent}},
 				userSession,
 				coalUserIdConversionTimestamp, lookbackDays,
 				model.AttributionQueryTypeConversionBased,
@@ -4371,7 +4445,7 @@ func TestAttributionMethodologiesNonDirectKeyword(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, got1, err := model.ApplyAttribution(tt.args.queryType, tt.args.method, tt.args.conversionEvent,
-				tt.args.usersToBeAttributed, tt.args.userInitialSession, tt.args.coalUserIdConversionTimestamp, tt.args.lookbackDays,
+				tt.args.usersToBeAttributed, tt.args.userInitialSession, nil, tt.args.coalUserIdConversionTimestamp, tt.args.lookbackDays,
 				int64(queryFrom), int64(queryTo), tt.args.attributionKey, log.Entry{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("applyAttribution() error = %v, wantErr %v", err, tt.wantErr)
@@ -4920,3 +4994,509 @@ func TestKpiAttributionWithMultipleRows(t *testing.T) {
 	})
 
 }
+
+func TestAccountLevelAttributionWithBuyingCommitteeWeights(t *testing.T) {
+
+	conversionEvent := "$Form_Submitted"
+	account := "account1"
+	user1, user2, user3 := "user1", "user2", "user3"
+	camp1 := "adwords:-:campaign1"
+	camp2 := "adwords:-:campaign2"
+
+	accountUsers := map[string]model.AttributionAccountUser{
+		"u1": {CoalUserID: user1, AccountID: account, Role: "VP Marketing"},
+		"u2": {CoalUserID: user2, AccountID: account, Role: "Analyst"},
+	}
+	coalUserIDToAccount, coalUserIDToRole := model.GetCoalUserIDToAccountMaps(accountUsers)
+
+	sessions := make(map[string]map[string]model.UserSessionData)
+	sessions[user1] = map[string]model.UserSessionData{camp1: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}}}
+	sessions[user2] = map[string]model.UserSessionData{camp2: {MinTimestamp: 200, MaxTimestamp: 300, TimeStamps: []int64{200, 300}},
+		camp1: {MinTimestamp: 150, MaxTimestamp: 150, TimeStamps: []int64{150}}}
+	// user3 is not part of any account.
+	sessions[user3] = map[string]model.UserSessionData{camp2: {MinTimestamp: 200, MaxTimestamp: 200, TimeStamps: []int64{200}}}
+
+	roleWeights := map[string]float64{"vp marketing": 3}
+	accountSessions, accountKeyRoleWeights := model.RollUpSessionsToAccounts(sessions, coalUserIDToAccount,
+		coalUserIDToRole, roleWeights)
+	assert.Len(t, accountSessions, 2)
+	assert.Equal(t, int64(100), accountSessions[account][camp1].MinTimestamp)
+	assert.Equal(t, int64(150), accountSessions[account][camp1].MaxTimestamp)
+	assert.Len(t, accountSessions[account][camp1].TimeStamps, 2)
+	assert.Equal(t, float64(3), accountKeyRoleWeights[account][camp1])
+	assert.Equal(t, float64(1), accountKeyRoleWeights[account][camp2])
+	assert.Contains(t, accountSessions, user3)
+
+	// earliest conversion of the account is used.
+	usersToBeAttributed := []model.UserEventInfo{
+		{CoalUserID: user1, EventName: conversionEvent, Timestamp: 2000, EventType: model.EventTypeGoalEvent},
+		{CoalUserID: user2, EventName: conversionEvent, Timestamp: 1000, EventType: model.EventTypeGoalEvent},
+	}
+	coalUserIdConversionTimestamp := map[string]int64{user1: 2000, user2: 1000}
+	accountsToBeAttributed, accountConversionTimestamp := model.RollUpConversionsToAccounts(usersToBeAttributed,
+		coalUserIdConversionTimestamp, coalUserIDToAccount)
+	assert.Len(t, accountsToBeAttributed, 1)
+	assert.Equal(t, account, accountsToBeAttributed[0].CoalUserID)
+	assert.Equal(t, int64(1000), accountsToBeAttributed[0].Timestamp)
+	assert.Equal(t, int64(1000), accountConversionTimestamp[account])
+
+	methods := []string{model.AttributionMethodLinear, model.AttributionMethodFirstTouch, model.AttributionMethodLastTouch,
+		model.AttributionMethodUShaped, model.AttributionMethodTimeDecay, model.AttributionMethodWShaped,
+		model.AttributionMethodInfluence, model.AttributionMethodDataDriven}
+	for _, method := range methods {
+		accountsAttribution, _, err := model.ApplyAttribution(model.AttributionQueryTypeConversionBased, method,
+			conversionEvent, accountsToBeAttributed, accountSessions, nil, accountConversionTimestamp, 20, 0, 10000,
+			model.AttributionKeyCampaign, *log.WithField("method", method))
+		assert.Nil(t, err, method)
+		assert.Contains(t, accountsAttribution, account, method)
+
+		var totalBefore float64
+		for _, keyWeight := range accountsAttribution[account] {
+			totalBefore += keyWeight.Weight
+		}
+		model.ApplyBuyingCommitteeWeights(accountsAttribution, accountKeyRoleWeights)
+		var totalAfter float64
+		for _, keyWeight := range accountsAttribution[account] {
+			totalAfter += keyWeight.Weight
+		}
+		assert.InDelta(t, totalBefore, totalAfter, 0.0001, method)
+	}
+
+	// linear gives equal credit to 4 touches, camp1 touched by the VP weighs 3 times.
+	accountsAttribution, _, err := model.ApplyAttribution(model.AttributionQueryTypeConversionBased,
+		model.AttributionMethodLinear, conversionEvent, accountsToBeAttributed, accountSessions,
+		nil, accountConversionTimestamp, 20, 0, 10000, model.AttributionKeyCampaign, *log.WithField("method", "linear"))
+	assert.Nil(t, err)
+	model.ApplyBuyingCommitteeWeights(accountsAttribution, accountKeyRoleWeights)
+	keyCredit := make(map[string]float64)
+	for _, keyWeight := range accountsAttribution[account] {
+		keyCredit[keyWeight.Key] += keyWeight.Weight
+	}
+	assert.InDelta(t, 0.75, keyCredit[camp1], 0.0001)
+	assert.InDelta(t, 0.25, keyCredit[camp2], 0.0001)
+}
+
+func TestAccountLevelAttributionV1WithBuyingCommitteeWeights(t *testing.T) {
+
+	account := "account1"
+	user1, user2, user3 := "user1", "user2", "user3"
+	camp1 := "adwords:-:campaign1"
+	camp2 := "adwords:-:campaign2"
+
+	accountUsers := map[string]model.AttributionAccountUser{
+		"u1": {CoalUserID: user1, AccountID: account, Role: "VP Marketing"},
+		"u2": {CoalUserID: user2, AccountID: account, Role: "Analyst"},
+	}
+
+	// deal1 is closed by user1, the touch points of user2 of the same account are rolled up.
+	kpiData := map[string]model.KPIInfo{
+		"deal1": {KpiID: "deal1", KpiUserIds: []string{"u1"}, KpiCoalUserIds: []string{user1}},
+		"deal2": {KpiID: "deal2", KpiUserIds: []string{"u3"}, KpiCoalUserIds: []string{user3}},
+	}
+	model.RollUpKPIUsersToAccounts(kpiData, accountUsers)
+	assert.ElementsMatch(t, []string{"u1", "u2"}, kpiData["deal1"].KpiUserIds)
+	assert.ElementsMatch(t, []string{user1, user2}, kpiData["deal1"].KpiCoalUserIds)
+	assert.Equal(t, []string{user3}, kpiData["deal2"].KpiCoalUserIds)
+
+	sessions := make(map[string]map[string]model.UserSessionData)
+	sessions[user1] = map[string]model.UserSessionData{camp1: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}}}
+	sessions[user2] = map[string]model.UserSessionData{camp2: {MinTimestamp: 200, MaxTimestamp: 300, TimeStamps: []int64{200, 300}},
+		camp1: {MinTimestamp: 150, MaxTimestamp: 150, TimeStamps: []int64{150}}}
+	sessions[user3] = map[string]model.UserSessionData{camp2: {MinTimestamp: 200, MaxTimestamp: 200, TimeStamps: []int64{200}}}
+
+	kpiKeyRoleWeights := model.GetKPIKeyRoleWeights(kpiData, sessions, accountUsers, map[string]float64{"vp marketing": 3})
+	assert.Equal(t, float64(3), kpiKeyRoleWeights["deal1"][camp1])
+	assert.Equal(t, float64(1), kpiKeyRoleWeights["deal1"][camp2])
+	assert.Equal(t, float64(1), kpiKeyRoleWeights["deal2"][camp2])
+
+	kpiAttribution := map[string][]model.AttributionKeyWeight{
+		"deal1": {{Key: camp1, Weight: 0.5}, {Key: camp2, Weight: 0.5}},
+	}
+	model.ApplyBuyingCommitteeWeights(kpiAttribution, kpiKeyRoleWeights)
+	for _, keyWeight := range kpiAttribution["deal1"] {
+		if keyWeight.Key == camp1 {
+			assert.InDelta(t, 0.75, keyWeight.Weight, 0.0001)
+		} else {
+			assert.InDelta(t, 0.25, keyWeight.Weight, 0.0001)
+		}
+	}
+}
+
+func TestAttributionMethodologyComparisonMeta(t *testing.T) {
+
+	conversionEvent := "$Form_Submitted"
+	user1, user2 := "user1", "user2"
+	linkedin := "linkedin:-:campaign1"
+	adwords := "adwords:-:campaign2"
+
+	sessions := make(map[string]map[string]model.UserSessionData)
+	sessions[user1] = map[string]model.UserSessionData{
+		linkedin: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}},
+		adwords:  {MinTimestamp: 200, MaxTimestamp: 200, TimeStamps: []int64{200}},
+	}
+	sessions[user2] = map[string]model.UserSessionData{
+		adwords: {MinTimestamp: 150, MaxTimestamp: 150, TimeStamps: []int64{150}},
+	}
+	coalUserIdConversionTimestamp := map[string]int64{user1: 1000, user2: 1000}
+	usersToBeAttributed := []model.UserEventInfo{
+		{CoalUserID: user1, EventName: conversionEvent, Timestamp: 1000, EventType: model.EventTypeGoalEvent},
+		{CoalUserID: user2, EventName: conversionEvent, Timestamp: 1000, EventType: model.EventTypeGoalEvent},
+	}
+	query := &model.AttributionQuery{
+		ConversionEvent:               model.QueryEventWithProperties{Name: conversionEvent},
+		AttributionKey:                model.AttributionKeyCampaign,
+		AttributionMethodology:        model.AttributionMethodLastTouch,
+		AttributionMethodologyCompare: model.AttributionMethodFirstTouch,
+		LookbackDays:                  20,
+		From:                          0,
+		To:                            10000,
+		QueryType:                     model.AttributionQueryTypeConversionBased,
+	}
+
+	// nothing kept by the attribution run.
+	assert.Nil(t, model.GetMethodologyComparisonMeta(query, nil))
+
+	userConversionHit, _, err := model.ApplyAttribution(query.QueryType, query.AttributionMethodology,
+		conversionEvent, usersToBeAttributed, sessions, nil, coalUserIdConversionTimestamp, query.LookbackDays,
+		query.From, query.To, query.AttributionKey, *log.WithField("test", "comparison"))
+	assert.Nil(t, err)
+	userConversionCompareHit, _, err := model.ApplyAttribution(query.QueryType, query.AttributionMethodologyCompare,
+		conversionEvent, usersToBeAttributed, sessions, nil, coalUserIdConversionTimestamp, query.LookbackDays,
+		query.From, query.To, query.AttributionKey, *log.WithField("test", "comparison"))
+	assert.Nil(t, err)
+	query.MethodologyComparisonHits = &model.MethodologyComparisonHits{
+		UserConversionHit:        userConversionHit,
+		UserConversionCompareHit: userConversionCompareHit,
+		Sessions:                 sessions,
+		ConversionTimestamp:      coalUserIdConversionTimestamp,
+	}
+
+	meta := model.GetMethodologyComparisonMeta(query, nil)
+	assert.Len(t, meta, 2)
+
+	keysMeta := meta[0]
+	assert.Equal(t, model.MetaMethodologyComparisonKeys, keysMeta.Title)
+	assert.Equal(t, []string{model.AttributionKeyCampaign, model.AttributionMethodLastTouch,
+		model.AttributionMethodFirstTouch, "Delta", "Delta(%)"}, keysMeta.Headers)
+	assert.Len(t, keysMeta.Rows, 2)
+	// last touch gives linkedin nothing, first touch gives it user1's conversion.
+	for _, row := range keysMeta.Rows {
+		if row[0] == linkedin {
+			assert.Equal(t, float64(0), row[1])
+			assert.Equal(t, float64(1), row[2])
+			assert.Equal(t, float64(1), row[3])
+		} else {
+			assert.Equal(t, adwords, row[0])
+			assert.Equal(t, float64(2), row[1])
+			assert.Equal(t, float64(1), row[2])
+			assert.Equal(t, float64(-1), row[3])
+			assert.Equal(t, float64(-50), row[4])
+		}
+	}
+
+	pathsMeta := meta[1]
+	assert.Equal(t, model.MetaMethodologyComparisonPaths, pathsMeta.Title)
+	assert.Len(t, pathsMeta.Rows, 2)
+	// path which moved the credit comes first.
+	assert.Equal(t, linkedin+model.MethodologyComparisonPathSeparator+adwords, pathsMeta.Rows[0][0])
+	assert.Equal(t, int64(1), pathsMeta.Rows[0][1])
+	assert.Equal(t, float64(1), pathsMeta.Rows[0][2])
+	assert.Equal(t, linkedin, pathsMeta.Rows[0][3])
+	assert.Equal(t, adwords, pathsMeta.Rows[0][4])
+	assert.Equal(t, adwords, pathsMeta.Rows[1][0])
+	assert.Equal(t, float64(0), pathsMeta.Rows[1][2])
+}
+
+func TestAttributionMethodologyComparisonMetaV1(t *testing.T) {
+
+	linkedin := "linkedin:-:campaign1"
+	adwords := "adwords:-:campaign2"
+
+	// sessions of the users of each KPI, as grouped by the attribution run.
+	sessions := make(map[string]map[string]model.UserSessionData)
+	sessions["deal1"] = map[string]model.UserSessionData{
+		linkedin: {MinTimestamp: 100, MaxTimestamp: 100, TimeStamps: []int64{100}},
+		adwords:  {MinTimestamp: 200, MaxTimestamp: 200, TimeStamps: []int64{200}},
+	}
+	kpiData := map[string]model.KPIInfo{
+		"deal1": {KpiID: "deal1", KpiCoalUserIds: []string{"user1"},
+			KpiValuesList: []model.KpiRowValue{{Timestamp: 1000, Values: []float64{10}}}},
+	}
+	query := &model.AttributionQueryV1{
+		AttributionKey:                model.AttributionKeyCampaign,
+		AttributionMethodology:        model.AttributionMethodLastTouch,
+		AttributionMethodologyCompare: model.AttributionMethodFirstTouch,
+		MethodologyComparisonDetails:  true,
+		LookbackDays:                  20,
+		From:                          0,
+		To:                            10000,
+		QueryType:                     model.AttributionQueryTypeConversionBased,
+	}
+
+	userConversionHit, err := model.ApplyAttributionKPI(query.QueryType, query.AttributionMethodology, sessions,
+		nil, kpiData, query.LookbackDays, query.From, query.To, query.AttributionKey)
+	assert.Nil(t, err)
+	userConversionCompareHit, err := model.ApplyAttributionKPI(query.QueryType, query.AttributionMethodologyCompare,
+		sessions, nil, kpiData, query.LookbackDays, query.From, query.To, query.AttributionKey)
+	assert.Nil(t, err)
+	conversionTimestamps := model.GetKPIConversionTimestamps(kpiData)
+	assert.Equal(t, int64(1000), conversionTimestamps["deal1"])
+	query.MethodologyComparisonHits = &model.MethodologyComparisonHits{
+		UserConversionHit:        userConversionHit,
+		UserConversionCompareHit: userConversionCompareHit,
+		Sessions:                 sessions,
+		ConversionTimestamp:      conversionTimestamps,
+	}
+
+	meta := model.GetMethodologyComparisonMetaV1(query, nil)
+	assert.Len(t, meta, 2)
+	assert.Len(t, meta[0].Rows, 2)
+	pathsMeta := meta[1]
+	assert.Len(t, pathsMeta.Rows, 1)
+	assert.Equal(t, linkedin+model.MethodologyComparisonPathSeparator+adwords, pathsMeta.Rows[0][0])
+	assert.Equal(t, float64(1), pathsMeta.Rows[0][2])
+	assert.Equal(t, linkedin, pathsMeta.Rows[0][3])
+	assert.Equal(t, adwords, pathsMeta.Rows[0][4])
+}
+
+func TestAttributionDayPartials(t *testing.T) {
+
+	timezone := string(U.TimeZoneStringIST)
+	dayStart := U.GetBeginningOfDayTimestampIn(time.Now().Unix()-10*U.SECONDS_IN_A_DAY, U.TimeZoneStringIST)
+	from := dayStart + 3600
+	to := dayStart + 3*U.SECONDS_IN_A_DAY - 1
+
+	dayRanges := model.GetAttributionDayRanges(from, to, timezone, time.Now().Unix())
+	assert.Len(t, dayRanges, 3)
+	// partial first day is not cached.
+	assert.Equal(t, from, dayRanges[0].From)
+	assert.False(t, dayRanges[0].Cacheable)
+	assert.Equal(t, dayStart+U.SECONDS_IN_A_DAY, dayRanges[1].From)
+	assert.True(t, dayRanges[1].Cacheable)
+	assert.Equal(t, to, dayRanges[2].To)
+	assert.True(t, dayRanges[2].Cacheable)
+
+	// days ended recently are not cached.
+	dayRanges = model.GetAttributionDayRanges(from, to, timezone, to+60)
+	assert.False(t, dayRanges[2].Cacheable)
+
+	conversionEvent := "$Form_Submitted"
+	user1, user2 := "user1", "user2"
+	camp1 := "adwords:-:campaign1"
+	camp2 := "adwords:-:campaign2"
+	day1 := &model.AttributionDayPartial{
+		CoalUserIdConversionTimestamp: map[string]int64{user1: 1000},
+		UsersToBeAttributed:           []model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: 1000}},
+		UserIDs:                       []string{user1},
+		Sessions: map[string]map[string]model.UserSessionData{
+			user1: {camp1: {MinTimestamp: 500, MaxTimestamp: 500, TimeStamps: []int64{500}}}},
+	}
+	day2 := &model.AttributionDayPartial{
+		CoalUserIdConversionTimestamp: map[string]int64{user1: 2000, user2: 2500},
+		UsersToBeAttributed: []model.UserEventInfo{{CoalUserID: user1, EventName: conversionEvent, Timestamp: 2000},
+			{CoalUserID: user2, EventName: conversionEvent, Timestamp: 2500}},
+		UserIDs: []string{user1, user2},
+		Sessions: map[string]map[string]model.UserSessionData{
+			user1: {camp2: {MinTimestamp: 1500, MaxTimestamp: 1500, TimeStamps: []int64{1500}}},
+			user2: {camp2: {MinTimestamp: 2400, MaxTimestamp: 2400, TimeStamps: []int64{2400}}}},
+	}
+
+	coalUserIdConversionTimestamp, usersToBeAttributed, usersIDsToAttribute, sessions := model.MergeAttributionDayPartials(
+		[]*model.AttributionDayPartial{day1, day2})
+	assert.ElementsMatch(t, []string{user1, user2}, usersIDsToAttribute)
+	// user1 is attributed on the first conversion with the touch points of that day.
+	assert.Equal(t, map[string]int64{user1: 1000, user2: 2500}, coalUserIdConversionTimestamp)
+	assert.Len(t, usersToBeAttributed, 2)
+	assert.Contains(t, usersToBeAttributed, model.UserEventInfo{CoalUserID: user1, EventName: conversionEvent, Timestamp: 1000})
+	assert.Contains(t, sessions[user1], camp1)
+	assert.NotContains(t, sessions[user1], camp2)
+	assert.Contains(t, sessions[user2], camp2)
+	assert.ElementsMatch(t, []string{user1, user2}, day2.GetCoalUserIDs())
+
+	// touch points are evaluated against the range of the whole query, not of the day they were cached for.
+	day3 := &model.AttributionDayPartial{
+		Sessions: map[string]map[string]model.UserSessionData{
+			user1: {camp1: {MinTimestamp: 500, MaxTimestamp: 500, TimeStamps: []int64{500}, WithinQueryPeriod: true},
+				camp2: {MinTimestamp: 1500, MaxTimestamp: 1500, TimeStamps: []int64{1500}}}},
+	}
+	model.SetAttributionDayPartialsWithinQueryPeriod([]*model.AttributionDayPartial{day3},
+		model.AttributionQueryTypeConversionBased, 0, 1000, 2000)
+	assert.False(t, day3.Sessions[user1][camp1].WithinQueryPeriod)
+	assert.True(t, day3.Sessions[user1][camp2].WithinQueryPeriod)
+}
+
+func TestAttributionDayPartialsV1(t *testing.T) {
+
+	query := &model.AttributionQueryV1{
+		KPIQueries: []model.AttributionKPIQueries{{AnalyzeType: model.AnalyzeTypeHSDeals,
+			KPI: model.KPIQueryGroup{Queries: []model.KPIQuery{{Metrics: []string{"revenue"}, From: 100, To: 200}}}}},
+		AttributionKey: model.AttributionKeyCampaign,
+		QueryType:      model.AttributionQueryTypeConversionBased,
+		LookbackDays:   10,
+		From:           0,
+		To:             7 * U.SECONDS_IN_A_DAY,
+	}
+	assert.True(t, model.IsAttributionDayPartialsSupportedV1(query))
+
+	// hash doesn't depend on the range of the query.
+	hash, err := model.GetAttributionDayPartialQueryHashV1(query)
+	assert.Nil(t, err)
+	otherRangeQuery := *query
+	otherRangeQuery.From, otherRangeQuery.To = 100, 200
+	otherRangeQuery.KPIQueries = []model.AttributionKPIQueries{{AnalyzeType: model.AnalyzeTypeHSDeals,
+		KPI: model.KPIQueryGroup{Queries: []model.KPIQuery{{Metrics: []string{"revenue"}, From: 300, To: 400}}}}}
+	otherHash, err := model.GetAttributionDayPartialQueryHashV1(&otherRangeQuery)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, otherHash)
+
+	compareQuery := *query
+	compareQuery.ConversionEventCompare = model.QueryEventWithProperties{Name: "$session"}
+	assert.False(t, model.IsAttributionDayPartialsSupportedV1(&compareQuery))
+
+	accountQuery := *query
+	accountQuery.AttributionLevel = model.AttributionLevelAccount
+	assert.False(t, model.IsAttributionDayPartialsSupportedV1(&accountQuery))
+
+	user1, user2 := "user1", "user2"
+	camp1 := "adwords:-:campaign1"
+	headers := []string{"revenue"}
+	aggTypes := []string{model.SumAggregateFunction}
+	day1 := &model.AttributionDayPartial{
+		KPIData: map[string]model.KPIInfo{"deal1": {KpiID: "deal1", KpiUserIds: []string{user1}, KpiCoalUserIds: []string{user1},
+			KpiHeaderNames: headers, KpiValuesList: []model.KpiRowValue{{Timestamp: 1000, Values: []float64{10}}}}},
+		KPIHeaders:          headers,
+		KPIAggFunctionTypes: aggTypes,
+		Sessions: map[string]map[string]model.UserSessionData{
+			user1: {camp1: {MinTimestamp: 500, MaxTimestamp: 500, TimeStamps: []int64{500}}}},
+	}
+	day2 := &model.AttributionDayPartial{
+		KPIData: map[string]model.KPIInfo{
+			"deal1": {KpiID: "deal1", KpiUserIds: []string{user1}, KpiCoalUserIds: []string{user1},
+				KpiHeaderNames: headers, KpiValuesList: []model.KpiRowValue{{Timestamp: 2000, Values: []float64{5}}}},
+			"deal2": {KpiID: "deal2", KpiUserIds: []string{user2}, KpiCoalUserIds: []string{user2},
+				KpiHeaderNames: headers, KpiValuesList: []model.KpiRowValue{{Timestamp: 2500, Values: []float64{20}}}}},
+		KPIHeaders:          headers,
+		KPIAggFunctionTypes: aggTypes,
+		Sessions: map[string]map[string]model.UserSessionData{
+			user1: {camp1: {MinTimestamp: 500, MaxTimestamp: 1500, TimeStamps: []int64{500, 1500}}},
+			user2: {camp1: {MinTimestamp: 2400, MaxTimestamp: 2400, TimeStamps: []int64{2400}}}},
+	}
+
+	kpiData, kpiHeaders, kpiAggFunctionTypes, usersIDsToAttribute, sessions := model.MergeAttributionDayPartialsV1(
+		[]*model.AttributionDayPartial{day1, {}, day2})
+	assert.Equal(t, headers, kpiHeaders)
+	assert.Equal(t, aggTypes, kpiAggFunctionTypes)
+	assert.ElementsMatch(t, []string{user1, user2}, usersIDsToAttribute)
+	assert.Len(t, kpiData, 2)
+	assert.Len(t, kpiData["deal1"].KpiValuesList, 2)
+	assert.Equal(t, []string{user1}, kpiData["deal1"].KpiUserIds)
+	// touch points pulled on both the days are merged without duplicates.
+	assert.Equal(t, []int64{500, 1500}, sessions[user1][camp1].TimeStamps)
+	assert.Equal(t, int64(500), sessions[user1][camp1].MinTimestamp)
+	assert.Equal(t, int64(1500), sessions[user1][camp1].MaxTimestamp)
+	assert.Equal(t, []int64{2400}, sessions[user2][camp1].TimeStamps)
+}
+
+func TestAttributionDayPartialVersions(t *testing.T) {
+
+	sessions := map[string]map[string]model.UserSessionData{
+		"user1": {"campaign1": {MarketingInfo: model.MarketingData{CampaignID: "1", CampaignName: "campaign1"}}}}
+	reports := &model.MarketingReports{AdwordsCampaignIDData: map[string]model.MarketingData{
+		"1": {ID: "1", Name: "campaign1", Spend: 10},
+		"2": {ID: "2", Name: "campaign2", Spend: 10},
+	}}
+
+	version, err := model.GetAttributionMarketingReportsVersion(reports, sessions)
+	assert.Nil(t, err)
+
+	// metrics and campaigns not referred by the touch points don't change the version.
+	reports.AdwordsCampaignIDData["1"] = model.MarketingData{ID: "1", Name: "campaign1", Spend: 20}
+	reports.AdwordsCampaignIDData["2"] = model.MarketingData{ID: "2", Name: "campaign2 renamed"}
+	sameVersion, err := model.GetAttributionMarketingReportsVersion(reports, sessions)
+	assert.Nil(t, err)
+	assert.Equal(t, version, sameVersion)
+
+	// renaming a referred campaign changes it.
+	reports.AdwordsCampaignIDData["1"] = model.MarketingData{ID: "1", Name: "campaign1 renamed"}
+	newVersion, err := model.GetAttributionMarketingReportsVersion(reports, sessions)
+	assert.Nil(t, err)
+	assert.NotEqual(t, version, newVersion)
+
+	usersVersion, _ := model.GetAttributionUsersVersion(map[string]string{"u1": "customer1"})
+	mergedUsersVersion, _ := model.GetAttributionUsersVersion(map[string]string{"u1": "customer1", "u2": "customer1"})
+	assert.NotEqual(t, usersVersion, mergedUsersVersion)
+
+	partial := &model.AttributionDayPartial{MarketingVersion: version, UsersVersion: usersVersion}
+	assert.False(t, partial.IsStale(version, usersVersion))
+	assert.True(t, partial.IsStale(newVersion, usersVersion))
+	assert.True(t, partial.IsStale(version, mergedUsersVersion))
+
+	// partials with conversions cached without the user ids are recomputed.
+	partial.CoalUserIdConversionTimestamp = map[string]int64{"u1": 1000}
+	assert.True(t, partial.IsStale(version, usersVersion))
+	partial.UserIDs = []string{"u1"}
+	assert.False(t, partial.IsStale(version, usersVersion))
+}
+
+func TestAttributionDayPartialsMatchFullRange(t *testing.T) {
+
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	timestamp := int64(1589068800)
+	createdUserIDs := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		createdUserID, errCode := store.GetStore().CreateUser(&model.User{ProjectId: project.ID, Properties: postgres.Jsonb{},
+			JoinTimestamp: timestamp, Source: model.GetRequestSourcePointer(model.UserSourceWeb)})
+		assert.Equal(t, http.StatusCreated, errCode)
+		createdUserIDs = append(createdUserIDs, createdUserID)
+	}
+
+	// user1: campaign1 -> campaign2 -> conversion on the second day, user2: campaign2 -> conversion on
+	// the third day, user3 doesn't convert.
+	_, errCode := createSession(project.ID, createdUserIDs[0], timestamp+1*U.SECONDS_IN_A_DAY,
+		"campaign1", "", "", "", "", "")
+	assert.Equal(t, http.StatusCreated, errCode)
+	errCode = createEventWithSession(project.ID, "event1", createdUserIDs[0],
+		timestamp+2*U.SECONDS_IN_A_DAY, "campaign2", "", "", "", "", "")
+	assert.Equal(t, http.StatusCreated, errCode)
+	errCode = createEventWithSession(project.ID, "event1", createdUserIDs[1],
+		timestamp+3*U.SECONDS_IN_A_DAY, "campaign2", "", "", "", "", "")
+	assert.Equal(t, http.StatusCreated, errCode)
+	_, errCode = createSession(project.ID, createdUserIDs[2], timestamp+2*U.SECONDS_IN_A_DAY,
+		"campaign1", "", "", "", "", "")
+	assert.Equal(t, http.StatusCreated, errCode)
+
+	query := &model.AttributionQuery{
+		AnalyzeType:            model.AnalyzeTypeUsers,
+		QueryType:              model.AttributionQueryTypeConversionBased,
+		From:                   timestamp,
+		To:                     timestamp + 4*U.SECONDS_IN_A_DAY - 1,
+		AttributionKey:         model.AttributionKeyCampaign,
+		AttributionMethodology: model.AttributionMethodDataDriven,
+		ConversionEvent:        model.QueryEventWithProperties{Name: "event1"},
+		LookbackDays:           10,
+	}
+	var debugQueryKey string
+
+	attributionDayPartials := C.GetConfig().AttributionDayPartials
+	defer func() { C.GetConfig().AttributionDayPartials = attributionDayPartials }()
+
+	C.GetConfig().AttributionDayPartials = ""
+	fullRangeQuery := *query
+	fullRangeResult, err := store.GetStore().ExecuteAttributionQueryV0(project.ID, &fullRangeQuery, debugQueryKey,
+		C.EnableOptimisedFilterOnProfileQuery(), C.EnableOptimisedFilterOnEventUserQuery())
+	assert.Nil(t, err)
+
+	// partials are computed on the first run and read from cache on the next.
+	C.GetConfig().AttributionDayPartials = "*"
+	for i := 0; i < 2; i++ {
+		dayPartialsQuery := *query
+		dayPartialsResult, err := store.GetStore().ExecuteAttributionQueryV0(project.ID, &dayPartialsQuery, debugQueryKey,
+			C.EnableOptimisedFilterOnProfileQuery(), C.EnableOptimisedFilterOnEventUserQuery())
+		assert.Nil(t, err)
+		assert.Equal(t, fullRangeResult.Headers, dayPartialsResult.Headers)
+		assert.ElementsMatch(t, fullRangeResult.Rows, dayPartialsResult.Rows)
+		assert.ElementsMatch(t, fullRangeQuery.NonConvertedPaths, dayPartialsQuery.NonConvertedPaths)
+	}
+}
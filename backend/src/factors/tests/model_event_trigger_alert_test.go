@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -2881,3 +2883,168 @@ func TestWorkflowCreation(t *testing.T) {
 	})
 
 }
+
+func TestEventTriggerAlertDeliveries(t *testing.T) {
+	project, agent, err := SetupProjectWithSlackIntegratedAgentDAO()
+	assert.Nil(t, err)
+
+	rName := U.RandomString(5)
+	alert, errCode, _ := store.GetStore().CreateEventTriggerAlert(agent.UUID, "", project.ID, &model.EventTriggerAlertConfig{
+		Title: rName, Event: rName, Message: "Remember", MessageProperty: &postgres.Jsonb{},
+		Webhook: true, WebhookURL: "https://example.com/hook"}, agent.UUID, agent.UUID, false, nil)
+	assert.Equal(t, http.StatusCreated, errCode)
+
+	payload, err := U.EncodeStructTypeToPostgresJsonb(model.CachedEventTriggerAlert{
+		Message: model.EventTriggerAlertMessage{Title: rName, Event: rName, Message: "Remember"},
+	})
+	assert.Nil(t, err)
+
+	success := model.EventTriggerAlertDelivery{ProjectID: project.ID, AlertID: alert.ID, DeliveryOption: model.WEBHOOK,
+		Destination: "https://example.com/hook", Payload: payload, Status: model.DeliveryStatusSuccess, LatencyInMs: 10}
+	assert.Equal(t, http.StatusCreated, store.GetStore().CreateEventTriggerAlertDelivery(&success))
+	failure := model.EventTriggerAlertDelivery{ProjectID: project.ID, AlertID: alert.ID, DeliveryOption: model.WEBHOOK,
+		Destination: "https://example.com/hook", Payload: payload, Status: model.DeliveryStatusFailure, Error: "timeout"}
+	assert.Equal(t, http.StatusCreated, store.GetStore().CreateEventTriggerAlertDelivery(&failure))
+
+	deliveries, errCode := store.GetStore().GetEventTriggerAlertDeliveries(project.ID, alert.ID, "", 0)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Len(t, deliveries, 2)
+
+	deliveries, errCode = store.GetStore().GetEventTriggerAlertDeliveries(project.ID, alert.ID, model.DeliveryStatusFailure, 0)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, failure.ID, deliveries[0].ID)
+
+	// Only failed deliveries can be replayed and only once.
+	errCode, _ = store.GetStore().ReplayEventTriggerAlertDelivery(project.ID, alert.ID, success.ID)
+	assert.Equal(t, http.StatusBadRequest, errCode)
+	errCode, err = store.GetStore().ReplayEventTriggerAlertDelivery(project.ID, alert.ID, failure.ID)
+	assert.Equal(t, http.StatusAccepted, errCode)
+	assert.Nil(t, err)
+	errCode, _ = store.GetStore().ReplayEventTriggerAlertDelivery(project.ID, alert.ID, failure.ID)
+	assert.Equal(t, http.StatusConflict, errCode)
+
+	replayed, errCode := store.GetStore().GetEventTriggerAlertDeliveryByID(project.ID, alert.ID, failure.ID)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.NotNil(t, replayed.ReplayedAt)
+
+	// Concurrent replays of a delivery enqueue it only once.
+	concurrentFailure := model.EventTriggerAlertDelivery{ProjectID: project.ID, AlertID: alert.ID, DeliveryOption: model.WEBHOOK,
+		Destination: "https://example.com/hook", Payload: payload, Status: model.DeliveryStatusFailure, Error: "timeout"}
+	assert.Equal(t, http.StatusCreated, store.GetStore().CreateEventTriggerAlertDelivery(&concurrentFailure))
+	var wg sync.WaitGroup
+	var accepted int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errCode, _ := store.GetStore().ReplayEventTriggerAlertDelivery(project.ID, alert.ID,
+				concurrentFailure.ID); errCode == http.StatusAccepted {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), accepted)
+
+	// Deliveries within the retention are not deleted.
+	errCode, _ = store.GetStore().DeleteEventTriggerAlertDeliveriesOlderThanGivenDays(0)
+	assert.Equal(t, http.StatusBadRequest, errCode)
+	errCode, err = store.GetStore().DeleteEventTriggerAlertDeliveriesOlderThanGivenDays(model.DeliveryRetentionDays)
+	assert.Equal(t, http.StatusOK, errCode)
+	assert.Nil(t, err)
+	deliveries, errCode = store.GetStore().GetEventTriggerAlertDeliveries(project.ID, alert.ID, "", 0)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Len(t, deliveries, 3)
+}
+
+func TestEventTriggerAlertEmailDigest(t *testing.T) {
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+	alertID := util.GetUUID()
+
+	digest, err := model.GetEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+	assert.Nil(t, digest)
+
+	for _, company := range []string{"acme.com", "globex.com"} {
+		item := model.EventTriggerAlertDigestItem{
+			Message: model.EventTriggerAlertMessage{
+				Title: "ICP visited pricing",
+				MessageProperty: util.PropertiesMap{
+					"0": model.MessagePropMapStruct{DisplayName: "Company", PropValue: company},
+				},
+			},
+			AccountURL: "https://app.factors.ai/profiles/accounts/" + company,
+		}
+		err = model.AddToEventTriggerAlertEmailDigest(project.ID, alertID, item)
+		assert.Nil(t, err)
+	}
+
+	alertIDs, err := model.GetPendingEventTriggerAlertDigests(project.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{alertID}, alertIDs)
+
+	digest, err = model.GetEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), digest.Count)
+	assert.Len(t, digest.Items, 2)
+
+	assert.False(t, model.IsEventTriggerAlertDigestDue(digest, 60, digest.StartedAt+59*60))
+	assert.True(t, model.IsEventTriggerAlertDigestDue(digest, 60, digest.StartedAt+60*60))
+
+	subject, html, text, err := model.GetEventTriggerAlertDigestEmailContent("ICP visited pricing",
+		model.EventLevelAccount, digest, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, "2 accounts - \"ICP visited pricing\" in the last hour", subject)
+	assert.Contains(t, html, "globex.com")
+	assert.Contains(t, text, "Company: acme.com")
+
+	// partially failed send is retried with the same alerts, only to the rest of the recipients.
+	err = model.SetEventTriggerAlertEmailDigestSentTo(project.ID, digest, []string{"a@example.com"})
+	assert.Nil(t, err)
+	err = model.AddToEventTriggerAlertEmailDigest(project.ID, alertID, model.EventTriggerAlertDigestItem{
+		Message: model.EventTriggerAlertMessage{Title: "ICP visited pricing"},
+	})
+	assert.Nil(t, err)
+	digest, err = model.GetEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), digest.Count)
+	assert.Equal(t, []string{"a@example.com"}, digest.SentTo)
+
+	// alerts added after the digest was read stay pending.
+	err = model.CompleteEventTriggerAlertEmailDigest(project.ID, digest)
+	assert.Nil(t, err)
+	alertIDs, err = model.GetPendingEventTriggerAlertDigests(project.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{alertID}, alertIDs)
+	digest, err = model.GetEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), digest.Count)
+	assert.Empty(t, digest.SentTo)
+
+	err = model.DeleteEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+	alertIDs, err = model.GetPendingEventTriggerAlertDigests(project.ID)
+	assert.Nil(t, err)
+	assert.Empty(t, alertIDs)
+
+	// concurrent adds are not lost.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := model.AddToEventTriggerAlertEmailDigest(project.ID, alertID, model.EventTriggerAlertDigestItem{
+				Message: model.EventTriggerAlertMessage{Title: "ICP visited pricing"},
+			})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	digest, err = model.GetEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), digest.Count)
+	err = model.DeleteEventTriggerAlertEmailDigest(project.ID, alertID)
+	assert.Nil(t, err)
+}
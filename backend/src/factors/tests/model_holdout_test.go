@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"testing"
+
+	"factors/model/model"
+	U "factors/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldoutLift(t *testing.T) {
+	kpiQueryGroup := model.KPIQueryGroup{
+		Class: "kpi",
+		Queries: []model.KPIQuery{
+			{Category: model.ProfileCategory, DisplayCategory: model.HubspotContactsDisplayCategory,
+				Metrics: []string{"count"}, From: 1672531200, To: 1675209599},
+		},
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		query := model.HoldoutLiftQuery{KPI: kpiQueryGroup, Split: model.HoldoutSplit{Type: "random"}}
+		valid, _ := query.IsValid()
+		assert.False(t, valid)
+
+		query.Split = model.HoldoutSplit{Type: model.HoldoutSplitTypeUserProperty, PropertyName: "$country"}
+		valid, _ = query.IsValid()
+		assert.False(t, valid)
+
+		query.Split = model.HoldoutSplit{Type: model.HoldoutSplitTypeSegment, ControlSegmentID: "s1", ExposedSegmentID: "s1"}
+		valid, _ = query.IsValid()
+		assert.False(t, valid)
+
+		query.Split = model.HoldoutSplit{Type: model.HoldoutSplitTypeLinkedinCapping}
+		query.ConfidenceLevel = 0.5
+		valid, _ = query.IsValid()
+		assert.False(t, valid)
+
+		query.ConfidenceLevel = 0
+		valid, _ = query.IsValid()
+		assert.True(t, valid)
+		assert.Equal(t, 1.960, query.GetZScore())
+
+		channelQuery := query
+		channelQuery.KPI = model.KPIQueryGroup{Class: "kpi", Queries: []model.KPIQuery{
+			{Category: model.ChannelCategory, DisplayCategory: model.LinkedinDisplayCategory, Metrics: []string{"impressions"}},
+		}}
+		valid, _ = channelQuery.IsValid()
+		assert.False(t, valid)
+
+		channelQuery.KPI.Queries[0].DisplayCategory = model.AdwordsDisplayCategory
+		valid, _ = channelQuery.IsValid()
+		assert.False(t, valid)
+	})
+
+	t.Run("Populations", func(t *testing.T) {
+		query := model.HoldoutLiftQuery{KPI: kpiQueryGroup, Split: model.HoldoutSplit{
+			Type: model.HoldoutSplitTypeUserProperty, PropertyName: "$holdout", ControlValues: []string{"a", "b"}}}
+		exposed, control, err := model.GetHoldoutPopulations(&query, nil)
+		assert.Nil(t, err)
+
+		assert.Len(t, control.KPI.GlobalFilters, 2)
+		assert.Equal(t, model.EqualsOpStr, control.KPI.GlobalFilters[0].Condition)
+		assert.Equal(t, model.LOGICAL_OP_AND, control.KPI.GlobalFilters[0].LogicalOp)
+		assert.Equal(t, model.LOGICAL_OP_OR, control.KPI.GlobalFilters[1].LogicalOp)
+
+		// exposed is everyone with the property set, other than the control values.
+		assert.True(t, exposed.Exclude)
+		assert.Len(t, exposed.KPI.GlobalFilters, 3)
+		assert.Equal(t, model.PropertyValueNone, exposed.KPI.GlobalFilters[0].Value)
+		for _, filter := range exposed.KPI.GlobalFilters {
+			assert.Equal(t, model.NotEqualOpStr, filter.Condition)
+			assert.Equal(t, model.LOGICAL_OP_AND, filter.LogicalOp)
+		}
+		// original query is not modified.
+		assert.Len(t, query.KPI.GlobalFilters, 0)
+
+		query.Split = model.HoldoutSplit{Type: model.HoldoutSplitTypeSegment, ControlSegmentID: "s1", ExposedSegmentID: "s2"}
+		exposed, control, err = model.GetHoldoutPopulations(&query, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "s2", exposed.KPI.SegmentID)
+		assert.Equal(t, "s1", control.KPI.SegmentID)
+
+		query.Split = model.HoldoutSplit{Type: model.HoldoutSplitTypeLinkedinCapping}
+		_, _, err = model.GetHoldoutPopulations(&query, nil)
+		assert.NotNil(t, err)
+		exposed, control, err = model.GetHoldoutPopulations(&query, []string{"123"})
+		assert.Nil(t, err)
+		assert.Equal(t, U.LI_ORGANIZATION_ID, control.KPI.GlobalFilters[0].PropertyName)
+		assert.Equal(t, "123", control.KPI.GlobalFilters[0].Value)
+		assert.True(t, exposed.Exclude)
+	})
+
+	t.Run("Lift", func(t *testing.T) {
+		// 10% vs 5% conversion.
+		lift, err := model.ComputeHoldoutLift(1000, 10000, 50, 1000, 1.960)
+		assert.Nil(t, err)
+		assert.InDelta(t, 100, lift.Lift, 0.0001)
+		assert.True(t, lift.LiftLower > 0 && lift.LiftLower < lift.Lift)
+		assert.True(t, lift.LiftUpper > lift.Lift)
+		assert.True(t, lift.Significant)
+
+		// small difference on small populations is not significant.
+		lift, err = model.ComputeHoldoutLift(11, 100, 10, 100, 1.960)
+		assert.Nil(t, err)
+		assert.InDelta(t, 10, lift.Lift, 0.0001)
+		assert.False(t, lift.Significant)
+
+		_, err = model.ComputeHoldoutLift(10, 100, 0, 100, 1.960)
+		assert.NotNil(t, err)
+
+		result := model.GetHoldoutLiftResult([]string{"count"}, map[string]bool{"count": true}, []float64{1000}, 10000, []float64{50}, 1000, 1.960)
+		assert.Equal(t, model.HoldoutLiftResultHeaders, result.Headers)
+		assert.Len(t, result.Rows, 1)
+		assert.Equal(t, 100.0, result.Rows[0][7])
+		assert.Equal(t, true, result.Rows[0][10])
+
+		result = model.GetHoldoutLiftResult([]string{"count"}, map[string]bool{"count": true}, []float64{1000}, 10000, []float64{0}, 0, 1.960)
+		assert.Nil(t, result.Rows[0][7])
+		assert.Equal(t, false, result.Rows[0][10])
+
+		// no confidence interval for non count metrics.
+		result = model.GetHoldoutLiftResult([]string{"revenue"}, map[string]bool{"count": true}, []float64{1000}, 10000, []float64{50}, 1000, 1.960)
+		assert.Equal(t, 100.0, result.Rows[0][7])
+		assert.Nil(t, result.Rows[0][8])
+		assert.Nil(t, result.Rows[0][9])
+		assert.Equal(t, false, result.Rows[0][10])
+	})
+
+	t.Run("CountMetrics", func(t *testing.T) {
+		countTransformation, _ := U.EncodeStructTypeToPostgresJsonb(&model.CustomMetricTransformation{AggregateFunction: model.CountAggregateFunction})
+		sumTransformation, _ := U.EncodeStructTypeToPostgresJsonb(&model.CustomMetricTransformation{AggregateFunction: model.SumAggregateFunction})
+		customMetrics := []model.CustomMetric{
+			{Name: "count", TypeOfQuery: model.ProfileQueryType, ObjectType: model.HubspotContactsDisplayCategory, Transformations: countTransformation},
+			{Name: "revenue", TypeOfQuery: model.ProfileQueryType, ObjectType: model.HubspotContactsDisplayCategory, Transformations: sumTransformation},
+		}
+		query := kpiQueryGroup
+		query.Queries = []model.KPIQuery{
+			{Category: model.ProfileCategory, DisplayCategory: model.HubspotContactsDisplayCategory, Metrics: []string{"count", "revenue"}},
+			{Category: model.EventCategory, DisplayCategory: model.WebsiteSessionDisplayCategory,
+				Metrics: []string{model.TotalSessions, model.AvgSessionDuration}},
+		}
+		countMetrics := model.GetHoldoutCountMetrics(query, customMetrics)
+		assert.Equal(t, map[string]bool{"count": true, model.TotalSessions: true}, countMetrics)
+	})
+
+	t.Run("TotalsResult", func(t *testing.T) {
+		_, exists := model.GetHoldoutTotalsResult(nil)
+		assert.False(t, exists)
+
+		gbtResult := model.QueryResult{Headers: []string{model.AliasDateTime, "count"}, Rows: [][]interface{}{{"2023-01-01", 1}}}
+		totalsResult := model.QueryResult{Headers: []string{"count"}, Rows: [][]interface{}{{10}}}
+		result, exists := model.GetHoldoutTotalsResult([]model.QueryResult{totalsResult, gbtResult})
+		assert.True(t, exists)
+		assert.Equal(t, totalsResult, result)
+
+		_, exists = model.GetHoldoutTotalsResult([]model.QueryResult{gbtResult, {}})
+		assert.False(t, exists)
+	})
+}
@@ -1,12 +1,15 @@
 package tests
 
 import (
+	"encoding/json"
 	"factors/model/model"
 	"factors/model/store"
+	T "factors/task"
 	U "factors/util"
 	"net/http"
 	"testing"
 
+	"github.com/jinzhu/gorm/dialects/postgres"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,3 +74,117 @@ func TestUpdateMarkerRunSegment(t *testing.T) {
 		assert.Equal(t, updateTime, segment.MarkerRunSegment.UTC())
 	}
 }
+
+func TestSegmentMembershipHistory(t *testing.T) {
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	segmentID := U.GetUUID()
+	domainID := U.GetUUID()
+	oldSegmentID := U.GetUUID()
+
+	// domain enters segmentID and exits oldSegmentID.
+	newSegments := map[string]model.AssociatedSegments{segmentID: {V: 0}}
+	oldSegments := map[string]interface{}{oldSegmentID: map[string]interface{}{"v": 0}}
+	isDifferent, changes := T.IsMapsNotMatching(project.ID, domainID, newSegments, oldSegments, U.TimeZoneStringIST)
+	assert.True(t, isDifferent)
+	assert.Len(t, changes, 2)
+
+	status := store.GetStore().CreateSegmentMembershipChanges(project.ID, changes)
+	assert.Equal(t, http.StatusCreated, status)
+
+	history, status := store.GetStore().GetSegmentMembershipHistory(project.ID, segmentID, "", 0, 0, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, history, 1)
+	assert.Equal(t, model.SegmentMembershipEntered, history[0].Action)
+	assert.Equal(t, domainID, history[0].UserID)
+	assert.Equal(t, U.EVENT_NAME_SEGMENT_ENTERED, model.GetSegmentMembershipEventName(history[0].Action))
+
+	history, status = store.GetStore().GetSegmentMembershipHistory(project.ID, oldSegmentID, domainID, 0, 0, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, history, 1)
+	assert.Equal(t, model.SegmentMembershipExited, history[0].Action)
+
+	// filtered out by time range.
+	history, status = store.GetStore().GetSegmentMembershipHistory(project.ID, segmentID, "", 0, history[0].Timestamp-3600, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, history, 0)
+
+	status = store.GetStore().CreateSegmentMembershipChanges(project.ID,
+		[]model.SegmentMembershipChange{{SegmentID: segmentID, UserID: domainID, Action: "unknown"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestUserSegmentMembershipHistory(t *testing.T) {
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	source := model.GetRequestSourcePointer(model.UserSourceWeb)
+	userID, status := store.GetStore().CreateUser(&model.User{
+		ProjectId:  project.ID,
+		Source:     source,
+		Properties: postgres.Jsonb{RawMessage: json.RawMessage(`{"$country":"India"}`)},
+	})
+	assert.Equal(t, http.StatusCreated, status)
+
+	segment, status, err := store.GetStore().CreateSegment(project.ID, &model.SegmentPayload{
+		Name: "Users from India",
+		Query: model.Query{
+			Caller:        model.PROFILE_TYPE_USER,
+			GroupAnalysis: model.FILTER_TYPE_USERS,
+			Source:        "web",
+			Timezone:      string(U.TimeZoneStringIST),
+			GlobalUserProperties: []model.QueryProperty{
+				{
+					Entity:    "user_g",
+					Type:      "categorical",
+					Property:  "$country",
+					Operator:  "equals",
+					Value:     "India",
+					LogicalOp: "AND",
+				},
+			},
+		},
+		Type: "web",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+
+	segmentQuery := model.Query{}
+	err = U.DecodePostgresJsonbToStructType(segment.Query, &segmentQuery)
+	assert.Nil(t, err)
+	allSegmentsMap := map[string][]model.Segment{"web": {segment}}
+	decodedSegmentRulesMap := map[string][]model.Query{"web": {segmentQuery}}
+
+	// user enters the segment.
+	user, status := store.GetStore().GetUser(project.ID, userID)
+	assert.Equal(t, http.StatusFound, status)
+	status = T.UserProcessingWithErrcode(project.ID, *user, allSegmentsMap, decodedSegmentRulesMap,
+		map[string]string{}, map[string]map[string]bool{})
+	assert.Equal(t, http.StatusOK, status)
+
+	history, status := store.GetStore().GetSegmentMembershipHistory(project.ID, segment.Id, userID, 0, 0, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, history, 1)
+	assert.Equal(t, model.SegmentMembershipEntered, history[0].Action)
+
+	// no change on the next run.
+	status = T.UserProcessingWithErrcode(project.ID, *user, allSegmentsMap, decodedSegmentRulesMap,
+		map[string]string{}, map[string]map[string]bool{})
+	assert.Equal(t, http.StatusOK, status)
+	history, status = store.GetStore().GetSegmentMembershipHistory(project.ID, segment.Id, userID, 0, 0, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, history, 1)
+
+	// user exits the segment.
+	user.Properties = postgres.Jsonb{RawMessage: json.RawMessage(`{"$country":"US"}`)}
+	status = T.UserProcessingWithErrcode(project.ID, *user, allSegmentsMap, decodedSegmentRulesMap,
+		map[string]string{}, map[string]map[string]bool{})
+	assert.Equal(t, http.StatusOK, status)
+
+	history, status = store.GetStore().GetSegmentMembershipHistory(project.ID, segment.Id, userID, 0, 0, 0)
+	assert.Equal(t, http.StatusFound, status)
+	assert.Len(t, history, 2)
+	actions := []string{history[0].Action, history[1].Action}
+	assert.Contains(t, actions, model.SegmentMembershipExited)
+}
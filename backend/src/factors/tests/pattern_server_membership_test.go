@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"factors/pattern_server/membership"
+	serviceEtcd "factors/services/etcd"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternServerStaticMembership(t *testing.T) {
+	dir, err := ioutil.TempDir("", "membership")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "membership.json")
+	writeFile := func(content string, modTime time.Time) {
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+		assert.Nil(t, os.Chtimes(filePath, modTime, modTime))
+	}
+
+	_, err = membership.NewStaticMembership(filePath, 0)
+	assert.NotNil(t, err)
+
+	modTime := time.Now().Add(-time.Hour)
+	writeFile(`{"pattern_servers": ["127.0.0.1:8110", "127.0.0.1:8100", "127.0.0.1:8100"]}`, modTime)
+	psMembership, err := membership.NewStaticMembership(filePath, 10*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, membership.TypeStatic, psMembership.GetType())
+
+	nodes, err := psMembership.DiscoverPatternServers()
+	assert.Nil(t, err)
+	assert.Equal(t, []serviceEtcd.KV{
+		{Key: serviceEtcd.PatternServerPrefix + "127.0.0.1:8100", Value: "127.0.0.1:8100"},
+		{Key: serviceEtcd.PatternServerPrefix + "127.0.0.1:8110", Value: "127.0.0.1:8110"},
+	}, nodes)
+
+	assert.Nil(t, psMembership.Register("127.0.0.1", "8100"))
+	assert.NotNil(t, psMembership.Register("127.0.0.1", "8120"))
+	isRegistered, err := psMembership.IsRegistered("127.0.0.1", "8100")
+	assert.Nil(t, err)
+	assert.False(t, isRegistered)
+
+	_, err = psMembership.GetProjectVersion()
+	assert.Equal(t, serviceEtcd.NotFound, err)
+	assert.Nil(t, psMembership.SetProjectVersion("version1"))
+	version, err := psMembership.GetProjectVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, "version1", version)
+
+	nodesEvents := psMembership.WatchPatternServers()
+	versionEvents := psMembership.WatchProjectVersion()
+
+	// change of version only notifies the version watchers.
+	modTime = modTime.Add(time.Minute)
+	writeFile(`{"pattern_servers": ["127.0.0.1:8100", "127.0.0.1:8110"], "project_version": "version2"}`, modTime)
+	select {
+	case event := <-versionEvents:
+		assert.Nil(t, event.Err)
+		assert.Equal(t, "version2", event.Version)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "version change not notified")
+	}
+	version, _ = psMembership.GetProjectVersion()
+	assert.Equal(t, "version2", version)
+
+	modTime = modTime.Add(time.Minute)
+	writeFile(`{"pattern_servers": ["127.0.0.1:8100"], "project_version": "version2"}`, modTime)
+	select {
+	case event := <-nodesEvents:
+		assert.Nil(t, event.Err)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "pattern servers change not notified")
+	}
+	nodes, _ = psMembership.DiscoverPatternServers()
+	assert.Len(t, nodes, 1)
+
+	select {
+	case <-versionEvents:
+		assert.Fail(t, "version not changed but notified")
+	default:
+	}
+
+	// a watcher not reading doesn't block the others and gets the latest version.
+	idleVersionEvents := psMembership.WatchProjectVersion()
+	for _, newVersion := range []string{"version3", "version4"} {
+		modTime = modTime.Add(time.Minute)
+		writeFile(`{"pattern_servers": ["127.0.0.1:8100"], "project_version": "`+newVersion+`"}`, modTime)
+		select {
+		case event := <-versionEvents:
+			assert.Equal(t, newVersion, event.Version)
+		case <-time.After(2 * time.Second):
+			assert.Fail(t, "version change not notified")
+		}
+	}
+	select {
+	case event := <-idleVersionEvents:
+		assert.Equal(t, "version4", event.Version)
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "version change not notified to the idle watcher")
+	}
+}
@@ -1,7 +1,9 @@
 package tests
 
 import (
+	PC "factors/pattern_client"
 	patternserver "factors/pattern_server"
+	"factors/pattern_server/hashring"
 	serviceEtcd "factors/services/etcd"
 	U "factors/util"
 	"fmt"
@@ -87,3 +89,92 @@ func TestCalculateMyNum(t *testing.T) {
 	num := patternserver.CalculateMyNum(ip, port, kv)
 	assert.Equal(t, 2, num)
 }
+
+func TestHashRing(t *testing.T) {
+	nodes := []string{"127.0.0.1:8100", "127.0.0.1:8110", "127.0.0.1:8120", "127.0.0.1:8130"}
+	ring := hashring.New(nodes, hashring.DefaultVirtualNodes)
+
+	keys := make([]string, 0)
+	for i := 0; i < 2000; i++ {
+		keys = append(keys, patternserver.GetChunkKey(1, 2, fmt.Sprintf("chunk_%d", i)))
+	}
+
+	countByNode := make(map[string]int)
+	for _, key := range keys {
+		replicas := ring.GetNodes(key, 2)
+		assert.Len(t, replicas, 2)
+		assert.NotEqual(t, replicas[0], replicas[1])
+		countByNode[replicas[0]]++
+	}
+	for _, node := range nodes {
+		assert.True(t, countByNode[node] > 250, "keys not spread across the nodes")
+	}
+	assert.Len(t, ring.GetNodes(keys[0], 10), len(nodes))
+
+	// removing a node moves only its keys, to its next replica.
+	reducedRing := hashring.New(nodes[:3], hashring.DefaultVirtualNodes)
+	for _, key := range keys {
+		replicas := ring.GetNodes(key, 2)
+		newPrimary := reducedRing.GetNodes(key, 1)[0]
+		if replicas[0] == nodes[3] {
+			assert.Equal(t, replicas[1], newPrimary)
+		} else {
+			assert.Equal(t, replicas[0], newPrimary)
+		}
+	}
+}
+
+func TestPatternServerFailover(t *testing.T) {
+	addrs := []string{"8100", "8110", "8120"}
+	psNodes := make([]serviceEtcd.KV, 0)
+	for _, port := range addrs {
+		psNodes = append(psNodes, serviceEtcd.KV{Key: "/prefix/127.0.0.1:" + port, Value: "127.0.0.1:" + port})
+	}
+
+	projectId := U.RandomInt64()
+	modelId := U.RandomUint64()
+	chunks := make([]string, 0)
+	for i := 0; i < 100; i++ {
+		chunks = append(chunks, fmt.Sprintf("chunk_%d", i))
+	}
+	projectData := map[int64]patternserver.ModelChunkMapping{
+		projectId: {modelId: patternserver.ModelData{Chunks: chunks}},
+	}
+
+	servers := make(map[string]*patternserver.PatternServer)
+	for i, port := range addrs {
+		ps, err := patternserver.New("127.0.0.1", port, "", nil, nil, nil, 1, 1, 2)
+		assert.Nil(t, err)
+		ps.SetState(i, psNodes, "", projectData)
+		servers[ps.GetAddr()] = ps
+	}
+
+	// simulates the client. Response of the failed server is lost and the request is
+	// retried on the rest with failover. Each chunk has to be served exactly once.
+	getServedCount := func(chunk string, failedAddr string) int {
+		attempts := []PC.Failover{{}}
+		if failedAddr != "" {
+			attempts = append(attempts, PC.Failover{FailedNodes: []string{failedAddr}, RetryNodes: []string{failedAddr}})
+		}
+
+		count := 0
+		for _, failover := range attempts {
+			for addr, ps := range servers {
+				if addr == failedAddr {
+					continue
+				}
+				if ps.IsProjectModelChunkServableOnRequest(projectId, modelId, chunk, failover) {
+					count++
+				}
+			}
+		}
+		return count
+	}
+
+	for _, chunk := range chunks {
+		assert.Equal(t, 1, getServedCount(chunk, ""), chunk)
+		for addr := range servers {
+			assert.Equal(t, 1, getServedCount(chunk, addr), chunk)
+		}
+	}
+}
@@ -4969,3 +4969,158 @@ func TestAddSessionUserRealSessionProperties(t *testing.T) {
 	assert.Equal(t, float64(2), (*userProperties)[U.UP_TOTAL_SPENT_TIME])
 	assert.Equal(t, "android1", (*userProperties)[U.UP_OS])
 }
+
+func getSessionTestEvent(t *testing.T, timestamp int64, properties map[string]interface{}) *model.Event {
+	propertiesJsonb, err := U.EncodeToPostgresJsonb(&properties)
+	assert.Nil(t, err)
+	return &model.Event{Timestamp: timestamp, Properties: *propertiesJsonb}
+}
+
+func TestSessionDefinition(t *testing.T) {
+	// 2023-01-01 23:50:00 IST.
+	timestamp := int64(1672597200)
+	timezone := U.TimeZoneString("Asia/Kolkata")
+
+	t.Run("Default", func(t *testing.T) {
+		definition, err := model.GetSessionDefinition(&model.ProjectSetting{})
+		assert.Nil(t, err)
+		assert.Equal(t, model.DefaultSessionDefinition(), definition)
+		assert.Equal(t, model.NewUserSessionInactivityInSeconds, definition.GetInactivityTimeoutInSecs())
+
+		event := getSessionTestEvent(t, timestamp, map[string]interface{}{})
+		assert.False(t, model.IsNewSessionRequired(definition, event,
+			getSessionTestEvent(t, timestamp+model.NewUserSessionInactivityInSeconds, map[string]interface{}{}), "", timezone))
+		assert.True(t, model.IsNewSessionRequired(definition, event,
+			getSessionTestEvent(t, timestamp+model.NewUserSessionInactivityInSeconds+1, map[string]interface{}{}), "", timezone))
+	})
+
+	t.Run("InactivityTimeoutAndMidnight", func(t *testing.T) {
+		definition := model.SessionDefinition{InactivityTimeoutInMins: 60}
+		event := getSessionTestEvent(t, timestamp, map[string]interface{}{})
+		// 00:30 of the next day on project timezone.
+		nextEvent := getSessionTestEvent(t, timestamp+40*60, map[string]interface{}{})
+		assert.False(t, model.IsNewSessionRequired(definition, event, nextEvent, "", timezone))
+
+		definition.SplitAtMidnight = true
+		assert.True(t, model.IsNewSessionRequired(definition, event, nextEvent, "", timezone))
+		// Same day on UTC.
+		assert.False(t, model.IsNewSessionRequired(definition, event, nextEvent, "", U.TimeZoneString("UTC")))
+	})
+
+	t.Run("ReferrerChange", func(t *testing.T) {
+		definition := model.SessionDefinition{InactivityTimeoutInMins: 30, NewSessionOnReferrerChange: true}
+		event := getSessionTestEvent(t, timestamp, map[string]interface{}{U.EP_IS_PAGE_VIEW: true,
+			U.EP_PAGE_DOMAIN: "factors.ai", U.EP_REFERRER_DOMAIN: "google.com"})
+
+		getPageView := func(referrerDomain string) *model.Event {
+			return getSessionTestEvent(t, timestamp+60, map[string]interface{}{U.EP_IS_PAGE_VIEW: true,
+				U.EP_PAGE_DOMAIN: "factors.ai", U.EP_REFERRER_DOMAIN: referrerDomain})
+		}
+		landingReferrerDomain := model.GetEventReferrerDomain(event)
+		assert.Equal(t, "google.com", landingReferrerDomain)
+		assert.False(t, model.IsNewSessionRequired(definition, event, getPageView("factors.ai"), landingReferrerDomain, timezone))
+		assert.False(t, model.IsNewSessionRequired(definition, event, getPageView("google.com"), landingReferrerDomain, timezone))
+		assert.False(t, model.IsNewSessionRequired(definition, event, getPageView(""), landingReferrerDomain, timezone))
+		assert.True(t, model.IsNewSessionRequired(definition, event, getPageView("linkedin.com"), landingReferrerDomain, timezone))
+
+		// referrer is compared with the landing page of the session, not with the internal navigation before.
+		internalPageView := getPageView("factors.ai")
+		assert.False(t, model.IsNewSessionRequired(definition, internalPageView, getPageView("Google.com"), landingReferrerDomain, timezone))
+		assert.True(t, model.IsNewSessionRequired(definition, internalPageView, getPageView("linkedin.com"), landingReferrerDomain, timezone))
+
+		definition.NewSessionOnReferrerChange = false
+		assert.False(t, model.IsNewSessionRequired(definition, event, getPageView("linkedin.com"), landingReferrerDomain, timezone))
+	})
+
+	t.Run("UpdateProjectSettings", func(t *testing.T) {
+		project, err := SetupProjectReturnDAO()
+		assert.Nil(t, err)
+
+		for _, invalidTimeout := range []int64{0, model.SessionMaxInactivityTimeoutInMins + 1} {
+			definitionJsonb, err := U.EncodeStructTypeToPostgresJsonb(model.SessionDefinition{InactivityTimeoutInMins: invalidTimeout})
+			assert.Nil(t, err)
+			_, errCode := store.GetStore().UpdateProjectSettings(project.ID, &model.ProjectSetting{SessionDefinition: definitionJsonb})
+			assert.Equal(t, http.StatusBadRequest, errCode)
+		}
+
+		sessionDefinition := model.SessionDefinition{InactivityTimeoutInMins: 45, SplitAtMidnight: true}
+		definitionJsonb, err := U.EncodeStructTypeToPostgresJsonb(sessionDefinition)
+		assert.Nil(t, err)
+		_, errCode := store.GetStore().UpdateProjectSettings(project.ID, &model.ProjectSetting{SessionDefinition: definitionJsonb})
+		assert.Equal(t, http.StatusAccepted, errCode)
+
+		projectSettings, errCode := store.GetStore().GetProjectSetting(project.ID)
+		assert.Equal(t, http.StatusFound, errCode)
+		definition, err := model.GetSessionDefinition(projectSettings)
+		assert.Nil(t, err)
+		assert.Equal(t, sessionDefinition, definition)
+
+		// rules not on the partial definition are taken from the defaults.
+		_, errCode = store.GetStore().UpdateProjectSettings(project.ID, &model.ProjectSetting{
+			SessionDefinition: &postgres.Jsonb{RawMessage: json.RawMessage(`{"split_at_midnight": true}`)}})
+		assert.Equal(t, http.StatusAccepted, errCode)
+		projectSettings, errCode = store.GetStore().GetProjectSetting(project.ID)
+		assert.Equal(t, http.StatusFound, errCode)
+		definition, err = model.GetSessionDefinition(projectSettings)
+		assert.Nil(t, err)
+		expectedDefinition := model.DefaultSessionDefinition()
+		expectedDefinition.SplitAtMidnight = true
+		assert.Equal(t, expectedDefinition, definition)
+
+		definition, err = model.DecodeSessionDefinition(&postgres.Jsonb{
+			RawMessage: json.RawMessage(`{"inactivity_timeout_in_mins": 10, "new_session_on_campaign_change": false}`)})
+		assert.Nil(t, err)
+		assert.Equal(t, model.SessionDefinition{InactivityTimeoutInMins: 10}, definition)
+	})
+}
+
+func TestAddSessionWithProjectSessionDefinition(t *testing.T) {
+	project, _, err := SetupProjectUserReturnDAO()
+	assert.Nil(t, err)
+
+	definitionJsonb, err := U.EncodeStructTypeToPostgresJsonb(model.SessionDefinition{InactivityTimeoutInMins: 5})
+	assert.Nil(t, err)
+	_, errCode := store.GetStore().UpdateProjectSettings(project.ID, &model.ProjectSetting{SessionDefinition: definitionJsonb})
+	assert.Equal(t, http.StatusAccepted, errCode)
+
+	timestamp := U.UnixTimeBeforeDuration(time.Hour * 2)
+	errCode = store.GetStore().UpdateNextSessionStartTimestampForProject(project.ID, timestamp-1)
+	assert.Equal(t, http.StatusAccepted, errCode)
+
+	// Gap of 10 mins is within the default inactivity timeout of 30 mins
+	// but beyond the 5 mins on the project definition.
+	randomEventName := RandomURL()
+	var userID string
+	eventIDs := make([]string, 0)
+	for _, eventTimestamp := range []int64{timestamp, timestamp + 2*60, timestamp + 12*60} {
+		trackPayload := SDK.TrackPayload{
+			Auto:          true,
+			Name:          randomEventName,
+			Timestamp:     eventTimestamp,
+			UserId:        userID,
+			RequestSource: model.UserSourceWeb,
+		}
+		status, response := SDK.Track(project.ID, &trackPayload, false, SDK.SourceJSSDK, "")
+		assert.Equal(t, http.StatusOK, status)
+		userID = response.UserId
+		eventIDs = append(eventIDs, response.EventId)
+	}
+
+	_, err = TaskSession.AddSession([]int64{project.ID}, 0, 0, 0, 0, 1, 1)
+	assert.Nil(t, err)
+
+	sessionIDs := make([]string, 0)
+	for i := range eventIDs {
+		event, errCode := store.GetStore().GetEventById(project.ID, eventIDs[i], "")
+		assert.Equal(t, http.StatusFound, errCode)
+		assert.NotNil(t, event.SessionId)
+		if event.SessionId != nil {
+			sessionIDs = append(sessionIDs, *event.SessionId)
+		}
+	}
+	assert.Len(t, sessionIDs, 3)
+	if len(sessionIDs) == 3 {
+		assert.Equal(t, sessionIDs[0], sessionIDs[1])
+		assert.NotEqual(t, sessionIDs[1], sessionIDs[2])
+	}
+}
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"factors/model/store"
+	serviceDisk "factors/services/disk"
+	eventsImport "factors/task/events_import"
+	U "factors/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsImportMapping(t *testing.T) {
+	config := &eventsImport.MappingConfig{
+		Format:           eventsImport.FormatCSV,
+		EventName:        "event",
+		DefaultEventName: "Signup",
+		Timestamp:        "time",
+		TimestampFormat:  eventsImport.TimestampFormatUnixMs,
+		CustomerUserID:   "email",
+		AnonymousID:      "visitor",
+		CustomerEventID:  "id",
+		EventProperties:  map[string]string{"$page_url": "url"},
+		UserProperties:   map[string]string{"$country": "country"},
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.Nil(t, config.Validate())
+		assert.NotNil(t, (&eventsImport.MappingConfig{Format: "xml", DefaultEventName: "a",
+			Timestamp: "t", AnonymousID: "a"}).Validate())
+		assert.NotNil(t, (&eventsImport.MappingConfig{Format: eventsImport.FormatNDJSON,
+			DefaultEventName: "a", Timestamp: "t"}).Validate())
+	})
+
+	t.Run("Timestamp", func(t *testing.T) {
+		timestamp, err := eventsImport.ParseTimestamp("1672531200", eventsImport.TimestampFormatUnix)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1672531200), timestamp)
+		timestamp, err = eventsImport.ParseTimestamp(float64(1672531200123), eventsImport.TimestampFormatUnixMs)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1672531200), timestamp)
+		timestamp, err = eventsImport.ParseTimestamp("2023-01-01T00:00:00Z", "")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1672531200), timestamp)
+		timestamp, err = eventsImport.ParseTimestamp("2023-01-01", "2006-01-02")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1672531200), timestamp)
+		_, err = eventsImport.ParseTimestamp("01/01/2023", "")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		file := "event,time,email,visitor,id,url,country\n" +
+			"Page View,1672531200000,u1@example.com,v1,e1,https://example.com,US\n" +
+			",1672531200000,,v2,,,\n" +
+			"Page View,not a time,u1@example.com,v1,e2,,\n" +
+			"Page View,1672531200000,,,e3,,\n" +
+			"Page View,1672531200000\n"
+
+		rows := make([]*eventsImport.ImportRow, 0)
+		rejected := 0
+		err := eventsImport.ReadFileRows(strings.NewReader(file), config.Format,
+			func(number int, fileRow map[string]interface{}, err error) {
+				if err != nil {
+					rejected++
+					return
+				}
+				row, err := eventsImport.GetImportRowFromFileRow(number, fileRow, config)
+				if err != nil {
+					rejected++
+					return
+				}
+				rows = append(rows, row)
+			})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, rejected)
+		assert.Len(t, rows, 2)
+
+		assert.Equal(t, 1, rows[0].Number)
+		assert.Equal(t, "Page View", rows[0].Payload.Name)
+		assert.Equal(t, int64(1672531200), rows[0].Payload.Timestamp)
+		assert.True(t, rows[0].Payload.IsPast)
+		assert.Equal(t, "u1@example.com", rows[0].CustomerUserID)
+		assert.Equal(t, "v1", rows[0].AnonymousID)
+		assert.Equal(t, "e1", *rows[0].Payload.CustomerEventId)
+		assert.Equal(t, "https://example.com", rows[0].Payload.EventProperties["$page_url"])
+		assert.Equal(t, "US", rows[0].Payload.UserProperties["$country"])
+
+		// empty event name and properties fallback to defaults.
+		assert.Equal(t, "Signup", rows[1].Payload.Name)
+		assert.Nil(t, rows[1].Payload.CustomerEventId)
+		assert.Len(t, rows[1].Payload.EventProperties, 0)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		ndjsonConfig := *config
+		ndjsonConfig.Format = eventsImport.FormatNDJSON
+		file := `{"event": "Page View", "time": 1672531200000, "visitor": "v1", "url": "https://example.com"}` + "\n\n" +
+			`{"event": "Page View", "time": ` + "\n" +
+			`{"event": "Page View", "time": 4102444800000, "visitor": "v1"}` + "\n"
+
+		numbers := make([]int, 0)
+		errs := make([]error, 0)
+		err := eventsImport.ReadFileRows(strings.NewReader(file), ndjsonConfig.Format,
+			func(number int, fileRow map[string]interface{}, err error) {
+				if err == nil {
+					_, err = eventsImport.GetImportRowFromFileRow(number, fileRow, &ndjsonConfig)
+				}
+				numbers = append(numbers, number)
+				errs = append(errs, err)
+			})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, numbers)
+		assert.Nil(t, errs[0])
+		assert.NotNil(t, errs[1])
+		// events in future are rejected.
+		assert.NotNil(t, errs[2])
+	})
+}
+
+func TestEventsImportErrorsFile(t *testing.T) {
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	dir := t.TempDir()
+	fileManager := serviceDisk.New(dir)
+	timestamp := U.TimeNowUnix() - U.SECONDS_IN_A_DAY
+	file := "event,time,visitor\n" +
+		fmt.Sprintf("Page View,%d,v1\n", timestamp) +
+		"Page View,not a time,v1\n" +
+		fmt.Sprintf("Page View,%d\n", timestamp) +
+		fmt.Sprintf("Page View,%d,\n", timestamp)
+	assert.Nil(t, fileManager.Create(dir, "events.csv", strings.NewReader(file)))
+
+	config := &eventsImport.MappingConfig{
+		Format:          eventsImport.FormatCSV,
+		EventName:       "event",
+		Timestamp:       "time",
+		TimestampFormat: eventsImport.TimestampFormatUnix,
+		AnonymousID:     "visitor",
+	}
+
+	status, err := eventsImport.ImportEvents(project.ID, fileManager, dir, "events.csv", config, true)
+	assert.Nil(t, err)
+	assert.Equal(t, eventsImport.ImportStatus{Total: 4, Imported: 1, Rejected: 3,
+		ErrorsFile: eventsImport.GetErrorsFileName("events.csv")}, *status)
+
+	errorsFile, err := fileManager.Get(dir, status.ErrorsFile)
+	assert.Nil(t, err)
+	defer errorsFile.Close()
+
+	type rejectedRow struct {
+		Row   int                    `json:"row"`
+		Error string                 `json:"error"`
+		Data  map[string]interface{} `json:"data"`
+	}
+	rejectedRows := make([]rejectedRow, 0)
+	scanner := bufio.NewScanner(errorsFile)
+	for scanner.Scan() {
+		var row rejectedRow
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &row))
+		rejectedRows = append(rejectedRows, row)
+	}
+	assert.Nil(t, scanner.Err())
+
+	assert.Len(t, rejectedRows, 3)
+	assert.Equal(t, 2, rejectedRows[0].Row)
+	assert.Equal(t, "invalid unix timestamp", rejectedRows[0].Error)
+	assert.Equal(t, "not a time", rejectedRows[0].Data["time"])
+	// rows which can't be read are reported without the data.
+	assert.Equal(t, 3, rejectedRows[1].Row)
+	assert.Nil(t, rejectedRows[1].Data)
+	assert.Equal(t, 4, rejectedRows[2].Row)
+	assert.Equal(t, "customer_user_id and anonymous_id are empty", rejectedRows[2].Error)
+
+	// errors file is not created when no row is rejected.
+	assert.Nil(t, fileManager.Create(dir, "valid.csv", strings.NewReader("event,time,visitor\n"+
+		fmt.Sprintf("Page View,%d,v1\n", timestamp))))
+	status, err = eventsImport.ImportEvents(project.ID, fileManager, dir, "valid.csv", config, true)
+	assert.Nil(t, err)
+	assert.Equal(t, eventsImport.ImportStatus{Total: 1, Imported: 1}, *status)
+	_, err = fileManager.Get(dir, eventsImport.GetErrorsFileName("valid.csv"))
+	assert.NotNil(t, err)
+}
+
+func TestEventsImportRunTwice(t *testing.T) {
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	dir := t.TempDir()
+	fileManager := serviceDisk.New(dir)
+	timestamp := U.TimeNowUnix() - U.SECONDS_IN_A_DAY
+	file := "event,time,email,visitor,id\n" +
+		fmt.Sprintf("Page View,%d,,v1,e1\n", timestamp) +
+		fmt.Sprintf("Signup,%d,u1@example.com,v1,e2\n", timestamp+10) +
+		fmt.Sprintf("Page View,%d,,v2,\n", timestamp+20)
+	assert.Nil(t, fileManager.Create(dir, "events.csv", strings.NewReader(file)))
+
+	config := &eventsImport.MappingConfig{
+		Format:          eventsImport.FormatCSV,
+		EventName:       "event",
+		Timestamp:       "time",
+		TimestampFormat: eventsImport.TimestampFormatUnix,
+		CustomerUserID:  "email",
+		AnonymousID:     "visitor",
+		CustomerEventID: "id",
+	}
+
+	status, err := eventsImport.ImportEvents(project.ID, fileManager, dir, "events.csv", config, false)
+	assert.Nil(t, err)
+	assert.Equal(t, eventsImport.ImportStatus{Total: 3, Imported: 3}, *status)
+
+	v1UserID, errCode := store.GetStore().GetUserIDByImportAnonymousID(project.ID, "v1")
+	assert.Equal(t, http.StatusFound, errCode)
+	user, errCode := store.GetStore().GetUser(project.ID, v1UserID)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Equal(t, "u1@example.com", user.CustomerUserId)
+	v2UserID, errCode := store.GetStore().GetUserIDByImportAnonymousID(project.ID, "v2")
+	assert.Equal(t, http.StatusFound, errCode)
+
+	// rows with c_event_id imported already are skipped, the rest are tracked on the same users.
+	status, err = eventsImport.ImportEvents(project.ID, fileManager, dir, "events.csv", config, false)
+	assert.Nil(t, err)
+	assert.Equal(t, eventsImport.ImportStatus{Total: 3, Imported: 1, Duplicates: 2}, *status)
+
+	userID, errCode := store.GetStore().GetUserIDByImportAnonymousID(project.ID, "v2")
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Equal(t, v2UserID, userID)
+
+	eventName, errCode := store.GetStore().GetEventName("Page View", project.ID)
+	assert.Equal(t, http.StatusFound, errCode)
+	events, errCode := store.GetStore().GetUserEventsByEventNameId(project.ID, v1UserID, eventName.ID)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Len(t, events, 1)
+	events, errCode = store.GetStore().GetUserEventsByEventNameId(project.ID, v2UserID, eventName.ID)
+	assert.Equal(t, http.StatusFound, errCode)
+	assert.Len(t, events, 2)
+}
+
+func TestCreateOrGetImportUserConcurrently(t *testing.T) {
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	anonymousID := U.RandomLowerAphaNumString(10)
+	userIDs := make([]string, 5)
+	errCodes := make([]int, 5)
+	var wg sync.WaitGroup
+	for i := range userIDs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			userIDs[i], errCodes[i] = store.GetStore().CreateOrGetImportUser(project.ID, anonymousID, U.TimeNowUnix())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range userIDs {
+		assert.Contains(t, []int{http.StatusCreated, http.StatusFound}, errCodes[i])
+		assert.Equal(t, userIDs[0], userIDs[i])
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	U "factors/util"
+	"factors/webhooks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropWebhookSignature(t *testing.T) {
+	secret := "test_secret"
+	var body []byte
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		headers = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	response, err := webhooks.DropWebhook(server.URL, secret, false, map[string]string{"key": "value"})
+	assert.Nil(t, err)
+	assert.Equal(t, "success", response["status"])
+	assert.Empty(t, headers.Get(webhooks.HeaderLegacySecret))
+	assert.NotEmpty(t, headers.Get(webhooks.HeaderDeliveryID))
+
+	timestamp, err := strconv.ParseInt(headers.Get(webhooks.HeaderTimestamp), 10, 64)
+	assert.Nil(t, err)
+	signature := headers.Get(webhooks.HeaderSignature)
+	assert.True(t, webhooks.VerifySignature(secret, timestamp, body, signature, webhooks.DefaultSignatureTolerance))
+
+	// Tampered body, wrong secret and replayed deliveries are rejected.
+	assert.False(t, webhooks.VerifySignature(secret, timestamp, []byte(`{"key":"forged"}`), signature, webhooks.DefaultSignatureTolerance))
+	assert.False(t, webhooks.VerifySignature("other_secret", timestamp, body, signature, webhooks.DefaultSignatureTolerance))
+	oldTimestamp := timestamp - 3600
+	assert.False(t, webhooks.VerifySignature(secret, oldTimestamp, body,
+		webhooks.ComputeSignature(secret, oldTimestamp, body), webhooks.DefaultSignatureTolerance))
+}
+
+func TestDropWebhookLegacySecretHeader(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	response, err := webhooks.DropWebhook(server.URL, "test_secret", true, map[string]string{"key": "value"})
+	assert.Nil(t, err)
+	assert.Equal(t, "success", response["status"])
+	assert.NotEmpty(t, headers.Get(webhooks.HeaderLegacySecret))
+	assert.NotEmpty(t, headers.Get(webhooks.HeaderSignature))
+}
+
+func TestDropWebhookWithRetries(t *testing.T) {
+	initialRetryBackoff := webhooks.InitialRetryBackoff
+	defer func() { webhooks.InitialRetryBackoff = initialRetryBackoff }()
+	webhooks.InitialRetryBackoff = 10 * time.Millisecond
+	project, err := SetupProjectReturnDAO()
+	assert.Nil(t, err)
+
+	t.Run("SuccessAfterServerErrors", func(t *testing.T) {
+		requests := 0
+		deliveryIDs := make(map[string]bool)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			deliveryIDs[r.Header.Get(webhooks.HeaderDeliveryID)] = true
+			if requests < 3 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		var retriedResponse map[string]interface{}
+		var retriedErr error
+		response, err := webhooks.DropWebhookWithRetries(project.ID, server.URL, "secret", false, map[string]string{"key": "value"},
+			func(response map[string]interface{}, err error) {
+				retriedResponse, retriedErr = response, err
+			})
+		assert.Nil(t, err)
+		// retries don't block the caller.
+		assert.Equal(t, webhooks.DeliveryStatusRetrying, response["status"])
+
+		webhooks.WaitForRetries()
+		assert.Nil(t, retriedErr)
+		assert.Equal(t, "success", retriedResponse["status"])
+		assert.Equal(t, 3, retriedResponse["attempts"])
+		assert.Equal(t, response["delivery_id"], retriedResponse["delivery_id"])
+		assert.Equal(t, 3, requests)
+		// Same delivery id on all attempts.
+		assert.Len(t, deliveryIDs, 1)
+	})
+
+	t.Run("DeadLetterAfterAllAttempts", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		var retriedResponse map[string]interface{}
+		response, err := webhooks.DropWebhookWithRetries(project.ID, server.URL, "secret", false, map[string]string{"key": "value"},
+			func(response map[string]interface{}, err error) {
+				retriedResponse = response
+			})
+		assert.Nil(t, err)
+		assert.Equal(t, webhooks.DeliveryStatusRetrying, response["status"])
+
+		webhooks.WaitForRetries()
+		assert.Equal(t, "failure", retriedResponse["status"])
+		assert.Equal(t, webhooks.MaxDeliveryAttempts, requests)
+
+		deadLetter, errCode := webhooks.GetDeadLetter(project.ID, U.GetPropertyValueAsString(response["delivery_id"]))
+		assert.Equal(t, http.StatusFound, errCode)
+		assert.Equal(t, server.URL, deadLetter.Url)
+		assert.Equal(t, webhooks.MaxDeliveryAttempts, deadLetter.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, deadLetter.LastStatusCode)
+	})
+
+	t.Run("NoRetryOnClientError", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		response, err := webhooks.DropWebhookWithRetries(project.ID, server.URL, "secret", false, map[string]string{"key": "value"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "failure", response["status"])
+		assert.Equal(t, 1, requests)
+	})
+}
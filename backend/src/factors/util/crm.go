@@ -13,6 +13,10 @@ const (
 	CRM_SOURCE_NAME_SALESFORCE            = "salesforce"
 	CRM_SOURCE_NAME_MARKETO               = "marketo"
 	CRM_SOURCE_NAME_LEADSQUARED           = "leadsquared"
+	// Sources registered by the projects for the CRMs pushed through the CRM API.
+	// Source ids of custom sources starts from CRM_SOURCE_CUSTOM_MIN.
+	CRM_SOURCE_CUSTOM_MIN  CRMSource = 1001
+	CRM_SOURCE_NAME_CUSTOM           = "custom_crm"
 )
 
 // List of prefix to differentiate CRM property from other properties. Only properties with prefix will overwritten by CRM
@@ -21,6 +25,7 @@ var CRMUserPropertiesOverwritePrefixes = map[string]string{
 	CRM_SOURCE_NAME_SALESFORCE:  SALESFORCE_PROPERTY_PREFIX,
 	CRM_SOURCE_NAME_MARKETO:     MARKETO_PROPERTY_PREFIX,
 	CRM_SOURCE_NAME_LEADSQUARED: LEADSQUARED_PROPERTY_PREFIX,
+	CRM_SOURCE_NAME_CUSTOM:      CUSTOM_CRM_PROPERTY_PREFIX,
 }
 
 var SourceCRM = map[string]int{
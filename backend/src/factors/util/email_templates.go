@@ -426,3 +426,25 @@ func GetSixSignalReportSharingTemplate(url string, domain string) string {
 	</html>`, domain, url)
 	return html
 }
+
+const SharedReportLoginSubject = "Your login link for the shared report on Factors.AI"
+
+func CreateSharedReportLoginLinkTemplate(email, link string, expiryInMins int) (subject, text, html string) {
+	subject = SharedReportLoginSubject
+	text = fmt.Sprintf("Hey there,\n\nUse the link below to view the report shared with %s. The link can be used only once and expires in %d minutes.\n\n%s\n\nIf you did not request it, ignore this mail.\n\n-The Factors Team",
+		email, expiryInMins, link)
+	html = fmt.Sprintf(`<!DOCTYPE html>
+	<html>
+		<head>
+			<meta charset="utf-8">
+		</head>
+		<body>
+			<p>Hey there,</p>
+			<p>Use the link below to view the report shared with %s. The link can be used only once and expires in %d minutes.</p>
+			<p><a href=%s>View the report</a></p>
+			<p>If you did not request it, ignore this mail.</p>
+			<p>-The Factors Team</p>
+		</body>
+	</html>`, email, expiryInMins, link)
+	return
+}
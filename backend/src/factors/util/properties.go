@@ -33,6 +33,10 @@ const EVENT_NAME_OFFLINE_TOUCH_POINT = "$offline_touch_point"
 const EVENT_NAME_FORM_SUBMITTED = "$form_submitted"
 const EVENT_NAME_CLICKED_EMAIL = "$clicked_email"
 
+// Segment membership events, tracked by the segment marker.
+const EVENT_NAME_SEGMENT_ENTERED = "$segment_entered"
+const EVENT_NAME_SEGMENT_EXITED = "$segment_exited"
+
 // Integration: Hubspot event names.
 const EVENT_NAME_HUBSPOT_CONTACT_CREATED = "$hubspot_contact_created"
 const EVENT_NAME_HUBSPOT_CONTACT_UPDATED = "$hubspot_contact_updated"
@@ -190,6 +194,8 @@ const EVENT_NAME_SHOPIFY_CART_UPDATED = "$shopify_cart_updated"
 
 var ALLOWED_INTERNAL_EVENT_NAMES = [...]string{
 	EVENT_NAME_CLICKED_EMAIL,
+	EVENT_NAME_SEGMENT_ENTERED,
+	EVENT_NAME_SEGMENT_EXITED,
 	EVENT_NAME_SESSION,
 	EVENT_NAME_PAGE_VIEW,
 	EVENT_NAME_FORM_FILL,
@@ -346,6 +352,7 @@ const GROUP_NAME_SIX_SIGNAL = "$6signal"
 const GROUP_NAME_DOMAINS = "$domains"
 const GROUP_NAME_LINKEDIN_COMPANY = "$linkedin_company"
 const GROUP_NAME_G2 = "$g2"
+const GROUP_NAME_SEGMENT_ACCOUNT = "$segment_account"
 
 var GROUP_EVENT_NAME_TO_GROUP_NAME_MAPPING = map[string]string{
 	GROUP_EVENT_NAME_HUBSPOT_COMPANY_CREATED:        GROUP_NAME_HUBSPOT_COMPANY,
@@ -410,6 +417,8 @@ const PROPERTY_KEY_LAST_MODIFIED_DATE_HS = "hs_lastmodifieddate"
 const EP_TIME_SPENT_ON_FORM = "time_spent_on_form"             // unit:seconds
 const EP_TIME_SPENT_ON_FORM_FIELD = "time_spent_on_form_field" // unit:seconds
 const EP_FORM_FIELD_VALUE = "form_field_value"
+const EP_SEGMENT_ID = "$segment_id"
+const EP_SEGMENT_NAME = "$segment_name"
 
 var GENERIC_NUMERIC_EVENT_PROPERTIES = [...]string{
 	EP_FIRST_SEEN_OCCURRENCE_COUNT,
@@ -773,6 +782,12 @@ var G2_EMPLOYEES_RANGE = "$g2_employees_range"
 var G2_EMPLOYEES = "$g2_employees"
 var G2_COMPANY_ID = "$g2_company_id"
 
+// segment account properties, from the traits of segment group calls.
+var SEGMENT_ACCOUNT_PROPERTIES_PREFIX = "$segment_account_"
+var SEGMENT_ACCOUNT_ID = "$segment_account_id"
+var SEGMENT_ACCOUNT_DOMAIN = "$segment_account_domain"
+var SEGMENT_ACCOUNT_NAME = "$segment_account_name"
+
 // account properties
 var IN_HUBSPOT = "$in_hubspot"
 var IN_G2 = "$in_g2"
@@ -1058,15 +1073,17 @@ const HUBSPOT_PROPERTY_PREFIX = "$hubspot_"
 const SALESFORCE_PROPERTY_PREFIX = "$salesforce_"
 const MARKETO_PROPERTY_PREFIX = "$marketo_"
 const LEADSQUARED_PROPERTY_PREFIX = "$leadsquared_"
+const CUSTOM_CRM_PROPERTY_PREFIX = "$custom_crm_"
 
 var CRMEventPrefixes = [...]string{
-	"$hubspot", "$salesforce", "$sf", "$leadsquared", "$marketo",
+	"$hubspot", "$salesforce", "$sf", "$leadsquared", "$marketo", "$custom_crm",
 }
 var AllowedCRMPropertyPrefix = map[string]bool{
 	HUBSPOT_PROPERTY_PREFIX:     true,
 	SALESFORCE_PROPERTY_PREFIX:  true,
 	MARKETO_PROPERTY_PREFIX:     true,
 	LEADSQUARED_PROPERTY_PREFIX: true,
+	CUSTOM_CRM_PROPERTY_PREFIX:  true,
 }
 
 const (
@@ -1074,6 +1091,8 @@ const (
 	SMART_EVENT_SALESFORCE_CURR_PROPERTY = "$curr_salesforce_"
 	SMART_EVENT_HUBSPOT_PREV_PROPERTY    = "$prev_hubspot_"
 	SMART_EVENT_HUBSPOT_CURR_PROPERTY    = "$curr_hubspot_"
+	SMART_EVENT_CUSTOM_CRM_PREV_PROPERTY = "$prev_custom_crm_"
+	SMART_EVENT_CUSTOM_CRM_CURR_PROPERTY = "$curr_custom_crm_"
 )
 
 const (
@@ -1725,6 +1744,7 @@ var STANDARD_GROUP_DISPLAY_NAMES = map[string]string{
 	GROUP_NAME_SIX_SIGNAL:             "Company Identification",
 	GROUP_NAME_LINKEDIN_COMPANY:       "Linkedin Company Engagements",
 	GROUP_NAME_G2:                     "G2 Engagements",
+	GROUP_NAME_SEGMENT_ACCOUNT:        "Segment Accounts",
 }
 
 var ALL_ACCOUNT_DEFAULT_PROPERTIES = []string{
@@ -2211,6 +2231,9 @@ var STANDARD_USER_PROPERTIES_DISPLAY_NAMES = map[string]string{
 	G2_EMPLOYEES_RANGE:                     "G2 Company Employee Range",
 	G2_EMPLOYEES:                           "G2 No Of Employees",
 	G2_COMPANY_ID:                          "G2 Company ID",
+	SEGMENT_ACCOUNT_ID:                     "Segment Account ID",
+	SEGMENT_ACCOUNT_DOMAIN:                 "Segment Account Domain",
+	SEGMENT_ACCOUNT_NAME:                   "Segment Account Name",
 	ENRICHED_SALESFORCE_ACCOUNT_OBJECT_URL: "Salesforce Account URL",
 	ENRICHED_HUBSPOT_COMPANY_OBJECT_URL:    "Hubspot Company URL",
 	LI_DOMAIN:                              "Linkedin Domain",
@@ -3909,6 +3932,8 @@ func isCRMSmartEventPropertyKey(key *string) bool {
 		!strings.HasPrefix((*key), SMART_EVENT_SALESFORCE_CURR_PROPERTY) &&
 		!strings.HasPrefix((*key), SMART_EVENT_HUBSPOT_PREV_PROPERTY) &&
 		!strings.HasPrefix((*key), SMART_EVENT_HUBSPOT_CURR_PROPERTY) &&
+		!strings.HasPrefix((*key), SMART_EVENT_CUSTOM_CRM_PREV_PROPERTY) &&
+		!strings.HasPrefix((*key), SMART_EVENT_CUSTOM_CRM_CURR_PROPERTY) &&
 		(*key) != EP_CRM_REFERENCE_EVENT_ID {
 		return false
 	}
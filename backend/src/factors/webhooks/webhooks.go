@@ -2,18 +2,194 @@ package webhooks
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"time"
 
+	"factors/model/model"
+	"factors/model/store"
+	U "factors/util"
+
+	"github.com/jinzhu/gorm/dialects/postgres"
 	log "github.com/sirupsen/logrus"
 )
 
-func DropWebhook(url, secret string, payload interface{}) (map[string]interface{}, error) {
+const (
+	HeaderSignature  = "factors-signature-256"
+	HeaderTimestamp  = "factors-timestamp"
+	HeaderDeliveryID = "factors-delivery-id"
+	// HeaderLegacySecret carries a hash of the secret alone. Sent unless the webhook
+	// opted out, for receivers which haven't moved to HeaderSignature yet.
+	HeaderLegacySecret = "factors-secret-256"
+
+	// DeliveryStatusRetrying is the status of a delivery whose first attempt failed
+	// and is being retried in the background.
+	DeliveryStatusRetrying = "retrying"
+
+	// DefaultSignatureTolerance is the max age of a delivery accepted by VerifySignature.
+	DefaultSignatureTolerance = 5 * time.Minute
+
+	MaxDeliveryAttempts = 4
+	requestTimeout      = 30 * time.Second
+)
+
+// InitialRetryBackoff is the wait before the first retry, doubled on every next retry.
+var InitialRetryBackoff = 1 * time.Second
+
+// pendingRetries tracks the deliveries being retried in the background.
+var pendingRetries sync.WaitGroup
+
+// ComputeSignature returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" using the secret.
+func ComputeSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature validates the signature of a delivery and rejects deliveries
+// older than the tolerance to protect receivers against replays.
+func VerifySignature(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration) bool {
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+
+	expected := ComputeSignature(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// DropWebhook sends the payload once, signed with the secret.
+func DropWebhook(url, secret string, legacySecretHeader bool, payload interface{}) (map[string]interface{}, error) {
+	jsonBody, err := validateAndMarshal(url, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := sendSignedRequest(url, secret, legacySecretHeader, U.GetUUID(), jsonBody)
+	return response, err
+}
+
+// DropWebhookWithRetries makes the first delivery attempt inline. Network errors, rate
+// limits and server errors are retried in the background with exponential backoff, to
+// not hold the caller on the waits, and the response has DeliveryStatusRetrying as status.
+// onRetried, if not nil, is called with the result of the last background attempt.
+// All attempts share the same delivery id, for receivers to dedupe. If all attempts
+// fail, a dead letter is recorded for the project on the webhook_dead_letters table.
+func DropWebhookWithRetries(projectID int64, url, secret string, legacySecretHeader bool, payload interface{},
+	onRetried func(response map[string]interface{}, err error)) (map[string]interface{}, error) {
+
+	jsonBody, err := validateAndMarshal(url, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryID := U.GetUUID()
+	response, retriable, err := sendSignedRequest(url, secret, legacySecretHeader, deliveryID, jsonBody)
+	if !retriable {
+		return completeDelivery(projectID, url, deliveryID, jsonBody, 1, response, err)
+	}
+
+	log.WithField("project_id", projectID).WithField("url", url).WithField("delivery_id", deliveryID).
+		WithError(err).Warn("Webhook delivery failed. Retrying in background.")
+
+	pendingRetries.Add(1)
+	go func() {
+		defer pendingRetries.Done()
+		response, err := retryDelivery(projectID, url, secret, legacySecretHeader, deliveryID, jsonBody)
+		if onRetried != nil {
+			onRetried(response, err)
+		}
+	}()
+
+	return map[string]interface{}{
+		"status":      DeliveryStatusRetrying,
+		"delivery_id": deliveryID,
+		"attempts":    1,
+	}, nil
+}
+
+// WaitForRetries blocks till the deliveries being retried in the background are done.
+// Jobs using DropWebhookWithRetries have to call it before exiting.
+func WaitForRetries() {
+	pendingRetries.Wait()
+}
+
+func retryDelivery(projectID int64, url, secret string, legacySecretHeader bool, deliveryID string,
+	jsonBody []byte) (map[string]interface{}, error) {
+
+	logCtx := log.WithField("project_id", projectID).WithField("url", url).WithField("delivery_id", deliveryID)
+
+	var response map[string]interface{}
+	var retriable bool
+	var err error
+	attempts := 1
+	for attempts < MaxDeliveryAttempts {
+		time.Sleep(InitialRetryBackoff * time.Duration(math.Pow(2, float64(attempts-1))))
+		attempts++
+
+		response, retriable, err = sendSignedRequest(url, secret, legacySecretHeader, deliveryID, jsonBody)
+		if !retriable {
+			break
+		}
+		logCtx.WithField("attempt", attempts).WithError(err).Warn("Webhook delivery failed. Retrying.")
+	}
+
+	return completeDelivery(projectID, url, deliveryID, jsonBody, attempts, response, err)
+}
+
+// completeDelivery returns the result of the last attempt of the delivery and
+// records the dead letter, if the attempt failed.
+func completeDelivery(projectID int64, url, deliveryID string, jsonBody []byte, attempts int,
+	response map[string]interface{}, err error) (map[string]interface{}, error) {
+
+	if err == nil && response["status"] == "success" {
+		response["attempts"] = attempts
+		return response, nil
+	}
+
+	deadLetter := model.WebhookDeadLetter{
+		ProjectID:  projectID,
+		DeliveryID: deliveryID,
+		Url:        url,
+		Payload:    &postgres.Jsonb{RawMessage: jsonBody},
+		Attempts:   attempts,
+		FailedAt:   U.TimeNowZ(),
+	}
+	if err != nil {
+		deadLetter.LastError = err.Error()
+	} else {
+		deadLetter.LastError = fmt.Sprintf("%v", response["error"])
+		if statusCode, ok := response["statuscode"].(int); ok {
+			deadLetter.LastStatusCode = statusCode
+		}
+		response["attempts"] = attempts
+	}
+	if errCode := store.GetStore().CreateWebhookDeadLetter(&deadLetter); errCode != http.StatusCreated {
+		log.WithField("project_id", projectID).WithField("url", url).WithField("delivery_id", deliveryID).
+			WithField("err_code", errCode).Error("Failed to record webhook dead letter.")
+	}
+
+	return response, err
+}
+
+// GetDeadLetter returns the dead letter recorded for the delivery, if any.
+func GetDeadLetter(projectID int64, deliveryID string) (*model.WebhookDeadLetter, int) {
+	return store.GetStore().GetWebhookDeadLetter(projectID, deliveryID)
+}
+
+func validateAndMarshal(url string, payload interface{}) ([]byte, error) {
 	if url == "" || !IsUrl(url) {
 		return nil, fmt.Errorf("invalid url")
 	}
@@ -21,40 +197,53 @@ func DropWebhook(url, secret string, payload interface{}) (map[string]interface{
 		return nil, fmt.Errorf("no payload to drop")
 	}
 
-	jsonBody, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
+	return json.Marshal(payload)
+}
+
+// sendSignedRequest makes a single delivery attempt and returns the response along
+// with whether the failure, if any, is worth retrying.
+func sendSignedRequest(url, secret string, legacySecretHeader bool, deliveryID string,
+	jsonBody []byte) (map[string]interface{}, bool, error) {
 	request, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
+	timestamp := U.TimeNowUnix()
 	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request.Header.Set(HeaderDeliveryID, deliveryID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
 	if secret != "" {
-		h := sha256.New()
-		h.Write([]byte(secret))
-		request.Header.Add("factors-secret-256", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+		request.Header.Set(HeaderSignature, ComputeSignature(secret, timestamp, jsonBody))
+
+		if legacySecretHeader {
+			h := sha256.New()
+			h.Write([]byte(secret))
+			request.Header.Add(HeaderLegacySecret, base64.StdEncoding.EncodeToString(h.Sum(nil)))
+		}
 	}
 
-	client := &http.Client{}
+	client := &http.Client{Timeout: requestTimeout}
 	resp, err := client.Do(request)
 	if err != nil {
 		log.WithError(err).Error("failed to make request for webhook")
-		return nil, err
+		return nil, true, err
 	}
 	defer resp.Body.Close()
 	bodyBytes, _ := ioutil.ReadAll(resp.Body)
 	response := make(map[string]interface{})
+	response["delivery_id"] = deliveryID
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		response["status"] = "success"
-	} else {
-		log.WithField("request", request).Error("Failed to send webhook request")
-		response["status"] = "failure"
-		response["error"] = string(bodyBytes)
-		response["statuscode"] = resp.StatusCode
+		return response, false, nil
 	}
-	return response, nil
+
+	log.WithField("request", request).Error("Failed to send webhook request")
+	response["status"] = "failure"
+	response["error"] = string(bodyBytes)
+	response["statuscode"] = resp.StatusCode
+	retriable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return response, retriable, nil
 }
 
 func IsUrl(str string) bool {
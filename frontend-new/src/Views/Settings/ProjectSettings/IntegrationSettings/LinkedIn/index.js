@@ -43,7 +43,9 @@ const LinkedInIntegration = ({
       const url = `${getBackendHost()}/integrations/linkedin/auth`;
       fetch(url, {
         method: 'POST',
+        credentials: 'include',
         body: JSON.stringify({
+          project_id: activeProject?.id?.toString(),
           code
         })
       })
@@ -59,7 +61,9 @@ const LinkedInIntegration = ({
               setOauthResponse(e);
               fetch(`${getBackendHost()}/integrations/linkedin/ad_accounts`, {
                 method: 'POST',
+                credentials: 'include',
                 body: JSON.stringify({
+                  project_id: activeProject?.id?.toString(),
                   access_token: e?.access_token
                 })
               })
@@ -214,7 +214,7 @@ export function getKPIPropertyMappings(projectID, data) {
         host +
           'projects/' +
           projectID +
-          `/v1/kpi/property_mappings/commom_properties`,
+          `/v1/kpi/property_mappings/common_properties`,
         data
       )
         .then((response) => {
@@ -62,7 +62,9 @@ class Linkedin extends Component {
         let url= getHostURL()+'integrations/linkedin/auth'
         fetch(url,{
           method: 'POST',
+          credentials: 'include',
           body: JSON.stringify({
+            'project_id': this.props.currentProjectId.toString(),
             'code': code
           })
         }).then(response => {
@@ -73,7 +75,9 @@ class Linkedin extends Component {
             })
             fetch(getHostURL()+'integrations/linkedin/ad_accounts', {
               method: 'POST',
+              credentials: 'include',
               body: JSON.stringify({
+                'project_id': this.props.currentProjectId.toString(),
                 'access_token': this.state.oauthResponse['access_token']
               })
             }